@@ -7,11 +7,13 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // tenant time zones must resolve on images without zoneinfo
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"sfa/backend/internal/config"
 	httpapi "sfa/backend/internal/http"
+	"sfa/backend/internal/jobs"
 	"sfa/backend/internal/store"
 )
 
@@ -28,7 +30,7 @@ func main() {
 	defer pool.Close()
 
 	s := store.New(pool)
-	router := httpapi.NewRouter(s)
+	router := httpapi.NewRouter(s, cfg)
 
 	server := &http.Server{
 		Addr:              ":" + cfg.Port,
@@ -36,6 +38,15 @@ func main() {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
+	if cfg.QuoteExpiryInterval > 0 {
+		expiry := jobs.QuoteExpiry{Store: s, NoticeDays: cfg.QuoteExpiryNoticeDays}
+		go expiry.Run(ctx, cfg.QuoteExpiryInterval)
+	}
+	if cfg.ApprovalEscalationInterval > 0 {
+		escalation := jobs.ApprovalEscalation{Store: s}
+		go escalation.Run(ctx, cfg.ApprovalEscalationInterval)
+	}
+
 	go func() {
 		log.Printf("api listening on :%s", cfg.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
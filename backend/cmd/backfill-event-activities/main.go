@@ -0,0 +1,58 @@
+// Command backfill-event-activities converts linked email/calendar events that were
+// imported before automatic conversion existed (or whose meetings have since taken place)
+// into opportunity activities. It is safe to re-run.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sfa/backend/internal/config"
+	"sfa/backend/internal/integrations"
+	"sfa/backend/internal/store"
+)
+
+func main() {
+	tenants := flag.String("tenant", "", "comma-separated tenant IDs (default: all tenants)")
+	batchSize := flag.Int("batch", 500, "events per transaction")
+	flag.Parse()
+
+	var tenantIDs []uuid.UUID
+	for _, raw := range strings.Split(*tenants, ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			log.Fatalf("invalid tenant id %q: %v", raw, err)
+		}
+		tenantIDs = append(tenantIDs, id)
+	}
+	if *batchSize < 1 {
+		log.Fatalf("batch must be at least 1")
+	}
+
+	cfg := config.Load()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to create db pool: %v", err)
+	}
+	defer pool.Close()
+
+	result, err := integrations.Backfill(ctx, store.New(pool), tenantIDs, int32(*batchSize))
+	log.Printf("tenants=%d scanned=%d converted=%d", result.Tenants, result.Scanned, result.Converted)
+	if err != nil {
+		log.Fatalf("backfill failed: %v", err)
+	}
+}
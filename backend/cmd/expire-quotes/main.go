@@ -0,0 +1,45 @@
+// Command expire-quotes runs the quote expiry job once, for deployments that schedule it
+// with cron instead of the ticker inside the API. It is safe to run alongside the API.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sfa/backend/internal/config"
+	"sfa/backend/internal/jobs"
+	"sfa/backend/internal/store"
+)
+
+func main() {
+	cfg := config.Load()
+
+	noticeDays := flag.Int("notice-days", cfg.QuoteExpiryNoticeDays, "days before valid_until to remind the owner (0 disables reminders)")
+	batchSize := flag.Int("batch", 200, "quotes per transaction")
+	flag.Parse()
+
+	if *batchSize < 1 {
+		log.Fatalf("batch must be at least 1")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to create db pool: %v", err)
+	}
+	defer pool.Close()
+
+	job := jobs.QuoteExpiry{Store: store.New(pool), NoticeDays: *noticeDays, BatchSize: int32(*batchSize)}
+	result, err := job.RunOnce(ctx)
+	log.Printf("tenants=%d expired=%d reminded=%d follow_ups=%d", result.Tenants, result.Expired, result.Reminded, result.FollowUps)
+	if err != nil {
+		log.Fatalf("quote expiry failed: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Lookups used by the account timeline feed.
+CREATE INDEX idx_integration_events_tenant_linked_account ON integration_events (tenant_id, linked_account_id);
+CREATE INDEX idx_integration_events_tenant_linked_contact ON integration_events (tenant_id, linked_contact_id);
+CREATE INDEX idx_integration_events_tenant_linked_opportunity ON integration_events (tenant_id, linked_opportunity_id);
+CREATE INDEX idx_approval_requests_tenant_entity ON approval_requests (tenant_id, entity_id);
+CREATE INDEX idx_audit_logs_tenant_entity ON audit_logs (tenant_id, entity_id, created_at DESC);
+
+COMMIT;
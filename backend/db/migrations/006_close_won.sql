@@ -0,0 +1,22 @@
+BEGIN;
+
+-- Per-tenant counters for generated document numbers (order_no, quote_no). Rows are
+-- keyed by prefix and period so numbering restarts every month.
+CREATE TABLE document_sequences (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  prefix TEXT NOT NULL,
+  period TEXT NOT NULL,
+  last_value INTEGER NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, prefix, period)
+);
+
+ALTER TABLE document_sequences ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_document_sequences ON document_sequences
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE INDEX idx_quotes_tenant_opportunity_status ON quotes (tenant_id, opportunity_id, status);
+
+COMMIT;
@@ -0,0 +1,64 @@
+BEGIN;
+
+ALTER TABLE tenants
+  ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'JPY' CHECK (base_currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE opportunities
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE quotes
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE orders
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE price_books
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+-- rate is the value of one unit of currency in base_currency, effective from
+-- effective_on until the next row for the same pair.
+CREATE TABLE fx_rates (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  base_currency TEXT NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+  rate NUMERIC(18,8) NOT NULL CHECK (rate > 0),
+  effective_on DATE NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, currency, base_currency, effective_on)
+);
+
+CREATE INDEX idx_fx_rates_lookup ON fx_rates (tenant_id, currency, base_currency, effective_on DESC);
+
+ALTER TABLE fx_rates ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_fx_rates ON fx_rates
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- fx_rate returns the multiplier from currency to the tenant's base currency on the
+-- given date, 1 for the base currency itself, or NULL when no rate is in effect.
+CREATE FUNCTION fx_rate(p_tenant_id UUID, p_currency TEXT, p_on DATE)
+RETURNS NUMERIC
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT CASE
+    WHEN p_currency = t.base_currency THEN 1::NUMERIC
+    ELSE (
+      SELECT r.rate
+      FROM fx_rates r
+      WHERE r.tenant_id = p_tenant_id
+        AND r.currency = p_currency
+        AND r.base_currency = t.base_currency
+        AND r.effective_on <= p_on
+      ORDER BY r.effective_on DESC
+      LIMIT 1
+    )
+  END
+  FROM tenants t
+  WHERE t.id = p_tenant_id
+$$;
+
+COMMIT;
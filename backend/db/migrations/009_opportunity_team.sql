@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TYPE opportunity_team_role_enum AS ENUM ('primary_rep', 'presales_engineer', 'partner_manager', 'executive_sponsor', 'other');
+
+-- Team members share access to a deal. split_percent is the member's share of revenue
+-- credit; a non-empty team must split exactly 100% (enforced by the API on replace).
+CREATE TABLE opportunity_team_members (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  opportunity_id UUID NOT NULL REFERENCES opportunities(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id),
+  team_role opportunity_team_role_enum NOT NULL,
+  split_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (split_percent >= 0 AND split_percent <= 100),
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (opportunity_id, user_id)
+);
+
+CREATE UNIQUE INDEX uq_opportunity_team_primary_rep ON opportunity_team_members (opportunity_id) WHERE team_role = 'primary_rep';
+CREATE INDEX idx_opportunity_team_tenant_user ON opportunity_team_members (tenant_id, user_id);
+
+ALTER TABLE opportunity_team_members ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_opportunity_team_members ON opportunity_team_members
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+COMMIT;
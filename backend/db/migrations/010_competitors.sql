@@ -0,0 +1,58 @@
+BEGIN;
+
+CREATE TABLE competitors (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  website TEXT,
+  note TEXT,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+-- Competitors present on a deal. is_winner marks the one the deal was lost to and
+-- is only set while the opportunity is closed_lost.
+CREATE TABLE opportunity_competitors (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  opportunity_id UUID NOT NULL REFERENCES opportunities(id) ON DELETE CASCADE,
+  competitor_id UUID NOT NULL REFERENCES competitors(id) ON DELETE CASCADE,
+  is_winner BOOLEAN NOT NULL DEFAULT FALSE,
+  note TEXT,
+  created_by UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (opportunity_id, competitor_id)
+);
+
+CREATE UNIQUE INDEX uq_opportunity_competitors_winner ON opportunity_competitors (opportunity_id) WHERE is_winner;
+CREATE INDEX idx_opportunity_competitors_competitor ON opportunity_competitors (tenant_id, competitor_id);
+
+ALTER TABLE competitors ENABLE ROW LEVEL SECURITY;
+ALTER TABLE opportunity_competitors ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_competitors ON competitors
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_opportunity_competitors ON opportunity_competitors
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Seed the catalog from the free-text competitor recorded on past losses and link
+-- those deals to it as the winner.
+INSERT INTO competitors (tenant_id, name)
+SELECT DISTINCT l.tenant_id, btrim(l.competitor)
+FROM opportunity_losses l
+WHERE btrim(coalesce(l.competitor, '')) <> ''
+ON CONFLICT (tenant_id, name) DO NOTHING;
+
+INSERT INTO opportunity_competitors (tenant_id, opportunity_id, competitor_id, is_winner, created_by)
+SELECT l.tenant_id, l.opportunity_id, c.id, TRUE, l.created_by
+FROM opportunity_losses l
+JOIN competitors c ON c.tenant_id = l.tenant_id AND c.name = btrim(l.competitor)
+ON CONFLICT (opportunity_id, competitor_id) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+
+-- Row versions back the ETag / If-Match checks on PATCH endpoints. The trigger bumps
+-- the version on every UPDATE so writers cannot forget to.
+CREATE OR REPLACE FUNCTION bump_row_version() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$;
+
+ALTER TABLE accounts ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE contacts ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE opportunities ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE quotes ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE approval_requests ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER trg_accounts_version BEFORE UPDATE ON accounts
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER trg_contacts_version BEFORE UPDATE ON contacts
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER trg_opportunities_version BEFORE UPDATE ON opportunities
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER trg_quotes_version BEFORE UPDATE ON quotes
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+CREATE TRIGGER trg_approval_requests_version BEFORE UPDATE ON approval_requests
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+COMMIT;
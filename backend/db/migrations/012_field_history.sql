@@ -0,0 +1,59 @@
+BEGIN;
+
+CREATE TYPE change_source_enum AS ENUM ('api', 'csv_import', 'merge', 'system');
+
+-- One row per changed column. Values are stored as JSON so every column type fits.
+-- changed_by has no FK: it comes from app.user_id and must never block the write.
+CREATE TABLE field_changes (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  entity_type TEXT NOT NULL CHECK (entity_type IN ('account', 'contact', 'opportunity')),
+  entity_id UUID NOT NULL,
+  field_name TEXT NOT NULL,
+  old_value JSONB,
+  new_value JSONB,
+  changed_by UUID,
+  source change_source_enum NOT NULL DEFAULT 'system',
+  changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_field_changes_tenant_entity ON field_changes (tenant_id, entity_type, entity_id, changed_at DESC, id DESC);
+CREATE INDEX idx_field_changes_tenant_changed ON field_changes (tenant_id, changed_at);
+
+ALTER TABLE field_changes ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_field_changes ON field_changes
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Diffs OLD and NEW so that every writer (API, CSV import, merge, ad-hoc SQL) is
+-- covered. The actor and source come from app.user_id / app.change_source, which the
+-- application sets per transaction; unset means a system write.
+CREATE OR REPLACE FUNCTION record_field_changes() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+  skipped TEXT[] := ARRAY['id', 'tenant_id', 'created_by', 'created_at', 'updated_at', 'version'];
+  old_doc JSONB := to_jsonb(OLD) - skipped;
+  new_doc JSONB := to_jsonb(NEW) - skipped;
+  actor UUID := nullif(current_setting('app.user_id', true), '')::UUID;
+  change_source change_source_enum := coalesce(nullif(current_setting('app.change_source', true), ''), 'system')::change_source_enum;
+  field TEXT;
+BEGIN
+  FOR field IN SELECT jsonb_object_keys(new_doc) LOOP
+    IF old_doc -> field IS DISTINCT FROM new_doc -> field THEN
+      INSERT INTO field_changes (tenant_id, entity_type, entity_id, field_name, old_value, new_value, changed_by, source)
+      VALUES (NEW.tenant_id, TG_ARGV[0], NEW.id, field, old_doc -> field, new_doc -> field, actor, change_source);
+    END IF;
+  END LOOP;
+  RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER trg_accounts_field_changes AFTER UPDATE ON accounts
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('account');
+CREATE TRIGGER trg_contacts_field_changes AFTER UPDATE ON contacts
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('contact');
+CREATE TRIGGER trg_opportunities_field_changes AFTER UPDATE ON opportunities
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('opportunity');
+
+COMMIT;
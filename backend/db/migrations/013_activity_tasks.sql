@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TYPE task_priority_enum AS ENUM ('low', 'normal', 'high');
+
+-- completed_at marks an activity as done. Logged meetings, calls, emails and notes are
+-- done when they happen; tasks stay open until someone completes them.
+ALTER TABLE activities
+  ADD COLUMN assignee_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN due_at TIMESTAMPTZ,
+  ADD COLUMN priority task_priority_enum,
+  ADD COLUMN completed_at TIMESTAMPTZ,
+  ADD COLUMN completed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Backfill: existing non-task activities count as done. Existing tasks get their
+-- activity time as due date, the creator as assignee, and stay open.
+UPDATE activities
+SET completed_at = activity_at,
+    completed_by = created_by
+WHERE activity_type <> 'task';
+
+UPDATE activities
+SET due_at = activity_at,
+    assignee_user_id = created_by,
+    priority = 'normal'
+WHERE activity_type = 'task';
+
+ALTER TABLE activities ADD CONSTRAINT activities_task_fields_check CHECK (
+  activity_type = 'task'
+  OR (assignee_user_id IS NULL AND due_at IS NULL AND priority IS NULL)
+);
+ALTER TABLE activities ADD CONSTRAINT activities_task_priority_check CHECK (
+  activity_type <> 'task' OR priority IS NOT NULL
+);
+
+CREATE INDEX idx_activities_open_tasks ON activities (tenant_id, assignee_user_id, due_at)
+  WHERE activity_type = 'task' AND completed_at IS NULL;
+
+COMMIT;
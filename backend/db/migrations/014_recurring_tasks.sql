@@ -0,0 +1,58 @@
+BEGIN;
+
+-- Recurring tasks: every occurrence is its own activity row. Occurrences of one series
+-- share recurrence_series_id and the anchor (first due date), and recurrence_seq numbers
+-- them from 1 so the next due date never drifts (e.g. monthly on the 31st).
+ALTER TABLE activities
+  ADD COLUMN recurrence_rule TEXT,
+  ADD COLUMN recurrence_series_id UUID,
+  ADD COLUMN recurrence_anchor TIMESTAMPTZ,
+  ADD COLUMN recurrence_seq INT;
+
+ALTER TABLE activities ADD CONSTRAINT activities_recurrence_check CHECK (
+  recurrence_rule IS NULL
+  OR (activity_type = 'task' AND recurrence_series_id IS NOT NULL AND recurrence_anchor IS NOT NULL AND recurrence_seq >= 1)
+);
+
+CREATE UNIQUE INDEX uq_activities_recurrence_occurrence ON activities (recurrence_series_id, recurrence_seq)
+  WHERE recurrence_series_id IS NOT NULL;
+
+CREATE TABLE activity_templates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT,
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE activity_template_steps (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  template_id UUID NOT NULL REFERENCES activity_templates(id) ON DELETE CASCADE,
+  position INT NOT NULL,
+  subject TEXT NOT NULL,
+  detail TEXT,
+  offset_days INT NOT NULL DEFAULT 0 CHECK (offset_days >= 0),
+  priority task_priority_enum NOT NULL DEFAULT 'normal',
+  recurrence_rule TEXT,
+  UNIQUE (template_id, position)
+);
+
+CREATE INDEX idx_activity_template_steps_tenant_template ON activity_template_steps (tenant_id, template_id, position);
+
+ALTER TABLE activity_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE activity_template_steps ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_activity_templates ON activity_templates
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_activity_template_steps ON activity_template_steps
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+COMMIT;
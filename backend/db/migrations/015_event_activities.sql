@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Linked email/calendar events become email/meeting activities. external_event_id is the
+-- idempotency key (together with the activity type, which follows the integration type);
+-- source_event_id keeps the link back to the integration_events row.
+ALTER TABLE activities
+  ADD COLUMN source_event_id BIGINT REFERENCES integration_events(id) ON DELETE SET NULL,
+  ADD COLUMN external_event_id TEXT;
+
+ALTER TABLE activities ADD CONSTRAINT activities_external_event_check CHECK (
+  external_event_id IS NULL OR activity_type IN ('email', 'meeting')
+);
+
+CREATE UNIQUE INDEX uq_activities_external_event ON activities (tenant_id, activity_type, external_event_id)
+  WHERE external_event_id IS NOT NULL;
+CREATE INDEX idx_activities_source_event ON activities (source_event_id)
+  WHERE source_event_id IS NOT NULL;
+
+COMMIT;
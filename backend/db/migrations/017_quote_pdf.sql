@@ -0,0 +1,67 @@
+BEGIN;
+
+-- The billing location is printed as the addressee on quote PDFs. At most one per account.
+ALTER TABLE account_locations
+  ADD COLUMN is_billing BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX uq_account_locations_billing
+  ON account_locations (tenant_id, account_id)
+  WHERE is_billing;
+
+-- Letterhead and layout options for quote PDFs. version is bumped on every change so a
+-- stored PDF can tell whether it was rendered with the current template.
+CREATE TABLE quote_pdf_templates (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  language TEXT NOT NULL DEFAULT 'ja' CHECK (language IN ('ja', 'en')),
+  company_name TEXT NOT NULL,
+  company_address TEXT,
+  company_phone TEXT,
+  registration_number TEXT CHECK (registration_number ~ '^T[0-9]{13}$'),
+  accent_color TEXT NOT NULL DEFAULT '#1F4E79' CHECK (accent_color ~ '^#[0-9A-Fa-f]{6}$'),
+  footer_text TEXT,
+  is_default BOOLEAN NOT NULL DEFAULT false,
+  version BIGINT NOT NULL DEFAULT 1,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+CREATE UNIQUE INDEX uq_quote_pdf_templates_default
+  ON quote_pdf_templates (tenant_id)
+  WHERE is_default;
+
+-- Every rendered PDF is kept; version counts up per quote.
+CREATE TABLE quote_documents (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  quote_id UUID NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+  version INT NOT NULL CHECK (version > 0),
+  quote_version BIGINT NOT NULL,
+  template_id UUID REFERENCES quote_pdf_templates(id) ON DELETE SET NULL,
+  template_version BIGINT,
+  file_name TEXT NOT NULL,
+  content BYTEA NOT NULL,
+  size_bytes INT NOT NULL,
+  sha256 TEXT NOT NULL,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (quote_id, version)
+);
+
+CREATE INDEX idx_quote_documents_tenant_quote ON quote_documents (tenant_id, quote_id, version DESC);
+
+ALTER TABLE quote_pdf_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE quote_documents ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_quote_pdf_templates ON quote_pdf_templates
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_quote_documents ON quote_documents
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+COMMIT;
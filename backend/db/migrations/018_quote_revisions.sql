@@ -0,0 +1,18 @@
+BEGIN;
+
+-- A revision chain starts at the original quote (root_quote_id NULL, revision 1). Each
+-- revision points at the root and at the quote it replaced; the replaced quote becomes
+-- 'superseded' so only the latest revision can be sent, accepted or closed won.
+ALTER TYPE quote_status_enum ADD VALUE IF NOT EXISTS 'superseded';
+
+ALTER TABLE quotes
+  ADD COLUMN root_quote_id UUID REFERENCES quotes(id) ON DELETE CASCADE,
+  ADD COLUMN previous_quote_id UUID REFERENCES quotes(id) ON DELETE SET NULL,
+  ADD COLUMN revision INT NOT NULL DEFAULT 1 CHECK (revision >= 1),
+  ADD COLUMN superseded_at TIMESTAMPTZ,
+  ADD CONSTRAINT quotes_revision_root_check CHECK ((revision = 1) = (root_quote_id IS NULL));
+
+CREATE UNIQUE INDEX uq_quotes_revision ON quotes ((coalesce(root_quote_id, id)), revision);
+CREATE INDEX idx_quotes_root ON quotes (tenant_id, root_quote_id) WHERE root_quote_id IS NOT NULL;
+
+COMMIT;
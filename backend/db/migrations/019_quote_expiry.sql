@@ -0,0 +1,50 @@
+BEGIN;
+
+ALTER TABLE quotes ADD COLUMN expired_at TIMESTAMPTZ;
+
+CREATE INDEX idx_quotes_sent_valid_until ON quotes (tenant_id, valid_until) WHERE status = 'sent';
+
+-- One row per quote and validity date the owner was reminded about. The expiry job claims
+-- a reminder by inserting it, so each one is sent once even with several API replicas;
+-- moving valid_until makes the quote eligible for a new reminder.
+CREATE TABLE quote_expiry_reminders (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  quote_id UUID NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+  valid_until DATE NOT NULL,
+  notified_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (quote_id, valid_until)
+);
+
+ALTER TABLE quote_expiry_reminders ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_quote_expiry_reminders ON quote_expiry_reminders
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- In-app notifications for a single user. Background jobs write them; users list and
+-- mark them read through the API.
+CREATE TABLE notifications (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind TEXT NOT NULL CHECK (kind IN ('quote_expiring', 'quote_expired')),
+  entity_type TEXT NOT NULL,
+  entity_id UUID NOT NULL,
+  title TEXT NOT NULL,
+  body TEXT NOT NULL DEFAULT '',
+  data JSONB NOT NULL DEFAULT '{}'::jsonb,
+  read_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notifications_tenant_user ON notifications (tenant_id, user_id, created_at DESC, id DESC);
+CREATE INDEX idx_notifications_tenant_user_unread ON notifications (tenant_id, user_id) WHERE read_at IS NULL;
+
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_notifications ON notifications
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+COMMIT;
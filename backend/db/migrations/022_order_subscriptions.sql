@@ -0,0 +1,49 @@
+BEGIN;
+
+-- Subscription orders carry a service term and a billing frequency. One-time orders
+-- without a term are recognized and billed in the month they were ordered.
+CREATE TYPE billing_frequency_enum AS ENUM ('one_time', 'monthly', 'quarterly', 'semi_annual', 'annual');
+
+ALTER TABLE orders
+  ADD COLUMN term_start DATE,
+  ADD COLUMN term_end DATE,
+  ADD COLUMN billing_frequency billing_frequency_enum NOT NULL DEFAULT 'one_time',
+  ADD CONSTRAINT orders_term_check CHECK (
+    (term_start IS NULL AND term_end IS NULL AND billing_frequency = 'one_time')
+    OR (term_start IS NOT NULL AND term_end IS NOT NULL AND term_end >= term_start)
+  );
+
+-- One row per order and calendar month. revenue_amount spreads the order amount over the
+-- term by days; billing_amount falls in the month each billing period starts. Both add up
+-- to the order amount exactly: rounding differences go to the later months.
+CREATE TABLE order_revenue_schedules (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+  period_month DATE NOT NULL CHECK (period_month = date_trunc('month', period_month)::date),
+  revenue_amount NUMERIC(14,2) NOT NULL,
+  billing_amount NUMERIC(14,2) NOT NULL,
+  currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (order_id, period_month)
+);
+
+ALTER TABLE order_revenue_schedules ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_order_revenue_schedules ON order_revenue_schedules
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE INDEX idx_order_revenue_schedules_tenant_month ON order_revenue_schedules (tenant_id, period_month);
+
+-- Existing orders are one-time orders.
+INSERT INTO order_revenue_schedules (tenant_id, order_id, period_month, revenue_amount, billing_amount, currency)
+SELECT
+  tenant_id,
+  id,
+  date_trunc('month', coalesce(ordered_on, created_at::date))::date,
+  amount,
+  amount,
+  currency
+FROM orders;
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Record inserts too, so records brought in by a CSV import (which only creates rows)
+-- show up in history with source csv_import. An insert writes one row per non-empty
+-- field with old_value NULL.
+CREATE OR REPLACE FUNCTION record_field_changes() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+  skipped TEXT[] := ARRAY['id', 'tenant_id', 'created_by', 'created_at', 'updated_at', 'version'];
+  old_doc JSONB := CASE WHEN TG_OP = 'UPDATE' THEN to_jsonb(OLD) - skipped ELSE '{}'::jsonb END;
+  new_doc JSONB := to_jsonb(NEW) - skipped;
+  actor UUID := nullif(current_setting('app.user_id', true), '')::UUID;
+  change_source change_source_enum := coalesce(nullif(current_setting('app.change_source', true), ''), 'system')::change_source_enum;
+  field TEXT;
+BEGIN
+  FOR field IN SELECT jsonb_object_keys(new_doc) LOOP
+    IF TG_OP = 'INSERT' AND new_doc -> field = 'null'::jsonb THEN
+      CONTINUE;
+    END IF;
+    IF old_doc -> field IS DISTINCT FROM new_doc -> field THEN
+      INSERT INTO field_changes (tenant_id, entity_type, entity_id, field_name, old_value, new_value, changed_by, source)
+      VALUES (NEW.tenant_id, TG_ARGV[0], NEW.id, field, old_doc -> field, new_doc -> field, actor, change_source);
+    END IF;
+  END LOOP;
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER trg_accounts_field_changes ON accounts;
+DROP TRIGGER trg_contacts_field_changes ON contacts;
+DROP TRIGGER trg_opportunities_field_changes ON opportunities;
+
+CREATE TRIGGER trg_accounts_field_changes AFTER INSERT OR UPDATE ON accounts
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('account');
+CREATE TRIGGER trg_contacts_field_changes AFTER INSERT OR UPDATE ON contacts
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('contact');
+CREATE TRIGGER trg_opportunities_field_changes AFTER INSERT OR UPDATE ON opportunities
+  FOR EACH ROW EXECUTE FUNCTION record_field_changes('opportunity');
+
+COMMIT;
@@ -0,0 +1,49 @@
+BEGIN;
+
+-- Document-number periods (yearly, monthly) follow the tenant's calendar, not UTC.
+ALTER TABLE tenants
+  ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'Asia/Tokyo';
+
+-- line_total used to round to 2 decimals whatever the currency, so JPY lines could
+-- carry fractional yen into the header amounts. Each line now records the currency of
+-- its parent and rounds with currency_minor_units, as round_tax does.
+ALTER TABLE line_items
+  ADD COLUMN currency TEXT NOT NULL DEFAULT 'JPY' CHECK (currency ~ '^[A-Z]{3}$');
+
+UPDATE line_items li
+SET currency = coalesce(
+  (SELECT o.currency FROM opportunities o WHERE o.id = li.opportunity_id),
+  (SELECT q.currency FROM quotes q WHERE q.id = li.quote_id),
+  (SELECT od.currency FROM orders od WHERE od.id = li.order_id),
+  li.currency
+);
+
+ALTER TABLE line_items DROP COLUMN line_total;
+ALTER TABLE line_items
+  ADD COLUMN line_total NUMERIC(14,2) GENERATED ALWAYS AS (
+    round(quantity * unit_price * (1 - discount_percent / 100), currency_minor_units(currency))
+  ) STORED;
+
+-- line_items_set_currency copies the parent's currency, so callers never pass it.
+-- Generated columns are computed after BEFORE triggers, so line_total sees it.
+CREATE FUNCTION line_items_set_currency() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+  NEW.currency := coalesce(
+    (SELECT o.currency FROM opportunities o WHERE o.id = NEW.opportunity_id),
+    (SELECT q.currency FROM quotes q WHERE q.id = NEW.quote_id),
+    (SELECT od.currency FROM orders od WHERE od.id = NEW.order_id),
+    NEW.currency
+  );
+  RETURN NEW;
+END;
+$$;
+
+CREATE TRIGGER trg_line_items_set_currency
+BEFORE INSERT OR UPDATE OF opportunity_id, quote_id, order_id ON line_items
+FOR EACH ROW EXECUTE FUNCTION line_items_set_currency();
+
+-- Header amounts already stored are not recomputed here; they follow the new line
+-- totals on the next line item change, like a tax rounding change.
+
+COMMIT;
@@ -110,7 +110,8 @@ INSERT INTO account_locations (
   prefecture,
   city,
   address_line1,
-  address_line2
+  address_line2,
+  is_billing
 ) VALUES (
   sqlc.arg(tenant_id),
   sqlc.arg(account_id),
@@ -120,6 +121,260 @@ INSERT INTO account_locations (
   sqlc.narg(prefecture),
   sqlc.narg(city),
   sqlc.narg(address_line1),
-  sqlc.narg(address_line2)
+  sqlc.narg(address_line2),
+  sqlc.arg(is_billing)
 )
 RETURNING *;
+
+-- Run before flagging another billing location so the partial unique index holds.
+-- name: ClearBillingLocation :exec
+UPDATE account_locations
+SET is_billing = false,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND account_id = sqlc.arg(account_id)
+  AND is_billing;
+
+-- name: GetAccount :one
+SELECT *
+FROM accounts
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(account_id);
+
+-- ListAccountTimeline merges the account's feed newest first. visible_to narrows the
+-- opportunity-bound items to deals the user owns or is on the team of (sales users).
+-- Quote and order entries are their status changes, read from audit_logs; those audit
+-- rows are not repeated as audit_log items.
+-- name: ListAccountTimeline :many
+WITH account_opportunities AS (
+  SELECT o.id
+  FROM opportunities o
+  WHERE o.tenant_id = sqlc.arg(tenant_id)
+    AND o.account_id = sqlc.arg(account_id)
+    AND (
+      sqlc.narg(visible_to)::uuid IS NULL
+      OR o.owner_user_id = sqlc.narg(visible_to)
+      OR o.id IN (
+        SELECT t.opportunity_id FROM opportunity_team_members t
+        WHERE t.user_id = sqlc.narg(visible_to)
+      )
+    )
+),
+account_contacts AS (
+  SELECT c.id
+  FROM contacts c
+  WHERE c.tenant_id = sqlc.arg(tenant_id)
+    AND c.account_id = sqlc.arg(account_id)
+),
+account_quotes AS (
+  SELECT q.id
+  FROM quotes q
+  WHERE q.tenant_id = sqlc.arg(tenant_id)
+    AND q.opportunity_id IN (SELECT id FROM account_opportunities)
+),
+account_orders AS (
+  SELECT od.id
+  FROM orders od
+  WHERE od.tenant_id = sqlc.arg(tenant_id)
+    AND od.opportunity_id IN (SELECT id FROM account_opportunities)
+),
+status_audits AS (
+  SELECT al.*
+  FROM audit_logs al
+  WHERE al.tenant_id = sqlc.arg(tenant_id)
+    AND al.entity_type IN ('quote', 'order')
+    AND (al.action = 'create' OR al.metadata ->> 'event' IN ('status_changed', 'status_change'))
+),
+timeline AS (
+  SELECT
+    'activity'::text AS item_type,
+    a.id::text AS item_id,
+    a.activity_at AS occurred_at,
+    a.opportunity_id AS opportunity_id,
+    'opportunity'::text AS entity_type,
+    a.opportunity_id AS entity_id,
+    a.activity_type::text AS kind,
+    a.subject AS title,
+    a.detail AS detail,
+    a.created_by AS actor_user_id,
+    CASE
+      WHEN a.source_event_id IS NOT NULL THEN jsonb_build_object('sourceEventId', a.source_event_id, 'externalEventId', a.external_event_id)
+      ELSE '{}'::jsonb
+    END AS metadata
+  FROM activities a
+  WHERE a.tenant_id = sqlc.arg(tenant_id)
+    AND a.opportunity_id IN (SELECT id FROM account_opportunities)
+  UNION ALL
+  SELECT
+    'integration_event'::text,
+    e.id::text,
+    e.occurred_at,
+    e.linked_opportunity_id,
+    CASE
+      WHEN e.linked_contact_id IS NOT NULL THEN 'contact'
+      ELSE 'account'
+    END,
+    coalesce(e.linked_contact_id, e.linked_account_id, sqlc.arg(account_id)),
+    e.integration_type::text,
+    e.event_type,
+    NULL::text,
+    NULL::uuid,
+    e.payload
+  FROM integration_events e
+  WHERE e.tenant_id = sqlc.arg(tenant_id)
+    AND (
+      e.linked_account_id = sqlc.arg(account_id)
+      OR e.linked_contact_id IN (SELECT id FROM account_contacts)
+      OR e.linked_opportunity_id IN (SELECT id FROM account_opportunities)
+    )
+    AND (e.linked_opportunity_id IS NULL OR e.linked_opportunity_id IN (SELECT id FROM account_opportunities))
+    -- Events already converted into activities are shown once, as the activity.
+    AND NOT EXISTS (
+      SELECT 1
+      FROM activities ea
+      WHERE ea.tenant_id = e.tenant_id
+        AND ea.source_event_id = e.id
+    )
+  UNION ALL
+  SELECT
+    'quote'::text,
+    sa.id::text,
+    sa.created_at,
+    q.opportunity_id,
+    'quote'::text,
+    q.id,
+    CASE WHEN sa.action = 'create' THEN 'draft' ELSE sa.metadata ->> 'toStatus' END,
+    q.quote_no,
+    sa.metadata ->> 'reason',
+    sa.actor_user_id,
+    jsonb_strip_nulls(jsonb_build_object(
+      'fromStatus', sa.metadata -> 'fromStatus',
+      'toStatus', coalesce(sa.metadata -> 'toStatus', to_jsonb('draft'::text)),
+      'revision', q.revision
+    ))
+  FROM status_audits sa
+  JOIN quotes q ON q.tenant_id = sa.tenant_id AND q.id = sa.entity_id
+  WHERE sa.entity_type = 'quote'
+    AND q.id IN (SELECT id FROM account_quotes)
+  UNION ALL
+  SELECT
+    'order'::text,
+    sa.id::text,
+    sa.created_at,
+    od.opportunity_id,
+    'order'::text,
+    od.id,
+    CASE WHEN sa.action = 'create' THEN 'pending' ELSE sa.metadata ->> 'toStatus' END,
+    od.order_no,
+    sa.metadata ->> 'reason',
+    sa.actor_user_id,
+    jsonb_strip_nulls(jsonb_build_object(
+      'fromStatus', sa.metadata -> 'fromStatus',
+      'toStatus', coalesce(sa.metadata -> 'toStatus', to_jsonb('pending'::text))
+    ))
+  FROM status_audits sa
+  JOIN orders od ON od.tenant_id = sa.tenant_id AND od.id = sa.entity_id
+  WHERE sa.entity_type = 'order'
+    AND od.id IN (SELECT id FROM account_orders)
+  UNION ALL
+  SELECT
+    'approval'::text,
+    ar.id::text,
+    coalesce(ar.decided_at, ar.created_at),
+    NULL::uuid,
+    ar.entity_type,
+    ar.entity_id,
+    ar.status::text,
+    ar.reason,
+    ar.decision_note,
+    ar.requested_by,
+    jsonb_build_object('approverUserId', ar.approver_user_id)
+  FROM approval_requests ar
+  WHERE ar.tenant_id = sqlc.arg(tenant_id)
+    AND (
+      ar.entity_id IN (SELECT id FROM account_opportunities)
+      OR ar.entity_id IN (SELECT id FROM account_quotes)
+      OR ar.entity_id IN (SELECT id FROM account_orders)
+      OR (ar.entity_type = 'account_ownership_transfer' AND ar.entity_id = sqlc.arg(account_id))
+    )
+  UNION ALL
+  SELECT
+    'audit_log'::text,
+    al.id::text,
+    al.created_at,
+    NULL::uuid,
+    coalesce(al.entity_type, ''),
+    al.entity_id,
+    al.action::text,
+    coalesce(al.entity_type, '') || ':' || al.action::text,
+    NULL::text,
+    al.actor_user_id,
+    al.metadata
+  FROM audit_logs al
+  WHERE al.tenant_id = sqlc.arg(tenant_id)
+    AND (
+      al.entity_id = sqlc.arg(account_id)
+      OR al.entity_id IN (SELECT id FROM account_contacts)
+      OR al.entity_id IN (SELECT id FROM account_opportunities)
+      OR al.entity_id IN (SELECT id FROM account_quotes)
+      OR al.entity_id IN (SELECT id FROM account_orders)
+    )
+    AND al.id NOT IN (SELECT id FROM status_audits)
+)
+SELECT
+  t.item_type,
+  t.item_id,
+  t.occurred_at,
+  t.opportunity_id,
+  t.entity_type,
+  t.entity_id,
+  t.kind,
+  t.title,
+  t.detail,
+  t.actor_user_id,
+  t.metadata
+FROM timeline t
+WHERE (sqlc.narg(item_types)::text[] IS NULL OR t.item_type = ANY(sqlc.narg(item_types)::text[]))
+  AND (
+    sqlc.narg(cursor_at)::timestamptz IS NULL
+    OR (t.occurred_at, t.item_type, t.item_id) < (sqlc.narg(cursor_at)::timestamptz, sqlc.narg(cursor_type)::text, sqlc.narg(cursor_id)::text)
+  )
+ORDER BY t.occurred_at DESC, t.item_type DESC, t.item_id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: GetAccountForUpdate :one
+SELECT *
+FROM accounts
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(account_id)
+FOR UPDATE;
+
+-- name: GetContact :one
+SELECT *
+FROM contacts
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(contact_id);
+
+-- name: GetContactForUpdate :one
+SELECT *
+FROM contacts
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(contact_id)
+FOR UPDATE;
+
+-- name: UpdateContact :one
+UPDATE contacts
+SET
+  location_id = coalesce(sqlc.narg(location_id), location_id),
+  owner_user_id = coalesce(sqlc.narg(owner_user_id), owner_user_id),
+  full_name = coalesce(sqlc.narg(full_name), full_name),
+  department = coalesce(sqlc.narg(department), department),
+  title = coalesce(sqlc.narg(title), title),
+  email = coalesce(sqlc.narg(email), email),
+  phone = coalesce(sqlc.narg(phone), phone),
+  is_primary = coalesce(sqlc.narg(is_primary)::boolean, is_primary),
+  memo = coalesce(sqlc.narg(memo), memo),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(contact_id)
+RETURNING *;
@@ -0,0 +1,246 @@
+-- name: ListActivitiesByOpportunity :many
+SELECT *
+FROM activities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND (sqlc.narg(activity_type)::activity_type_enum IS NULL OR activity_type = sqlc.narg(activity_type)::activity_type_enum)
+  AND (sqlc.narg(completed)::boolean IS NULL OR (completed_at IS NOT NULL) = sqlc.narg(completed)::boolean)
+ORDER BY activity_at DESC, id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: CountActivitiesByOpportunity :one
+SELECT count(*)::bigint
+FROM activities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND (sqlc.narg(activity_type)::activity_type_enum IS NULL OR activity_type = sqlc.narg(activity_type)::activity_type_enum)
+  AND (sqlc.narg(completed)::boolean IS NULL OR (completed_at IS NOT NULL) = sqlc.narg(completed)::boolean);
+
+-- name: GetActivityForUpdate :one
+SELECT *
+FROM activities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(activity_id)
+FOR UPDATE;
+
+-- name: CreateActivity :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  assignee_user_id,
+  due_at,
+  priority,
+  completed_at,
+  completed_by,
+  recurrence_rule,
+  recurrence_series_id,
+  recurrence_anchor,
+  recurrence_seq,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(opportunity_id),
+  sqlc.arg(activity_type),
+  sqlc.arg(subject),
+  sqlc.narg(detail),
+  sqlc.arg(activity_at),
+  sqlc.narg(assignee_user_id),
+  sqlc.narg(due_at),
+  sqlc.narg(priority),
+  sqlc.narg(completed_at),
+  sqlc.narg(completed_by),
+  sqlc.narg(recurrence_rule),
+  sqlc.narg(recurrence_series_id),
+  sqlc.narg(recurrence_anchor),
+  sqlc.narg(recurrence_seq),
+  sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- Returns no row when the occurrence already exists (task completed, reopened and
+-- completed again).
+-- name: CreateNextOccurrence :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  assignee_user_id,
+  due_at,
+  priority,
+  recurrence_rule,
+  recurrence_series_id,
+  recurrence_anchor,
+  recurrence_seq,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(opportunity_id),
+  'task',
+  sqlc.arg(subject),
+  sqlc.narg(detail),
+  sqlc.arg(due_at),
+  sqlc.narg(assignee_user_id),
+  sqlc.arg(due_at),
+  sqlc.arg(priority),
+  sqlc.arg(recurrence_rule),
+  sqlc.arg(recurrence_series_id),
+  sqlc.arg(recurrence_anchor),
+  sqlc.arg(recurrence_seq),
+  sqlc.arg(created_by)
+)
+ON CONFLICT (recurrence_series_id, recurrence_seq) WHERE recurrence_series_id IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- Setting a rule starts a new series anchored at the task's current due date; a NULL
+-- rule stops the recurrence and keeps the series columns for history.
+-- name: SetActivityRecurrence :one
+UPDATE activities
+SET
+  recurrence_rule = sqlc.narg(recurrence_rule),
+  recurrence_series_id = CASE WHEN sqlc.narg(recurrence_rule)::text IS NULL THEN recurrence_series_id ELSE sqlc.arg(series_id)::uuid END,
+  recurrence_anchor = CASE WHEN sqlc.narg(recurrence_rule)::text IS NULL THEN recurrence_anchor ELSE due_at END,
+  recurrence_seq = CASE WHEN sqlc.narg(recurrence_rule)::text IS NULL THEN recurrence_seq ELSE 1 END,
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(activity_id)
+RETURNING *;
+
+-- completed: NULL leaves completion alone, true completes (keeping an earlier
+-- completion time), false reopens.
+-- name: UpdateActivity :one
+UPDATE activities
+SET
+  subject = coalesce(sqlc.narg(subject), subject),
+  detail = coalesce(sqlc.narg(detail), detail),
+  activity_at = coalesce(sqlc.narg(activity_at), activity_at),
+  assignee_user_id = coalesce(sqlc.narg(assignee_user_id), assignee_user_id),
+  due_at = coalesce(sqlc.narg(due_at), due_at),
+  priority = coalesce(sqlc.narg(priority), priority),
+  completed_at = CASE
+    WHEN sqlc.narg(completed)::boolean IS NULL THEN completed_at
+    WHEN sqlc.narg(completed)::boolean THEN coalesce(completed_at, now())
+    ELSE NULL
+  END,
+  completed_by = CASE
+    WHEN sqlc.narg(completed)::boolean IS NULL THEN completed_by
+    WHEN sqlc.narg(completed)::boolean THEN coalesce(completed_by, sqlc.arg(actor_user_id))
+    ELSE NULL
+  END,
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(activity_id)
+RETURNING *;
+
+-- name: DeleteActivity :execrows
+DELETE FROM activities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(activity_id);
+
+-- name: ListOpenTasksByAssignee :many
+SELECT
+  a.*,
+  o.name AS opportunity_name,
+  o.account_id
+FROM activities a
+JOIN opportunities o ON o.id = a.opportunity_id
+WHERE a.tenant_id = sqlc.arg(tenant_id)
+  AND a.activity_type = 'task'
+  AND a.completed_at IS NULL
+  AND a.assignee_user_id = sqlc.arg(assignee_user_id)
+  AND (NOT sqlc.arg(overdue_only)::boolean OR a.due_at < now())
+  AND (sqlc.narg(due_before)::timestamptz IS NULL OR a.due_at < sqlc.narg(due_before)::timestamptz)
+ORDER BY a.due_at ASC NULLS LAST, a.priority DESC, a.id
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: CountOpenTasksByAssignee :one
+SELECT
+  count(*)::bigint AS open_count,
+  count(*) FILTER (WHERE a.due_at < now())::bigint AS overdue_count
+FROM activities a
+WHERE a.tenant_id = sqlc.arg(tenant_id)
+  AND a.activity_type = 'task'
+  AND a.completed_at IS NULL
+  AND a.assignee_user_id = sqlc.arg(assignee_user_id)
+  AND (NOT sqlc.arg(overdue_only)::boolean OR a.due_at < now())
+  AND (sqlc.narg(due_before)::timestamptz IS NULL OR a.due_at < sqlc.narg(due_before)::timestamptz);
+
+-- Converts one linked integration event into an email or meeting activity owned by the
+-- opportunity owner. Future meetings stay open; re-running completes them once they have
+-- happened. Returns no row when the event is not convertible or nothing changed.
+-- name: UpsertActivityFromEvent :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  completed_at,
+  source_event_id,
+  external_event_id,
+  created_by
+)
+SELECT
+  e.tenant_id,
+  e.linked_opportunity_id,
+  CASE e.integration_type WHEN 'email' THEN 'email' ELSE 'meeting' END::activity_type_enum,
+  coalesce(
+    nullif(btrim(e.payload->>'subject'), ''),
+    nullif(btrim(e.payload->>'title'), ''),
+    nullif(btrim(e.payload->>'summary'), ''),
+    e.event_type
+  ),
+  coalesce(nullif(e.payload->>'snippet', ''), nullif(e.payload->>'description', '')),
+  e.occurred_at,
+  CASE WHEN e.occurred_at <= now() THEN e.occurred_at END,
+  e.id,
+  e.external_event_id,
+  o.owner_user_id
+FROM integration_events e
+JOIN opportunities o ON o.tenant_id = e.tenant_id AND o.id = e.linked_opportunity_id
+WHERE e.tenant_id = sqlc.arg(tenant_id)
+  AND e.id = sqlc.arg(event_id)
+  AND e.external_event_id IS NOT NULL
+ON CONFLICT (tenant_id, activity_type, external_event_id) WHERE external_event_id IS NOT NULL DO UPDATE
+SET
+  completed_at = coalesce(activities.completed_at, EXCLUDED.completed_at),
+  source_event_id = coalesce(activities.source_event_id, EXCLUDED.source_event_id),
+  updated_at = now()
+WHERE (activities.completed_at IS NULL AND EXCLUDED.completed_at IS NOT NULL)
+  OR activities.source_event_id IS NULL
+RETURNING *;
+
+-- Events that still need UpsertActivityFromEvent: linked, keyed, and either without an
+-- activity or with a past meeting still open. Keyset-paged by event id.
+-- name: ListPendingEventActivities :many
+SELECT e.id
+FROM integration_events e
+WHERE e.tenant_id = sqlc.arg(tenant_id)
+  AND e.id > sqlc.arg(after_id)
+  AND e.linked_opportunity_id IS NOT NULL
+  AND e.external_event_id IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1
+    FROM activities a
+    WHERE a.tenant_id = e.tenant_id
+      AND a.external_event_id = e.external_event_id
+      AND a.activity_type = CASE e.integration_type WHEN 'email' THEN 'email' ELSE 'meeting' END::activity_type_enum
+      AND a.source_event_id IS NOT NULL
+      AND (a.completed_at IS NOT NULL OR e.occurred_at > now())
+  )
+ORDER BY e.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListTenantIDs :many
+SELECT id
+FROM tenants
+ORDER BY id;
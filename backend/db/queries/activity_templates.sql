@@ -0,0 +1,72 @@
+-- name: ListActivityTemplates :many
+SELECT
+  t.*,
+  (SELECT count(*) FROM activity_template_steps s WHERE s.template_id = t.id)::bigint AS step_count
+FROM activity_templates t
+WHERE t.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::boolean IS NULL OR t.is_active = sqlc.narg(is_active)::boolean)
+ORDER BY t.name;
+
+-- name: GetActivityTemplate :one
+SELECT *
+FROM activity_templates
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(template_id);
+
+-- name: CreateActivityTemplate :one
+INSERT INTO activity_templates (
+  tenant_id,
+  name,
+  description,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  sqlc.narg(description),
+  sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: UpdateActivityTemplate :one
+UPDATE activity_templates
+SET
+  name = coalesce(sqlc.narg(name), name),
+  description = coalesce(sqlc.narg(description), description),
+  is_active = coalesce(sqlc.narg(is_active), is_active),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(template_id)
+RETURNING *;
+
+-- name: ListActivityTemplateSteps :many
+SELECT *
+FROM activity_template_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND template_id = sqlc.arg(template_id)
+ORDER BY position;
+
+-- name: DeleteActivityTemplateSteps :exec
+DELETE FROM activity_template_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND template_id = sqlc.arg(template_id);
+
+-- name: CreateActivityTemplateStep :exec
+INSERT INTO activity_template_steps (
+  tenant_id,
+  template_id,
+  position,
+  subject,
+  detail,
+  offset_days,
+  priority,
+  recurrence_rule
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(template_id),
+  sqlc.arg(position),
+  sqlc.arg(subject),
+  sqlc.narg(detail),
+  sqlc.arg(offset_days),
+  sqlc.arg(priority),
+  sqlc.narg(recurrence_rule)
+);
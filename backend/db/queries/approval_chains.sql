@@ -0,0 +1,400 @@
+-- name: ListApprovalChains :many
+SELECT *
+FROM approval_chains
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (NOT sqlc.arg(active_only)::boolean OR is_active)
+ORDER BY name;
+
+-- name: GetApprovalChain :one
+SELECT *
+FROM approval_chains
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(chain_id);
+
+-- name: GetApprovalChainForUpdate :one
+SELECT *
+FROM approval_chains
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(chain_id)
+FOR UPDATE;
+
+-- name: CreateApprovalChain :one
+INSERT INTO approval_chains (
+  tenant_id,
+  name,
+  description,
+  is_active,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  sqlc.narg(description),
+  sqlc.arg(is_active),
+  sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: UpdateApprovalChain :one
+UPDATE approval_chains
+SET
+  name = coalesce(sqlc.narg(name), name),
+  description = coalesce(sqlc.narg(description), description),
+  is_active = coalesce(sqlc.narg(is_active), is_active),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(chain_id)
+RETURNING *;
+
+-- name: ListApprovalChainSteps :many
+SELECT *
+FROM approval_chain_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND chain_id = ANY(sqlc.arg(chain_ids)::uuid[])
+ORDER BY chain_id, step_order;
+
+-- name: DeleteApprovalChainSteps :exec
+DELETE FROM approval_chain_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND chain_id = sqlc.arg(chain_id);
+
+-- name: CreateApprovalChainStep :one
+INSERT INTO approval_chain_steps (
+  tenant_id,
+  chain_id,
+  step_order,
+  name,
+  approver_type,
+  approver_user_id,
+  approver_role,
+  approver_team_role,
+  min_value,
+  sla_hours,
+  escalation_user_id
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(chain_id),
+  sqlc.arg(step_order),
+  sqlc.arg(name),
+  sqlc.arg(approver_type),
+  sqlc.narg(approver_user_id),
+  sqlc.narg(approver_role),
+  sqlc.narg(approver_team_role),
+  sqlc.narg(min_value),
+  sqlc.narg(sla_hours),
+  sqlc.narg(escalation_user_id)
+)
+RETURNING *;
+
+-- name: ListActiveMembersByRole :many
+SELECT m.user_id
+FROM memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.tenant_id = sqlc.arg(tenant_id)
+  AND m.role = sqlc.arg(role)
+  AND m.is_active
+  AND u.is_active
+ORDER BY u.display_name, m.user_id;
+
+-- name: ListOpportunityTeamMembersByRole :many
+SELECT t.user_id
+FROM opportunity_team_members t
+JOIN memberships m ON m.tenant_id = t.tenant_id AND m.user_id = t.user_id AND m.is_active
+WHERE t.tenant_id = sqlc.arg(tenant_id)
+  AND t.opportunity_id = sqlc.arg(opportunity_id)
+  AND t.team_role = sqlc.arg(team_role)
+ORDER BY t.created_at, t.user_id;
+
+-- The opportunity an approval subject belongs to; NULL for subjects outside the deal.
+-- name: GetApprovalEntityOpportunity :one
+SELECT coalesce(
+  (SELECT q.opportunity_id FROM quotes q
+   WHERE sqlc.arg(entity_type)::text = 'quote' AND q.tenant_id = sqlc.arg(tenant_id) AND q.id = sqlc.arg(entity_id)),
+  (SELECT od.opportunity_id FROM orders od
+   WHERE sqlc.arg(entity_type)::text = 'order' AND od.tenant_id = sqlc.arg(tenant_id) AND od.id = sqlc.arg(entity_id)),
+  (SELECT o.id FROM opportunities o
+   WHERE sqlc.arg(entity_type)::text = 'opportunity_discount' AND o.tenant_id = sqlc.arg(tenant_id) AND o.id = sqlc.arg(entity_id))
+)::uuid AS opportunity_id;
+
+-- Summaries of approval subjects, matched pairwise from the two arrays. Subjects that
+-- do not exist in the tenant, or whose type is not registered, are left out.
+-- name: ListApprovalEntitySummaries :many
+WITH e AS (
+  SELECT t.entity_type, i.entity_id
+  FROM unnest(sqlc.arg(entity_types)::text[]) WITH ORDINALITY AS t(entity_type, n)
+  JOIN unnest(sqlc.arg(entity_ids)::uuid[]) WITH ORDINALITY AS i(entity_id, n) ON i.n = t.n
+)
+SELECT e.entity_type::text AS entity_type, e.entity_id::uuid AS entity_id, s.summary::jsonb AS summary
+FROM e
+CROSS JOIN LATERAL (
+  SELECT jsonb_build_object(
+    'quoteNo', q.quote_no, 'revision', q.revision, 'status', q.status,
+    'totalAmount', q.total_amount, 'currency', q.currency, 'validUntil', q.valid_until,
+    'opportunityId', o.id, 'opportunityName', o.name, 'accountName', a.name
+  ) AS summary
+  FROM quotes q
+  JOIN opportunities o ON o.tenant_id = q.tenant_id AND o.id = q.opportunity_id
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'quote' AND q.tenant_id = sqlc.arg(tenant_id) AND q.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'orderNo', od.order_no, 'status', od.status, 'amount', od.amount, 'currency', od.currency,
+    'orderedOn', od.ordered_on, 'opportunityId', o.id, 'opportunityName', o.name, 'accountName', a.name
+  )
+  FROM orders od
+  JOIN opportunities o ON o.tenant_id = od.tenant_id AND o.id = od.opportunity_id
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'order' AND od.tenant_id = sqlc.arg(tenant_id) AND od.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'name', o.name, 'stage', o.stage, 'amount', o.amount, 'currency', o.currency,
+    'expectedCloseDate', o.expected_close_date, 'ownerUserId', o.owner_user_id, 'accountName', a.name
+  )
+  FROM opportunities o
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'opportunity_discount' AND o.tenant_id = sqlc.arg(tenant_id) AND o.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'name', a.name, 'status', a.status, 'ownerUserId', a.owner_user_id, 'ownerName', u.display_name
+  )
+  FROM accounts a
+  LEFT JOIN users u ON u.id = a.owner_user_id
+  WHERE e.entity_type = 'account_ownership_transfer' AND a.tenant_id = sqlc.arg(tenant_id) AND a.id = e.entity_id
+) s;
+
+-- name: ListApprovalDelegations :many
+SELECT *
+FROM approval_delegations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (user_id = sqlc.arg(user_id) OR delegate_user_id = sqlc.arg(user_id))
+  AND (sqlc.arg(include_past)::boolean OR ends_at > now())
+ORDER BY starts_at DESC;
+
+-- name: CountOverlappingDelegations :one
+SELECT count(*)::bigint
+FROM approval_delegations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND starts_at < sqlc.arg(ends_at)
+  AND ends_at > sqlc.arg(starts_at);
+
+-- name: CreateApprovalDelegation :one
+INSERT INTO approval_delegations (
+  tenant_id,
+  user_id,
+  delegate_user_id,
+  starts_at,
+  ends_at,
+  reason
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(user_id),
+  sqlc.arg(delegate_user_id),
+  sqlc.arg(starts_at),
+  sqlc.arg(ends_at),
+  sqlc.narg(reason)
+)
+RETURNING *;
+
+-- name: DeleteApprovalDelegation :execrows
+DELETE FROM approval_delegations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(delegation_id)
+  AND user_id = sqlc.arg(user_id);
+
+-- name: GetActiveDelegation :one
+SELECT *
+FROM approval_delegations
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND starts_at <= now()
+  AND ends_at > now()
+ORDER BY starts_at DESC
+LIMIT 1;
+
+-- name: CreateChainApprovalRequest :one
+INSERT INTO approval_requests (
+  tenant_id,
+  entity_type,
+  entity_id,
+  requested_by,
+  approver_user_id,
+  reason,
+  policy_id,
+  metric_value,
+  chain_id,
+  current_step
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(entity_type),
+  sqlc.arg(entity_id),
+  sqlc.arg(requested_by),
+  sqlc.arg(approver_user_id),
+  sqlc.arg(reason),
+  sqlc.narg(policy_id),
+  sqlc.narg(metric_value),
+  sqlc.arg(chain_id),
+  sqlc.arg(current_step)
+)
+RETURNING *;
+
+-- name: AdvanceApprovalRequest :one
+UPDATE approval_requests
+SET current_step = sqlc.arg(current_step),
+    approver_user_id = sqlc.arg(approver_user_id),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(approval_id)
+RETURNING *;
+
+-- name: FinishApprovalRequest :one
+UPDATE approval_requests
+SET status = sqlc.arg(status),
+    decision_note = sqlc.narg(decision_note),
+    current_step = NULL,
+    decided_at = now(),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(approval_id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: CreateApprovalRequestStep :one
+INSERT INTO approval_request_steps (
+  tenant_id,
+  approval_request_id,
+  step_order,
+  name,
+  approver_type,
+  approver_user_id,
+  approver_role,
+  approver_team_role,
+  sla_hours,
+  escalation_user_id,
+  status
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(approval_request_id),
+  sqlc.arg(step_order),
+  sqlc.arg(name),
+  sqlc.arg(approver_type),
+  sqlc.narg(approver_user_id),
+  sqlc.narg(approver_role),
+  sqlc.narg(approver_team_role),
+  sqlc.narg(sla_hours),
+  sqlc.narg(escalation_user_id),
+  sqlc.arg(status)
+)
+RETURNING *;
+
+-- name: ActivateApprovalRequestStep :one
+UPDATE approval_request_steps
+SET status = 'pending',
+    activated_at = now(),
+    sla_due_at = CASE WHEN sla_hours IS NULL THEN NULL ELSE now() + sla_hours * interval '1 hour' END
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(step_id)
+RETURNING *;
+
+-- name: GetPendingApprovalRequestStepForUpdate :one
+SELECT *
+FROM approval_request_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND approval_request_id = sqlc.arg(approval_request_id)
+  AND status = 'pending'
+FOR UPDATE;
+
+-- name: DecideApprovalRequestStep :one
+UPDATE approval_request_steps
+SET status = sqlc.arg(status),
+    decided_by = sqlc.arg(decided_by),
+    decision_note = sqlc.narg(decision_note),
+    decided_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(step_id)
+RETURNING *;
+
+-- name: CancelOpenApprovalRequestSteps :exec
+UPDATE approval_request_steps
+SET status = 'cancelled'
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND approval_request_id = ANY(sqlc.arg(approval_request_ids)::uuid[])
+  AND status IN ('waiting', 'pending');
+
+-- name: ListApprovalRequestSteps :many
+SELECT *
+FROM approval_request_steps
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND approval_request_id = ANY(sqlc.arg(approval_request_ids)::uuid[])
+ORDER BY approval_request_id, step_order;
+
+-- name: AddApprovalStepApprover :execrows
+INSERT INTO approval_step_approvers (
+  step_id,
+  user_id,
+  tenant_id,
+  source,
+  delegated_from
+) VALUES (
+  sqlc.arg(step_id),
+  sqlc.arg(user_id),
+  sqlc.arg(tenant_id),
+  sqlc.arg(source),
+  sqlc.narg(delegated_from)
+)
+ON CONFLICT (step_id, user_id) DO NOTHING;
+
+-- name: ListApprovalStepApprovers :many
+SELECT sa.*, u.display_name
+FROM approval_step_approvers sa
+JOIN users u ON u.id = sa.user_id
+WHERE sa.tenant_id = sqlc.arg(tenant_id)
+  AND sa.step_id = ANY(sqlc.arg(step_ids)::uuid[])
+ORDER BY sa.step_id, sa.added_at, u.display_name;
+
+-- name: CreateApprovalRequestEvent :exec
+INSERT INTO approval_request_events (
+  tenant_id,
+  approval_request_id,
+  step_order,
+  event,
+  actor_user_id,
+  note,
+  data
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(approval_request_id),
+  sqlc.narg(step_order),
+  sqlc.arg(event),
+  sqlc.narg(actor_user_id),
+  sqlc.narg(note),
+  sqlc.arg(data)
+);
+
+-- name: ListApprovalRequestEvents :many
+SELECT e.*, u.display_name AS actor_name
+FROM approval_request_events e
+LEFT JOIN users u ON u.id = e.actor_user_id
+WHERE e.tenant_id = sqlc.arg(tenant_id)
+  AND e.approval_request_id = sqlc.arg(approval_request_id)
+ORDER BY e.created_at, e.id;
+
+-- Pending steps past their SLA that have not been escalated yet. Steps locked by a
+-- decision in progress are skipped until the next run.
+-- name: ListOverdueApprovalSteps :many
+SELECT s.*, ar.entity_type, ar.entity_id, ar.reason AS request_reason, ar.requested_by
+FROM approval_request_steps s
+JOIN approval_requests ar ON ar.id = s.approval_request_id
+WHERE s.tenant_id = sqlc.arg(tenant_id)
+  AND s.status = 'pending'
+  AND s.escalated_at IS NULL
+  AND s.sla_due_at <= now()
+  AND ar.status = 'pending'
+ORDER BY s.sla_due_at
+LIMIT sqlc.arg(limit_count)
+FOR UPDATE OF s SKIP LOCKED;
+
+-- name: MarkApprovalStepEscalated :exec
+UPDATE approval_request_steps
+SET escalated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(step_id);
@@ -20,12 +20,31 @@ INSERT INTO audit_logs (
 )
 RETURNING *;
 
+-- Newest first, keyset-paged on (created_at, id). from is inclusive, until exclusive.
 -- name: ListAuditLogs :many
-SELECT *
-FROM audit_logs
-WHERE tenant_id = sqlc.arg(tenant_id)
-  AND (sqlc.narg(action)::audit_action_enum IS NULL OR action = sqlc.narg(action))
-  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR actor_user_id = sqlc.narg(actor_user_id))
-ORDER BY created_at DESC
-LIMIT sqlc.arg(limit_count)
-OFFSET sqlc.arg(offset_count);
+SELECT
+  al.id,
+  al.actor_user_id,
+  u.display_name AS actor_name,
+  al.action,
+  al.entity_type,
+  al.entity_id,
+  al.metadata,
+  al.ip_address,
+  al.user_agent,
+  al.created_at
+FROM audit_logs al
+LEFT JOIN users u ON u.id = al.actor_user_id
+WHERE al.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(action)::audit_action_enum IS NULL OR al.action = sqlc.narg(action))
+  AND (sqlc.narg(actor_user_id)::uuid IS NULL OR al.actor_user_id = sqlc.narg(actor_user_id))
+  AND (sqlc.narg(entity_type)::text IS NULL OR al.entity_type = sqlc.narg(entity_type))
+  AND (sqlc.narg(entity_id)::uuid IS NULL OR al.entity_id = sqlc.narg(entity_id))
+  AND (sqlc.narg(from_at)::timestamptz IS NULL OR al.created_at >= sqlc.narg(from_at))
+  AND (sqlc.narg(until_at)::timestamptz IS NULL OR al.created_at < sqlc.narg(until_at))
+  AND (
+    sqlc.narg(cursor_at)::timestamptz IS NULL
+    OR (al.created_at, al.id) < (sqlc.narg(cursor_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+  )
+ORDER BY al.created_at DESC, al.id DESC
+LIMIT sqlc.arg(limit_count);
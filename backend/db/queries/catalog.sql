@@ -0,0 +1,204 @@
+-- name: ListProducts :many
+SELECT *
+FROM products
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active))
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR sku ILIKE '%' || sqlc.narg(search) || '%'
+    OR name ILIKE '%' || sqlc.narg(search) || '%'
+  )
+ORDER BY name ASC, sku ASC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: CountProducts :one
+SELECT count(*)::bigint
+FROM products
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active))
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR sku ILIKE '%' || sqlc.narg(search) || '%'
+    OR name ILIKE '%' || sqlc.narg(search) || '%'
+  );
+
+-- name: GetProduct :one
+SELECT *
+FROM products
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(product_id);
+
+-- name: CreateProduct :one
+INSERT INTO products (
+  tenant_id,
+  sku,
+  name,
+  description,
+  unit,
+  is_active,
+  tax_rate
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(sku),
+  sqlc.arg(name),
+  sqlc.narg(description),
+  sqlc.narg(unit),
+  coalesce(sqlc.narg(is_active)::boolean, TRUE),
+  coalesce(sqlc.narg(tax_rate)::numeric, 10)
+)
+RETURNING *;
+
+-- name: UpdateProduct :one
+UPDATE products
+SET sku = coalesce(sqlc.narg(sku), sku),
+    name = coalesce(sqlc.narg(name), name),
+    description = coalesce(sqlc.narg(description), description),
+    unit = coalesce(sqlc.narg(unit), unit),
+    is_active = coalesce(sqlc.narg(is_active)::boolean, is_active),
+    tax_rate = coalesce(sqlc.narg(tax_rate)::numeric, tax_rate),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(product_id)
+RETURNING *;
+
+-- name: UpsertProductBySKU :one
+INSERT INTO products (
+  tenant_id,
+  sku,
+  name,
+  description,
+  unit,
+  is_active
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(sku),
+  sqlc.arg(name),
+  sqlc.narg(description),
+  sqlc.narg(unit),
+  coalesce(sqlc.narg(is_active)::boolean, TRUE)
+)
+ON CONFLICT (tenant_id, sku)
+DO UPDATE SET name = EXCLUDED.name,
+              description = EXCLUDED.description,
+              unit = EXCLUDED.unit,
+              is_active = EXCLUDED.is_active,
+              updated_at = now()
+RETURNING *;
+
+-- name: ExportProductsRows :many
+SELECT
+  p.id,
+  p.sku,
+  p.name,
+  p.description,
+  p.unit,
+  p.is_active,
+  pbe.unit_price AS list_price,
+  p.created_at,
+  p.updated_at
+FROM products p
+LEFT JOIN price_books pb
+  ON pb.tenant_id = p.tenant_id
+ AND pb.is_default
+LEFT JOIN price_book_entries pbe
+  ON pbe.price_book_id = pb.id
+ AND pbe.product_id = p.id
+WHERE p.tenant_id = sqlc.arg(tenant_id)
+ORDER BY p.sku ASC;
+
+-- name: ListPriceBooks :many
+SELECT *
+FROM price_books
+WHERE tenant_id = sqlc.arg(tenant_id)
+ORDER BY is_default DESC, name ASC;
+
+-- name: GetPriceBook :one
+SELECT *
+FROM price_books
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(price_book_id);
+
+-- name: GetDefaultPriceBook :one
+SELECT *
+FROM price_books
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND is_default;
+
+-- name: ClearDefaultPriceBook :exec
+UPDATE price_books
+SET is_default = FALSE,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND is_default;
+
+-- name: CreatePriceBook :one
+INSERT INTO price_books (
+  tenant_id,
+  name,
+  currency,
+  is_default,
+  is_active
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  coalesce(sqlc.narg(currency), (SELECT t.base_currency FROM tenants t WHERE t.id = sqlc.arg(tenant_id))),
+  sqlc.arg(is_default),
+  coalesce(sqlc.narg(is_active)::boolean, TRUE)
+)
+RETURNING *;
+
+-- name: UpdatePriceBook :one
+UPDATE price_books
+SET name = coalesce(sqlc.narg(name), name),
+    is_default = coalesce(sqlc.narg(is_default)::boolean, is_default),
+    is_active = coalesce(sqlc.narg(is_active)::boolean, is_active),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(price_book_id)
+RETURNING *;
+
+-- name: ListPriceBookEntries :many
+SELECT
+  pbe.id,
+  pbe.price_book_id,
+  pbe.product_id,
+  p.sku,
+  p.name AS product_name,
+  pbe.unit_price,
+  pbe.updated_at
+FROM price_book_entries pbe
+JOIN products p ON p.id = pbe.product_id
+WHERE pbe.tenant_id = sqlc.arg(tenant_id)
+  AND pbe.price_book_id = sqlc.arg(price_book_id)
+ORDER BY p.sku ASC;
+
+-- name: UpsertPriceBookEntry :one
+INSERT INTO price_book_entries (
+  tenant_id,
+  price_book_id,
+  product_id,
+  unit_price
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(price_book_id),
+  sqlc.arg(product_id),
+  sqlc.arg(unit_price)
+)
+ON CONFLICT (price_book_id, product_id)
+DO UPDATE SET unit_price = EXCLUDED.unit_price,
+              updated_at = now()
+RETURNING *;
+
+-- name: DeletePriceBookEntry :execrows
+DELETE FROM price_book_entries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND price_book_id = sqlc.arg(price_book_id)
+  AND product_id = sqlc.arg(product_id);
+
+-- name: GetPriceBookEntryPrice :one
+SELECT unit_price
+FROM price_book_entries
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND price_book_id = sqlc.arg(price_book_id)
+  AND product_id = sqlc.arg(product_id);
@@ -0,0 +1,169 @@
+-- name: ListCompetitors :many
+SELECT *
+FROM competitors
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::boolean IS NULL OR is_active = sqlc.narg(is_active))
+  AND (sqlc.narg(search)::text IS NULL OR name ILIKE '%' || sqlc.narg(search) || '%')
+ORDER BY name ASC;
+
+-- name: GetCompetitor :one
+SELECT *
+FROM competitors
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(competitor_id);
+
+-- name: CreateCompetitor :one
+INSERT INTO competitors (
+  tenant_id,
+  name,
+  website,
+  note
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  sqlc.narg(website),
+  sqlc.narg(note)
+)
+RETURNING *;
+
+-- name: UpdateCompetitor :one
+UPDATE competitors
+SET name = coalesce(sqlc.narg(name), name),
+    website = coalesce(sqlc.narg(website), website),
+    note = coalesce(sqlc.narg(note), note),
+    is_active = coalesce(sqlc.narg(is_active)::boolean, is_active),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(competitor_id)
+RETURNING *;
+
+-- name: ListOpportunityCompetitors :many
+SELECT
+  oc.id,
+  oc.opportunity_id,
+  oc.competitor_id,
+  c.name AS competitor_name,
+  oc.is_winner,
+  oc.note,
+  oc.created_by,
+  oc.created_at,
+  oc.updated_at
+FROM opportunity_competitors oc
+JOIN competitors c ON c.id = oc.competitor_id
+WHERE oc.tenant_id = sqlc.arg(tenant_id)
+  AND oc.opportunity_id = sqlc.arg(opportunity_id)
+ORDER BY oc.is_winner DESC, c.name ASC;
+
+-- name: UpsertOpportunityCompetitor :one
+INSERT INTO opportunity_competitors (
+  tenant_id,
+  opportunity_id,
+  competitor_id,
+  is_winner,
+  note,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(opportunity_id),
+  sqlc.arg(competitor_id),
+  sqlc.arg(is_winner),
+  sqlc.narg(note),
+  sqlc.narg(created_by)
+)
+ON CONFLICT (opportunity_id, competitor_id)
+DO UPDATE SET is_winner = EXCLUDED.is_winner,
+              note = coalesce(EXCLUDED.note, opportunity_competitors.note),
+              updated_at = now()
+RETURNING *;
+
+-- name: DeleteOpportunityCompetitor :execrows
+DELETE FROM opportunity_competitors
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND competitor_id = sqlc.arg(competitor_id);
+
+-- name: ClearOpportunityCompetitorWinner :exec
+UPDATE opportunity_competitors
+SET is_winner = FALSE,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND is_winner;
+
+-- Closed deals with a competitor on them, dated by lost_at for losses and closed_at
+-- for wins. Lost amounts only count deals lost to that competitor and convert at the
+-- rate on the close date.
+
+-- name: GetCompetitiveSummary :many
+WITH closed AS (
+  SELECT
+    oc.competitor_id,
+    oc.is_winner,
+    o.stage,
+    o.currency,
+    o.amount,
+    o.tenant_id,
+    CASE
+      WHEN o.stage = 'closed_lost' THEN coalesce(l.lost_at, o.closed_at, o.updated_at)
+      ELSE coalesce(o.closed_at, o.updated_at)
+    END AS closed_on
+  FROM opportunity_competitors oc
+  JOIN opportunities o ON o.id = oc.opportunity_id
+  LEFT JOIN opportunity_losses l ON l.opportunity_id = o.id
+  WHERE oc.tenant_id = sqlc.arg(tenant_id)
+    AND o.stage IN ('closed_won', 'closed_lost')
+)
+SELECT
+  c.id AS competitor_id,
+  c.name AS competitor_name,
+  cl.currency,
+  count(*)::bigint AS deal_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_won')::bigint AS won_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost')::bigint AS lost_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner)::bigint AS lost_to_count,
+  coalesce(sum(cl.amount) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner), 0)::double precision AS lost_amount,
+  coalesce(sum(cl.amount * fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date)) FILTER (
+    WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NOT NULL
+  ), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NULL)::bigint AS missing_rate_count
+FROM closed cl
+JOIN competitors c ON c.id = cl.competitor_id
+WHERE cl.closed_on >= sqlc.arg(range_start)::timestamptz
+  AND cl.closed_on < sqlc.arg(range_end)::timestamptz
+GROUP BY c.id, c.name, cl.currency
+ORDER BY c.name ASC, cl.currency ASC;
+
+-- name: GetCompetitiveTrend :many
+WITH closed AS (
+  SELECT
+    oc.competitor_id,
+    oc.is_winner,
+    o.stage,
+    o.currency,
+    o.amount,
+    o.tenant_id,
+    CASE
+      WHEN o.stage = 'closed_lost' THEN coalesce(l.lost_at, o.closed_at, o.updated_at)
+      ELSE coalesce(o.closed_at, o.updated_at)
+    END AS closed_on
+  FROM opportunity_competitors oc
+  JOIN opportunities o ON o.id = oc.opportunity_id
+  LEFT JOIN opportunity_losses l ON l.opportunity_id = o.id
+  WHERE oc.tenant_id = sqlc.arg(tenant_id)
+    AND o.stage IN ('closed_won', 'closed_lost')
+)
+SELECT
+  cl.competitor_id,
+  to_char(date_trunc('month', cl.closed_on), 'YYYY-MM') AS month_bucket,
+  count(*)::bigint AS deal_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_won')::bigint AS won_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner)::bigint AS lost_to_count,
+  coalesce(sum(cl.amount * fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date)) FILTER (
+    WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NOT NULL
+  ), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NULL)::bigint AS missing_rate_count
+FROM closed cl
+WHERE cl.closed_on >= sqlc.arg(range_start)::timestamptz
+  AND cl.closed_on < sqlc.arg(range_end)::timestamptz
+GROUP BY cl.competitor_id, to_char(date_trunc('month', cl.closed_on), 'YYYY-MM')
+ORDER BY cl.competitor_id, month_bucket ASC;
@@ -0,0 +1,51 @@
+-- name: GetTenantBaseCurrency :one
+SELECT base_currency
+FROM tenants
+WHERE id = sqlc.arg(tenant_id);
+
+-- name: UpdateTenantBaseCurrency :one
+UPDATE tenants
+SET base_currency = sqlc.arg(base_currency)
+WHERE id = sqlc.arg(tenant_id)
+RETURNING base_currency;
+
+-- name: ListFxRates :many
+SELECT *
+FROM fx_rates
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(currency)::text IS NULL OR currency = sqlc.narg(currency))
+  AND base_currency = sqlc.arg(base_currency)
+ORDER BY currency ASC, effective_on DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: UpsertFxRate :one
+INSERT INTO fx_rates (
+  tenant_id,
+  currency,
+  base_currency,
+  rate,
+  effective_on,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(currency),
+  sqlc.arg(base_currency),
+  sqlc.arg(rate),
+  sqlc.arg(effective_on),
+  sqlc.narg(created_by)
+)
+ON CONFLICT (tenant_id, currency, base_currency, effective_on)
+DO UPDATE SET rate = EXCLUDED.rate,
+              created_by = EXCLUDED.created_by,
+              created_at = now()
+RETURNING *;
+
+-- name: DeleteFxRate :one
+DELETE FROM fx_rates
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(fx_rate_id)
+RETURNING *;
+
+-- name: GetFxRate :one
+SELECT fx_rate(sqlc.arg(tenant_id)::uuid, sqlc.arg(currency)::text, sqlc.arg(on_date)::date)::numeric AS rate;
@@ -15,11 +15,114 @@ WHERE ks.tenant_id = sqlc.arg(tenant_id)
 ORDER BY metric_key, dimension_key;
 
 -- name: GetPipelineSummary :many
+-- Closed deals convert at the rate on their close date, open deals at today's rate.
+-- Deals without a rate are left out of total_amount_base and counted in missing_rate_count.
 SELECT
   stage,
+  currency,
   count(*)::bigint AS deal_count,
-  coalesce(sum(amount), 0)::double precision AS total_amount
+  coalesce(sum(amount), 0)::double precision AS total_amount,
+  coalesce(sum(amount * fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)))
+    FILTER (WHERE fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)) IS NOT NULL), 0)::double precision AS total_amount_base,
+  count(*) FILTER (WHERE fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)) IS NULL)::bigint AS missing_rate_count
 FROM opportunities
 WHERE tenant_id = sqlc.arg(tenant_id)
-GROUP BY stage
-ORDER BY stage;
+GROUP BY stage, currency
+ORDER BY stage, currency;
+
+-- name: CreateKpiSnapshot :exec
+-- Recomputes the headline KPIs in the tenant's base currency and stores them as a new
+-- snapshot. Amounts without an FX rate are left out of the sums and counted in the
+-- *_missing_rate_count metrics stored next to them.
+WITH open_deals AS (
+  SELECT
+    amount * fx_rate(tenant_id, currency, current_date) AS amount_base,
+    probability
+  FROM opportunities
+  WHERE tenant_id = sqlc.arg(tenant_id)
+    AND stage NOT IN ('closed_won', 'closed_lost')
+),
+closed_90d AS (
+  SELECT stage
+  FROM opportunities
+  WHERE tenant_id = sqlc.arg(tenant_id)
+    AND stage IN ('closed_won', 'closed_lost')
+    AND closed_at >= now() - interval '90 day'
+),
+won_90d AS (
+  SELECT amount * fx_rate(tenant_id, currency, closed_at::date) AS amount_base
+  FROM opportunities
+  WHERE tenant_id = sqlc.arg(tenant_id)
+    AND stage = 'closed_won'
+    AND closed_at >= now() - interval '90 day'
+),
+metrics AS (
+  SELECT 'open_pipeline_amount'::text AS metric_key, coalesce((SELECT sum(amount_base) FROM open_deals WHERE amount_base IS NOT NULL), 0)::numeric AS metric_value
+  UNION ALL
+  SELECT 'weighted_pipeline_amount', coalesce((SELECT sum(amount_base * probability / 100.0) FROM open_deals WHERE amount_base IS NOT NULL), 0)::numeric
+  UNION ALL
+  SELECT 'open_pipeline_missing_rate_count', (SELECT count(*) FROM open_deals WHERE amount_base IS NULL)::numeric
+  UNION ALL
+  SELECT 'open_deal_count', (SELECT count(*) FROM open_deals)::numeric
+  UNION ALL
+  SELECT 'won_amount_90d', coalesce((SELECT sum(amount_base) FROM won_90d WHERE amount_base IS NOT NULL), 0)::numeric
+  UNION ALL
+  SELECT 'won_amount_90d_missing_rate_count', (SELECT count(*) FROM won_90d WHERE amount_base IS NULL)::numeric
+  UNION ALL
+  SELECT 'win_rate_90d', coalesce(
+    (SELECT count(*) FILTER (WHERE stage = 'closed_won')::numeric / nullif(count(*), 0) FROM closed_90d),
+    0
+  )::numeric
+)
+INSERT INTO kpi_snapshots (
+  tenant_id, snapshot_at, metric_key, metric_value, dimension_key, dimensions
+)
+SELECT
+  sqlc.arg(tenant_id),
+  now(),
+  m.metric_key,
+  m.metric_value,
+  '',
+  jsonb_build_object('currency', (SELECT t.base_currency FROM tenants t WHERE t.id = sqlc.arg(tenant_id)))
+FROM metrics m;
+
+-- name: GetRevenueSchedule :many
+-- Months up to and including as_of_month count as recognized, later months as scheduled.
+-- Amounts convert to the base currency at the rate on the order date; cancelled orders
+-- are left out, and so are months without a rate, which count in missing_rate_count.
+SELECT
+  rs.period_month,
+  o.account_id,
+  a.name AS account_name,
+  o.owner_user_id,
+  u.display_name AS owner_name,
+  coalesce(sum(rs.revenue_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE rs.period_month <= sqlc.arg(as_of_month)::date
+      AND fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS recognized_amount,
+  coalesce(sum(rs.revenue_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE rs.period_month > sqlc.arg(as_of_month)::date
+      AND fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS scheduled_amount,
+  coalesce(sum(rs.billing_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS billing_amount,
+  count(DISTINCT rs.order_id)::bigint AS order_count,
+  count(*) FILTER (WHERE fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NULL)::bigint AS missing_rate_count
+FROM order_revenue_schedules rs
+JOIN orders od ON od.id = rs.order_id
+JOIN opportunities o ON o.id = od.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+JOIN users u ON u.id = o.owner_user_id
+WHERE rs.tenant_id = sqlc.arg(tenant_id)
+  AND od.status <> 'cancelled'
+  AND rs.period_month BETWEEN sqlc.arg(from_month)::date AND sqlc.arg(to_month)::date
+  AND (sqlc.narg(account_id)::uuid IS NULL OR o.account_id = sqlc.narg(account_id))
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+  AND (
+    sqlc.narg(visible_to)::uuid IS NULL
+    OR o.owner_user_id = sqlc.narg(visible_to)
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = sqlc.narg(visible_to)
+    )
+  )
+GROUP BY rs.period_month, o.account_id, a.name, o.owner_user_id, u.display_name
+ORDER BY rs.period_month, a.name, u.display_name;
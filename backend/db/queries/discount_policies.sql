@@ -0,0 +1,125 @@
+-- name: ListDiscountPolicies :many
+SELECT *
+FROM discount_policies
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (NOT sqlc.arg(active_only)::boolean OR is_active)
+ORDER BY name;
+
+-- name: GetDiscountPolicy :one
+SELECT *
+FROM discount_policies
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(policy_id);
+
+-- name: CreateDiscountPolicy :one
+INSERT INTO discount_policies (
+  tenant_id,
+  name,
+  metric,
+  threshold,
+  approver_user_id,
+  chain_id,
+  is_active,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  sqlc.arg(metric),
+  sqlc.arg(threshold),
+  sqlc.narg(approver_user_id),
+  sqlc.narg(chain_id),
+  sqlc.arg(is_active),
+  sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: UpdateDiscountPolicy :one
+UPDATE discount_policies
+SET
+  name = coalesce(sqlc.narg(name), name),
+  metric = coalesce(sqlc.narg(metric), metric),
+  threshold = coalesce(sqlc.narg(threshold), threshold),
+  approver_user_id = coalesce(sqlc.narg(approver_user_id), approver_user_id),
+  chain_id = CASE WHEN sqlc.arg(set_chain)::boolean THEN sqlc.narg(chain_id) ELSE chain_id END,
+  is_active = coalesce(sqlc.narg(is_active), is_active),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(policy_id)
+RETURNING *;
+
+-- Inputs for the discount policies. base_amount is NULL when the quote currency has no
+-- exchange rate to the tenant's base currency.
+-- name: GetQuoteDiscountMetrics :one
+SELECT
+  coalesce((
+    SELECT max(li.discount_percent)
+    FROM line_items li
+    WHERE li.tenant_id = qh.tenant_id
+      AND li.quote_id = qh.id
+  ), 0)::numeric AS max_line_discount_percent,
+  coalesce((
+    SELECT sum(li.quantity * li.unit_price)
+    FROM line_items li
+    WHERE li.tenant_id = qh.tenant_id
+      AND li.quote_id = qh.id
+  ), 0)::numeric AS list_amount,
+  qh.amount,
+  (qh.amount * fx_rate(qh.tenant_id, qh.currency, current_date))::numeric AS base_amount
+FROM quotes qh
+WHERE qh.tenant_id = sqlc.arg(tenant_id)
+  AND qh.id = sqlc.arg(quote_id);
+
+-- Approvals raised by policies for the quote's current content, newest first. Withdrawn
+-- requests no longer cover it, so the next send attempt raises a fresh one.
+-- name: ListCurrentQuotePolicyApprovals :many
+SELECT *
+FROM approval_requests
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND entity_type = 'quote'
+  AND entity_id = sqlc.arg(quote_id)
+  AND policy_id IS NOT NULL
+  AND invalidated_at IS NULL
+  AND status <> 'withdrawn'
+ORDER BY created_at DESC;
+
+-- name: SetQuoteApprovedForSend :exec
+UPDATE quotes
+SET approved_for_send_at = sqlc.narg(approved_for_send_at)
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id)
+  AND approved_for_send_at IS DISTINCT FROM sqlc.narg(approved_for_send_at);
+
+-- name: CreatePolicyApprovalRequest :one
+INSERT INTO approval_requests (
+  tenant_id,
+  entity_type,
+  entity_id,
+  requested_by,
+  approver_user_id,
+  reason,
+  policy_id,
+  metric_value
+) VALUES (
+  sqlc.arg(tenant_id),
+  'quote',
+  sqlc.arg(quote_id),
+  sqlc.arg(requested_by),
+  sqlc.arg(approver_user_id),
+  sqlc.arg(reason),
+  sqlc.arg(policy_id),
+  sqlc.narg(metric_value)
+)
+RETURNING *;
+
+-- Open and granted requests become 'invalidated'; rejections keep their status but no
+-- longer block the edited quote.
+-- name: InvalidateQuoteApprovals :many
+UPDATE approval_requests
+SET status = CASE WHEN status IN ('pending', 'approved') THEN 'invalidated'::approval_status_enum ELSE status END,
+    invalidated_at = now(),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND entity_type = 'quote'
+  AND entity_id = sqlc.arg(quote_id)
+  AND invalidated_at IS NULL
+RETURNING *;
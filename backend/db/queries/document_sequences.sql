@@ -0,0 +1,45 @@
+-- name: NextDocumentSequence :one
+INSERT INTO document_sequences (tenant_id, prefix, period, last_value)
+VALUES (sqlc.arg(tenant_id), sqlc.arg(prefix), sqlc.arg(period), 1)
+ON CONFLICT (tenant_id, prefix, period)
+DO UPDATE SET last_value = document_sequences.last_value + 1,
+              updated_at = now()
+RETURNING last_value;
+
+-- name: GetDocumentNumberFormat :one
+SELECT *
+FROM document_number_formats
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND document_type = sqlc.arg(document_type);
+
+-- name: ListDocumentNumberFormats :many
+SELECT *
+FROM document_number_formats
+WHERE tenant_id = sqlc.arg(tenant_id)
+ORDER BY document_type;
+
+-- name: UpsertDocumentNumberFormat :one
+INSERT INTO document_number_formats (tenant_id, document_type, prefix, period_format, padding)
+VALUES (sqlc.arg(tenant_id), sqlc.arg(document_type), sqlc.arg(prefix), sqlc.arg(period_format), sqlc.arg(padding))
+ON CONFLICT (tenant_id, document_type)
+DO UPDATE SET prefix = EXCLUDED.prefix,
+              period_format = EXCLUDED.period_format,
+              padding = EXCLUDED.padding,
+              updated_at = now()
+RETURNING *;
+
+-- name: GetTenantTaxRounding :one
+SELECT tax_rounding
+FROM tenants
+WHERE id = sqlc.arg(tenant_id);
+
+-- name: UpdateTenantTaxRounding :one
+UPDATE tenants
+SET tax_rounding = sqlc.arg(tax_rounding)
+WHERE id = sqlc.arg(tenant_id)
+RETURNING tax_rounding;
+
+-- name: GetTenantTimeZone :one
+SELECT time_zone
+FROM tenants
+WHERE id = sqlc.arg(tenant_id);
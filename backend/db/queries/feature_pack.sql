@@ -25,6 +25,7 @@ WHERE tenant_id = sqlc.arg(tenant_id)
   AND id = sqlc.arg(opportunity_id)
 RETURNING *;
 
+-- Only completed activities count as engagement; open or overdue tasks do not.
 -- name: ListDealHealth :many
 SELECT
   o.id,
@@ -49,39 +50,55 @@ SELECT
   )::int AS health_score
 FROM opportunities o
 LEFT JOIN LATERAL (
-  SELECT max(a.activity_at) AS last_activity_at
+  SELECT max(a.completed_at) AS last_activity_at
   FROM activities a
   WHERE a.tenant_id = o.tenant_id
     AND a.opportunity_id = o.id
+    AND a.completed_at IS NOT NULL
 ) AS last_activity ON true
 WHERE o.tenant_id = sqlc.arg(tenant_id)
 ORDER BY health_score ASC, o.updated_at ASC
 LIMIT sqlc.arg(limit_count)
 OFFSET sqlc.arg(offset_count);
 
+-- Open deals convert at today's rate; amounts without a rate are left out of the
+-- *_base sums and counted in missing_rate_count.
+
 -- name: GetForecastSummary :many
 SELECT
   o.owner_user_id,
   to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM') AS month_bucket,
+  o.currency,
   count(*)::bigint AS deal_count,
   coalesce(sum(o.amount), 0)::double precision AS pipeline_amount,
-  coalesce(sum(o.amount * (o.probability::numeric / 100.0)), 0)::double precision AS weighted_amount
+  coalesce(sum(o.amount * (o.probability::numeric / 100.0)), 0)::double precision AS weighted_amount,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, current_date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL), 0)::double precision AS pipeline_amount_base,
+  coalesce(sum(o.amount * (o.probability::numeric / 100.0) * fx_rate(o.tenant_id, o.currency, current_date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL), 0)::double precision AS weighted_amount_base,
+  count(*) FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NULL)::bigint AS missing_rate_count
 FROM opportunities o
 WHERE o.tenant_id = sqlc.arg(tenant_id)
   AND o.stage NOT IN ('closed_won', 'closed_lost')
-GROUP BY o.owner_user_id, to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM')
-ORDER BY month_bucket ASC, o.owner_user_id ASC;
+GROUP BY o.owner_user_id, to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM'), o.currency
+ORDER BY month_bucket ASC, o.owner_user_id ASC, o.currency ASC;
 
+-- Lost deals convert at the rate on the loss date, with the same missing-rate handling.
 -- name: GetLossReasonAnalysis :many
 SELECT
   l.reason,
+  o.currency,
   count(*)::bigint AS lost_count,
-  coalesce(sum(o.amount), 0)::double precision AS lost_amount
+  coalesce(sum(o.amount), 0)::double precision AS lost_amount,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, l.lost_at::date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, l.lost_at::date) IS NOT NULL), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE fx_rate(o.tenant_id, o.currency, l.lost_at::date) IS NULL)::bigint AS missing_rate_count
 FROM opportunity_losses l
 JOIN opportunities o ON o.id = l.opportunity_id
 WHERE l.tenant_id = sqlc.arg(tenant_id)
-GROUP BY l.reason
-ORDER BY lost_count DESC, lost_amount DESC;
+  AND o.stage = 'closed_lost'
+GROUP BY l.reason, o.currency
+ORDER BY l.reason, o.currency;
 
 -- name: ListDuplicateCandidates :many
 SELECT
@@ -218,13 +235,38 @@ INSERT INTO approval_requests (
 RETURNING *;
 
 -- name: ListApprovalRequests :many
+SELECT ar.*
+FROM approval_requests ar
+WHERE ar.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(status)::approval_status_enum IS NULL OR ar.status = sqlc.narg(status))
+  AND (
+    sqlc.narg(assignee)::uuid IS NULL
+    OR (ar.chain_id IS NULL AND ar.approver_user_id = sqlc.narg(assignee))
+    OR EXISTS (
+      SELECT 1
+      FROM approval_request_steps s
+      JOIN approval_step_approvers sa ON sa.step_id = s.id
+      WHERE s.approval_request_id = ar.id
+        AND s.status = 'pending'
+        AND sa.user_id = sqlc.narg(assignee)
+    )
+  )
+ORDER BY ar.created_at DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: GetApprovalRequest :one
 SELECT *
 FROM approval_requests
 WHERE tenant_id = sqlc.arg(tenant_id)
-  AND (sqlc.narg(status)::approval_status_enum IS NULL OR status = sqlc.narg(status))
-ORDER BY created_at DESC
-LIMIT sqlc.arg(limit_count)
-OFFSET sqlc.arg(offset_count);
+  AND id = sqlc.arg(approval_id);
+
+-- name: GetApprovalRequestForUpdate :one
+SELECT *
+FROM approval_requests
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(approval_id)
+FOR UPDATE;
 
 -- name: DecideApprovalRequest :one
 UPDATE approval_requests
@@ -235,6 +277,20 @@ SET
   updated_at = now()
 WHERE tenant_id = sqlc.arg(tenant_id)
   AND id = sqlc.arg(approval_id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: WithdrawApprovalRequest :one
+UPDATE approval_requests
+SET
+  status = 'withdrawn',
+  decision_note = sqlc.narg(reason),
+  current_step = NULL,
+  withdrawn_at = now(),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(approval_id)
+  AND status = 'pending'
 RETURNING *;
 
 -- name: ExportAccountsRows :many
@@ -263,6 +319,7 @@ SELECT
   stage,
   probability,
   amount::double precision AS amount,
+  currency,
   expected_close_date,
   next_action_at,
   next_action_note,
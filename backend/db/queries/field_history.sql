@@ -0,0 +1,29 @@
+-- name: SetChangeContext :exec
+SELECT
+  set_config('app.user_id', sqlc.arg(user_id)::text, true),
+  set_config('app.change_source', sqlc.arg(source)::text, true);
+
+-- name: ListFieldChanges :many
+SELECT
+  fc.id,
+  fc.entity_type,
+  fc.entity_id,
+  fc.field_name,
+  fc.old_value,
+  fc.new_value,
+  fc.changed_by,
+  u.display_name AS changed_by_name,
+  fc.source,
+  fc.changed_at
+FROM field_changes fc
+LEFT JOIN users u ON u.id = fc.changed_by
+WHERE fc.tenant_id = sqlc.arg(tenant_id)
+  AND fc.entity_type = sqlc.arg(entity_type)
+  AND fc.entity_id = sqlc.arg(entity_id)
+  AND (sqlc.narg(field_names)::text[] IS NULL OR fc.field_name = ANY(sqlc.narg(field_names)::text[]))
+  AND (
+    sqlc.narg(cursor_at)::timestamptz IS NULL
+    OR (fc.changed_at, fc.id) < (sqlc.narg(cursor_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+  )
+ORDER BY fc.changed_at DESC, fc.id DESC
+LIMIT sqlc.arg(limit_count);
@@ -0,0 +1,317 @@
+-- Exactly one of opportunity_id, quote_id and order_id is set per call; IS NOT DISTINCT
+-- FROM keeps the other two parents NULL so a query never mixes line item sets.
+
+-- name: ListLineItems :many
+SELECT
+  li.*,
+  p.sku,
+  p.name AS product_name
+FROM line_items li
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+  AND li.opportunity_id IS NOT DISTINCT FROM sqlc.narg(opportunity_id)::uuid
+  AND li.quote_id IS NOT DISTINCT FROM sqlc.narg(quote_id)::uuid
+  AND li.order_id IS NOT DISTINCT FROM sqlc.narg(order_id)::uuid
+ORDER BY li.sort_order ASC, li.created_at ASC;
+
+-- name: DeleteLineItems :exec
+DELETE FROM line_items
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id IS NOT DISTINCT FROM sqlc.narg(opportunity_id)::uuid
+  AND quote_id IS NOT DISTINCT FROM sqlc.narg(quote_id)::uuid
+  AND order_id IS NOT DISTINCT FROM sqlc.narg(order_id)::uuid;
+
+-- name: CreateLineItem :one
+INSERT INTO line_items (
+  tenant_id,
+  opportunity_id,
+  quote_id,
+  order_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.narg(opportunity_id),
+  sqlc.narg(quote_id),
+  sqlc.narg(order_id),
+  sqlc.arg(product_id),
+  sqlc.narg(price_book_id),
+  sqlc.narg(description),
+  sqlc.arg(quantity),
+  sqlc.arg(unit_price),
+  sqlc.arg(discount_percent),
+  sqlc.arg(tax_rate),
+  sqlc.arg(sort_order)
+)
+RETURNING *;
+
+-- name: CountOpportunityLineItems :one
+SELECT count(*)::bigint
+FROM line_items
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id);
+
+-- CountLiveQuoteLineItems counts the lines on the opportunity's draft, sent and accepted
+-- quotes; while there are any, the deal amount follows the quote.
+-- name: CountLiveQuoteLineItems :one
+SELECT count(*)::bigint
+FROM line_items li
+JOIN quotes qt ON qt.id = li.quote_id
+WHERE qt.tenant_id = sqlc.arg(tenant_id)
+  AND qt.opportunity_id = sqlc.arg(opportunity_id)
+  AND qt.status IN ('draft', 'sent', 'accepted');
+
+-- RecalculateOpportunityAmount derives the deal amount from its latest live quote: the
+-- accepted one, else the newest draft or sent quote with line items. Superseded, rejected
+-- and expired quotes never count; without a live quote the opportunity's own lines do, and
+-- a deal with neither keeps its current amount.
+-- name: RecalculateOpportunityAmount :one
+UPDATE opportunities o
+SET amount = coalesce(
+      (
+        SELECT qt.amount
+        FROM quotes qt
+        WHERE qt.tenant_id = o.tenant_id
+          AND qt.opportunity_id = o.id
+          AND qt.status IN ('draft', 'sent', 'accepted')
+          AND EXISTS (SELECT 1 FROM line_items qli WHERE qli.quote_id = qt.id)
+        ORDER BY qt.status = 'accepted' DESC, qt.created_at DESC, qt.id DESC
+        LIMIT 1
+      ),
+      (
+        SELECT sum(li.line_total)
+        FROM line_items li
+        WHERE li.opportunity_id = o.id
+      ),
+      o.amount
+    ),
+    updated_at = now()
+WHERE o.tenant_id = sqlc.arg(tenant_id)
+  AND o.id = sqlc.arg(opportunity_id)
+RETURNING *;
+
+-- RecalculateQuoteTotals derives the pre-tax amount, consumption tax and total from the
+-- line items. Tax is rounded once per rate with the tenant's rounding mode.
+-- name: RecalculateQuoteTotals :one
+WITH by_rate AS (
+  SELECT li.tax_rate, sum(li.line_total) AS taxable_amount
+  FROM line_items li
+  WHERE li.tenant_id = sqlc.arg(tenant_id)
+    AND li.quote_id = sqlc.arg(quote_id)
+  GROUP BY li.tax_rate
+),
+taxed AS (
+  SELECT
+    br.tax_rate,
+    br.taxable_amount,
+    round_tax(br.taxable_amount * br.tax_rate / 100, t.tax_rounding, currency_minor_units(qh.currency)) AS tax_amount
+  FROM by_rate br
+  CROSS JOIN quotes qh
+  JOIN tenants t ON t.id = qh.tenant_id
+  WHERE qh.tenant_id = sqlc.arg(tenant_id)
+    AND qh.id = sqlc.arg(quote_id)
+),
+totals AS (
+  SELECT
+    coalesce(sum(tx.taxable_amount), 0) AS amount,
+    coalesce(sum(tx.tax_amount), 0) AS tax_amount,
+    coalesce(
+      jsonb_agg(
+        jsonb_build_object('taxRate', tx.tax_rate, 'taxableAmount', tx.taxable_amount, 'taxAmount', tx.tax_amount)
+        ORDER BY tx.tax_rate DESC
+      ),
+      '[]'::jsonb
+    ) AS tax_breakdown
+  FROM taxed tx
+)
+UPDATE quotes q
+SET amount = totals.amount,
+    tax_amount = totals.tax_amount,
+    total_amount = totals.amount + totals.tax_amount,
+    tax_breakdown = totals.tax_breakdown,
+    updated_at = now()
+FROM totals
+WHERE q.tenant_id = sqlc.arg(tenant_id)
+  AND q.id = sqlc.arg(quote_id)
+RETURNING q.*;
+
+-- name: RecalculateOrderAmount :one
+UPDATE orders od
+SET amount = (
+      SELECT coalesce(sum(li.line_total), 0)
+      FROM line_items li
+      WHERE li.order_id = od.id
+    ),
+    updated_at = now()
+WHERE od.tenant_id = sqlc.arg(tenant_id)
+  AND od.id = sqlc.arg(order_id)
+RETURNING *;
+
+-- name: GetQuote :one
+SELECT *
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id);
+
+-- name: GetOrder :one
+SELECT *
+FROM orders
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(order_id);
+
+-- name: CopyQuoteLineItems :execrows
+INSERT INTO line_items (
+  tenant_id,
+  opportunity_id,
+  order_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  sqlc.narg(opportunity_id)::uuid,
+  sqlc.narg(order_id)::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+  AND li.quote_id = sqlc.arg(quote_id);
+
+-- name: CopyOpportunityLineItemsToQuote :execrows
+INSERT INTO line_items (
+  tenant_id,
+  quote_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  sqlc.arg(quote_id)::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+  AND li.opportunity_id = sqlc.arg(opportunity_id);
+
+-- name: CopyQuoteLineItemsToQuote :execrows
+INSERT INTO line_items (
+  tenant_id,
+  quote_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  sqlc.arg(target_quote_id)::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+  AND li.quote_id = sqlc.arg(source_quote_id);
+
+-- Open lines convert at today's rate and won lines at the close date's rate. Lines
+-- without a rate are left out of the amounts; their deals count in missing_rate_count.
+-- name: GetProductRevenueSummary :many
+SELECT
+  p.id AS product_id,
+  p.sku,
+  p.name AS product_name,
+  count(DISTINCT o.id) FILTER (WHERE o.stage NOT IN ('closed_won', 'closed_lost'))::bigint AS open_deal_count,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, current_date)) FILTER (
+    WHERE o.stage NOT IN ('closed_won', 'closed_lost')
+      AND fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL
+  ), 0)::double precision AS pipeline_amount,
+  coalesce(sum(li.line_total * (o.probability::numeric / 100.0) * fx_rate(o.tenant_id, o.currency, current_date)) FILTER (
+    WHERE o.stage NOT IN ('closed_won', 'closed_lost')
+      AND fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL
+  ), 0)::double precision AS weighted_amount,
+  count(DISTINCT o.id) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= sqlc.arg(range_start)
+      AND o.closed_at < sqlc.arg(range_end)
+  )::bigint AS won_deal_count,
+  coalesce(sum(li.quantity) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= sqlc.arg(range_start)
+      AND o.closed_at < sqlc.arg(range_end)
+  ), 0)::double precision AS won_quantity,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, o.closed_at::date)) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= sqlc.arg(range_start)
+      AND o.closed_at < sqlc.arg(range_end)
+      AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NOT NULL
+  ), 0)::double precision AS won_amount,
+  count(DISTINCT o.id) FILTER (
+    WHERE (o.stage NOT IN ('closed_won', 'closed_lost') AND fx_rate(o.tenant_id, o.currency, current_date) IS NULL)
+       OR (o.stage = 'closed_won'
+           AND o.closed_at >= sqlc.arg(range_start)
+           AND o.closed_at < sqlc.arg(range_end)
+           AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NULL)
+  )::bigint AS missing_rate_count
+FROM line_items li
+JOIN opportunities o ON o.id = li.opportunity_id
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+GROUP BY p.id, p.sku, p.name
+ORDER BY won_amount DESC, pipeline_amount DESC, p.sku ASC;
+
+-- Same rates as GetPipelineSummary; lines without one are left out of total_amount.
+-- name: GetPipelineSummaryByProduct :many
+SELECT
+  o.stage,
+  p.id AS product_id,
+  p.sku,
+  p.name AS product_name,
+  count(DISTINCT o.id)::bigint AS deal_count,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)) IS NOT NULL), 0)::double precision AS total_amount,
+  count(DISTINCT o.id) FILTER (WHERE fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)) IS NULL)::bigint AS missing_rate_count
+FROM line_items li
+JOIN opportunities o ON o.id = li.opportunity_id
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = sqlc.arg(tenant_id)
+GROUP BY o.stage, p.id, p.sku, p.name
+ORDER BY o.stage, total_amount DESC;
@@ -0,0 +1,56 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (
+  tenant_id,
+  user_id,
+  kind,
+  entity_type,
+  entity_id,
+  title,
+  body,
+  data
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(user_id),
+  sqlc.arg(kind),
+  sqlc.arg(entity_type),
+  sqlc.arg(entity_id),
+  sqlc.arg(title),
+  sqlc.arg(body),
+  coalesce(sqlc.narg(data), '{}'::jsonb)
+)
+RETURNING *;
+
+-- name: ListNotificationsByUser :many
+SELECT *
+FROM notifications
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND (NOT sqlc.arg(unread_only)::boolean OR read_at IS NULL)
+  AND (
+    sqlc.narg(cursor_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: CountUnreadNotifications :one
+SELECT count(*)::bigint
+FROM notifications
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND read_at IS NULL;
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = coalesce(read_at, now())
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(notification_id)
+  AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND read_at IS NULL;
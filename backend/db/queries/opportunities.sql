@@ -1,9 +1,17 @@
 -- name: ListOpportunities :many
 SELECT *
 FROM opportunities
-WHERE tenant_id = sqlc.arg(tenant_id)
+WHERE opportunities.tenant_id = sqlc.arg(tenant_id)
   AND (sqlc.narg(stage)::opportunity_stage_enum IS NULL OR stage = sqlc.narg(stage))
   AND (sqlc.narg(owner_user_id)::uuid IS NULL OR owner_user_id = sqlc.narg(owner_user_id))
+  AND (
+    sqlc.narg(visible_to)::uuid IS NULL
+    OR owner_user_id = sqlc.narg(visible_to)
+    OR id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = sqlc.narg(visible_to)
+    )
+  )
 ORDER BY updated_at DESC
 LIMIT sqlc.arg(limit_count)
 OFFSET sqlc.arg(offset_count);
@@ -11,9 +19,30 @@ OFFSET sqlc.arg(offset_count);
 -- name: CountOpportunities :one
 SELECT count(*)::bigint
 FROM opportunities
-WHERE tenant_id = sqlc.arg(tenant_id)
+WHERE opportunities.tenant_id = sqlc.arg(tenant_id)
   AND (sqlc.narg(stage)::opportunity_stage_enum IS NULL OR stage = sqlc.narg(stage))
-  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR owner_user_id = sqlc.narg(owner_user_id));
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR owner_user_id = sqlc.narg(owner_user_id))
+  AND (
+    sqlc.narg(visible_to)::uuid IS NULL
+    OR owner_user_id = sqlc.narg(visible_to)
+    OR id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = sqlc.narg(visible_to)
+    )
+  );
+
+-- name: GetOpportunity :one
+SELECT *
+FROM opportunities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(opportunity_id);
+
+-- name: GetOpportunityForUpdate :one
+SELECT *
+FROM opportunities
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(opportunity_id)
+FOR UPDATE;
 
 -- name: CreateOpportunity :one
 INSERT INTO opportunities (
@@ -25,7 +54,9 @@ INSERT INTO opportunities (
   stage,
   probability,
   amount,
+  currency,
   expected_close_date,
+  closed_at,
   memo,
   created_by
 ) VALUES (
@@ -37,7 +68,12 @@ INSERT INTO opportunities (
   coalesce(sqlc.narg(stage)::opportunity_stage_enum, 'new_lead'),
   coalesce(sqlc.narg(probability), 0),
   coalesce(sqlc.narg(amount), 0),
+  coalesce(sqlc.narg(currency), (SELECT t.base_currency FROM tenants t WHERE t.id = sqlc.arg(tenant_id))),
   sqlc.narg(expected_close_date),
+  CASE
+    WHEN sqlc.narg(stage)::opportunity_stage_enum IN ('closed_won', 'closed_lost') THEN now()
+    ELSE NULL
+  END,
   sqlc.narg(memo),
   sqlc.arg(created_by)
 )
@@ -52,47 +88,25 @@ SET
   stage = coalesce(sqlc.narg(stage)::opportunity_stage_enum, stage),
   probability = coalesce(sqlc.narg(probability), probability),
   amount = coalesce(sqlc.narg(amount), amount),
-  expected_close_date = coalesce(sqlc.narg(expected_close_date), expected_close_date),
+  currency = coalesce(sqlc.narg(currency), currency),
+  expected_close_date = CASE WHEN sqlc.arg(set_expected_close_date)::boolean THEN sqlc.narg(expected_close_date) ELSE expected_close_date END,
   memo = coalesce(sqlc.narg(memo), memo),
+  closed_at = CASE
+    WHEN sqlc.narg(stage)::opportunity_stage_enum IS NULL THEN closed_at
+    WHEN sqlc.narg(stage)::opportunity_stage_enum IN ('closed_won', 'closed_lost') THEN coalesce(closed_at, now())
+    ELSE NULL
+  END,
   updated_at = now()
 WHERE tenant_id = sqlc.arg(tenant_id)
   AND id = sqlc.arg(opportunity_id)
 RETURNING *;
 
--- name: ListActivitiesByOpportunity :many
-SELECT *
-FROM activities
-WHERE tenant_id = sqlc.arg(tenant_id)
-  AND opportunity_id = sqlc.arg(opportunity_id)
-ORDER BY activity_at DESC
-LIMIT sqlc.arg(limit_count)
-OFFSET sqlc.arg(offset_count);
-
--- name: CreateActivity :one
-INSERT INTO activities (
-  tenant_id,
-  opportunity_id,
-  activity_type,
-  subject,
-  detail,
-  activity_at,
-  created_by
-) VALUES (
-  sqlc.arg(tenant_id),
-  sqlc.arg(opportunity_id),
-  sqlc.arg(activity_type),
-  sqlc.arg(subject),
-  sqlc.narg(detail),
-  sqlc.arg(activity_at),
-  sqlc.arg(created_by)
-)
-RETURNING *;
-
 -- name: ListQuotesByOpportunity :many
 SELECT *
 FROM quotes
 WHERE tenant_id = sqlc.arg(tenant_id)
   AND opportunity_id = sqlc.arg(opportunity_id)
+  AND (NOT sqlc.arg(latest_only)::boolean OR status <> 'superseded')
 ORDER BY created_at DESC;
 
 -- name: CreateQuote :one
@@ -101,6 +115,7 @@ INSERT INTO quotes (
   opportunity_id,
   quote_no,
   amount,
+  currency,
   status,
   issued_on,
   valid_until,
@@ -111,6 +126,7 @@ INSERT INTO quotes (
   sqlc.arg(opportunity_id),
   sqlc.arg(quote_no),
   sqlc.arg(amount),
+  coalesce(sqlc.narg(currency), (SELECT o.currency FROM opportunities o WHERE o.id = sqlc.arg(opportunity_id))),
   coalesce(sqlc.narg(status)::quote_status_enum, 'draft'),
   sqlc.narg(issued_on),
   sqlc.narg(valid_until),
@@ -132,6 +148,7 @@ INSERT INTO orders (
   opportunity_id,
   order_no,
   amount,
+  currency,
   status,
   ordered_on,
   note,
@@ -141,6 +158,7 @@ INSERT INTO orders (
   sqlc.arg(opportunity_id),
   sqlc.arg(order_no),
   sqlc.arg(amount),
+  coalesce(sqlc.narg(currency), (SELECT o.currency FROM opportunities o WHERE o.id = sqlc.arg(opportunity_id))),
   coalesce(sqlc.narg(status)::order_status_enum, 'pending'),
   sqlc.narg(ordered_on),
   sqlc.narg(note),
@@ -148,13 +166,15 @@ INSERT INTO orders (
 )
 RETURNING *;
 
--- name: CloseOpportunityAsLost :exec
+-- name: CloseOpportunityAsLost :one
 UPDATE opportunities
 SET stage = 'closed_lost',
+    probability = 0,
     closed_at = now(),
     updated_at = now()
 WHERE tenant_id = sqlc.arg(tenant_id)
-  AND id = sqlc.arg(opportunity_id);
+  AND id = sqlc.arg(opportunity_id)
+RETURNING *;
 
 -- name: CreateOpportunityLoss :one
 INSERT INTO opportunity_losses (
@@ -162,6 +182,7 @@ INSERT INTO opportunity_losses (
   opportunity_id,
   reason,
   detail,
+  competitor,
   lost_at,
   created_by
 )
@@ -170,7 +191,71 @@ VALUES (
   sqlc.arg(opportunity_id),
   sqlc.arg(reason),
   sqlc.narg(detail),
+  sqlc.narg(competitor),
   coalesce(sqlc.narg(lost_at), now()),
   sqlc.arg(created_by)
 )
 RETURNING *;
+
+-- name: SetOpportunityLossCompetitor :exec
+UPDATE opportunity_losses
+SET competitor = sqlc.narg(competitor)
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id);
+
+-- name: DeleteOpportunityLoss :one
+DELETE FROM opportunity_losses
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+RETURNING *;
+
+-- name: ListAcceptedQuotesForUpdate :many
+SELECT *
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND status = 'accepted'
+ORDER BY updated_at DESC
+FOR UPDATE;
+
+-- name: GetQuoteForUpdate :one
+SELECT *
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+  AND id = sqlc.arg(quote_id)
+FOR UPDATE;
+
+-- name: RejectOpenQuotes :many
+UPDATE quotes q
+SET status = 'rejected',
+    updated_at = now()
+FROM (
+  SELECT o.id, o.status AS previous_status
+  FROM quotes o
+  WHERE o.tenant_id = sqlc.arg(tenant_id)
+    AND o.opportunity_id = sqlc.arg(opportunity_id)
+    AND (sqlc.narg(keep_quote_id)::uuid IS NULL OR o.id <> sqlc.narg(keep_quote_id))
+    AND o.status IN ('draft', 'sent', 'accepted')
+  FOR UPDATE
+) prev
+WHERE q.id = prev.id
+RETURNING q.id, q.quote_no, prev.previous_status;
+
+-- name: CloseOpportunityAsWon :one
+UPDATE opportunities
+SET stage = 'closed_won',
+    probability = 100,
+    amount = sqlc.arg(amount),
+    closed_at = now(),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(opportunity_id)
+RETURNING *;
+
+-- name: UpdateQuoteStatus :exec
+UPDATE quotes
+SET status = sqlc.arg(status),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id);
@@ -0,0 +1,103 @@
+-- name: ListOpportunityTeamMembers :many
+SELECT
+  m.id,
+  m.opportunity_id,
+  m.user_id,
+  u.display_name,
+  u.email,
+  m.team_role,
+  m.split_percent,
+  m.created_by,
+  m.created_at,
+  m.updated_at
+FROM opportunity_team_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.tenant_id = sqlc.arg(tenant_id)
+  AND m.opportunity_id = sqlc.arg(opportunity_id)
+ORDER BY m.split_percent DESC, m.created_at ASC;
+
+-- name: DeleteOpportunityTeamMembers :exec
+DELETE FROM opportunity_team_members
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id);
+
+-- name: CreateOpportunityTeamMember :exec
+INSERT INTO opportunity_team_members (
+  tenant_id,
+  opportunity_id,
+  user_id,
+  team_role,
+  split_percent,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(opportunity_id),
+  sqlc.arg(user_id),
+  sqlc.arg(team_role),
+  sqlc.arg(split_percent),
+  sqlc.narg(created_by)
+);
+
+-- name: IsOpportunityTeamMember :one
+SELECT EXISTS (
+  SELECT 1
+  FROM opportunity_team_members
+  WHERE tenant_id = sqlc.arg(tenant_id)
+    AND opportunity_id = sqlc.arg(opportunity_id)
+    AND user_id = sqlc.arg(user_id)
+)::boolean;
+
+-- Revenue credit per user: deals with a team split their amount by split_percent,
+-- the rest credit the owner in full. Open deals bucket by expected close month and
+-- won deals by close month; amounts convert like GetForecastSummary.
+
+-- name: GetSplitForecastSummary :many
+WITH credits AS (
+  SELECT o.id AS opportunity_id, o.owner_user_id AS user_id, 1::numeric AS share
+  FROM opportunities o
+  WHERE o.tenant_id = sqlc.arg(tenant_id)
+    AND NOT EXISTS (
+      SELECT 1 FROM opportunity_team_members t
+      WHERE t.opportunity_id = o.id AND t.split_percent > 0
+    )
+  UNION ALL
+  SELECT t.opportunity_id, t.user_id, t.split_percent / 100.0
+  FROM opportunity_team_members t
+  WHERE t.tenant_id = sqlc.arg(tenant_id)
+    AND t.split_percent > 0
+), credited AS (
+  SELECT
+    c.user_id,
+    c.share,
+    o.currency,
+    o.amount,
+    o.probability,
+    o.stage = 'closed_won' AS is_won,
+    to_char(date_trunc('month', CASE
+      WHEN o.stage = 'closed_won' THEN coalesce(o.closed_at, o.updated_at)
+      ELSE coalesce(o.expected_close_date::timestamptz, now())
+    END), 'YYYY-MM') AS month_bucket,
+    CASE
+      WHEN o.stage = 'closed_won' THEN fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at, o.updated_at)::date)
+      ELSE fx_rate(o.tenant_id, o.currency, current_date)
+    END AS rate
+  FROM credits c
+  JOIN opportunities o ON o.id = c.opportunity_id
+  WHERE o.stage <> 'closed_lost'
+)
+SELECT
+  user_id,
+  month_bucket,
+  currency,
+  count(*) FILTER (WHERE NOT is_won)::bigint AS deal_count,
+  coalesce(sum(amount * share) FILTER (WHERE NOT is_won), 0)::double precision AS pipeline_amount,
+  coalesce(sum(amount * share * (probability::numeric / 100.0)) FILTER (WHERE NOT is_won), 0)::double precision AS weighted_amount,
+  count(*) FILTER (WHERE is_won)::bigint AS won_count,
+  coalesce(sum(amount * share) FILTER (WHERE is_won), 0)::double precision AS won_amount,
+  coalesce(sum(amount * share * rate) FILTER (WHERE NOT is_won AND rate IS NOT NULL), 0)::double precision AS pipeline_amount_base,
+  coalesce(sum(amount * share * (probability::numeric / 100.0) * rate) FILTER (WHERE NOT is_won AND rate IS NOT NULL), 0)::double precision AS weighted_amount_base,
+  coalesce(sum(amount * share * rate) FILTER (WHERE is_won AND rate IS NOT NULL), 0)::double precision AS won_amount_base,
+  count(*) FILTER (WHERE rate IS NULL)::bigint AS missing_rate_count
+FROM credited
+GROUP BY user_id, month_bucket, currency
+ORDER BY month_bucket ASC, user_id ASC, currency ASC;
@@ -0,0 +1,174 @@
+-- name: GetOrderForUpdate :one
+SELECT *
+FROM orders
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(order_id)
+FOR UPDATE;
+
+-- Back-office listing across the tenant. ordered_from/ordered_to are inclusive dates;
+-- orders without ordered_on fall back to the day they were created.
+-- name: ListOrders :many
+SELECT
+  od.*,
+  o.name AS opportunity_name,
+  o.owner_user_id,
+  o.account_id,
+  a.name AS account_name
+FROM orders od
+JOIN opportunities o ON o.id = od.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+WHERE od.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(statuses)::text[] IS NULL OR od.status::text = ANY(sqlc.narg(statuses)::text[]))
+  AND (sqlc.narg(ordered_from)::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) >= sqlc.narg(ordered_from)::date)
+  AND (sqlc.narg(ordered_to)::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) <= sqlc.narg(ordered_to)::date)
+  AND (sqlc.narg(account_id)::uuid IS NULL OR o.account_id = sqlc.narg(account_id))
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+  AND (
+    sqlc.narg(visible_to)::uuid IS NULL
+    OR o.owner_user_id = sqlc.narg(visible_to)
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = sqlc.narg(visible_to)
+    )
+  )
+ORDER BY coalesce(od.ordered_on, od.created_at::date) DESC, od.created_at DESC, od.id
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: SummarizeOrders :one
+SELECT
+  count(*)::bigint AS total,
+  coalesce(sum(od.amount), 0)::numeric AS total_amount,
+  count(DISTINCT od.currency)::int AS currency_count
+FROM orders od
+JOIN opportunities o ON o.id = od.opportunity_id
+WHERE od.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(statuses)::text[] IS NULL OR od.status::text = ANY(sqlc.narg(statuses)::text[]))
+  AND (sqlc.narg(ordered_from)::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) >= sqlc.narg(ordered_from)::date)
+  AND (sqlc.narg(ordered_to)::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) <= sqlc.narg(ordered_to)::date)
+  AND (sqlc.narg(account_id)::uuid IS NULL OR o.account_id = sqlc.narg(account_id))
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+  AND (
+    sqlc.narg(visible_to)::uuid IS NULL
+    OR o.owner_user_id = sqlc.narg(visible_to)
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = sqlc.narg(visible_to)
+    )
+  );
+
+-- name: TransitionOrderStatus :one
+UPDATE orders
+SET status = sqlc.arg(status),
+    confirmed_at = CASE WHEN sqlc.arg(status) = 'confirmed'::order_status_enum THEN now() ELSE confirmed_at END,
+    invoiced_at = CASE WHEN sqlc.arg(status) = 'invoiced'::order_status_enum THEN now() ELSE invoiced_at END,
+    cancelled_at = CASE WHEN sqlc.arg(status) = 'cancelled'::order_status_enum THEN now() ELSE cancelled_at END,
+    cancel_reason = CASE WHEN sqlc.arg(status) = 'cancelled'::order_status_enum THEN sqlc.narg(cancel_reason)::text ELSE cancel_reason END,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(order_id)
+RETURNING *;
+
+-- name: SetOrderSubscription :one
+UPDATE orders
+SET term_start = sqlc.narg(term_start),
+    term_end = sqlc.narg(term_end),
+    billing_frequency = sqlc.arg(billing_frequency),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(order_id)
+RETURNING *;
+
+-- name: DeleteOrderRevenueSchedule :exec
+DELETE FROM order_revenue_schedules
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND order_id = sqlc.arg(order_id);
+
+-- BuildOrderRevenueSchedule writes the monthly schedule of an order whose old rows were
+-- deleted. Each month gets round(amount * cumulative share) minus the previous month's
+-- cumulative value, so the months always add up to the order amount.
+-- name: BuildOrderRevenueSchedule :execrows
+WITH od AS (
+  SELECT
+    o.id,
+    o.tenant_id,
+    o.amount,
+    o.currency,
+    o.billing_frequency,
+    coalesce(o.term_start, o.ordered_on, o.created_at::date) AS term_start,
+    coalesce(o.term_end, o.ordered_on, o.created_at::date) AS term_end,
+    currency_minor_units(o.currency) AS minor_units
+  FROM orders o
+  WHERE o.tenant_id = sqlc.arg(tenant_id)
+    AND o.id = sqlc.arg(order_id)
+),
+months AS (
+  SELECT
+    m.period_start::date AS period_month,
+    least(od.term_end, (m.period_start + interval '1 month' - interval '1 day')::date)
+      - greatest(od.term_start, m.period_start::date) + 1 AS day_count
+  FROM od
+  CROSS JOIN generate_series(date_trunc('month', od.term_start::timestamp), date_trunc('month', od.term_end::timestamp), interval '1 month') AS m(period_start)
+),
+cumulative_days AS (
+  SELECT
+    mo.period_month,
+    sum(mo.day_count) OVER (ORDER BY mo.period_month) AS days_to_date,
+    sum(mo.day_count) OVER (ORDER BY mo.period_month) - mo.day_count AS days_before
+  FROM months mo
+),
+revenue AS (
+  SELECT
+    cd.period_month,
+    round(od.amount * cd.days_to_date / (od.term_end - od.term_start + 1), od.minor_units)
+      - round(od.amount * cd.days_before / (od.term_end - od.term_start + 1), od.minor_units) AS revenue_amount
+  FROM cumulative_days cd
+  CROSS JOIN od
+),
+billing_periods AS (
+  SELECT
+    date_trunc('month', b.period_start)::date AS period_month,
+    row_number() OVER (ORDER BY b.period_start) AS period_no,
+    count(*) OVER () AS period_count
+  FROM od
+  CROSS JOIN generate_series(
+    od.term_start::timestamp,
+    od.term_end::timestamp,
+    CASE od.billing_frequency
+      WHEN 'monthly' THEN interval '1 month'
+      WHEN 'quarterly' THEN interval '3 months'
+      WHEN 'semi_annual' THEN interval '6 months'
+      WHEN 'annual' THEN interval '12 months'
+      ELSE (od.term_end - od.term_start + 1) * interval '1 day'
+    END
+  ) AS b(period_start)
+),
+billing AS (
+  SELECT
+    bp.period_month,
+    sum(
+      round(od.amount * bp.period_no / bp.period_count, od.minor_units)
+        - round(od.amount * (bp.period_no - 1) / bp.period_count, od.minor_units)
+    ) AS billing_amount
+  FROM billing_periods bp
+  CROSS JOIN od
+  GROUP BY bp.period_month
+)
+INSERT INTO order_revenue_schedules (tenant_id, order_id, period_month, revenue_amount, billing_amount, currency)
+SELECT
+  od.tenant_id AS tenant_id,
+  od.id AS order_id,
+  rv.period_month AS period_month,
+  rv.revenue_amount AS revenue_amount,
+  coalesce(bl.billing_amount, 0) AS billing_amount,
+  od.currency AS currency
+FROM revenue rv
+CROSS JOIN od
+LEFT JOIN billing bl ON bl.period_month = rv.period_month;
+
+-- name: ListOrderRevenueSchedule :many
+SELECT *
+FROM order_revenue_schedules
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND order_id = sqlc.arg(order_id)
+ORDER BY period_month;
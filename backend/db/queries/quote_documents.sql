@@ -0,0 +1,167 @@
+-- name: ListQuotePDFTemplates :many
+SELECT *
+FROM quote_pdf_templates
+WHERE tenant_id = sqlc.arg(tenant_id)
+ORDER BY is_default DESC, name;
+
+-- name: GetQuotePDFTemplate :one
+SELECT *
+FROM quote_pdf_templates
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(template_id);
+
+-- name: GetDefaultQuotePDFTemplate :one
+SELECT *
+FROM quote_pdf_templates
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND is_default;
+
+-- name: CreateQuotePDFTemplate :one
+INSERT INTO quote_pdf_templates (
+  tenant_id,
+  name,
+  language,
+  company_name,
+  company_address,
+  company_phone,
+  registration_number,
+  accent_color,
+  footer_text,
+  is_default,
+  created_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(name),
+  sqlc.arg(language),
+  sqlc.arg(company_name),
+  sqlc.narg(company_address),
+  sqlc.narg(company_phone),
+  sqlc.narg(registration_number),
+  sqlc.arg(accent_color),
+  sqlc.narg(footer_text),
+  sqlc.arg(is_default),
+  sqlc.arg(created_by)
+)
+RETURNING *;
+
+-- name: UpdateQuotePDFTemplate :one
+UPDATE quote_pdf_templates
+SET
+  name = coalesce(sqlc.narg(name), name),
+  language = coalesce(sqlc.narg(language), language),
+  company_name = coalesce(sqlc.narg(company_name), company_name),
+  company_address = coalesce(sqlc.narg(company_address), company_address),
+  company_phone = coalesce(sqlc.narg(company_phone), company_phone),
+  registration_number = coalesce(sqlc.narg(registration_number), registration_number),
+  accent_color = coalesce(sqlc.narg(accent_color), accent_color),
+  footer_text = coalesce(sqlc.narg(footer_text), footer_text),
+  is_default = coalesce(sqlc.narg(is_default), is_default),
+  version = version + 1,
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(template_id)
+RETURNING *;
+
+-- Run before making another template the default so the partial unique index holds.
+-- name: ClearDefaultQuotePDFTemplate :exec
+UPDATE quote_pdf_templates
+SET is_default = false,
+    version = version + 1,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND is_default
+  AND id <> sqlc.arg(keep_template_id);
+
+-- Everything a quote PDF prints besides the quote, its line items and the template.
+-- The billing location falls back to the oldest location when none is flagged.
+-- name: GetQuotePDFContext :one
+SELECT
+  t.name AS tenant_name,
+  a.name AS account_name,
+  o.name AS opportunity_name,
+  coalesce(loc.name, '')::text AS location_name,
+  loc.country,
+  loc.postal_code,
+  loc.prefecture,
+  loc.city,
+  loc.address_line1,
+  loc.address_line2
+FROM quotes qt
+JOIN tenants t ON t.id = qt.tenant_id
+JOIN opportunities o ON o.id = qt.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+LEFT JOIN LATERAL (
+  SELECT al.*
+  FROM account_locations al
+  WHERE al.tenant_id = qt.tenant_id
+    AND al.account_id = a.id
+  ORDER BY al.is_billing DESC, al.created_at ASC
+  LIMIT 1
+) loc ON true
+WHERE qt.tenant_id = sqlc.arg(tenant_id)
+  AND qt.id = sqlc.arg(quote_id);
+
+-- name: GetLatestQuoteDocument :one
+SELECT *
+FROM quote_documents
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND quote_id = sqlc.arg(quote_id)
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: GetQuoteDocument :one
+SELECT *
+FROM quote_documents
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND quote_id = sqlc.arg(quote_id)
+  AND version = sqlc.arg(version);
+
+-- name: ListQuoteDocuments :many
+SELECT
+  id,
+  quote_id,
+  version,
+  quote_version,
+  template_id,
+  template_version,
+  file_name,
+  size_bytes,
+  sha256,
+  created_by,
+  created_at
+FROM quote_documents
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND quote_id = sqlc.arg(quote_id)
+ORDER BY version DESC;
+
+-- The caller holds the quote row lock, so max(version) + 1 cannot race.
+-- name: CreateQuoteDocument :one
+INSERT INTO quote_documents (
+  tenant_id,
+  quote_id,
+  version,
+  quote_version,
+  template_id,
+  template_version,
+  file_name,
+  content,
+  size_bytes,
+  sha256,
+  created_by
+)
+SELECT
+  sqlc.arg(tenant_id),
+  sqlc.arg(quote_id),
+  coalesce(max(qd.version), 0) + 1,
+  sqlc.arg(quote_version),
+  sqlc.narg(template_id),
+  sqlc.narg(template_version),
+  sqlc.arg(file_name),
+  sqlc.arg(content),
+  sqlc.arg(size_bytes),
+  sqlc.arg(sha256),
+  sqlc.arg(created_by)
+FROM quote_documents qd
+WHERE qd.tenant_id = sqlc.arg(tenant_id)
+  AND qd.quote_id = sqlc.arg(quote_id)
+RETURNING *;
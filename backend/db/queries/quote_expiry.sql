@@ -0,0 +1,79 @@
+-- Rows locked by a request or by another replica are skipped and picked up on the next run.
+-- name: ExpireDueQuotes :many
+WITH due AS (
+  SELECT sent.id
+  FROM quotes sent
+  WHERE sent.tenant_id = sqlc.arg(tenant_id)
+    AND sent.status = 'sent'
+    AND sent.valid_until < current_date
+  ORDER BY sent.id
+  LIMIT sqlc.arg(limit_count)
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE quotes q
+SET status = 'expired',
+    expired_at = now(),
+    updated_at = now()
+FROM due, opportunities o
+WHERE q.id = due.id
+  AND o.tenant_id = q.tenant_id
+  AND o.id = q.opportunity_id
+RETURNING
+  q.id,
+  q.quote_no,
+  q.opportunity_id,
+  q.valid_until,
+  o.name AS opportunity_name,
+  o.owner_user_id;
+
+-- Locks the opportunity rather than the quote: request handlers lock the opportunity
+-- first, so the job never waits on a quote while holding something they need.
+-- name: ListQuotesDueForExpiryReminder :many
+SELECT
+  q.id,
+  q.quote_no,
+  q.valid_until,
+  q.opportunity_id,
+  o.name AS opportunity_name,
+  o.owner_user_id
+FROM quotes q
+JOIN opportunities o ON o.tenant_id = q.tenant_id AND o.id = q.opportunity_id
+WHERE q.tenant_id = sqlc.arg(tenant_id)
+  AND q.status = 'sent'
+  AND q.valid_until BETWEEN current_date AND current_date + sqlc.arg(notice_days)::int
+  AND o.stage NOT IN ('closed_won', 'closed_lost')
+  AND NOT EXISTS (
+    SELECT 1
+    FROM quote_expiry_reminders r
+    WHERE r.quote_id = q.id
+      AND r.valid_until = q.valid_until
+  )
+ORDER BY o.id, q.id
+LIMIT sqlc.arg(limit_count)
+FOR UPDATE OF o SKIP LOCKED;
+
+-- Returns no row when another run already claimed the reminder.
+-- name: ClaimQuoteExpiryReminder :one
+INSERT INTO quote_expiry_reminders (
+  tenant_id,
+  quote_id,
+  valid_until,
+  notified_user_id
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(quote_id),
+  sqlc.arg(valid_until),
+  sqlc.arg(notified_user_id)
+)
+ON CONFLICT (quote_id, valid_until) DO NOTHING
+RETURNING *;
+
+-- Keeps a next action the owner already planned before the quote expires.
+-- name: SetOpportunityFollowUp :execrows
+UPDATE opportunities
+SET next_action_at = sqlc.arg(next_action_at),
+    next_action_note = sqlc.arg(next_action_note),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(opportunity_id)
+  AND (next_action_at IS NULL OR next_action_at >= sqlc.arg(expires_at)::timestamptz);
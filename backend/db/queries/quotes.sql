@@ -0,0 +1,98 @@
+-- name: GetQuoteByIDForUpdate :one
+SELECT *
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id)
+FOR UPDATE;
+
+-- name: UpdateQuote :one
+UPDATE quotes
+SET
+  issued_on = coalesce(sqlc.narg(issued_on), issued_on),
+  valid_until = coalesce(sqlc.narg(valid_until), valid_until),
+  note = coalesce(sqlc.narg(note), note),
+  updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id)
+RETURNING *;
+
+-- name: CountQuoteLineItems :one
+SELECT count(*)::bigint
+FROM line_items
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND quote_id = sqlc.arg(quote_id);
+
+-- Sending stamps issued_on (default today) and valid_until (default issued_on + 30 days).
+-- name: TransitionQuoteStatus :one
+UPDATE quotes
+SET status = sqlc.arg(status),
+    issued_on = CASE WHEN sqlc.arg(status) = 'sent'::quote_status_enum THEN coalesce(issued_on, current_date) ELSE issued_on END,
+    valid_until = CASE
+      WHEN sqlc.arg(status) = 'sent'::quote_status_enum THEN coalesce(valid_until, coalesce(issued_on, current_date) + 30)
+      ELSE valid_until
+    END,
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id)
+RETURNING *;
+
+-- name: ListQuoteRevisions :many
+SELECT *
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND coalesce(root_quote_id, id) = sqlc.arg(root_quote_id)
+ORDER BY revision;
+
+-- name: GetMaxQuoteRevision :one
+SELECT max(revision)::int
+FROM quotes
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND coalesce(root_quote_id, id) = sqlc.arg(root_quote_id);
+
+-- A new revision starts as a draft with the source's header, totals and currency;
+-- issued_on and valid_until are stamped again when it is sent.
+-- name: CreateQuoteRevision :one
+INSERT INTO quotes (
+  tenant_id,
+  opportunity_id,
+  quote_no,
+  amount,
+  tax_amount,
+  total_amount,
+  tax_breakdown,
+  currency,
+  status,
+  note,
+  created_by,
+  root_quote_id,
+  previous_quote_id,
+  revision
+)
+SELECT
+  src.tenant_id,
+  src.opportunity_id,
+  sqlc.arg(quote_no)::text AS quote_no,
+  src.amount,
+  src.tax_amount,
+  src.total_amount,
+  src.tax_breakdown,
+  src.currency,
+  'draft'::quote_status_enum AS status,
+  coalesce(sqlc.narg(note)::text, src.note) AS note,
+  sqlc.arg(created_by)::uuid AS created_by,
+  coalesce(src.root_quote_id, src.id) AS root_quote_id,
+  src.id AS previous_quote_id,
+  sqlc.arg(revision)::int AS revision
+FROM quotes src
+WHERE src.tenant_id = sqlc.arg(tenant_id)
+  AND src.id = sqlc.arg(source_quote_id)
+RETURNING *;
+
+-- name: SupersedeQuote :one
+UPDATE quotes
+SET status = 'superseded',
+    superseded_at = now(),
+    updated_at = now()
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND id = sqlc.arg(quote_id)
+RETURNING *;
@@ -0,0 +1,93 @@
+-- name: CreateOpportunityStageHistory :exec
+INSERT INTO opportunity_stage_history (
+  tenant_id,
+  opportunity_id,
+  from_stage,
+  to_stage,
+  amount,
+  probability,
+  changed_by
+) VALUES (
+  sqlc.arg(tenant_id),
+  sqlc.arg(opportunity_id),
+  sqlc.narg(from_stage),
+  sqlc.arg(to_stage),
+  sqlc.arg(amount),
+  sqlc.arg(probability),
+  sqlc.narg(changed_by)
+);
+
+-- name: ListOpportunityStageHistory :many
+SELECT *
+FROM opportunity_stage_history
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND opportunity_id = sqlc.arg(opportunity_id)
+ORDER BY changed_at ASC, id ASC;
+
+-- name: GetStageDurationStats :many
+WITH intervals AS (
+  SELECT
+    h.to_stage AS stage,
+    h.changed_at AS entered_at,
+    lead(h.changed_at) OVER (PARTITION BY h.opportunity_id ORDER BY h.changed_at, h.id) AS exited_at,
+    o.owner_user_id
+  FROM opportunity_stage_history h
+  JOIN opportunities o ON o.id = h.opportunity_id
+  WHERE h.tenant_id = sqlc.arg(tenant_id)
+)
+SELECT
+  i.stage,
+  count(*) FILTER (WHERE i.exited_at IS NOT NULL)::bigint AS completed_count,
+  count(*) FILTER (WHERE i.exited_at IS NULL)::bigint AS open_count,
+  coalesce(
+    avg(EXTRACT(EPOCH FROM (i.exited_at - i.entered_at))) FILTER (WHERE i.exited_at IS NOT NULL) / 86400.0,
+    0
+  )::double precision AS avg_days,
+  coalesce(
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (i.exited_at - i.entered_at))) FILTER (WHERE i.exited_at IS NOT NULL) / 86400.0,
+    0
+  )::double precision AS median_days
+FROM intervals i
+WHERE i.entered_at >= sqlc.arg(range_start)::timestamptz
+  AND i.entered_at < sqlc.arg(range_end)::timestamptz
+  AND i.stage NOT IN ('closed_won', 'closed_lost')
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR i.owner_user_id = sqlc.narg(owner_user_id))
+GROUP BY i.stage
+ORDER BY i.stage;
+
+-- name: GetStageConversionStats :many
+SELECT
+  h.from_stage,
+  h.to_stage,
+  count(*)::bigint AS transition_count,
+  (count(*)::double precision / sum(count(*)) OVER (PARTITION BY h.from_stage))::double precision AS conversion_rate
+FROM opportunity_stage_history h
+JOIN opportunities o ON o.id = h.opportunity_id
+WHERE h.tenant_id = sqlc.arg(tenant_id)
+  AND h.from_stage IS NOT NULL
+  AND h.changed_at >= sqlc.arg(range_start)::timestamptz
+  AND h.changed_at < sqlc.arg(range_end)::timestamptz
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+GROUP BY h.from_stage, h.to_stage
+ORDER BY h.from_stage, h.to_stage;
+
+-- name: GetOwnerVelocityStats :many
+SELECT
+  o.owner_user_id,
+  count(*)::bigint AS closed_count,
+  count(*) FILTER (WHERE o.stage = 'closed_won')::bigint AS won_count,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, o.closed_at::date))
+    FILTER (WHERE o.stage = 'closed_won' AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NOT NULL), 0)::double precision AS won_amount,
+  count(*) FILTER (WHERE o.stage = 'closed_won' AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NULL)::bigint AS missing_rate_count,
+  coalesce(
+    avg(EXTRACT(EPOCH FROM (o.closed_at - o.created_at))) FILTER (WHERE o.stage = 'closed_won') / 86400.0,
+    0
+  )::double precision AS avg_cycle_days
+FROM opportunities o
+WHERE o.tenant_id = sqlc.arg(tenant_id)
+  AND o.stage IN ('closed_won', 'closed_lost')
+  AND o.closed_at >= sqlc.arg(range_start)::timestamptz
+  AND o.closed_at < sqlc.arg(range_end)::timestamptz
+  AND (sqlc.narg(owner_user_id)::uuid IS NULL OR o.owner_user_id = sqlc.narg(owner_user_id))
+GROUP BY o.owner_user_id
+ORDER BY won_amount DESC, o.owner_user_id ASC;
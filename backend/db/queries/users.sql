@@ -39,3 +39,10 @@ SET role = sqlc.arg(role), updated_at = now()
 WHERE tenant_id = sqlc.arg(tenant_id)
   AND user_id = sqlc.arg(user_id)
 RETURNING *;
+
+-- name: GetActiveMembershipRole :one
+SELECT role
+FROM memberships
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND user_id = sqlc.arg(user_id)
+  AND is_active;
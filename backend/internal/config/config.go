@@ -14,6 +14,12 @@ type Config struct {
 	JWTAudience     string
 	AccessTokenTTL  time.Duration
 	RefreshTokenTTL time.Duration
+	PDFFontPath     string
+
+	QuoteExpiryInterval   time.Duration
+	QuoteExpiryNoticeDays int
+
+	ApprovalEscalationInterval time.Duration
 }
 
 func Load() Config {
@@ -25,6 +31,12 @@ func Load() Config {
 		JWTAudience:     getEnv("APP_JWT_AUDIENCE", "sfa-web"),
 		AccessTokenTTL:  time.Duration(getEnvInt("APP_JWT_ACCESS_TTL_MINUTES", 15)) * time.Minute,
 		RefreshTokenTTL: time.Duration(getEnvInt("APP_JWT_REFRESH_TTL_HOURS", 720)) * time.Hour,
+		PDFFontPath:     getEnv("APP_PDF_FONT_PATH", "/usr/share/fonts/ipaex/ipaexg.ttf"),
+
+		QuoteExpiryInterval:   time.Duration(getEnvInt("APP_QUOTE_EXPIRY_INTERVAL_MINUTES", 60)) * time.Minute,
+		QuoteExpiryNoticeDays: getEnvInt("APP_QUOTE_EXPIRY_NOTICE_DAYS", 3),
+
+		ApprovalEscalationInterval: time.Duration(getEnvInt("APP_APPROVAL_ESCALATION_INTERVAL_MINUTES", 15)) * time.Minute,
 	}
 }
 
@@ -11,6 +11,26 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const clearBillingLocation = `-- name: ClearBillingLocation :exec
+UPDATE account_locations
+SET is_billing = false,
+    updated_at = now()
+WHERE tenant_id = $1
+  AND account_id = $2
+  AND is_billing
+`
+
+type ClearBillingLocationParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	AccountID pgtype.UUID `json:"account_id"`
+}
+
+// Run before flagging another billing location so the partial unique index holds.
+func (q *Queries) ClearBillingLocation(ctx context.Context, arg ClearBillingLocationParams) error {
+	_, err := q.db.Exec(ctx, clearBillingLocation, arg.TenantID, arg.AccountID)
+	return err
+}
+
 const countAccounts = `-- name: CountAccounts :one
 SELECT count(*)::bigint
 FROM accounts
@@ -54,7 +74,7 @@ INSERT INTO accounts (
   $8,
   $9
 )
-RETURNING id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at
+RETURNING id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at, version
 `
 
 type CreateAccountParams struct {
@@ -95,6 +115,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -127,7 +148,7 @@ INSERT INTO contacts (
   $11,
   $12
 )
-RETURNING id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at
+RETURNING id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at, version
 `
 
 type CreateContactParams struct {
@@ -177,6 +198,7 @@ func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (C
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -191,7 +213,8 @@ INSERT INTO account_locations (
   prefecture,
   city,
   address_line1,
-  address_line2
+  address_line2,
+  is_billing
 ) VALUES (
   $1,
   $2,
@@ -201,9 +224,10 @@ INSERT INTO account_locations (
   $6,
   $7,
   $8,
-  $9
+  $9,
+  $10
 )
-RETURNING id, tenant_id, account_id, name, country, postal_code, prefecture, city, address_line1, address_line2, created_at, updated_at
+RETURNING id, tenant_id, account_id, name, country, postal_code, prefecture, city, address_line1, address_line2, created_at, updated_at, is_billing
 `
 
 type CreateLocationParams struct {
@@ -216,6 +240,7 @@ type CreateLocationParams struct {
 	City         pgtype.Text `json:"city"`
 	AddressLine1 pgtype.Text `json:"address_line1"`
 	AddressLine2 pgtype.Text `json:"address_line2"`
+	IsBilling    bool        `json:"is_billing"`
 }
 
 func (q *Queries) CreateLocation(ctx context.Context, arg CreateLocationParams) (AccountLocation, error) {
@@ -229,6 +254,7 @@ func (q *Queries) CreateLocation(ctx context.Context, arg CreateLocationParams)
 		arg.City,
 		arg.AddressLine1,
 		arg.AddressLine2,
+		arg.IsBilling,
 	)
 	var i AccountLocation
 	err := row.Scan(
@@ -244,12 +270,421 @@ func (q *Queries) CreateLocation(ctx context.Context, arg CreateLocationParams)
 		&i.AddressLine2,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsBilling,
+	)
+	return i, err
+}
+
+const getAccount = `-- name: GetAccount :one
+SELECT id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at, version
+FROM accounts
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetAccountParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	AccountID pgtype.UUID `json:"account_id"`
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccount, arg.TenantID, arg.AccountID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Industry,
+		&i.Website,
+		&i.Phone,
+		&i.Status,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at, version
+FROM accounts
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetAccountForUpdateParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	AccountID pgtype.UUID `json:"account_id"`
+}
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, arg GetAccountForUpdateParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountForUpdate, arg.TenantID, arg.AccountID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Industry,
+		&i.Website,
+		&i.Phone,
+		&i.Status,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getContact = `-- name: GetContact :one
+SELECT id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at, version
+FROM contacts
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetContactParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	ContactID pgtype.UUID `json:"contact_id"`
+}
+
+func (q *Queries) GetContact(ctx context.Context, arg GetContactParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, getContact, arg.TenantID, arg.ContactID)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.LocationID,
+		&i.OwnerUserID,
+		&i.FullName,
+		&i.Department,
+		&i.Title,
+		&i.Email,
+		&i.Phone,
+		&i.IsPrimary,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getContactForUpdate = `-- name: GetContactForUpdate :one
+SELECT id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at, version
+FROM contacts
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetContactForUpdateParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	ContactID pgtype.UUID `json:"contact_id"`
+}
+
+func (q *Queries) GetContactForUpdate(ctx context.Context, arg GetContactForUpdateParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, getContactForUpdate, arg.TenantID, arg.ContactID)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.LocationID,
+		&i.OwnerUserID,
+		&i.FullName,
+		&i.Department,
+		&i.Title,
+		&i.Email,
+		&i.Phone,
+		&i.IsPrimary,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
+const listAccountTimeline = `-- name: ListAccountTimeline :many
+WITH account_opportunities AS (
+  SELECT o.id
+  FROM opportunities o
+  WHERE o.tenant_id = $6
+    AND o.account_id = $7
+    AND (
+      $8::uuid IS NULL
+      OR o.owner_user_id = $8
+      OR o.id IN (
+        SELECT t.opportunity_id FROM opportunity_team_members t
+        WHERE t.user_id = $8
+      )
+    )
+),
+account_contacts AS (
+  SELECT c.id
+  FROM contacts c
+  WHERE c.tenant_id = $6
+    AND c.account_id = $7
+),
+account_quotes AS (
+  SELECT q.id
+  FROM quotes q
+  WHERE q.tenant_id = $6
+    AND q.opportunity_id IN (SELECT id FROM account_opportunities)
+),
+account_orders AS (
+  SELECT od.id
+  FROM orders od
+  WHERE od.tenant_id = $6
+    AND od.opportunity_id IN (SELECT id FROM account_opportunities)
+),
+status_audits AS (
+  SELECT al.id, al.tenant_id, al.actor_user_id, al.action, al.entity_type, al.entity_id, al.metadata, al.ip_address, al.user_agent, al.created_at
+  FROM audit_logs al
+  WHERE al.tenant_id = $6
+    AND al.entity_type IN ('quote', 'order')
+    AND (al.action = 'create' OR al.metadata ->> 'event' IN ('status_changed', 'status_change'))
+),
+timeline AS (
+  SELECT
+    'activity'::text AS item_type,
+    a.id::text AS item_id,
+    a.activity_at AS occurred_at,
+    a.opportunity_id AS opportunity_id,
+    'opportunity'::text AS entity_type,
+    a.opportunity_id AS entity_id,
+    a.activity_type::text AS kind,
+    a.subject AS title,
+    a.detail AS detail,
+    a.created_by AS actor_user_id,
+    CASE
+      WHEN a.source_event_id IS NOT NULL THEN jsonb_build_object('sourceEventId', a.source_event_id, 'externalEventId', a.external_event_id)
+      ELSE '{}'::jsonb
+    END AS metadata
+  FROM activities a
+  WHERE a.tenant_id = $6
+    AND a.opportunity_id IN (SELECT id FROM account_opportunities)
+  UNION ALL
+  SELECT
+    'integration_event'::text,
+    e.id::text,
+    e.occurred_at,
+    e.linked_opportunity_id,
+    CASE
+      WHEN e.linked_contact_id IS NOT NULL THEN 'contact'
+      ELSE 'account'
+    END,
+    coalesce(e.linked_contact_id, e.linked_account_id, $7),
+    e.integration_type::text,
+    e.event_type,
+    NULL::text,
+    NULL::uuid,
+    e.payload
+  FROM integration_events e
+  WHERE e.tenant_id = $6
+    AND (
+      e.linked_account_id = $7
+      OR e.linked_contact_id IN (SELECT id FROM account_contacts)
+      OR e.linked_opportunity_id IN (SELECT id FROM account_opportunities)
+    )
+    AND (e.linked_opportunity_id IS NULL OR e.linked_opportunity_id IN (SELECT id FROM account_opportunities))
+    -- Events already converted into activities are shown once, as the activity.
+    AND NOT EXISTS (
+      SELECT 1
+      FROM activities ea
+      WHERE ea.tenant_id = e.tenant_id
+        AND ea.source_event_id = e.id
+    )
+  UNION ALL
+  SELECT
+    'quote'::text,
+    sa.id::text,
+    sa.created_at,
+    q.opportunity_id,
+    'quote'::text,
+    q.id,
+    CASE WHEN sa.action = 'create' THEN 'draft' ELSE sa.metadata ->> 'toStatus' END,
+    q.quote_no,
+    sa.metadata ->> 'reason',
+    sa.actor_user_id,
+    jsonb_strip_nulls(jsonb_build_object(
+      'fromStatus', sa.metadata -> 'fromStatus',
+      'toStatus', coalesce(sa.metadata -> 'toStatus', to_jsonb('draft'::text)),
+      'revision', q.revision
+    ))
+  FROM status_audits sa
+  JOIN quotes q ON q.tenant_id = sa.tenant_id AND q.id = sa.entity_id
+  WHERE sa.entity_type = 'quote'
+    AND q.id IN (SELECT id FROM account_quotes)
+  UNION ALL
+  SELECT
+    'order'::text,
+    sa.id::text,
+    sa.created_at,
+    od.opportunity_id,
+    'order'::text,
+    od.id,
+    CASE WHEN sa.action = 'create' THEN 'pending' ELSE sa.metadata ->> 'toStatus' END,
+    od.order_no,
+    sa.metadata ->> 'reason',
+    sa.actor_user_id,
+    jsonb_strip_nulls(jsonb_build_object(
+      'fromStatus', sa.metadata -> 'fromStatus',
+      'toStatus', coalesce(sa.metadata -> 'toStatus', to_jsonb('pending'::text))
+    ))
+  FROM status_audits sa
+  JOIN orders od ON od.tenant_id = sa.tenant_id AND od.id = sa.entity_id
+  WHERE sa.entity_type = 'order'
+    AND od.id IN (SELECT id FROM account_orders)
+  UNION ALL
+  SELECT
+    'approval'::text,
+    ar.id::text,
+    coalesce(ar.decided_at, ar.created_at),
+    NULL::uuid,
+    ar.entity_type,
+    ar.entity_id,
+    ar.status::text,
+    ar.reason,
+    ar.decision_note,
+    ar.requested_by,
+    jsonb_build_object('approverUserId', ar.approver_user_id)
+  FROM approval_requests ar
+  WHERE ar.tenant_id = $6
+    AND (
+      ar.entity_id IN (SELECT id FROM account_opportunities)
+      OR ar.entity_id IN (SELECT id FROM account_quotes)
+      OR ar.entity_id IN (SELECT id FROM account_orders)
+      OR (ar.entity_type = 'account_ownership_transfer' AND ar.entity_id = $7)
+    )
+  UNION ALL
+  SELECT
+    'audit_log'::text,
+    al.id::text,
+    al.created_at,
+    NULL::uuid,
+    coalesce(al.entity_type, ''),
+    al.entity_id,
+    al.action::text,
+    coalesce(al.entity_type, '') || ':' || al.action::text,
+    NULL::text,
+    al.actor_user_id,
+    al.metadata
+  FROM audit_logs al
+  WHERE al.tenant_id = $6
+    AND (
+      al.entity_id = $7
+      OR al.entity_id IN (SELECT id FROM account_contacts)
+      OR al.entity_id IN (SELECT id FROM account_opportunities)
+      OR al.entity_id IN (SELECT id FROM account_quotes)
+      OR al.entity_id IN (SELECT id FROM account_orders)
+    )
+    AND al.id NOT IN (SELECT id FROM status_audits)
+)
+SELECT
+  t.item_type,
+  t.item_id,
+  t.occurred_at,
+  t.opportunity_id,
+  t.entity_type,
+  t.entity_id,
+  t.kind,
+  t.title,
+  t.detail,
+  t.actor_user_id,
+  t.metadata
+FROM timeline t
+WHERE ($1::text[] IS NULL OR t.item_type = ANY($1::text[]))
+  AND (
+    $2::timestamptz IS NULL
+    OR (t.occurred_at, t.item_type, t.item_id) < ($2::timestamptz, $3::text, $4::text)
+  )
+ORDER BY t.occurred_at DESC, t.item_type DESC, t.item_id DESC
+LIMIT $5
+`
+
+type ListAccountTimelineParams struct {
+	ItemTypes  []string           `json:"item_types"`
+	CursorAt   pgtype.Timestamptz `json:"cursor_at"`
+	CursorType pgtype.Text        `json:"cursor_type"`
+	CursorID   pgtype.Text        `json:"cursor_id"`
+	LimitCount int32              `json:"limit_count"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	AccountID  pgtype.UUID        `json:"account_id"`
+	VisibleTo  pgtype.UUID        `json:"visible_to"`
+}
+
+type ListAccountTimelineRow struct {
+	ItemType      string             `json:"item_type"`
+	ItemID        string             `json:"item_id"`
+	OccurredAt    pgtype.Timestamptz `json:"occurred_at"`
+	OpportunityID pgtype.UUID        `json:"opportunity_id"`
+	EntityType    string             `json:"entity_type"`
+	EntityID      pgtype.UUID        `json:"entity_id"`
+	Kind          string             `json:"kind"`
+	Title         string             `json:"title"`
+	Detail        pgtype.Text        `json:"detail"`
+	ActorUserID   pgtype.UUID        `json:"actor_user_id"`
+	Metadata      []byte             `json:"metadata"`
+}
+
+// ListAccountTimeline merges the account's feed newest first. visible_to narrows the
+// opportunity-bound items to deals the user owns or is on the team of (sales users).
+// Quote and order entries are their status changes, read from audit_logs; those audit
+// rows are not repeated as audit_log items.
+func (q *Queries) ListAccountTimeline(ctx context.Context, arg ListAccountTimelineParams) ([]ListAccountTimelineRow, error) {
+	rows, err := q.db.Query(ctx, listAccountTimeline,
+		arg.ItemTypes,
+		arg.CursorAt,
+		arg.CursorType,
+		arg.CursorID,
+		arg.LimitCount,
+		arg.TenantID,
+		arg.AccountID,
+		arg.VisibleTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountTimelineRow{}
+	for rows.Next() {
+		var i ListAccountTimelineRow
+		if err := rows.Scan(
+			&i.ItemType,
+			&i.ItemID,
+			&i.OccurredAt,
+			&i.OpportunityID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Kind,
+			&i.Title,
+			&i.Detail,
+			&i.ActorUserID,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at
+SELECT id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at, version
 FROM accounts
 WHERE tenant_id = $1
   AND ($2::account_status_enum IS NULL OR status = $2)
@@ -295,6 +730,7 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -307,7 +743,7 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 }
 
 const listContactsByAccount = `-- name: ListContactsByAccount :many
-SELECT id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at
+SELECT id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at, version
 FROM contacts
 WHERE tenant_id = $1
   AND account_id = $2
@@ -353,6 +789,7 @@ func (q *Queries) ListContactsByAccount(ctx context.Context, arg ListContactsByA
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -365,7 +802,7 @@ func (q *Queries) ListContactsByAccount(ctx context.Context, arg ListContactsByA
 }
 
 const listLocationsByAccount = `-- name: ListLocationsByAccount :many
-SELECT id, tenant_id, account_id, name, country, postal_code, prefecture, city, address_line1, address_line2, created_at, updated_at
+SELECT id, tenant_id, account_id, name, country, postal_code, prefecture, city, address_line1, address_line2, created_at, updated_at, is_billing
 FROM account_locations
 WHERE tenant_id = $1
   AND account_id = $2
@@ -399,6 +836,7 @@ func (q *Queries) ListLocationsByAccount(ctx context.Context, arg ListLocationsB
 			&i.AddressLine2,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsBilling,
 		); err != nil {
 			return nil, err
 		}
@@ -423,7 +861,7 @@ SET
   updated_at = now()
 WHERE tenant_id = $8
   AND id = $9
-RETURNING id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at
+RETURNING id, tenant_id, owner_user_id, name, industry, website, phone, status, memo, created_by, created_at, updated_at, version
 `
 
 type UpdateAccountParams struct {
@@ -464,6 +902,75 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateContact = `-- name: UpdateContact :one
+UPDATE contacts
+SET
+  location_id = coalesce($1, location_id),
+  owner_user_id = coalesce($2, owner_user_id),
+  full_name = coalesce($3, full_name),
+  department = coalesce($4, department),
+  title = coalesce($5, title),
+  email = coalesce($6, email),
+  phone = coalesce($7, phone),
+  is_primary = coalesce($8::boolean, is_primary),
+  memo = coalesce($9, memo),
+  updated_at = now()
+WHERE tenant_id = $10
+  AND id = $11
+RETURNING id, tenant_id, account_id, location_id, owner_user_id, full_name, department, title, email, phone, is_primary, memo, created_by, created_at, updated_at, version
+`
+
+type UpdateContactParams struct {
+	LocationID  pgtype.UUID `json:"location_id"`
+	OwnerUserID pgtype.UUID `json:"owner_user_id"`
+	FullName    pgtype.Text `json:"full_name"`
+	Department  pgtype.Text `json:"department"`
+	Title       pgtype.Text `json:"title"`
+	Email       pgtype.Text `json:"email"`
+	Phone       pgtype.Text `json:"phone"`
+	IsPrimary   pgtype.Bool `json:"is_primary"`
+	Memo        pgtype.Text `json:"memo"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	ContactID   pgtype.UUID `json:"contact_id"`
+}
+
+func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error) {
+	row := q.db.QueryRow(ctx, updateContact,
+		arg.LocationID,
+		arg.OwnerUserID,
+		arg.FullName,
+		arg.Department,
+		arg.Title,
+		arg.Email,
+		arg.Phone,
+		arg.IsPrimary,
+		arg.Memo,
+		arg.TenantID,
+		arg.ContactID,
+	)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.LocationID,
+		&i.OwnerUserID,
+		&i.FullName,
+		&i.Department,
+		&i.Title,
+		&i.Email,
+		&i.Phone,
+		&i.IsPrimary,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -0,0 +1,802 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: activities.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countActivitiesByOpportunity = `-- name: CountActivitiesByOpportunity :one
+SELECT count(*)::bigint
+FROM activities
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND ($3::activity_type_enum IS NULL OR activity_type = $3::activity_type_enum)
+  AND ($4::boolean IS NULL OR (completed_at IS NOT NULL) = $4::boolean)
+`
+
+type CountActivitiesByOpportunityParams struct {
+	TenantID      pgtype.UUID          `json:"tenant_id"`
+	OpportunityID pgtype.UUID          `json:"opportunity_id"`
+	ActivityType  NullActivityTypeEnum `json:"activity_type"`
+	Completed     pgtype.Bool          `json:"completed"`
+}
+
+func (q *Queries) CountActivitiesByOpportunity(ctx context.Context, arg CountActivitiesByOpportunityParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countActivitiesByOpportunity,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.ActivityType,
+		arg.Completed,
+	)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countOpenTasksByAssignee = `-- name: CountOpenTasksByAssignee :one
+SELECT
+  count(*)::bigint AS open_count,
+  count(*) FILTER (WHERE a.due_at < now())::bigint AS overdue_count
+FROM activities a
+WHERE a.tenant_id = $1
+  AND a.activity_type = 'task'
+  AND a.completed_at IS NULL
+  AND a.assignee_user_id = $2
+  AND (NOT $3::boolean OR a.due_at < now())
+  AND ($4::timestamptz IS NULL OR a.due_at < $4::timestamptz)
+`
+
+type CountOpenTasksByAssigneeParams struct {
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	AssigneeUserID pgtype.UUID        `json:"assignee_user_id"`
+	OverdueOnly    bool               `json:"overdue_only"`
+	DueBefore      pgtype.Timestamptz `json:"due_before"`
+}
+
+type CountOpenTasksByAssigneeRow struct {
+	OpenCount    int64 `json:"open_count"`
+	OverdueCount int64 `json:"overdue_count"`
+}
+
+func (q *Queries) CountOpenTasksByAssignee(ctx context.Context, arg CountOpenTasksByAssigneeParams) (CountOpenTasksByAssigneeRow, error) {
+	row := q.db.QueryRow(ctx, countOpenTasksByAssignee,
+		arg.TenantID,
+		arg.AssigneeUserID,
+		arg.OverdueOnly,
+		arg.DueBefore,
+	)
+	var i CountOpenTasksByAssigneeRow
+	err := row.Scan(&i.OpenCount, &i.OverdueCount)
+	return i, err
+}
+
+const createActivity = `-- name: CreateActivity :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  assignee_user_id,
+  due_at,
+  priority,
+  completed_at,
+  completed_by,
+  recurrence_rule,
+  recurrence_series_id,
+  recurrence_anchor,
+  recurrence_seq,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11,
+  $12,
+  $13,
+  $14,
+  $15,
+  $16
+)
+RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+`
+
+type CreateActivityParams struct {
+	TenantID           pgtype.UUID          `json:"tenant_id"`
+	OpportunityID      pgtype.UUID          `json:"opportunity_id"`
+	ActivityType       ActivityTypeEnum     `json:"activity_type"`
+	Subject            string               `json:"subject"`
+	Detail             pgtype.Text          `json:"detail"`
+	ActivityAt         pgtype.Timestamptz   `json:"activity_at"`
+	AssigneeUserID     pgtype.UUID          `json:"assignee_user_id"`
+	DueAt              pgtype.Timestamptz   `json:"due_at"`
+	Priority           NullTaskPriorityEnum `json:"priority"`
+	CompletedAt        pgtype.Timestamptz   `json:"completed_at"`
+	CompletedBy        pgtype.UUID          `json:"completed_by"`
+	RecurrenceRule     pgtype.Text          `json:"recurrence_rule"`
+	RecurrenceSeriesID pgtype.UUID          `json:"recurrence_series_id"`
+	RecurrenceAnchor   pgtype.Timestamptz   `json:"recurrence_anchor"`
+	RecurrenceSeq      pgtype.Int4          `json:"recurrence_seq"`
+	CreatedBy          pgtype.UUID          `json:"created_by"`
+}
+
+func (q *Queries) CreateActivity(ctx context.Context, arg CreateActivityParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, createActivity,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.ActivityType,
+		arg.Subject,
+		arg.Detail,
+		arg.ActivityAt,
+		arg.AssigneeUserID,
+		arg.DueAt,
+		arg.Priority,
+		arg.CompletedAt,
+		arg.CompletedBy,
+		arg.RecurrenceRule,
+		arg.RecurrenceSeriesID,
+		arg.RecurrenceAnchor,
+		arg.RecurrenceSeq,
+		arg.CreatedBy,
+	)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
+
+const createNextOccurrence = `-- name: CreateNextOccurrence :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  assignee_user_id,
+  due_at,
+  priority,
+  recurrence_rule,
+  recurrence_series_id,
+  recurrence_anchor,
+  recurrence_seq,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  'task',
+  $3,
+  $4,
+  $5,
+  $6,
+  $5,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11,
+  $12
+)
+ON CONFLICT (recurrence_series_id, recurrence_seq) WHERE recurrence_series_id IS NOT NULL DO NOTHING
+RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+`
+
+type CreateNextOccurrenceParams struct {
+	TenantID           pgtype.UUID          `json:"tenant_id"`
+	OpportunityID      pgtype.UUID          `json:"opportunity_id"`
+	Subject            string               `json:"subject"`
+	Detail             pgtype.Text          `json:"detail"`
+	DueAt              pgtype.Timestamptz   `json:"due_at"`
+	AssigneeUserID     pgtype.UUID          `json:"assignee_user_id"`
+	Priority           NullTaskPriorityEnum `json:"priority"`
+	RecurrenceRule     pgtype.Text          `json:"recurrence_rule"`
+	RecurrenceSeriesID pgtype.UUID          `json:"recurrence_series_id"`
+	RecurrenceAnchor   pgtype.Timestamptz   `json:"recurrence_anchor"`
+	RecurrenceSeq      pgtype.Int4          `json:"recurrence_seq"`
+	CreatedBy          pgtype.UUID          `json:"created_by"`
+}
+
+// Returns no row when the occurrence already exists (task completed, reopened and
+// completed again).
+func (q *Queries) CreateNextOccurrence(ctx context.Context, arg CreateNextOccurrenceParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, createNextOccurrence,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.Subject,
+		arg.Detail,
+		arg.DueAt,
+		arg.AssigneeUserID,
+		arg.Priority,
+		arg.RecurrenceRule,
+		arg.RecurrenceSeriesID,
+		arg.RecurrenceAnchor,
+		arg.RecurrenceSeq,
+		arg.CreatedBy,
+	)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
+
+const deleteActivity = `-- name: DeleteActivity :execrows
+DELETE FROM activities
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type DeleteActivityParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ActivityID pgtype.UUID `json:"activity_id"`
+}
+
+func (q *Queries) DeleteActivity(ctx context.Context, arg DeleteActivityParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteActivity, arg.TenantID, arg.ActivityID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getActivityForUpdate = `-- name: GetActivityForUpdate :one
+SELECT id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+FROM activities
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetActivityForUpdateParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ActivityID pgtype.UUID `json:"activity_id"`
+}
+
+func (q *Queries) GetActivityForUpdate(ctx context.Context, arg GetActivityForUpdateParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, getActivityForUpdate, arg.TenantID, arg.ActivityID)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
+
+const listActivitiesByOpportunity = `-- name: ListActivitiesByOpportunity :many
+SELECT id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+FROM activities
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND ($3::activity_type_enum IS NULL OR activity_type = $3::activity_type_enum)
+  AND ($4::boolean IS NULL OR (completed_at IS NOT NULL) = $4::boolean)
+ORDER BY activity_at DESC, id DESC
+LIMIT $6
+OFFSET $5
+`
+
+type ListActivitiesByOpportunityParams struct {
+	TenantID      pgtype.UUID          `json:"tenant_id"`
+	OpportunityID pgtype.UUID          `json:"opportunity_id"`
+	ActivityType  NullActivityTypeEnum `json:"activity_type"`
+	Completed     pgtype.Bool          `json:"completed"`
+	OffsetCount   int32                `json:"offset_count"`
+	LimitCount    int32                `json:"limit_count"`
+}
+
+func (q *Queries) ListActivitiesByOpportunity(ctx context.Context, arg ListActivitiesByOpportunityParams) ([]Activity, error) {
+	rows, err := q.db.Query(ctx, listActivitiesByOpportunity,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.ActivityType,
+		arg.Completed,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Activity{}
+	for rows.Next() {
+		var i Activity
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.ActivityType,
+			&i.Subject,
+			&i.Detail,
+			&i.ActivityAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.AssigneeUserID,
+			&i.DueAt,
+			&i.Priority,
+			&i.CompletedAt,
+			&i.CompletedBy,
+			&i.UpdatedAt,
+			&i.RecurrenceRule,
+			&i.RecurrenceSeriesID,
+			&i.RecurrenceAnchor,
+			&i.RecurrenceSeq,
+			&i.SourceEventID,
+			&i.ExternalEventID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTasksByAssignee = `-- name: ListOpenTasksByAssignee :many
+SELECT
+  a.id, a.tenant_id, a.opportunity_id, a.activity_type, a.subject, a.detail, a.activity_at, a.created_by, a.created_at, a.assignee_user_id, a.due_at, a.priority, a.completed_at, a.completed_by, a.updated_at, a.recurrence_rule, a.recurrence_series_id, a.recurrence_anchor, a.recurrence_seq, a.source_event_id, a.external_event_id,
+  o.name AS opportunity_name,
+  o.account_id
+FROM activities a
+JOIN opportunities o ON o.id = a.opportunity_id
+WHERE a.tenant_id = $1
+  AND a.activity_type = 'task'
+  AND a.completed_at IS NULL
+  AND a.assignee_user_id = $2
+  AND (NOT $3::boolean OR a.due_at < now())
+  AND ($4::timestamptz IS NULL OR a.due_at < $4::timestamptz)
+ORDER BY a.due_at ASC NULLS LAST, a.priority DESC, a.id
+LIMIT $6
+OFFSET $5
+`
+
+type ListOpenTasksByAssigneeParams struct {
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	AssigneeUserID pgtype.UUID        `json:"assignee_user_id"`
+	OverdueOnly    bool               `json:"overdue_only"`
+	DueBefore      pgtype.Timestamptz `json:"due_before"`
+	OffsetCount    int32              `json:"offset_count"`
+	LimitCount     int32              `json:"limit_count"`
+}
+
+type ListOpenTasksByAssigneeRow struct {
+	ID                 pgtype.UUID          `json:"id"`
+	TenantID           pgtype.UUID          `json:"tenant_id"`
+	OpportunityID      pgtype.UUID          `json:"opportunity_id"`
+	ActivityType       ActivityTypeEnum     `json:"activity_type"`
+	Subject            string               `json:"subject"`
+	Detail             pgtype.Text          `json:"detail"`
+	ActivityAt         pgtype.Timestamptz   `json:"activity_at"`
+	CreatedBy          pgtype.UUID          `json:"created_by"`
+	CreatedAt          pgtype.Timestamptz   `json:"created_at"`
+	AssigneeUserID     pgtype.UUID          `json:"assignee_user_id"`
+	DueAt              pgtype.Timestamptz   `json:"due_at"`
+	Priority           NullTaskPriorityEnum `json:"priority"`
+	CompletedAt        pgtype.Timestamptz   `json:"completed_at"`
+	CompletedBy        pgtype.UUID          `json:"completed_by"`
+	UpdatedAt          pgtype.Timestamptz   `json:"updated_at"`
+	RecurrenceRule     pgtype.Text          `json:"recurrence_rule"`
+	RecurrenceSeriesID pgtype.UUID          `json:"recurrence_series_id"`
+	RecurrenceAnchor   pgtype.Timestamptz   `json:"recurrence_anchor"`
+	RecurrenceSeq      pgtype.Int4          `json:"recurrence_seq"`
+	SourceEventID      pgtype.Int8          `json:"source_event_id"`
+	ExternalEventID    pgtype.Text          `json:"external_event_id"`
+	OpportunityName    string               `json:"opportunity_name"`
+	AccountID          pgtype.UUID          `json:"account_id"`
+}
+
+func (q *Queries) ListOpenTasksByAssignee(ctx context.Context, arg ListOpenTasksByAssigneeParams) ([]ListOpenTasksByAssigneeRow, error) {
+	rows, err := q.db.Query(ctx, listOpenTasksByAssignee,
+		arg.TenantID,
+		arg.AssigneeUserID,
+		arg.OverdueOnly,
+		arg.DueBefore,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpenTasksByAssigneeRow{}
+	for rows.Next() {
+		var i ListOpenTasksByAssigneeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.ActivityType,
+			&i.Subject,
+			&i.Detail,
+			&i.ActivityAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.AssigneeUserID,
+			&i.DueAt,
+			&i.Priority,
+			&i.CompletedAt,
+			&i.CompletedBy,
+			&i.UpdatedAt,
+			&i.RecurrenceRule,
+			&i.RecurrenceSeriesID,
+			&i.RecurrenceAnchor,
+			&i.RecurrenceSeq,
+			&i.SourceEventID,
+			&i.ExternalEventID,
+			&i.OpportunityName,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingEventActivities = `-- name: ListPendingEventActivities :many
+SELECT e.id
+FROM integration_events e
+WHERE e.tenant_id = $1
+  AND e.id > $2
+  AND e.linked_opportunity_id IS NOT NULL
+  AND e.external_event_id IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1
+    FROM activities a
+    WHERE a.tenant_id = e.tenant_id
+      AND a.external_event_id = e.external_event_id
+      AND a.activity_type = CASE e.integration_type WHEN 'email' THEN 'email' ELSE 'meeting' END::activity_type_enum
+      AND a.source_event_id IS NOT NULL
+      AND (a.completed_at IS NOT NULL OR e.occurred_at > now())
+  )
+ORDER BY e.id
+LIMIT $3
+`
+
+type ListPendingEventActivitiesParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	AfterID    int64       `json:"after_id"`
+	LimitCount int32       `json:"limit_count"`
+}
+
+// Events that still need UpsertActivityFromEvent: linked, keyed, and either without an
+// activity or with a past meeting still open. Keyset-paged by event id.
+func (q *Queries) ListPendingEventActivities(ctx context.Context, arg ListPendingEventActivitiesParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listPendingEventActivities, arg.TenantID, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTenantIDs = `-- name: ListTenantIDs :many
+SELECT id
+FROM tenants
+ORDER BY id
+`
+
+func (q *Queries) ListTenantIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listTenantIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setActivityRecurrence = `-- name: SetActivityRecurrence :one
+UPDATE activities
+SET
+  recurrence_rule = $1,
+  recurrence_series_id = CASE WHEN $1::text IS NULL THEN recurrence_series_id ELSE $2::uuid END,
+  recurrence_anchor = CASE WHEN $1::text IS NULL THEN recurrence_anchor ELSE due_at END,
+  recurrence_seq = CASE WHEN $1::text IS NULL THEN recurrence_seq ELSE 1 END,
+  updated_at = now()
+WHERE tenant_id = $3
+  AND id = $4
+RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+`
+
+type SetActivityRecurrenceParams struct {
+	RecurrenceRule pgtype.Text `json:"recurrence_rule"`
+	SeriesID       pgtype.UUID `json:"series_id"`
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	ActivityID     pgtype.UUID `json:"activity_id"`
+}
+
+// Setting a rule starts a new series anchored at the task's current due date; a NULL
+// rule stops the recurrence and keeps the series columns for history.
+func (q *Queries) SetActivityRecurrence(ctx context.Context, arg SetActivityRecurrenceParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, setActivityRecurrence,
+		arg.RecurrenceRule,
+		arg.SeriesID,
+		arg.TenantID,
+		arg.ActivityID,
+	)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
+
+const updateActivity = `-- name: UpdateActivity :one
+UPDATE activities
+SET
+  subject = coalesce($1, subject),
+  detail = coalesce($2, detail),
+  activity_at = coalesce($3, activity_at),
+  assignee_user_id = coalesce($4, assignee_user_id),
+  due_at = coalesce($5, due_at),
+  priority = coalesce($6, priority),
+  completed_at = CASE
+    WHEN $7::boolean IS NULL THEN completed_at
+    WHEN $7::boolean THEN coalesce(completed_at, now())
+    ELSE NULL
+  END,
+  completed_by = CASE
+    WHEN $7::boolean IS NULL THEN completed_by
+    WHEN $7::boolean THEN coalesce(completed_by, $8)
+    ELSE NULL
+  END,
+  updated_at = now()
+WHERE tenant_id = $9
+  AND id = $10
+RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+`
+
+type UpdateActivityParams struct {
+	Subject        pgtype.Text          `json:"subject"`
+	Detail         pgtype.Text          `json:"detail"`
+	ActivityAt     pgtype.Timestamptz   `json:"activity_at"`
+	AssigneeUserID pgtype.UUID          `json:"assignee_user_id"`
+	DueAt          pgtype.Timestamptz   `json:"due_at"`
+	Priority       NullTaskPriorityEnum `json:"priority"`
+	Completed      pgtype.Bool          `json:"completed"`
+	ActorUserID    pgtype.UUID          `json:"actor_user_id"`
+	TenantID       pgtype.UUID          `json:"tenant_id"`
+	ActivityID     pgtype.UUID          `json:"activity_id"`
+}
+
+// completed: NULL leaves completion alone, true completes (keeping an earlier
+// completion time), false reopens.
+func (q *Queries) UpdateActivity(ctx context.Context, arg UpdateActivityParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, updateActivity,
+		arg.Subject,
+		arg.Detail,
+		arg.ActivityAt,
+		arg.AssigneeUserID,
+		arg.DueAt,
+		arg.Priority,
+		arg.Completed,
+		arg.ActorUserID,
+		arg.TenantID,
+		arg.ActivityID,
+	)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
+
+const upsertActivityFromEvent = `-- name: UpsertActivityFromEvent :one
+INSERT INTO activities (
+  tenant_id,
+  opportunity_id,
+  activity_type,
+  subject,
+  detail,
+  activity_at,
+  completed_at,
+  source_event_id,
+  external_event_id,
+  created_by
+)
+SELECT
+  e.tenant_id,
+  e.linked_opportunity_id,
+  CASE e.integration_type WHEN 'email' THEN 'email' ELSE 'meeting' END::activity_type_enum,
+  coalesce(
+    nullif(btrim(e.payload->>'subject'), ''),
+    nullif(btrim(e.payload->>'title'), ''),
+    nullif(btrim(e.payload->>'summary'), ''),
+    e.event_type
+  ),
+  coalesce(nullif(e.payload->>'snippet', ''), nullif(e.payload->>'description', '')),
+  e.occurred_at,
+  CASE WHEN e.occurred_at <= now() THEN e.occurred_at END,
+  e.id,
+  e.external_event_id,
+  o.owner_user_id
+FROM integration_events e
+JOIN opportunities o ON o.tenant_id = e.tenant_id AND o.id = e.linked_opportunity_id
+WHERE e.tenant_id = $1
+  AND e.id = $2
+  AND e.external_event_id IS NOT NULL
+ON CONFLICT (tenant_id, activity_type, external_event_id) WHERE external_event_id IS NOT NULL DO UPDATE
+SET
+  completed_at = coalesce(activities.completed_at, EXCLUDED.completed_at),
+  source_event_id = coalesce(activities.source_event_id, EXCLUDED.source_event_id),
+  updated_at = now()
+WHERE (activities.completed_at IS NULL AND EXCLUDED.completed_at IS NOT NULL)
+  OR activities.source_event_id IS NULL
+RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at, assignee_user_id, due_at, priority, completed_at, completed_by, updated_at, recurrence_rule, recurrence_series_id, recurrence_anchor, recurrence_seq, source_event_id, external_event_id
+`
+
+type UpsertActivityFromEventParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	EventID  int64       `json:"event_id"`
+}
+
+// Converts one linked integration event into an email or meeting activity owned by the
+// opportunity owner. Future meetings stay open; re-running completes them once they have
+// happened. Returns no row when the event is not convertible or nothing changed.
+func (q *Queries) UpsertActivityFromEvent(ctx context.Context, arg UpsertActivityFromEventParams) (Activity, error) {
+	row := q.db.QueryRow(ctx, upsertActivityFromEvent, arg.TenantID, arg.EventID)
+	var i Activity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.ActivityType,
+		&i.Subject,
+		&i.Detail,
+		&i.ActivityAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.AssigneeUserID,
+		&i.DueAt,
+		&i.Priority,
+		&i.CompletedAt,
+		&i.CompletedBy,
+		&i.UpdatedAt,
+		&i.RecurrenceRule,
+		&i.RecurrenceSeriesID,
+		&i.RecurrenceAnchor,
+		&i.RecurrenceSeq,
+		&i.SourceEventID,
+		&i.ExternalEventID,
+	)
+	return i, err
+}
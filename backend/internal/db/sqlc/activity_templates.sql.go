@@ -0,0 +1,288 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: activity_templates.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createActivityTemplate = `-- name: CreateActivityTemplate :one
+INSERT INTO activity_templates (
+  tenant_id,
+  name,
+  description,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+RETURNING id, tenant_id, name, description, is_active, created_by, created_at, updated_at
+`
+
+type CreateActivityTemplateParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	CreatedBy   pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateActivityTemplate(ctx context.Context, arg CreateActivityTemplateParams) (ActivityTemplate, error) {
+	row := q.db.QueryRow(ctx, createActivityTemplate,
+		arg.TenantID,
+		arg.Name,
+		arg.Description,
+		arg.CreatedBy,
+	)
+	var i ActivityTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createActivityTemplateStep = `-- name: CreateActivityTemplateStep :exec
+INSERT INTO activity_template_steps (
+  tenant_id,
+  template_id,
+  position,
+  subject,
+  detail,
+  offset_days,
+  priority,
+  recurrence_rule
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+)
+`
+
+type CreateActivityTemplateStepParams struct {
+	TenantID       pgtype.UUID      `json:"tenant_id"`
+	TemplateID     pgtype.UUID      `json:"template_id"`
+	Position       int32            `json:"position"`
+	Subject        string           `json:"subject"`
+	Detail         pgtype.Text      `json:"detail"`
+	OffsetDays     int32            `json:"offset_days"`
+	Priority       TaskPriorityEnum `json:"priority"`
+	RecurrenceRule pgtype.Text      `json:"recurrence_rule"`
+}
+
+func (q *Queries) CreateActivityTemplateStep(ctx context.Context, arg CreateActivityTemplateStepParams) error {
+	_, err := q.db.Exec(ctx, createActivityTemplateStep,
+		arg.TenantID,
+		arg.TemplateID,
+		arg.Position,
+		arg.Subject,
+		arg.Detail,
+		arg.OffsetDays,
+		arg.Priority,
+		arg.RecurrenceRule,
+	)
+	return err
+}
+
+const deleteActivityTemplateSteps = `-- name: DeleteActivityTemplateSteps :exec
+DELETE FROM activity_template_steps
+WHERE tenant_id = $1
+  AND template_id = $2
+`
+
+type DeleteActivityTemplateStepsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	TemplateID pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) DeleteActivityTemplateSteps(ctx context.Context, arg DeleteActivityTemplateStepsParams) error {
+	_, err := q.db.Exec(ctx, deleteActivityTemplateSteps, arg.TenantID, arg.TemplateID)
+	return err
+}
+
+const getActivityTemplate = `-- name: GetActivityTemplate :one
+SELECT id, tenant_id, name, description, is_active, created_by, created_at, updated_at
+FROM activity_templates
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetActivityTemplateParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	TemplateID pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) GetActivityTemplate(ctx context.Context, arg GetActivityTemplateParams) (ActivityTemplate, error) {
+	row := q.db.QueryRow(ctx, getActivityTemplate, arg.TenantID, arg.TemplateID)
+	var i ActivityTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listActivityTemplateSteps = `-- name: ListActivityTemplateSteps :many
+SELECT id, tenant_id, template_id, position, subject, detail, offset_days, priority, recurrence_rule
+FROM activity_template_steps
+WHERE tenant_id = $1
+  AND template_id = $2
+ORDER BY position
+`
+
+type ListActivityTemplateStepsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	TemplateID pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) ListActivityTemplateSteps(ctx context.Context, arg ListActivityTemplateStepsParams) ([]ActivityTemplateStep, error) {
+	rows, err := q.db.Query(ctx, listActivityTemplateSteps, arg.TenantID, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ActivityTemplateStep{}
+	for rows.Next() {
+		var i ActivityTemplateStep
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.TemplateID,
+			&i.Position,
+			&i.Subject,
+			&i.Detail,
+			&i.OffsetDays,
+			&i.Priority,
+			&i.RecurrenceRule,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActivityTemplates = `-- name: ListActivityTemplates :many
+SELECT
+  t.id, t.tenant_id, t.name, t.description, t.is_active, t.created_by, t.created_at, t.updated_at,
+  (SELECT count(*) FROM activity_template_steps s WHERE s.template_id = t.id)::bigint AS step_count
+FROM activity_templates t
+WHERE t.tenant_id = $1
+  AND ($2::boolean IS NULL OR t.is_active = $2::boolean)
+ORDER BY t.name
+`
+
+type ListActivityTemplatesParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	IsActive pgtype.Bool `json:"is_active"`
+}
+
+type ListActivityTemplatesRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	IsActive    bool               `json:"is_active"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	StepCount   int64              `json:"step_count"`
+}
+
+func (q *Queries) ListActivityTemplates(ctx context.Context, arg ListActivityTemplatesParams) ([]ListActivityTemplatesRow, error) {
+	rows, err := q.db.Query(ctx, listActivityTemplates, arg.TenantID, arg.IsActive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListActivityTemplatesRow{}
+	for rows.Next() {
+		var i ListActivityTemplatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.Description,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StepCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateActivityTemplate = `-- name: UpdateActivityTemplate :one
+UPDATE activity_templates
+SET
+  name = coalesce($1, name),
+  description = coalesce($2, description),
+  is_active = coalesce($3, is_active),
+  updated_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, name, description, is_active, created_by, created_at, updated_at
+`
+
+type UpdateActivityTemplateParams struct {
+	Name        pgtype.Text `json:"name"`
+	Description pgtype.Text `json:"description"`
+	IsActive    pgtype.Bool `json:"is_active"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	TemplateID  pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) UpdateActivityTemplate(ctx context.Context, arg UpdateActivityTemplateParams) (ActivityTemplate, error) {
+	row := q.db.QueryRow(ctx, updateActivityTemplate,
+		arg.Name,
+		arg.Description,
+		arg.IsActive,
+		arg.TenantID,
+		arg.TemplateID,
+	)
+	var i ActivityTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
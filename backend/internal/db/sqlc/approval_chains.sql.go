@@ -0,0 +1,1463 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: approval_chains.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const activateApprovalRequestStep = `-- name: ActivateApprovalRequestStep :one
+UPDATE approval_request_steps
+SET status = 'pending',
+    activated_at = now(),
+    sla_due_at = CASE WHEN sla_hours IS NULL THEN NULL ELSE now() + sla_hours * interval '1 hour' END
+WHERE tenant_id = $1
+  AND id = $2
+RETURNING id, tenant_id, approval_request_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, sla_hours, escalation_user_id, status, activated_at, sla_due_at, escalated_at, decided_by, decided_at, decision_note, created_at
+`
+
+type ActivateApprovalRequestStepParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	StepID   pgtype.UUID `json:"step_id"`
+}
+
+func (q *Queries) ActivateApprovalRequestStep(ctx context.Context, arg ActivateApprovalRequestStepParams) (ApprovalRequestStep, error) {
+	row := q.db.QueryRow(ctx, activateApprovalRequestStep, arg.TenantID, arg.StepID)
+	var i ApprovalRequestStep
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ApprovalRequestID,
+		&i.StepOrder,
+		&i.Name,
+		&i.ApproverType,
+		&i.ApproverUserID,
+		&i.ApproverRole,
+		&i.ApproverTeamRole,
+		&i.SlaHours,
+		&i.EscalationUserID,
+		&i.Status,
+		&i.ActivatedAt,
+		&i.SlaDueAt,
+		&i.EscalatedAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.DecisionNote,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const addApprovalStepApprover = `-- name: AddApprovalStepApprover :execrows
+INSERT INTO approval_step_approvers (
+  step_id,
+  user_id,
+  tenant_id,
+  source,
+  delegated_from
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+ON CONFLICT (step_id, user_id) DO NOTHING
+`
+
+type AddApprovalStepApproverParams struct {
+	StepID        pgtype.UUID `json:"step_id"`
+	UserID        pgtype.UUID `json:"user_id"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	Source        string      `json:"source"`
+	DelegatedFrom pgtype.UUID `json:"delegated_from"`
+}
+
+func (q *Queries) AddApprovalStepApprover(ctx context.Context, arg AddApprovalStepApproverParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addApprovalStepApprover,
+		arg.StepID,
+		arg.UserID,
+		arg.TenantID,
+		arg.Source,
+		arg.DelegatedFrom,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const advanceApprovalRequest = `-- name: AdvanceApprovalRequest :one
+UPDATE approval_requests
+SET current_step = $1,
+    approver_user_id = $2,
+    updated_at = now()
+WHERE tenant_id = $3
+  AND id = $4
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type AdvanceApprovalRequestParams struct {
+	CurrentStep    pgtype.Int4 `json:"current_step"`
+	ApproverUserID pgtype.UUID `json:"approver_user_id"`
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	ApprovalID     pgtype.UUID `json:"approval_id"`
+}
+
+func (q *Queries) AdvanceApprovalRequest(ctx context.Context, arg AdvanceApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, advanceApprovalRequest,
+		arg.CurrentStep,
+		arg.ApproverUserID,
+		arg.TenantID,
+		arg.ApprovalID,
+	)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
+const cancelOpenApprovalRequestSteps = `-- name: CancelOpenApprovalRequestSteps :exec
+UPDATE approval_request_steps
+SET status = 'cancelled'
+WHERE tenant_id = $1
+  AND approval_request_id = ANY($2::uuid[])
+  AND status IN ('waiting', 'pending')
+`
+
+type CancelOpenApprovalRequestStepsParams struct {
+	TenantID           pgtype.UUID   `json:"tenant_id"`
+	ApprovalRequestIds []pgtype.UUID `json:"approval_request_ids"`
+}
+
+func (q *Queries) CancelOpenApprovalRequestSteps(ctx context.Context, arg CancelOpenApprovalRequestStepsParams) error {
+	_, err := q.db.Exec(ctx, cancelOpenApprovalRequestSteps, arg.TenantID, arg.ApprovalRequestIds)
+	return err
+}
+
+const countOverlappingDelegations = `-- name: CountOverlappingDelegations :one
+SELECT count(*)::bigint
+FROM approval_delegations
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND starts_at < $3
+  AND ends_at > $4
+`
+
+type CountOverlappingDelegationsParams struct {
+	TenantID pgtype.UUID        `json:"tenant_id"`
+	UserID   pgtype.UUID        `json:"user_id"`
+	EndsAt   pgtype.Timestamptz `json:"ends_at"`
+	StartsAt pgtype.Timestamptz `json:"starts_at"`
+}
+
+func (q *Queries) CountOverlappingDelegations(ctx context.Context, arg CountOverlappingDelegationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOverlappingDelegations,
+		arg.TenantID,
+		arg.UserID,
+		arg.EndsAt,
+		arg.StartsAt,
+	)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createApprovalChain = `-- name: CreateApprovalChain :one
+INSERT INTO approval_chains (
+  tenant_id,
+  name,
+  description,
+  is_active,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5
+)
+RETURNING id, tenant_id, name, description, is_active, created_by, created_at, updated_at, version
+`
+
+type CreateApprovalChainParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	IsActive    bool        `json:"is_active"`
+	CreatedBy   pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateApprovalChain(ctx context.Context, arg CreateApprovalChainParams) (ApprovalChain, error) {
+	row := q.db.QueryRow(ctx, createApprovalChain,
+		arg.TenantID,
+		arg.Name,
+		arg.Description,
+		arg.IsActive,
+		arg.CreatedBy,
+	)
+	var i ApprovalChain
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createApprovalChainStep = `-- name: CreateApprovalChainStep :one
+INSERT INTO approval_chain_steps (
+  tenant_id,
+  chain_id,
+  step_order,
+  name,
+  approver_type,
+  approver_user_id,
+  approver_role,
+  approver_team_role,
+  min_value,
+  sla_hours,
+  escalation_user_id
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11
+)
+RETURNING id, tenant_id, chain_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, min_value, sla_hours, escalation_user_id, created_at
+`
+
+type CreateApprovalChainStepParams struct {
+	TenantID         pgtype.UUID                 `json:"tenant_id"`
+	ChainID          pgtype.UUID                 `json:"chain_id"`
+	StepOrder        int32                       `json:"step_order"`
+	Name             string                      `json:"name"`
+	ApproverType     ApprovalApproverTypeEnum    `json:"approver_type"`
+	ApproverUserID   pgtype.UUID                 `json:"approver_user_id"`
+	ApproverRole     NullRoleEnum                `json:"approver_role"`
+	ApproverTeamRole NullOpportunityTeamRoleEnum `json:"approver_team_role"`
+	MinValue         pgtype.Numeric              `json:"min_value"`
+	SlaHours         pgtype.Int4                 `json:"sla_hours"`
+	EscalationUserID pgtype.UUID                 `json:"escalation_user_id"`
+}
+
+func (q *Queries) CreateApprovalChainStep(ctx context.Context, arg CreateApprovalChainStepParams) (ApprovalChainStep, error) {
+	row := q.db.QueryRow(ctx, createApprovalChainStep,
+		arg.TenantID,
+		arg.ChainID,
+		arg.StepOrder,
+		arg.Name,
+		arg.ApproverType,
+		arg.ApproverUserID,
+		arg.ApproverRole,
+		arg.ApproverTeamRole,
+		arg.MinValue,
+		arg.SlaHours,
+		arg.EscalationUserID,
+	)
+	var i ApprovalChainStep
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ChainID,
+		&i.StepOrder,
+		&i.Name,
+		&i.ApproverType,
+		&i.ApproverUserID,
+		&i.ApproverRole,
+		&i.ApproverTeamRole,
+		&i.MinValue,
+		&i.SlaHours,
+		&i.EscalationUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createApprovalDelegation = `-- name: CreateApprovalDelegation :one
+INSERT INTO approval_delegations (
+  tenant_id,
+  user_id,
+  delegate_user_id,
+  starts_at,
+  ends_at,
+  reason
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+RETURNING id, tenant_id, user_id, delegate_user_id, starts_at, ends_at, reason, created_at
+`
+
+type CreateApprovalDelegationParams struct {
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	DelegateUserID pgtype.UUID        `json:"delegate_user_id"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `json:"ends_at"`
+	Reason         pgtype.Text        `json:"reason"`
+}
+
+func (q *Queries) CreateApprovalDelegation(ctx context.Context, arg CreateApprovalDelegationParams) (ApprovalDelegation, error) {
+	row := q.db.QueryRow(ctx, createApprovalDelegation,
+		arg.TenantID,
+		arg.UserID,
+		arg.DelegateUserID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+	)
+	var i ApprovalDelegation
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.UserID,
+		&i.DelegateUserID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createApprovalRequestEvent = `-- name: CreateApprovalRequestEvent :exec
+INSERT INTO approval_request_events (
+  tenant_id,
+  approval_request_id,
+  step_order,
+  event,
+  actor_user_id,
+  note,
+  data
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+`
+
+type CreateApprovalRequestEventParams struct {
+	TenantID          pgtype.UUID `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID `json:"approval_request_id"`
+	StepOrder         pgtype.Int4 `json:"step_order"`
+	Event             string      `json:"event"`
+	ActorUserID       pgtype.UUID `json:"actor_user_id"`
+	Note              pgtype.Text `json:"note"`
+	Data              []byte      `json:"data"`
+}
+
+func (q *Queries) CreateApprovalRequestEvent(ctx context.Context, arg CreateApprovalRequestEventParams) error {
+	_, err := q.db.Exec(ctx, createApprovalRequestEvent,
+		arg.TenantID,
+		arg.ApprovalRequestID,
+		arg.StepOrder,
+		arg.Event,
+		arg.ActorUserID,
+		arg.Note,
+		arg.Data,
+	)
+	return err
+}
+
+const createApprovalRequestStep = `-- name: CreateApprovalRequestStep :one
+INSERT INTO approval_request_steps (
+  tenant_id,
+  approval_request_id,
+  step_order,
+  name,
+  approver_type,
+  approver_user_id,
+  approver_role,
+  approver_team_role,
+  sla_hours,
+  escalation_user_id,
+  status
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11
+)
+RETURNING id, tenant_id, approval_request_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, sla_hours, escalation_user_id, status, activated_at, sla_due_at, escalated_at, decided_by, decided_at, decision_note, created_at
+`
+
+type CreateApprovalRequestStepParams struct {
+	TenantID          pgtype.UUID                 `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID                 `json:"approval_request_id"`
+	StepOrder         int32                       `json:"step_order"`
+	Name              string                      `json:"name"`
+	ApproverType      ApprovalApproverTypeEnum    `json:"approver_type"`
+	ApproverUserID    pgtype.UUID                 `json:"approver_user_id"`
+	ApproverRole      NullRoleEnum                `json:"approver_role"`
+	ApproverTeamRole  NullOpportunityTeamRoleEnum `json:"approver_team_role"`
+	SlaHours          pgtype.Int4                 `json:"sla_hours"`
+	EscalationUserID  pgtype.UUID                 `json:"escalation_user_id"`
+	Status            ApprovalStepStatusEnum      `json:"status"`
+}
+
+func (q *Queries) CreateApprovalRequestStep(ctx context.Context, arg CreateApprovalRequestStepParams) (ApprovalRequestStep, error) {
+	row := q.db.QueryRow(ctx, createApprovalRequestStep,
+		arg.TenantID,
+		arg.ApprovalRequestID,
+		arg.StepOrder,
+		arg.Name,
+		arg.ApproverType,
+		arg.ApproverUserID,
+		arg.ApproverRole,
+		arg.ApproverTeamRole,
+		arg.SlaHours,
+		arg.EscalationUserID,
+		arg.Status,
+	)
+	var i ApprovalRequestStep
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ApprovalRequestID,
+		&i.StepOrder,
+		&i.Name,
+		&i.ApproverType,
+		&i.ApproverUserID,
+		&i.ApproverRole,
+		&i.ApproverTeamRole,
+		&i.SlaHours,
+		&i.EscalationUserID,
+		&i.Status,
+		&i.ActivatedAt,
+		&i.SlaDueAt,
+		&i.EscalatedAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.DecisionNote,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createChainApprovalRequest = `-- name: CreateChainApprovalRequest :one
+INSERT INTO approval_requests (
+  tenant_id,
+  entity_type,
+  entity_id,
+  requested_by,
+  approver_user_id,
+  reason,
+  policy_id,
+  metric_value,
+  chain_id,
+  current_step
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10
+)
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type CreateChainApprovalRequestParams struct {
+	TenantID       pgtype.UUID    `json:"tenant_id"`
+	EntityType     string         `json:"entity_type"`
+	EntityID       pgtype.UUID    `json:"entity_id"`
+	RequestedBy    pgtype.UUID    `json:"requested_by"`
+	ApproverUserID pgtype.UUID    `json:"approver_user_id"`
+	Reason         string         `json:"reason"`
+	PolicyID       pgtype.UUID    `json:"policy_id"`
+	MetricValue    pgtype.Numeric `json:"metric_value"`
+	ChainID        pgtype.UUID    `json:"chain_id"`
+	CurrentStep    pgtype.Int4    `json:"current_step"`
+}
+
+func (q *Queries) CreateChainApprovalRequest(ctx context.Context, arg CreateChainApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, createChainApprovalRequest,
+		arg.TenantID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.RequestedBy,
+		arg.ApproverUserID,
+		arg.Reason,
+		arg.PolicyID,
+		arg.MetricValue,
+		arg.ChainID,
+		arg.CurrentStep,
+	)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
+const decideApprovalRequestStep = `-- name: DecideApprovalRequestStep :one
+UPDATE approval_request_steps
+SET status = $1,
+    decided_by = $2,
+    decision_note = $3,
+    decided_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, approval_request_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, sla_hours, escalation_user_id, status, activated_at, sla_due_at, escalated_at, decided_by, decided_at, decision_note, created_at
+`
+
+type DecideApprovalRequestStepParams struct {
+	Status       ApprovalStepStatusEnum `json:"status"`
+	DecidedBy    pgtype.UUID            `json:"decided_by"`
+	DecisionNote pgtype.Text            `json:"decision_note"`
+	TenantID     pgtype.UUID            `json:"tenant_id"`
+	StepID       pgtype.UUID            `json:"step_id"`
+}
+
+func (q *Queries) DecideApprovalRequestStep(ctx context.Context, arg DecideApprovalRequestStepParams) (ApprovalRequestStep, error) {
+	row := q.db.QueryRow(ctx, decideApprovalRequestStep,
+		arg.Status,
+		arg.DecidedBy,
+		arg.DecisionNote,
+		arg.TenantID,
+		arg.StepID,
+	)
+	var i ApprovalRequestStep
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ApprovalRequestID,
+		&i.StepOrder,
+		&i.Name,
+		&i.ApproverType,
+		&i.ApproverUserID,
+		&i.ApproverRole,
+		&i.ApproverTeamRole,
+		&i.SlaHours,
+		&i.EscalationUserID,
+		&i.Status,
+		&i.ActivatedAt,
+		&i.SlaDueAt,
+		&i.EscalatedAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.DecisionNote,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteApprovalChainSteps = `-- name: DeleteApprovalChainSteps :exec
+DELETE FROM approval_chain_steps
+WHERE tenant_id = $1
+  AND chain_id = $2
+`
+
+type DeleteApprovalChainStepsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ChainID  pgtype.UUID `json:"chain_id"`
+}
+
+func (q *Queries) DeleteApprovalChainSteps(ctx context.Context, arg DeleteApprovalChainStepsParams) error {
+	_, err := q.db.Exec(ctx, deleteApprovalChainSteps, arg.TenantID, arg.ChainID)
+	return err
+}
+
+const deleteApprovalDelegation = `-- name: DeleteApprovalDelegation :execrows
+DELETE FROM approval_delegations
+WHERE tenant_id = $1
+  AND id = $2
+  AND user_id = $3
+`
+
+type DeleteApprovalDelegationParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	DelegationID pgtype.UUID `json:"delegation_id"`
+	UserID       pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteApprovalDelegation(ctx context.Context, arg DeleteApprovalDelegationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteApprovalDelegation, arg.TenantID, arg.DelegationID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const finishApprovalRequest = `-- name: FinishApprovalRequest :one
+UPDATE approval_requests
+SET status = $1,
+    decision_note = $2,
+    current_step = NULL,
+    decided_at = now(),
+    updated_at = now()
+WHERE tenant_id = $3
+  AND id = $4
+  AND status = 'pending'
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type FinishApprovalRequestParams struct {
+	Status       ApprovalStatusEnum `json:"status"`
+	DecisionNote pgtype.Text        `json:"decision_note"`
+	TenantID     pgtype.UUID        `json:"tenant_id"`
+	ApprovalID   pgtype.UUID        `json:"approval_id"`
+}
+
+func (q *Queries) FinishApprovalRequest(ctx context.Context, arg FinishApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, finishApprovalRequest,
+		arg.Status,
+		arg.DecisionNote,
+		arg.TenantID,
+		arg.ApprovalID,
+	)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
+const getActiveDelegation = `-- name: GetActiveDelegation :one
+SELECT id, tenant_id, user_id, delegate_user_id, starts_at, ends_at, reason, created_at
+FROM approval_delegations
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND starts_at <= now()
+  AND ends_at > now()
+ORDER BY starts_at DESC
+LIMIT 1
+`
+
+type GetActiveDelegationParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetActiveDelegation(ctx context.Context, arg GetActiveDelegationParams) (ApprovalDelegation, error) {
+	row := q.db.QueryRow(ctx, getActiveDelegation, arg.TenantID, arg.UserID)
+	var i ApprovalDelegation
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.UserID,
+		&i.DelegateUserID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getApprovalChain = `-- name: GetApprovalChain :one
+SELECT id, tenant_id, name, description, is_active, created_by, created_at, updated_at, version
+FROM approval_chains
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetApprovalChainParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ChainID  pgtype.UUID `json:"chain_id"`
+}
+
+func (q *Queries) GetApprovalChain(ctx context.Context, arg GetApprovalChainParams) (ApprovalChain, error) {
+	row := q.db.QueryRow(ctx, getApprovalChain, arg.TenantID, arg.ChainID)
+	var i ApprovalChain
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getApprovalChainForUpdate = `-- name: GetApprovalChainForUpdate :one
+SELECT id, tenant_id, name, description, is_active, created_by, created_at, updated_at, version
+FROM approval_chains
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetApprovalChainForUpdateParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	ChainID  pgtype.UUID `json:"chain_id"`
+}
+
+func (q *Queries) GetApprovalChainForUpdate(ctx context.Context, arg GetApprovalChainForUpdateParams) (ApprovalChain, error) {
+	row := q.db.QueryRow(ctx, getApprovalChainForUpdate, arg.TenantID, arg.ChainID)
+	var i ApprovalChain
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getApprovalEntityOpportunity = `-- name: GetApprovalEntityOpportunity :one
+SELECT coalesce(
+  (SELECT q.opportunity_id FROM quotes q
+   WHERE $1::text = 'quote' AND q.tenant_id = $2 AND q.id = $3),
+  (SELECT od.opportunity_id FROM orders od
+   WHERE $1::text = 'order' AND od.tenant_id = $2 AND od.id = $3),
+  (SELECT o.id FROM opportunities o
+   WHERE $1::text = 'opportunity_discount' AND o.tenant_id = $2 AND o.id = $3)
+)::uuid AS opportunity_id
+`
+
+type GetApprovalEntityOpportunityParams struct {
+	EntityType string      `json:"entity_type"`
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	EntityID   pgtype.UUID `json:"entity_id"`
+}
+
+// The opportunity an approval subject belongs to; NULL for subjects outside the deal.
+func (q *Queries) GetApprovalEntityOpportunity(ctx context.Context, arg GetApprovalEntityOpportunityParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getApprovalEntityOpportunity, arg.EntityType, arg.TenantID, arg.EntityID)
+	var opportunity_id pgtype.UUID
+	err := row.Scan(&opportunity_id)
+	return opportunity_id, err
+}
+
+const getPendingApprovalRequestStepForUpdate = `-- name: GetPendingApprovalRequestStepForUpdate :one
+SELECT id, tenant_id, approval_request_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, sla_hours, escalation_user_id, status, activated_at, sla_due_at, escalated_at, decided_by, decided_at, decision_note, created_at
+FROM approval_request_steps
+WHERE tenant_id = $1
+  AND approval_request_id = $2
+  AND status = 'pending'
+FOR UPDATE
+`
+
+type GetPendingApprovalRequestStepForUpdateParams struct {
+	TenantID          pgtype.UUID `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID `json:"approval_request_id"`
+}
+
+func (q *Queries) GetPendingApprovalRequestStepForUpdate(ctx context.Context, arg GetPendingApprovalRequestStepForUpdateParams) (ApprovalRequestStep, error) {
+	row := q.db.QueryRow(ctx, getPendingApprovalRequestStepForUpdate, arg.TenantID, arg.ApprovalRequestID)
+	var i ApprovalRequestStep
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.ApprovalRequestID,
+		&i.StepOrder,
+		&i.Name,
+		&i.ApproverType,
+		&i.ApproverUserID,
+		&i.ApproverRole,
+		&i.ApproverTeamRole,
+		&i.SlaHours,
+		&i.EscalationUserID,
+		&i.Status,
+		&i.ActivatedAt,
+		&i.SlaDueAt,
+		&i.EscalatedAt,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.DecisionNote,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listActiveMembersByRole = `-- name: ListActiveMembersByRole :many
+SELECT m.user_id
+FROM memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.tenant_id = $1
+  AND m.role = $2
+  AND m.is_active
+  AND u.is_active
+ORDER BY u.display_name, m.user_id
+`
+
+type ListActiveMembersByRoleParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Role     RoleEnum    `json:"role"`
+}
+
+func (q *Queries) ListActiveMembersByRole(ctx context.Context, arg ListActiveMembersByRoleParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listActiveMembersByRole, arg.TenantID, arg.Role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var user_id pgtype.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalChainSteps = `-- name: ListApprovalChainSteps :many
+SELECT id, tenant_id, chain_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, min_value, sla_hours, escalation_user_id, created_at
+FROM approval_chain_steps
+WHERE tenant_id = $1
+  AND chain_id = ANY($2::uuid[])
+ORDER BY chain_id, step_order
+`
+
+type ListApprovalChainStepsParams struct {
+	TenantID pgtype.UUID   `json:"tenant_id"`
+	ChainIds []pgtype.UUID `json:"chain_ids"`
+}
+
+func (q *Queries) ListApprovalChainSteps(ctx context.Context, arg ListApprovalChainStepsParams) ([]ApprovalChainStep, error) {
+	rows, err := q.db.Query(ctx, listApprovalChainSteps, arg.TenantID, arg.ChainIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalChainStep{}
+	for rows.Next() {
+		var i ApprovalChainStep
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ChainID,
+			&i.StepOrder,
+			&i.Name,
+			&i.ApproverType,
+			&i.ApproverUserID,
+			&i.ApproverRole,
+			&i.ApproverTeamRole,
+			&i.MinValue,
+			&i.SlaHours,
+			&i.EscalationUserID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalChains = `-- name: ListApprovalChains :many
+SELECT id, tenant_id, name, description, is_active, created_by, created_at, updated_at, version
+FROM approval_chains
+WHERE tenant_id = $1
+  AND (NOT $2::boolean OR is_active)
+ORDER BY name
+`
+
+type ListApprovalChainsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ActiveOnly bool        `json:"active_only"`
+}
+
+func (q *Queries) ListApprovalChains(ctx context.Context, arg ListApprovalChainsParams) ([]ApprovalChain, error) {
+	rows, err := q.db.Query(ctx, listApprovalChains, arg.TenantID, arg.ActiveOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalChain{}
+	for rows.Next() {
+		var i ApprovalChain
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.Description,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalDelegations = `-- name: ListApprovalDelegations :many
+SELECT id, tenant_id, user_id, delegate_user_id, starts_at, ends_at, reason, created_at
+FROM approval_delegations
+WHERE tenant_id = $1
+  AND (user_id = $2 OR delegate_user_id = $2)
+  AND ($3::boolean OR ends_at > now())
+ORDER BY starts_at DESC
+`
+
+type ListApprovalDelegationsParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	UserID      pgtype.UUID `json:"user_id"`
+	IncludePast bool        `json:"include_past"`
+}
+
+func (q *Queries) ListApprovalDelegations(ctx context.Context, arg ListApprovalDelegationsParams) ([]ApprovalDelegation, error) {
+	rows, err := q.db.Query(ctx, listApprovalDelegations, arg.TenantID, arg.UserID, arg.IncludePast)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalDelegation{}
+	for rows.Next() {
+		var i ApprovalDelegation
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.UserID,
+			&i.DelegateUserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalEntitySummaries = `-- name: ListApprovalEntitySummaries :many
+WITH e AS (
+  SELECT t.entity_type, i.entity_id
+  FROM unnest($2::text[]) WITH ORDINALITY AS t(entity_type, n)
+  JOIN unnest($3::uuid[]) WITH ORDINALITY AS i(entity_id, n) ON i.n = t.n
+)
+SELECT e.entity_type::text AS entity_type, e.entity_id::uuid AS entity_id, s.summary::jsonb AS summary
+FROM e
+CROSS JOIN LATERAL (
+  SELECT jsonb_build_object(
+    'quoteNo', q.quote_no, 'revision', q.revision, 'status', q.status,
+    'totalAmount', q.total_amount, 'currency', q.currency, 'validUntil', q.valid_until,
+    'opportunityId', o.id, 'opportunityName', o.name, 'accountName', a.name
+  ) AS summary
+  FROM quotes q
+  JOIN opportunities o ON o.tenant_id = q.tenant_id AND o.id = q.opportunity_id
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'quote' AND q.tenant_id = $1 AND q.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'orderNo', od.order_no, 'status', od.status, 'amount', od.amount, 'currency', od.currency,
+    'orderedOn', od.ordered_on, 'opportunityId', o.id, 'opportunityName', o.name, 'accountName', a.name
+  )
+  FROM orders od
+  JOIN opportunities o ON o.tenant_id = od.tenant_id AND o.id = od.opportunity_id
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'order' AND od.tenant_id = $1 AND od.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'name', o.name, 'stage', o.stage, 'amount', o.amount, 'currency', o.currency,
+    'expectedCloseDate', o.expected_close_date, 'ownerUserId', o.owner_user_id, 'accountName', a.name
+  )
+  FROM opportunities o
+  JOIN accounts a ON a.tenant_id = o.tenant_id AND a.id = o.account_id
+  WHERE e.entity_type = 'opportunity_discount' AND o.tenant_id = $1 AND o.id = e.entity_id
+  UNION ALL
+  SELECT jsonb_build_object(
+    'name', a.name, 'status', a.status, 'ownerUserId', a.owner_user_id, 'ownerName', u.display_name
+  )
+  FROM accounts a
+  LEFT JOIN users u ON u.id = a.owner_user_id
+  WHERE e.entity_type = 'account_ownership_transfer' AND a.tenant_id = $1 AND a.id = e.entity_id
+) s
+`
+
+type ListApprovalEntitySummariesParams struct {
+	TenantID    pgtype.UUID   `json:"tenant_id"`
+	EntityTypes []string      `json:"entity_types"`
+	EntityIds   []pgtype.UUID `json:"entity_ids"`
+}
+
+type ListApprovalEntitySummariesRow struct {
+	EntityType string      `json:"entity_type"`
+	EntityID   pgtype.UUID `json:"entity_id"`
+	Summary    []byte      `json:"summary"`
+}
+
+// Summaries of approval subjects, matched pairwise from the two arrays. Subjects that
+// do not exist in the tenant, or whose type is not registered, are left out.
+func (q *Queries) ListApprovalEntitySummaries(ctx context.Context, arg ListApprovalEntitySummariesParams) ([]ListApprovalEntitySummariesRow, error) {
+	rows, err := q.db.Query(ctx, listApprovalEntitySummaries, arg.TenantID, arg.EntityTypes, arg.EntityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListApprovalEntitySummariesRow{}
+	for rows.Next() {
+		var i ListApprovalEntitySummariesRow
+		if err := rows.Scan(&i.EntityType, &i.EntityID, &i.Summary); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalRequestEvents = `-- name: ListApprovalRequestEvents :many
+SELECT e.id, e.tenant_id, e.approval_request_id, e.step_order, e.event, e.actor_user_id, e.note, e.data, e.created_at, u.display_name AS actor_name
+FROM approval_request_events e
+LEFT JOIN users u ON u.id = e.actor_user_id
+WHERE e.tenant_id = $1
+  AND e.approval_request_id = $2
+ORDER BY e.created_at, e.id
+`
+
+type ListApprovalRequestEventsParams struct {
+	TenantID          pgtype.UUID `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID `json:"approval_request_id"`
+}
+
+type ListApprovalRequestEventsRow struct {
+	ID                pgtype.UUID        `json:"id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID        `json:"approval_request_id"`
+	StepOrder         pgtype.Int4        `json:"step_order"`
+	Event             string             `json:"event"`
+	ActorUserID       pgtype.UUID        `json:"actor_user_id"`
+	Note              pgtype.Text        `json:"note"`
+	Data              []byte             `json:"data"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	ActorName         pgtype.Text        `json:"actor_name"`
+}
+
+func (q *Queries) ListApprovalRequestEvents(ctx context.Context, arg ListApprovalRequestEventsParams) ([]ListApprovalRequestEventsRow, error) {
+	rows, err := q.db.Query(ctx, listApprovalRequestEvents, arg.TenantID, arg.ApprovalRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListApprovalRequestEventsRow{}
+	for rows.Next() {
+		var i ListApprovalRequestEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ApprovalRequestID,
+			&i.StepOrder,
+			&i.Event,
+			&i.ActorUserID,
+			&i.Note,
+			&i.Data,
+			&i.CreatedAt,
+			&i.ActorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalRequestSteps = `-- name: ListApprovalRequestSteps :many
+SELECT id, tenant_id, approval_request_id, step_order, name, approver_type, approver_user_id, approver_role, approver_team_role, sla_hours, escalation_user_id, status, activated_at, sla_due_at, escalated_at, decided_by, decided_at, decision_note, created_at
+FROM approval_request_steps
+WHERE tenant_id = $1
+  AND approval_request_id = ANY($2::uuid[])
+ORDER BY approval_request_id, step_order
+`
+
+type ListApprovalRequestStepsParams struct {
+	TenantID           pgtype.UUID   `json:"tenant_id"`
+	ApprovalRequestIds []pgtype.UUID `json:"approval_request_ids"`
+}
+
+func (q *Queries) ListApprovalRequestSteps(ctx context.Context, arg ListApprovalRequestStepsParams) ([]ApprovalRequestStep, error) {
+	rows, err := q.db.Query(ctx, listApprovalRequestSteps, arg.TenantID, arg.ApprovalRequestIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalRequestStep{}
+	for rows.Next() {
+		var i ApprovalRequestStep
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ApprovalRequestID,
+			&i.StepOrder,
+			&i.Name,
+			&i.ApproverType,
+			&i.ApproverUserID,
+			&i.ApproverRole,
+			&i.ApproverTeamRole,
+			&i.SlaHours,
+			&i.EscalationUserID,
+			&i.Status,
+			&i.ActivatedAt,
+			&i.SlaDueAt,
+			&i.EscalatedAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.DecisionNote,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApprovalStepApprovers = `-- name: ListApprovalStepApprovers :many
+SELECT sa.step_id, sa.user_id, sa.tenant_id, sa.source, sa.delegated_from, sa.added_at, u.display_name
+FROM approval_step_approvers sa
+JOIN users u ON u.id = sa.user_id
+WHERE sa.tenant_id = $1
+  AND sa.step_id = ANY($2::uuid[])
+ORDER BY sa.step_id, sa.added_at, u.display_name
+`
+
+type ListApprovalStepApproversParams struct {
+	TenantID pgtype.UUID   `json:"tenant_id"`
+	StepIds  []pgtype.UUID `json:"step_ids"`
+}
+
+type ListApprovalStepApproversRow struct {
+	StepID        pgtype.UUID        `json:"step_id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	Source        string             `json:"source"`
+	DelegatedFrom pgtype.UUID        `json:"delegated_from"`
+	AddedAt       pgtype.Timestamptz `json:"added_at"`
+	DisplayName   string             `json:"display_name"`
+}
+
+func (q *Queries) ListApprovalStepApprovers(ctx context.Context, arg ListApprovalStepApproversParams) ([]ListApprovalStepApproversRow, error) {
+	rows, err := q.db.Query(ctx, listApprovalStepApprovers, arg.TenantID, arg.StepIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListApprovalStepApproversRow{}
+	for rows.Next() {
+		var i ListApprovalStepApproversRow
+		if err := rows.Scan(
+			&i.StepID,
+			&i.UserID,
+			&i.TenantID,
+			&i.Source,
+			&i.DelegatedFrom,
+			&i.AddedAt,
+			&i.DisplayName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpportunityTeamMembersByRole = `-- name: ListOpportunityTeamMembersByRole :many
+SELECT t.user_id
+FROM opportunity_team_members t
+JOIN memberships m ON m.tenant_id = t.tenant_id AND m.user_id = t.user_id AND m.is_active
+WHERE t.tenant_id = $1
+  AND t.opportunity_id = $2
+  AND t.team_role = $3
+ORDER BY t.created_at, t.user_id
+`
+
+type ListOpportunityTeamMembersByRoleParams struct {
+	TenantID      pgtype.UUID             `json:"tenant_id"`
+	OpportunityID pgtype.UUID             `json:"opportunity_id"`
+	TeamRole      OpportunityTeamRoleEnum `json:"team_role"`
+}
+
+func (q *Queries) ListOpportunityTeamMembersByRole(ctx context.Context, arg ListOpportunityTeamMembersByRoleParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listOpportunityTeamMembersByRole, arg.TenantID, arg.OpportunityID, arg.TeamRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.UUID{}
+	for rows.Next() {
+		var user_id pgtype.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverdueApprovalSteps = `-- name: ListOverdueApprovalSteps :many
+SELECT s.id, s.tenant_id, s.approval_request_id, s.step_order, s.name, s.approver_type, s.approver_user_id, s.approver_role, s.approver_team_role, s.sla_hours, s.escalation_user_id, s.status, s.activated_at, s.sla_due_at, s.escalated_at, s.decided_by, s.decided_at, s.decision_note, s.created_at, ar.entity_type, ar.entity_id, ar.reason AS request_reason, ar.requested_by
+FROM approval_request_steps s
+JOIN approval_requests ar ON ar.id = s.approval_request_id
+WHERE s.tenant_id = $1
+  AND s.status = 'pending'
+  AND s.escalated_at IS NULL
+  AND s.sla_due_at <= now()
+  AND ar.status = 'pending'
+ORDER BY s.sla_due_at
+LIMIT $2
+FOR UPDATE OF s SKIP LOCKED
+`
+
+type ListOverdueApprovalStepsParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	LimitCount int32       `json:"limit_count"`
+}
+
+type ListOverdueApprovalStepsRow struct {
+	ID                pgtype.UUID                 `json:"id"`
+	TenantID          pgtype.UUID                 `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID                 `json:"approval_request_id"`
+	StepOrder         int32                       `json:"step_order"`
+	Name              string                      `json:"name"`
+	ApproverType      ApprovalApproverTypeEnum    `json:"approver_type"`
+	ApproverUserID    pgtype.UUID                 `json:"approver_user_id"`
+	ApproverRole      NullRoleEnum                `json:"approver_role"`
+	ApproverTeamRole  NullOpportunityTeamRoleEnum `json:"approver_team_role"`
+	SlaHours          pgtype.Int4                 `json:"sla_hours"`
+	EscalationUserID  pgtype.UUID                 `json:"escalation_user_id"`
+	Status            ApprovalStepStatusEnum      `json:"status"`
+	ActivatedAt       pgtype.Timestamptz          `json:"activated_at"`
+	SlaDueAt          pgtype.Timestamptz          `json:"sla_due_at"`
+	EscalatedAt       pgtype.Timestamptz          `json:"escalated_at"`
+	DecidedBy         pgtype.UUID                 `json:"decided_by"`
+	DecidedAt         pgtype.Timestamptz          `json:"decided_at"`
+	DecisionNote      pgtype.Text                 `json:"decision_note"`
+	CreatedAt         pgtype.Timestamptz          `json:"created_at"`
+	EntityType        string                      `json:"entity_type"`
+	EntityID          pgtype.UUID                 `json:"entity_id"`
+	RequestReason     string                      `json:"request_reason"`
+	RequestedBy       pgtype.UUID                 `json:"requested_by"`
+}
+
+// Pending steps past their SLA that have not been escalated yet. Steps locked by a
+// decision in progress are skipped until the next run.
+func (q *Queries) ListOverdueApprovalSteps(ctx context.Context, arg ListOverdueApprovalStepsParams) ([]ListOverdueApprovalStepsRow, error) {
+	rows, err := q.db.Query(ctx, listOverdueApprovalSteps, arg.TenantID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOverdueApprovalStepsRow{}
+	for rows.Next() {
+		var i ListOverdueApprovalStepsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.ApprovalRequestID,
+			&i.StepOrder,
+			&i.Name,
+			&i.ApproverType,
+			&i.ApproverUserID,
+			&i.ApproverRole,
+			&i.ApproverTeamRole,
+			&i.SlaHours,
+			&i.EscalationUserID,
+			&i.Status,
+			&i.ActivatedAt,
+			&i.SlaDueAt,
+			&i.EscalatedAt,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.DecisionNote,
+			&i.CreatedAt,
+			&i.EntityType,
+			&i.EntityID,
+			&i.RequestReason,
+			&i.RequestedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markApprovalStepEscalated = `-- name: MarkApprovalStepEscalated :exec
+UPDATE approval_request_steps
+SET escalated_at = now()
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type MarkApprovalStepEscalatedParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	StepID   pgtype.UUID `json:"step_id"`
+}
+
+func (q *Queries) MarkApprovalStepEscalated(ctx context.Context, arg MarkApprovalStepEscalatedParams) error {
+	_, err := q.db.Exec(ctx, markApprovalStepEscalated, arg.TenantID, arg.StepID)
+	return err
+}
+
+const updateApprovalChain = `-- name: UpdateApprovalChain :one
+UPDATE approval_chains
+SET
+  name = coalesce($1, name),
+  description = coalesce($2, description),
+  is_active = coalesce($3, is_active),
+  updated_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, name, description, is_active, created_by, created_at, updated_at, version
+`
+
+type UpdateApprovalChainParams struct {
+	Name        pgtype.Text `json:"name"`
+	Description pgtype.Text `json:"description"`
+	IsActive    pgtype.Bool `json:"is_active"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	ChainID     pgtype.UUID `json:"chain_id"`
+}
+
+func (q *Queries) UpdateApprovalChain(ctx context.Context, arg UpdateApprovalChainParams) (ApprovalChain, error) {
+	row := q.db.QueryRow(ctx, updateApprovalChain,
+		arg.Name,
+		arg.Description,
+		arg.IsActive,
+		arg.TenantID,
+		arg.ChainID,
+	)
+	var i ApprovalChain
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
@@ -74,43 +74,85 @@ func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams)
 }
 
 const listAuditLogs = `-- name: ListAuditLogs :many
-SELECT id, tenant_id, actor_user_id, action, entity_type, entity_id, metadata, ip_address, user_agent, created_at
-FROM audit_logs
-WHERE tenant_id = $1
-  AND ($2::audit_action_enum IS NULL OR action = $2)
-  AND ($3::uuid IS NULL OR actor_user_id = $3)
-ORDER BY created_at DESC
-LIMIT $5
-OFFSET $4
+SELECT
+  al.id,
+  al.actor_user_id,
+  u.display_name AS actor_name,
+  al.action,
+  al.entity_type,
+  al.entity_id,
+  al.metadata,
+  al.ip_address,
+  al.user_agent,
+  al.created_at
+FROM audit_logs al
+LEFT JOIN users u ON u.id = al.actor_user_id
+WHERE al.tenant_id = $1
+  AND ($2::audit_action_enum IS NULL OR al.action = $2)
+  AND ($3::uuid IS NULL OR al.actor_user_id = $3)
+  AND ($4::text IS NULL OR al.entity_type = $4)
+  AND ($5::uuid IS NULL OR al.entity_id = $5)
+  AND ($6::timestamptz IS NULL OR al.created_at >= $6)
+  AND ($7::timestamptz IS NULL OR al.created_at < $7)
+  AND (
+    $8::timestamptz IS NULL
+    OR (al.created_at, al.id) < ($8::timestamptz, $9::bigint)
+  )
+ORDER BY al.created_at DESC, al.id DESC
+LIMIT $10
 `
 
 type ListAuditLogsParams struct {
 	TenantID    pgtype.UUID         `json:"tenant_id"`
 	Action      NullAuditActionEnum `json:"action"`
 	ActorUserID pgtype.UUID         `json:"actor_user_id"`
-	OffsetCount int32               `json:"offset_count"`
+	EntityType  pgtype.Text         `json:"entity_type"`
+	EntityID    pgtype.UUID         `json:"entity_id"`
+	FromAt      pgtype.Timestamptz  `json:"from_at"`
+	UntilAt     pgtype.Timestamptz  `json:"until_at"`
+	CursorAt    pgtype.Timestamptz  `json:"cursor_at"`
+	CursorID    pgtype.Int8         `json:"cursor_id"`
 	LimitCount  int32               `json:"limit_count"`
 }
 
-func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+type ListAuditLogsRow struct {
+	ID          int64              `json:"id"`
+	ActorUserID pgtype.UUID        `json:"actor_user_id"`
+	ActorName   pgtype.Text        `json:"actor_name"`
+	Action      AuditActionEnum    `json:"action"`
+	EntityType  pgtype.Text        `json:"entity_type"`
+	EntityID    pgtype.UUID        `json:"entity_id"`
+	Metadata    []byte             `json:"metadata"`
+	IpAddress   *netip.Addr        `json:"ip_address"`
+	UserAgent   pgtype.Text        `json:"user_agent"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+// Newest first, keyset-paged on (created_at, id). from is inclusive, until exclusive.
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]ListAuditLogsRow, error) {
 	rows, err := q.db.Query(ctx, listAuditLogs,
 		arg.TenantID,
 		arg.Action,
 		arg.ActorUserID,
-		arg.OffsetCount,
+		arg.EntityType,
+		arg.EntityID,
+		arg.FromAt,
+		arg.UntilAt,
+		arg.CursorAt,
+		arg.CursorID,
 		arg.LimitCount,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []AuditLog{}
+	items := []ListAuditLogsRow{}
 	for rows.Next() {
-		var i AuditLog
+		var i ListAuditLogsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.TenantID,
 			&i.ActorUserID,
+			&i.ActorName,
 			&i.Action,
 			&i.EntityType,
 			&i.EntityID,
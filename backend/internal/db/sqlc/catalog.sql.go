@@ -0,0 +1,693 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: catalog.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clearDefaultPriceBook = `-- name: ClearDefaultPriceBook :exec
+UPDATE price_books
+SET is_default = FALSE,
+    updated_at = now()
+WHERE tenant_id = $1
+  AND is_default
+`
+
+func (q *Queries) ClearDefaultPriceBook(ctx context.Context, tenantID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearDefaultPriceBook, tenantID)
+	return err
+}
+
+const countProducts = `-- name: CountProducts :one
+SELECT count(*)::bigint
+FROM products
+WHERE tenant_id = $1
+  AND ($2::boolean IS NULL OR is_active = $2)
+  AND (
+    $3::text IS NULL
+    OR sku ILIKE '%' || $3 || '%'
+    OR name ILIKE '%' || $3 || '%'
+  )
+`
+
+type CountProductsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	IsActive pgtype.Bool `json:"is_active"`
+	Search   pgtype.Text `json:"search"`
+}
+
+func (q *Queries) CountProducts(ctx context.Context, arg CountProductsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countProducts, arg.TenantID, arg.IsActive, arg.Search)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createPriceBook = `-- name: CreatePriceBook :one
+INSERT INTO price_books (
+  tenant_id,
+  name,
+  currency,
+  is_default,
+  is_active
+) VALUES (
+  $1,
+  $2,
+  coalesce($3, (SELECT t.base_currency FROM tenants t WHERE t.id = $1)),
+  $4,
+  coalesce($5::boolean, TRUE)
+)
+RETURNING id, tenant_id, name, is_default, is_active, created_at, updated_at, currency
+`
+
+type CreatePriceBookParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	Name      string      `json:"name"`
+	Currency  interface{} `json:"currency"`
+	IsDefault bool        `json:"is_default"`
+	IsActive  pgtype.Bool `json:"is_active"`
+}
+
+func (q *Queries) CreatePriceBook(ctx context.Context, arg CreatePriceBookParams) (PriceBook, error) {
+	row := q.db.QueryRow(ctx, createPriceBook,
+		arg.TenantID,
+		arg.Name,
+		arg.Currency,
+		arg.IsDefault,
+		arg.IsActive,
+	)
+	var i PriceBook
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const createProduct = `-- name: CreateProduct :one
+INSERT INTO products (
+  tenant_id,
+  sku,
+  name,
+  description,
+  unit,
+  is_active,
+  tax_rate
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  coalesce($6::boolean, TRUE),
+  coalesce($7::numeric, 10)
+)
+RETURNING id, tenant_id, sku, name, description, unit, is_active, created_at, updated_at, tax_rate
+`
+
+type CreateProductParams struct {
+	TenantID    pgtype.UUID    `json:"tenant_id"`
+	Sku         string         `json:"sku"`
+	Name        string         `json:"name"`
+	Description pgtype.Text    `json:"description"`
+	Unit        pgtype.Text    `json:"unit"`
+	IsActive    pgtype.Bool    `json:"is_active"`
+	TaxRate     pgtype.Numeric `json:"tax_rate"`
+}
+
+func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
+	row := q.db.QueryRow(ctx, createProduct,
+		arg.TenantID,
+		arg.Sku,
+		arg.Name,
+		arg.Description,
+		arg.Unit,
+		arg.IsActive,
+		arg.TaxRate,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Sku,
+		&i.Name,
+		&i.Description,
+		&i.Unit,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TaxRate,
+	)
+	return i, err
+}
+
+const deletePriceBookEntry = `-- name: DeletePriceBookEntry :execrows
+DELETE FROM price_book_entries
+WHERE tenant_id = $1
+  AND price_book_id = $2
+  AND product_id = $3
+`
+
+type DeletePriceBookEntryParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	PriceBookID pgtype.UUID `json:"price_book_id"`
+	ProductID   pgtype.UUID `json:"product_id"`
+}
+
+func (q *Queries) DeletePriceBookEntry(ctx context.Context, arg DeletePriceBookEntryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePriceBookEntry, arg.TenantID, arg.PriceBookID, arg.ProductID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const exportProductsRows = `-- name: ExportProductsRows :many
+SELECT
+  p.id,
+  p.sku,
+  p.name,
+  p.description,
+  p.unit,
+  p.is_active,
+  pbe.unit_price AS list_price,
+  p.created_at,
+  p.updated_at
+FROM products p
+LEFT JOIN price_books pb
+  ON pb.tenant_id = p.tenant_id
+ AND pb.is_default
+LEFT JOIN price_book_entries pbe
+  ON pbe.price_book_id = pb.id
+ AND pbe.product_id = p.id
+WHERE p.tenant_id = $1
+ORDER BY p.sku ASC
+`
+
+type ExportProductsRowsRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	Sku         string             `json:"sku"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Unit        pgtype.Text        `json:"unit"`
+	IsActive    bool               `json:"is_active"`
+	ListPrice   pgtype.Numeric     `json:"list_price"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ExportProductsRows(ctx context.Context, tenantID pgtype.UUID) ([]ExportProductsRowsRow, error) {
+	rows, err := q.db.Query(ctx, exportProductsRows, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExportProductsRowsRow{}
+	for rows.Next() {
+		var i ExportProductsRowsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sku,
+			&i.Name,
+			&i.Description,
+			&i.Unit,
+			&i.IsActive,
+			&i.ListPrice,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDefaultPriceBook = `-- name: GetDefaultPriceBook :one
+SELECT id, tenant_id, name, is_default, is_active, created_at, updated_at, currency
+FROM price_books
+WHERE tenant_id = $1
+  AND is_default
+`
+
+func (q *Queries) GetDefaultPriceBook(ctx context.Context, tenantID pgtype.UUID) (PriceBook, error) {
+	row := q.db.QueryRow(ctx, getDefaultPriceBook, tenantID)
+	var i PriceBook
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getPriceBook = `-- name: GetPriceBook :one
+SELECT id, tenant_id, name, is_default, is_active, created_at, updated_at, currency
+FROM price_books
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetPriceBookParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	PriceBookID pgtype.UUID `json:"price_book_id"`
+}
+
+func (q *Queries) GetPriceBook(ctx context.Context, arg GetPriceBookParams) (PriceBook, error) {
+	row := q.db.QueryRow(ctx, getPriceBook, arg.TenantID, arg.PriceBookID)
+	var i PriceBook
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getPriceBookEntryPrice = `-- name: GetPriceBookEntryPrice :one
+SELECT unit_price
+FROM price_book_entries
+WHERE tenant_id = $1
+  AND price_book_id = $2
+  AND product_id = $3
+`
+
+type GetPriceBookEntryPriceParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	PriceBookID pgtype.UUID `json:"price_book_id"`
+	ProductID   pgtype.UUID `json:"product_id"`
+}
+
+func (q *Queries) GetPriceBookEntryPrice(ctx context.Context, arg GetPriceBookEntryPriceParams) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getPriceBookEntryPrice, arg.TenantID, arg.PriceBookID, arg.ProductID)
+	var unit_price pgtype.Numeric
+	err := row.Scan(&unit_price)
+	return unit_price, err
+}
+
+const getProduct = `-- name: GetProduct :one
+SELECT id, tenant_id, sku, name, description, unit, is_active, created_at, updated_at, tax_rate
+FROM products
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetProductParams struct {
+	TenantID  pgtype.UUID `json:"tenant_id"`
+	ProductID pgtype.UUID `json:"product_id"`
+}
+
+func (q *Queries) GetProduct(ctx context.Context, arg GetProductParams) (Product, error) {
+	row := q.db.QueryRow(ctx, getProduct, arg.TenantID, arg.ProductID)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Sku,
+		&i.Name,
+		&i.Description,
+		&i.Unit,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TaxRate,
+	)
+	return i, err
+}
+
+const listPriceBookEntries = `-- name: ListPriceBookEntries :many
+SELECT
+  pbe.id,
+  pbe.price_book_id,
+  pbe.product_id,
+  p.sku,
+  p.name AS product_name,
+  pbe.unit_price,
+  pbe.updated_at
+FROM price_book_entries pbe
+JOIN products p ON p.id = pbe.product_id
+WHERE pbe.tenant_id = $1
+  AND pbe.price_book_id = $2
+ORDER BY p.sku ASC
+`
+
+type ListPriceBookEntriesParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	PriceBookID pgtype.UUID `json:"price_book_id"`
+}
+
+type ListPriceBookEntriesRow struct {
+	ID          pgtype.UUID        `json:"id"`
+	PriceBookID pgtype.UUID        `json:"price_book_id"`
+	ProductID   pgtype.UUID        `json:"product_id"`
+	Sku         string             `json:"sku"`
+	ProductName string             `json:"product_name"`
+	UnitPrice   pgtype.Numeric     `json:"unit_price"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ListPriceBookEntries(ctx context.Context, arg ListPriceBookEntriesParams) ([]ListPriceBookEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listPriceBookEntries, arg.TenantID, arg.PriceBookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPriceBookEntriesRow{}
+	for rows.Next() {
+		var i ListPriceBookEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PriceBookID,
+			&i.ProductID,
+			&i.Sku,
+			&i.ProductName,
+			&i.UnitPrice,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPriceBooks = `-- name: ListPriceBooks :many
+SELECT id, tenant_id, name, is_default, is_active, created_at, updated_at, currency
+FROM price_books
+WHERE tenant_id = $1
+ORDER BY is_default DESC, name ASC
+`
+
+func (q *Queries) ListPriceBooks(ctx context.Context, tenantID pgtype.UUID) ([]PriceBook, error) {
+	rows, err := q.db.Query(ctx, listPriceBooks, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PriceBook{}
+	for rows.Next() {
+		var i PriceBook
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.IsDefault,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProducts = `-- name: ListProducts :many
+SELECT id, tenant_id, sku, name, description, unit, is_active, created_at, updated_at, tax_rate
+FROM products
+WHERE tenant_id = $1
+  AND ($2::boolean IS NULL OR is_active = $2)
+  AND (
+    $3::text IS NULL
+    OR sku ILIKE '%' || $3 || '%'
+    OR name ILIKE '%' || $3 || '%'
+  )
+ORDER BY name ASC, sku ASC
+LIMIT $5
+OFFSET $4
+`
+
+type ListProductsParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	IsActive    pgtype.Bool `json:"is_active"`
+	Search      pgtype.Text `json:"search"`
+	OffsetCount int32       `json:"offset_count"`
+	LimitCount  int32       `json:"limit_count"`
+}
+
+func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProducts,
+		arg.TenantID,
+		arg.IsActive,
+		arg.Search,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Product{}
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Sku,
+			&i.Name,
+			&i.Description,
+			&i.Unit,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TaxRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePriceBook = `-- name: UpdatePriceBook :one
+UPDATE price_books
+SET name = coalesce($1, name),
+    is_default = coalesce($2::boolean, is_default),
+    is_active = coalesce($3::boolean, is_active),
+    updated_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, name, is_default, is_active, created_at, updated_at, currency
+`
+
+type UpdatePriceBookParams struct {
+	Name        pgtype.Text `json:"name"`
+	IsDefault   pgtype.Bool `json:"is_default"`
+	IsActive    pgtype.Bool `json:"is_active"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	PriceBookID pgtype.UUID `json:"price_book_id"`
+}
+
+func (q *Queries) UpdatePriceBook(ctx context.Context, arg UpdatePriceBookParams) (PriceBook, error) {
+	row := q.db.QueryRow(ctx, updatePriceBook,
+		arg.Name,
+		arg.IsDefault,
+		arg.IsActive,
+		arg.TenantID,
+		arg.PriceBookID,
+	)
+	var i PriceBook
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.IsDefault,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE products
+SET sku = coalesce($1, sku),
+    name = coalesce($2, name),
+    description = coalesce($3, description),
+    unit = coalesce($4, unit),
+    is_active = coalesce($5::boolean, is_active),
+    tax_rate = coalesce($6::numeric, tax_rate),
+    updated_at = now()
+WHERE tenant_id = $7
+  AND id = $8
+RETURNING id, tenant_id, sku, name, description, unit, is_active, created_at, updated_at, tax_rate
+`
+
+type UpdateProductParams struct {
+	Sku         pgtype.Text    `json:"sku"`
+	Name        pgtype.Text    `json:"name"`
+	Description pgtype.Text    `json:"description"`
+	Unit        pgtype.Text    `json:"unit"`
+	IsActive    pgtype.Bool    `json:"is_active"`
+	TaxRate     pgtype.Numeric `json:"tax_rate"`
+	TenantID    pgtype.UUID    `json:"tenant_id"`
+	ProductID   pgtype.UUID    `json:"product_id"`
+}
+
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
+	row := q.db.QueryRow(ctx, updateProduct,
+		arg.Sku,
+		arg.Name,
+		arg.Description,
+		arg.Unit,
+		arg.IsActive,
+		arg.TaxRate,
+		arg.TenantID,
+		arg.ProductID,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Sku,
+		&i.Name,
+		&i.Description,
+		&i.Unit,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TaxRate,
+	)
+	return i, err
+}
+
+const upsertPriceBookEntry = `-- name: UpsertPriceBookEntry :one
+INSERT INTO price_book_entries (
+  tenant_id,
+  price_book_id,
+  product_id,
+  unit_price
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (price_book_id, product_id)
+DO UPDATE SET unit_price = EXCLUDED.unit_price,
+              updated_at = now()
+RETURNING id, tenant_id, price_book_id, product_id, unit_price, created_at, updated_at
+`
+
+type UpsertPriceBookEntryParams struct {
+	TenantID    pgtype.UUID    `json:"tenant_id"`
+	PriceBookID pgtype.UUID    `json:"price_book_id"`
+	ProductID   pgtype.UUID    `json:"product_id"`
+	UnitPrice   pgtype.Numeric `json:"unit_price"`
+}
+
+func (q *Queries) UpsertPriceBookEntry(ctx context.Context, arg UpsertPriceBookEntryParams) (PriceBookEntry, error) {
+	row := q.db.QueryRow(ctx, upsertPriceBookEntry,
+		arg.TenantID,
+		arg.PriceBookID,
+		arg.ProductID,
+		arg.UnitPrice,
+	)
+	var i PriceBookEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.PriceBookID,
+		&i.ProductID,
+		&i.UnitPrice,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertProductBySKU = `-- name: UpsertProductBySKU :one
+INSERT INTO products (
+  tenant_id,
+  sku,
+  name,
+  description,
+  unit,
+  is_active
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  coalesce($6::boolean, TRUE)
+)
+ON CONFLICT (tenant_id, sku)
+DO UPDATE SET name = EXCLUDED.name,
+              description = EXCLUDED.description,
+              unit = EXCLUDED.unit,
+              is_active = EXCLUDED.is_active,
+              updated_at = now()
+RETURNING id, tenant_id, sku, name, description, unit, is_active, created_at, updated_at, tax_rate
+`
+
+type UpsertProductBySKUParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	Sku         string      `json:"sku"`
+	Name        string      `json:"name"`
+	Description pgtype.Text `json:"description"`
+	Unit        pgtype.Text `json:"unit"`
+	IsActive    pgtype.Bool `json:"is_active"`
+}
+
+func (q *Queries) UpsertProductBySKU(ctx context.Context, arg UpsertProductBySKUParams) (Product, error) {
+	row := q.db.QueryRow(ctx, upsertProductBySKU,
+		arg.TenantID,
+		arg.Sku,
+		arg.Name,
+		arg.Description,
+		arg.Unit,
+		arg.IsActive,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Sku,
+		&i.Name,
+		&i.Description,
+		&i.Unit,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TaxRate,
+	)
+	return i, err
+}
@@ -0,0 +1,506 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: competitors.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clearOpportunityCompetitorWinner = `-- name: ClearOpportunityCompetitorWinner :exec
+UPDATE opportunity_competitors
+SET is_winner = FALSE,
+    updated_at = now()
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND is_winner
+`
+
+type ClearOpportunityCompetitorWinnerParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) ClearOpportunityCompetitorWinner(ctx context.Context, arg ClearOpportunityCompetitorWinnerParams) error {
+	_, err := q.db.Exec(ctx, clearOpportunityCompetitorWinner, arg.TenantID, arg.OpportunityID)
+	return err
+}
+
+const createCompetitor = `-- name: CreateCompetitor :one
+INSERT INTO competitors (
+  tenant_id,
+  name,
+  website,
+  note
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+RETURNING id, tenant_id, name, website, note, is_active, created_at, updated_at
+`
+
+type CreateCompetitorParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Name     string      `json:"name"`
+	Website  pgtype.Text `json:"website"`
+	Note     pgtype.Text `json:"note"`
+}
+
+func (q *Queries) CreateCompetitor(ctx context.Context, arg CreateCompetitorParams) (Competitor, error) {
+	row := q.db.QueryRow(ctx, createCompetitor,
+		arg.TenantID,
+		arg.Name,
+		arg.Website,
+		arg.Note,
+	)
+	var i Competitor
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Website,
+		&i.Note,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteOpportunityCompetitor = `-- name: DeleteOpportunityCompetitor :execrows
+DELETE FROM opportunity_competitors
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND competitor_id = $3
+`
+
+type DeleteOpportunityCompetitorParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	CompetitorID  pgtype.UUID `json:"competitor_id"`
+}
+
+func (q *Queries) DeleteOpportunityCompetitor(ctx context.Context, arg DeleteOpportunityCompetitorParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteOpportunityCompetitor, arg.TenantID, arg.OpportunityID, arg.CompetitorID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getCompetitiveSummary = `-- name: GetCompetitiveSummary :many
+
+WITH closed AS (
+  SELECT
+    oc.competitor_id,
+    oc.is_winner,
+    o.stage,
+    o.currency,
+    o.amount,
+    o.tenant_id,
+    CASE
+      WHEN o.stage = 'closed_lost' THEN coalesce(l.lost_at, o.closed_at, o.updated_at)
+      ELSE coalesce(o.closed_at, o.updated_at)
+    END AS closed_on
+  FROM opportunity_competitors oc
+  JOIN opportunities o ON o.id = oc.opportunity_id
+  LEFT JOIN opportunity_losses l ON l.opportunity_id = o.id
+  WHERE oc.tenant_id = $3
+    AND o.stage IN ('closed_won', 'closed_lost')
+)
+SELECT
+  c.id AS competitor_id,
+  c.name AS competitor_name,
+  cl.currency,
+  count(*)::bigint AS deal_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_won')::bigint AS won_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost')::bigint AS lost_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner)::bigint AS lost_to_count,
+  coalesce(sum(cl.amount) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner), 0)::double precision AS lost_amount,
+  coalesce(sum(cl.amount * fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date)) FILTER (
+    WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NOT NULL
+  ), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NULL)::bigint AS missing_rate_count
+FROM closed cl
+JOIN competitors c ON c.id = cl.competitor_id
+WHERE cl.closed_on >= $1::timestamptz
+  AND cl.closed_on < $2::timestamptz
+GROUP BY c.id, c.name, cl.currency
+ORDER BY c.name ASC, cl.currency ASC
+`
+
+type GetCompetitiveSummaryParams struct {
+	RangeStart pgtype.Timestamptz `json:"range_start"`
+	RangeEnd   pgtype.Timestamptz `json:"range_end"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+}
+
+type GetCompetitiveSummaryRow struct {
+	CompetitorID     pgtype.UUID `json:"competitor_id"`
+	CompetitorName   string      `json:"competitor_name"`
+	Currency         string      `json:"currency"`
+	DealCount        int64       `json:"deal_count"`
+	WonCount         int64       `json:"won_count"`
+	LostCount        int64       `json:"lost_count"`
+	LostToCount      int64       `json:"lost_to_count"`
+	LostAmount       float64     `json:"lost_amount"`
+	LostAmountBase   float64     `json:"lost_amount_base"`
+	MissingRateCount int64       `json:"missing_rate_count"`
+}
+
+// Closed deals with a competitor on them, dated by lost_at for losses and closed_at
+// for wins. Lost amounts only count deals lost to that competitor and convert at the
+// rate on the close date.
+func (q *Queries) GetCompetitiveSummary(ctx context.Context, arg GetCompetitiveSummaryParams) ([]GetCompetitiveSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getCompetitiveSummary, arg.RangeStart, arg.RangeEnd, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCompetitiveSummaryRow{}
+	for rows.Next() {
+		var i GetCompetitiveSummaryRow
+		if err := rows.Scan(
+			&i.CompetitorID,
+			&i.CompetitorName,
+			&i.Currency,
+			&i.DealCount,
+			&i.WonCount,
+			&i.LostCount,
+			&i.LostToCount,
+			&i.LostAmount,
+			&i.LostAmountBase,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCompetitiveTrend = `-- name: GetCompetitiveTrend :many
+WITH closed AS (
+  SELECT
+    oc.competitor_id,
+    oc.is_winner,
+    o.stage,
+    o.currency,
+    o.amount,
+    o.tenant_id,
+    CASE
+      WHEN o.stage = 'closed_lost' THEN coalesce(l.lost_at, o.closed_at, o.updated_at)
+      ELSE coalesce(o.closed_at, o.updated_at)
+    END AS closed_on
+  FROM opportunity_competitors oc
+  JOIN opportunities o ON o.id = oc.opportunity_id
+  LEFT JOIN opportunity_losses l ON l.opportunity_id = o.id
+  WHERE oc.tenant_id = $3
+    AND o.stage IN ('closed_won', 'closed_lost')
+)
+SELECT
+  cl.competitor_id,
+  to_char(date_trunc('month', cl.closed_on), 'YYYY-MM') AS month_bucket,
+  count(*)::bigint AS deal_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_won')::bigint AS won_count,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner)::bigint AS lost_to_count,
+  coalesce(sum(cl.amount * fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date)) FILTER (
+    WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NOT NULL
+  ), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE cl.stage = 'closed_lost' AND cl.is_winner AND fx_rate(cl.tenant_id, cl.currency, cl.closed_on::date) IS NULL)::bigint AS missing_rate_count
+FROM closed cl
+WHERE cl.closed_on >= $1::timestamptz
+  AND cl.closed_on < $2::timestamptz
+GROUP BY cl.competitor_id, to_char(date_trunc('month', cl.closed_on), 'YYYY-MM')
+ORDER BY cl.competitor_id, month_bucket ASC
+`
+
+type GetCompetitiveTrendParams struct {
+	RangeStart pgtype.Timestamptz `json:"range_start"`
+	RangeEnd   pgtype.Timestamptz `json:"range_end"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+}
+
+type GetCompetitiveTrendRow struct {
+	CompetitorID     pgtype.UUID `json:"competitor_id"`
+	MonthBucket      string      `json:"month_bucket"`
+	DealCount        int64       `json:"deal_count"`
+	WonCount         int64       `json:"won_count"`
+	LostToCount      int64       `json:"lost_to_count"`
+	LostAmountBase   float64     `json:"lost_amount_base"`
+	MissingRateCount int64       `json:"missing_rate_count"`
+}
+
+func (q *Queries) GetCompetitiveTrend(ctx context.Context, arg GetCompetitiveTrendParams) ([]GetCompetitiveTrendRow, error) {
+	rows, err := q.db.Query(ctx, getCompetitiveTrend, arg.RangeStart, arg.RangeEnd, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCompetitiveTrendRow{}
+	for rows.Next() {
+		var i GetCompetitiveTrendRow
+		if err := rows.Scan(
+			&i.CompetitorID,
+			&i.MonthBucket,
+			&i.DealCount,
+			&i.WonCount,
+			&i.LostToCount,
+			&i.LostAmountBase,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCompetitor = `-- name: GetCompetitor :one
+SELECT id, tenant_id, name, website, note, is_active, created_at, updated_at
+FROM competitors
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetCompetitorParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	CompetitorID pgtype.UUID `json:"competitor_id"`
+}
+
+func (q *Queries) GetCompetitor(ctx context.Context, arg GetCompetitorParams) (Competitor, error) {
+	row := q.db.QueryRow(ctx, getCompetitor, arg.TenantID, arg.CompetitorID)
+	var i Competitor
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Website,
+		&i.Note,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listCompetitors = `-- name: ListCompetitors :many
+SELECT id, tenant_id, name, website, note, is_active, created_at, updated_at
+FROM competitors
+WHERE tenant_id = $1
+  AND ($2::boolean IS NULL OR is_active = $2)
+  AND ($3::text IS NULL OR name ILIKE '%' || $3 || '%')
+ORDER BY name ASC
+`
+
+type ListCompetitorsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	IsActive pgtype.Bool `json:"is_active"`
+	Search   pgtype.Text `json:"search"`
+}
+
+func (q *Queries) ListCompetitors(ctx context.Context, arg ListCompetitorsParams) ([]Competitor, error) {
+	rows, err := q.db.Query(ctx, listCompetitors, arg.TenantID, arg.IsActive, arg.Search)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Competitor{}
+	for rows.Next() {
+		var i Competitor
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.Website,
+			&i.Note,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpportunityCompetitors = `-- name: ListOpportunityCompetitors :many
+SELECT
+  oc.id,
+  oc.opportunity_id,
+  oc.competitor_id,
+  c.name AS competitor_name,
+  oc.is_winner,
+  oc.note,
+  oc.created_by,
+  oc.created_at,
+  oc.updated_at
+FROM opportunity_competitors oc
+JOIN competitors c ON c.id = oc.competitor_id
+WHERE oc.tenant_id = $1
+  AND oc.opportunity_id = $2
+ORDER BY oc.is_winner DESC, c.name ASC
+`
+
+type ListOpportunityCompetitorsParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+type ListOpportunityCompetitorsRow struct {
+	ID             pgtype.UUID        `json:"id"`
+	OpportunityID  pgtype.UUID        `json:"opportunity_id"`
+	CompetitorID   pgtype.UUID        `json:"competitor_id"`
+	CompetitorName string             `json:"competitor_name"`
+	IsWinner       bool               `json:"is_winner"`
+	Note           pgtype.Text        `json:"note"`
+	CreatedBy      pgtype.UUID        `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) ListOpportunityCompetitors(ctx context.Context, arg ListOpportunityCompetitorsParams) ([]ListOpportunityCompetitorsRow, error) {
+	rows, err := q.db.Query(ctx, listOpportunityCompetitors, arg.TenantID, arg.OpportunityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpportunityCompetitorsRow{}
+	for rows.Next() {
+		var i ListOpportunityCompetitorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OpportunityID,
+			&i.CompetitorID,
+			&i.CompetitorName,
+			&i.IsWinner,
+			&i.Note,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCompetitor = `-- name: UpdateCompetitor :one
+UPDATE competitors
+SET name = coalesce($1, name),
+    website = coalesce($2, website),
+    note = coalesce($3, note),
+    is_active = coalesce($4::boolean, is_active),
+    updated_at = now()
+WHERE tenant_id = $5
+  AND id = $6
+RETURNING id, tenant_id, name, website, note, is_active, created_at, updated_at
+`
+
+type UpdateCompetitorParams struct {
+	Name         pgtype.Text `json:"name"`
+	Website      pgtype.Text `json:"website"`
+	Note         pgtype.Text `json:"note"`
+	IsActive     pgtype.Bool `json:"is_active"`
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	CompetitorID pgtype.UUID `json:"competitor_id"`
+}
+
+func (q *Queries) UpdateCompetitor(ctx context.Context, arg UpdateCompetitorParams) (Competitor, error) {
+	row := q.db.QueryRow(ctx, updateCompetitor,
+		arg.Name,
+		arg.Website,
+		arg.Note,
+		arg.IsActive,
+		arg.TenantID,
+		arg.CompetitorID,
+	)
+	var i Competitor
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Website,
+		&i.Note,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertOpportunityCompetitor = `-- name: UpsertOpportunityCompetitor :one
+INSERT INTO opportunity_competitors (
+  tenant_id,
+  opportunity_id,
+  competitor_id,
+  is_winner,
+  note,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+ON CONFLICT (opportunity_id, competitor_id)
+DO UPDATE SET is_winner = EXCLUDED.is_winner,
+              note = coalesce(EXCLUDED.note, opportunity_competitors.note),
+              updated_at = now()
+RETURNING id, tenant_id, opportunity_id, competitor_id, is_winner, note, created_by, created_at, updated_at
+`
+
+type UpsertOpportunityCompetitorParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	CompetitorID  pgtype.UUID `json:"competitor_id"`
+	IsWinner      bool        `json:"is_winner"`
+	Note          pgtype.Text `json:"note"`
+	CreatedBy     pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) UpsertOpportunityCompetitor(ctx context.Context, arg UpsertOpportunityCompetitorParams) (OpportunityCompetitor, error) {
+	row := q.db.QueryRow(ctx, upsertOpportunityCompetitor,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.CompetitorID,
+		arg.IsWinner,
+		arg.Note,
+		arg.CreatedBy,
+	)
+	var i OpportunityCompetitor
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.CompetitorID,
+		&i.IsWinner,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
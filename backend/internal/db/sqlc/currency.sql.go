@@ -0,0 +1,198 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: currency.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const deleteFxRate = `-- name: DeleteFxRate :one
+DELETE FROM fx_rates
+WHERE tenant_id = $1
+  AND id = $2
+RETURNING id, tenant_id, currency, base_currency, rate, effective_on, created_by, created_at
+`
+
+type DeleteFxRateParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	FxRateID int64       `json:"fx_rate_id"`
+}
+
+func (q *Queries) DeleteFxRate(ctx context.Context, arg DeleteFxRateParams) (FxRate, error) {
+	row := q.db.QueryRow(ctx, deleteFxRate, arg.TenantID, arg.FxRateID)
+	var i FxRate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Currency,
+		&i.BaseCurrency,
+		&i.Rate,
+		&i.EffectiveOn,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFxRate = `-- name: GetFxRate :one
+SELECT fx_rate($1::uuid, $2::text, $3::date)::numeric AS rate
+`
+
+type GetFxRateParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Currency string      `json:"currency"`
+	OnDate   pgtype.Date `json:"on_date"`
+}
+
+func (q *Queries) GetFxRate(ctx context.Context, arg GetFxRateParams) (pgtype.Numeric, error) {
+	row := q.db.QueryRow(ctx, getFxRate, arg.TenantID, arg.Currency, arg.OnDate)
+	var rate pgtype.Numeric
+	err := row.Scan(&rate)
+	return rate, err
+}
+
+const getTenantBaseCurrency = `-- name: GetTenantBaseCurrency :one
+SELECT base_currency
+FROM tenants
+WHERE id = $1
+`
+
+func (q *Queries) GetTenantBaseCurrency(ctx context.Context, tenantID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getTenantBaseCurrency, tenantID)
+	var base_currency string
+	err := row.Scan(&base_currency)
+	return base_currency, err
+}
+
+const listFxRates = `-- name: ListFxRates :many
+SELECT id, tenant_id, currency, base_currency, rate, effective_on, created_by, created_at
+FROM fx_rates
+WHERE tenant_id = $1
+  AND ($2::text IS NULL OR currency = $2)
+  AND base_currency = $3
+ORDER BY currency ASC, effective_on DESC
+LIMIT $5
+OFFSET $4
+`
+
+type ListFxRatesParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	Currency     pgtype.Text `json:"currency"`
+	BaseCurrency string      `json:"base_currency"`
+	OffsetCount  int32       `json:"offset_count"`
+	LimitCount   int32       `json:"limit_count"`
+}
+
+func (q *Queries) ListFxRates(ctx context.Context, arg ListFxRatesParams) ([]FxRate, error) {
+	rows, err := q.db.Query(ctx, listFxRates,
+		arg.TenantID,
+		arg.Currency,
+		arg.BaseCurrency,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FxRate{}
+	for rows.Next() {
+		var i FxRate
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Currency,
+			&i.BaseCurrency,
+			&i.Rate,
+			&i.EffectiveOn,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTenantBaseCurrency = `-- name: UpdateTenantBaseCurrency :one
+UPDATE tenants
+SET base_currency = $1
+WHERE id = $2
+RETURNING base_currency
+`
+
+type UpdateTenantBaseCurrencyParams struct {
+	BaseCurrency string      `json:"base_currency"`
+	TenantID     pgtype.UUID `json:"tenant_id"`
+}
+
+func (q *Queries) UpdateTenantBaseCurrency(ctx context.Context, arg UpdateTenantBaseCurrencyParams) (string, error) {
+	row := q.db.QueryRow(ctx, updateTenantBaseCurrency, arg.BaseCurrency, arg.TenantID)
+	var base_currency string
+	err := row.Scan(&base_currency)
+	return base_currency, err
+}
+
+const upsertFxRate = `-- name: UpsertFxRate :one
+INSERT INTO fx_rates (
+  tenant_id,
+  currency,
+  base_currency,
+  rate,
+  effective_on,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+ON CONFLICT (tenant_id, currency, base_currency, effective_on)
+DO UPDATE SET rate = EXCLUDED.rate,
+              created_by = EXCLUDED.created_by,
+              created_at = now()
+RETURNING id, tenant_id, currency, base_currency, rate, effective_on, created_by, created_at
+`
+
+type UpsertFxRateParams struct {
+	TenantID     pgtype.UUID    `json:"tenant_id"`
+	Currency     string         `json:"currency"`
+	BaseCurrency string         `json:"base_currency"`
+	Rate         pgtype.Numeric `json:"rate"`
+	EffectiveOn  pgtype.Date    `json:"effective_on"`
+	CreatedBy    pgtype.UUID    `json:"created_by"`
+}
+
+func (q *Queries) UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error) {
+	row := q.db.QueryRow(ctx, upsertFxRate,
+		arg.TenantID,
+		arg.Currency,
+		arg.BaseCurrency,
+		arg.Rate,
+		arg.EffectiveOn,
+		arg.CreatedBy,
+	)
+	var i FxRate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Currency,
+		&i.BaseCurrency,
+		&i.Rate,
+		&i.EffectiveOn,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -11,6 +11,68 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createKpiSnapshot = `-- name: CreateKpiSnapshot :exec
+WITH open_deals AS (
+  SELECT
+    amount * fx_rate(tenant_id, currency, current_date) AS amount_base,
+    probability
+  FROM opportunities
+  WHERE tenant_id = $1
+    AND stage NOT IN ('closed_won', 'closed_lost')
+),
+closed_90d AS (
+  SELECT stage
+  FROM opportunities
+  WHERE tenant_id = $1
+    AND stage IN ('closed_won', 'closed_lost')
+    AND closed_at >= now() - interval '90 day'
+),
+won_90d AS (
+  SELECT amount * fx_rate(tenant_id, currency, closed_at::date) AS amount_base
+  FROM opportunities
+  WHERE tenant_id = $1
+    AND stage = 'closed_won'
+    AND closed_at >= now() - interval '90 day'
+),
+metrics AS (
+  SELECT 'open_pipeline_amount'::text AS metric_key, coalesce((SELECT sum(amount_base) FROM open_deals WHERE amount_base IS NOT NULL), 0)::numeric AS metric_value
+  UNION ALL
+  SELECT 'weighted_pipeline_amount', coalesce((SELECT sum(amount_base * probability / 100.0) FROM open_deals WHERE amount_base IS NOT NULL), 0)::numeric
+  UNION ALL
+  SELECT 'open_pipeline_missing_rate_count', (SELECT count(*) FROM open_deals WHERE amount_base IS NULL)::numeric
+  UNION ALL
+  SELECT 'open_deal_count', (SELECT count(*) FROM open_deals)::numeric
+  UNION ALL
+  SELECT 'won_amount_90d', coalesce((SELECT sum(amount_base) FROM won_90d WHERE amount_base IS NOT NULL), 0)::numeric
+  UNION ALL
+  SELECT 'won_amount_90d_missing_rate_count', (SELECT count(*) FROM won_90d WHERE amount_base IS NULL)::numeric
+  UNION ALL
+  SELECT 'win_rate_90d', coalesce(
+    (SELECT count(*) FILTER (WHERE stage = 'closed_won')::numeric / nullif(count(*), 0) FROM closed_90d),
+    0
+  )::numeric
+)
+INSERT INTO kpi_snapshots (
+  tenant_id, snapshot_at, metric_key, metric_value, dimension_key, dimensions
+)
+SELECT
+  $1,
+  now(),
+  m.metric_key,
+  m.metric_value,
+  '',
+  jsonb_build_object('currency', (SELECT t.base_currency FROM tenants t WHERE t.id = $1))
+FROM metrics m
+`
+
+// Recomputes the headline KPIs in the tenant's base currency and stores them as a new
+// snapshot. Amounts without an FX rate are left out of the sums and counted in the
+// *_missing_rate_count metrics stored next to them.
+func (q *Queries) CreateKpiSnapshot(ctx context.Context, tenantID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, createKpiSnapshot, tenantID)
+	return err
+}
+
 const getLatestKpiSnapshot = `-- name: GetLatestKpiSnapshot :many
 SELECT
   ks.snapshot_at,
@@ -65,20 +127,29 @@ func (q *Queries) GetLatestKpiSnapshot(ctx context.Context, tenantID pgtype.UUID
 const getPipelineSummary = `-- name: GetPipelineSummary :many
 SELECT
   stage,
+  currency,
   count(*)::bigint AS deal_count,
-  coalesce(sum(amount), 0)::double precision AS total_amount
+  coalesce(sum(amount), 0)::double precision AS total_amount,
+  coalesce(sum(amount * fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)))
+    FILTER (WHERE fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)) IS NOT NULL), 0)::double precision AS total_amount_base,
+  count(*) FILTER (WHERE fx_rate(tenant_id, currency, coalesce(closed_at::date, current_date)) IS NULL)::bigint AS missing_rate_count
 FROM opportunities
 WHERE tenant_id = $1
-GROUP BY stage
-ORDER BY stage
+GROUP BY stage, currency
+ORDER BY stage, currency
 `
 
 type GetPipelineSummaryRow struct {
-	Stage       OpportunityStageEnum `json:"stage"`
-	DealCount   int64                `json:"deal_count"`
-	TotalAmount float64              `json:"total_amount"`
+	Stage            OpportunityStageEnum `json:"stage"`
+	Currency         string               `json:"currency"`
+	DealCount        int64                `json:"deal_count"`
+	TotalAmount      float64              `json:"total_amount"`
+	TotalAmountBase  float64              `json:"total_amount_base"`
+	MissingRateCount int64                `json:"missing_rate_count"`
 }
 
+// Closed deals convert at the rate on their close date, open deals at today's rate.
+// Deals without a rate are left out of total_amount_base and counted in missing_rate_count.
 func (q *Queries) GetPipelineSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetPipelineSummaryRow, error) {
 	rows, err := q.db.Query(ctx, getPipelineSummary, tenantID)
 	if err != nil {
@@ -88,7 +159,118 @@ func (q *Queries) GetPipelineSummary(ctx context.Context, tenantID pgtype.UUID)
 	items := []GetPipelineSummaryRow{}
 	for rows.Next() {
 		var i GetPipelineSummaryRow
-		if err := rows.Scan(&i.Stage, &i.DealCount, &i.TotalAmount); err != nil {
+		if err := rows.Scan(
+			&i.Stage,
+			&i.Currency,
+			&i.DealCount,
+			&i.TotalAmount,
+			&i.TotalAmountBase,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRevenueSchedule = `-- name: GetRevenueSchedule :many
+SELECT
+  rs.period_month,
+  o.account_id,
+  a.name AS account_name,
+  o.owner_user_id,
+  u.display_name AS owner_name,
+  coalesce(sum(rs.revenue_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE rs.period_month <= $1::date
+      AND fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS recognized_amount,
+  coalesce(sum(rs.revenue_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE rs.period_month > $1::date
+      AND fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS scheduled_amount,
+  coalesce(sum(rs.billing_amount * fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)))
+    FILTER (WHERE fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NOT NULL), 0)::double precision AS billing_amount,
+  count(DISTINCT rs.order_id)::bigint AS order_count,
+  count(*) FILTER (WHERE fx_rate(rs.tenant_id, rs.currency, coalesce(od.ordered_on, od.created_at::date)) IS NULL)::bigint AS missing_rate_count
+FROM order_revenue_schedules rs
+JOIN orders od ON od.id = rs.order_id
+JOIN opportunities o ON o.id = od.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+JOIN users u ON u.id = o.owner_user_id
+WHERE rs.tenant_id = $2
+  AND od.status <> 'cancelled'
+  AND rs.period_month BETWEEN $3::date AND $4::date
+  AND ($5::uuid IS NULL OR o.account_id = $5)
+  AND ($6::uuid IS NULL OR o.owner_user_id = $6)
+  AND (
+    $7::uuid IS NULL
+    OR o.owner_user_id = $7
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = $7
+    )
+  )
+GROUP BY rs.period_month, o.account_id, a.name, o.owner_user_id, u.display_name
+ORDER BY rs.period_month, a.name, u.display_name
+`
+
+type GetRevenueScheduleParams struct {
+	AsOfMonth   pgtype.Date `json:"as_of_month"`
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	FromMonth   pgtype.Date `json:"from_month"`
+	ToMonth     pgtype.Date `json:"to_month"`
+	AccountID   pgtype.UUID `json:"account_id"`
+	OwnerUserID pgtype.UUID `json:"owner_user_id"`
+	VisibleTo   pgtype.UUID `json:"visible_to"`
+}
+
+type GetRevenueScheduleRow struct {
+	PeriodMonth      pgtype.Date `json:"period_month"`
+	AccountID        pgtype.UUID `json:"account_id"`
+	AccountName      string      `json:"account_name"`
+	OwnerUserID      pgtype.UUID `json:"owner_user_id"`
+	OwnerName        string      `json:"owner_name"`
+	RecognizedAmount float64     `json:"recognized_amount"`
+	ScheduledAmount  float64     `json:"scheduled_amount"`
+	BillingAmount    float64     `json:"billing_amount"`
+	OrderCount       int64       `json:"order_count"`
+	MissingRateCount int64       `json:"missing_rate_count"`
+}
+
+// Months up to and including as_of_month count as recognized, later months as scheduled.
+// Amounts convert to the base currency at the rate on the order date; cancelled orders
+// are left out, and so are months without a rate, which count in missing_rate_count.
+func (q *Queries) GetRevenueSchedule(ctx context.Context, arg GetRevenueScheduleParams) ([]GetRevenueScheduleRow, error) {
+	rows, err := q.db.Query(ctx, getRevenueSchedule,
+		arg.AsOfMonth,
+		arg.TenantID,
+		arg.FromMonth,
+		arg.ToMonth,
+		arg.AccountID,
+		arg.OwnerUserID,
+		arg.VisibleTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRevenueScheduleRow{}
+	for rows.Next() {
+		var i GetRevenueScheduleRow
+		if err := rows.Scan(
+			&i.PeriodMonth,
+			&i.AccountID,
+			&i.AccountName,
+			&i.OwnerUserID,
+			&i.OwnerName,
+			&i.RecognizedAmount,
+			&i.ScheduledAmount,
+			&i.BillingAmount,
+			&i.OrderCount,
+			&i.MissingRateCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -0,0 +1,462 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: discount_policies.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createDiscountPolicy = `-- name: CreateDiscountPolicy :one
+INSERT INTO discount_policies (
+  tenant_id,
+  name,
+  metric,
+  threshold,
+  approver_user_id,
+  chain_id,
+  is_active,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+)
+RETURNING id, tenant_id, name, metric, threshold, approver_user_id, is_active, created_by, created_at, updated_at, version, chain_id
+`
+
+type CreateDiscountPolicyParams struct {
+	TenantID       pgtype.UUID              `json:"tenant_id"`
+	Name           string                   `json:"name"`
+	Metric         DiscountPolicyMetricEnum `json:"metric"`
+	Threshold      pgtype.Numeric           `json:"threshold"`
+	ApproverUserID pgtype.UUID              `json:"approver_user_id"`
+	ChainID        pgtype.UUID              `json:"chain_id"`
+	IsActive       bool                     `json:"is_active"`
+	CreatedBy      pgtype.UUID              `json:"created_by"`
+}
+
+func (q *Queries) CreateDiscountPolicy(ctx context.Context, arg CreateDiscountPolicyParams) (DiscountPolicy, error) {
+	row := q.db.QueryRow(ctx, createDiscountPolicy,
+		arg.TenantID,
+		arg.Name,
+		arg.Metric,
+		arg.Threshold,
+		arg.ApproverUserID,
+		arg.ChainID,
+		arg.IsActive,
+		arg.CreatedBy,
+	)
+	var i DiscountPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Metric,
+		&i.Threshold,
+		&i.ApproverUserID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.ChainID,
+	)
+	return i, err
+}
+
+const createPolicyApprovalRequest = `-- name: CreatePolicyApprovalRequest :one
+INSERT INTO approval_requests (
+  tenant_id,
+  entity_type,
+  entity_id,
+  requested_by,
+  approver_user_id,
+  reason,
+  policy_id,
+  metric_value
+) VALUES (
+  $1,
+  'quote',
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type CreatePolicyApprovalRequestParams struct {
+	TenantID       pgtype.UUID    `json:"tenant_id"`
+	QuoteID        pgtype.UUID    `json:"quote_id"`
+	RequestedBy    pgtype.UUID    `json:"requested_by"`
+	ApproverUserID pgtype.UUID    `json:"approver_user_id"`
+	Reason         string         `json:"reason"`
+	PolicyID       pgtype.UUID    `json:"policy_id"`
+	MetricValue    pgtype.Numeric `json:"metric_value"`
+}
+
+func (q *Queries) CreatePolicyApprovalRequest(ctx context.Context, arg CreatePolicyApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, createPolicyApprovalRequest,
+		arg.TenantID,
+		arg.QuoteID,
+		arg.RequestedBy,
+		arg.ApproverUserID,
+		arg.Reason,
+		arg.PolicyID,
+		arg.MetricValue,
+	)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
+const getDiscountPolicy = `-- name: GetDiscountPolicy :one
+SELECT id, tenant_id, name, metric, threshold, approver_user_id, is_active, created_by, created_at, updated_at, version, chain_id
+FROM discount_policies
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetDiscountPolicyParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	PolicyID pgtype.UUID `json:"policy_id"`
+}
+
+func (q *Queries) GetDiscountPolicy(ctx context.Context, arg GetDiscountPolicyParams) (DiscountPolicy, error) {
+	row := q.db.QueryRow(ctx, getDiscountPolicy, arg.TenantID, arg.PolicyID)
+	var i DiscountPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Metric,
+		&i.Threshold,
+		&i.ApproverUserID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.ChainID,
+	)
+	return i, err
+}
+
+const getQuoteDiscountMetrics = `-- name: GetQuoteDiscountMetrics :one
+SELECT
+  coalesce((
+    SELECT max(li.discount_percent)
+    FROM line_items li
+    WHERE li.tenant_id = qh.tenant_id
+      AND li.quote_id = qh.id
+  ), 0)::numeric AS max_line_discount_percent,
+  coalesce((
+    SELECT sum(li.quantity * li.unit_price)
+    FROM line_items li
+    WHERE li.tenant_id = qh.tenant_id
+      AND li.quote_id = qh.id
+  ), 0)::numeric AS list_amount,
+  qh.amount,
+  (qh.amount * fx_rate(qh.tenant_id, qh.currency, current_date))::numeric AS base_amount
+FROM quotes qh
+WHERE qh.tenant_id = $1
+  AND qh.id = $2
+`
+
+type GetQuoteDiscountMetricsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+type GetQuoteDiscountMetricsRow struct {
+	MaxLineDiscountPercent pgtype.Numeric `json:"max_line_discount_percent"`
+	ListAmount             pgtype.Numeric `json:"list_amount"`
+	Amount                 pgtype.Numeric `json:"amount"`
+	BaseAmount             pgtype.Numeric `json:"base_amount"`
+}
+
+// Inputs for the discount policies. base_amount is NULL when the quote currency has no
+// exchange rate to the tenant's base currency.
+func (q *Queries) GetQuoteDiscountMetrics(ctx context.Context, arg GetQuoteDiscountMetricsParams) (GetQuoteDiscountMetricsRow, error) {
+	row := q.db.QueryRow(ctx, getQuoteDiscountMetrics, arg.TenantID, arg.QuoteID)
+	var i GetQuoteDiscountMetricsRow
+	err := row.Scan(
+		&i.MaxLineDiscountPercent,
+		&i.ListAmount,
+		&i.Amount,
+		&i.BaseAmount,
+	)
+	return i, err
+}
+
+const invalidateQuoteApprovals = `-- name: InvalidateQuoteApprovals :many
+UPDATE approval_requests
+SET status = CASE WHEN status IN ('pending', 'approved') THEN 'invalidated'::approval_status_enum ELSE status END,
+    invalidated_at = now(),
+    updated_at = now()
+WHERE tenant_id = $1
+  AND entity_type = 'quote'
+  AND entity_id = $2
+  AND invalidated_at IS NULL
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type InvalidateQuoteApprovalsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+// Open and granted requests become 'invalidated'; rejections keep their status but no
+// longer block the edited quote.
+func (q *Queries) InvalidateQuoteApprovals(ctx context.Context, arg InvalidateQuoteApprovalsParams) ([]ApprovalRequest, error) {
+	rows, err := q.db.Query(ctx, invalidateQuoteApprovals, arg.TenantID, arg.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalRequest{}
+	for rows.Next() {
+		var i ApprovalRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.RequestedBy,
+			&i.ApproverUserID,
+			&i.Status,
+			&i.Reason,
+			&i.DecisionNote,
+			&i.DecidedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.PolicyID,
+			&i.MetricValue,
+			&i.InvalidatedAt,
+			&i.ChainID,
+			&i.CurrentStep,
+			&i.WithdrawnAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCurrentQuotePolicyApprovals = `-- name: ListCurrentQuotePolicyApprovals :many
+SELECT id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+FROM approval_requests
+WHERE tenant_id = $1
+  AND entity_type = 'quote'
+  AND entity_id = $2
+  AND policy_id IS NOT NULL
+  AND invalidated_at IS NULL
+  AND status <> 'withdrawn'
+ORDER BY created_at DESC
+`
+
+type ListCurrentQuotePolicyApprovalsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+// Approvals raised by policies for the quote's current content, newest first. Withdrawn
+// requests no longer cover it, so the next send attempt raises a fresh one.
+func (q *Queries) ListCurrentQuotePolicyApprovals(ctx context.Context, arg ListCurrentQuotePolicyApprovalsParams) ([]ApprovalRequest, error) {
+	rows, err := q.db.Query(ctx, listCurrentQuotePolicyApprovals, arg.TenantID, arg.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApprovalRequest{}
+	for rows.Next() {
+		var i ApprovalRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.RequestedBy,
+			&i.ApproverUserID,
+			&i.Status,
+			&i.Reason,
+			&i.DecisionNote,
+			&i.DecidedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.PolicyID,
+			&i.MetricValue,
+			&i.InvalidatedAt,
+			&i.ChainID,
+			&i.CurrentStep,
+			&i.WithdrawnAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDiscountPolicies = `-- name: ListDiscountPolicies :many
+SELECT id, tenant_id, name, metric, threshold, approver_user_id, is_active, created_by, created_at, updated_at, version, chain_id
+FROM discount_policies
+WHERE tenant_id = $1
+  AND (NOT $2::boolean OR is_active)
+ORDER BY name
+`
+
+type ListDiscountPoliciesParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ActiveOnly bool        `json:"active_only"`
+}
+
+func (q *Queries) ListDiscountPolicies(ctx context.Context, arg ListDiscountPoliciesParams) ([]DiscountPolicy, error) {
+	rows, err := q.db.Query(ctx, listDiscountPolicies, arg.TenantID, arg.ActiveOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DiscountPolicy{}
+	for rows.Next() {
+		var i DiscountPolicy
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.Metric,
+			&i.Threshold,
+			&i.ApproverUserID,
+			&i.IsActive,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.ChainID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setQuoteApprovedForSend = `-- name: SetQuoteApprovedForSend :exec
+UPDATE quotes
+SET approved_for_send_at = $1
+WHERE tenant_id = $2
+  AND id = $3
+  AND approved_for_send_at IS DISTINCT FROM $1
+`
+
+type SetQuoteApprovedForSendParams struct {
+	ApprovedForSendAt pgtype.Timestamptz `json:"approved_for_send_at"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	QuoteID           pgtype.UUID        `json:"quote_id"`
+}
+
+func (q *Queries) SetQuoteApprovedForSend(ctx context.Context, arg SetQuoteApprovedForSendParams) error {
+	_, err := q.db.Exec(ctx, setQuoteApprovedForSend, arg.ApprovedForSendAt, arg.TenantID, arg.QuoteID)
+	return err
+}
+
+const updateDiscountPolicy = `-- name: UpdateDiscountPolicy :one
+UPDATE discount_policies
+SET
+  name = coalesce($1, name),
+  metric = coalesce($2, metric),
+  threshold = coalesce($3, threshold),
+  approver_user_id = coalesce($4, approver_user_id),
+  chain_id = CASE WHEN $5::boolean THEN $6 ELSE chain_id END,
+  is_active = coalesce($7, is_active),
+  updated_at = now()
+WHERE tenant_id = $8
+  AND id = $9
+RETURNING id, tenant_id, name, metric, threshold, approver_user_id, is_active, created_by, created_at, updated_at, version, chain_id
+`
+
+type UpdateDiscountPolicyParams struct {
+	Name           pgtype.Text                  `json:"name"`
+	Metric         NullDiscountPolicyMetricEnum `json:"metric"`
+	Threshold      pgtype.Numeric               `json:"threshold"`
+	ApproverUserID pgtype.UUID                  `json:"approver_user_id"`
+	SetChain       bool                         `json:"set_chain"`
+	ChainID        pgtype.UUID                  `json:"chain_id"`
+	IsActive       pgtype.Bool                  `json:"is_active"`
+	TenantID       pgtype.UUID                  `json:"tenant_id"`
+	PolicyID       pgtype.UUID                  `json:"policy_id"`
+}
+
+func (q *Queries) UpdateDiscountPolicy(ctx context.Context, arg UpdateDiscountPolicyParams) (DiscountPolicy, error) {
+	row := q.db.QueryRow(ctx, updateDiscountPolicy,
+		arg.Name,
+		arg.Metric,
+		arg.Threshold,
+		arg.ApproverUserID,
+		arg.SetChain,
+		arg.ChainID,
+		arg.IsActive,
+		arg.TenantID,
+		arg.PolicyID,
+	)
+	var i DiscountPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Metric,
+		&i.Threshold,
+		&i.ApproverUserID,
+		&i.IsActive,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.ChainID,
+	)
+	return i, err
+}
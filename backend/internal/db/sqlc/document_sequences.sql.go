@@ -0,0 +1,178 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: document_sequences.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getDocumentNumberFormat = `-- name: GetDocumentNumberFormat :one
+SELECT tenant_id, document_type, prefix, period_format, padding, updated_at
+FROM document_number_formats
+WHERE tenant_id = $1
+  AND document_type = $2
+`
+
+type GetDocumentNumberFormatParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	DocumentType string      `json:"document_type"`
+}
+
+func (q *Queries) GetDocumentNumberFormat(ctx context.Context, arg GetDocumentNumberFormatParams) (DocumentNumberFormat, error) {
+	row := q.db.QueryRow(ctx, getDocumentNumberFormat, arg.TenantID, arg.DocumentType)
+	var i DocumentNumberFormat
+	err := row.Scan(
+		&i.TenantID,
+		&i.DocumentType,
+		&i.Prefix,
+		&i.PeriodFormat,
+		&i.Padding,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTenantTaxRounding = `-- name: GetTenantTaxRounding :one
+SELECT tax_rounding
+FROM tenants
+WHERE id = $1
+`
+
+func (q *Queries) GetTenantTaxRounding(ctx context.Context, tenantID pgtype.UUID) (TaxRoundingEnum, error) {
+	row := q.db.QueryRow(ctx, getTenantTaxRounding, tenantID)
+	var tax_rounding TaxRoundingEnum
+	err := row.Scan(&tax_rounding)
+	return tax_rounding, err
+}
+
+const getTenantTimeZone = `-- name: GetTenantTimeZone :one
+SELECT time_zone
+FROM tenants
+WHERE id = $1
+`
+
+func (q *Queries) GetTenantTimeZone(ctx context.Context, tenantID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getTenantTimeZone, tenantID)
+	var time_zone string
+	err := row.Scan(&time_zone)
+	return time_zone, err
+}
+
+const listDocumentNumberFormats = `-- name: ListDocumentNumberFormats :many
+SELECT tenant_id, document_type, prefix, period_format, padding, updated_at
+FROM document_number_formats
+WHERE tenant_id = $1
+ORDER BY document_type
+`
+
+func (q *Queries) ListDocumentNumberFormats(ctx context.Context, tenantID pgtype.UUID) ([]DocumentNumberFormat, error) {
+	rows, err := q.db.Query(ctx, listDocumentNumberFormats, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []DocumentNumberFormat{}
+	for rows.Next() {
+		var i DocumentNumberFormat
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.DocumentType,
+			&i.Prefix,
+			&i.PeriodFormat,
+			&i.Padding,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextDocumentSequence = `-- name: NextDocumentSequence :one
+INSERT INTO document_sequences (tenant_id, prefix, period, last_value)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (tenant_id, prefix, period)
+DO UPDATE SET last_value = document_sequences.last_value + 1,
+              updated_at = now()
+RETURNING last_value
+`
+
+type NextDocumentSequenceParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	Prefix   string      `json:"prefix"`
+	Period   string      `json:"period"`
+}
+
+func (q *Queries) NextDocumentSequence(ctx context.Context, arg NextDocumentSequenceParams) (int32, error) {
+	row := q.db.QueryRow(ctx, nextDocumentSequence, arg.TenantID, arg.Prefix, arg.Period)
+	var last_value int32
+	err := row.Scan(&last_value)
+	return last_value, err
+}
+
+const updateTenantTaxRounding = `-- name: UpdateTenantTaxRounding :one
+UPDATE tenants
+SET tax_rounding = $1
+WHERE id = $2
+RETURNING tax_rounding
+`
+
+type UpdateTenantTaxRoundingParams struct {
+	TaxRounding TaxRoundingEnum `json:"tax_rounding"`
+	TenantID    pgtype.UUID     `json:"tenant_id"`
+}
+
+func (q *Queries) UpdateTenantTaxRounding(ctx context.Context, arg UpdateTenantTaxRoundingParams) (TaxRoundingEnum, error) {
+	row := q.db.QueryRow(ctx, updateTenantTaxRounding, arg.TaxRounding, arg.TenantID)
+	var tax_rounding TaxRoundingEnum
+	err := row.Scan(&tax_rounding)
+	return tax_rounding, err
+}
+
+const upsertDocumentNumberFormat = `-- name: UpsertDocumentNumberFormat :one
+INSERT INTO document_number_formats (tenant_id, document_type, prefix, period_format, padding)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tenant_id, document_type)
+DO UPDATE SET prefix = EXCLUDED.prefix,
+              period_format = EXCLUDED.period_format,
+              padding = EXCLUDED.padding,
+              updated_at = now()
+RETURNING tenant_id, document_type, prefix, period_format, padding, updated_at
+`
+
+type UpsertDocumentNumberFormatParams struct {
+	TenantID     pgtype.UUID `json:"tenant_id"`
+	DocumentType string      `json:"document_type"`
+	Prefix       string      `json:"prefix"`
+	PeriodFormat string      `json:"period_format"`
+	Padding      int32       `json:"padding"`
+}
+
+func (q *Queries) UpsertDocumentNumberFormat(ctx context.Context, arg UpsertDocumentNumberFormatParams) (DocumentNumberFormat, error) {
+	row := q.db.QueryRow(ctx, upsertDocumentNumberFormat,
+		arg.TenantID,
+		arg.DocumentType,
+		arg.Prefix,
+		arg.PeriodFormat,
+		arg.Padding,
+	)
+	var i DocumentNumberFormat
+	err := row.Scan(
+		&i.TenantID,
+		&i.DocumentType,
+		&i.Prefix,
+		&i.PeriodFormat,
+		&i.Padding,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -27,7 +27,7 @@ INSERT INTO approval_requests (
   $5,
   $6
 )
-RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
 `
 
 type CreateApprovalRequestParams struct {
@@ -62,6 +62,13 @@ func (q *Queries) CreateApprovalRequest(ctx context.Context, arg CreateApprovalR
 		&i.DecidedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
 	)
 	return i, err
 }
@@ -146,7 +153,8 @@ SET
   updated_at = now()
 WHERE tenant_id = $3
   AND id = $4
-RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at
+  AND status = 'pending'
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
 `
 
 type DecideApprovalRequestParams struct {
@@ -177,6 +185,13 @@ func (q *Queries) DecideApprovalRequest(ctx context.Context, arg DecideApprovalR
 		&i.DecidedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
 	)
 	return i, err
 }
@@ -252,6 +267,7 @@ SELECT
   stage,
   probability,
   amount::double precision AS amount,
+  currency,
   expected_close_date,
   next_action_at,
   next_action_note,
@@ -271,6 +287,7 @@ type ExportOpportunitiesRowsRow struct {
 	Stage             OpportunityStageEnum `json:"stage"`
 	Probability       int16                `json:"probability"`
 	Amount            float64              `json:"amount"`
+	Currency          string               `json:"currency"`
 	ExpectedCloseDate pgtype.Date          `json:"expected_close_date"`
 	NextActionAt      pgtype.Timestamptz   `json:"next_action_at"`
 	NextActionNote    pgtype.Text          `json:"next_action_note"`
@@ -296,6 +313,7 @@ func (q *Queries) ExportOpportunitiesRows(ctx context.Context, tenantID pgtype.U
 			&i.Stage,
 			&i.Probability,
 			&i.Amount,
+			&i.Currency,
 			&i.ExpectedCloseDate,
 			&i.NextActionAt,
 			&i.NextActionNote,
@@ -312,28 +330,120 @@ func (q *Queries) ExportOpportunitiesRows(ctx context.Context, tenantID pgtype.U
 	return items, nil
 }
 
+const getApprovalRequest = `-- name: GetApprovalRequest :one
+SELECT id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+FROM approval_requests
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetApprovalRequestParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ApprovalID pgtype.UUID `json:"approval_id"`
+}
+
+func (q *Queries) GetApprovalRequest(ctx context.Context, arg GetApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, getApprovalRequest, arg.TenantID, arg.ApprovalID)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
+const getApprovalRequestForUpdate = `-- name: GetApprovalRequestForUpdate :one
+SELECT id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+FROM approval_requests
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetApprovalRequestForUpdateParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ApprovalID pgtype.UUID `json:"approval_id"`
+}
+
+func (q *Queries) GetApprovalRequestForUpdate(ctx context.Context, arg GetApprovalRequestForUpdateParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, getApprovalRequestForUpdate, arg.TenantID, arg.ApprovalID)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
+
 const getForecastSummary = `-- name: GetForecastSummary :many
+
 SELECT
   o.owner_user_id,
   to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM') AS month_bucket,
+  o.currency,
   count(*)::bigint AS deal_count,
   coalesce(sum(o.amount), 0)::double precision AS pipeline_amount,
-  coalesce(sum(o.amount * (o.probability::numeric / 100.0)), 0)::double precision AS weighted_amount
+  coalesce(sum(o.amount * (o.probability::numeric / 100.0)), 0)::double precision AS weighted_amount,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, current_date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL), 0)::double precision AS pipeline_amount_base,
+  coalesce(sum(o.amount * (o.probability::numeric / 100.0) * fx_rate(o.tenant_id, o.currency, current_date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL), 0)::double precision AS weighted_amount_base,
+  count(*) FILTER (WHERE fx_rate(o.tenant_id, o.currency, current_date) IS NULL)::bigint AS missing_rate_count
 FROM opportunities o
 WHERE o.tenant_id = $1
   AND o.stage NOT IN ('closed_won', 'closed_lost')
-GROUP BY o.owner_user_id, to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM')
-ORDER BY month_bucket ASC, o.owner_user_id ASC
+GROUP BY o.owner_user_id, to_char(date_trunc('month', coalesce(o.expected_close_date::timestamptz, now())), 'YYYY-MM'), o.currency
+ORDER BY month_bucket ASC, o.owner_user_id ASC, o.currency ASC
 `
 
 type GetForecastSummaryRow struct {
-	OwnerUserID    pgtype.UUID `json:"owner_user_id"`
-	MonthBucket    string      `json:"month_bucket"`
-	DealCount      int64       `json:"deal_count"`
-	PipelineAmount float64     `json:"pipeline_amount"`
-	WeightedAmount float64     `json:"weighted_amount"`
+	OwnerUserID        pgtype.UUID `json:"owner_user_id"`
+	MonthBucket        string      `json:"month_bucket"`
+	Currency           string      `json:"currency"`
+	DealCount          int64       `json:"deal_count"`
+	PipelineAmount     float64     `json:"pipeline_amount"`
+	WeightedAmount     float64     `json:"weighted_amount"`
+	PipelineAmountBase float64     `json:"pipeline_amount_base"`
+	WeightedAmountBase float64     `json:"weighted_amount_base"`
+	MissingRateCount   int64       `json:"missing_rate_count"`
 }
 
+// Open deals convert at today's rate; amounts without a rate are left out of the
+// *_base sums and counted in missing_rate_count.
 func (q *Queries) GetForecastSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetForecastSummaryRow, error) {
 	rows, err := q.db.Query(ctx, getForecastSummary, tenantID)
 	if err != nil {
@@ -346,9 +456,13 @@ func (q *Queries) GetForecastSummary(ctx context.Context, tenantID pgtype.UUID)
 		if err := rows.Scan(
 			&i.OwnerUserID,
 			&i.MonthBucket,
+			&i.Currency,
 			&i.DealCount,
 			&i.PipelineAmount,
 			&i.WeightedAmount,
+			&i.PipelineAmountBase,
+			&i.WeightedAmountBase,
+			&i.MissingRateCount,
 		); err != nil {
 			return nil, err
 		}
@@ -363,21 +477,30 @@ func (q *Queries) GetForecastSummary(ctx context.Context, tenantID pgtype.UUID)
 const getLossReasonAnalysis = `-- name: GetLossReasonAnalysis :many
 SELECT
   l.reason,
+  o.currency,
   count(*)::bigint AS lost_count,
-  coalesce(sum(o.amount), 0)::double precision AS lost_amount
+  coalesce(sum(o.amount), 0)::double precision AS lost_amount,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, l.lost_at::date))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, l.lost_at::date) IS NOT NULL), 0)::double precision AS lost_amount_base,
+  count(*) FILTER (WHERE fx_rate(o.tenant_id, o.currency, l.lost_at::date) IS NULL)::bigint AS missing_rate_count
 FROM opportunity_losses l
 JOIN opportunities o ON o.id = l.opportunity_id
 WHERE l.tenant_id = $1
-GROUP BY l.reason
-ORDER BY lost_count DESC, lost_amount DESC
+  AND o.stage = 'closed_lost'
+GROUP BY l.reason, o.currency
+ORDER BY l.reason, o.currency
 `
 
 type GetLossReasonAnalysisRow struct {
-	Reason     LossReasonEnum `json:"reason"`
-	LostCount  int64          `json:"lost_count"`
-	LostAmount float64        `json:"lost_amount"`
+	Reason           LossReasonEnum `json:"reason"`
+	Currency         string         `json:"currency"`
+	LostCount        int64          `json:"lost_count"`
+	LostAmount       float64        `json:"lost_amount"`
+	LostAmountBase   float64        `json:"lost_amount_base"`
+	MissingRateCount int64          `json:"missing_rate_count"`
 }
 
+// Lost deals convert at the rate on the loss date, with the same missing-rate handling.
 func (q *Queries) GetLossReasonAnalysis(ctx context.Context, tenantID pgtype.UUID) ([]GetLossReasonAnalysisRow, error) {
 	rows, err := q.db.Query(ctx, getLossReasonAnalysis, tenantID)
 	if err != nil {
@@ -387,7 +510,14 @@ func (q *Queries) GetLossReasonAnalysis(ctx context.Context, tenantID pgtype.UUI
 	items := []GetLossReasonAnalysisRow{}
 	for rows.Next() {
 		var i GetLossReasonAnalysisRow
-		if err := rows.Scan(&i.Reason, &i.LostCount, &i.LostAmount); err != nil {
+		if err := rows.Scan(
+			&i.Reason,
+			&i.Currency,
+			&i.LostCount,
+			&i.LostAmount,
+			&i.LostAmountBase,
+			&i.MissingRateCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -399,18 +529,31 @@ func (q *Queries) GetLossReasonAnalysis(ctx context.Context, tenantID pgtype.UUI
 }
 
 const listApprovalRequests = `-- name: ListApprovalRequests :many
-SELECT id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at
-FROM approval_requests
-WHERE tenant_id = $1
-  AND ($2::approval_status_enum IS NULL OR status = $2)
-ORDER BY created_at DESC
-LIMIT $4
-OFFSET $3
+SELECT ar.id, ar.tenant_id, ar.entity_type, ar.entity_id, ar.requested_by, ar.approver_user_id, ar.status, ar.reason, ar.decision_note, ar.decided_at, ar.created_at, ar.updated_at, ar.version, ar.policy_id, ar.metric_value, ar.invalidated_at, ar.chain_id, ar.current_step, ar.withdrawn_at
+FROM approval_requests ar
+WHERE ar.tenant_id = $1
+  AND ($2::approval_status_enum IS NULL OR ar.status = $2)
+  AND (
+    $3::uuid IS NULL
+    OR (ar.chain_id IS NULL AND ar.approver_user_id = $3)
+    OR EXISTS (
+      SELECT 1
+      FROM approval_request_steps s
+      JOIN approval_step_approvers sa ON sa.step_id = s.id
+      WHERE s.approval_request_id = ar.id
+        AND s.status = 'pending'
+        AND sa.user_id = $3
+    )
+  )
+ORDER BY ar.created_at DESC
+LIMIT $5
+OFFSET $4
 `
 
 type ListApprovalRequestsParams struct {
 	TenantID    pgtype.UUID            `json:"tenant_id"`
 	Status      NullApprovalStatusEnum `json:"status"`
+	Assignee    pgtype.UUID            `json:"assignee"`
 	OffsetCount int32                  `json:"offset_count"`
 	LimitCount  int32                  `json:"limit_count"`
 }
@@ -419,6 +562,7 @@ func (q *Queries) ListApprovalRequests(ctx context.Context, arg ListApprovalRequ
 	rows, err := q.db.Query(ctx, listApprovalRequests,
 		arg.TenantID,
 		arg.Status,
+		arg.Assignee,
 		arg.OffsetCount,
 		arg.LimitCount,
 	)
@@ -442,6 +586,13 @@ func (q *Queries) ListApprovalRequests(ctx context.Context, arg ListApprovalRequ
 			&i.DecidedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.PolicyID,
+			&i.MetricValue,
+			&i.InvalidatedAt,
+			&i.ChainID,
+			&i.CurrentStep,
+			&i.WithdrawnAt,
 		); err != nil {
 			return nil, err
 		}
@@ -477,10 +628,11 @@ SELECT
   )::int AS health_score
 FROM opportunities o
 LEFT JOIN LATERAL (
-  SELECT max(a.activity_at) AS last_activity_at
+  SELECT max(a.completed_at) AS last_activity_at
   FROM activities a
   WHERE a.tenant_id = o.tenant_id
     AND a.opportunity_id = o.id
+    AND a.completed_at IS NOT NULL
 ) AS last_activity ON true
 WHERE o.tenant_id = $1
 ORDER BY health_score ASC, o.updated_at ASC
@@ -504,6 +656,7 @@ type ListDealHealthRow struct {
 	HealthScore    int32                `json:"health_score"`
 }
 
+// Only completed activities count as engagement; open or overdue tasks do not.
 func (q *Queries) ListDealHealth(ctx context.Context, arg ListDealHealthParams) ([]ListDealHealthRow, error) {
 	rows, err := q.db.Query(ctx, listDealHealth, arg.TenantID, arg.OffsetCount, arg.LimitCount)
 	if err != nil {
@@ -770,7 +923,7 @@ SET
   updated_at = now()
 WHERE tenant_id = $3
   AND id = $4
-RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
 `
 
 type UpdateOpportunityNextActionParams struct {
@@ -806,6 +959,8 @@ func (q *Queries) UpdateOpportunityNextAction(ctx context.Context, arg UpdateOpp
 		&i.UpdatedAt,
 		&i.NextActionAt,
 		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
 	)
 	return i, err
 }
@@ -889,3 +1044,50 @@ func (q *Queries) UpsertIntegrationConnection(ctx context.Context, arg UpsertInt
 	)
 	return i, err
 }
+
+const withdrawApprovalRequest = `-- name: WithdrawApprovalRequest :one
+UPDATE approval_requests
+SET
+  status = 'withdrawn',
+  decision_note = $1,
+  current_step = NULL,
+  withdrawn_at = now(),
+  updated_at = now()
+WHERE tenant_id = $2
+  AND id = $3
+  AND status = 'pending'
+RETURNING id, tenant_id, entity_type, entity_id, requested_by, approver_user_id, status, reason, decision_note, decided_at, created_at, updated_at, version, policy_id, metric_value, invalidated_at, chain_id, current_step, withdrawn_at
+`
+
+type WithdrawApprovalRequestParams struct {
+	Reason     pgtype.Text `json:"reason"`
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	ApprovalID pgtype.UUID `json:"approval_id"`
+}
+
+func (q *Queries) WithdrawApprovalRequest(ctx context.Context, arg WithdrawApprovalRequestParams) (ApprovalRequest, error) {
+	row := q.db.QueryRow(ctx, withdrawApprovalRequest, arg.Reason, arg.TenantID, arg.ApprovalID)
+	var i ApprovalRequest
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.RequestedBy,
+		&i.ApproverUserID,
+		&i.Status,
+		&i.Reason,
+		&i.DecisionNote,
+		&i.DecidedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.PolicyID,
+		&i.MetricValue,
+		&i.InvalidatedAt,
+		&i.ChainID,
+		&i.CurrentStep,
+		&i.WithdrawnAt,
+	)
+	return i, err
+}
@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: field_history.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const listFieldChanges = `-- name: ListFieldChanges :many
+SELECT
+  fc.id,
+  fc.entity_type,
+  fc.entity_id,
+  fc.field_name,
+  fc.old_value,
+  fc.new_value,
+  fc.changed_by,
+  u.display_name AS changed_by_name,
+  fc.source,
+  fc.changed_at
+FROM field_changes fc
+LEFT JOIN users u ON u.id = fc.changed_by
+WHERE fc.tenant_id = $1
+  AND fc.entity_type = $2
+  AND fc.entity_id = $3
+  AND ($4::text[] IS NULL OR fc.field_name = ANY($4::text[]))
+  AND (
+    $5::timestamptz IS NULL
+    OR (fc.changed_at, fc.id) < ($5::timestamptz, $6::bigint)
+  )
+ORDER BY fc.changed_at DESC, fc.id DESC
+LIMIT $7
+`
+
+type ListFieldChangesParams struct {
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	EntityType string             `json:"entity_type"`
+	EntityID   pgtype.UUID        `json:"entity_id"`
+	FieldNames []string           `json:"field_names"`
+	CursorAt   pgtype.Timestamptz `json:"cursor_at"`
+	CursorID   pgtype.Int8        `json:"cursor_id"`
+	LimitCount int32              `json:"limit_count"`
+}
+
+type ListFieldChangesRow struct {
+	ID            int64              `json:"id"`
+	EntityType    string             `json:"entity_type"`
+	EntityID      pgtype.UUID        `json:"entity_id"`
+	FieldName     string             `json:"field_name"`
+	OldValue      []byte             `json:"old_value"`
+	NewValue      []byte             `json:"new_value"`
+	ChangedBy     pgtype.UUID        `json:"changed_by"`
+	ChangedByName pgtype.Text        `json:"changed_by_name"`
+	Source        ChangeSourceEnum   `json:"source"`
+	ChangedAt     pgtype.Timestamptz `json:"changed_at"`
+}
+
+func (q *Queries) ListFieldChanges(ctx context.Context, arg ListFieldChangesParams) ([]ListFieldChangesRow, error) {
+	rows, err := q.db.Query(ctx, listFieldChanges,
+		arg.TenantID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.FieldNames,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListFieldChangesRow{}
+	for rows.Next() {
+		var i ListFieldChangesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.FieldName,
+			&i.OldValue,
+			&i.NewValue,
+			&i.ChangedBy,
+			&i.ChangedByName,
+			&i.Source,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setChangeContext = `-- name: SetChangeContext :exec
+SELECT
+  set_config('app.user_id', $1::text, true),
+  set_config('app.change_source', $2::text, true)
+`
+
+type SetChangeContextParams struct {
+	UserID string `json:"user_id"`
+	Source string `json:"source"`
+}
+
+func (q *Queries) SetChangeContext(ctx context.Context, arg SetChangeContextParams) error {
+	_, err := q.db.Exec(ctx, setChangeContext, arg.UserID, arg.Source)
+	return err
+}
@@ -0,0 +1,825 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: line_items.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const copyOpportunityLineItemsToQuote = `-- name: CopyOpportunityLineItemsToQuote :execrows
+INSERT INTO line_items (
+  tenant_id,
+  quote_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  $1::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = $2
+  AND li.opportunity_id = $3
+`
+
+type CopyOpportunityLineItemsToQuoteParams struct {
+	QuoteID       pgtype.UUID `json:"quote_id"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) CopyOpportunityLineItemsToQuote(ctx context.Context, arg CopyOpportunityLineItemsToQuoteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyOpportunityLineItemsToQuote, arg.QuoteID, arg.TenantID, arg.OpportunityID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copyQuoteLineItems = `-- name: CopyQuoteLineItems :execrows
+INSERT INTO line_items (
+  tenant_id,
+  opportunity_id,
+  order_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  $1::uuid,
+  $2::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = $3
+  AND li.quote_id = $4
+`
+
+type CopyQuoteLineItemsParams struct {
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	OrderID       pgtype.UUID `json:"order_id"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	QuoteID       pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) CopyQuoteLineItems(ctx context.Context, arg CopyQuoteLineItemsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyQuoteLineItems,
+		arg.OpportunityID,
+		arg.OrderID,
+		arg.TenantID,
+		arg.QuoteID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copyQuoteLineItemsToQuote = `-- name: CopyQuoteLineItemsToQuote :execrows
+INSERT INTO line_items (
+  tenant_id,
+  quote_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+)
+SELECT
+  li.tenant_id,
+  $1::uuid,
+  li.product_id,
+  li.price_book_id,
+  li.description,
+  li.quantity,
+  li.unit_price,
+  li.discount_percent,
+  li.tax_rate,
+  li.sort_order
+FROM line_items li
+WHERE li.tenant_id = $2
+  AND li.quote_id = $3
+`
+
+type CopyQuoteLineItemsToQuoteParams struct {
+	TargetQuoteID pgtype.UUID `json:"target_quote_id"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	SourceQuoteID pgtype.UUID `json:"source_quote_id"`
+}
+
+func (q *Queries) CopyQuoteLineItemsToQuote(ctx context.Context, arg CopyQuoteLineItemsToQuoteParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyQuoteLineItemsToQuote, arg.TargetQuoteID, arg.TenantID, arg.SourceQuoteID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countLiveQuoteLineItems = `-- name: CountLiveQuoteLineItems :one
+SELECT count(*)::bigint
+FROM line_items li
+JOIN quotes qt ON qt.id = li.quote_id
+WHERE qt.tenant_id = $1
+  AND qt.opportunity_id = $2
+  AND qt.status IN ('draft', 'sent', 'accepted')
+`
+
+type CountLiveQuoteLineItemsParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+// CountLiveQuoteLineItems counts the lines on the opportunity's draft, sent and accepted
+// quotes; while there are any, the deal amount follows the quote.
+func (q *Queries) CountLiveQuoteLineItems(ctx context.Context, arg CountLiveQuoteLineItemsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countLiveQuoteLineItems, arg.TenantID, arg.OpportunityID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countOpportunityLineItems = `-- name: CountOpportunityLineItems :one
+SELECT count(*)::bigint
+FROM line_items
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+`
+
+type CountOpportunityLineItemsParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) CountOpportunityLineItems(ctx context.Context, arg CountOpportunityLineItemsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOpportunityLineItems, arg.TenantID, arg.OpportunityID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createLineItem = `-- name: CreateLineItem :one
+INSERT INTO line_items (
+  tenant_id,
+  opportunity_id,
+  quote_id,
+  order_id,
+  product_id,
+  price_book_id,
+  description,
+  quantity,
+  unit_price,
+  discount_percent,
+  tax_rate,
+  sort_order
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11,
+  $12
+)
+RETURNING id, tenant_id, opportunity_id, quote_id, order_id, product_id, price_book_id, description, quantity, unit_price, discount_percent, sort_order, created_at, updated_at, tax_rate, currency, line_total
+`
+
+type CreateLineItemParams struct {
+	TenantID        pgtype.UUID    `json:"tenant_id"`
+	OpportunityID   pgtype.UUID    `json:"opportunity_id"`
+	QuoteID         pgtype.UUID    `json:"quote_id"`
+	OrderID         pgtype.UUID    `json:"order_id"`
+	ProductID       pgtype.UUID    `json:"product_id"`
+	PriceBookID     pgtype.UUID    `json:"price_book_id"`
+	Description     pgtype.Text    `json:"description"`
+	Quantity        pgtype.Numeric `json:"quantity"`
+	UnitPrice       pgtype.Numeric `json:"unit_price"`
+	DiscountPercent pgtype.Numeric `json:"discount_percent"`
+	TaxRate         pgtype.Numeric `json:"tax_rate"`
+	SortOrder       int32          `json:"sort_order"`
+}
+
+func (q *Queries) CreateLineItem(ctx context.Context, arg CreateLineItemParams) (LineItem, error) {
+	row := q.db.QueryRow(ctx, createLineItem,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.QuoteID,
+		arg.OrderID,
+		arg.ProductID,
+		arg.PriceBookID,
+		arg.Description,
+		arg.Quantity,
+		arg.UnitPrice,
+		arg.DiscountPercent,
+		arg.TaxRate,
+		arg.SortOrder,
+	)
+	var i LineItem
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteID,
+		&i.OrderID,
+		&i.ProductID,
+		&i.PriceBookID,
+		&i.Description,
+		&i.Quantity,
+		&i.UnitPrice,
+		&i.DiscountPercent,
+		&i.SortOrder,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TaxRate,
+		&i.Currency,
+		&i.LineTotal,
+	)
+	return i, err
+}
+
+const deleteLineItems = `-- name: DeleteLineItems :exec
+DELETE FROM line_items
+WHERE tenant_id = $1
+  AND opportunity_id IS NOT DISTINCT FROM $2::uuid
+  AND quote_id IS NOT DISTINCT FROM $3::uuid
+  AND order_id IS NOT DISTINCT FROM $4::uuid
+`
+
+type DeleteLineItemsParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	QuoteID       pgtype.UUID `json:"quote_id"`
+	OrderID       pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) DeleteLineItems(ctx context.Context, arg DeleteLineItemsParams) error {
+	_, err := q.db.Exec(ctx, deleteLineItems,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.QuoteID,
+		arg.OrderID,
+	)
+	return err
+}
+
+const getOrder = `-- name: GetOrder :one
+SELECT id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
+FROM orders
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetOrderParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) GetOrder(ctx context.Context, arg GetOrderParams) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrder, arg.TenantID, arg.OrderID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.OrderNo,
+		&i.Amount,
+		&i.Status,
+		&i.OrderedOn,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
+	)
+	return i, err
+}
+
+const getPipelineSummaryByProduct = `-- name: GetPipelineSummaryByProduct :many
+SELECT
+  o.stage,
+  p.id AS product_id,
+  p.sku,
+  p.name AS product_name,
+  count(DISTINCT o.id)::bigint AS deal_count,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)))
+    FILTER (WHERE fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)) IS NOT NULL), 0)::double precision AS total_amount,
+  count(DISTINCT o.id) FILTER (WHERE fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at::date, current_date)) IS NULL)::bigint AS missing_rate_count
+FROM line_items li
+JOIN opportunities o ON o.id = li.opportunity_id
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = $1
+GROUP BY o.stage, p.id, p.sku, p.name
+ORDER BY o.stage, total_amount DESC
+`
+
+type GetPipelineSummaryByProductRow struct {
+	Stage            OpportunityStageEnum `json:"stage"`
+	ProductID        pgtype.UUID          `json:"product_id"`
+	Sku              string               `json:"sku"`
+	ProductName      string               `json:"product_name"`
+	DealCount        int64                `json:"deal_count"`
+	TotalAmount      float64              `json:"total_amount"`
+	MissingRateCount int64                `json:"missing_rate_count"`
+}
+
+// Same rates as GetPipelineSummary; lines without one are left out of total_amount.
+func (q *Queries) GetPipelineSummaryByProduct(ctx context.Context, tenantID pgtype.UUID) ([]GetPipelineSummaryByProductRow, error) {
+	rows, err := q.db.Query(ctx, getPipelineSummaryByProduct, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPipelineSummaryByProductRow{}
+	for rows.Next() {
+		var i GetPipelineSummaryByProductRow
+		if err := rows.Scan(
+			&i.Stage,
+			&i.ProductID,
+			&i.Sku,
+			&i.ProductName,
+			&i.DealCount,
+			&i.TotalAmount,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProductRevenueSummary = `-- name: GetProductRevenueSummary :many
+SELECT
+  p.id AS product_id,
+  p.sku,
+  p.name AS product_name,
+  count(DISTINCT o.id) FILTER (WHERE o.stage NOT IN ('closed_won', 'closed_lost'))::bigint AS open_deal_count,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, current_date)) FILTER (
+    WHERE o.stage NOT IN ('closed_won', 'closed_lost')
+      AND fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL
+  ), 0)::double precision AS pipeline_amount,
+  coalesce(sum(li.line_total * (o.probability::numeric / 100.0) * fx_rate(o.tenant_id, o.currency, current_date)) FILTER (
+    WHERE o.stage NOT IN ('closed_won', 'closed_lost')
+      AND fx_rate(o.tenant_id, o.currency, current_date) IS NOT NULL
+  ), 0)::double precision AS weighted_amount,
+  count(DISTINCT o.id) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= $1
+      AND o.closed_at < $2
+  )::bigint AS won_deal_count,
+  coalesce(sum(li.quantity) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= $1
+      AND o.closed_at < $2
+  ), 0)::double precision AS won_quantity,
+  coalesce(sum(li.line_total * fx_rate(o.tenant_id, o.currency, o.closed_at::date)) FILTER (
+    WHERE o.stage = 'closed_won'
+      AND o.closed_at >= $1
+      AND o.closed_at < $2
+      AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NOT NULL
+  ), 0)::double precision AS won_amount,
+  count(DISTINCT o.id) FILTER (
+    WHERE (o.stage NOT IN ('closed_won', 'closed_lost') AND fx_rate(o.tenant_id, o.currency, current_date) IS NULL)
+       OR (o.stage = 'closed_won'
+           AND o.closed_at >= $1
+           AND o.closed_at < $2
+           AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NULL)
+  )::bigint AS missing_rate_count
+FROM line_items li
+JOIN opportunities o ON o.id = li.opportunity_id
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = $3
+  AND ($4::uuid IS NULL OR o.owner_user_id = $4)
+GROUP BY p.id, p.sku, p.name
+ORDER BY won_amount DESC, pipeline_amount DESC, p.sku ASC
+`
+
+type GetProductRevenueSummaryParams struct {
+	RangeStart  pgtype.Timestamptz `json:"range_start"`
+	RangeEnd    pgtype.Timestamptz `json:"range_end"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	OwnerUserID pgtype.UUID        `json:"owner_user_id"`
+}
+
+type GetProductRevenueSummaryRow struct {
+	ProductID        pgtype.UUID `json:"product_id"`
+	Sku              string      `json:"sku"`
+	ProductName      string      `json:"product_name"`
+	OpenDealCount    int64       `json:"open_deal_count"`
+	PipelineAmount   float64     `json:"pipeline_amount"`
+	WeightedAmount   float64     `json:"weighted_amount"`
+	WonDealCount     int64       `json:"won_deal_count"`
+	WonQuantity      float64     `json:"won_quantity"`
+	WonAmount        float64     `json:"won_amount"`
+	MissingRateCount int64       `json:"missing_rate_count"`
+}
+
+// Open lines convert at today's rate and won lines at the close date's rate. Lines
+// without a rate are left out of the amounts; their deals count in missing_rate_count.
+func (q *Queries) GetProductRevenueSummary(ctx context.Context, arg GetProductRevenueSummaryParams) ([]GetProductRevenueSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getProductRevenueSummary,
+		arg.RangeStart,
+		arg.RangeEnd,
+		arg.TenantID,
+		arg.OwnerUserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetProductRevenueSummaryRow{}
+	for rows.Next() {
+		var i GetProductRevenueSummaryRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.Sku,
+			&i.ProductName,
+			&i.OpenDealCount,
+			&i.PipelineAmount,
+			&i.WeightedAmount,
+			&i.WonDealCount,
+			&i.WonQuantity,
+			&i.WonAmount,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuote = `-- name: GetQuote :one
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+FROM quotes
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetQuoteParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) GetQuote(ctx context.Context, arg GetQuoteParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, getQuote, arg.TenantID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const listLineItems = `-- name: ListLineItems :many
+
+SELECT
+  li.id, li.tenant_id, li.opportunity_id, li.quote_id, li.order_id, li.product_id, li.price_book_id, li.description, li.quantity, li.unit_price, li.discount_percent, li.sort_order, li.created_at, li.updated_at, li.tax_rate, li.currency, li.line_total,
+  p.sku,
+  p.name AS product_name
+FROM line_items li
+JOIN products p ON p.id = li.product_id
+WHERE li.tenant_id = $1
+  AND li.opportunity_id IS NOT DISTINCT FROM $2::uuid
+  AND li.quote_id IS NOT DISTINCT FROM $3::uuid
+  AND li.order_id IS NOT DISTINCT FROM $4::uuid
+ORDER BY li.sort_order ASC, li.created_at ASC
+`
+
+type ListLineItemsParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	QuoteID       pgtype.UUID `json:"quote_id"`
+	OrderID       pgtype.UUID `json:"order_id"`
+}
+
+type ListLineItemsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	OpportunityID   pgtype.UUID        `json:"opportunity_id"`
+	QuoteID         pgtype.UUID        `json:"quote_id"`
+	OrderID         pgtype.UUID        `json:"order_id"`
+	ProductID       pgtype.UUID        `json:"product_id"`
+	PriceBookID     pgtype.UUID        `json:"price_book_id"`
+	Description     pgtype.Text        `json:"description"`
+	Quantity        pgtype.Numeric     `json:"quantity"`
+	UnitPrice       pgtype.Numeric     `json:"unit_price"`
+	DiscountPercent pgtype.Numeric     `json:"discount_percent"`
+	SortOrder       int32              `json:"sort_order"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	TaxRate         pgtype.Numeric     `json:"tax_rate"`
+	Currency        string             `json:"currency"`
+	LineTotal       pgtype.Numeric     `json:"line_total"`
+	Sku             string             `json:"sku"`
+	ProductName     string             `json:"product_name"`
+}
+
+// Exactly one of opportunity_id, quote_id and order_id is set per call; IS NOT DISTINCT
+// FROM keeps the other two parents NULL so a query never mixes line item sets.
+func (q *Queries) ListLineItems(ctx context.Context, arg ListLineItemsParams) ([]ListLineItemsRow, error) {
+	rows, err := q.db.Query(ctx, listLineItems,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.QuoteID,
+		arg.OrderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLineItemsRow{}
+	for rows.Next() {
+		var i ListLineItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.QuoteID,
+			&i.OrderID,
+			&i.ProductID,
+			&i.PriceBookID,
+			&i.Description,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.DiscountPercent,
+			&i.SortOrder,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TaxRate,
+			&i.Currency,
+			&i.LineTotal,
+			&i.Sku,
+			&i.ProductName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recalculateOpportunityAmount = `-- name: RecalculateOpportunityAmount :one
+UPDATE opportunities o
+SET amount = coalesce(
+      (
+        SELECT qt.amount
+        FROM quotes qt
+        WHERE qt.tenant_id = o.tenant_id
+          AND qt.opportunity_id = o.id
+          AND qt.status IN ('draft', 'sent', 'accepted')
+          AND EXISTS (SELECT 1 FROM line_items qli WHERE qli.quote_id = qt.id)
+        ORDER BY qt.status = 'accepted' DESC, qt.created_at DESC, qt.id DESC
+        LIMIT 1
+      ),
+      (
+        SELECT sum(li.line_total)
+        FROM line_items li
+        WHERE li.opportunity_id = o.id
+      ),
+      o.amount
+    ),
+    updated_at = now()
+WHERE o.tenant_id = $1
+  AND o.id = $2
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
+`
+
+type RecalculateOpportunityAmountParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+// RecalculateOpportunityAmount derives the deal amount from its latest live quote: the
+// accepted one, else the newest draft or sent quote with line items. Superseded, rejected
+// and expired quotes never count; without a live quote the opportunity's own lines do, and
+// a deal with neither keeps its current amount.
+func (q *Queries) RecalculateOpportunityAmount(ctx context.Context, arg RecalculateOpportunityAmountParams) (Opportunity, error) {
+	row := q.db.QueryRow(ctx, recalculateOpportunityAmount, arg.TenantID, arg.OpportunityID)
+	var i Opportunity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.ContactID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Stage,
+		&i.Probability,
+		&i.Amount,
+		&i.ExpectedCloseDate,
+		&i.ClosedAt,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextActionAt,
+		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
+	)
+	return i, err
+}
+
+const recalculateOrderAmount = `-- name: RecalculateOrderAmount :one
+UPDATE orders od
+SET amount = (
+      SELECT coalesce(sum(li.line_total), 0)
+      FROM line_items li
+      WHERE li.order_id = od.id
+    ),
+    updated_at = now()
+WHERE od.tenant_id = $1
+  AND od.id = $2
+RETURNING id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
+`
+
+type RecalculateOrderAmountParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) RecalculateOrderAmount(ctx context.Context, arg RecalculateOrderAmountParams) (Order, error) {
+	row := q.db.QueryRow(ctx, recalculateOrderAmount, arg.TenantID, arg.OrderID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.OrderNo,
+		&i.Amount,
+		&i.Status,
+		&i.OrderedOn,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
+	)
+	return i, err
+}
+
+const recalculateQuoteTotals = `-- name: RecalculateQuoteTotals :one
+WITH by_rate AS (
+  SELECT li.tax_rate, sum(li.line_total) AS taxable_amount
+  FROM line_items li
+  WHERE li.tenant_id = $1
+    AND li.quote_id = $2
+  GROUP BY li.tax_rate
+),
+taxed AS (
+  SELECT
+    br.tax_rate,
+    br.taxable_amount,
+    round_tax(br.taxable_amount * br.tax_rate / 100, t.tax_rounding, currency_minor_units(qh.currency)) AS tax_amount
+  FROM by_rate br
+  CROSS JOIN quotes qh
+  JOIN tenants t ON t.id = qh.tenant_id
+  WHERE qh.tenant_id = $1
+    AND qh.id = $2
+),
+totals AS (
+  SELECT
+    coalesce(sum(tx.taxable_amount), 0) AS amount,
+    coalesce(sum(tx.tax_amount), 0) AS tax_amount,
+    coalesce(
+      jsonb_agg(
+        jsonb_build_object('taxRate', tx.tax_rate, 'taxableAmount', tx.taxable_amount, 'taxAmount', tx.tax_amount)
+        ORDER BY tx.tax_rate DESC
+      ),
+      '[]'::jsonb
+    ) AS tax_breakdown
+  FROM taxed tx
+)
+UPDATE quotes q
+SET amount = totals.amount,
+    tax_amount = totals.tax_amount,
+    total_amount = totals.amount + totals.tax_amount,
+    tax_breakdown = totals.tax_breakdown,
+    updated_at = now()
+FROM totals
+WHERE q.tenant_id = $1
+  AND q.id = $2
+RETURNING q.id, q.tenant_id, q.opportunity_id, q.quote_no, q.amount, q.status, q.issued_on, q.valid_until, q.note, q.created_by, q.created_at, q.updated_at, q.currency, q.version, q.tax_amount, q.total_amount, q.tax_breakdown, q.root_quote_id, q.previous_quote_id, q.revision, q.superseded_at, q.expired_at, q.approved_for_send_at
+`
+
+type RecalculateQuoteTotalsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+// RecalculateQuoteTotals derives the pre-tax amount, consumption tax and total from the
+// line items. Tax is rounded once per rate with the tenant's rounding mode.
+func (q *Queries) RecalculateQuoteTotals(ctx context.Context, arg RecalculateQuoteTotalsParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, recalculateQuoteTotals, arg.TenantID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
@@ -100,12 +100,57 @@ func (ns NullActivityTypeEnum) Value() (driver.Value, error) {
 	return string(ns.ActivityTypeEnum), nil
 }
 
+type ApprovalApproverTypeEnum string
+
+const (
+	ApprovalApproverTypeEnumUser ApprovalApproverTypeEnum = "user"
+	ApprovalApproverTypeEnumRole ApprovalApproverTypeEnum = "role"
+	ApprovalApproverTypeEnumTeam ApprovalApproverTypeEnum = "team"
+)
+
+func (e *ApprovalApproverTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ApprovalApproverTypeEnum(s)
+	case string:
+		*e = ApprovalApproverTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ApprovalApproverTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullApprovalApproverTypeEnum struct {
+	ApprovalApproverTypeEnum ApprovalApproverTypeEnum `json:"approval_approver_type_enum"`
+	Valid                    bool                     `json:"valid"` // Valid is true if ApprovalApproverTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullApprovalApproverTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ApprovalApproverTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ApprovalApproverTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullApprovalApproverTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ApprovalApproverTypeEnum), nil
+}
+
 type ApprovalStatusEnum string
 
 const (
-	ApprovalStatusEnumPending  ApprovalStatusEnum = "pending"
-	ApprovalStatusEnumApproved ApprovalStatusEnum = "approved"
-	ApprovalStatusEnumRejected ApprovalStatusEnum = "rejected"
+	ApprovalStatusEnumPending     ApprovalStatusEnum = "pending"
+	ApprovalStatusEnumApproved    ApprovalStatusEnum = "approved"
+	ApprovalStatusEnumRejected    ApprovalStatusEnum = "rejected"
+	ApprovalStatusEnumInvalidated ApprovalStatusEnum = "invalidated"
+	ApprovalStatusEnumWithdrawn   ApprovalStatusEnum = "withdrawn"
 )
 
 func (e *ApprovalStatusEnum) Scan(src interface{}) error {
@@ -143,6 +188,52 @@ func (ns NullApprovalStatusEnum) Value() (driver.Value, error) {
 	return string(ns.ApprovalStatusEnum), nil
 }
 
+type ApprovalStepStatusEnum string
+
+const (
+	ApprovalStepStatusEnumWaiting   ApprovalStepStatusEnum = "waiting"
+	ApprovalStepStatusEnumPending   ApprovalStepStatusEnum = "pending"
+	ApprovalStepStatusEnumApproved  ApprovalStepStatusEnum = "approved"
+	ApprovalStepStatusEnumRejected  ApprovalStepStatusEnum = "rejected"
+	ApprovalStepStatusEnumSkipped   ApprovalStepStatusEnum = "skipped"
+	ApprovalStepStatusEnumCancelled ApprovalStepStatusEnum = "cancelled"
+)
+
+func (e *ApprovalStepStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ApprovalStepStatusEnum(s)
+	case string:
+		*e = ApprovalStepStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ApprovalStepStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullApprovalStepStatusEnum struct {
+	ApprovalStepStatusEnum ApprovalStepStatusEnum `json:"approval_step_status_enum"`
+	Valid                  bool                   `json:"valid"` // Valid is true if ApprovalStepStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullApprovalStepStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ApprovalStepStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ApprovalStepStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullApprovalStepStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ApprovalStepStatusEnum), nil
+}
+
 type AuditActionEnum string
 
 const (
@@ -187,6 +278,138 @@ func (ns NullAuditActionEnum) Value() (driver.Value, error) {
 	return string(ns.AuditActionEnum), nil
 }
 
+type BillingFrequencyEnum string
+
+const (
+	BillingFrequencyEnumOneTime    BillingFrequencyEnum = "one_time"
+	BillingFrequencyEnumMonthly    BillingFrequencyEnum = "monthly"
+	BillingFrequencyEnumQuarterly  BillingFrequencyEnum = "quarterly"
+	BillingFrequencyEnumSemiAnnual BillingFrequencyEnum = "semi_annual"
+	BillingFrequencyEnumAnnual     BillingFrequencyEnum = "annual"
+)
+
+func (e *BillingFrequencyEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = BillingFrequencyEnum(s)
+	case string:
+		*e = BillingFrequencyEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for BillingFrequencyEnum: %T", src)
+	}
+	return nil
+}
+
+type NullBillingFrequencyEnum struct {
+	BillingFrequencyEnum BillingFrequencyEnum `json:"billing_frequency_enum"`
+	Valid                bool                 `json:"valid"` // Valid is true if BillingFrequencyEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullBillingFrequencyEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.BillingFrequencyEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.BillingFrequencyEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullBillingFrequencyEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.BillingFrequencyEnum), nil
+}
+
+type ChangeSourceEnum string
+
+const (
+	ChangeSourceEnumApi       ChangeSourceEnum = "api"
+	ChangeSourceEnumCsvImport ChangeSourceEnum = "csv_import"
+	ChangeSourceEnumMerge     ChangeSourceEnum = "merge"
+	ChangeSourceEnumSystem    ChangeSourceEnum = "system"
+)
+
+func (e *ChangeSourceEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ChangeSourceEnum(s)
+	case string:
+		*e = ChangeSourceEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ChangeSourceEnum: %T", src)
+	}
+	return nil
+}
+
+type NullChangeSourceEnum struct {
+	ChangeSourceEnum ChangeSourceEnum `json:"change_source_enum"`
+	Valid            bool             `json:"valid"` // Valid is true if ChangeSourceEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullChangeSourceEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.ChangeSourceEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ChangeSourceEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullChangeSourceEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ChangeSourceEnum), nil
+}
+
+type DiscountPolicyMetricEnum string
+
+const (
+	DiscountPolicyMetricEnumLineDiscountPercent  DiscountPolicyMetricEnum = "line_discount_percent"
+	DiscountPolicyMetricEnumQuoteDiscountPercent DiscountPolicyMetricEnum = "quote_discount_percent"
+	DiscountPolicyMetricEnumQuoteAmount          DiscountPolicyMetricEnum = "quote_amount"
+)
+
+func (e *DiscountPolicyMetricEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DiscountPolicyMetricEnum(s)
+	case string:
+		*e = DiscountPolicyMetricEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DiscountPolicyMetricEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDiscountPolicyMetricEnum struct {
+	DiscountPolicyMetricEnum DiscountPolicyMetricEnum `json:"discount_policy_metric_enum"`
+	Valid                    bool                     `json:"valid"` // Valid is true if DiscountPolicyMetricEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDiscountPolicyMetricEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DiscountPolicyMetricEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DiscountPolicyMetricEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDiscountPolicyMetricEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DiscountPolicyMetricEnum), nil
+}
+
 type IntegrationProviderEnum string
 
 const (
@@ -405,6 +628,51 @@ func (ns NullOpportunityStageEnum) Value() (driver.Value, error) {
 	return string(ns.OpportunityStageEnum), nil
 }
 
+type OpportunityTeamRoleEnum string
+
+const (
+	OpportunityTeamRoleEnumPrimaryRep       OpportunityTeamRoleEnum = "primary_rep"
+	OpportunityTeamRoleEnumPresalesEngineer OpportunityTeamRoleEnum = "presales_engineer"
+	OpportunityTeamRoleEnumPartnerManager   OpportunityTeamRoleEnum = "partner_manager"
+	OpportunityTeamRoleEnumExecutiveSponsor OpportunityTeamRoleEnum = "executive_sponsor"
+	OpportunityTeamRoleEnumOther            OpportunityTeamRoleEnum = "other"
+)
+
+func (e *OpportunityTeamRoleEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OpportunityTeamRoleEnum(s)
+	case string:
+		*e = OpportunityTeamRoleEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OpportunityTeamRoleEnum: %T", src)
+	}
+	return nil
+}
+
+type NullOpportunityTeamRoleEnum struct {
+	OpportunityTeamRoleEnum OpportunityTeamRoleEnum `json:"opportunity_team_role_enum"`
+	Valid                   bool                    `json:"valid"` // Valid is true if OpportunityTeamRoleEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOpportunityTeamRoleEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.OpportunityTeamRoleEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OpportunityTeamRoleEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOpportunityTeamRoleEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OpportunityTeamRoleEnum), nil
+}
+
 type OrderStatusEnum string
 
 const (
@@ -452,11 +720,12 @@ func (ns NullOrderStatusEnum) Value() (driver.Value, error) {
 type QuoteStatusEnum string
 
 const (
-	QuoteStatusEnumDraft    QuoteStatusEnum = "draft"
-	QuoteStatusEnumSent     QuoteStatusEnum = "sent"
-	QuoteStatusEnumAccepted QuoteStatusEnum = "accepted"
-	QuoteStatusEnumRejected QuoteStatusEnum = "rejected"
-	QuoteStatusEnumExpired  QuoteStatusEnum = "expired"
+	QuoteStatusEnumDraft      QuoteStatusEnum = "draft"
+	QuoteStatusEnumSent       QuoteStatusEnum = "sent"
+	QuoteStatusEnumAccepted   QuoteStatusEnum = "accepted"
+	QuoteStatusEnumRejected   QuoteStatusEnum = "rejected"
+	QuoteStatusEnumExpired    QuoteStatusEnum = "expired"
+	QuoteStatusEnumSuperseded QuoteStatusEnum = "superseded"
 )
 
 func (e *QuoteStatusEnum) Scan(src interface{}) error {
@@ -537,6 +806,92 @@ func (ns NullRoleEnum) Value() (driver.Value, error) {
 	return string(ns.RoleEnum), nil
 }
 
+type TaskPriorityEnum string
+
+const (
+	TaskPriorityEnumLow    TaskPriorityEnum = "low"
+	TaskPriorityEnumNormal TaskPriorityEnum = "normal"
+	TaskPriorityEnumHigh   TaskPriorityEnum = "high"
+)
+
+func (e *TaskPriorityEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TaskPriorityEnum(s)
+	case string:
+		*e = TaskPriorityEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TaskPriorityEnum: %T", src)
+	}
+	return nil
+}
+
+type NullTaskPriorityEnum struct {
+	TaskPriorityEnum TaskPriorityEnum `json:"task_priority_enum"`
+	Valid            bool             `json:"valid"` // Valid is true if TaskPriorityEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTaskPriorityEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.TaskPriorityEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TaskPriorityEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTaskPriorityEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TaskPriorityEnum), nil
+}
+
+type TaxRoundingEnum string
+
+const (
+	TaxRoundingEnumFloor TaxRoundingEnum = "floor"
+	TaxRoundingEnumRound TaxRoundingEnum = "round"
+	TaxRoundingEnumCeil  TaxRoundingEnum = "ceil"
+)
+
+func (e *TaxRoundingEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TaxRoundingEnum(s)
+	case string:
+		*e = TaxRoundingEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TaxRoundingEnum: %T", src)
+	}
+	return nil
+}
+
+type NullTaxRoundingEnum struct {
+	TaxRoundingEnum TaxRoundingEnum `json:"tax_rounding_enum"`
+	Valid           bool            `json:"valid"` // Valid is true if TaxRoundingEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTaxRoundingEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.TaxRoundingEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TaxRoundingEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTaxRoundingEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TaxRoundingEnum), nil
+}
+
 type Account struct {
 	ID          pgtype.UUID        `json:"id"`
 	TenantID    pgtype.UUID        `json:"tenant_id"`
@@ -550,6 +905,7 @@ type Account struct {
 	CreatedBy   pgtype.UUID        `json:"created_by"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int64              `json:"version"`
 }
 
 type AccountLocation struct {
@@ -565,18 +921,93 @@ type AccountLocation struct {
 	AddressLine2 pgtype.Text        `json:"address_line2"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	IsBilling    bool               `json:"is_billing"`
 }
 
 type Activity struct {
-	ID            pgtype.UUID        `json:"id"`
-	TenantID      pgtype.UUID        `json:"tenant_id"`
-	OpportunityID pgtype.UUID        `json:"opportunity_id"`
-	ActivityType  ActivityTypeEnum   `json:"activity_type"`
-	Subject       string             `json:"subject"`
-	Detail        pgtype.Text        `json:"detail"`
-	ActivityAt    pgtype.Timestamptz `json:"activity_at"`
-	CreatedBy     pgtype.UUID        `json:"created_by"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ID                 pgtype.UUID          `json:"id"`
+	TenantID           pgtype.UUID          `json:"tenant_id"`
+	OpportunityID      pgtype.UUID          `json:"opportunity_id"`
+	ActivityType       ActivityTypeEnum     `json:"activity_type"`
+	Subject            string               `json:"subject"`
+	Detail             pgtype.Text          `json:"detail"`
+	ActivityAt         pgtype.Timestamptz   `json:"activity_at"`
+	CreatedBy          pgtype.UUID          `json:"created_by"`
+	CreatedAt          pgtype.Timestamptz   `json:"created_at"`
+	AssigneeUserID     pgtype.UUID          `json:"assignee_user_id"`
+	DueAt              pgtype.Timestamptz   `json:"due_at"`
+	Priority           NullTaskPriorityEnum `json:"priority"`
+	CompletedAt        pgtype.Timestamptz   `json:"completed_at"`
+	CompletedBy        pgtype.UUID          `json:"completed_by"`
+	UpdatedAt          pgtype.Timestamptz   `json:"updated_at"`
+	RecurrenceRule     pgtype.Text          `json:"recurrence_rule"`
+	RecurrenceSeriesID pgtype.UUID          `json:"recurrence_series_id"`
+	RecurrenceAnchor   pgtype.Timestamptz   `json:"recurrence_anchor"`
+	RecurrenceSeq      pgtype.Int4          `json:"recurrence_seq"`
+	SourceEventID      pgtype.Int8          `json:"source_event_id"`
+	ExternalEventID    pgtype.Text          `json:"external_event_id"`
+}
+
+type ActivityTemplate struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	IsActive    bool               `json:"is_active"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ActivityTemplateStep struct {
+	ID             int64            `json:"id"`
+	TenantID       pgtype.UUID      `json:"tenant_id"`
+	TemplateID     pgtype.UUID      `json:"template_id"`
+	Position       int32            `json:"position"`
+	Subject        string           `json:"subject"`
+	Detail         pgtype.Text      `json:"detail"`
+	OffsetDays     int32            `json:"offset_days"`
+	Priority       TaskPriorityEnum `json:"priority"`
+	RecurrenceRule pgtype.Text      `json:"recurrence_rule"`
+}
+
+type ApprovalChain struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	IsActive    bool               `json:"is_active"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int64              `json:"version"`
+}
+
+type ApprovalChainStep struct {
+	ID               pgtype.UUID                 `json:"id"`
+	TenantID         pgtype.UUID                 `json:"tenant_id"`
+	ChainID          pgtype.UUID                 `json:"chain_id"`
+	StepOrder        int32                       `json:"step_order"`
+	Name             string                      `json:"name"`
+	ApproverType     ApprovalApproverTypeEnum    `json:"approver_type"`
+	ApproverUserID   pgtype.UUID                 `json:"approver_user_id"`
+	ApproverRole     NullRoleEnum                `json:"approver_role"`
+	ApproverTeamRole NullOpportunityTeamRoleEnum `json:"approver_team_role"`
+	MinValue         pgtype.Numeric              `json:"min_value"`
+	SlaHours         pgtype.Int4                 `json:"sla_hours"`
+	EscalationUserID pgtype.UUID                 `json:"escalation_user_id"`
+	CreatedAt        pgtype.Timestamptz          `json:"created_at"`
+}
+
+type ApprovalDelegation struct {
+	ID             pgtype.UUID        `json:"id"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	DelegateUserID pgtype.UUID        `json:"delegate_user_id"`
+	StartsAt       pgtype.Timestamptz `json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `json:"ends_at"`
+	Reason         pgtype.Text        `json:"reason"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
 type ApprovalRequest struct {
@@ -592,6 +1023,56 @@ type ApprovalRequest struct {
 	DecidedAt      pgtype.Timestamptz `json:"decided_at"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	Version        int64              `json:"version"`
+	PolicyID       pgtype.UUID        `json:"policy_id"`
+	MetricValue    pgtype.Numeric     `json:"metric_value"`
+	InvalidatedAt  pgtype.Timestamptz `json:"invalidated_at"`
+	ChainID        pgtype.UUID        `json:"chain_id"`
+	CurrentStep    pgtype.Int4        `json:"current_step"`
+	WithdrawnAt    pgtype.Timestamptz `json:"withdrawn_at"`
+}
+
+type ApprovalRequestEvent struct {
+	ID                pgtype.UUID        `json:"id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID        `json:"approval_request_id"`
+	StepOrder         pgtype.Int4        `json:"step_order"`
+	Event             string             `json:"event"`
+	ActorUserID       pgtype.UUID        `json:"actor_user_id"`
+	Note              pgtype.Text        `json:"note"`
+	Data              []byte             `json:"data"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+}
+
+type ApprovalRequestStep struct {
+	ID                pgtype.UUID                 `json:"id"`
+	TenantID          pgtype.UUID                 `json:"tenant_id"`
+	ApprovalRequestID pgtype.UUID                 `json:"approval_request_id"`
+	StepOrder         int32                       `json:"step_order"`
+	Name              string                      `json:"name"`
+	ApproverType      ApprovalApproverTypeEnum    `json:"approver_type"`
+	ApproverUserID    pgtype.UUID                 `json:"approver_user_id"`
+	ApproverRole      NullRoleEnum                `json:"approver_role"`
+	ApproverTeamRole  NullOpportunityTeamRoleEnum `json:"approver_team_role"`
+	SlaHours          pgtype.Int4                 `json:"sla_hours"`
+	EscalationUserID  pgtype.UUID                 `json:"escalation_user_id"`
+	Status            ApprovalStepStatusEnum      `json:"status"`
+	ActivatedAt       pgtype.Timestamptz          `json:"activated_at"`
+	SlaDueAt          pgtype.Timestamptz          `json:"sla_due_at"`
+	EscalatedAt       pgtype.Timestamptz          `json:"escalated_at"`
+	DecidedBy         pgtype.UUID                 `json:"decided_by"`
+	DecidedAt         pgtype.Timestamptz          `json:"decided_at"`
+	DecisionNote      pgtype.Text                 `json:"decision_note"`
+	CreatedAt         pgtype.Timestamptz          `json:"created_at"`
+}
+
+type ApprovalStepApprover struct {
+	StepID        pgtype.UUID        `json:"step_id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	Source        string             `json:"source"`
+	DelegatedFrom pgtype.UUID        `json:"delegated_from"`
+	AddedAt       pgtype.Timestamptz `json:"added_at"`
 }
 
 type AuditLog struct {
@@ -607,6 +1088,17 @@ type AuditLog struct {
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 }
 
+type Competitor struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Name      string             `json:"name"`
+	Website   pgtype.Text        `json:"website"`
+	Note      pgtype.Text        `json:"note"`
+	IsActive  bool               `json:"is_active"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Contact struct {
 	ID          pgtype.UUID        `json:"id"`
 	TenantID    pgtype.UUID        `json:"tenant_id"`
@@ -623,6 +1115,63 @@ type Contact struct {
 	CreatedBy   pgtype.UUID        `json:"created_by"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Version     int64              `json:"version"`
+}
+
+type DiscountPolicy struct {
+	ID             pgtype.UUID              `json:"id"`
+	TenantID       pgtype.UUID              `json:"tenant_id"`
+	Name           string                   `json:"name"`
+	Metric         DiscountPolicyMetricEnum `json:"metric"`
+	Threshold      pgtype.Numeric           `json:"threshold"`
+	ApproverUserID pgtype.UUID              `json:"approver_user_id"`
+	IsActive       bool                     `json:"is_active"`
+	CreatedBy      pgtype.UUID              `json:"created_by"`
+	CreatedAt      pgtype.Timestamptz       `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz       `json:"updated_at"`
+	Version        int64                    `json:"version"`
+	ChainID        pgtype.UUID              `json:"chain_id"`
+}
+
+type DocumentNumberFormat struct {
+	TenantID     pgtype.UUID        `json:"tenant_id"`
+	DocumentType string             `json:"document_type"`
+	Prefix       string             `json:"prefix"`
+	PeriodFormat string             `json:"period_format"`
+	Padding      int32              `json:"padding"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DocumentSequence struct {
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Prefix    string             `json:"prefix"`
+	Period    string             `json:"period"`
+	LastValue int32              `json:"last_value"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type FieldChange struct {
+	ID         int64              `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	EntityType string             `json:"entity_type"`
+	EntityID   pgtype.UUID        `json:"entity_id"`
+	FieldName  string             `json:"field_name"`
+	OldValue   []byte             `json:"old_value"`
+	NewValue   []byte             `json:"new_value"`
+	ChangedBy  pgtype.UUID        `json:"changed_by"`
+	Source     ChangeSourceEnum   `json:"source"`
+	ChangedAt  pgtype.Timestamptz `json:"changed_at"`
+}
+
+type FxRate struct {
+	ID           int64              `json:"id"`
+	TenantID     pgtype.UUID        `json:"tenant_id"`
+	Currency     string             `json:"currency"`
+	BaseCurrency string             `json:"base_currency"`
+	Rate         pgtype.Numeric     `json:"rate"`
+	EffectiveOn  pgtype.Date        `json:"effective_on"`
+	CreatedBy    pgtype.UUID        `json:"created_by"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
 type IntegrationConnection struct {
@@ -667,6 +1216,26 @@ type KpiSnapshot struct {
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
+type LineItem struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	OpportunityID   pgtype.UUID        `json:"opportunity_id"`
+	QuoteID         pgtype.UUID        `json:"quote_id"`
+	OrderID         pgtype.UUID        `json:"order_id"`
+	ProductID       pgtype.UUID        `json:"product_id"`
+	PriceBookID     pgtype.UUID        `json:"price_book_id"`
+	Description     pgtype.Text        `json:"description"`
+	Quantity        pgtype.Numeric     `json:"quantity"`
+	UnitPrice       pgtype.Numeric     `json:"unit_price"`
+	DiscountPercent pgtype.Numeric     `json:"discount_percent"`
+	SortOrder       int32              `json:"sort_order"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	TaxRate         pgtype.Numeric     `json:"tax_rate"`
+	Currency        string             `json:"currency"`
+	LineTotal       pgtype.Numeric     `json:"line_total"`
+}
+
 type Membership struct {
 	ID        pgtype.UUID        `json:"id"`
 	TenantID  pgtype.UUID        `json:"tenant_id"`
@@ -677,6 +1246,20 @@ type Membership struct {
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type Notification struct {
+	ID         pgtype.UUID        `json:"id"`
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	Kind       string             `json:"kind"`
+	EntityType string             `json:"entity_type"`
+	EntityID   pgtype.UUID        `json:"entity_id"`
+	Title      string             `json:"title"`
+	Body       string             `json:"body"`
+	Data       []byte             `json:"data"`
+	ReadAt     pgtype.Timestamptz `json:"read_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
 type Opportunity struct {
 	ID                pgtype.UUID          `json:"id"`
 	TenantID          pgtype.UUID          `json:"tenant_id"`
@@ -695,6 +1278,20 @@ type Opportunity struct {
 	UpdatedAt         pgtype.Timestamptz   `json:"updated_at"`
 	NextActionAt      pgtype.Timestamptz   `json:"next_action_at"`
 	NextActionNote    pgtype.Text          `json:"next_action_note"`
+	Currency          string               `json:"currency"`
+	Version           int64                `json:"version"`
+}
+
+type OpportunityCompetitor struct {
+	ID            pgtype.UUID        `json:"id"`
+	TenantID      pgtype.UUID        `json:"tenant_id"`
+	OpportunityID pgtype.UUID        `json:"opportunity_id"`
+	CompetitorID  pgtype.UUID        `json:"competitor_id"`
+	IsWinner      bool               `json:"is_winner"`
+	Note          pgtype.Text        `json:"note"`
+	CreatedBy     pgtype.UUID        `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
 }
 
 type OpportunityLoss struct {
@@ -706,35 +1303,166 @@ type OpportunityLoss struct {
 	LostAt        pgtype.Timestamptz `json:"lost_at"`
 	CreatedBy     pgtype.UUID        `json:"created_by"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Competitor    pgtype.Text        `json:"competitor"`
+}
+
+type OpportunityStageHistory struct {
+	ID            int64                    `json:"id"`
+	TenantID      pgtype.UUID              `json:"tenant_id"`
+	OpportunityID pgtype.UUID              `json:"opportunity_id"`
+	FromStage     NullOpportunityStageEnum `json:"from_stage"`
+	ToStage       OpportunityStageEnum     `json:"to_stage"`
+	Amount        pgtype.Numeric           `json:"amount"`
+	Probability   int16                    `json:"probability"`
+	ChangedBy     pgtype.UUID              `json:"changed_by"`
+	ChangedAt     pgtype.Timestamptz       `json:"changed_at"`
+}
+
+type OpportunityTeamMember struct {
+	ID            pgtype.UUID             `json:"id"`
+	TenantID      pgtype.UUID             `json:"tenant_id"`
+	OpportunityID pgtype.UUID             `json:"opportunity_id"`
+	UserID        pgtype.UUID             `json:"user_id"`
+	TeamRole      OpportunityTeamRoleEnum `json:"team_role"`
+	SplitPercent  pgtype.Numeric          `json:"split_percent"`
+	CreatedBy     pgtype.UUID             `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz      `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz      `json:"updated_at"`
 }
 
 type Order struct {
-	ID            pgtype.UUID        `json:"id"`
+	ID               pgtype.UUID          `json:"id"`
+	TenantID         pgtype.UUID          `json:"tenant_id"`
+	OpportunityID    pgtype.UUID          `json:"opportunity_id"`
+	OrderNo          string               `json:"order_no"`
+	Amount           pgtype.Numeric       `json:"amount"`
+	Status           OrderStatusEnum      `json:"status"`
+	OrderedOn        pgtype.Date          `json:"ordered_on"`
+	Note             pgtype.Text          `json:"note"`
+	CreatedBy        pgtype.UUID          `json:"created_by"`
+	CreatedAt        pgtype.Timestamptz   `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz   `json:"updated_at"`
+	Currency         string               `json:"currency"`
+	ConfirmedAt      pgtype.Timestamptz   `json:"confirmed_at"`
+	InvoicedAt       pgtype.Timestamptz   `json:"invoiced_at"`
+	CancelledAt      pgtype.Timestamptz   `json:"cancelled_at"`
+	CancelReason     pgtype.Text          `json:"cancel_reason"`
+	Version          int64                `json:"version"`
+	TermStart        pgtype.Date          `json:"term_start"`
+	TermEnd          pgtype.Date          `json:"term_end"`
+	BillingFrequency BillingFrequencyEnum `json:"billing_frequency"`
+}
+
+type OrderRevenueSchedule struct {
 	TenantID      pgtype.UUID        `json:"tenant_id"`
-	OpportunityID pgtype.UUID        `json:"opportunity_id"`
-	OrderNo       string             `json:"order_no"`
-	Amount        pgtype.Numeric     `json:"amount"`
-	Status        OrderStatusEnum    `json:"status"`
-	OrderedOn     pgtype.Date        `json:"ordered_on"`
-	Note          pgtype.Text        `json:"note"`
-	CreatedBy     pgtype.UUID        `json:"created_by"`
+	OrderID       pgtype.UUID        `json:"order_id"`
+	PeriodMonth   pgtype.Date        `json:"period_month"`
+	RevenueAmount pgtype.Numeric     `json:"revenue_amount"`
+	BillingAmount pgtype.Numeric     `json:"billing_amount"`
+	Currency      string             `json:"currency"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PriceBook struct {
+	ID        pgtype.UUID        `json:"id"`
+	TenantID  pgtype.UUID        `json:"tenant_id"`
+	Name      string             `json:"name"`
+	IsDefault bool               `json:"is_default"`
+	IsActive  bool               `json:"is_active"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Currency  string             `json:"currency"`
+}
+
+type PriceBookEntry struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	PriceBookID pgtype.UUID        `json:"price_book_id"`
+	ProductID   pgtype.UUID        `json:"product_id"`
+	UnitPrice   pgtype.Numeric     `json:"unit_price"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Product struct {
+	ID          pgtype.UUID        `json:"id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	Sku         string             `json:"sku"`
+	Name        string             `json:"name"`
+	Description pgtype.Text        `json:"description"`
+	Unit        pgtype.Text        `json:"unit"`
+	IsActive    bool               `json:"is_active"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	TaxRate     pgtype.Numeric     `json:"tax_rate"`
 }
 
 type Quote struct {
-	ID            pgtype.UUID        `json:"id"`
-	TenantID      pgtype.UUID        `json:"tenant_id"`
-	OpportunityID pgtype.UUID        `json:"opportunity_id"`
-	QuoteNo       string             `json:"quote_no"`
-	Amount        pgtype.Numeric     `json:"amount"`
-	Status        QuoteStatusEnum    `json:"status"`
-	IssuedOn      pgtype.Date        `json:"issued_on"`
-	ValidUntil    pgtype.Date        `json:"valid_until"`
-	Note          pgtype.Text        `json:"note"`
-	CreatedBy     pgtype.UUID        `json:"created_by"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	ID                pgtype.UUID        `json:"id"`
+	TenantID          pgtype.UUID        `json:"tenant_id"`
+	OpportunityID     pgtype.UUID        `json:"opportunity_id"`
+	QuoteNo           string             `json:"quote_no"`
+	Amount            pgtype.Numeric     `json:"amount"`
+	Status            QuoteStatusEnum    `json:"status"`
+	IssuedOn          pgtype.Date        `json:"issued_on"`
+	ValidUntil        pgtype.Date        `json:"valid_until"`
+	Note              pgtype.Text        `json:"note"`
+	CreatedBy         pgtype.UUID        `json:"created_by"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	Currency          string             `json:"currency"`
+	Version           int64              `json:"version"`
+	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
+	TotalAmount       pgtype.Numeric     `json:"total_amount"`
+	TaxBreakdown      []byte             `json:"tax_breakdown"`
+	RootQuoteID       pgtype.UUID        `json:"root_quote_id"`
+	PreviousQuoteID   pgtype.UUID        `json:"previous_quote_id"`
+	Revision          int32              `json:"revision"`
+	SupersededAt      pgtype.Timestamptz `json:"superseded_at"`
+	ExpiredAt         pgtype.Timestamptz `json:"expired_at"`
+	ApprovedForSendAt pgtype.Timestamptz `json:"approved_for_send_at"`
+}
+
+type QuoteDocument struct {
+	ID              pgtype.UUID        `json:"id"`
+	TenantID        pgtype.UUID        `json:"tenant_id"`
+	QuoteID         pgtype.UUID        `json:"quote_id"`
+	Version         int32              `json:"version"`
+	QuoteVersion    int64              `json:"quote_version"`
+	TemplateID      pgtype.UUID        `json:"template_id"`
+	TemplateVersion pgtype.Int8        `json:"template_version"`
+	FileName        string             `json:"file_name"`
+	Content         []byte             `json:"content"`
+	SizeBytes       int32              `json:"size_bytes"`
+	Sha256          string             `json:"sha256"`
+	CreatedBy       pgtype.UUID        `json:"created_by"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type QuoteExpiryReminder struct {
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	QuoteID        pgtype.UUID        `json:"quote_id"`
+	ValidUntil     pgtype.Date        `json:"valid_until"`
+	NotifiedUserID pgtype.UUID        `json:"notified_user_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type QuotePdfTemplate struct {
+	ID                 pgtype.UUID        `json:"id"`
+	TenantID           pgtype.UUID        `json:"tenant_id"`
+	Name               string             `json:"name"`
+	Language           string             `json:"language"`
+	CompanyName        string             `json:"company_name"`
+	CompanyAddress     pgtype.Text        `json:"company_address"`
+	CompanyPhone       pgtype.Text        `json:"company_phone"`
+	RegistrationNumber pgtype.Text        `json:"registration_number"`
+	AccentColor        string             `json:"accent_color"`
+	FooterText         pgtype.Text        `json:"footer_text"`
+	IsDefault          bool               `json:"is_default"`
+	Version            int64              `json:"version"`
+	CreatedBy          pgtype.UUID        `json:"created_by"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
 }
 
 type RefreshToken struct {
@@ -747,9 +1475,12 @@ type RefreshToken struct {
 }
 
 type Tenant struct {
-	ID        pgtype.UUID        `json:"id"`
-	Name      string             `json:"name"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID           pgtype.UUID        `json:"id"`
+	Name         string             `json:"name"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	BaseCurrency string             `json:"base_currency"`
+	TaxRounding  TaxRoundingEnum    `json:"tax_rounding"`
+	TimeZone     string             `json:"time_zone"`
 }
 
 type User struct {
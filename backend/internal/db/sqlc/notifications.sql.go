@@ -0,0 +1,211 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: notifications.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countUnreadNotifications = `-- name: CountUnreadNotifications :one
+SELECT count(*)::bigint
+FROM notifications
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND read_at IS NULL
+`
+
+type CountUnreadNotificationsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, arg CountUnreadNotificationsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnreadNotifications, arg.TenantID, arg.UserID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createNotification = `-- name: CreateNotification :one
+INSERT INTO notifications (
+  tenant_id,
+  user_id,
+  kind,
+  entity_type,
+  entity_id,
+  title,
+  body,
+  data
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  coalesce($8, '{}'::jsonb)
+)
+RETURNING id, tenant_id, user_id, kind, entity_type, entity_id, title, body, data, read_at, created_at
+`
+
+type CreateNotificationParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+	Kind       string      `json:"kind"`
+	EntityType string      `json:"entity_type"`
+	EntityID   pgtype.UUID `json:"entity_id"`
+	Title      string      `json:"title"`
+	Body       string      `json:"body"`
+	Data       interface{} `json:"data"`
+}
+
+func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
+	row := q.db.QueryRow(ctx, createNotification,
+		arg.TenantID,
+		arg.UserID,
+		arg.Kind,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Title,
+		arg.Body,
+		arg.Data,
+	)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.UserID,
+		&i.Kind,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Title,
+		&i.Body,
+		&i.Data,
+		&i.ReadAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listNotificationsByUser = `-- name: ListNotificationsByUser :many
+SELECT id, tenant_id, user_id, kind, entity_type, entity_id, title, body, data, read_at, created_at
+FROM notifications
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND (NOT $3::boolean OR read_at IS NULL)
+  AND (
+    $4::timestamptz IS NULL
+    OR (created_at, id) < ($4::timestamptz, $5::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListNotificationsByUserParams struct {
+	TenantID   pgtype.UUID        `json:"tenant_id"`
+	UserID     pgtype.UUID        `json:"user_id"`
+	UnreadOnly bool               `json:"unread_only"`
+	CursorAt   pgtype.Timestamptz `json:"cursor_at"`
+	CursorID   pgtype.UUID        `json:"cursor_id"`
+	LimitCount int32              `json:"limit_count"`
+}
+
+func (q *Queries) ListNotificationsByUser(ctx context.Context, arg ListNotificationsByUserParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, listNotificationsByUser,
+		arg.TenantID,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.UserID,
+			&i.Kind,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Title,
+			&i.Body,
+			&i.Data,
+			&i.ReadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllNotificationsRead = `-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = now()
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND read_at IS NULL
+`
+
+type MarkAllNotificationsReadParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markAllNotificationsRead, arg.TenantID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markNotificationRead = `-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = coalesce(read_at, now())
+WHERE tenant_id = $1
+  AND id = $2
+  AND user_id = $3
+RETURNING id, tenant_id, user_id, kind, entity_type, entity_id, title, body, data, read_at, created_at
+`
+
+type MarkNotificationReadParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	NotificationID pgtype.UUID `json:"notification_id"`
+	UserID         pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error) {
+	row := q.db.QueryRow(ctx, markNotificationRead, arg.TenantID, arg.NotificationID, arg.UserID)
+	var i Notification
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.UserID,
+		&i.Kind,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Title,
+		&i.Body,
+		&i.Data,
+		&i.ReadAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -11,13 +11,15 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const closeOpportunityAsLost = `-- name: CloseOpportunityAsLost :exec
+const closeOpportunityAsLost = `-- name: CloseOpportunityAsLost :one
 UPDATE opportunities
 SET stage = 'closed_lost',
+    probability = 0,
     closed_at = now(),
     updated_at = now()
 WHERE tenant_id = $1
   AND id = $2
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
 `
 
 type CloseOpportunityAsLostParams struct {
@@ -25,88 +27,113 @@ type CloseOpportunityAsLostParams struct {
 	OpportunityID pgtype.UUID `json:"opportunity_id"`
 }
 
-func (q *Queries) CloseOpportunityAsLost(ctx context.Context, arg CloseOpportunityAsLostParams) error {
-	_, err := q.db.Exec(ctx, closeOpportunityAsLost, arg.TenantID, arg.OpportunityID)
-	return err
+func (q *Queries) CloseOpportunityAsLost(ctx context.Context, arg CloseOpportunityAsLostParams) (Opportunity, error) {
+	row := q.db.QueryRow(ctx, closeOpportunityAsLost, arg.TenantID, arg.OpportunityID)
+	var i Opportunity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.ContactID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Stage,
+		&i.Probability,
+		&i.Amount,
+		&i.ExpectedCloseDate,
+		&i.ClosedAt,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextActionAt,
+		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
+	)
+	return i, err
+}
+
+const closeOpportunityAsWon = `-- name: CloseOpportunityAsWon :one
+UPDATE opportunities
+SET stage = 'closed_won',
+    probability = 100,
+    amount = $1,
+    closed_at = now(),
+    updated_at = now()
+WHERE tenant_id = $2
+  AND id = $3
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
+`
+
+type CloseOpportunityAsWonParams struct {
+	Amount        pgtype.Numeric `json:"amount"`
+	TenantID      pgtype.UUID    `json:"tenant_id"`
+	OpportunityID pgtype.UUID    `json:"opportunity_id"`
+}
+
+func (q *Queries) CloseOpportunityAsWon(ctx context.Context, arg CloseOpportunityAsWonParams) (Opportunity, error) {
+	row := q.db.QueryRow(ctx, closeOpportunityAsWon, arg.Amount, arg.TenantID, arg.OpportunityID)
+	var i Opportunity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.ContactID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Stage,
+		&i.Probability,
+		&i.Amount,
+		&i.ExpectedCloseDate,
+		&i.ClosedAt,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextActionAt,
+		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
+	)
+	return i, err
 }
 
 const countOpportunities = `-- name: CountOpportunities :one
 SELECT count(*)::bigint
 FROM opportunities
-WHERE tenant_id = $1
+WHERE opportunities.tenant_id = $1
   AND ($2::opportunity_stage_enum IS NULL OR stage = $2)
   AND ($3::uuid IS NULL OR owner_user_id = $3)
+  AND (
+    $4::uuid IS NULL
+    OR owner_user_id = $4
+    OR id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = $4
+    )
+  )
 `
 
 type CountOpportunitiesParams struct {
 	TenantID    pgtype.UUID              `json:"tenant_id"`
 	Stage       NullOpportunityStageEnum `json:"stage"`
 	OwnerUserID pgtype.UUID              `json:"owner_user_id"`
+	VisibleTo   pgtype.UUID              `json:"visible_to"`
 }
 
 func (q *Queries) CountOpportunities(ctx context.Context, arg CountOpportunitiesParams) (int64, error) {
-	row := q.db.QueryRow(ctx, countOpportunities, arg.TenantID, arg.Stage, arg.OwnerUserID)
+	row := q.db.QueryRow(ctx, countOpportunities,
+		arg.TenantID,
+		arg.Stage,
+		arg.OwnerUserID,
+		arg.VisibleTo,
+	)
 	var column_1 int64
 	err := row.Scan(&column_1)
 	return column_1, err
 }
 
-const createActivity = `-- name: CreateActivity :one
-INSERT INTO activities (
-  tenant_id,
-  opportunity_id,
-  activity_type,
-  subject,
-  detail,
-  activity_at,
-  created_by
-) VALUES (
-  $1,
-  $2,
-  $3,
-  $4,
-  $5,
-  $6,
-  $7
-)
-RETURNING id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at
-`
-
-type CreateActivityParams struct {
-	TenantID      pgtype.UUID        `json:"tenant_id"`
-	OpportunityID pgtype.UUID        `json:"opportunity_id"`
-	ActivityType  ActivityTypeEnum   `json:"activity_type"`
-	Subject       string             `json:"subject"`
-	Detail        pgtype.Text        `json:"detail"`
-	ActivityAt    pgtype.Timestamptz `json:"activity_at"`
-	CreatedBy     pgtype.UUID        `json:"created_by"`
-}
-
-func (q *Queries) CreateActivity(ctx context.Context, arg CreateActivityParams) (Activity, error) {
-	row := q.db.QueryRow(ctx, createActivity,
-		arg.TenantID,
-		arg.OpportunityID,
-		arg.ActivityType,
-		arg.Subject,
-		arg.Detail,
-		arg.ActivityAt,
-		arg.CreatedBy,
-	)
-	var i Activity
-	err := row.Scan(
-		&i.ID,
-		&i.TenantID,
-		&i.OpportunityID,
-		&i.ActivityType,
-		&i.Subject,
-		&i.Detail,
-		&i.ActivityAt,
-		&i.CreatedBy,
-		&i.CreatedAt,
-	)
-	return i, err
-}
-
 const createOpportunity = `-- name: CreateOpportunity :one
 INSERT INTO opportunities (
   tenant_id,
@@ -117,7 +144,9 @@ INSERT INTO opportunities (
   stage,
   probability,
   amount,
+  currency,
   expected_close_date,
+  closed_at,
   memo,
   created_by
 ) VALUES (
@@ -129,11 +158,16 @@ INSERT INTO opportunities (
   coalesce($6::opportunity_stage_enum, 'new_lead'),
   coalesce($7, 0),
   coalesce($8, 0),
-  $9,
+  coalesce($9, (SELECT t.base_currency FROM tenants t WHERE t.id = $1)),
   $10,
-  $11
+  CASE
+    WHEN $6::opportunity_stage_enum IN ('closed_won', 'closed_lost') THEN now()
+    ELSE NULL
+  END,
+  $11,
+  $12
 )
-RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
 `
 
 type CreateOpportunityParams struct {
@@ -145,6 +179,7 @@ type CreateOpportunityParams struct {
 	Stage             NullOpportunityStageEnum `json:"stage"`
 	Probability       interface{}              `json:"probability"`
 	Amount            interface{}              `json:"amount"`
+	Currency          interface{}              `json:"currency"`
 	ExpectedCloseDate pgtype.Date              `json:"expected_close_date"`
 	Memo              pgtype.Text              `json:"memo"`
 	CreatedBy         pgtype.UUID              `json:"created_by"`
@@ -160,6 +195,7 @@ func (q *Queries) CreateOpportunity(ctx context.Context, arg CreateOpportunityPa
 		arg.Stage,
 		arg.Probability,
 		arg.Amount,
+		arg.Currency,
 		arg.ExpectedCloseDate,
 		arg.Memo,
 		arg.CreatedBy,
@@ -183,6 +219,8 @@ func (q *Queries) CreateOpportunity(ctx context.Context, arg CreateOpportunityPa
 		&i.UpdatedAt,
 		&i.NextActionAt,
 		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
 	)
 	return i, err
 }
@@ -193,6 +231,7 @@ INSERT INTO opportunity_losses (
   opportunity_id,
   reason,
   detail,
+  competitor,
   lost_at,
   created_by
 )
@@ -201,10 +240,11 @@ VALUES (
   $2,
   $3,
   $4,
-  coalesce($5, now()),
-  $6
+  $5,
+  coalesce($6, now()),
+  $7
 )
-RETURNING id, tenant_id, opportunity_id, reason, detail, lost_at, created_by, created_at
+RETURNING id, tenant_id, opportunity_id, reason, detail, lost_at, created_by, created_at, competitor
 `
 
 type CreateOpportunityLossParams struct {
@@ -212,6 +252,7 @@ type CreateOpportunityLossParams struct {
 	OpportunityID pgtype.UUID    `json:"opportunity_id"`
 	Reason        LossReasonEnum `json:"reason"`
 	Detail        pgtype.Text    `json:"detail"`
+	Competitor    pgtype.Text    `json:"competitor"`
 	LostAt        interface{}    `json:"lost_at"`
 	CreatedBy     pgtype.UUID    `json:"created_by"`
 }
@@ -222,6 +263,7 @@ func (q *Queries) CreateOpportunityLoss(ctx context.Context, arg CreateOpportuni
 		arg.OpportunityID,
 		arg.Reason,
 		arg.Detail,
+		arg.Competitor,
 		arg.LostAt,
 		arg.CreatedBy,
 	)
@@ -235,6 +277,7 @@ func (q *Queries) CreateOpportunityLoss(ctx context.Context, arg CreateOpportuni
 		&i.LostAt,
 		&i.CreatedBy,
 		&i.CreatedAt,
+		&i.Competitor,
 	)
 	return i, err
 }
@@ -245,6 +288,7 @@ INSERT INTO orders (
   opportunity_id,
   order_no,
   amount,
+  currency,
   status,
   ordered_on,
   note,
@@ -254,12 +298,13 @@ INSERT INTO orders (
   $2,
   $3,
   $4,
-  coalesce($5::order_status_enum, 'pending'),
-  $6,
+  coalesce($5, (SELECT o.currency FROM opportunities o WHERE o.id = $2)),
+  coalesce($6::order_status_enum, 'pending'),
   $7,
-  $8
+  $8,
+  $9
 )
-RETURNING id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at
+RETURNING id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
 `
 
 type CreateOrderParams struct {
@@ -267,6 +312,7 @@ type CreateOrderParams struct {
 	OpportunityID pgtype.UUID         `json:"opportunity_id"`
 	OrderNo       string              `json:"order_no"`
 	Amount        pgtype.Numeric      `json:"amount"`
+	Currency      interface{}         `json:"currency"`
 	Status        NullOrderStatusEnum `json:"status"`
 	OrderedOn     pgtype.Date         `json:"ordered_on"`
 	Note          pgtype.Text         `json:"note"`
@@ -279,6 +325,7 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.OpportunityID,
 		arg.OrderNo,
 		arg.Amount,
+		arg.Currency,
 		arg.Status,
 		arg.OrderedOn,
 		arg.Note,
@@ -297,6 +344,15 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
 	)
 	return i, err
 }
@@ -307,6 +363,7 @@ INSERT INTO quotes (
   opportunity_id,
   quote_no,
   amount,
+  currency,
   status,
   issued_on,
   valid_until,
@@ -317,13 +374,14 @@ INSERT INTO quotes (
   $2,
   $3,
   $4,
-  coalesce($5::quote_status_enum, 'draft'),
-  $6,
+  coalesce($5, (SELECT o.currency FROM opportunities o WHERE o.id = $2)),
+  coalesce($6::quote_status_enum, 'draft'),
   $7,
   $8,
-  $9
+  $9,
+  $10
 )
-RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at
+RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
 `
 
 type CreateQuoteParams struct {
@@ -331,6 +389,7 @@ type CreateQuoteParams struct {
 	OpportunityID pgtype.UUID         `json:"opportunity_id"`
 	QuoteNo       string              `json:"quote_no"`
 	Amount        pgtype.Numeric      `json:"amount"`
+	Currency      interface{}         `json:"currency"`
 	Status        NullQuoteStatusEnum `json:"status"`
 	IssuedOn      pgtype.Date         `json:"issued_on"`
 	ValidUntil    pgtype.Date         `json:"valid_until"`
@@ -344,6 +403,7 @@ func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote
 		arg.OpportunityID,
 		arg.QuoteNo,
 		arg.Amount,
+		arg.Currency,
 		arg.Status,
 		arg.IssuedOn,
 		arg.ValidUntil,
@@ -364,51 +424,223 @@ func (q *Queries) CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
 	)
 	return i, err
 }
 
-const listActivitiesByOpportunity = `-- name: ListActivitiesByOpportunity :many
-SELECT id, tenant_id, opportunity_id, activity_type, subject, detail, activity_at, created_by, created_at
-FROM activities
+const deleteOpportunityLoss = `-- name: DeleteOpportunityLoss :one
+DELETE FROM opportunity_losses
 WHERE tenant_id = $1
   AND opportunity_id = $2
-ORDER BY activity_at DESC
-LIMIT $4
-OFFSET $3
+RETURNING id, tenant_id, opportunity_id, reason, detail, lost_at, created_by, created_at, competitor
 `
 
-type ListActivitiesByOpportunityParams struct {
+type DeleteOpportunityLossParams struct {
 	TenantID      pgtype.UUID `json:"tenant_id"`
 	OpportunityID pgtype.UUID `json:"opportunity_id"`
-	OffsetCount   int32       `json:"offset_count"`
-	LimitCount    int32       `json:"limit_count"`
 }
 
-func (q *Queries) ListActivitiesByOpportunity(ctx context.Context, arg ListActivitiesByOpportunityParams) ([]Activity, error) {
-	rows, err := q.db.Query(ctx, listActivitiesByOpportunity,
-		arg.TenantID,
-		arg.OpportunityID,
-		arg.OffsetCount,
-		arg.LimitCount,
+func (q *Queries) DeleteOpportunityLoss(ctx context.Context, arg DeleteOpportunityLossParams) (OpportunityLoss, error) {
+	row := q.db.QueryRow(ctx, deleteOpportunityLoss, arg.TenantID, arg.OpportunityID)
+	var i OpportunityLoss
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.Reason,
+		&i.Detail,
+		&i.LostAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.Competitor,
 	)
+	return i, err
+}
+
+const getOpportunity = `-- name: GetOpportunity :one
+SELECT id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
+FROM opportunities
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetOpportunityParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) GetOpportunity(ctx context.Context, arg GetOpportunityParams) (Opportunity, error) {
+	row := q.db.QueryRow(ctx, getOpportunity, arg.TenantID, arg.OpportunityID)
+	var i Opportunity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.ContactID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Stage,
+		&i.Probability,
+		&i.Amount,
+		&i.ExpectedCloseDate,
+		&i.ClosedAt,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextActionAt,
+		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getOpportunityForUpdate = `-- name: GetOpportunityForUpdate :one
+SELECT id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
+FROM opportunities
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetOpportunityForUpdateParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) GetOpportunityForUpdate(ctx context.Context, arg GetOpportunityForUpdateParams) (Opportunity, error) {
+	row := q.db.QueryRow(ctx, getOpportunityForUpdate, arg.TenantID, arg.OpportunityID)
+	var i Opportunity
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.AccountID,
+		&i.ContactID,
+		&i.OwnerUserID,
+		&i.Name,
+		&i.Stage,
+		&i.Probability,
+		&i.Amount,
+		&i.ExpectedCloseDate,
+		&i.ClosedAt,
+		&i.Memo,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextActionAt,
+		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getQuoteForUpdate = `-- name: GetQuoteForUpdate :one
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+FROM quotes
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND id = $3
+FOR UPDATE
+`
+
+type GetQuoteForUpdateParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	QuoteID       pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) GetQuoteForUpdate(ctx context.Context, arg GetQuoteForUpdateParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, getQuoteForUpdate, arg.TenantID, arg.OpportunityID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const listAcceptedQuotesForUpdate = `-- name: ListAcceptedQuotesForUpdate :many
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+FROM quotes
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+  AND status = 'accepted'
+ORDER BY updated_at DESC
+FOR UPDATE
+`
+
+type ListAcceptedQuotesForUpdateParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) ListAcceptedQuotesForUpdate(ctx context.Context, arg ListAcceptedQuotesForUpdateParams) ([]Quote, error) {
+	rows, err := q.db.Query(ctx, listAcceptedQuotesForUpdate, arg.TenantID, arg.OpportunityID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Activity{}
+	items := []Quote{}
 	for rows.Next() {
-		var i Activity
+		var i Quote
 		if err := rows.Scan(
 			&i.ID,
 			&i.TenantID,
 			&i.OpportunityID,
-			&i.ActivityType,
-			&i.Subject,
-			&i.Detail,
-			&i.ActivityAt,
+			&i.QuoteNo,
+			&i.Amount,
+			&i.Status,
+			&i.IssuedOn,
+			&i.ValidUntil,
+			&i.Note,
 			&i.CreatedBy,
 			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.Version,
+			&i.TaxAmount,
+			&i.TotalAmount,
+			&i.TaxBreakdown,
+			&i.RootQuoteID,
+			&i.PreviousQuoteID,
+			&i.Revision,
+			&i.SupersededAt,
+			&i.ExpiredAt,
+			&i.ApprovedForSendAt,
 		); err != nil {
 			return nil, err
 		}
@@ -421,20 +653,29 @@ func (q *Queries) ListActivitiesByOpportunity(ctx context.Context, arg ListActiv
 }
 
 const listOpportunities = `-- name: ListOpportunities :many
-SELECT id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note
+SELECT id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
 FROM opportunities
-WHERE tenant_id = $1
+WHERE opportunities.tenant_id = $1
   AND ($2::opportunity_stage_enum IS NULL OR stage = $2)
   AND ($3::uuid IS NULL OR owner_user_id = $3)
+  AND (
+    $4::uuid IS NULL
+    OR owner_user_id = $4
+    OR id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = $4
+    )
+  )
 ORDER BY updated_at DESC
-LIMIT $5
-OFFSET $4
+LIMIT $6
+OFFSET $5
 `
 
 type ListOpportunitiesParams struct {
 	TenantID    pgtype.UUID              `json:"tenant_id"`
 	Stage       NullOpportunityStageEnum `json:"stage"`
 	OwnerUserID pgtype.UUID              `json:"owner_user_id"`
+	VisibleTo   pgtype.UUID              `json:"visible_to"`
 	OffsetCount int32                    `json:"offset_count"`
 	LimitCount  int32                    `json:"limit_count"`
 }
@@ -444,6 +685,7 @@ func (q *Queries) ListOpportunities(ctx context.Context, arg ListOpportunitiesPa
 		arg.TenantID,
 		arg.Stage,
 		arg.OwnerUserID,
+		arg.VisibleTo,
 		arg.OffsetCount,
 		arg.LimitCount,
 	)
@@ -472,6 +714,8 @@ func (q *Queries) ListOpportunities(ctx context.Context, arg ListOpportunitiesPa
 			&i.UpdatedAt,
 			&i.NextActionAt,
 			&i.NextActionNote,
+			&i.Currency,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -484,7 +728,7 @@ func (q *Queries) ListOpportunities(ctx context.Context, arg ListOpportunitiesPa
 }
 
 const listOrdersByOpportunity = `-- name: ListOrdersByOpportunity :many
-SELECT id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at
+SELECT id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
 FROM orders
 WHERE tenant_id = $1
   AND opportunity_id = $2
@@ -517,6 +761,15 @@ func (q *Queries) ListOrdersByOpportunity(ctx context.Context, arg ListOrdersByO
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
+			&i.ConfirmedAt,
+			&i.InvoicedAt,
+			&i.CancelledAt,
+			&i.CancelReason,
+			&i.Version,
+			&i.TermStart,
+			&i.TermEnd,
+			&i.BillingFrequency,
 		); err != nil {
 			return nil, err
 		}
@@ -529,20 +782,22 @@ func (q *Queries) ListOrdersByOpportunity(ctx context.Context, arg ListOrdersByO
 }
 
 const listQuotesByOpportunity = `-- name: ListQuotesByOpportunity :many
-SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
 FROM quotes
 WHERE tenant_id = $1
   AND opportunity_id = $2
+  AND (NOT $3::boolean OR status <> 'superseded')
 ORDER BY created_at DESC
 `
 
 type ListQuotesByOpportunityParams struct {
 	TenantID      pgtype.UUID `json:"tenant_id"`
 	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	LatestOnly    bool        `json:"latest_only"`
 }
 
 func (q *Queries) ListQuotesByOpportunity(ctx context.Context, arg ListQuotesByOpportunityParams) ([]Quote, error) {
-	rows, err := q.db.Query(ctx, listQuotesByOpportunity, arg.TenantID, arg.OpportunityID)
+	rows, err := q.db.Query(ctx, listQuotesByOpportunity, arg.TenantID, arg.OpportunityID, arg.LatestOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -563,6 +818,17 @@ func (q *Queries) ListQuotesByOpportunity(ctx context.Context, arg ListQuotesByO
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Currency,
+			&i.Version,
+			&i.TaxAmount,
+			&i.TotalAmount,
+			&i.TaxBreakdown,
+			&i.RootQuoteID,
+			&i.PreviousQuoteID,
+			&i.Revision,
+			&i.SupersededAt,
+			&i.ExpiredAt,
+			&i.ApprovedForSendAt,
 		); err != nil {
 			return nil, err
 		}
@@ -574,6 +840,73 @@ func (q *Queries) ListQuotesByOpportunity(ctx context.Context, arg ListQuotesByO
 	return items, nil
 }
 
+const rejectOpenQuotes = `-- name: RejectOpenQuotes :many
+UPDATE quotes q
+SET status = 'rejected',
+    updated_at = now()
+FROM (
+  SELECT o.id, o.status AS previous_status
+  FROM quotes o
+  WHERE o.tenant_id = $1
+    AND o.opportunity_id = $2
+    AND ($3::uuid IS NULL OR o.id <> $3)
+    AND o.status IN ('draft', 'sent', 'accepted')
+  FOR UPDATE
+) prev
+WHERE q.id = prev.id
+RETURNING q.id, q.quote_no, prev.previous_status
+`
+
+type RejectOpenQuotesParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	KeepQuoteID   pgtype.UUID `json:"keep_quote_id"`
+}
+
+type RejectOpenQuotesRow struct {
+	ID             pgtype.UUID     `json:"id"`
+	QuoteNo        string          `json:"quote_no"`
+	PreviousStatus QuoteStatusEnum `json:"previous_status"`
+}
+
+func (q *Queries) RejectOpenQuotes(ctx context.Context, arg RejectOpenQuotesParams) ([]RejectOpenQuotesRow, error) {
+	rows, err := q.db.Query(ctx, rejectOpenQuotes, arg.TenantID, arg.OpportunityID, arg.KeepQuoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RejectOpenQuotesRow{}
+	for rows.Next() {
+		var i RejectOpenQuotesRow
+		if err := rows.Scan(&i.ID, &i.QuoteNo, &i.PreviousStatus); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setOpportunityLossCompetitor = `-- name: SetOpportunityLossCompetitor :exec
+UPDATE opportunity_losses
+SET competitor = $1
+WHERE tenant_id = $2
+  AND opportunity_id = $3
+`
+
+type SetOpportunityLossCompetitorParams struct {
+	Competitor    pgtype.Text `json:"competitor"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) SetOpportunityLossCompetitor(ctx context.Context, arg SetOpportunityLossCompetitorParams) error {
+	_, err := q.db.Exec(ctx, setOpportunityLossCompetitor, arg.Competitor, arg.TenantID, arg.OpportunityID)
+	return err
+}
+
 const updateOpportunity = `-- name: UpdateOpportunity :one
 UPDATE opportunities
 SET
@@ -583,25 +916,33 @@ SET
   stage = coalesce($4::opportunity_stage_enum, stage),
   probability = coalesce($5, probability),
   amount = coalesce($6, amount),
-  expected_close_date = coalesce($7, expected_close_date),
-  memo = coalesce($8, memo),
+  currency = coalesce($7, currency),
+  expected_close_date = CASE WHEN $8::boolean THEN $9 ELSE expected_close_date END,
+  memo = coalesce($10, memo),
+  closed_at = CASE
+    WHEN $4::opportunity_stage_enum IS NULL THEN closed_at
+    WHEN $4::opportunity_stage_enum IN ('closed_won', 'closed_lost') THEN coalesce(closed_at, now())
+    ELSE NULL
+  END,
   updated_at = now()
-WHERE tenant_id = $9
-  AND id = $10
-RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note
+WHERE tenant_id = $11
+  AND id = $12
+RETURNING id, tenant_id, account_id, contact_id, owner_user_id, name, stage, probability, amount, expected_close_date, closed_at, memo, created_by, created_at, updated_at, next_action_at, next_action_note, currency, version
 `
 
 type UpdateOpportunityParams struct {
-	ContactID         pgtype.UUID              `json:"contact_id"`
-	OwnerUserID       pgtype.UUID              `json:"owner_user_id"`
-	Name              pgtype.Text              `json:"name"`
-	Stage             NullOpportunityStageEnum `json:"stage"`
-	Probability       pgtype.Int2              `json:"probability"`
-	Amount            pgtype.Numeric           `json:"amount"`
-	ExpectedCloseDate pgtype.Date              `json:"expected_close_date"`
-	Memo              pgtype.Text              `json:"memo"`
-	TenantID          pgtype.UUID              `json:"tenant_id"`
-	OpportunityID     pgtype.UUID              `json:"opportunity_id"`
+	ContactID            pgtype.UUID              `json:"contact_id"`
+	OwnerUserID          pgtype.UUID              `json:"owner_user_id"`
+	Name                 pgtype.Text              `json:"name"`
+	Stage                NullOpportunityStageEnum `json:"stage"`
+	Probability          pgtype.Int2              `json:"probability"`
+	Amount               pgtype.Numeric           `json:"amount"`
+	Currency             pgtype.Text              `json:"currency"`
+	SetExpectedCloseDate bool                     `json:"set_expected_close_date"`
+	ExpectedCloseDate    pgtype.Date              `json:"expected_close_date"`
+	Memo                 pgtype.Text              `json:"memo"`
+	TenantID             pgtype.UUID              `json:"tenant_id"`
+	OpportunityID        pgtype.UUID              `json:"opportunity_id"`
 }
 
 func (q *Queries) UpdateOpportunity(ctx context.Context, arg UpdateOpportunityParams) (Opportunity, error) {
@@ -612,6 +953,8 @@ func (q *Queries) UpdateOpportunity(ctx context.Context, arg UpdateOpportunityPa
 		arg.Stage,
 		arg.Probability,
 		arg.Amount,
+		arg.Currency,
+		arg.SetExpectedCloseDate,
 		arg.ExpectedCloseDate,
 		arg.Memo,
 		arg.TenantID,
@@ -636,6 +979,27 @@ func (q *Queries) UpdateOpportunity(ctx context.Context, arg UpdateOpportunityPa
 		&i.UpdatedAt,
 		&i.NextActionAt,
 		&i.NextActionNote,
+		&i.Currency,
+		&i.Version,
 	)
 	return i, err
 }
+
+const updateQuoteStatus = `-- name: UpdateQuoteStatus :exec
+UPDATE quotes
+SET status = $1,
+    updated_at = now()
+WHERE tenant_id = $2
+  AND id = $3
+`
+
+type UpdateQuoteStatusParams struct {
+	Status   QuoteStatusEnum `json:"status"`
+	TenantID pgtype.UUID     `json:"tenant_id"`
+	QuoteID  pgtype.UUID     `json:"quote_id"`
+}
+
+func (q *Queries) UpdateQuoteStatus(ctx context.Context, arg UpdateQuoteStatusParams) error {
+	_, err := q.db.Exec(ctx, updateQuoteStatus, arg.Status, arg.TenantID, arg.QuoteID)
+	return err
+}
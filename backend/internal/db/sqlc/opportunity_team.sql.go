@@ -0,0 +1,262 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: opportunity_team.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createOpportunityTeamMember = `-- name: CreateOpportunityTeamMember :exec
+INSERT INTO opportunity_team_members (
+  tenant_id,
+  opportunity_id,
+  user_id,
+  team_role,
+  split_percent,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+`
+
+type CreateOpportunityTeamMemberParams struct {
+	TenantID      pgtype.UUID             `json:"tenant_id"`
+	OpportunityID pgtype.UUID             `json:"opportunity_id"`
+	UserID        pgtype.UUID             `json:"user_id"`
+	TeamRole      OpportunityTeamRoleEnum `json:"team_role"`
+	SplitPercent  pgtype.Numeric          `json:"split_percent"`
+	CreatedBy     pgtype.UUID             `json:"created_by"`
+}
+
+func (q *Queries) CreateOpportunityTeamMember(ctx context.Context, arg CreateOpportunityTeamMemberParams) error {
+	_, err := q.db.Exec(ctx, createOpportunityTeamMember,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.UserID,
+		arg.TeamRole,
+		arg.SplitPercent,
+		arg.CreatedBy,
+	)
+	return err
+}
+
+const deleteOpportunityTeamMembers = `-- name: DeleteOpportunityTeamMembers :exec
+DELETE FROM opportunity_team_members
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+`
+
+type DeleteOpportunityTeamMembersParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) DeleteOpportunityTeamMembers(ctx context.Context, arg DeleteOpportunityTeamMembersParams) error {
+	_, err := q.db.Exec(ctx, deleteOpportunityTeamMembers, arg.TenantID, arg.OpportunityID)
+	return err
+}
+
+const getSplitForecastSummary = `-- name: GetSplitForecastSummary :many
+
+WITH credits AS (
+  SELECT o.id AS opportunity_id, o.owner_user_id AS user_id, 1::numeric AS share
+  FROM opportunities o
+  WHERE o.tenant_id = $1
+    AND NOT EXISTS (
+      SELECT 1 FROM opportunity_team_members t
+      WHERE t.opportunity_id = o.id AND t.split_percent > 0
+    )
+  UNION ALL
+  SELECT t.opportunity_id, t.user_id, t.split_percent / 100.0
+  FROM opportunity_team_members t
+  WHERE t.tenant_id = $1
+    AND t.split_percent > 0
+), credited AS (
+  SELECT
+    c.user_id,
+    c.share,
+    o.currency,
+    o.amount,
+    o.probability,
+    o.stage = 'closed_won' AS is_won,
+    to_char(date_trunc('month', CASE
+      WHEN o.stage = 'closed_won' THEN coalesce(o.closed_at, o.updated_at)
+      ELSE coalesce(o.expected_close_date::timestamptz, now())
+    END), 'YYYY-MM') AS month_bucket,
+    CASE
+      WHEN o.stage = 'closed_won' THEN fx_rate(o.tenant_id, o.currency, coalesce(o.closed_at, o.updated_at)::date)
+      ELSE fx_rate(o.tenant_id, o.currency, current_date)
+    END AS rate
+  FROM credits c
+  JOIN opportunities o ON o.id = c.opportunity_id
+  WHERE o.stage <> 'closed_lost'
+)
+SELECT
+  user_id,
+  month_bucket,
+  currency,
+  count(*) FILTER (WHERE NOT is_won)::bigint AS deal_count,
+  coalesce(sum(amount * share) FILTER (WHERE NOT is_won), 0)::double precision AS pipeline_amount,
+  coalesce(sum(amount * share * (probability::numeric / 100.0)) FILTER (WHERE NOT is_won), 0)::double precision AS weighted_amount,
+  count(*) FILTER (WHERE is_won)::bigint AS won_count,
+  coalesce(sum(amount * share) FILTER (WHERE is_won), 0)::double precision AS won_amount,
+  coalesce(sum(amount * share * rate) FILTER (WHERE NOT is_won AND rate IS NOT NULL), 0)::double precision AS pipeline_amount_base,
+  coalesce(sum(amount * share * (probability::numeric / 100.0) * rate) FILTER (WHERE NOT is_won AND rate IS NOT NULL), 0)::double precision AS weighted_amount_base,
+  coalesce(sum(amount * share * rate) FILTER (WHERE is_won AND rate IS NOT NULL), 0)::double precision AS won_amount_base,
+  count(*) FILTER (WHERE rate IS NULL)::bigint AS missing_rate_count
+FROM credited
+GROUP BY user_id, month_bucket, currency
+ORDER BY month_bucket ASC, user_id ASC, currency ASC
+`
+
+type GetSplitForecastSummaryRow struct {
+	UserID             pgtype.UUID `json:"user_id"`
+	MonthBucket        string      `json:"month_bucket"`
+	Currency           string      `json:"currency"`
+	DealCount          int64       `json:"deal_count"`
+	PipelineAmount     float64     `json:"pipeline_amount"`
+	WeightedAmount     float64     `json:"weighted_amount"`
+	WonCount           int64       `json:"won_count"`
+	WonAmount          float64     `json:"won_amount"`
+	PipelineAmountBase float64     `json:"pipeline_amount_base"`
+	WeightedAmountBase float64     `json:"weighted_amount_base"`
+	WonAmountBase      float64     `json:"won_amount_base"`
+	MissingRateCount   int64       `json:"missing_rate_count"`
+}
+
+// Revenue credit per user: deals with a team split their amount by split_percent,
+// the rest credit the owner in full. Open deals bucket by expected close month and
+// won deals by close month; amounts convert like GetForecastSummary.
+func (q *Queries) GetSplitForecastSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetSplitForecastSummaryRow, error) {
+	rows, err := q.db.Query(ctx, getSplitForecastSummary, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSplitForecastSummaryRow{}
+	for rows.Next() {
+		var i GetSplitForecastSummaryRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.MonthBucket,
+			&i.Currency,
+			&i.DealCount,
+			&i.PipelineAmount,
+			&i.WeightedAmount,
+			&i.WonCount,
+			&i.WonAmount,
+			&i.PipelineAmountBase,
+			&i.WeightedAmountBase,
+			&i.WonAmountBase,
+			&i.MissingRateCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isOpportunityTeamMember = `-- name: IsOpportunityTeamMember :one
+SELECT EXISTS (
+  SELECT 1
+  FROM opportunity_team_members
+  WHERE tenant_id = $1
+    AND opportunity_id = $2
+    AND user_id = $3
+)::boolean
+`
+
+type IsOpportunityTeamMemberParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+	UserID        pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) IsOpportunityTeamMember(ctx context.Context, arg IsOpportunityTeamMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isOpportunityTeamMember, arg.TenantID, arg.OpportunityID, arg.UserID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listOpportunityTeamMembers = `-- name: ListOpportunityTeamMembers :many
+SELECT
+  m.id,
+  m.opportunity_id,
+  m.user_id,
+  u.display_name,
+  u.email,
+  m.team_role,
+  m.split_percent,
+  m.created_by,
+  m.created_at,
+  m.updated_at
+FROM opportunity_team_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.tenant_id = $1
+  AND m.opportunity_id = $2
+ORDER BY m.split_percent DESC, m.created_at ASC
+`
+
+type ListOpportunityTeamMembersParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+type ListOpportunityTeamMembersRow struct {
+	ID            pgtype.UUID             `json:"id"`
+	OpportunityID pgtype.UUID             `json:"opportunity_id"`
+	UserID        pgtype.UUID             `json:"user_id"`
+	DisplayName   string                  `json:"display_name"`
+	Email         string                  `json:"email"`
+	TeamRole      OpportunityTeamRoleEnum `json:"team_role"`
+	SplitPercent  pgtype.Numeric          `json:"split_percent"`
+	CreatedBy     pgtype.UUID             `json:"created_by"`
+	CreatedAt     pgtype.Timestamptz      `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz      `json:"updated_at"`
+}
+
+func (q *Queries) ListOpportunityTeamMembers(ctx context.Context, arg ListOpportunityTeamMembersParams) ([]ListOpportunityTeamMembersRow, error) {
+	rows, err := q.db.Query(ctx, listOpportunityTeamMembers, arg.TenantID, arg.OpportunityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpportunityTeamMembersRow{}
+	for rows.Next() {
+		var i ListOpportunityTeamMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OpportunityID,
+			&i.UserID,
+			&i.DisplayName,
+			&i.Email,
+			&i.TeamRole,
+			&i.SplitPercent,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
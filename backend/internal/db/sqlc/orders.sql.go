@@ -0,0 +1,491 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: orders.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const buildOrderRevenueSchedule = `-- name: BuildOrderRevenueSchedule :execrows
+WITH od AS (
+  SELECT
+    o.id,
+    o.tenant_id,
+    o.amount,
+    o.currency,
+    o.billing_frequency,
+    coalesce(o.term_start, o.ordered_on, o.created_at::date) AS term_start,
+    coalesce(o.term_end, o.ordered_on, o.created_at::date) AS term_end,
+    currency_minor_units(o.currency) AS minor_units
+  FROM orders o
+  WHERE o.tenant_id = $1
+    AND o.id = $2
+),
+months AS (
+  SELECT
+    m.period_start::date AS period_month,
+    least(od.term_end, (m.period_start + interval '1 month' - interval '1 day')::date)
+      - greatest(od.term_start, m.period_start::date) + 1 AS day_count
+  FROM od
+  CROSS JOIN generate_series(date_trunc('month', od.term_start::timestamp), date_trunc('month', od.term_end::timestamp), interval '1 month') AS m(period_start)
+),
+cumulative_days AS (
+  SELECT
+    mo.period_month,
+    sum(mo.day_count) OVER (ORDER BY mo.period_month) AS days_to_date,
+    sum(mo.day_count) OVER (ORDER BY mo.period_month) - mo.day_count AS days_before
+  FROM months mo
+),
+revenue AS (
+  SELECT
+    cd.period_month,
+    round(od.amount * cd.days_to_date / (od.term_end - od.term_start + 1), od.minor_units)
+      - round(od.amount * cd.days_before / (od.term_end - od.term_start + 1), od.minor_units) AS revenue_amount
+  FROM cumulative_days cd
+  CROSS JOIN od
+),
+billing_periods AS (
+  SELECT
+    date_trunc('month', b.period_start)::date AS period_month,
+    row_number() OVER (ORDER BY b.period_start) AS period_no,
+    count(*) OVER () AS period_count
+  FROM od
+  CROSS JOIN generate_series(
+    od.term_start::timestamp,
+    od.term_end::timestamp,
+    CASE od.billing_frequency
+      WHEN 'monthly' THEN interval '1 month'
+      WHEN 'quarterly' THEN interval '3 months'
+      WHEN 'semi_annual' THEN interval '6 months'
+      WHEN 'annual' THEN interval '12 months'
+      ELSE (od.term_end - od.term_start + 1) * interval '1 day'
+    END
+  ) AS b(period_start)
+),
+billing AS (
+  SELECT
+    bp.period_month,
+    sum(
+      round(od.amount * bp.period_no / bp.period_count, od.minor_units)
+        - round(od.amount * (bp.period_no - 1) / bp.period_count, od.minor_units)
+    ) AS billing_amount
+  FROM billing_periods bp
+  CROSS JOIN od
+  GROUP BY bp.period_month
+)
+INSERT INTO order_revenue_schedules (tenant_id, order_id, period_month, revenue_amount, billing_amount, currency)
+SELECT
+  od.tenant_id AS tenant_id,
+  od.id AS order_id,
+  rv.period_month AS period_month,
+  rv.revenue_amount AS revenue_amount,
+  coalesce(bl.billing_amount, 0) AS billing_amount,
+  od.currency AS currency
+FROM revenue rv
+CROSS JOIN od
+LEFT JOIN billing bl ON bl.period_month = rv.period_month
+`
+
+type BuildOrderRevenueScheduleParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+// BuildOrderRevenueSchedule writes the monthly schedule of an order whose old rows were
+// deleted. Each month gets round(amount * cumulative share) minus the previous month's
+// cumulative value, so the months always add up to the order amount.
+func (q *Queries) BuildOrderRevenueSchedule(ctx context.Context, arg BuildOrderRevenueScheduleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, buildOrderRevenueSchedule, arg.TenantID, arg.OrderID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteOrderRevenueSchedule = `-- name: DeleteOrderRevenueSchedule :exec
+DELETE FROM order_revenue_schedules
+WHERE tenant_id = $1
+  AND order_id = $2
+`
+
+type DeleteOrderRevenueScheduleParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) DeleteOrderRevenueSchedule(ctx context.Context, arg DeleteOrderRevenueScheduleParams) error {
+	_, err := q.db.Exec(ctx, deleteOrderRevenueSchedule, arg.TenantID, arg.OrderID)
+	return err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
+FROM orders
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetOrderForUpdateParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) GetOrderForUpdate(ctx context.Context, arg GetOrderForUpdateParams) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrderForUpdate, arg.TenantID, arg.OrderID)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.OrderNo,
+		&i.Amount,
+		&i.Status,
+		&i.OrderedOn,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
+	)
+	return i, err
+}
+
+const listOrderRevenueSchedule = `-- name: ListOrderRevenueSchedule :many
+SELECT tenant_id, order_id, period_month, revenue_amount, billing_amount, currency, created_at
+FROM order_revenue_schedules
+WHERE tenant_id = $1
+  AND order_id = $2
+ORDER BY period_month
+`
+
+type ListOrderRevenueScheduleParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	OrderID  pgtype.UUID `json:"order_id"`
+}
+
+func (q *Queries) ListOrderRevenueSchedule(ctx context.Context, arg ListOrderRevenueScheduleParams) ([]OrderRevenueSchedule, error) {
+	rows, err := q.db.Query(ctx, listOrderRevenueSchedule, arg.TenantID, arg.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OrderRevenueSchedule{}
+	for rows.Next() {
+		var i OrderRevenueSchedule
+		if err := rows.Scan(
+			&i.TenantID,
+			&i.OrderID,
+			&i.PeriodMonth,
+			&i.RevenueAmount,
+			&i.BillingAmount,
+			&i.Currency,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrders = `-- name: ListOrders :many
+SELECT
+  od.id, od.tenant_id, od.opportunity_id, od.order_no, od.amount, od.status, od.ordered_on, od.note, od.created_by, od.created_at, od.updated_at, od.currency, od.confirmed_at, od.invoiced_at, od.cancelled_at, od.cancel_reason, od.version, od.term_start, od.term_end, od.billing_frequency,
+  o.name AS opportunity_name,
+  o.owner_user_id,
+  o.account_id,
+  a.name AS account_name
+FROM orders od
+JOIN opportunities o ON o.id = od.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+WHERE od.tenant_id = $1
+  AND ($2::text[] IS NULL OR od.status::text = ANY($2::text[]))
+  AND ($3::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) >= $3::date)
+  AND ($4::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) <= $4::date)
+  AND ($5::uuid IS NULL OR o.account_id = $5)
+  AND ($6::uuid IS NULL OR o.owner_user_id = $6)
+  AND (
+    $7::uuid IS NULL
+    OR o.owner_user_id = $7
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = $7
+    )
+  )
+ORDER BY coalesce(od.ordered_on, od.created_at::date) DESC, od.created_at DESC, od.id
+LIMIT $9
+OFFSET $8
+`
+
+type ListOrdersParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	Statuses    []string    `json:"statuses"`
+	OrderedFrom pgtype.Date `json:"ordered_from"`
+	OrderedTo   pgtype.Date `json:"ordered_to"`
+	AccountID   pgtype.UUID `json:"account_id"`
+	OwnerUserID pgtype.UUID `json:"owner_user_id"`
+	VisibleTo   pgtype.UUID `json:"visible_to"`
+	OffsetCount int32       `json:"offset_count"`
+	LimitCount  int32       `json:"limit_count"`
+}
+
+type ListOrdersRow struct {
+	ID               pgtype.UUID          `json:"id"`
+	TenantID         pgtype.UUID          `json:"tenant_id"`
+	OpportunityID    pgtype.UUID          `json:"opportunity_id"`
+	OrderNo          string               `json:"order_no"`
+	Amount           pgtype.Numeric       `json:"amount"`
+	Status           OrderStatusEnum      `json:"status"`
+	OrderedOn        pgtype.Date          `json:"ordered_on"`
+	Note             pgtype.Text          `json:"note"`
+	CreatedBy        pgtype.UUID          `json:"created_by"`
+	CreatedAt        pgtype.Timestamptz   `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz   `json:"updated_at"`
+	Currency         string               `json:"currency"`
+	ConfirmedAt      pgtype.Timestamptz   `json:"confirmed_at"`
+	InvoicedAt       pgtype.Timestamptz   `json:"invoiced_at"`
+	CancelledAt      pgtype.Timestamptz   `json:"cancelled_at"`
+	CancelReason     pgtype.Text          `json:"cancel_reason"`
+	Version          int64                `json:"version"`
+	TermStart        pgtype.Date          `json:"term_start"`
+	TermEnd          pgtype.Date          `json:"term_end"`
+	BillingFrequency BillingFrequencyEnum `json:"billing_frequency"`
+	OpportunityName  string               `json:"opportunity_name"`
+	OwnerUserID      pgtype.UUID          `json:"owner_user_id"`
+	AccountID        pgtype.UUID          `json:"account_id"`
+	AccountName      string               `json:"account_name"`
+}
+
+// Back-office listing across the tenant. ordered_from/ordered_to are inclusive dates;
+// orders without ordered_on fall back to the day they were created.
+func (q *Queries) ListOrders(ctx context.Context, arg ListOrdersParams) ([]ListOrdersRow, error) {
+	rows, err := q.db.Query(ctx, listOrders,
+		arg.TenantID,
+		arg.Statuses,
+		arg.OrderedFrom,
+		arg.OrderedTo,
+		arg.AccountID,
+		arg.OwnerUserID,
+		arg.VisibleTo,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrdersRow{}
+	for rows.Next() {
+		var i ListOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.OrderNo,
+			&i.Amount,
+			&i.Status,
+			&i.OrderedOn,
+			&i.Note,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.ConfirmedAt,
+			&i.InvoicedAt,
+			&i.CancelledAt,
+			&i.CancelReason,
+			&i.Version,
+			&i.TermStart,
+			&i.TermEnd,
+			&i.BillingFrequency,
+			&i.OpportunityName,
+			&i.OwnerUserID,
+			&i.AccountID,
+			&i.AccountName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setOrderSubscription = `-- name: SetOrderSubscription :one
+UPDATE orders
+SET term_start = $1,
+    term_end = $2,
+    billing_frequency = $3,
+    updated_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
+`
+
+type SetOrderSubscriptionParams struct {
+	TermStart        pgtype.Date          `json:"term_start"`
+	TermEnd          pgtype.Date          `json:"term_end"`
+	BillingFrequency BillingFrequencyEnum `json:"billing_frequency"`
+	TenantID         pgtype.UUID          `json:"tenant_id"`
+	OrderID          pgtype.UUID          `json:"order_id"`
+}
+
+func (q *Queries) SetOrderSubscription(ctx context.Context, arg SetOrderSubscriptionParams) (Order, error) {
+	row := q.db.QueryRow(ctx, setOrderSubscription,
+		arg.TermStart,
+		arg.TermEnd,
+		arg.BillingFrequency,
+		arg.TenantID,
+		arg.OrderID,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.OrderNo,
+		&i.Amount,
+		&i.Status,
+		&i.OrderedOn,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
+	)
+	return i, err
+}
+
+const summarizeOrders = `-- name: SummarizeOrders :one
+SELECT
+  count(*)::bigint AS total,
+  coalesce(sum(od.amount), 0)::numeric AS total_amount,
+  count(DISTINCT od.currency)::int AS currency_count
+FROM orders od
+JOIN opportunities o ON o.id = od.opportunity_id
+WHERE od.tenant_id = $1
+  AND ($2::text[] IS NULL OR od.status::text = ANY($2::text[]))
+  AND ($3::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) >= $3::date)
+  AND ($4::date IS NULL OR coalesce(od.ordered_on, od.created_at::date) <= $4::date)
+  AND ($5::uuid IS NULL OR o.account_id = $5)
+  AND ($6::uuid IS NULL OR o.owner_user_id = $6)
+  AND (
+    $7::uuid IS NULL
+    OR o.owner_user_id = $7
+    OR o.id IN (
+      SELECT t.opportunity_id FROM opportunity_team_members t
+      WHERE t.user_id = $7
+    )
+  )
+`
+
+type SummarizeOrdersParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	Statuses    []string    `json:"statuses"`
+	OrderedFrom pgtype.Date `json:"ordered_from"`
+	OrderedTo   pgtype.Date `json:"ordered_to"`
+	AccountID   pgtype.UUID `json:"account_id"`
+	OwnerUserID pgtype.UUID `json:"owner_user_id"`
+	VisibleTo   pgtype.UUID `json:"visible_to"`
+}
+
+type SummarizeOrdersRow struct {
+	Total         int64          `json:"total"`
+	TotalAmount   pgtype.Numeric `json:"total_amount"`
+	CurrencyCount int32          `json:"currency_count"`
+}
+
+func (q *Queries) SummarizeOrders(ctx context.Context, arg SummarizeOrdersParams) (SummarizeOrdersRow, error) {
+	row := q.db.QueryRow(ctx, summarizeOrders,
+		arg.TenantID,
+		arg.Statuses,
+		arg.OrderedFrom,
+		arg.OrderedTo,
+		arg.AccountID,
+		arg.OwnerUserID,
+		arg.VisibleTo,
+	)
+	var i SummarizeOrdersRow
+	err := row.Scan(&i.Total, &i.TotalAmount, &i.CurrencyCount)
+	return i, err
+}
+
+const transitionOrderStatus = `-- name: TransitionOrderStatus :one
+UPDATE orders
+SET status = $1,
+    confirmed_at = CASE WHEN $1 = 'confirmed'::order_status_enum THEN now() ELSE confirmed_at END,
+    invoiced_at = CASE WHEN $1 = 'invoiced'::order_status_enum THEN now() ELSE invoiced_at END,
+    cancelled_at = CASE WHEN $1 = 'cancelled'::order_status_enum THEN now() ELSE cancelled_at END,
+    cancel_reason = CASE WHEN $1 = 'cancelled'::order_status_enum THEN $2::text ELSE cancel_reason END,
+    updated_at = now()
+WHERE tenant_id = $3
+  AND id = $4
+RETURNING id, tenant_id, opportunity_id, order_no, amount, status, ordered_on, note, created_by, created_at, updated_at, currency, confirmed_at, invoiced_at, cancelled_at, cancel_reason, version, term_start, term_end, billing_frequency
+`
+
+type TransitionOrderStatusParams struct {
+	Status       OrderStatusEnum `json:"status"`
+	CancelReason pgtype.Text     `json:"cancel_reason"`
+	TenantID     pgtype.UUID     `json:"tenant_id"`
+	OrderID      pgtype.UUID     `json:"order_id"`
+}
+
+func (q *Queries) TransitionOrderStatus(ctx context.Context, arg TransitionOrderStatusParams) (Order, error) {
+	row := q.db.QueryRow(ctx, transitionOrderStatus,
+		arg.Status,
+		arg.CancelReason,
+		arg.TenantID,
+		arg.OrderID,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.OrderNo,
+		&i.Amount,
+		&i.Status,
+		&i.OrderedOn,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.ConfirmedAt,
+		&i.InvoicedAt,
+		&i.CancelledAt,
+		&i.CancelReason,
+		&i.Version,
+		&i.TermStart,
+		&i.TermEnd,
+		&i.BillingFrequency,
+	)
+	return i, err
+}
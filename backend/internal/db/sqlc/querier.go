@@ -11,52 +11,312 @@ import (
 )
 
 type Querier interface {
-	CloseOpportunityAsLost(ctx context.Context, arg CloseOpportunityAsLostParams) error
+	ActivateApprovalRequestStep(ctx context.Context, arg ActivateApprovalRequestStepParams) (ApprovalRequestStep, error)
+	AddApprovalStepApprover(ctx context.Context, arg AddApprovalStepApproverParams) (int64, error)
+	AdvanceApprovalRequest(ctx context.Context, arg AdvanceApprovalRequestParams) (ApprovalRequest, error)
+	// BuildOrderRevenueSchedule writes the monthly schedule of an order whose old rows were
+	// deleted. Each month gets round(amount * cumulative share) minus the previous month's
+	// cumulative value, so the months always add up to the order amount.
+	BuildOrderRevenueSchedule(ctx context.Context, arg BuildOrderRevenueScheduleParams) (int64, error)
+	CancelOpenApprovalRequestSteps(ctx context.Context, arg CancelOpenApprovalRequestStepsParams) error
+	// Returns no row when another run already claimed the reminder.
+	ClaimQuoteExpiryReminder(ctx context.Context, arg ClaimQuoteExpiryReminderParams) (QuoteExpiryReminder, error)
+	// Run before flagging another billing location so the partial unique index holds.
+	ClearBillingLocation(ctx context.Context, arg ClearBillingLocationParams) error
+	ClearDefaultPriceBook(ctx context.Context, tenantID pgtype.UUID) error
+	// Run before making another template the default so the partial unique index holds.
+	ClearDefaultQuotePDFTemplate(ctx context.Context, arg ClearDefaultQuotePDFTemplateParams) error
+	ClearOpportunityCompetitorWinner(ctx context.Context, arg ClearOpportunityCompetitorWinnerParams) error
+	CloseOpportunityAsLost(ctx context.Context, arg CloseOpportunityAsLostParams) (Opportunity, error)
+	CloseOpportunityAsWon(ctx context.Context, arg CloseOpportunityAsWonParams) (Opportunity, error)
+	CopyOpportunityLineItemsToQuote(ctx context.Context, arg CopyOpportunityLineItemsToQuoteParams) (int64, error)
+	CopyQuoteLineItems(ctx context.Context, arg CopyQuoteLineItemsParams) (int64, error)
+	CopyQuoteLineItemsToQuote(ctx context.Context, arg CopyQuoteLineItemsToQuoteParams) (int64, error)
 	CountAccounts(ctx context.Context, arg CountAccountsParams) (int64, error)
+	CountActivitiesByOpportunity(ctx context.Context, arg CountActivitiesByOpportunityParams) (int64, error)
+	// CountLiveQuoteLineItems counts the lines on the opportunity's draft, sent and accepted
+	// quotes; while there are any, the deal amount follows the quote.
+	CountLiveQuoteLineItems(ctx context.Context, arg CountLiveQuoteLineItemsParams) (int64, error)
+	CountOpenTasksByAssignee(ctx context.Context, arg CountOpenTasksByAssigneeParams) (CountOpenTasksByAssigneeRow, error)
 	CountOpportunities(ctx context.Context, arg CountOpportunitiesParams) (int64, error)
+	CountOpportunityLineItems(ctx context.Context, arg CountOpportunityLineItemsParams) (int64, error)
+	CountOverlappingDelegations(ctx context.Context, arg CountOverlappingDelegationsParams) (int64, error)
+	CountProducts(ctx context.Context, arg CountProductsParams) (int64, error)
+	CountQuoteLineItems(ctx context.Context, arg CountQuoteLineItemsParams) (int64, error)
 	CountTenantUsers(ctx context.Context, arg CountTenantUsersParams) (int64, error)
+	CountUnreadNotifications(ctx context.Context, arg CountUnreadNotificationsParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateActivity(ctx context.Context, arg CreateActivityParams) (Activity, error)
+	CreateActivityTemplate(ctx context.Context, arg CreateActivityTemplateParams) (ActivityTemplate, error)
+	CreateActivityTemplateStep(ctx context.Context, arg CreateActivityTemplateStepParams) error
+	CreateApprovalChain(ctx context.Context, arg CreateApprovalChainParams) (ApprovalChain, error)
+	CreateApprovalChainStep(ctx context.Context, arg CreateApprovalChainStepParams) (ApprovalChainStep, error)
+	CreateApprovalDelegation(ctx context.Context, arg CreateApprovalDelegationParams) (ApprovalDelegation, error)
 	CreateApprovalRequest(ctx context.Context, arg CreateApprovalRequestParams) (ApprovalRequest, error)
+	CreateApprovalRequestEvent(ctx context.Context, arg CreateApprovalRequestEventParams) error
+	CreateApprovalRequestStep(ctx context.Context, arg CreateApprovalRequestStepParams) (ApprovalRequestStep, error)
 	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateChainApprovalRequest(ctx context.Context, arg CreateChainApprovalRequestParams) (ApprovalRequest, error)
+	CreateCompetitor(ctx context.Context, arg CreateCompetitorParams) (Competitor, error)
 	CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error)
+	CreateDiscountPolicy(ctx context.Context, arg CreateDiscountPolicyParams) (DiscountPolicy, error)
 	CreateIntegrationEvent(ctx context.Context, arg CreateIntegrationEventParams) (IntegrationEvent, error)
+	// Recomputes the headline KPIs in the tenant's base currency and stores them as a new
+	// snapshot. Amounts without an FX rate are left out of the sums and counted in the
+	// *_missing_rate_count metrics stored next to them.
+	CreateKpiSnapshot(ctx context.Context, tenantID pgtype.UUID) error
+	CreateLineItem(ctx context.Context, arg CreateLineItemParams) (LineItem, error)
 	CreateLocation(ctx context.Context, arg CreateLocationParams) (AccountLocation, error)
 	CreateMembership(ctx context.Context, arg CreateMembershipParams) (Membership, error)
+	// Returns no row when the occurrence already exists (task completed, reopened and
+	// completed again).
+	CreateNextOccurrence(ctx context.Context, arg CreateNextOccurrenceParams) (Activity, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
 	CreateOpportunity(ctx context.Context, arg CreateOpportunityParams) (Opportunity, error)
 	CreateOpportunityLoss(ctx context.Context, arg CreateOpportunityLossParams) (OpportunityLoss, error)
+	CreateOpportunityStageHistory(ctx context.Context, arg CreateOpportunityStageHistoryParams) error
+	CreateOpportunityTeamMember(ctx context.Context, arg CreateOpportunityTeamMemberParams) error
 	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreatePolicyApprovalRequest(ctx context.Context, arg CreatePolicyApprovalRequestParams) (ApprovalRequest, error)
+	CreatePriceBook(ctx context.Context, arg CreatePriceBookParams) (PriceBook, error)
+	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
 	CreateQuote(ctx context.Context, arg CreateQuoteParams) (Quote, error)
+	// The caller holds the quote row lock, so max(version) + 1 cannot race.
+	CreateQuoteDocument(ctx context.Context, arg CreateQuoteDocumentParams) (QuoteDocument, error)
+	CreateQuotePDFTemplate(ctx context.Context, arg CreateQuotePDFTemplateParams) (QuotePdfTemplate, error)
+	// A new revision starts as a draft with the source's header, totals and currency;
+	// issued_on and valid_until are stamped again when it is sent.
+	CreateQuoteRevision(ctx context.Context, arg CreateQuoteRevisionParams) (Quote, error)
 	DecideApprovalRequest(ctx context.Context, arg DecideApprovalRequestParams) (ApprovalRequest, error)
+	DecideApprovalRequestStep(ctx context.Context, arg DecideApprovalRequestStepParams) (ApprovalRequestStep, error)
+	DeleteActivity(ctx context.Context, arg DeleteActivityParams) (int64, error)
+	DeleteActivityTemplateSteps(ctx context.Context, arg DeleteActivityTemplateStepsParams) error
+	DeleteApprovalChainSteps(ctx context.Context, arg DeleteApprovalChainStepsParams) error
+	DeleteApprovalDelegation(ctx context.Context, arg DeleteApprovalDelegationParams) (int64, error)
+	DeleteFxRate(ctx context.Context, arg DeleteFxRateParams) (FxRate, error)
+	DeleteLineItems(ctx context.Context, arg DeleteLineItemsParams) error
+	DeleteOpportunityCompetitor(ctx context.Context, arg DeleteOpportunityCompetitorParams) (int64, error)
+	DeleteOpportunityLoss(ctx context.Context, arg DeleteOpportunityLossParams) (OpportunityLoss, error)
+	DeleteOpportunityTeamMembers(ctx context.Context, arg DeleteOpportunityTeamMembersParams) error
+	DeleteOrderRevenueSchedule(ctx context.Context, arg DeleteOrderRevenueScheduleParams) error
+	DeletePriceBookEntry(ctx context.Context, arg DeletePriceBookEntryParams) (int64, error)
+	// Rows locked by a request or by another replica are skipped and picked up on the next run.
+	ExpireDueQuotes(ctx context.Context, arg ExpireDueQuotesParams) ([]ExpireDueQuotesRow, error)
 	ExportAccountsRows(ctx context.Context, tenantID pgtype.UUID) ([]ExportAccountsRowsRow, error)
 	ExportOpportunitiesRows(ctx context.Context, tenantID pgtype.UUID) ([]ExportOpportunitiesRowsRow, error)
+	ExportProductsRows(ctx context.Context, tenantID pgtype.UUID) ([]ExportProductsRowsRow, error)
+	FinishApprovalRequest(ctx context.Context, arg FinishApprovalRequestParams) (ApprovalRequest, error)
+	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	GetAccountForUpdate(ctx context.Context, arg GetAccountForUpdateParams) (Account, error)
+	GetActiveDelegation(ctx context.Context, arg GetActiveDelegationParams) (ApprovalDelegation, error)
+	GetActiveMembershipRole(ctx context.Context, arg GetActiveMembershipRoleParams) (RoleEnum, error)
+	GetActivityForUpdate(ctx context.Context, arg GetActivityForUpdateParams) (Activity, error)
+	GetActivityTemplate(ctx context.Context, arg GetActivityTemplateParams) (ActivityTemplate, error)
+	GetApprovalChain(ctx context.Context, arg GetApprovalChainParams) (ApprovalChain, error)
+	GetApprovalChainForUpdate(ctx context.Context, arg GetApprovalChainForUpdateParams) (ApprovalChain, error)
+	// The opportunity an approval subject belongs to; NULL for subjects outside the deal.
+	GetApprovalEntityOpportunity(ctx context.Context, arg GetApprovalEntityOpportunityParams) (pgtype.UUID, error)
+	GetApprovalRequest(ctx context.Context, arg GetApprovalRequestParams) (ApprovalRequest, error)
+	GetApprovalRequestForUpdate(ctx context.Context, arg GetApprovalRequestForUpdateParams) (ApprovalRequest, error)
+	// Closed deals with a competitor on them, dated by lost_at for losses and closed_at
+	// for wins. Lost amounts only count deals lost to that competitor and convert at the
+	// rate on the close date.
+	GetCompetitiveSummary(ctx context.Context, arg GetCompetitiveSummaryParams) ([]GetCompetitiveSummaryRow, error)
+	GetCompetitiveTrend(ctx context.Context, arg GetCompetitiveTrendParams) ([]GetCompetitiveTrendRow, error)
+	GetCompetitor(ctx context.Context, arg GetCompetitorParams) (Competitor, error)
+	GetContact(ctx context.Context, arg GetContactParams) (Contact, error)
+	GetContactForUpdate(ctx context.Context, arg GetContactForUpdateParams) (Contact, error)
+	GetDefaultPriceBook(ctx context.Context, tenantID pgtype.UUID) (PriceBook, error)
+	GetDefaultQuotePDFTemplate(ctx context.Context, tenantID pgtype.UUID) (QuotePdfTemplate, error)
+	GetDiscountPolicy(ctx context.Context, arg GetDiscountPolicyParams) (DiscountPolicy, error)
+	GetDocumentNumberFormat(ctx context.Context, arg GetDocumentNumberFormatParams) (DocumentNumberFormat, error)
+	// Open deals convert at today's rate; amounts without a rate are left out of the
+	// *_base sums and counted in missing_rate_count.
 	GetForecastSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetForecastSummaryRow, error)
+	GetFxRate(ctx context.Context, arg GetFxRateParams) (pgtype.Numeric, error)
 	GetLatestKpiSnapshot(ctx context.Context, tenantID pgtype.UUID) ([]GetLatestKpiSnapshotRow, error)
+	GetLatestQuoteDocument(ctx context.Context, arg GetLatestQuoteDocumentParams) (QuoteDocument, error)
+	// Lost deals convert at the rate on the loss date, with the same missing-rate handling.
 	GetLossReasonAnalysis(ctx context.Context, tenantID pgtype.UUID) ([]GetLossReasonAnalysisRow, error)
+	GetMaxQuoteRevision(ctx context.Context, arg GetMaxQuoteRevisionParams) (int32, error)
+	GetOpportunity(ctx context.Context, arg GetOpportunityParams) (Opportunity, error)
+	GetOpportunityForUpdate(ctx context.Context, arg GetOpportunityForUpdateParams) (Opportunity, error)
+	GetOrder(ctx context.Context, arg GetOrderParams) (Order, error)
+	GetOrderForUpdate(ctx context.Context, arg GetOrderForUpdateParams) (Order, error)
+	GetOwnerVelocityStats(ctx context.Context, arg GetOwnerVelocityStatsParams) ([]GetOwnerVelocityStatsRow, error)
+	GetPendingApprovalRequestStepForUpdate(ctx context.Context, arg GetPendingApprovalRequestStepForUpdateParams) (ApprovalRequestStep, error)
+	// Closed deals convert at the rate on their close date, open deals at today's rate.
+	// Deals without a rate are left out of total_amount_base and counted in missing_rate_count.
 	GetPipelineSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetPipelineSummaryRow, error)
+	// Same rates as GetPipelineSummary; lines without one are left out of total_amount.
+	GetPipelineSummaryByProduct(ctx context.Context, tenantID pgtype.UUID) ([]GetPipelineSummaryByProductRow, error)
+	GetPriceBook(ctx context.Context, arg GetPriceBookParams) (PriceBook, error)
+	GetPriceBookEntryPrice(ctx context.Context, arg GetPriceBookEntryPriceParams) (pgtype.Numeric, error)
+	GetProduct(ctx context.Context, arg GetProductParams) (Product, error)
+	// Open lines convert at today's rate and won lines at the close date's rate. Lines
+	// without a rate are left out of the amounts; their deals count in missing_rate_count.
+	GetProductRevenueSummary(ctx context.Context, arg GetProductRevenueSummaryParams) ([]GetProductRevenueSummaryRow, error)
+	GetQuote(ctx context.Context, arg GetQuoteParams) (Quote, error)
+	GetQuoteByIDForUpdate(ctx context.Context, arg GetQuoteByIDForUpdateParams) (Quote, error)
+	// Inputs for the discount policies. base_amount is NULL when the quote currency has no
+	// exchange rate to the tenant's base currency.
+	GetQuoteDiscountMetrics(ctx context.Context, arg GetQuoteDiscountMetricsParams) (GetQuoteDiscountMetricsRow, error)
+	GetQuoteDocument(ctx context.Context, arg GetQuoteDocumentParams) (QuoteDocument, error)
+	GetQuoteForUpdate(ctx context.Context, arg GetQuoteForUpdateParams) (Quote, error)
+	// Everything a quote PDF prints besides the quote, its line items and the template.
+	// The billing location falls back to the oldest location when none is flagged.
+	GetQuotePDFContext(ctx context.Context, arg GetQuotePDFContextParams) (GetQuotePDFContextRow, error)
+	GetQuotePDFTemplate(ctx context.Context, arg GetQuotePDFTemplateParams) (QuotePdfTemplate, error)
+	// Months up to and including as_of_month count as recognized, later months as scheduled.
+	// Amounts convert to the base currency at the rate on the order date; cancelled orders
+	// are left out, and so are months without a rate, which count in missing_rate_count.
+	GetRevenueSchedule(ctx context.Context, arg GetRevenueScheduleParams) ([]GetRevenueScheduleRow, error)
+	// Revenue credit per user: deals with a team split their amount by split_percent,
+	// the rest credit the owner in full. Open deals bucket by expected close month and
+	// won deals by close month; amounts convert like GetForecastSummary.
+	GetSplitForecastSummary(ctx context.Context, tenantID pgtype.UUID) ([]GetSplitForecastSummaryRow, error)
+	GetStageConversionStats(ctx context.Context, arg GetStageConversionStatsParams) ([]GetStageConversionStatsRow, error)
+	GetStageDurationStats(ctx context.Context, arg GetStageDurationStatsParams) ([]GetStageDurationStatsRow, error)
+	GetTenantBaseCurrency(ctx context.Context, tenantID pgtype.UUID) (string, error)
+	GetTenantTaxRounding(ctx context.Context, tenantID pgtype.UUID) (TaxRoundingEnum, error)
+	GetTenantTimeZone(ctx context.Context, tenantID pgtype.UUID) (string, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	InsertRefreshToken(ctx context.Context, arg InsertRefreshTokenParams) (RefreshToken, error)
+	// Open and granted requests become 'invalidated'; rejections keep their status but no
+	// longer block the edited quote.
+	InvalidateQuoteApprovals(ctx context.Context, arg InvalidateQuoteApprovalsParams) ([]ApprovalRequest, error)
+	IsOpportunityTeamMember(ctx context.Context, arg IsOpportunityTeamMemberParams) (bool, error)
+	ListAcceptedQuotesForUpdate(ctx context.Context, arg ListAcceptedQuotesForUpdateParams) ([]Quote, error)
+	// ListAccountTimeline merges the account's feed newest first. visible_to narrows the
+	// opportunity-bound items to deals the user owns or is on the team of (sales users).
+	// Quote and order entries are their status changes, read from audit_logs; those audit
+	// rows are not repeated as audit_log items.
+	ListAccountTimeline(ctx context.Context, arg ListAccountTimelineParams) ([]ListAccountTimelineRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveMembersByRole(ctx context.Context, arg ListActiveMembersByRoleParams) ([]pgtype.UUID, error)
 	ListActivitiesByOpportunity(ctx context.Context, arg ListActivitiesByOpportunityParams) ([]Activity, error)
+	ListActivityTemplateSteps(ctx context.Context, arg ListActivityTemplateStepsParams) ([]ActivityTemplateStep, error)
+	ListActivityTemplates(ctx context.Context, arg ListActivityTemplatesParams) ([]ListActivityTemplatesRow, error)
+	ListApprovalChainSteps(ctx context.Context, arg ListApprovalChainStepsParams) ([]ApprovalChainStep, error)
+	ListApprovalChains(ctx context.Context, arg ListApprovalChainsParams) ([]ApprovalChain, error)
+	ListApprovalDelegations(ctx context.Context, arg ListApprovalDelegationsParams) ([]ApprovalDelegation, error)
+	// Summaries of approval subjects, matched pairwise from the two arrays. Subjects that
+	// do not exist in the tenant, or whose type is not registered, are left out.
+	ListApprovalEntitySummaries(ctx context.Context, arg ListApprovalEntitySummariesParams) ([]ListApprovalEntitySummariesRow, error)
+	ListApprovalRequestEvents(ctx context.Context, arg ListApprovalRequestEventsParams) ([]ListApprovalRequestEventsRow, error)
+	ListApprovalRequestSteps(ctx context.Context, arg ListApprovalRequestStepsParams) ([]ApprovalRequestStep, error)
 	ListApprovalRequests(ctx context.Context, arg ListApprovalRequestsParams) ([]ApprovalRequest, error)
-	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListApprovalStepApprovers(ctx context.Context, arg ListApprovalStepApproversParams) ([]ListApprovalStepApproversRow, error)
+	// Newest first, keyset-paged on (created_at, id). from is inclusive, until exclusive.
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]ListAuditLogsRow, error)
+	ListCompetitors(ctx context.Context, arg ListCompetitorsParams) ([]Competitor, error)
 	ListContactsByAccount(ctx context.Context, arg ListContactsByAccountParams) ([]Contact, error)
+	// Approvals raised by policies for the quote's current content, newest first. Withdrawn
+	// requests no longer cover it, so the next send attempt raises a fresh one.
+	ListCurrentQuotePolicyApprovals(ctx context.Context, arg ListCurrentQuotePolicyApprovalsParams) ([]ApprovalRequest, error)
+	// Only completed activities count as engagement; open or overdue tasks do not.
 	ListDealHealth(ctx context.Context, arg ListDealHealthParams) ([]ListDealHealthRow, error)
+	ListDiscountPolicies(ctx context.Context, arg ListDiscountPoliciesParams) ([]DiscountPolicy, error)
+	ListDocumentNumberFormats(ctx context.Context, tenantID pgtype.UUID) ([]DocumentNumberFormat, error)
 	ListDuplicateCandidates(ctx context.Context, tenantID pgtype.UUID) ([]ListDuplicateCandidatesRow, error)
+	ListFieldChanges(ctx context.Context, arg ListFieldChangesParams) ([]ListFieldChangesRow, error)
+	ListFxRates(ctx context.Context, arg ListFxRatesParams) ([]FxRate, error)
 	ListIntegrationConnections(ctx context.Context, tenantID pgtype.UUID) ([]IntegrationConnection, error)
 	ListIntegrationEvents(ctx context.Context, arg ListIntegrationEventsParams) ([]IntegrationEvent, error)
+	// Exactly one of opportunity_id, quote_id and order_id is set per call; IS NOT DISTINCT
+	// FROM keeps the other two parents NULL so a query never mixes line item sets.
+	ListLineItems(ctx context.Context, arg ListLineItemsParams) ([]ListLineItemsRow, error)
 	ListLocationsByAccount(ctx context.Context, arg ListLocationsByAccountParams) ([]AccountLocation, error)
 	ListNextActions(ctx context.Context, arg ListNextActionsParams) ([]ListNextActionsRow, error)
+	ListNotificationsByUser(ctx context.Context, arg ListNotificationsByUserParams) ([]Notification, error)
+	ListOpenTasksByAssignee(ctx context.Context, arg ListOpenTasksByAssigneeParams) ([]ListOpenTasksByAssigneeRow, error)
 	ListOpportunities(ctx context.Context, arg ListOpportunitiesParams) ([]Opportunity, error)
+	ListOpportunityCompetitors(ctx context.Context, arg ListOpportunityCompetitorsParams) ([]ListOpportunityCompetitorsRow, error)
+	ListOpportunityStageHistory(ctx context.Context, arg ListOpportunityStageHistoryParams) ([]OpportunityStageHistory, error)
+	ListOpportunityTeamMembers(ctx context.Context, arg ListOpportunityTeamMembersParams) ([]ListOpportunityTeamMembersRow, error)
+	ListOpportunityTeamMembersByRole(ctx context.Context, arg ListOpportunityTeamMembersByRoleParams) ([]pgtype.UUID, error)
+	ListOrderRevenueSchedule(ctx context.Context, arg ListOrderRevenueScheduleParams) ([]OrderRevenueSchedule, error)
+	// Back-office listing across the tenant. ordered_from/ordered_to are inclusive dates;
+	// orders without ordered_on fall back to the day they were created.
+	ListOrders(ctx context.Context, arg ListOrdersParams) ([]ListOrdersRow, error)
 	ListOrdersByOpportunity(ctx context.Context, arg ListOrdersByOpportunityParams) ([]Order, error)
+	// Pending steps past their SLA that have not been escalated yet. Steps locked by a
+	// decision in progress are skipped until the next run.
+	ListOverdueApprovalSteps(ctx context.Context, arg ListOverdueApprovalStepsParams) ([]ListOverdueApprovalStepsRow, error)
+	// Events that still need UpsertActivityFromEvent: linked, keyed, and either without an
+	// activity or with a past meeting still open. Keyset-paged by event id.
+	ListPendingEventActivities(ctx context.Context, arg ListPendingEventActivitiesParams) ([]int64, error)
+	ListPriceBookEntries(ctx context.Context, arg ListPriceBookEntriesParams) ([]ListPriceBookEntriesRow, error)
+	ListPriceBooks(ctx context.Context, tenantID pgtype.UUID) ([]PriceBook, error)
+	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
+	ListQuoteDocuments(ctx context.Context, arg ListQuoteDocumentsParams) ([]ListQuoteDocumentsRow, error)
+	ListQuotePDFTemplates(ctx context.Context, tenantID pgtype.UUID) ([]QuotePdfTemplate, error)
+	ListQuoteRevisions(ctx context.Context, arg ListQuoteRevisionsParams) ([]Quote, error)
 	ListQuotesByOpportunity(ctx context.Context, arg ListQuotesByOpportunityParams) ([]Quote, error)
+	// Locks the opportunity rather than the quote: request handlers lock the opportunity
+	// first, so the job never waits on a quote while holding something they need.
+	ListQuotesDueForExpiryReminder(ctx context.Context, arg ListQuotesDueForExpiryReminderParams) ([]ListQuotesDueForExpiryReminderRow, error)
+	ListTenantIDs(ctx context.Context) ([]pgtype.UUID, error)
 	ListTenantUsers(ctx context.Context, arg ListTenantUsersParams) ([]ListTenantUsersRow, error)
+	MarkAllNotificationsRead(ctx context.Context, arg MarkAllNotificationsReadParams) (int64, error)
+	MarkApprovalStepEscalated(ctx context.Context, arg MarkApprovalStepEscalatedParams) error
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error)
+	NextDocumentSequence(ctx context.Context, arg NextDocumentSequenceParams) (int32, error)
+	// RecalculateOpportunityAmount derives the deal amount from its latest live quote: the
+	// accepted one, else the newest draft or sent quote with line items. Superseded, rejected
+	// and expired quotes never count; without a live quote the opportunity's own lines do, and
+	// a deal with neither keeps its current amount.
+	RecalculateOpportunityAmount(ctx context.Context, arg RecalculateOpportunityAmountParams) (Opportunity, error)
+	RecalculateOrderAmount(ctx context.Context, arg RecalculateOrderAmountParams) (Order, error)
+	// RecalculateQuoteTotals derives the pre-tax amount, consumption tax and total from the
+	// line items. Tax is rounded once per rate with the tenant's rounding mode.
+	RecalculateQuoteTotals(ctx context.Context, arg RecalculateQuoteTotalsParams) (Quote, error)
+	RejectOpenQuotes(ctx context.Context, arg RejectOpenQuotesParams) ([]RejectOpenQuotesRow, error)
 	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	// Setting a rule starts a new series anchored at the task's current due date; a NULL
+	// rule stops the recurrence and keeps the series columns for history.
+	SetActivityRecurrence(ctx context.Context, arg SetActivityRecurrenceParams) (Activity, error)
+	SetChangeContext(ctx context.Context, arg SetChangeContextParams) error
+	// Keeps a next action the owner already planned before the quote expires.
+	SetOpportunityFollowUp(ctx context.Context, arg SetOpportunityFollowUpParams) (int64, error)
+	SetOpportunityLossCompetitor(ctx context.Context, arg SetOpportunityLossCompetitorParams) error
+	SetOrderSubscription(ctx context.Context, arg SetOrderSubscriptionParams) (Order, error)
+	SetQuoteApprovedForSend(ctx context.Context, arg SetQuoteApprovedForSendParams) error
+	SummarizeOrders(ctx context.Context, arg SummarizeOrdersParams) (SummarizeOrdersRow, error)
+	SupersedeQuote(ctx context.Context, arg SupersedeQuoteParams) (Quote, error)
+	TransitionOrderStatus(ctx context.Context, arg TransitionOrderStatusParams) (Order, error)
+	// Sending stamps issued_on (default today) and valid_until (default issued_on + 30 days).
+	TransitionQuoteStatus(ctx context.Context, arg TransitionQuoteStatusParams) (Quote, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	// completed: NULL leaves completion alone, true completes (keeping an earlier
+	// completion time), false reopens.
+	UpdateActivity(ctx context.Context, arg UpdateActivityParams) (Activity, error)
+	UpdateActivityTemplate(ctx context.Context, arg UpdateActivityTemplateParams) (ActivityTemplate, error)
+	UpdateApprovalChain(ctx context.Context, arg UpdateApprovalChainParams) (ApprovalChain, error)
+	UpdateCompetitor(ctx context.Context, arg UpdateCompetitorParams) (Competitor, error)
+	UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error)
+	UpdateDiscountPolicy(ctx context.Context, arg UpdateDiscountPolicyParams) (DiscountPolicy, error)
 	UpdateMembershipRole(ctx context.Context, arg UpdateMembershipRoleParams) (Membership, error)
 	UpdateOpportunity(ctx context.Context, arg UpdateOpportunityParams) (Opportunity, error)
 	UpdateOpportunityNextAction(ctx context.Context, arg UpdateOpportunityNextActionParams) (Opportunity, error)
+	UpdatePriceBook(ctx context.Context, arg UpdatePriceBookParams) (PriceBook, error)
+	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
+	UpdateQuote(ctx context.Context, arg UpdateQuoteParams) (Quote, error)
+	UpdateQuotePDFTemplate(ctx context.Context, arg UpdateQuotePDFTemplateParams) (QuotePdfTemplate, error)
+	UpdateQuoteStatus(ctx context.Context, arg UpdateQuoteStatusParams) error
+	UpdateTenantBaseCurrency(ctx context.Context, arg UpdateTenantBaseCurrencyParams) (string, error)
+	UpdateTenantTaxRounding(ctx context.Context, arg UpdateTenantTaxRoundingParams) (TaxRoundingEnum, error)
+	// Converts one linked integration event into an email or meeting activity owned by the
+	// opportunity owner. Future meetings stay open; re-running completes them once they have
+	// happened. Returns no row when the event is not convertible or nothing changed.
+	UpsertActivityFromEvent(ctx context.Context, arg UpsertActivityFromEventParams) (Activity, error)
+	UpsertDocumentNumberFormat(ctx context.Context, arg UpsertDocumentNumberFormatParams) (DocumentNumberFormat, error)
+	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error)
 	UpsertIntegrationConnection(ctx context.Context, arg UpsertIntegrationConnectionParams) (IntegrationConnection, error)
+	UpsertOpportunityCompetitor(ctx context.Context, arg UpsertOpportunityCompetitorParams) (OpportunityCompetitor, error)
+	UpsertPriceBookEntry(ctx context.Context, arg UpsertPriceBookEntryParams) (PriceBookEntry, error)
+	UpsertProductBySKU(ctx context.Context, arg UpsertProductBySKUParams) (Product, error)
+	WithdrawApprovalRequest(ctx context.Context, arg WithdrawApprovalRequestParams) (ApprovalRequest, error)
 }
 
 var _ Querier = (*Queries)(nil)
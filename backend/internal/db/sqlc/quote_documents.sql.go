@@ -0,0 +1,571 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: quote_documents.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clearDefaultQuotePDFTemplate = `-- name: ClearDefaultQuotePDFTemplate :exec
+UPDATE quote_pdf_templates
+SET is_default = false,
+    version = version + 1,
+    updated_at = now()
+WHERE tenant_id = $1
+  AND is_default
+  AND id <> $2
+`
+
+type ClearDefaultQuotePDFTemplateParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	KeepTemplateID pgtype.UUID `json:"keep_template_id"`
+}
+
+// Run before making another template the default so the partial unique index holds.
+func (q *Queries) ClearDefaultQuotePDFTemplate(ctx context.Context, arg ClearDefaultQuotePDFTemplateParams) error {
+	_, err := q.db.Exec(ctx, clearDefaultQuotePDFTemplate, arg.TenantID, arg.KeepTemplateID)
+	return err
+}
+
+const createQuoteDocument = `-- name: CreateQuoteDocument :one
+INSERT INTO quote_documents (
+  tenant_id,
+  quote_id,
+  version,
+  quote_version,
+  template_id,
+  template_version,
+  file_name,
+  content,
+  size_bytes,
+  sha256,
+  created_by
+)
+SELECT
+  $1,
+  $2,
+  coalesce(max(qd.version), 0) + 1,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10
+FROM quote_documents qd
+WHERE qd.tenant_id = $1
+  AND qd.quote_id = $2
+RETURNING id, tenant_id, quote_id, version, quote_version, template_id, template_version, file_name, content, size_bytes, sha256, created_by, created_at
+`
+
+type CreateQuoteDocumentParams struct {
+	TenantID        pgtype.UUID `json:"tenant_id"`
+	QuoteID         pgtype.UUID `json:"quote_id"`
+	QuoteVersion    int64       `json:"quote_version"`
+	TemplateID      pgtype.UUID `json:"template_id"`
+	TemplateVersion pgtype.Int8 `json:"template_version"`
+	FileName        string      `json:"file_name"`
+	Content         []byte      `json:"content"`
+	SizeBytes       int32       `json:"size_bytes"`
+	Sha256          string      `json:"sha256"`
+	CreatedBy       pgtype.UUID `json:"created_by"`
+}
+
+// The caller holds the quote row lock, so max(version) + 1 cannot race.
+func (q *Queries) CreateQuoteDocument(ctx context.Context, arg CreateQuoteDocumentParams) (QuoteDocument, error) {
+	row := q.db.QueryRow(ctx, createQuoteDocument,
+		arg.TenantID,
+		arg.QuoteID,
+		arg.QuoteVersion,
+		arg.TemplateID,
+		arg.TemplateVersion,
+		arg.FileName,
+		arg.Content,
+		arg.SizeBytes,
+		arg.Sha256,
+		arg.CreatedBy,
+	)
+	var i QuoteDocument
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.QuoteID,
+		&i.Version,
+		&i.QuoteVersion,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.FileName,
+		&i.Content,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createQuotePDFTemplate = `-- name: CreateQuotePDFTemplate :one
+INSERT INTO quote_pdf_templates (
+  tenant_id,
+  name,
+  language,
+  company_name,
+  company_address,
+  company_phone,
+  registration_number,
+  accent_color,
+  footer_text,
+  is_default,
+  created_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10,
+  $11
+)
+RETURNING id, tenant_id, name, language, company_name, company_address, company_phone, registration_number, accent_color, footer_text, is_default, version, created_by, created_at, updated_at
+`
+
+type CreateQuotePDFTemplateParams struct {
+	TenantID           pgtype.UUID `json:"tenant_id"`
+	Name               string      `json:"name"`
+	Language           string      `json:"language"`
+	CompanyName        string      `json:"company_name"`
+	CompanyAddress     pgtype.Text `json:"company_address"`
+	CompanyPhone       pgtype.Text `json:"company_phone"`
+	RegistrationNumber pgtype.Text `json:"registration_number"`
+	AccentColor        string      `json:"accent_color"`
+	FooterText         pgtype.Text `json:"footer_text"`
+	IsDefault          bool        `json:"is_default"`
+	CreatedBy          pgtype.UUID `json:"created_by"`
+}
+
+func (q *Queries) CreateQuotePDFTemplate(ctx context.Context, arg CreateQuotePDFTemplateParams) (QuotePdfTemplate, error) {
+	row := q.db.QueryRow(ctx, createQuotePDFTemplate,
+		arg.TenantID,
+		arg.Name,
+		arg.Language,
+		arg.CompanyName,
+		arg.CompanyAddress,
+		arg.CompanyPhone,
+		arg.RegistrationNumber,
+		arg.AccentColor,
+		arg.FooterText,
+		arg.IsDefault,
+		arg.CreatedBy,
+	)
+	var i QuotePdfTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Language,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.RegistrationNumber,
+		&i.AccentColor,
+		&i.FooterText,
+		&i.IsDefault,
+		&i.Version,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDefaultQuotePDFTemplate = `-- name: GetDefaultQuotePDFTemplate :one
+SELECT id, tenant_id, name, language, company_name, company_address, company_phone, registration_number, accent_color, footer_text, is_default, version, created_by, created_at, updated_at
+FROM quote_pdf_templates
+WHERE tenant_id = $1
+  AND is_default
+`
+
+func (q *Queries) GetDefaultQuotePDFTemplate(ctx context.Context, tenantID pgtype.UUID) (QuotePdfTemplate, error) {
+	row := q.db.QueryRow(ctx, getDefaultQuotePDFTemplate, tenantID)
+	var i QuotePdfTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Language,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.RegistrationNumber,
+		&i.AccentColor,
+		&i.FooterText,
+		&i.IsDefault,
+		&i.Version,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLatestQuoteDocument = `-- name: GetLatestQuoteDocument :one
+SELECT id, tenant_id, quote_id, version, quote_version, template_id, template_version, file_name, content, size_bytes, sha256, created_by, created_at
+FROM quote_documents
+WHERE tenant_id = $1
+  AND quote_id = $2
+ORDER BY version DESC
+LIMIT 1
+`
+
+type GetLatestQuoteDocumentParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) GetLatestQuoteDocument(ctx context.Context, arg GetLatestQuoteDocumentParams) (QuoteDocument, error) {
+	row := q.db.QueryRow(ctx, getLatestQuoteDocument, arg.TenantID, arg.QuoteID)
+	var i QuoteDocument
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.QuoteID,
+		&i.Version,
+		&i.QuoteVersion,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.FileName,
+		&i.Content,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getQuoteDocument = `-- name: GetQuoteDocument :one
+SELECT id, tenant_id, quote_id, version, quote_version, template_id, template_version, file_name, content, size_bytes, sha256, created_by, created_at
+FROM quote_documents
+WHERE tenant_id = $1
+  AND quote_id = $2
+  AND version = $3
+`
+
+type GetQuoteDocumentParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+	Version  int32       `json:"version"`
+}
+
+func (q *Queries) GetQuoteDocument(ctx context.Context, arg GetQuoteDocumentParams) (QuoteDocument, error) {
+	row := q.db.QueryRow(ctx, getQuoteDocument, arg.TenantID, arg.QuoteID, arg.Version)
+	var i QuoteDocument
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.QuoteID,
+		&i.Version,
+		&i.QuoteVersion,
+		&i.TemplateID,
+		&i.TemplateVersion,
+		&i.FileName,
+		&i.Content,
+		&i.SizeBytes,
+		&i.Sha256,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getQuotePDFContext = `-- name: GetQuotePDFContext :one
+SELECT
+  t.name AS tenant_name,
+  a.name AS account_name,
+  o.name AS opportunity_name,
+  coalesce(loc.name, '')::text AS location_name,
+  loc.country,
+  loc.postal_code,
+  loc.prefecture,
+  loc.city,
+  loc.address_line1,
+  loc.address_line2
+FROM quotes qt
+JOIN tenants t ON t.id = qt.tenant_id
+JOIN opportunities o ON o.id = qt.opportunity_id
+JOIN accounts a ON a.id = o.account_id
+LEFT JOIN LATERAL (
+  SELECT al.id, al.tenant_id, al.account_id, al.name, al.country, al.postal_code, al.prefecture, al.city, al.address_line1, al.address_line2, al.created_at, al.updated_at, al.is_billing
+  FROM account_locations al
+  WHERE al.tenant_id = qt.tenant_id
+    AND al.account_id = a.id
+  ORDER BY al.is_billing DESC, al.created_at ASC
+  LIMIT 1
+) loc ON true
+WHERE qt.tenant_id = $1
+  AND qt.id = $2
+`
+
+type GetQuotePDFContextParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+type GetQuotePDFContextRow struct {
+	TenantName      string      `json:"tenant_name"`
+	AccountName     string      `json:"account_name"`
+	OpportunityName string      `json:"opportunity_name"`
+	LocationName    string      `json:"location_name"`
+	Country         pgtype.Text `json:"country"`
+	PostalCode      pgtype.Text `json:"postal_code"`
+	Prefecture      pgtype.Text `json:"prefecture"`
+	City            pgtype.Text `json:"city"`
+	AddressLine1    pgtype.Text `json:"address_line1"`
+	AddressLine2    pgtype.Text `json:"address_line2"`
+}
+
+// Everything a quote PDF prints besides the quote, its line items and the template.
+// The billing location falls back to the oldest location when none is flagged.
+func (q *Queries) GetQuotePDFContext(ctx context.Context, arg GetQuotePDFContextParams) (GetQuotePDFContextRow, error) {
+	row := q.db.QueryRow(ctx, getQuotePDFContext, arg.TenantID, arg.QuoteID)
+	var i GetQuotePDFContextRow
+	err := row.Scan(
+		&i.TenantName,
+		&i.AccountName,
+		&i.OpportunityName,
+		&i.LocationName,
+		&i.Country,
+		&i.PostalCode,
+		&i.Prefecture,
+		&i.City,
+		&i.AddressLine1,
+		&i.AddressLine2,
+	)
+	return i, err
+}
+
+const getQuotePDFTemplate = `-- name: GetQuotePDFTemplate :one
+SELECT id, tenant_id, name, language, company_name, company_address, company_phone, registration_number, accent_color, footer_text, is_default, version, created_by, created_at, updated_at
+FROM quote_pdf_templates
+WHERE tenant_id = $1
+  AND id = $2
+`
+
+type GetQuotePDFTemplateParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	TemplateID pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) GetQuotePDFTemplate(ctx context.Context, arg GetQuotePDFTemplateParams) (QuotePdfTemplate, error) {
+	row := q.db.QueryRow(ctx, getQuotePDFTemplate, arg.TenantID, arg.TemplateID)
+	var i QuotePdfTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Language,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.RegistrationNumber,
+		&i.AccentColor,
+		&i.FooterText,
+		&i.IsDefault,
+		&i.Version,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listQuoteDocuments = `-- name: ListQuoteDocuments :many
+SELECT
+  id,
+  quote_id,
+  version,
+  quote_version,
+  template_id,
+  template_version,
+  file_name,
+  size_bytes,
+  sha256,
+  created_by,
+  created_at
+FROM quote_documents
+WHERE tenant_id = $1
+  AND quote_id = $2
+ORDER BY version DESC
+`
+
+type ListQuoteDocumentsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+type ListQuoteDocumentsRow struct {
+	ID              pgtype.UUID        `json:"id"`
+	QuoteID         pgtype.UUID        `json:"quote_id"`
+	Version         int32              `json:"version"`
+	QuoteVersion    int64              `json:"quote_version"`
+	TemplateID      pgtype.UUID        `json:"template_id"`
+	TemplateVersion pgtype.Int8        `json:"template_version"`
+	FileName        string             `json:"file_name"`
+	SizeBytes       int32              `json:"size_bytes"`
+	Sha256          string             `json:"sha256"`
+	CreatedBy       pgtype.UUID        `json:"created_by"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) ListQuoteDocuments(ctx context.Context, arg ListQuoteDocumentsParams) ([]ListQuoteDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listQuoteDocuments, arg.TenantID, arg.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListQuoteDocumentsRow{}
+	for rows.Next() {
+		var i ListQuoteDocumentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuoteID,
+			&i.Version,
+			&i.QuoteVersion,
+			&i.TemplateID,
+			&i.TemplateVersion,
+			&i.FileName,
+			&i.SizeBytes,
+			&i.Sha256,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuotePDFTemplates = `-- name: ListQuotePDFTemplates :many
+SELECT id, tenant_id, name, language, company_name, company_address, company_phone, registration_number, accent_color, footer_text, is_default, version, created_by, created_at, updated_at
+FROM quote_pdf_templates
+WHERE tenant_id = $1
+ORDER BY is_default DESC, name
+`
+
+func (q *Queries) ListQuotePDFTemplates(ctx context.Context, tenantID pgtype.UUID) ([]QuotePdfTemplate, error) {
+	rows, err := q.db.Query(ctx, listQuotePDFTemplates, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []QuotePdfTemplate{}
+	for rows.Next() {
+		var i QuotePdfTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.Name,
+			&i.Language,
+			&i.CompanyName,
+			&i.CompanyAddress,
+			&i.CompanyPhone,
+			&i.RegistrationNumber,
+			&i.AccentColor,
+			&i.FooterText,
+			&i.IsDefault,
+			&i.Version,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateQuotePDFTemplate = `-- name: UpdateQuotePDFTemplate :one
+UPDATE quote_pdf_templates
+SET
+  name = coalesce($1, name),
+  language = coalesce($2, language),
+  company_name = coalesce($3, company_name),
+  company_address = coalesce($4, company_address),
+  company_phone = coalesce($5, company_phone),
+  registration_number = coalesce($6, registration_number),
+  accent_color = coalesce($7, accent_color),
+  footer_text = coalesce($8, footer_text),
+  is_default = coalesce($9, is_default),
+  version = version + 1,
+  updated_at = now()
+WHERE tenant_id = $10
+  AND id = $11
+RETURNING id, tenant_id, name, language, company_name, company_address, company_phone, registration_number, accent_color, footer_text, is_default, version, created_by, created_at, updated_at
+`
+
+type UpdateQuotePDFTemplateParams struct {
+	Name               pgtype.Text `json:"name"`
+	Language           pgtype.Text `json:"language"`
+	CompanyName        pgtype.Text `json:"company_name"`
+	CompanyAddress     pgtype.Text `json:"company_address"`
+	CompanyPhone       pgtype.Text `json:"company_phone"`
+	RegistrationNumber pgtype.Text `json:"registration_number"`
+	AccentColor        pgtype.Text `json:"accent_color"`
+	FooterText         pgtype.Text `json:"footer_text"`
+	IsDefault          pgtype.Bool `json:"is_default"`
+	TenantID           pgtype.UUID `json:"tenant_id"`
+	TemplateID         pgtype.UUID `json:"template_id"`
+}
+
+func (q *Queries) UpdateQuotePDFTemplate(ctx context.Context, arg UpdateQuotePDFTemplateParams) (QuotePdfTemplate, error) {
+	row := q.db.QueryRow(ctx, updateQuotePDFTemplate,
+		arg.Name,
+		arg.Language,
+		arg.CompanyName,
+		arg.CompanyAddress,
+		arg.CompanyPhone,
+		arg.RegistrationNumber,
+		arg.AccentColor,
+		arg.FooterText,
+		arg.IsDefault,
+		arg.TenantID,
+		arg.TemplateID,
+	)
+	var i QuotePdfTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.Name,
+		&i.Language,
+		&i.CompanyName,
+		&i.CompanyAddress,
+		&i.CompanyPhone,
+		&i.RegistrationNumber,
+		&i.AccentColor,
+		&i.FooterText,
+		&i.IsDefault,
+		&i.Version,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,226 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: quote_expiry.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimQuoteExpiryReminder = `-- name: ClaimQuoteExpiryReminder :one
+INSERT INTO quote_expiry_reminders (
+  tenant_id,
+  quote_id,
+  valid_until,
+  notified_user_id
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4
+)
+ON CONFLICT (quote_id, valid_until) DO NOTHING
+RETURNING tenant_id, quote_id, valid_until, notified_user_id, created_at
+`
+
+type ClaimQuoteExpiryReminderParams struct {
+	TenantID       pgtype.UUID `json:"tenant_id"`
+	QuoteID        pgtype.UUID `json:"quote_id"`
+	ValidUntil     pgtype.Date `json:"valid_until"`
+	NotifiedUserID pgtype.UUID `json:"notified_user_id"`
+}
+
+// Returns no row when another run already claimed the reminder.
+func (q *Queries) ClaimQuoteExpiryReminder(ctx context.Context, arg ClaimQuoteExpiryReminderParams) (QuoteExpiryReminder, error) {
+	row := q.db.QueryRow(ctx, claimQuoteExpiryReminder,
+		arg.TenantID,
+		arg.QuoteID,
+		arg.ValidUntil,
+		arg.NotifiedUserID,
+	)
+	var i QuoteExpiryReminder
+	err := row.Scan(
+		&i.TenantID,
+		&i.QuoteID,
+		&i.ValidUntil,
+		&i.NotifiedUserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const expireDueQuotes = `-- name: ExpireDueQuotes :many
+WITH due AS (
+  SELECT sent.id
+  FROM quotes sent
+  WHERE sent.tenant_id = $1
+    AND sent.status = 'sent'
+    AND sent.valid_until < current_date
+  ORDER BY sent.id
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+UPDATE quotes q
+SET status = 'expired',
+    expired_at = now(),
+    updated_at = now()
+FROM due, opportunities o
+WHERE q.id = due.id
+  AND o.tenant_id = q.tenant_id
+  AND o.id = q.opportunity_id
+RETURNING
+  q.id,
+  q.quote_no,
+  q.opportunity_id,
+  q.valid_until,
+  o.name AS opportunity_name,
+  o.owner_user_id
+`
+
+type ExpireDueQuotesParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	LimitCount int32       `json:"limit_count"`
+}
+
+type ExpireDueQuotesRow struct {
+	ID              pgtype.UUID `json:"id"`
+	QuoteNo         string      `json:"quote_no"`
+	OpportunityID   pgtype.UUID `json:"opportunity_id"`
+	ValidUntil      pgtype.Date `json:"valid_until"`
+	OpportunityName string      `json:"opportunity_name"`
+	OwnerUserID     pgtype.UUID `json:"owner_user_id"`
+}
+
+// Rows locked by a request or by another replica are skipped and picked up on the next run.
+func (q *Queries) ExpireDueQuotes(ctx context.Context, arg ExpireDueQuotesParams) ([]ExpireDueQuotesRow, error) {
+	rows, err := q.db.Query(ctx, expireDueQuotes, arg.TenantID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ExpireDueQuotesRow{}
+	for rows.Next() {
+		var i ExpireDueQuotesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuoteNo,
+			&i.OpportunityID,
+			&i.ValidUntil,
+			&i.OpportunityName,
+			&i.OwnerUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuotesDueForExpiryReminder = `-- name: ListQuotesDueForExpiryReminder :many
+SELECT
+  q.id,
+  q.quote_no,
+  q.valid_until,
+  q.opportunity_id,
+  o.name AS opportunity_name,
+  o.owner_user_id
+FROM quotes q
+JOIN opportunities o ON o.tenant_id = q.tenant_id AND o.id = q.opportunity_id
+WHERE q.tenant_id = $1
+  AND q.status = 'sent'
+  AND q.valid_until BETWEEN current_date AND current_date + $2::int
+  AND o.stage NOT IN ('closed_won', 'closed_lost')
+  AND NOT EXISTS (
+    SELECT 1
+    FROM quote_expiry_reminders r
+    WHERE r.quote_id = q.id
+      AND r.valid_until = q.valid_until
+  )
+ORDER BY o.id, q.id
+LIMIT $3
+FOR UPDATE OF o SKIP LOCKED
+`
+
+type ListQuotesDueForExpiryReminderParams struct {
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	NoticeDays int32       `json:"notice_days"`
+	LimitCount int32       `json:"limit_count"`
+}
+
+type ListQuotesDueForExpiryReminderRow struct {
+	ID              pgtype.UUID `json:"id"`
+	QuoteNo         string      `json:"quote_no"`
+	ValidUntil      pgtype.Date `json:"valid_until"`
+	OpportunityID   pgtype.UUID `json:"opportunity_id"`
+	OpportunityName string      `json:"opportunity_name"`
+	OwnerUserID     pgtype.UUID `json:"owner_user_id"`
+}
+
+// Locks the opportunity rather than the quote: request handlers lock the opportunity
+// first, so the job never waits on a quote while holding something they need.
+func (q *Queries) ListQuotesDueForExpiryReminder(ctx context.Context, arg ListQuotesDueForExpiryReminderParams) ([]ListQuotesDueForExpiryReminderRow, error) {
+	rows, err := q.db.Query(ctx, listQuotesDueForExpiryReminder, arg.TenantID, arg.NoticeDays, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListQuotesDueForExpiryReminderRow{}
+	for rows.Next() {
+		var i ListQuotesDueForExpiryReminderRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuoteNo,
+			&i.ValidUntil,
+			&i.OpportunityID,
+			&i.OpportunityName,
+			&i.OwnerUserID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setOpportunityFollowUp = `-- name: SetOpportunityFollowUp :execrows
+UPDATE opportunities
+SET next_action_at = $1,
+    next_action_note = $2,
+    updated_at = now()
+WHERE tenant_id = $3
+  AND id = $4
+  AND (next_action_at IS NULL OR next_action_at >= $5::timestamptz)
+`
+
+type SetOpportunityFollowUpParams struct {
+	NextActionAt   pgtype.Timestamptz `json:"next_action_at"`
+	NextActionNote pgtype.Text        `json:"next_action_note"`
+	TenantID       pgtype.UUID        `json:"tenant_id"`
+	OpportunityID  pgtype.UUID        `json:"opportunity_id"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+}
+
+// Keeps a next action the owner already planned before the quote expires.
+func (q *Queries) SetOpportunityFollowUp(ctx context.Context, arg SetOpportunityFollowUpParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setOpportunityFollowUp,
+		arg.NextActionAt,
+		arg.NextActionNote,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
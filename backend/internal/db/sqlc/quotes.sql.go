@@ -0,0 +1,393 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: quotes.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countQuoteLineItems = `-- name: CountQuoteLineItems :one
+SELECT count(*)::bigint
+FROM line_items
+WHERE tenant_id = $1
+  AND quote_id = $2
+`
+
+type CountQuoteLineItemsParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) CountQuoteLineItems(ctx context.Context, arg CountQuoteLineItemsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countQuoteLineItems, arg.TenantID, arg.QuoteID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createQuoteRevision = `-- name: CreateQuoteRevision :one
+INSERT INTO quotes (
+  tenant_id,
+  opportunity_id,
+  quote_no,
+  amount,
+  tax_amount,
+  total_amount,
+  tax_breakdown,
+  currency,
+  status,
+  note,
+  created_by,
+  root_quote_id,
+  previous_quote_id,
+  revision
+)
+SELECT
+  src.tenant_id,
+  src.opportunity_id,
+  $1::text AS quote_no,
+  src.amount,
+  src.tax_amount,
+  src.total_amount,
+  src.tax_breakdown,
+  src.currency,
+  'draft'::quote_status_enum AS status,
+  coalesce($2::text, src.note) AS note,
+  $3::uuid AS created_by,
+  coalesce(src.root_quote_id, src.id) AS root_quote_id,
+  src.id AS previous_quote_id,
+  $4::int AS revision
+FROM quotes src
+WHERE src.tenant_id = $5
+  AND src.id = $6
+RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+`
+
+type CreateQuoteRevisionParams struct {
+	QuoteNo       string      `json:"quote_no"`
+	Note          pgtype.Text `json:"note"`
+	CreatedBy     pgtype.UUID `json:"created_by"`
+	Revision      int32       `json:"revision"`
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	SourceQuoteID pgtype.UUID `json:"source_quote_id"`
+}
+
+// A new revision starts as a draft with the source's header, totals and currency;
+// issued_on and valid_until are stamped again when it is sent.
+func (q *Queries) CreateQuoteRevision(ctx context.Context, arg CreateQuoteRevisionParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, createQuoteRevision,
+		arg.QuoteNo,
+		arg.Note,
+		arg.CreatedBy,
+		arg.Revision,
+		arg.TenantID,
+		arg.SourceQuoteID,
+	)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const getMaxQuoteRevision = `-- name: GetMaxQuoteRevision :one
+SELECT max(revision)::int
+FROM quotes
+WHERE tenant_id = $1
+  AND coalesce(root_quote_id, id) = $2
+`
+
+type GetMaxQuoteRevisionParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RootQuoteID pgtype.UUID `json:"root_quote_id"`
+}
+
+func (q *Queries) GetMaxQuoteRevision(ctx context.Context, arg GetMaxQuoteRevisionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, getMaxQuoteRevision, arg.TenantID, arg.RootQuoteID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getQuoteByIDForUpdate = `-- name: GetQuoteByIDForUpdate :one
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+FROM quotes
+WHERE tenant_id = $1
+  AND id = $2
+FOR UPDATE
+`
+
+type GetQuoteByIDForUpdateParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) GetQuoteByIDForUpdate(ctx context.Context, arg GetQuoteByIDForUpdateParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, getQuoteByIDForUpdate, arg.TenantID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const listQuoteRevisions = `-- name: ListQuoteRevisions :many
+SELECT id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+FROM quotes
+WHERE tenant_id = $1
+  AND coalesce(root_quote_id, id) = $2
+ORDER BY revision
+`
+
+type ListQuoteRevisionsParams struct {
+	TenantID    pgtype.UUID `json:"tenant_id"`
+	RootQuoteID pgtype.UUID `json:"root_quote_id"`
+}
+
+func (q *Queries) ListQuoteRevisions(ctx context.Context, arg ListQuoteRevisionsParams) ([]Quote, error) {
+	rows, err := q.db.Query(ctx, listQuoteRevisions, arg.TenantID, arg.RootQuoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Quote{}
+	for rows.Next() {
+		var i Quote
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.QuoteNo,
+			&i.Amount,
+			&i.Status,
+			&i.IssuedOn,
+			&i.ValidUntil,
+			&i.Note,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Currency,
+			&i.Version,
+			&i.TaxAmount,
+			&i.TotalAmount,
+			&i.TaxBreakdown,
+			&i.RootQuoteID,
+			&i.PreviousQuoteID,
+			&i.Revision,
+			&i.SupersededAt,
+			&i.ExpiredAt,
+			&i.ApprovedForSendAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const supersedeQuote = `-- name: SupersedeQuote :one
+UPDATE quotes
+SET status = 'superseded',
+    superseded_at = now(),
+    updated_at = now()
+WHERE tenant_id = $1
+  AND id = $2
+RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+`
+
+type SupersedeQuoteParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	QuoteID  pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) SupersedeQuote(ctx context.Context, arg SupersedeQuoteParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, supersedeQuote, arg.TenantID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const transitionQuoteStatus = `-- name: TransitionQuoteStatus :one
+UPDATE quotes
+SET status = $1,
+    issued_on = CASE WHEN $1 = 'sent'::quote_status_enum THEN coalesce(issued_on, current_date) ELSE issued_on END,
+    valid_until = CASE
+      WHEN $1 = 'sent'::quote_status_enum THEN coalesce(valid_until, coalesce(issued_on, current_date) + 30)
+      ELSE valid_until
+    END,
+    updated_at = now()
+WHERE tenant_id = $2
+  AND id = $3
+RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+`
+
+type TransitionQuoteStatusParams struct {
+	Status   QuoteStatusEnum `json:"status"`
+	TenantID pgtype.UUID     `json:"tenant_id"`
+	QuoteID  pgtype.UUID     `json:"quote_id"`
+}
+
+// Sending stamps issued_on (default today) and valid_until (default issued_on + 30 days).
+func (q *Queries) TransitionQuoteStatus(ctx context.Context, arg TransitionQuoteStatusParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, transitionQuoteStatus, arg.Status, arg.TenantID, arg.QuoteID)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
+
+const updateQuote = `-- name: UpdateQuote :one
+UPDATE quotes
+SET
+  issued_on = coalesce($1, issued_on),
+  valid_until = coalesce($2, valid_until),
+  note = coalesce($3, note),
+  updated_at = now()
+WHERE tenant_id = $4
+  AND id = $5
+RETURNING id, tenant_id, opportunity_id, quote_no, amount, status, issued_on, valid_until, note, created_by, created_at, updated_at, currency, version, tax_amount, total_amount, tax_breakdown, root_quote_id, previous_quote_id, revision, superseded_at, expired_at, approved_for_send_at
+`
+
+type UpdateQuoteParams struct {
+	IssuedOn   pgtype.Date `json:"issued_on"`
+	ValidUntil pgtype.Date `json:"valid_until"`
+	Note       pgtype.Text `json:"note"`
+	TenantID   pgtype.UUID `json:"tenant_id"`
+	QuoteID    pgtype.UUID `json:"quote_id"`
+}
+
+func (q *Queries) UpdateQuote(ctx context.Context, arg UpdateQuoteParams) (Quote, error) {
+	row := q.db.QueryRow(ctx, updateQuote,
+		arg.IssuedOn,
+		arg.ValidUntil,
+		arg.Note,
+		arg.TenantID,
+		arg.QuoteID,
+	)
+	var i Quote
+	err := row.Scan(
+		&i.ID,
+		&i.TenantID,
+		&i.OpportunityID,
+		&i.QuoteNo,
+		&i.Amount,
+		&i.Status,
+		&i.IssuedOn,
+		&i.ValidUntil,
+		&i.Note,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Currency,
+		&i.Version,
+		&i.TaxAmount,
+		&i.TotalAmount,
+		&i.TaxBreakdown,
+		&i.RootQuoteID,
+		&i.PreviousQuoteID,
+		&i.Revision,
+		&i.SupersededAt,
+		&i.ExpiredAt,
+		&i.ApprovedForSendAt,
+	)
+	return i, err
+}
@@ -0,0 +1,307 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: stage_history.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createOpportunityStageHistory = `-- name: CreateOpportunityStageHistory :exec
+INSERT INTO opportunity_stage_history (
+  tenant_id,
+  opportunity_id,
+  from_stage,
+  to_stage,
+  amount,
+  probability,
+  changed_by
+) VALUES (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+`
+
+type CreateOpportunityStageHistoryParams struct {
+	TenantID      pgtype.UUID              `json:"tenant_id"`
+	OpportunityID pgtype.UUID              `json:"opportunity_id"`
+	FromStage     NullOpportunityStageEnum `json:"from_stage"`
+	ToStage       OpportunityStageEnum     `json:"to_stage"`
+	Amount        pgtype.Numeric           `json:"amount"`
+	Probability   int16                    `json:"probability"`
+	ChangedBy     pgtype.UUID              `json:"changed_by"`
+}
+
+func (q *Queries) CreateOpportunityStageHistory(ctx context.Context, arg CreateOpportunityStageHistoryParams) error {
+	_, err := q.db.Exec(ctx, createOpportunityStageHistory,
+		arg.TenantID,
+		arg.OpportunityID,
+		arg.FromStage,
+		arg.ToStage,
+		arg.Amount,
+		arg.Probability,
+		arg.ChangedBy,
+	)
+	return err
+}
+
+const getOwnerVelocityStats = `-- name: GetOwnerVelocityStats :many
+SELECT
+  o.owner_user_id,
+  count(*)::bigint AS closed_count,
+  count(*) FILTER (WHERE o.stage = 'closed_won')::bigint AS won_count,
+  coalesce(sum(o.amount * fx_rate(o.tenant_id, o.currency, o.closed_at::date))
+    FILTER (WHERE o.stage = 'closed_won' AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NOT NULL), 0)::double precision AS won_amount,
+  count(*) FILTER (WHERE o.stage = 'closed_won' AND fx_rate(o.tenant_id, o.currency, o.closed_at::date) IS NULL)::bigint AS missing_rate_count,
+  coalesce(
+    avg(EXTRACT(EPOCH FROM (o.closed_at - o.created_at))) FILTER (WHERE o.stage = 'closed_won') / 86400.0,
+    0
+  )::double precision AS avg_cycle_days
+FROM opportunities o
+WHERE o.tenant_id = $1
+  AND o.stage IN ('closed_won', 'closed_lost')
+  AND o.closed_at >= $2::timestamptz
+  AND o.closed_at < $3::timestamptz
+  AND ($4::uuid IS NULL OR o.owner_user_id = $4)
+GROUP BY o.owner_user_id
+ORDER BY won_amount DESC, o.owner_user_id ASC
+`
+
+type GetOwnerVelocityStatsParams struct {
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	RangeStart  pgtype.Timestamptz `json:"range_start"`
+	RangeEnd    pgtype.Timestamptz `json:"range_end"`
+	OwnerUserID pgtype.UUID        `json:"owner_user_id"`
+}
+
+type GetOwnerVelocityStatsRow struct {
+	OwnerUserID      pgtype.UUID `json:"owner_user_id"`
+	ClosedCount      int64       `json:"closed_count"`
+	WonCount         int64       `json:"won_count"`
+	WonAmount        float64     `json:"won_amount"`
+	MissingRateCount int64       `json:"missing_rate_count"`
+	AvgCycleDays     float64     `json:"avg_cycle_days"`
+}
+
+func (q *Queries) GetOwnerVelocityStats(ctx context.Context, arg GetOwnerVelocityStatsParams) ([]GetOwnerVelocityStatsRow, error) {
+	rows, err := q.db.Query(ctx, getOwnerVelocityStats,
+		arg.TenantID,
+		arg.RangeStart,
+		arg.RangeEnd,
+		arg.OwnerUserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetOwnerVelocityStatsRow{}
+	for rows.Next() {
+		var i GetOwnerVelocityStatsRow
+		if err := rows.Scan(
+			&i.OwnerUserID,
+			&i.ClosedCount,
+			&i.WonCount,
+			&i.WonAmount,
+			&i.MissingRateCount,
+			&i.AvgCycleDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStageConversionStats = `-- name: GetStageConversionStats :many
+SELECT
+  h.from_stage,
+  h.to_stage,
+  count(*)::bigint AS transition_count,
+  (count(*)::double precision / sum(count(*)) OVER (PARTITION BY h.from_stage))::double precision AS conversion_rate
+FROM opportunity_stage_history h
+JOIN opportunities o ON o.id = h.opportunity_id
+WHERE h.tenant_id = $1
+  AND h.from_stage IS NOT NULL
+  AND h.changed_at >= $2::timestamptz
+  AND h.changed_at < $3::timestamptz
+  AND ($4::uuid IS NULL OR o.owner_user_id = $4)
+GROUP BY h.from_stage, h.to_stage
+ORDER BY h.from_stage, h.to_stage
+`
+
+type GetStageConversionStatsParams struct {
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+	RangeStart  pgtype.Timestamptz `json:"range_start"`
+	RangeEnd    pgtype.Timestamptz `json:"range_end"`
+	OwnerUserID pgtype.UUID        `json:"owner_user_id"`
+}
+
+type GetStageConversionStatsRow struct {
+	FromStage       NullOpportunityStageEnum `json:"from_stage"`
+	ToStage         OpportunityStageEnum     `json:"to_stage"`
+	TransitionCount int64                    `json:"transition_count"`
+	ConversionRate  float64                  `json:"conversion_rate"`
+}
+
+func (q *Queries) GetStageConversionStats(ctx context.Context, arg GetStageConversionStatsParams) ([]GetStageConversionStatsRow, error) {
+	rows, err := q.db.Query(ctx, getStageConversionStats,
+		arg.TenantID,
+		arg.RangeStart,
+		arg.RangeEnd,
+		arg.OwnerUserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStageConversionStatsRow{}
+	for rows.Next() {
+		var i GetStageConversionStatsRow
+		if err := rows.Scan(
+			&i.FromStage,
+			&i.ToStage,
+			&i.TransitionCount,
+			&i.ConversionRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStageDurationStats = `-- name: GetStageDurationStats :many
+WITH intervals AS (
+  SELECT
+    h.to_stage AS stage,
+    h.changed_at AS entered_at,
+    lead(h.changed_at) OVER (PARTITION BY h.opportunity_id ORDER BY h.changed_at, h.id) AS exited_at,
+    o.owner_user_id
+  FROM opportunity_stage_history h
+  JOIN opportunities o ON o.id = h.opportunity_id
+  WHERE h.tenant_id = $4
+)
+SELECT
+  i.stage,
+  count(*) FILTER (WHERE i.exited_at IS NOT NULL)::bigint AS completed_count,
+  count(*) FILTER (WHERE i.exited_at IS NULL)::bigint AS open_count,
+  coalesce(
+    avg(EXTRACT(EPOCH FROM (i.exited_at - i.entered_at))) FILTER (WHERE i.exited_at IS NOT NULL) / 86400.0,
+    0
+  )::double precision AS avg_days,
+  coalesce(
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (i.exited_at - i.entered_at))) FILTER (WHERE i.exited_at IS NOT NULL) / 86400.0,
+    0
+  )::double precision AS median_days
+FROM intervals i
+WHERE i.entered_at >= $1::timestamptz
+  AND i.entered_at < $2::timestamptz
+  AND i.stage NOT IN ('closed_won', 'closed_lost')
+  AND ($3::uuid IS NULL OR i.owner_user_id = $3)
+GROUP BY i.stage
+ORDER BY i.stage
+`
+
+type GetStageDurationStatsParams struct {
+	RangeStart  pgtype.Timestamptz `json:"range_start"`
+	RangeEnd    pgtype.Timestamptz `json:"range_end"`
+	OwnerUserID pgtype.UUID        `json:"owner_user_id"`
+	TenantID    pgtype.UUID        `json:"tenant_id"`
+}
+
+type GetStageDurationStatsRow struct {
+	Stage          OpportunityStageEnum `json:"stage"`
+	CompletedCount int64                `json:"completed_count"`
+	OpenCount      int64                `json:"open_count"`
+	AvgDays        float64              `json:"avg_days"`
+	MedianDays     float64              `json:"median_days"`
+}
+
+func (q *Queries) GetStageDurationStats(ctx context.Context, arg GetStageDurationStatsParams) ([]GetStageDurationStatsRow, error) {
+	rows, err := q.db.Query(ctx, getStageDurationStats,
+		arg.RangeStart,
+		arg.RangeEnd,
+		arg.OwnerUserID,
+		arg.TenantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStageDurationStatsRow{}
+	for rows.Next() {
+		var i GetStageDurationStatsRow
+		if err := rows.Scan(
+			&i.Stage,
+			&i.CompletedCount,
+			&i.OpenCount,
+			&i.AvgDays,
+			&i.MedianDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpportunityStageHistory = `-- name: ListOpportunityStageHistory :many
+SELECT id, tenant_id, opportunity_id, from_stage, to_stage, amount, probability, changed_by, changed_at
+FROM opportunity_stage_history
+WHERE tenant_id = $1
+  AND opportunity_id = $2
+ORDER BY changed_at ASC, id ASC
+`
+
+type ListOpportunityStageHistoryParams struct {
+	TenantID      pgtype.UUID `json:"tenant_id"`
+	OpportunityID pgtype.UUID `json:"opportunity_id"`
+}
+
+func (q *Queries) ListOpportunityStageHistory(ctx context.Context, arg ListOpportunityStageHistoryParams) ([]OpportunityStageHistory, error) {
+	rows, err := q.db.Query(ctx, listOpportunityStageHistory, arg.TenantID, arg.OpportunityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []OpportunityStageHistory{}
+	for rows.Next() {
+		var i OpportunityStageHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.TenantID,
+			&i.OpportunityID,
+			&i.FromStage,
+			&i.ToStage,
+			&i.Amount,
+			&i.Probability,
+			&i.ChangedBy,
+			&i.ChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -64,6 +64,26 @@ func (q *Queries) CreateMembership(ctx context.Context, arg CreateMembershipPara
 	return i, err
 }
 
+const getActiveMembershipRole = `-- name: GetActiveMembershipRole :one
+SELECT role
+FROM memberships
+WHERE tenant_id = $1
+  AND user_id = $2
+  AND is_active
+`
+
+type GetActiveMembershipRoleParams struct {
+	TenantID pgtype.UUID `json:"tenant_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetActiveMembershipRole(ctx context.Context, arg GetActiveMembershipRoleParams) (RoleEnum, error) {
+	row := q.db.QueryRow(ctx, getActiveMembershipRole, arg.TenantID, arg.UserID)
+	var role RoleEnum
+	err := row.Scan(&role)
+	return role, err
+}
+
 const listTenantUsers = `-- name: ListTenantUsers :many
 SELECT
   u.id,
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errNotTenantMember = errors.New("user is not an active member of this tenant")
+var errOpportunityForbidden = errors.New("user has no access to this opportunity")
+var errAdminOnly = errors.New("only tenant admins may do this")
+
+// actorRole resolves the caller's role in the tenant. Users without an active
+// membership get errNotTenantMember.
+func actorRole(ctx context.Context, q *dbgen.Queries, tenantID, actorID uuid.UUID) (dbgen.RoleEnum, error) {
+	role, err := q.GetActiveMembershipRole(ctx, dbgen.GetActiveMembershipRoleParams{
+		TenantID: toPGUUID(tenantID),
+		UserID:   toPGUUID(actorID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errNotTenantMember
+	}
+	return role, err
+}
+
+// requireAdmin lets tenant admins through and fails with errAdminOnly for everyone else.
+func requireAdmin(ctx context.Context, q *dbgen.Queries, tenantID, actorID uuid.UUID) error {
+	role, err := actorRole(ctx, q, tenantID, actorID)
+	if err != nil {
+		return err
+	}
+	if role != dbgen.RoleEnumAdmin {
+		return errAdminOnly
+	}
+	return nil
+}
+
+// opportunityVisibility returns the user to restrict opportunity lists to: sales
+// users see deals they own or are on the team of, managers and admins see all.
+func opportunityVisibility(ctx context.Context, q *dbgen.Queries, tenantID, actorID uuid.UUID) (pgtype.UUID, error) {
+	role, err := actorRole(ctx, q, tenantID, actorID)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if role == dbgen.RoleEnumSales {
+		return toPGUUID(actorID), nil
+	}
+	return pgtype.UUID{}, nil
+}
+
+// authorizeOpportunity applies the RBAC rule for a single deal: managers and admins
+// may act on any deal, sales users only on deals they own or are a team member of.
+func authorizeOpportunity(ctx context.Context, q *dbgen.Queries, tenantID, actorID uuid.UUID, opportunity dbgen.Opportunity) error {
+	role, err := actorRole(ctx, q, tenantID, actorID)
+	if err != nil {
+		return err
+	}
+	if role != dbgen.RoleEnumSales || opportunity.OwnerUserID == toPGUUID(actorID) {
+		return nil
+	}
+	member, err := q.IsOpportunityTeamMember(ctx, dbgen.IsOpportunityTeamMemberParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: opportunity.ID,
+		UserID:        toPGUUID(actorID),
+	})
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errOpportunityForbidden
+	}
+	return nil
+}
+
+// isAccessDenied reports whether err came from one of the RBAC checks above.
+func isAccessDenied(err error) bool {
+	return errors.Is(err, errNotTenantMember) || errors.Is(err, errOpportunityForbidden) || errors.Is(err, errAdminOnly)
+}
@@ -0,0 +1,633 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var timelineItemTypes = map[string]bool{
+	"activity":          true,
+	"integration_event": true,
+	"quote":             true,
+	"order":             true,
+	"approval":          true,
+	"audit_log":         true,
+}
+
+type AccountHandler struct {
+	Store *store.Store
+}
+
+func NewAccountHandler(store *store.Store) AccountHandler {
+	return AccountHandler{Store: store}
+}
+
+// Timeline merges the account's activities, integration events, quote and order status
+// changes, approvals and audit entries. Sales users only see the deal-bound items of
+// opportunities they own or are on the team of.
+func (h AccountHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+
+	itemTypes, err := parseTimelineTypes(r.URL.Query()["type"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_type", err.Error())
+		return
+	}
+
+	limit := queryCursorLimit(r, 50)
+	params := dbgen.ListAccountTimelineParams{
+		TenantID:   toPGUUID(tenantID),
+		AccountID:  toPGUUID(accountID),
+		ItemTypes:  itemTypes,
+		LimitCount: limit + 1,
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, cursorErr := decodeCursor(raw)
+		if cursorErr == nil && !timelineItemTypes[cursor.Kind] {
+			cursorErr = errors.New("invalid cursor")
+		}
+		if cursorErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", cursorErr.Error())
+			return
+		}
+		params.CursorAt = toPGTimestamptz(cursor.At)
+		params.CursorType = toPGText(cursor.Kind)
+		params.CursorID = toPGText(cursor.ID)
+	}
+
+	var rows []dbgen.ListAccountTimelineRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetAccount(r.Context(), dbgen.GetAccountParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		visibleTo, queryErr := opportunityVisibility(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		params.VisibleTo = visibleTo
+		rows, queryErr = q.ListAccountTimeline(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "account not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "timeline_failed", "failed to load account timeline")
+		}
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(timeCursor{
+			At:   last.OccurredAt.Time,
+			Kind: last.ItemType,
+			ID:   last.ItemID,
+		})
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		metadata := map[string]any{}
+		_ = json.Unmarshal(row.Metadata, &metadata)
+		data = append(data, map[string]any{
+			"type":          row.ItemType,
+			"id":            row.ItemID,
+			"occurredAt":    row.OccurredAt.Time.UTC().Format(time.RFC3339Nano),
+			"opportunityId": pgUUIDToString(row.OpportunityID),
+			"entityType":    row.EntityType,
+			"entityId":      pgUUIDToString(row.EntityID),
+			"kind":          row.Kind,
+			"title":         row.Title,
+			"detail":        pgTextToString(row.Detail),
+			"actorUserId":   pgUUIDToString(row.ActorUserID),
+			"metadata":      metadata,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"limit":      limit,
+			"nextCursor": nextCursor,
+		},
+	})
+}
+
+func parseTimelineTypes(values []string) ([]string, error) {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			itemType := strings.ToLower(strings.TrimSpace(part))
+			if itemType == "" {
+				continue
+			}
+			if !timelineItemTypes[itemType] {
+				return nil, errors.New("type must be one of activity, integration_event, quote, order, approval, audit_log")
+			}
+			out = append(out, itemType)
+		}
+	}
+	return out, nil
+}
+
+func (h AccountHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Account
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetAccount(r.Context(), dbgen.GetAccountParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "account_get_failed", "failed to load account")
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": accountDTO(row)})
+}
+
+func (h AccountHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		OwnerUserID *string `json:"ownerUserId"`
+		Name        *string `json:"name"`
+		Industry    *string `json:"industry"`
+		Website     *string `json:"website"`
+		Phone       *string `json:"phone"`
+		Status      *string `json:"status"`
+		Memo        *string `json:"memo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateAccountParams{
+		TenantID:  toPGUUID(tenantID),
+		AccountID: toPGUUID(accountID),
+	}
+	if req.OwnerUserID != nil {
+		id, parseErr := parseUUID(*req.OwnerUserID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		params.OwnerUserID = toPGUUID(id)
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_name", "name must not be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	if req.Industry != nil {
+		params.Industry = pgtype.Text{String: *req.Industry, Valid: true}
+	}
+	if req.Website != nil {
+		params.Website = pgtype.Text{String: *req.Website, Valid: true}
+	}
+	if req.Phone != nil {
+		params.Phone = pgtype.Text{String: *req.Phone, Valid: true}
+	}
+	if req.Status != nil {
+		status, parseErr := parseAccountStatus(*req.Status)
+		if parseErr != nil || strings.TrimSpace(*req.Status) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_status", "status must be prospect, active or inactive")
+			return
+		}
+		params.Status = status
+	}
+	if req.Memo != nil {
+		params.Memo = pgtype.Text{String: *req.Memo, Valid: true}
+	}
+
+	var row dbgen.Account
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetAccountForUpdate(r.Context(), dbgen.GetAccountForUpdateParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.UpdateAccount(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "account not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "owner does not exist")
+		default:
+			writeError(w, http.StatusInternalServerError, "account_update_failed", "failed to update account")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": accountDTO(row)})
+}
+
+func (h AccountHandler) GetContact(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	contactID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_contact_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Contact
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetContact(r.Context(), dbgen.GetContactParams{
+			TenantID:  toPGUUID(tenantID),
+			ContactID: toPGUUID(contactID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "contact not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "contact_get_failed", "failed to load contact")
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": contactDTO(row)})
+}
+
+func (h AccountHandler) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	contactID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_contact_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		LocationID  *string `json:"locationId"`
+		OwnerUserID *string `json:"ownerUserId"`
+		FullName    *string `json:"fullName"`
+		Department  *string `json:"department"`
+		Title       *string `json:"title"`
+		Email       *string `json:"email"`
+		Phone       *string `json:"phone"`
+		IsPrimary   *bool   `json:"isPrimary"`
+		Memo        *string `json:"memo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateContactParams{
+		TenantID:  toPGUUID(tenantID),
+		ContactID: toPGUUID(contactID),
+	}
+	if req.LocationID != nil {
+		id, parseErr := parseUUID(*req.LocationID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_location_id", "locationId must be UUID")
+			return
+		}
+		params.LocationID = toPGUUID(id)
+	}
+	if req.OwnerUserID != nil {
+		id, parseErr := parseUUID(*req.OwnerUserID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		params.OwnerUserID = toPGUUID(id)
+	}
+	if req.FullName != nil {
+		if strings.TrimSpace(*req.FullName) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_full_name", "fullName must not be empty")
+			return
+		}
+		params.FullName = toPGText(strings.TrimSpace(*req.FullName))
+	}
+	if req.Department != nil {
+		params.Department = pgtype.Text{String: *req.Department, Valid: true}
+	}
+	if req.Title != nil {
+		params.Title = pgtype.Text{String: *req.Title, Valid: true}
+	}
+	if req.Email != nil {
+		params.Email = pgtype.Text{String: strings.TrimSpace(*req.Email), Valid: true}
+	}
+	if req.Phone != nil {
+		params.Phone = pgtype.Text{String: *req.Phone, Valid: true}
+	}
+	if req.IsPrimary != nil {
+		params.IsPrimary = pgtype.Bool{Bool: *req.IsPrimary, Valid: true}
+	}
+	if req.Memo != nil {
+		params.Memo = pgtype.Text{String: *req.Memo, Valid: true}
+	}
+
+	var row dbgen.Contact
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetContactForUpdate(r.Context(), dbgen.GetContactForUpdateParams{
+			TenantID:  toPGUUID(tenantID),
+			ContactID: toPGUUID(contactID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.UpdateContact(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "contact not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "location or owner does not exist")
+		default:
+			writeError(w, http.StatusInternalServerError, "contact_update_failed", "failed to update contact")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": contactDTO(row)})
+}
+
+func (h AccountHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.AccountLocation
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetAccount(r.Context(), dbgen.GetAccountParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListLocationsByAccount(r.Context(), dbgen.ListLocationsByAccountParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "location_list_failed", "failed to list locations")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, locationDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// CreateLocation adds a site to an account. isBilling moves the billing flag (the
+// addressee printed on quote PDFs) to the new location.
+func (h AccountHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Name         string `json:"name"`
+		Country      string `json:"country"`
+		PostalCode   string `json:"postalCode"`
+		Prefecture   string `json:"prefecture"`
+		City         string `json:"city"`
+		AddressLine1 string `json:"addressLine1"`
+		AddressLine2 string `json:"addressLine2"`
+		IsBilling    bool   `json:"isBilling"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "invalid_name", "name is required")
+		return
+	}
+
+	var row dbgen.AccountLocation
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetAccountForUpdate(r.Context(), dbgen.GetAccountForUpdateParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		if req.IsBilling {
+			if queryErr := q.ClearBillingLocation(r.Context(), dbgen.ClearBillingLocationParams{
+				TenantID:  toPGUUID(tenantID),
+				AccountID: toPGUUID(accountID),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.CreateLocation(r.Context(), dbgen.CreateLocationParams{
+			TenantID:     toPGUUID(tenantID),
+			AccountID:    toPGUUID(accountID),
+			Name:         name,
+			Country:      toPGText(strings.TrimSpace(req.Country)),
+			PostalCode:   toPGText(strings.TrimSpace(req.PostalCode)),
+			Prefecture:   toPGText(strings.TrimSpace(req.Prefecture)),
+			City:         toPGText(strings.TrimSpace(req.City)),
+			AddressLine1: toPGText(strings.TrimSpace(req.AddressLine1)),
+			AddressLine2: toPGText(strings.TrimSpace(req.AddressLine2)),
+			IsBilling:    req.IsBilling,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "account_location", uuid.UUID(row.ID.Bytes), map[string]any{
+			"accountId": accountID.String(),
+			"name":      row.Name,
+			"isBilling": row.IsBilling,
+		})
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "location_create_failed", "failed to create location")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": locationDTO(row)})
+}
+
+func accountDTO(row dbgen.Account) map[string]any {
+	return map[string]any{
+		"id":          pgUUIDToString(row.ID),
+		"ownerUserId": pgUUIDToString(row.OwnerUserID),
+		"name":        row.Name,
+		"industry":    pgTextToString(row.Industry),
+		"website":     pgTextToString(row.Website),
+		"phone":       pgTextToString(row.Phone),
+		"status":      string(row.Status),
+		"memo":        pgTextToString(row.Memo),
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+		"updatedAt":   pgTimestampToString(row.UpdatedAt),
+		"version":     row.Version,
+	}
+}
+
+func contactDTO(row dbgen.Contact) map[string]any {
+	return map[string]any{
+		"id":          pgUUIDToString(row.ID),
+		"accountId":   pgUUIDToString(row.AccountID),
+		"locationId":  pgUUIDToString(row.LocationID),
+		"ownerUserId": pgUUIDToString(row.OwnerUserID),
+		"fullName":    row.FullName,
+		"department":  pgTextToString(row.Department),
+		"title":       pgTextToString(row.Title),
+		"email":       pgTextToString(row.Email),
+		"phone":       pgTextToString(row.Phone),
+		"isPrimary":   row.IsPrimary,
+		"memo":        pgTextToString(row.Memo),
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+		"updatedAt":   pgTimestampToString(row.UpdatedAt),
+		"version":     row.Version,
+	}
+}
+
+func locationDTO(row dbgen.AccountLocation) map[string]any {
+	return map[string]any{
+		"id":           pgUUIDToString(row.ID),
+		"accountId":    pgUUIDToString(row.AccountID),
+		"name":         row.Name,
+		"country":      pgTextToString(row.Country),
+		"postalCode":   pgTextToString(row.PostalCode),
+		"prefecture":   pgTextToString(row.Prefecture),
+		"city":         pgTextToString(row.City),
+		"addressLine1": pgTextToString(row.AddressLine1),
+		"addressLine2": pgTextToString(row.AddressLine2),
+		"isBilling":    row.IsBilling,
+		"createdAt":    pgTimestampToString(row.CreatedAt),
+		"updatedAt":    pgTimestampToString(row.UpdatedAt),
+	}
+}
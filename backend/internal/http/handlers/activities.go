@@ -0,0 +1,692 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errTaskFieldsOnly = errors.New("assigneeUserId, dueAt, priority and recurrenceRule are only allowed on tasks")
+var errInvalidAssignee = errors.New("assignee is not an active member of this tenant")
+
+type ActivityHandler struct {
+	Store *store.Store
+}
+
+func NewActivityHandler(store *store.Store) ActivityHandler {
+	return ActivityHandler{Store: store}
+}
+
+type activityRequest struct {
+	ActivityType   string  `json:"activityType"`
+	Subject        *string `json:"subject"`
+	Detail         *string `json:"detail"`
+	ActivityAt     *string `json:"activityAt"`
+	AssigneeUserID *string `json:"assigneeUserId"`
+	DueAt          *string `json:"dueAt"`
+	Priority       *string `json:"priority"`
+	RecurrenceRule *string `json:"recurrenceRule"`
+	Completed      *bool   `json:"completed"`
+}
+
+func (req activityRequest) hasTaskFields() bool {
+	return req.AssigneeUserID != nil || req.DueAt != nil || req.Priority != nil || req.RecurrenceRule != nil
+}
+
+// recurrence parses recurrenceRule. set is false when the field is absent; an empty
+// string clears the rule.
+func (req activityRequest) recurrence() (rule pgtype.Text, set bool, err error) {
+	if req.RecurrenceRule == nil {
+		return pgtype.Text{}, false, nil
+	}
+	if strings.TrimSpace(*req.RecurrenceRule) == "" {
+		return pgtype.Text{}, true, nil
+	}
+	parsed, err := parseRecurrenceRule(*req.RecurrenceRule)
+	if err != nil {
+		return pgtype.Text{}, false, err
+	}
+	return toPGText(parsed.String()), true, nil
+}
+
+func (h ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 20)
+	activityType := dbgen.NullActivityTypeEnum{}
+	if raw := r.URL.Query().Get("type"); raw != "" {
+		parsed, parseErr := parseActivityType(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_type", parseErr.Error())
+			return
+		}
+		activityType = dbgen.NullActivityTypeEnum{ActivityTypeEnum: parsed, Valid: true}
+	}
+	var completed pgtype.Bool
+	switch r.URL.Query().Get("status") {
+	case "":
+	case "open":
+		completed = pgtype.Bool{Bool: false, Valid: true}
+	case "completed":
+		completed = pgtype.Bool{Bool: true, Valid: true}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_status", "status must be open or completed")
+		return
+	}
+
+	var rows []dbgen.Activity
+	var total int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, opportunityID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListActivitiesByOpportunity(r.Context(), dbgen.ListActivitiesByOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			ActivityType:  activityType,
+			Completed:     completed,
+			OffsetCount:   offset,
+			LimitCount:    limit,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		total, queryErr = q.CountActivitiesByOpportunity(r.Context(), dbgen.CountActivitiesByOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			ActivityType:  activityType,
+			Completed:     completed,
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "activity_list_failed", "failed to load activities")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, activityDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"page":  offset/limit + 1,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// Create logs an activity on the opportunity. Meetings, calls, emails and notes are
+// recorded as completed unless completed=false; tasks start open and default to the
+// caller as assignee.
+func (h ActivityHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req activityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	activityType, err := parseActivityType(req.ActivityType)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_activity_type", err.Error())
+		return
+	}
+	if req.Subject == nil || strings.TrimSpace(*req.Subject) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_subject", "subject is required")
+		return
+	}
+	isTask := activityType == dbgen.ActivityTypeEnumTask
+	if !isTask && req.hasTaskFields() {
+		writeError(w, http.StatusBadRequest, "invalid_activity", errTaskFieldsOnly.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	params := dbgen.CreateActivityParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: toPGUUID(opportunityID),
+		ActivityType:  activityType,
+		Subject:       strings.TrimSpace(*req.Subject),
+		ActivityAt:    toPGTimestamptz(now),
+		CreatedBy:     toPGUUID(actorID),
+	}
+	if req.Detail != nil {
+		params.Detail = toPGText(*req.Detail)
+	}
+	if req.ActivityAt != nil {
+		params.ActivityAt, err = parseOptionalTimestamp(*req.ActivityAt)
+		if err != nil || !params.ActivityAt.Valid {
+			writeError(w, http.StatusBadRequest, "invalid_activity_at", "activityAt must be RFC3339")
+			return
+		}
+	}
+
+	assigneeID := actorID
+	if isTask {
+		if req.AssigneeUserID != nil {
+			assigneeID, err = parseUUID(*req.AssigneeUserID)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_assignee_user_id", "assigneeUserId must be UUID")
+				return
+			}
+		}
+		params.AssigneeUserID = toPGUUID(assigneeID)
+		if req.DueAt != nil {
+			params.DueAt, err = parseOptionalTimestamp(*req.DueAt)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_due_at", "dueAt must be RFC3339 or YYYY-MM-DD")
+				return
+			}
+		}
+		priority := dbgen.TaskPriorityEnumNormal
+		if req.Priority != nil {
+			priority, err = parseTaskPriority(*req.Priority)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_priority", err.Error())
+				return
+			}
+		}
+		params.Priority = dbgen.NullTaskPriorityEnum{TaskPriorityEnum: priority, Valid: true}
+
+		rule, _, ruleErr := req.recurrence()
+		if ruleErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_recurrence_rule", ruleErr.Error())
+			return
+		}
+		if rule.Valid {
+			if !params.DueAt.Valid {
+				writeError(w, http.StatusBadRequest, "invalid_recurrence_rule", errRecurrenceNeedsDueAt.Error())
+				return
+			}
+			params.RecurrenceRule = rule
+			params.RecurrenceSeriesID = toPGUUID(uuid.New())
+			params.RecurrenceAnchor = params.DueAt
+			params.RecurrenceSeq = pgtype.Int4{Int32: 1, Valid: true}
+		}
+	}
+
+	completed := !isTask
+	if req.Completed != nil {
+		completed = *req.Completed
+	}
+	if completed {
+		params.CompletedAt = params.ActivityAt
+		if isTask {
+			params.CompletedAt = toPGTimestamptz(now)
+		}
+		params.CompletedBy = toPGUUID(actorID)
+	}
+
+	var row dbgen.Activity
+	var next *dbgen.Activity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, opportunityID); queryErr != nil {
+			return queryErr
+		}
+		if isTask {
+			if queryErr := checkAssignee(r.Context(), q, tenantID, assigneeID); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.CreateActivity(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if row.CompletedAt.Valid {
+			if next, queryErr = createNextOccurrence(r.Context(), q, row, actorID); queryErr != nil {
+				return queryErr
+			}
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "activity", uuid.UUID(row.ID.Bytes), map[string]any{
+			"opportunityId": opportunityID.String(),
+			"activityType":  string(row.ActivityType),
+			"subject":       row.Subject,
+		})
+	}); err != nil {
+		writeActivityError(w, err, "activity_create_failed", "failed to create activity")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"data": activityDTO(row),
+		"meta": map[string]any{"nextOccurrence": nextOccurrenceDTO(next)},
+	})
+}
+
+// Update edits an activity. The task's assignee may update and complete it even
+// without access to the opportunity.
+func (h ActivityHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	activityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_activity_id", "id must be UUID")
+		return
+	}
+
+	var req activityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if req.ActivityType != "" {
+		writeError(w, http.StatusBadRequest, "invalid_activity_type", "activityType cannot be changed")
+		return
+	}
+
+	params := dbgen.UpdateActivityParams{
+		TenantID:    toPGUUID(tenantID),
+		ActivityID:  toPGUUID(activityID),
+		ActorUserID: toPGUUID(actorID),
+	}
+	if req.Subject != nil {
+		if strings.TrimSpace(*req.Subject) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_subject", "subject must not be empty")
+			return
+		}
+		params.Subject = toPGText(strings.TrimSpace(*req.Subject))
+	}
+	if req.Detail != nil {
+		params.Detail = pgtype.Text{String: *req.Detail, Valid: true}
+	}
+	if req.ActivityAt != nil {
+		params.ActivityAt, err = parseOptionalTimestamp(*req.ActivityAt)
+		if err != nil || !params.ActivityAt.Valid {
+			writeError(w, http.StatusBadRequest, "invalid_activity_at", "activityAt must be RFC3339")
+			return
+		}
+	}
+	var assigneeID uuid.UUID
+	if req.AssigneeUserID != nil {
+		assigneeID, err = parseUUID(*req.AssigneeUserID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_assignee_user_id", "assigneeUserId must be UUID")
+			return
+		}
+		params.AssigneeUserID = toPGUUID(assigneeID)
+	}
+	if req.DueAt != nil {
+		params.DueAt, err = parseOptionalTimestamp(*req.DueAt)
+		if err != nil || !params.DueAt.Valid {
+			writeError(w, http.StatusBadRequest, "invalid_due_at", "dueAt must be RFC3339 or YYYY-MM-DD")
+			return
+		}
+	}
+	if req.Priority != nil {
+		priority, parseErr := parseTaskPriority(*req.Priority)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_priority", parseErr.Error())
+			return
+		}
+		params.Priority = dbgen.NullTaskPriorityEnum{TaskPriorityEnum: priority, Valid: true}
+	}
+	if req.Completed != nil {
+		params.Completed = pgtype.Bool{Bool: *req.Completed, Valid: true}
+	}
+	rule, setRule, err := req.recurrence()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_recurrence_rule", err.Error())
+		return
+	}
+
+	var row dbgen.Activity
+	var next *dbgen.Activity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetActivityForUpdate(r.Context(), dbgen.GetActivityForUpdateParams{
+			TenantID:   toPGUUID(tenantID),
+			ActivityID: toPGUUID(activityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if current.ActivityType != dbgen.ActivityTypeEnumTask && req.hasTaskFields() {
+			return errTaskFieldsOnly
+		}
+		if current.AssigneeUserID != toPGUUID(actorID) {
+			if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+				return queryErr
+			}
+		}
+		if req.AssigneeUserID != nil {
+			if queryErr := checkAssignee(r.Context(), q, tenantID, assigneeID); queryErr != nil {
+				return queryErr
+			}
+		}
+		row, queryErr = q.UpdateActivity(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if setRule {
+			if rule.Valid && !row.DueAt.Valid {
+				return errRecurrenceNeedsDueAt
+			}
+			row, queryErr = q.SetActivityRecurrence(r.Context(), dbgen.SetActivityRecurrenceParams{
+				RecurrenceRule: rule,
+				SeriesID:       toPGUUID(uuid.New()),
+				TenantID:       toPGUUID(tenantID),
+				ActivityID:     toPGUUID(activityID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+		}
+		if !current.CompletedAt.Valid && row.CompletedAt.Valid {
+			if next, queryErr = createNextOccurrence(r.Context(), q, row, actorID); queryErr != nil {
+				return queryErr
+			}
+		}
+		metadata := map[string]any{"opportunityId": pgUUIDToString(row.OpportunityID)}
+		if req.Completed != nil {
+			metadata["completed"] = *req.Completed
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "activity", activityID, metadata)
+	}); err != nil {
+		writeActivityError(w, err, "activity_update_failed", "failed to update activity")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": activityDTO(row),
+		"meta": map[string]any{"nextOccurrence": nextOccurrenceDTO(next)},
+	})
+}
+
+func (h ActivityHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	activityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_activity_id", "id must be UUID")
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetActivityForUpdate(r.Context(), dbgen.GetActivityForUpdateParams{
+			TenantID:   toPGUUID(tenantID),
+			ActivityID: toPGUUID(activityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.DeleteActivity(r.Context(), dbgen.DeleteActivityParams{
+			TenantID:   toPGUUID(tenantID),
+			ActivityID: toPGUUID(activityID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumDelete, "activity", activityID, map[string]any{
+			"opportunityId": pgUUIDToString(current.OpportunityID),
+			"activityType":  string(current.ActivityType),
+			"subject":       current.Subject,
+		})
+	}); err != nil {
+		writeActivityError(w, err, "activity_delete_failed", "failed to delete activity")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MyTasks lists the caller's open tasks across all opportunities, earliest due first.
+func (h ActivityHandler) MyTasks(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 50)
+	overdueOnly := r.URL.Query().Get("overdue") == "true"
+	dueBefore, err := parseOptionalTimestamp(r.URL.Query().Get("dueBefore"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_due_before", "dueBefore must be RFC3339 or YYYY-MM-DD")
+		return
+	}
+
+	var rows []dbgen.ListOpenTasksByAssigneeRow
+	var counts dbgen.CountOpenTasksByAssigneeRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListOpenTasksByAssignee(r.Context(), dbgen.ListOpenTasksByAssigneeParams{
+			TenantID:       toPGUUID(tenantID),
+			AssigneeUserID: toPGUUID(actorID),
+			OverdueOnly:    overdueOnly,
+			DueBefore:      dueBefore,
+			OffsetCount:    offset,
+			LimitCount:     limit,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		counts, queryErr = q.CountOpenTasksByAssignee(r.Context(), dbgen.CountOpenTasksByAssigneeParams{
+			TenantID:       toPGUUID(tenantID),
+			AssigneeUserID: toPGUUID(actorID),
+			OverdueOnly:    overdueOnly,
+			DueBefore:      dueBefore,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "task_list_failed", "failed to load tasks")
+		return
+	}
+
+	now := time.Now()
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		item := activityDTO(dbgen.Activity{
+			ID:                 row.ID,
+			TenantID:           row.TenantID,
+			OpportunityID:      row.OpportunityID,
+			ActivityType:       row.ActivityType,
+			Subject:            row.Subject,
+			Detail:             row.Detail,
+			ActivityAt:         row.ActivityAt,
+			CreatedBy:          row.CreatedBy,
+			CreatedAt:          row.CreatedAt,
+			AssigneeUserID:     row.AssigneeUserID,
+			DueAt:              row.DueAt,
+			Priority:           row.Priority,
+			CompletedAt:        row.CompletedAt,
+			CompletedBy:        row.CompletedBy,
+			UpdatedAt:          row.UpdatedAt,
+			RecurrenceRule:     row.RecurrenceRule,
+			RecurrenceSeriesID: row.RecurrenceSeriesID,
+			RecurrenceAnchor:   row.RecurrenceAnchor,
+			RecurrenceSeq:      row.RecurrenceSeq,
+			SourceEventID:      row.SourceEventID,
+			ExternalEventID:    row.ExternalEventID,
+		})
+		item["opportunityName"] = row.OpportunityName
+		item["accountId"] = pgUUIDToString(row.AccountID)
+		item["overdue"] = row.DueAt.Valid && row.DueAt.Time.Before(now)
+		data = append(data, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"page":         offset/limit + 1,
+			"limit":        limit,
+			"total":        counts.OpenCount,
+			"overdueCount": counts.OverdueCount,
+		},
+	})
+}
+
+func loadAuthorizedOpportunity(ctx context.Context, q *dbgen.Queries, tenantID, actorID, opportunityID uuid.UUID) error {
+	opportunity, err := q.GetOpportunity(ctx, dbgen.GetOpportunityParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: toPGUUID(opportunityID),
+	})
+	if err != nil {
+		return err
+	}
+	return authorizeOpportunity(ctx, q, tenantID, actorID, opportunity)
+}
+
+func checkAssignee(ctx context.Context, q *dbgen.Queries, tenantID, assigneeID uuid.UUID) error {
+	if _, err := actorRole(ctx, q, tenantID, assigneeID); err != nil {
+		if errors.Is(err, errNotTenantMember) {
+			return errInvalidAssignee
+		}
+		return err
+	}
+	return nil
+}
+
+func writeActivityError(w http.ResponseWriter, err error, code, message string) {
+	switch {
+	case errors.Is(err, errTaskFieldsOnly), errors.Is(err, errInvalidAssignee), errors.Is(err, errRecurrenceNeedsDueAt):
+		writeError(w, http.StatusBadRequest, "invalid_activity", err.Error())
+	case errors.Is(err, pgx.ErrNoRows):
+		writeError(w, http.StatusNotFound, "not_found", "activity or opportunity not found")
+	case isAccessDenied(err):
+		writeError(w, http.StatusForbidden, "forbidden", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, code, message)
+	}
+}
+
+func parseActivityType(raw string) (dbgen.ActivityTypeEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "meeting":
+		return dbgen.ActivityTypeEnumMeeting, nil
+	case "call":
+		return dbgen.ActivityTypeEnumCall, nil
+	case "email":
+		return dbgen.ActivityTypeEnumEmail, nil
+	case "note":
+		return dbgen.ActivityTypeEnumNote, nil
+	case "task":
+		return dbgen.ActivityTypeEnumTask, nil
+	default:
+		return "", errors.New("activityType must be meeting, call, email, note, or task")
+	}
+}
+
+func parseTaskPriority(raw string) (dbgen.TaskPriorityEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "low":
+		return dbgen.TaskPriorityEnumLow, nil
+	case "normal":
+		return dbgen.TaskPriorityEnumNormal, nil
+	case "high":
+		return dbgen.TaskPriorityEnumHigh, nil
+	default:
+		return "", errors.New("priority must be low, normal, or high")
+	}
+}
+
+func activityDTO(row dbgen.Activity) map[string]any {
+	priority := ""
+	if row.Priority.Valid {
+		priority = string(row.Priority.TaskPriorityEnum)
+	}
+	var sourceEventID any
+	if row.SourceEventID.Valid {
+		sourceEventID = row.SourceEventID.Int64
+	}
+	return map[string]any{
+		"id":              pgUUIDToString(row.ID),
+		"opportunityId":   pgUUIDToString(row.OpportunityID),
+		"activityType":    string(row.ActivityType),
+		"subject":         row.Subject,
+		"detail":          pgTextToString(row.Detail),
+		"activityAt":      pgTimestampToString(row.ActivityAt),
+		"assigneeUserId":  pgUUIDToString(row.AssigneeUserID),
+		"dueAt":           pgTimestampToString(row.DueAt),
+		"priority":        priority,
+		"completed":       row.CompletedAt.Valid,
+		"completedAt":     pgTimestampToString(row.CompletedAt),
+		"completedBy":     pgUUIDToString(row.CompletedBy),
+		"recurrenceRule":  pgTextToString(row.RecurrenceRule),
+		"recurrenceSeq":   row.RecurrenceSeq.Int32,
+		"seriesId":        pgUUIDToString(row.RecurrenceSeriesID),
+		"sourceEventId":   sourceEventID,
+		"externalEventId": pgTextToString(row.ExternalEventID),
+		"createdBy":       pgUUIDToString(row.CreatedBy),
+		"createdAt":       pgTimestampToString(row.CreatedAt),
+		"updatedAt":       pgTimestampToString(row.UpdatedAt),
+	}
+}
+
+func nextOccurrenceDTO(row *dbgen.Activity) any {
+	if row == nil {
+		return nil
+	}
+	return activityDTO(*row)
+}
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errTemplateInactive = errors.New("activity template is inactive")
+
+type templateStepError struct {
+	Index   int
+	Message string
+}
+
+func (e templateStepError) Error() string {
+	return fmt.Sprintf("steps[%d]: %s", e.Index, e.Message)
+}
+
+type templateStepRequest struct {
+	Subject        string  `json:"subject"`
+	Detail         *string `json:"detail"`
+	OffsetDays     int32   `json:"offsetDays"`
+	Priority       string  `json:"priority"`
+	RecurrenceRule string  `json:"recurrenceRule"`
+}
+
+func (h ActivityHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var isActive pgtype.Bool
+	switch r.URL.Query().Get("active") {
+	case "":
+	case "true":
+		isActive = pgtype.Bool{Bool: true, Valid: true}
+	case "false":
+		isActive = pgtype.Bool{Bool: false, Valid: true}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_active", "active must be true or false")
+		return
+	}
+
+	var rows []dbgen.ListActivityTemplatesRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListActivityTemplates(r.Context(), dbgen.ListActivityTemplatesParams{
+			TenantID: toPGUUID(tenantID),
+			IsActive: isActive,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "activity_template_list_failed", "failed to load activity templates")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		item := activityTemplateDTO(dbgen.ActivityTemplate{
+			ID:          row.ID,
+			TenantID:    row.TenantID,
+			Name:        row.Name,
+			Description: row.Description,
+			IsActive:    row.IsActive,
+			CreatedBy:   row.CreatedBy,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		}, nil)
+		delete(item, "steps")
+		item["stepCount"] = row.StepCount
+		data = append(data, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h ActivityHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	templateID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_id", "id must be UUID")
+		return
+	}
+
+	var template dbgen.ActivityTemplate
+	var steps []dbgen.ActivityTemplateStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		template, steps, queryErr = loadActivityTemplate(r, q, tenantID, templateID)
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "activity template not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "activity_template_get_failed", "failed to load activity template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": activityTemplateDTO(template, steps)})
+}
+
+func (h ActivityHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		Name        string                `json:"name"`
+		Description *string               `json:"description"`
+		Steps       []templateStepRequest `json:"steps"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "invalid_name", "name is required")
+		return
+	}
+	steps, err := parseTemplateSteps(req.Steps)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_step", err.Error())
+		return
+	}
+
+	var template dbgen.ActivityTemplate
+	var savedSteps []dbgen.ActivityTemplateStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		params := dbgen.CreateActivityTemplateParams{
+			TenantID:  toPGUUID(tenantID),
+			Name:      name,
+			CreatedBy: toPGUUID(actorID),
+		}
+		if req.Description != nil {
+			params.Description = toPGText(*req.Description)
+		}
+		var queryErr error
+		template, queryErr = q.CreateActivityTemplate(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		savedSteps, queryErr = replaceTemplateSteps(r, q, tenantID, template.ID, steps)
+		return queryErr
+	}); err != nil {
+		switch {
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_activity_template", "activity template name already exists")
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "user does not exist")
+		default:
+			writeError(w, http.StatusInternalServerError, "activity_template_create_failed", "failed to create activity template")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": activityTemplateDTO(template, savedSteps)})
+}
+
+// UpdateTemplate edits the template header; steps, when sent, replace the whole list.
+// Tasks already created from the template are not touched.
+func (h ActivityHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	templateID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Name        *string                `json:"name"`
+		Description *string                `json:"description"`
+		IsActive    *bool                  `json:"isActive"`
+		Steps       *[]templateStepRequest `json:"steps"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateActivityTemplateParams{
+		TenantID:   toPGUUID(tenantID),
+		TemplateID: toPGUUID(templateID),
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_name", "name must not be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	if req.Description != nil {
+		params.Description = pgtype.Text{String: *req.Description, Valid: true}
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+	var steps []dbgen.CreateActivityTemplateStepParams
+	if req.Steps != nil {
+		steps, err = parseTemplateSteps(*req.Steps)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_template_step", err.Error())
+			return
+		}
+	}
+
+	var template dbgen.ActivityTemplate
+	var savedSteps []dbgen.ActivityTemplateStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		template, queryErr = q.UpdateActivityTemplate(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if req.Steps != nil {
+			savedSteps, queryErr = replaceTemplateSteps(r, q, tenantID, template.ID, steps)
+			return queryErr
+		}
+		savedSteps, queryErr = q.ListActivityTemplateSteps(r.Context(), dbgen.ListActivityTemplateStepsParams{
+			TenantID:   toPGUUID(tenantID),
+			TemplateID: template.ID,
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "activity template not found")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_activity_template", "activity template name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "activity_template_update_failed", "failed to update activity template")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": activityTemplateDTO(template, savedSteps)})
+}
+
+// ApplyTemplate creates one task per template step on the opportunity. Each step is due
+// offsetDays after startAt and keeps its recurrence rule.
+func (h ActivityHandler) ApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		TemplateID     string  `json:"templateId"`
+		StartAt        string  `json:"startAt"`
+		AssigneeUserID *string `json:"assigneeUserId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	templateID, err := parseUUID(req.TemplateID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_id", "templateId must be UUID")
+		return
+	}
+	startAt := toPGTimestamptz(time.Now().UTC())
+	if req.StartAt != "" {
+		startAt, err = parseOptionalTimestamp(req.StartAt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_start_at", "startAt must be RFC3339 or YYYY-MM-DD")
+			return
+		}
+	}
+	var assigneeID uuid.UUID
+	if req.AssigneeUserID != nil {
+		assigneeID, err = parseUUID(*req.AssigneeUserID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_assignee_user_id", "assigneeUserId must be UUID")
+			return
+		}
+	}
+
+	var created []dbgen.Activity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		if req.AssigneeUserID == nil {
+			assigneeID = uuid.UUID(opportunity.OwnerUserID.Bytes)
+		}
+		if queryErr := checkAssignee(r.Context(), q, tenantID, assigneeID); queryErr != nil {
+			return queryErr
+		}
+
+		template, steps, queryErr := loadActivityTemplate(r, q, tenantID, templateID)
+		if queryErr != nil {
+			return queryErr
+		}
+		if !template.IsActive {
+			return errTemplateInactive
+		}
+
+		for _, step := range steps {
+			due := toPGTimestamptz(startAt.Time.AddDate(0, 0, int(step.OffsetDays)))
+			params := dbgen.CreateActivityParams{
+				TenantID:       toPGUUID(tenantID),
+				OpportunityID:  toPGUUID(opportunityID),
+				ActivityType:   dbgen.ActivityTypeEnumTask,
+				Subject:        step.Subject,
+				Detail:         step.Detail,
+				ActivityAt:     due,
+				AssigneeUserID: toPGUUID(assigneeID),
+				DueAt:          due,
+				Priority:       dbgen.NullTaskPriorityEnum{TaskPriorityEnum: step.Priority, Valid: true},
+				CreatedBy:      toPGUUID(actorID),
+			}
+			if step.RecurrenceRule.Valid {
+				params.RecurrenceRule = step.RecurrenceRule
+				params.RecurrenceSeriesID = toPGUUID(uuid.New())
+				params.RecurrenceAnchor = due
+				params.RecurrenceSeq = pgtype.Int4{Int32: 1, Valid: true}
+			}
+			row, queryErr := q.CreateActivity(r.Context(), params)
+			if queryErr != nil {
+				return queryErr
+			}
+			created = append(created, row)
+		}
+
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "opportunity", opportunityID, map[string]any{
+			"event":        "activity_template_applied",
+			"templateId":   templateID.String(),
+			"templateName": template.Name,
+			"taskCount":    len(created),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errTemplateInactive):
+			writeError(w, http.StatusConflict, "template_inactive", err.Error())
+		default:
+			writeActivityError(w, err, "activity_template_apply_failed", "failed to apply activity template")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(created))
+	for _, row := range created {
+		data = append(data, activityDTO(row))
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"data": data})
+}
+
+func parseTemplateSteps(steps []templateStepRequest) ([]dbgen.CreateActivityTemplateStepParams, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("steps must contain at least one task")
+	}
+	out := make([]dbgen.CreateActivityTemplateStepParams, len(steps))
+	for i, step := range steps {
+		subject := strings.TrimSpace(step.Subject)
+		if subject == "" {
+			return nil, templateStepError{Index: i, Message: "subject is required"}
+		}
+		if step.OffsetDays < 0 {
+			return nil, templateStepError{Index: i, Message: "offsetDays must be zero or greater"}
+		}
+		priority := dbgen.TaskPriorityEnumNormal
+		if step.Priority != "" {
+			parsed, err := parseTaskPriority(step.Priority)
+			if err != nil {
+				return nil, templateStepError{Index: i, Message: err.Error()}
+			}
+			priority = parsed
+		}
+		out[i] = dbgen.CreateActivityTemplateStepParams{
+			Position:   int32(i + 1),
+			Subject:    subject,
+			OffsetDays: step.OffsetDays,
+			Priority:   priority,
+		}
+		if step.Detail != nil {
+			out[i].Detail = toPGText(*step.Detail)
+		}
+		if strings.TrimSpace(step.RecurrenceRule) != "" {
+			rule, err := parseRecurrenceRule(step.RecurrenceRule)
+			if err != nil {
+				return nil, templateStepError{Index: i, Message: err.Error()}
+			}
+			out[i].RecurrenceRule = toPGText(rule.String())
+		}
+	}
+	return out, nil
+}
+
+func replaceTemplateSteps(r *http.Request, q *dbgen.Queries, tenantID uuid.UUID, templateID pgtype.UUID, steps []dbgen.CreateActivityTemplateStepParams) ([]dbgen.ActivityTemplateStep, error) {
+	if err := q.DeleteActivityTemplateSteps(r.Context(), dbgen.DeleteActivityTemplateStepsParams{
+		TenantID:   toPGUUID(tenantID),
+		TemplateID: templateID,
+	}); err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		step.TenantID = toPGUUID(tenantID)
+		step.TemplateID = templateID
+		if err := q.CreateActivityTemplateStep(r.Context(), step); err != nil {
+			return nil, err
+		}
+	}
+	return q.ListActivityTemplateSteps(r.Context(), dbgen.ListActivityTemplateStepsParams{
+		TenantID:   toPGUUID(tenantID),
+		TemplateID: templateID,
+	})
+}
+
+func loadActivityTemplate(r *http.Request, q *dbgen.Queries, tenantID, templateID uuid.UUID) (dbgen.ActivityTemplate, []dbgen.ActivityTemplateStep, error) {
+	template, err := q.GetActivityTemplate(r.Context(), dbgen.GetActivityTemplateParams{
+		TenantID:   toPGUUID(tenantID),
+		TemplateID: toPGUUID(templateID),
+	})
+	if err != nil {
+		return template, nil, err
+	}
+	steps, err := q.ListActivityTemplateSteps(r.Context(), dbgen.ListActivityTemplateStepsParams{
+		TenantID:   toPGUUID(tenantID),
+		TemplateID: template.ID,
+	})
+	return template, steps, err
+}
+
+func activityTemplateDTO(row dbgen.ActivityTemplate, steps []dbgen.ActivityTemplateStep) map[string]any {
+	stepData := make([]map[string]any, 0, len(steps))
+	for _, step := range steps {
+		stepData = append(stepData, map[string]any{
+			"position":       step.Position,
+			"subject":        step.Subject,
+			"detail":         pgTextToString(step.Detail),
+			"offsetDays":     step.OffsetDays,
+			"priority":       string(step.Priority),
+			"recurrenceRule": pgTextToString(step.RecurrenceRule),
+		})
+	}
+	return map[string]any{
+		"id":          pgUUIDToString(row.ID),
+		"name":        row.Name,
+		"description": pgTextToString(row.Description),
+		"isActive":    row.IsActive,
+		"createdBy":   pgUUIDToString(row.CreatedBy),
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+		"updatedAt":   pgTimestampToString(row.UpdatedAt),
+		"steps":       stepData,
+	}
+}
@@ -0,0 +1,1209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errApprovalChainEmpty = errors.New("no step of the approval chain applies to this request")
+var errApprovalNoApprover = errors.New("no active approver could be resolved for the approval step")
+var errApprovalNotPending = errors.New("approval request is no longer pending")
+var errNotApprover = errors.New("user is not the approver of this request or a delegate standing in")
+var errSelfApproval = errors.New("requesters cannot decide their own approval requests")
+var errNotRequester = errors.New("only the requester can withdraw an approval request")
+var errInvalidApprovalChain = errors.New("invalid approval chain")
+var errDelegationOverlap = errors.New("an out-of-office delegation already covers part of this period")
+
+// maxApprovalChainSteps keeps chains reviewable; real processes rarely need more than a handful.
+const maxApprovalChainSteps = 10
+
+type ApprovalChainHandler struct {
+	Store *store.Store
+}
+
+func NewApprovalChainHandler(store *store.Store) ApprovalChainHandler {
+	return ApprovalChainHandler{Store: store}
+}
+
+type approvalChainRequest struct {
+	Name        *string                     `json:"name"`
+	Description *string                     `json:"description"`
+	IsActive    *bool                       `json:"isActive"`
+	Steps       *[]approvalChainStepRequest `json:"steps"`
+}
+
+type approvalChainStepRequest struct {
+	Name             string   `json:"name"`
+	ApproverType     string   `json:"approverType"`
+	ApproverUserID   string   `json:"approverUserId"`
+	ApproverRole     string   `json:"approverRole"`
+	ApproverTeamRole string   `json:"approverTeamRole"`
+	MinValue         *float64 `json:"minValue"`
+	SLAHours         *int32   `json:"slaHours"`
+	EscalationUserID string   `json:"escalationUserId"`
+}
+
+// approverRule names who decides a step; it is shared by chain steps and the copies
+// made on each request.
+type approverRule struct {
+	Type     dbgen.ApprovalApproverTypeEnum
+	UserID   pgtype.UUID
+	Role     dbgen.NullRoleEnum
+	TeamRole dbgen.NullOpportunityTeamRoleEnum
+}
+
+// validate trims the chain fields and turns the steps into insert parameters ordered
+// as given. It returns the error code and message for the first invalid field.
+func (req *approvalChainRequest) validate(tenantID uuid.UUID) ([]dbgen.CreateApprovalChainStepParams, string, string) {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			return nil, "invalid_name", "name must not be empty"
+		}
+	}
+	if req.Steps == nil {
+		return nil, "", ""
+	}
+	steps := *req.Steps
+	if len(steps) == 0 || len(steps) > maxApprovalChainSteps {
+		return nil, "invalid_steps", fmt.Sprintf("a chain needs between 1 and %d steps", maxApprovalChainSteps)
+	}
+	params := make([]dbgen.CreateApprovalChainStepParams, 0, len(steps))
+	for i, step := range steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		name := strings.TrimSpace(step.Name)
+		if name == "" {
+			return nil, "invalid_steps", field + ".name must not be empty"
+		}
+		rule, err := parseApproverRule(step.ApproverType, step.ApproverUserID, step.ApproverRole, step.ApproverTeamRole)
+		if err != nil {
+			return nil, "invalid_steps", field + ": " + err.Error()
+		}
+		param := dbgen.CreateApprovalChainStepParams{
+			TenantID:         toPGUUID(tenantID),
+			StepOrder:        int32(i + 1),
+			Name:             name,
+			ApproverType:     rule.Type,
+			ApproverUserID:   rule.UserID,
+			ApproverRole:     rule.Role,
+			ApproverTeamRole: rule.TeamRole,
+		}
+		if step.MinValue != nil {
+			if i == 0 {
+				return nil, "invalid_steps", "the first step always applies and cannot have a minValue"
+			}
+			if *step.MinValue < 0 {
+				return nil, "invalid_steps", field + ".minValue must be zero or greater"
+			}
+			param.MinValue = toPGNumeric(*step.MinValue)
+		}
+		if step.SLAHours != nil {
+			if *step.SLAHours <= 0 || *step.SLAHours > 24*30 {
+				return nil, "invalid_steps", field + ".slaHours must be between 1 and 720"
+			}
+			param.SlaHours = pgtype.Int4{Int32: *step.SLAHours, Valid: true}
+		}
+		if strings.TrimSpace(step.EscalationUserID) != "" {
+			escalationID, err := parseUUID(step.EscalationUserID)
+			if err != nil {
+				return nil, "invalid_steps", field + ".escalationUserId must be UUID"
+			}
+			param.EscalationUserID = toPGUUID(escalationID)
+		}
+		params = append(params, param)
+	}
+	return params, "", ""
+}
+
+func parseApproverRule(rawType, rawUserID, rawRole, rawTeamRole string) (approverRule, error) {
+	switch strings.ToLower(strings.TrimSpace(rawType)) {
+	case "user":
+		userID, err := parseUUID(rawUserID)
+		if err != nil {
+			return approverRule{}, errors.New("approverUserId must be UUID for user approvers")
+		}
+		return approverRule{Type: dbgen.ApprovalApproverTypeEnumUser, UserID: toPGUUID(userID)}, nil
+	case "role":
+		role, err := parseMemberRole(rawRole)
+		if err != nil {
+			return approverRule{}, err
+		}
+		return approverRule{Type: dbgen.ApprovalApproverTypeEnumRole, Role: dbgen.NullRoleEnum{RoleEnum: role, Valid: true}}, nil
+	case "team":
+		teamRole, err := parseTeamRole(rawTeamRole)
+		if err != nil {
+			return approverRule{}, errors.New("approverTeamRole: " + err.Error())
+		}
+		return approverRule{Type: dbgen.ApprovalApproverTypeEnumTeam, TeamRole: dbgen.NullOpportunityTeamRoleEnum{OpportunityTeamRoleEnum: teamRole, Valid: true}}, nil
+	default:
+		return approverRule{}, errors.New("approverType must be user, role, or team")
+	}
+}
+
+func parseMemberRole(raw string) (dbgen.RoleEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "admin":
+		return dbgen.RoleEnumAdmin, nil
+	case "manager":
+		return dbgen.RoleEnumManager, nil
+	case "sales":
+		return dbgen.RoleEnumSales, nil
+	default:
+		return "", errors.New("approverRole must be admin, manager, or sales")
+	}
+}
+
+func (h ApprovalChainHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var chains []dbgen.ApprovalChain
+	var steps []dbgen.ApprovalChainStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		chains, queryErr = q.ListApprovalChains(r.Context(), dbgen.ListApprovalChainsParams{
+			TenantID:   toPGUUID(tenantID),
+			ActiveOnly: r.URL.Query().Get("active") == "true",
+		})
+		if queryErr != nil || len(chains) == 0 {
+			return queryErr
+		}
+		chainIDs := make([]pgtype.UUID, 0, len(chains))
+		for _, chain := range chains {
+			chainIDs = append(chainIDs, chain.ID)
+		}
+		steps, queryErr = q.ListApprovalChainSteps(r.Context(), dbgen.ListApprovalChainStepsParams{
+			TenantID: toPGUUID(tenantID),
+			ChainIds: chainIDs,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "approval_chain_list_failed", "failed to list approval chains")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(chains))
+	for _, chain := range chains {
+		data = append(data, approvalChainDTO(chain, steps))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h ApprovalChainHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	chainID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_chain_id", "id must be UUID")
+		return
+	}
+
+	var chain dbgen.ApprovalChain
+	var steps []dbgen.ApprovalChainStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		chain, queryErr = q.GetApprovalChain(r.Context(), dbgen.GetApprovalChainParams{
+			TenantID: toPGUUID(tenantID),
+			ChainID:  toPGUUID(chainID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		steps, queryErr = q.ListApprovalChainSteps(r.Context(), dbgen.ListApprovalChainStepsParams{
+			TenantID: toPGUUID(tenantID),
+			ChainIds: []pgtype.UUID{chain.ID},
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "approval chain not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "approval_chain_get_failed", "failed to load approval chain")
+		return
+	}
+
+	setETag(w, chain.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": approvalChainDTO(chain, steps)})
+}
+
+func (h ApprovalChainHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req approvalChainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if req.Name == nil || req.Steps == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "name and steps are required")
+		return
+	}
+	stepParams, code, message := req.validate(tenantID)
+	if code != "" {
+		writeError(w, http.StatusBadRequest, code, message)
+		return
+	}
+
+	var chain dbgen.ApprovalChain
+	var steps []dbgen.ApprovalChainStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		chain, queryErr = q.CreateApprovalChain(r.Context(), dbgen.CreateApprovalChainParams{
+			TenantID:    toPGUUID(tenantID),
+			Name:        *req.Name,
+			Description: toPGText(derefString(req.Description)),
+			IsActive:    req.IsActive == nil || *req.IsActive,
+			CreatedBy:   toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		steps, queryErr = createApprovalChainSteps(r.Context(), q, tenantID, chain.ID, stepParams)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "approval_chain", uuid.UUID(chain.ID.Bytes), map[string]any{
+			"name":  chain.Name,
+			"steps": len(steps),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errNotTenantMember):
+			writeError(w, http.StatusBadRequest, "invalid_approver", "step approvers must be active members of this tenant")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_approval_chain", "approval chain name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "approval_chain_create_failed", "failed to create approval chain")
+		}
+		return
+	}
+
+	setETag(w, chain.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": approvalChainDTO(chain, steps)})
+}
+
+// Update changes a chain; when steps are given they replace the old ones. Requests
+// already in flight keep the steps they were raised with.
+func (h ApprovalChainHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	chainID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_chain_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req approvalChainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	stepParams, code, message := req.validate(tenantID)
+	if code != "" {
+		writeError(w, http.StatusBadRequest, code, message)
+		return
+	}
+
+	var chain dbgen.ApprovalChain
+	var steps []dbgen.ApprovalChainStep
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetApprovalChainForUpdate(r.Context(), dbgen.GetApprovalChainForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			ChainID:  toPGUUID(chainID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+
+		params := dbgen.UpdateApprovalChainParams{
+			TenantID: toPGUUID(tenantID),
+			ChainID:  toPGUUID(chainID),
+		}
+		if req.Name != nil {
+			params.Name = toPGText(*req.Name)
+		}
+		if req.Description != nil {
+			params.Description = pgtype.Text{String: strings.TrimSpace(*req.Description), Valid: true}
+		}
+		if req.IsActive != nil {
+			params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+		}
+		chain, queryErr = q.UpdateApprovalChain(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if stepParams != nil {
+			if queryErr := q.DeleteApprovalChainSteps(r.Context(), dbgen.DeleteApprovalChainStepsParams{
+				TenantID: toPGUUID(tenantID),
+				ChainID:  chain.ID,
+			}); queryErr != nil {
+				return queryErr
+			}
+			steps, queryErr = createApprovalChainSteps(r.Context(), q, tenantID, chain.ID, stepParams)
+		} else {
+			steps, queryErr = q.ListApprovalChainSteps(r.Context(), dbgen.ListApprovalChainStepsParams{
+				TenantID: toPGUUID(tenantID),
+				ChainIds: []pgtype.UUID{chain.ID},
+			})
+		}
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "approval_chain", chainID, map[string]any{
+			"name":         chain.Name,
+			"isActive":     chain.IsActive,
+			"stepsChanged": stepParams != nil,
+			"steps":        len(steps),
+			"version":      chain.Version,
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "approval chain not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errNotTenantMember):
+			writeError(w, http.StatusBadRequest, "invalid_approver", "step approvers must be active members of this tenant")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_approval_chain", "approval chain name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "approval_chain_update_failed", "failed to update approval chain")
+		}
+		return
+	}
+
+	setETag(w, chain.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": approvalChainDTO(chain, steps)})
+}
+
+// createApprovalChainSteps inserts validated steps after checking that every named
+// approver and escalation user is an active member.
+func createApprovalChainSteps(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, chainID pgtype.UUID, params []dbgen.CreateApprovalChainStepParams) ([]dbgen.ApprovalChainStep, error) {
+	steps := make([]dbgen.ApprovalChainStep, 0, len(params))
+	for _, param := range params {
+		for _, userID := range []pgtype.UUID{param.ApproverUserID, param.EscalationUserID} {
+			if !userID.Valid {
+				continue
+			}
+			if _, err := actorRole(ctx, q, tenantID, uuid.UUID(userID.Bytes)); err != nil {
+				return nil, err
+			}
+		}
+		param.ChainID = chainID
+		step, err := q.CreateApprovalChainStep(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ListDelegations returns the caller's out-of-office delegations, both given and
+// received. Past ones are included with ?all=true.
+func (h ApprovalChainHandler) ListDelegations(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var rows []dbgen.ApprovalDelegation
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListApprovalDelegations(r.Context(), dbgen.ListApprovalDelegationsParams{
+			TenantID:    toPGUUID(tenantID),
+			UserID:      toPGUUID(actorID),
+			IncludePast: r.URL.Query().Get("all") == "true",
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "delegation_list_failed", "failed to list delegations")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, approvalDelegationDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// CreateDelegation hands the caller's approval steps to a colleague for a period.
+// Steps activated while it runs go to the delegate; the caller may still decide them.
+func (h ApprovalChainHandler) CreateDelegation(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		DelegateUserID string `json:"delegateUserId"`
+		StartsAt       string `json:"startsAt"`
+		EndsAt         string `json:"endsAt"`
+		Reason         string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	delegateID, err := parseUUID(req.DelegateUserID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_delegate", "delegateUserId must be UUID")
+		return
+	}
+	if delegateID == actorID {
+		writeError(w, http.StatusBadRequest, "invalid_delegate", "delegateUserId must be another user")
+		return
+	}
+	startsAt := toPGTimestamptz(time.Now().UTC())
+	if strings.TrimSpace(req.StartsAt) != "" {
+		startsAt, err = parseOptionalTimestamp(req.StartsAt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_starts_at", "startsAt must be RFC3339 or YYYY-MM-DD")
+			return
+		}
+	}
+	endsAt, err := parseOptionalTimestamp(req.EndsAt)
+	if err != nil || !endsAt.Valid {
+		writeError(w, http.StatusBadRequest, "invalid_ends_at", "endsAt is required as RFC3339 or YYYY-MM-DD")
+		return
+	}
+	if !endsAt.Time.After(startsAt.Time) || !endsAt.Time.After(time.Now()) {
+		writeError(w, http.StatusBadRequest, "invalid_ends_at", "endsAt must be in the future and after startsAt")
+		return
+	}
+
+	var row dbgen.ApprovalDelegation
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := actorRole(r.Context(), q, tenantID, delegateID); queryErr != nil {
+			return queryErr
+		}
+		overlapping, queryErr := q.CountOverlappingDelegations(r.Context(), dbgen.CountOverlappingDelegationsParams{
+			TenantID: toPGUUID(tenantID),
+			UserID:   toPGUUID(actorID),
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if overlapping > 0 {
+			return errDelegationOverlap
+		}
+		row, queryErr = q.CreateApprovalDelegation(r.Context(), dbgen.CreateApprovalDelegationParams{
+			TenantID:       toPGUUID(tenantID),
+			UserID:         toPGUUID(actorID),
+			DelegateUserID: toPGUUID(delegateID),
+			StartsAt:       startsAt,
+			EndsAt:         endsAt,
+			Reason:         toPGText(strings.TrimSpace(req.Reason)),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "approval_delegation", uuid.UUID(row.ID.Bytes), map[string]any{
+			"delegateUserId": pgUUIDToString(row.DelegateUserID),
+			"startsAt":       pgTimestampToString(row.StartsAt),
+			"endsAt":         pgTimestampToString(row.EndsAt),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errNotTenantMember):
+			writeError(w, http.StatusBadRequest, "invalid_delegate", "delegate is not an active member of this tenant")
+		case errors.Is(err, errDelegationOverlap):
+			writeError(w, http.StatusConflict, "delegation_overlap", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "delegation_create_failed", "failed to create delegation")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": approvalDelegationDTO(row)})
+}
+
+// DeleteDelegation ends one of the caller's delegations. Delegates already added to
+// pending steps keep their access to those steps.
+func (h ApprovalChainHandler) DeleteDelegation(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	delegationID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_delegation_id", "id must be UUID")
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		deleted, queryErr := q.DeleteApprovalDelegation(r.Context(), dbgen.DeleteApprovalDelegationParams{
+			TenantID:     toPGUUID(tenantID),
+			DelegationID: toPGUUID(delegationID),
+			UserID:       toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if deleted == 0 {
+			return pgx.ErrNoRows
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumDelete, "approval_delegation", delegationID, map[string]any{})
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "delegation not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "delegation_delete_failed", "failed to delete delegation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// approvalSubject is what a chain request is raised for.
+type approvalSubject struct {
+	EntityType  string
+	EntityID    pgtype.UUID
+	Reason      string
+	PolicyID    pgtype.UUID
+	MetricValue pgtype.Numeric
+}
+
+// startApprovalChain raises a request that walks the chain. Steps whose minValue the
+// metric does not exceed are skipped; without a metric every step applies, so an
+// unknown value never shortens the chain. The first applicable step is activated.
+func startApprovalChain(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, chainID pgtype.UUID, subject approvalSubject) (dbgen.ApprovalRequest, error) {
+	chainSteps, err := q.ListApprovalChainSteps(ctx, dbgen.ListApprovalChainStepsParams{
+		TenantID: toPGUUID(tenantID),
+		ChainIds: []pgtype.UUID{chainID},
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	first := -1
+	applies := make([]bool, len(chainSteps))
+	for i, step := range chainSteps {
+		applies[i] = !step.MinValue.Valid || !subject.MetricValue.Valid || pgNumericToFloat(subject.MetricValue) > pgNumericToFloat(step.MinValue)
+		if applies[i] && first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return dbgen.ApprovalRequest{}, errApprovalChainEmpty
+	}
+
+	opportunityID, err := q.GetApprovalEntityOpportunity(ctx, dbgen.GetApprovalEntityOpportunityParams{
+		TenantID:   toPGUUID(tenantID),
+		EntityType: subject.EntityType,
+		EntityID:   subject.EntityID,
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	firstStep := chainSteps[first]
+	assignments, err := resolveStepApprovers(ctx, q, tenantID, toPGUUID(actorID), opportunityID, approverRule{
+		Type:     firstStep.ApproverType,
+		UserID:   firstStep.ApproverUserID,
+		Role:     firstStep.ApproverRole,
+		TeamRole: firstStep.ApproverTeamRole,
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+
+	request, err := q.CreateChainApprovalRequest(ctx, dbgen.CreateChainApprovalRequestParams{
+		TenantID:       toPGUUID(tenantID),
+		EntityType:     subject.EntityType,
+		EntityID:       subject.EntityID,
+		RequestedBy:    toPGUUID(actorID),
+		ApproverUserID: assignments[0].UserID,
+		Reason:         subject.Reason,
+		PolicyID:       subject.PolicyID,
+		MetricValue:    subject.MetricValue,
+		ChainID:        chainID,
+		CurrentStep:    pgtype.Int4{Int32: firstStep.StepOrder, Valid: true},
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	if err := recordApprovalEvent(ctx, q, tenantID, request.ID, pgtype.Int4{}, "requested", toPGUUID(actorID), subject.Reason, map[string]any{
+		"chainId": pgUUIDToString(chainID),
+	}); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+
+	var activeStep dbgen.ApprovalRequestStep
+	for i, step := range chainSteps {
+		status := dbgen.ApprovalStepStatusEnumWaiting
+		if !applies[i] {
+			status = dbgen.ApprovalStepStatusEnumSkipped
+		}
+		created, err := q.CreateApprovalRequestStep(ctx, dbgen.CreateApprovalRequestStepParams{
+			TenantID:          toPGUUID(tenantID),
+			ApprovalRequestID: request.ID,
+			StepOrder:         step.StepOrder,
+			Name:              step.Name,
+			ApproverType:      step.ApproverType,
+			ApproverUserID:    step.ApproverUserID,
+			ApproverRole:      step.ApproverRole,
+			ApproverTeamRole:  step.ApproverTeamRole,
+			SlaHours:          step.SlaHours,
+			EscalationUserID:  step.EscalationUserID,
+			Status:            status,
+		})
+		if err != nil {
+			return dbgen.ApprovalRequest{}, err
+		}
+		if !applies[i] {
+			if err := recordApprovalEvent(ctx, q, tenantID, request.ID, pgtype.Int4{Int32: step.StepOrder, Valid: true}, "skipped", pgtype.UUID{}, "", map[string]any{
+				"minValue": pgNumericOrNil(step.MinValue),
+			}); err != nil {
+				return dbgen.ApprovalRequest{}, err
+			}
+		}
+		if i == first {
+			activeStep = created
+		}
+	}
+
+	if err := activateApprovalStep(ctx, q, tenantID, request, activeStep, assignments); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	if err := writeAuditLog(ctx, q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "approval_request", uuid.UUID(request.ID.Bytes), map[string]any{
+		"event":       "chain_approval_requested",
+		"entityType":  request.EntityType,
+		"entityId":    pgUUIDToString(request.EntityID),
+		"chainId":     pgUUIDToString(chainID),
+		"currentStep": activeStep.StepOrder,
+	}); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	return request, nil
+}
+
+// stepAssignment is one user allowed to decide a step and why.
+type stepAssignment struct {
+	UserID        pgtype.UUID
+	Source        string
+	DelegatedFrom pgtype.UUID
+}
+
+// resolveStepApprovers turns a step rule into users. The requester never approves
+// their own request; when the rule names nobody else the tenant admins stand in. Each
+// approver on active out-of-office delegation is joined by their delegate.
+func resolveStepApprovers(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, requesterID, opportunityID pgtype.UUID, rule approverRule) ([]stepAssignment, error) {
+	var candidates []pgtype.UUID
+	switch rule.Type {
+	case dbgen.ApprovalApproverTypeEnumUser:
+		if _, err := actorRole(ctx, q, tenantID, uuid.UUID(rule.UserID.Bytes)); err == nil {
+			candidates = []pgtype.UUID{rule.UserID}
+		} else if !errors.Is(err, errNotTenantMember) {
+			return nil, err
+		}
+	case dbgen.ApprovalApproverTypeEnumRole:
+		members, err := q.ListActiveMembersByRole(ctx, dbgen.ListActiveMembersByRoleParams{
+			TenantID: toPGUUID(tenantID),
+			Role:     rule.Role.RoleEnum,
+		})
+		if err != nil {
+			return nil, err
+		}
+		candidates = members
+	case dbgen.ApprovalApproverTypeEnumTeam:
+		if opportunityID.Valid {
+			members, err := q.ListOpportunityTeamMembersByRole(ctx, dbgen.ListOpportunityTeamMembersByRoleParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: opportunityID,
+				TeamRole:      rule.TeamRole.OpportunityTeamRoleEnum,
+			})
+			if err != nil {
+				return nil, err
+			}
+			candidates = members
+		}
+	}
+	candidates = withoutUser(candidates, requesterID)
+	if len(candidates) == 0 {
+		admins, err := q.ListActiveMembersByRole(ctx, dbgen.ListActiveMembersByRoleParams{
+			TenantID: toPGUUID(tenantID),
+			Role:     dbgen.RoleEnumAdmin,
+		})
+		if err != nil {
+			return nil, err
+		}
+		candidates = withoutUser(admins, requesterID)
+	}
+	if len(candidates) == 0 {
+		return nil, errApprovalNoApprover
+	}
+
+	assignments := make([]stepAssignment, 0, len(candidates))
+	seen := map[pgtype.UUID]bool{}
+	for _, userID := range candidates {
+		if !seen[userID] {
+			seen[userID] = true
+			assignments = append(assignments, stepAssignment{UserID: userID, Source: "assigned"})
+		}
+	}
+	for _, userID := range candidates {
+		delegation, err := q.GetActiveDelegation(ctx, dbgen.GetActiveDelegationParams{
+			TenantID: toPGUUID(tenantID),
+			UserID:   userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if seen[delegation.DelegateUserID] || delegation.DelegateUserID == requesterID {
+			continue
+		}
+		seen[delegation.DelegateUserID] = true
+		assignments = append(assignments, stepAssignment{UserID: delegation.DelegateUserID, Source: "delegate", DelegatedFrom: userID})
+	}
+	return assignments, nil
+}
+
+func withoutUser(users []pgtype.UUID, excluded pgtype.UUID) []pgtype.UUID {
+	kept := make([]pgtype.UUID, 0, len(users))
+	for _, userID := range users {
+		if userID != excluded {
+			kept = append(kept, userID)
+		}
+	}
+	return kept
+}
+
+// activateApprovalStep starts the SLA clock of a step, records its approvers and
+// notifies them. Delegates are notified instead of the approvers they stand in for.
+func activateApprovalStep(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, request dbgen.ApprovalRequest, step dbgen.ApprovalRequestStep, assignments []stepAssignment) error {
+	step, err := q.ActivateApprovalRequestStep(ctx, dbgen.ActivateApprovalRequestStepParams{
+		TenantID: toPGUUID(tenantID),
+		StepID:   step.ID,
+	})
+	if err != nil {
+		return err
+	}
+	stepOrder := pgtype.Int4{Int32: step.StepOrder, Valid: true}
+	approverIDs := make([]string, 0, len(assignments))
+	delegated := map[pgtype.UUID]bool{}
+	for _, assignment := range assignments {
+		if _, err := q.AddApprovalStepApprover(ctx, dbgen.AddApprovalStepApproverParams{
+			StepID:        step.ID,
+			UserID:        assignment.UserID,
+			TenantID:      toPGUUID(tenantID),
+			Source:        assignment.Source,
+			DelegatedFrom: assignment.DelegatedFrom,
+		}); err != nil {
+			return err
+		}
+		approverIDs = append(approverIDs, pgUUIDToString(assignment.UserID))
+		if assignment.Source == "delegate" {
+			delegated[assignment.DelegatedFrom] = true
+			if err := recordApprovalEvent(ctx, q, tenantID, request.ID, stepOrder, "delegated", pgtype.UUID{}, "", map[string]any{
+				"fromUserId": pgUUIDToString(assignment.DelegatedFrom),
+				"toUserId":   pgUUIDToString(assignment.UserID),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if err := recordApprovalEvent(ctx, q, tenantID, request.ID, stepOrder, "step_activated", pgtype.UUID{}, "", map[string]any{
+		"name":      step.Name,
+		"approvers": approverIDs,
+		"slaDueAt":  pgTimestampToString(step.SlaDueAt),
+	}); err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		if delegated[assignment.UserID] {
+			continue
+		}
+		if err := notifyApprovalUser(ctx, q, tenantID, assignment.UserID, "approval_requested", request,
+			fmt.Sprintf("Approval needed: %s", step.Name),
+			fmt.Sprintf("%s (step %d: %s)", request.Reason, step.StepOrder, step.Name),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advanceApprovalChain records an approver's decision on the pending step. An approval
+// activates the next waiting step or, after the last one, approves the request; a
+// rejection ends the request and cancels the steps that did not run.
+func advanceApprovalChain(ctx context.Context, q *dbgen.Queries, tenantID, actorID uuid.UUID, request dbgen.ApprovalRequest, status dbgen.ApprovalStatusEnum, note string) (dbgen.ApprovalRequest, error) {
+	step, err := q.GetPendingApprovalRequestStepForUpdate(ctx, dbgen.GetPendingApprovalRequestStepForUpdateParams{
+		TenantID:          toPGUUID(tenantID),
+		ApprovalRequestID: request.ID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dbgen.ApprovalRequest{}, errApprovalNotPending
+	}
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	// Checked before the approver list: escalation or a delegation may have put the
+	// requester on their own step.
+	if request.RequestedBy == toPGUUID(actorID) {
+		return dbgen.ApprovalRequest{}, errSelfApproval
+	}
+	onBehalfOf, err := stepApproverFor(ctx, q, tenantID, step.ID, toPGUUID(actorID))
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+
+	stepStatus := dbgen.ApprovalStepStatusEnumApproved
+	event := "approved"
+	if status == dbgen.ApprovalStatusEnumRejected {
+		stepStatus = dbgen.ApprovalStepStatusEnumRejected
+		event = "rejected"
+	}
+	if _, err := q.DecideApprovalRequestStep(ctx, dbgen.DecideApprovalRequestStepParams{
+		Status:       stepStatus,
+		DecidedBy:    toPGUUID(actorID),
+		DecisionNote: toPGText(note),
+		TenantID:     toPGUUID(tenantID),
+		StepID:       step.ID,
+	}); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	data := map[string]any{"name": step.Name}
+	if onBehalfOf.Valid {
+		data["onBehalfOf"] = pgUUIDToString(onBehalfOf)
+	}
+	stepOrder := pgtype.Int4{Int32: step.StepOrder, Valid: true}
+	if err := recordApprovalEvent(ctx, q, tenantID, request.ID, stepOrder, event, toPGUUID(actorID), note, data); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+
+	if status == dbgen.ApprovalStatusEnumApproved {
+		steps, err := q.ListApprovalRequestSteps(ctx, dbgen.ListApprovalRequestStepsParams{
+			TenantID:           toPGUUID(tenantID),
+			ApprovalRequestIds: []pgtype.UUID{request.ID},
+		})
+		if err != nil {
+			return dbgen.ApprovalRequest{}, err
+		}
+		for _, next := range steps {
+			if next.StepOrder <= step.StepOrder || next.Status != dbgen.ApprovalStepStatusEnumWaiting {
+				continue
+			}
+			opportunityID, err := q.GetApprovalEntityOpportunity(ctx, dbgen.GetApprovalEntityOpportunityParams{
+				TenantID:   toPGUUID(tenantID),
+				EntityType: request.EntityType,
+				EntityID:   request.EntityID,
+			})
+			if err != nil {
+				return dbgen.ApprovalRequest{}, err
+			}
+			assignments, err := resolveStepApprovers(ctx, q, tenantID, request.RequestedBy, opportunityID, approverRule{
+				Type:     next.ApproverType,
+				UserID:   next.ApproverUserID,
+				Role:     next.ApproverRole,
+				TeamRole: next.ApproverTeamRole,
+			})
+			if err != nil {
+				return dbgen.ApprovalRequest{}, err
+			}
+			advanced, err := q.AdvanceApprovalRequest(ctx, dbgen.AdvanceApprovalRequestParams{
+				CurrentStep:    pgtype.Int4{Int32: next.StepOrder, Valid: true},
+				ApproverUserID: assignments[0].UserID,
+				TenantID:       toPGUUID(tenantID),
+				ApprovalID:     request.ID,
+			})
+			if err != nil {
+				return dbgen.ApprovalRequest{}, err
+			}
+			return advanced, activateApprovalStep(ctx, q, tenantID, advanced, next, assignments)
+		}
+	} else if err := q.CancelOpenApprovalRequestSteps(ctx, dbgen.CancelOpenApprovalRequestStepsParams{
+		TenantID:           toPGUUID(tenantID),
+		ApprovalRequestIds: []pgtype.UUID{request.ID},
+	}); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+
+	finished, err := q.FinishApprovalRequest(ctx, dbgen.FinishApprovalRequestParams{
+		Status:       status,
+		DecisionNote: toPGText(note),
+		TenantID:     toPGUUID(tenantID),
+		ApprovalID:   request.ID,
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	return finished, recordApprovalEvent(ctx, q, tenantID, request.ID, pgtype.Int4{}, "completed", pgtype.UUID{}, "", map[string]any{
+		"status": string(finished.Status),
+	})
+}
+
+// stepApproverFor checks that the user may decide the step: as one of its approvers or
+// as the active delegate of one. It returns the approver a delegate acts for.
+func stepApproverFor(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, stepID, userID pgtype.UUID) (pgtype.UUID, error) {
+	approvers, err := q.ListApprovalStepApprovers(ctx, dbgen.ListApprovalStepApproversParams{
+		TenantID: toPGUUID(tenantID),
+		StepIds:  []pgtype.UUID{stepID},
+	})
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	for _, approver := range approvers {
+		if approver.UserID == userID {
+			return approver.DelegatedFrom, nil
+		}
+	}
+	for _, approver := range approvers {
+		delegate, err := isActiveDelegate(ctx, q, tenantID, approver.UserID, userID)
+		if err != nil {
+			return pgtype.UUID{}, err
+		}
+		if delegate {
+			return approver.UserID, nil
+		}
+	}
+	return pgtype.UUID{}, errNotApprover
+}
+
+// isActiveDelegate reports whether userID currently stands in for approverID.
+func isActiveDelegate(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, approverID, userID pgtype.UUID) (bool, error) {
+	delegation, err := q.GetActiveDelegation(ctx, dbgen.GetActiveDelegationParams{
+		TenantID: toPGUUID(tenantID),
+		UserID:   approverID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return delegation.DelegateUserID == userID, nil
+}
+
+// cancelApprovalChains closes the open steps of requests that were invalidated.
+func cancelApprovalChains(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, requests []dbgen.ApprovalRequest, reason string) error {
+	ids := make([]pgtype.UUID, 0, len(requests))
+	for _, request := range requests {
+		ids = append(ids, request.ID)
+		if err := recordApprovalEvent(ctx, q, tenantID, request.ID, request.CurrentStep, "invalidated", pgtype.UUID{}, reason, map[string]any{
+			"status": string(request.Status),
+		}); err != nil {
+			return err
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return q.CancelOpenApprovalRequestSteps(ctx, dbgen.CancelOpenApprovalRequestStepsParams{
+		TenantID:           toPGUUID(tenantID),
+		ApprovalRequestIds: ids,
+	})
+}
+
+func recordApprovalEvent(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, requestID pgtype.UUID, stepOrder pgtype.Int4, event string, actorID pgtype.UUID, note string, data map[string]any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return q.CreateApprovalRequestEvent(ctx, dbgen.CreateApprovalRequestEventParams{
+		TenantID:          toPGUUID(tenantID),
+		ApprovalRequestID: requestID,
+		StepOrder:         stepOrder,
+		Event:             event,
+		ActorUserID:       actorID,
+		Note:              toPGText(note),
+		Data:              payload,
+	})
+}
+
+func notifyApprovalUser(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, userID pgtype.UUID, kind string, request dbgen.ApprovalRequest, title, body string) error {
+	data, err := json.Marshal(map[string]any{
+		"entityType":  request.EntityType,
+		"entityId":    pgUUIDToString(request.EntityID),
+		"currentStep": request.CurrentStep.Int32,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateNotification(ctx, dbgen.CreateNotificationParams{
+		TenantID:   toPGUUID(tenantID),
+		UserID:     userID,
+		Kind:       kind,
+		EntityType: "approval_request",
+		EntityID:   request.ID,
+		Title:      title,
+		Body:       body,
+		Data:       data,
+	})
+	return err
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func approvalChainDTO(chain dbgen.ApprovalChain, steps []dbgen.ApprovalChainStep) map[string]any {
+	stepData := make([]map[string]any, 0, len(steps))
+	for _, step := range steps {
+		if step.ChainID != chain.ID {
+			continue
+		}
+		stepData = append(stepData, map[string]any{
+			"id":               pgUUIDToString(step.ID),
+			"stepOrder":        step.StepOrder,
+			"name":             step.Name,
+			"approverType":     string(step.ApproverType),
+			"approverUserId":   pgUUIDToString(step.ApproverUserID),
+			"approverRole":     string(step.ApproverRole.RoleEnum),
+			"approverTeamRole": string(step.ApproverTeamRole.OpportunityTeamRoleEnum),
+			"minValue":         pgNumericOrNil(step.MinValue),
+			"slaHours":         pgInt4OrNil(step.SlaHours),
+			"escalationUserId": pgUUIDToString(step.EscalationUserID),
+		})
+	}
+	return map[string]any{
+		"id":          pgUUIDToString(chain.ID),
+		"name":        chain.Name,
+		"description": pgTextToString(chain.Description),
+		"isActive":    chain.IsActive,
+		"steps":       stepData,
+		"createdBy":   pgUUIDToString(chain.CreatedBy),
+		"createdAt":   pgTimestampToString(chain.CreatedAt),
+		"updatedAt":   pgTimestampToString(chain.UpdatedAt),
+		"version":     chain.Version,
+	}
+}
+
+func approvalDelegationDTO(row dbgen.ApprovalDelegation) map[string]any {
+	return map[string]any{
+		"id":             pgUUIDToString(row.ID),
+		"userId":         pgUUIDToString(row.UserID),
+		"delegateUserId": pgUUIDToString(row.DelegateUserID),
+		"startsAt":       pgTimestampToString(row.StartsAt),
+		"endsAt":         pgTimestampToString(row.EndsAt),
+		"reason":         pgTextToString(row.Reason),
+		"createdAt":      pgTimestampToString(row.CreatedAt),
+	}
+}
+
+func approvalStepDTO(step dbgen.ApprovalRequestStep, approvers []dbgen.ListApprovalStepApproversRow) map[string]any {
+	approverData := make([]map[string]any, 0)
+	for _, approver := range approvers {
+		if approver.StepID != step.ID {
+			continue
+		}
+		approverData = append(approverData, map[string]any{
+			"userId":        pgUUIDToString(approver.UserID),
+			"displayName":   approver.DisplayName,
+			"source":        approver.Source,
+			"delegatedFrom": pgUUIDToString(approver.DelegatedFrom),
+			"addedAt":       pgTimestampToString(approver.AddedAt),
+		})
+	}
+	return map[string]any{
+		"stepOrder":        step.StepOrder,
+		"name":             step.Name,
+		"approverType":     string(step.ApproverType),
+		"approverUserId":   pgUUIDToString(step.ApproverUserID),
+		"approverRole":     string(step.ApproverRole.RoleEnum),
+		"approverTeamRole": string(step.ApproverTeamRole.OpportunityTeamRoleEnum),
+		"status":           string(step.Status),
+		"approvers":        approverData,
+		"slaHours":         pgInt4OrNil(step.SlaHours),
+		"activatedAt":      pgTimestampToString(step.ActivatedAt),
+		"slaDueAt":         pgTimestampToString(step.SlaDueAt),
+		"escalatedAt":      pgTimestampToString(step.EscalatedAt),
+		"decidedBy":        pgUUIDToString(step.DecidedBy),
+		"decidedAt":        pgTimestampToString(step.DecidedAt),
+		"decisionNote":     pgTextToString(step.DecisionNote),
+	}
+}
+
+func approvalEventDTO(row dbgen.ListApprovalRequestEventsRow) map[string]any {
+	var data map[string]any
+	if len(row.Data) > 0 {
+		_ = json.Unmarshal(row.Data, &data)
+	}
+	return map[string]any{
+		"event":       row.Event,
+		"stepOrder":   pgInt4OrNil(row.StepOrder),
+		"actorUserId": pgUUIDToString(row.ActorUserID),
+		"actorName":   pgTextToString(row.ActorName),
+		"note":        pgTextToString(row.Note),
+		"data":        data,
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+	}
+}
+
+func pgInt4OrNil(value pgtype.Int4) any {
+	if !value.Valid {
+		return nil
+	}
+	return value.Int32
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errInvalidApprovalEntity = errors.New("entityId does not name a record of entityType in this tenant")
+
+// approvalEntityType describes what an approval request of one entity_type is about.
+// Its hooks are optional and run in the decision's transaction, so a failing hook rolls
+// the decision back.
+type approvalEntityType struct {
+	// lock takes the subject's row lock before the request is locked, the same order
+	// as writers that change the subject and then invalidate its approvals.
+	lock func(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, entityID pgtype.UUID) error
+	// apply runs once the request is approved or rejected.
+	apply func(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, request dbgen.ApprovalRequest) error
+}
+
+// approvalEntityTypes registers the subjects approvals can be requested for. The entity
+// summaries come from ListApprovalEntitySummaries, which knows the same types.
+var approvalEntityTypes = map[string]approvalEntityType{
+	// entity_id is the quote.
+	"quote": {lock: lockQuoteForApproval, apply: applyQuoteApproval},
+	// entity_id is the order.
+	"order": {},
+	// entity_id is the opportunity whose discount needs sign-off.
+	"opportunity_discount": {},
+	// entity_id is the account changing hands.
+	"account_ownership_transfer": {},
+}
+
+func parseApprovalEntityType(raw string) (string, error) {
+	entityType := strings.TrimSpace(raw)
+	if _, ok := approvalEntityTypes[entityType]; !ok {
+		return "", errors.New("entityType must be quote, order, opportunity_discount, or account_ownership_transfer")
+	}
+	return entityType, nil
+}
+
+// checkApprovalEntity fails with errInvalidApprovalEntity unless the subject exists in
+// the tenant.
+func checkApprovalEntity(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, entityType string, entityID pgtype.UUID) error {
+	rows, err := q.ListApprovalEntitySummaries(ctx, dbgen.ListApprovalEntitySummariesParams{
+		TenantID:    toPGUUID(tenantID),
+		EntityTypes: []string{entityType},
+		EntityIds:   []pgtype.UUID{entityID},
+	})
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errInvalidApprovalEntity
+	}
+	return nil
+}
+
+// approvalEntityKey identifies a subject across entity types.
+type approvalEntityKey struct {
+	entityType string
+	entityID   pgtype.UUID
+}
+
+// loadApprovalEntities returns the summary of each request's subject. Subjects that were
+// deleted since, or rows from before entity types were registered, have none.
+func loadApprovalEntities(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, rows []dbgen.ApprovalRequest) (map[approvalEntityKey]json.RawMessage, error) {
+	types := make([]string, 0, len(rows))
+	ids := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		types = append(types, row.EntityType)
+		ids = append(ids, row.EntityID)
+	}
+	summaries := map[approvalEntityKey]json.RawMessage{}
+	if len(rows) == 0 {
+		return summaries, nil
+	}
+	found, err := q.ListApprovalEntitySummaries(ctx, dbgen.ListApprovalEntitySummariesParams{
+		TenantID:    toPGUUID(tenantID),
+		EntityTypes: types,
+		EntityIds:   ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range found {
+		summaries[approvalEntityKey{entityType: row.EntityType, entityID: row.EntityID}] = json.RawMessage(row.Summary)
+	}
+	return summaries, nil
+}
+
+// approvalEntityDTO is the summary embedded in approval responses; null when the
+// subject is gone.
+func approvalEntityDTO(row dbgen.ApprovalRequest, summaries map[approvalEntityKey]json.RawMessage) any {
+	summary, ok := summaries[approvalEntityKey{entityType: row.EntityType, entityID: row.EntityID}]
+	if !ok {
+		return nil
+	}
+	return summary
+}
+
+func lockApprovalSubject(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, request dbgen.ApprovalRequest) error {
+	entity, ok := approvalEntityTypes[request.EntityType]
+	if !ok || entity.lock == nil {
+		return nil
+	}
+	return entity.lock(ctx, q, tenantID, request.EntityID)
+}
+
+func applyApprovalEffect(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, request dbgen.ApprovalRequest) error {
+	entity, ok := approvalEntityTypes[request.EntityType]
+	if !ok || entity.apply == nil {
+		return nil
+	}
+	return entity.apply(ctx, q, r, tenantID, actorID, request)
+}
+
+func lockQuoteForApproval(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, quoteID pgtype.UUID) error {
+	_, err := q.GetQuoteByIDForUpdate(ctx, dbgen.GetQuoteByIDForUpdateParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quoteID,
+	})
+	return err
+}
+
+// applyQuoteApproval unlocks a draft quote for sending once every discount policy it
+// matches is approved, and locks it again when an approval is rejected. Sending still
+// re-checks the policies, which may have changed since.
+func applyQuoteApproval(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, request dbgen.ApprovalRequest) error {
+	quote, err := q.GetQuoteByIDForUpdate(ctx, dbgen.GetQuoteByIDForUpdateParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  request.EntityID,
+	})
+	if err != nil {
+		return err
+	}
+	if quote.Status != dbgen.QuoteStatusEnumDraft {
+		return nil
+	}
+
+	var approvedForSendAt pgtype.Timestamptz
+	if request.Status == dbgen.ApprovalStatusEnumApproved {
+		checks, err := checkDiscountPolicies(ctx, q, tenantID, quote.ID)
+		if err != nil {
+			return err
+		}
+		unlocked := true
+		for _, check := range checks {
+			unlocked = unlocked && check.approved()
+		}
+		if unlocked {
+			approvedForSendAt = toPGTimestamptz(time.Now().UTC())
+		}
+	}
+	if approvedForSendAt.Valid == quote.ApprovedForSendAt.Valid {
+		return nil
+	}
+	if err := q.SetQuoteApprovedForSend(ctx, dbgen.SetQuoteApprovedForSendParams{
+		ApprovedForSendAt: approvedForSendAt,
+		TenantID:          toPGUUID(tenantID),
+		QuoteID:           quote.ID,
+	}); err != nil {
+		return err
+	}
+	event := "approved_for_send"
+	if !approvedForSendAt.Valid {
+		event = "approval_lock_restored"
+	}
+	return writeAuditLog(ctx, q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", uuid.UUID(quote.ID.Bytes), map[string]any{
+		"event":      event,
+		"quoteNo":    quote.QuoteNo,
+		"approvalId": pgUUIDToString(request.ID),
+	})
+}
+
+// notifyApprovalDecision tells the requester how their request ended.
+func notifyApprovalDecision(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, request dbgen.ApprovalRequest) error {
+	return notifyApprovalUser(ctx, q, tenantID, request.RequestedBy, "approval_decided", request,
+		fmt.Sprintf("Approval %s", request.Status),
+		fmt.Sprintf("%s: %s", request.Reason, request.Status),
+	)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+// auditExportBatchSize is how many entries an export reads per transaction.
+const auditExportBatchSize = 1000
+
+type AuditLogHandler struct {
+	Store *store.Store
+}
+
+func NewAuditLogHandler(store *store.Store) AuditLogHandler {
+	return AuditLogHandler{Store: store}
+}
+
+// List pages through the tenant's audit log newest first. Admins only.
+func (h AuditLogHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	params, ok := parseAuditLogFilter(w, r, tenantID)
+	if !ok {
+		return
+	}
+	limit := queryCursorLimit(r, 50)
+	params.LimitCount = limit + 1
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, cursorErr := decodeCursor(raw)
+		if cursorErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", cursorErr.Error())
+			return
+		}
+		cursorID, parseErr := strconv.ParseInt(cursor.ID, 10, 64)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", "invalid cursor")
+			return
+		}
+		params.CursorAt = toPGTimestamptz(cursor.At)
+		params.CursorID = pgtype.Int8{Int64: cursorID, Valid: true}
+	}
+
+	var rows []dbgen.ListAuditLogsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListAuditLogs(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "audit_log_list_failed", "failed to load audit logs")
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(timeCursor{
+			At: last.CreatedAt.Time,
+			ID: strconv.FormatInt(last.ID, 10),
+		})
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, auditLogDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"limit":      limit,
+			"nextCursor": nextCursor,
+		},
+	})
+}
+
+// Export streams every entry matching the List filters as CSV (default) or NDJSON
+// (?format=ndjson). Admins only. Entries are read in batches, each in its own short
+// transaction, and flushed as they go; entries written during the export are left out.
+func (h AuditLogHandler) Export(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "ndjson" {
+		writeError(w, http.StatusBadRequest, "invalid_format", "format must be csv or ndjson")
+		return
+	}
+	params, ok := parseAuditLogFilter(w, r, tenantID)
+	if !ok {
+		return
+	}
+	params.LimitCount = auditExportBatchSize
+
+	// The first batch runs with the admin check, so errors can still get a status code.
+	var rows []dbgen.ListAuditLogsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListAuditLogs(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "audit_log_export_failed", "failed to export audit logs")
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	var writeRows func([]dbgen.ListAuditLogsRow) error
+	if format == "ndjson" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.ndjson")
+		encoder := json.NewEncoder(w)
+		writeRows = func(batch []dbgen.ListAuditLogsRow) error {
+			for _, row := range batch {
+				if err := encoder.Encode(auditLogDTO(row)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=audit-logs.csv")
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{
+			"id", "created_at", "action", "actor_user_id", "actor_name", "entity_type", "entity_id", "metadata", "ip_address", "user_agent",
+		})
+		writeRows = func(batch []dbgen.ListAuditLogsRow) error {
+			for _, row := range batch {
+				_ = writer.Write([]string{
+					strconv.FormatInt(row.ID, 10),
+					pgTimestampToString(row.CreatedAt),
+					string(row.Action),
+					pgUUIDToString(row.ActorUserID),
+					pgTextToString(row.ActorName),
+					pgTextToString(row.EntityType),
+					pgUUIDToString(row.EntityID),
+					string(jsonOrNull(row.Metadata)),
+					ipAddressToString(row.IpAddress),
+					pgTextToString(row.UserAgent),
+				})
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	}
+
+	for {
+		if err := writeRows(rows); err != nil {
+			return
+		}
+		_ = controller.Flush()
+		if len(rows) < auditExportBatchSize {
+			return
+		}
+		last := rows[len(rows)-1]
+		params.CursorAt = last.CreatedAt
+		params.CursorID = pgtype.Int8{Int64: last.ID, Valid: true}
+		if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+			var queryErr error
+			rows, queryErr = q.ListAuditLogs(r.Context(), params)
+			return queryErr
+		}); err != nil {
+			// The status line is gone; abort the connection so the client cannot take a
+			// truncated export for a complete one.
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// parseAuditLogFilter reads action, actorUserId, entityType, entityId, from (inclusive)
+// and to (exclusive). It writes the 400 itself and reports whether the filter is valid.
+func parseAuditLogFilter(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) (dbgen.ListAuditLogsParams, bool) {
+	query := r.URL.Query()
+	params := dbgen.ListAuditLogsParams{TenantID: toPGUUID(tenantID)}
+	if raw := query.Get("action"); raw != "" {
+		action, err := parseAuditAction(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_action", err.Error())
+			return params, false
+		}
+		params.Action = dbgen.NullAuditActionEnum{AuditActionEnum: action, Valid: true}
+	}
+	if raw := query.Get("actorUserId"); raw != "" {
+		actorID, err := parseUUID(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_actor_user_id", "actorUserId must be UUID")
+			return params, false
+		}
+		params.ActorUserID = toPGUUID(actorID)
+	}
+	if raw := strings.TrimSpace(query.Get("entityType")); raw != "" {
+		params.EntityType = toPGText(raw)
+	}
+	if raw := query.Get("entityId"); raw != "" {
+		entityID, err := parseUUID(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_entity_id", "entityId must be UUID")
+			return params, false
+		}
+		params.EntityID = toPGUUID(entityID)
+	}
+	var err error
+	if params.FromAt, err = parseOptionalTimestamp(query.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_from", "from must be RFC3339 or YYYY-MM-DD")
+		return params, false
+	}
+	if params.UntilAt, err = parseOptionalTimestamp(query.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_to", "to must be RFC3339 or YYYY-MM-DD")
+		return params, false
+	}
+	if params.FromAt.Valid && params.UntilAt.Valid && !params.FromAt.Time.Before(params.UntilAt.Time) {
+		writeError(w, http.StatusBadRequest, "invalid_range", "from must be before to")
+		return params, false
+	}
+	return params, true
+}
+
+func parseAuditAction(raw string) (dbgen.AuditActionEnum, error) {
+	switch dbgen.AuditActionEnum(strings.ToLower(strings.TrimSpace(raw))) {
+	case dbgen.AuditActionEnumCreate:
+		return dbgen.AuditActionEnumCreate, nil
+	case dbgen.AuditActionEnumUpdate:
+		return dbgen.AuditActionEnumUpdate, nil
+	case dbgen.AuditActionEnumDelete:
+		return dbgen.AuditActionEnumDelete, nil
+	case dbgen.AuditActionEnumLogin:
+		return dbgen.AuditActionEnumLogin, nil
+	default:
+		return "", errors.New("action must be create, update, delete, or login")
+	}
+}
+
+func auditLogDTO(row dbgen.ListAuditLogsRow) map[string]any {
+	return map[string]any{
+		"id":          row.ID,
+		"actorUserId": pgUUIDToString(row.ActorUserID),
+		"actorName":   pgTextToString(row.ActorName),
+		"action":      string(row.Action),
+		"entityType":  pgTextToString(row.EntityType),
+		"entityId":    pgUUIDToString(row.EntityID),
+		"metadata":    json.RawMessage(jsonOrNull(row.Metadata)),
+		"ipAddress":   ipAddressToString(row.IpAddress),
+		"userAgent":   pgTextToString(row.UserAgent),
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+	}
+}
+
+func ipAddressToString(addr *netip.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+// writeAuditLog records an audit entry inside the caller's tenant transaction so the
+// entry commits or rolls back together with the change it describes.
+func writeAuditLog(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, action dbgen.AuditActionEnum, entityType string, entityID uuid.UUID, metadata map[string]any) error {
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateAuditLog(ctx, dbgen.CreateAuditLogParams{
+		TenantID:    toPGUUID(tenantID),
+		ActorUserID: toPGUUID(actorID),
+		Action:      action,
+		EntityType:  toPGText(entityType),
+		EntityID:    toPGUUID(entityID),
+		Metadata:    payload,
+		IpAddress:   requestIP(r),
+		UserAgent:   toPGText(r.UserAgent()),
+	})
+	return err
+}
+
+func requestIP(r *http.Request) *netip.Addr {
+	if addr, err := netip.ParseAddr(r.RemoteAddr); err == nil {
+		return &addr
+	}
+	if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		addr := addrPort.Addr()
+		return &addr
+	}
+	return nil
+}
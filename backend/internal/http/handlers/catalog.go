@@ -0,0 +1,754 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+type CatalogHandler struct {
+	Store *store.Store
+}
+
+func NewCatalogHandler(store *store.Store) CatalogHandler {
+	return CatalogHandler{Store: store}
+}
+
+func (h CatalogHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 50)
+	var active pgtype.Bool
+	if raw := r.URL.Query().Get("active"); raw != "" {
+		parsed, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_active", "active must be true or false")
+			return
+		}
+		active = pgtype.Bool{Bool: parsed, Valid: true}
+	}
+	search := toPGText(strings.TrimSpace(r.URL.Query().Get("q")))
+
+	var rows []dbgen.Product
+	var total int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListProducts(r.Context(), dbgen.ListProductsParams{
+			TenantID:    toPGUUID(tenantID),
+			IsActive:    active,
+			Search:      search,
+			LimitCount:  limit,
+			OffsetCount: offset,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		total, queryErr = q.CountProducts(r.Context(), dbgen.CountProductsParams{
+			TenantID: toPGUUID(tenantID),
+			IsActive: active,
+			Search:   search,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "product_list_failed", "failed to load products")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, productDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"page":  offset/limit + 1,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h CatalogHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	productID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_product_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Product
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetProduct(r.Context(), dbgen.GetProductParams{
+			TenantID:  toPGUUID(tenantID),
+			ProductID: toPGUUID(productID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "product not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "product_get_failed", "failed to load product")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": productDTO(row)})
+}
+
+func (h CatalogHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var req struct {
+		SKU         string   `json:"sku"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Unit        string   `json:"unit"`
+		IsActive    *bool    `json:"isActive"`
+		ListPrice   *float64 `json:"listPrice"`
+		TaxRate     *float64 `json:"taxRate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.SKU == "" || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "validation_error", "sku and name are required")
+		return
+	}
+	if req.ListPrice != nil && *req.ListPrice < 0 {
+		writeError(w, http.StatusBadRequest, "invalid_list_price", "listPrice must be zero or greater")
+		return
+	}
+	var taxRate pgtype.Numeric
+	if req.TaxRate != nil {
+		if !validTaxRate(*req.TaxRate) {
+			writeError(w, http.StatusBadRequest, "invalid_tax_rate", errInvalidTaxRate.Error())
+			return
+		}
+		taxRate = toPGNumeric(*req.TaxRate)
+	}
+	var active pgtype.Bool
+	if req.IsActive != nil {
+		active = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	var row dbgen.Product
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.CreateProduct(r.Context(), dbgen.CreateProductParams{
+			TenantID:    toPGUUID(tenantID),
+			Sku:         req.SKU,
+			Name:        req.Name,
+			Description: toPGText(req.Description),
+			Unit:        toPGText(req.Unit),
+			IsActive:    active,
+			TaxRate:     taxRate,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if req.ListPrice == nil {
+			return nil
+		}
+		return setDefaultListPrice(r, q, row, *req.ListPrice)
+	}); err != nil {
+		switch {
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_sku", "sku already exists")
+		case errors.Is(err, errNoDefaultPriceBook):
+			writeError(w, http.StatusConflict, "no_default_price_book", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "product_create_failed", "failed to create product")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": productDTO(row)})
+}
+
+func (h CatalogHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	productID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_product_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		SKU         *string  `json:"sku"`
+		Name        *string  `json:"name"`
+		Description *string  `json:"description"`
+		Unit        *string  `json:"unit"`
+		IsActive    *bool    `json:"isActive"`
+		TaxRate     *float64 `json:"taxRate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateProductParams{
+		TenantID:  toPGUUID(tenantID),
+		ProductID: toPGUUID(productID),
+	}
+	if req.SKU != nil {
+		if strings.TrimSpace(*req.SKU) == "" {
+			writeError(w, http.StatusBadRequest, "validation_error", "sku cannot be empty")
+			return
+		}
+		params.Sku = toPGText(strings.TrimSpace(*req.SKU))
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "validation_error", "name cannot be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	if req.Description != nil {
+		params.Description = pgtype.Text{String: *req.Description, Valid: true}
+	}
+	if req.Unit != nil {
+		params.Unit = pgtype.Text{String: *req.Unit, Valid: true}
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+	if req.TaxRate != nil {
+		if !validTaxRate(*req.TaxRate) {
+			writeError(w, http.StatusBadRequest, "invalid_tax_rate", errInvalidTaxRate.Error())
+			return
+		}
+		params.TaxRate = toPGNumeric(*req.TaxRate)
+	}
+
+	var row dbgen.Product
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.UpdateProduct(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "product not found")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_sku", "sku already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "product_update_failed", "failed to update product")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": productDTO(row)})
+}
+
+func (h CatalogHandler) ListPriceBooks(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var rows []dbgen.PriceBook
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListPriceBooks(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "price_book_list_failed", "failed to load price books")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, priceBookDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h CatalogHandler) CreatePriceBook(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var req struct {
+		Name      string `json:"name"`
+		Currency  string `json:"currency"`
+		IsDefault bool   `json:"isDefault"`
+		IsActive  *bool  `json:"isActive"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "validation_error", "name is required")
+		return
+	}
+	var currency pgtype.Text
+	if strings.TrimSpace(req.Currency) != "" {
+		code, parseErr := parseCurrency(req.Currency)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_currency", parseErr.Error())
+			return
+		}
+		currency = toPGText(code)
+	}
+	var active pgtype.Bool
+	if req.IsActive != nil {
+		active = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	var row dbgen.PriceBook
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if req.IsDefault {
+			if queryErr := q.ClearDefaultPriceBook(r.Context(), toPGUUID(tenantID)); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.CreatePriceBook(r.Context(), dbgen.CreatePriceBookParams{
+			TenantID:  toPGUUID(tenantID),
+			Name:      req.Name,
+			Currency:  currency,
+			IsDefault: req.IsDefault,
+			IsActive:  active,
+		})
+		return queryErr
+	}); err != nil {
+		if isUniqueViolation(err) {
+			writeError(w, http.StatusConflict, "duplicate_price_book", "price book name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "price_book_create_failed", "failed to create price book")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": priceBookDTO(row)})
+}
+
+func (h CatalogHandler) UpdatePriceBook(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	priceBookID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_price_book_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Name      *string `json:"name"`
+		IsDefault *bool   `json:"isDefault"`
+		IsActive  *bool   `json:"isActive"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	params := dbgen.UpdatePriceBookParams{
+		TenantID:    toPGUUID(tenantID),
+		PriceBookID: toPGUUID(priceBookID),
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "validation_error", "name cannot be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	if req.IsDefault != nil {
+		params.IsDefault = pgtype.Bool{Bool: *req.IsDefault, Valid: true}
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	var row dbgen.PriceBook
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetPriceBook(r.Context(), dbgen.GetPriceBookParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		if params.IsDefault.Valid && params.IsDefault.Bool {
+			if queryErr := q.ClearDefaultPriceBook(r.Context(), toPGUUID(tenantID)); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.UpdatePriceBook(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "price book not found")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_price_book", "price book name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "price_book_update_failed", "failed to update price book")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": priceBookDTO(row)})
+}
+
+func (h CatalogHandler) ListPriceBookEntries(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	priceBookID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_price_book_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.ListPriceBookEntriesRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetPriceBook(r.Context(), dbgen.GetPriceBookParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListPriceBookEntries(r.Context(), dbgen.ListPriceBookEntriesParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "price book not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "price_book_entries_failed", "failed to load price book entries")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"id":          pgUUIDToString(row.ID),
+			"priceBookId": pgUUIDToString(row.PriceBookID),
+			"productId":   pgUUIDToString(row.ProductID),
+			"sku":         row.Sku,
+			"productName": row.ProductName,
+			"unitPrice":   pgNumericToFloat(row.UnitPrice),
+			"updatedAt":   pgTimestampToString(row.UpdatedAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h CatalogHandler) UpsertPriceBookEntry(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	priceBookID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_price_book_id", "id must be UUID")
+		return
+	}
+	productID, err := parseUUID(chi.URLParam(r, "productId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_product_id", "productId must be UUID")
+		return
+	}
+
+	var req struct {
+		UnitPrice *float64 `json:"unitPrice"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if req.UnitPrice == nil || *req.UnitPrice < 0 {
+		writeError(w, http.StatusBadRequest, "invalid_unit_price", "unitPrice is required and must be zero or greater")
+		return
+	}
+
+	var row dbgen.PriceBookEntry
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := q.GetPriceBook(r.Context(), dbgen.GetPriceBookParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.GetProduct(r.Context(), dbgen.GetProductParams{
+			TenantID:  toPGUUID(tenantID),
+			ProductID: toPGUUID(productID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		row, queryErr = q.UpsertPriceBookEntry(r.Context(), dbgen.UpsertPriceBookEntryParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+			ProductID:   toPGUUID(productID),
+			UnitPrice:   toPGNumeric(*req.UnitPrice),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "price book or product not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "price_book_entry_failed", "failed to save price book entry")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"id":          pgUUIDToString(row.ID),
+			"priceBookId": pgUUIDToString(row.PriceBookID),
+			"productId":   pgUUIDToString(row.ProductID),
+			"unitPrice":   pgNumericToFloat(row.UnitPrice),
+			"updatedAt":   pgTimestampToString(row.UpdatedAt),
+		},
+	})
+}
+
+func (h CatalogHandler) DeletePriceBookEntry(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	priceBookID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_price_book_id", "id must be UUID")
+		return
+	}
+	productID, err := parseUUID(chi.URLParam(r, "productId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_product_id", "productId must be UUID")
+		return
+	}
+
+	var affected int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		affected, queryErr = q.DeletePriceBookEntry(r.Context(), dbgen.DeletePriceBookEntryParams{
+			TenantID:    toPGUUID(tenantID),
+			PriceBookID: toPGUUID(priceBookID),
+			ProductID:   toPGUUID(productID),
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "price_book_entry_delete_failed", "failed to delete price book entry")
+		return
+	}
+	if affected == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "price book entry not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h CatalogHandler) ExportProductsCSV(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var rows []dbgen.ExportProductsRowsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ExportProductsRows(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "products_export_failed", "failed to export products")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=products.csv")
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{
+		"id", "sku", "name", "description", "unit", "is_active", "list_price", "created_at", "updated_at",
+	})
+	for _, row := range rows {
+		listPrice := ""
+		if row.ListPrice.Valid {
+			listPrice = strconv.FormatFloat(pgNumericToFloat(row.ListPrice), 'f', 2, 64)
+		}
+		_ = writer.Write([]string{
+			pgUUIDToString(row.ID),
+			row.Sku,
+			row.Name,
+			pgTextToString(row.Description),
+			pgTextToString(row.Unit),
+			strconv.FormatBool(row.IsActive),
+			listPrice,
+			pgTimestampToString(row.CreatedAt),
+			pgTimestampToString(row.UpdatedAt),
+		})
+	}
+	writer.Flush()
+}
+
+// ImportProductsCSV upserts products by sku. A list_price column sets the price in the
+// tenant's default price book.
+func (h CatalogHandler) ImportProductsCSV(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	records, err := readCSVRecords(r)
+	if err != nil || len(records) < 2 {
+		writeError(w, http.StatusBadRequest, "invalid_csv", "csv must contain header and at least one row")
+		return
+	}
+
+	headers := buildCSVHeaderIndex(records[0])
+	inserted := 0
+	rowErrors := []string{}
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		for i, rec := range records[1:] {
+			rowNo := i + 2
+			sku := csvCell(rec, headers, "sku")
+			name := csvCell(rec, headers, "name")
+			if sku == "" || name == "" {
+				rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": sku and name are required")
+				continue
+			}
+			var active pgtype.Bool
+			if raw := csvCell(rec, headers, "is_active"); raw != "" {
+				parsed, parseErr := strconv.ParseBool(raw)
+				if parseErr != nil {
+					rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": invalid is_active")
+					continue
+				}
+				active = pgtype.Bool{Bool: parsed, Valid: true}
+			}
+			var listPrice *float64
+			if raw := csvCell(rec, headers, "list_price"); raw != "" {
+				parsed, parseErr := strconv.ParseFloat(raw, 64)
+				if parseErr != nil || parsed < 0 {
+					rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": invalid list_price")
+					continue
+				}
+				listPrice = &parsed
+			}
+
+			product, queryErr := q.UpsertProductBySKU(r.Context(), dbgen.UpsertProductBySKUParams{
+				TenantID:    toPGUUID(tenantID),
+				Sku:         sku,
+				Name:        name,
+				Description: toPGText(csvCell(rec, headers, "description")),
+				Unit:        toPGText(csvCell(rec, headers, "unit")),
+				IsActive:    active,
+			})
+			if queryErr != nil {
+				rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": "+queryErr.Error())
+				continue
+			}
+			if listPrice != nil {
+				if queryErr := setDefaultListPrice(r, q, product, *listPrice); queryErr != nil {
+					if errors.Is(queryErr, errNoDefaultPriceBook) {
+						rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": "+queryErr.Error())
+						continue
+					}
+					return queryErr
+				}
+			}
+			inserted++
+		}
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "products_import_failed", "failed to import products")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"inserted": inserted, "errors": rowErrors})
+}
+
+var errNoDefaultPriceBook = errors.New("tenant has no default price book")
+
+func setDefaultListPrice(r *http.Request, q *dbgen.Queries, product dbgen.Product, price float64) error {
+	book, err := q.GetDefaultPriceBook(r.Context(), product.TenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errNoDefaultPriceBook
+	}
+	if err != nil {
+		return err
+	}
+	_, err = q.UpsertPriceBookEntry(r.Context(), dbgen.UpsertPriceBookEntryParams{
+		TenantID:    product.TenantID,
+		PriceBookID: book.ID,
+		ProductID:   product.ID,
+		UnitPrice:   toPGNumeric(price),
+	})
+	return err
+}
+
+func productDTO(row dbgen.Product) map[string]any {
+	return map[string]any{
+		"id":          pgUUIDToString(row.ID),
+		"sku":         row.Sku,
+		"name":        row.Name,
+		"description": pgTextToString(row.Description),
+		"unit":        pgTextToString(row.Unit),
+		"isActive":    row.IsActive,
+		"taxRate":     pgNumericToFloat(row.TaxRate),
+		"createdAt":   pgTimestampToString(row.CreatedAt),
+		"updatedAt":   pgTimestampToString(row.UpdatedAt),
+	}
+}
+
+func priceBookDTO(row dbgen.PriceBook) map[string]any {
+	return map[string]any{
+		"id":        pgUUIDToString(row.ID),
+		"name":      row.Name,
+		"currency":  row.Currency,
+		"isDefault": row.IsDefault,
+		"isActive":  row.IsActive,
+		"createdAt": pgTimestampToString(row.CreatedAt),
+		"updatedAt": pgTimestampToString(row.UpdatedAt),
+	}
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errNoAcceptedQuote = errors.New("opportunity has no accepted quote; pass quoteId or amount")
+var errMultipleAcceptedQuotes = errors.New("opportunity has more than one accepted quote; pass quoteId")
+var errQuoteNotUsable = errors.New("only a sent quote that is still valid, or an accepted quote, can be closed won")
+var errQuoteCurrencyMismatch = errors.New("quote currency differs from the opportunity currency")
+var errQuoteNotFound = errors.New("quoteId does not belong to this opportunity")
+var errQuoteNotApproved = errors.New("quote matches discount policies that are not approved")
+
+// CloseWon marks the opportunity closed_won and turns the accepted (or chosen) quote into
+// an order. Remaining open quotes are rejected in the same transaction.
+func (h OpportunityHandler) CloseWon(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		QuoteID   string   `json:"quoteId"`
+		Amount    *float64 `json:"amount"`
+		OrderedOn string   `json:"orderedOn"`
+		Note      string   `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	var quoteID pgtype.UUID
+	if strings.TrimSpace(req.QuoteID) != "" {
+		id, parseErr := parseUUID(req.QuoteID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_quote_id", "quoteId must be UUID")
+			return
+		}
+		quoteID = toPGUUID(id)
+	}
+	if req.Amount != nil && *req.Amount < 0 {
+		writeError(w, http.StatusBadRequest, "invalid_amount", "amount must be >= 0")
+		return
+	}
+	orderedOn, err := parseOptionalDate(req.OrderedOn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_ordered_on", "orderedOn must be YYYY-MM-DD")
+		return
+	}
+	now := time.Now().UTC()
+	if !orderedOn.Valid {
+		orderedOn = pgtype.Date{Time: now.Truncate(24 * time.Hour), Valid: true}
+	}
+
+	var opportunity dbgen.Opportunity
+	var order dbgen.Order
+	var quote dbgen.Quote
+	var rejected []dbgen.RejectOpenQuotesRow
+	var pendingChecks []policyCheck
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, current); queryErr != nil {
+			return queryErr
+		}
+		if current.Stage == dbgen.OpportunityStageEnumClosedWon {
+			return errOpportunityNotOpen
+		}
+		if queryErr := checkStageTransition(current.Stage, dbgen.OpportunityStageEnumClosedWon); queryErr != nil {
+			return queryErr
+		}
+
+		if quoteID.Valid {
+			quote, queryErr = q.GetQuoteForUpdate(r.Context(), dbgen.GetQuoteForUpdateParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+				QuoteID:       quoteID,
+			})
+			if errors.Is(queryErr, pgx.ErrNoRows) {
+				return errQuoteNotFound
+			}
+			if queryErr != nil {
+				return queryErr
+			}
+			if !quoteClosable(quote, now) {
+				return errQuoteNotUsable
+			}
+		}
+		if !quoteID.Valid {
+			accepted, queryErr := q.ListAcceptedQuotesForUpdate(r.Context(), dbgen.ListAcceptedQuotesForUpdateParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			switch {
+			case len(accepted) == 1:
+				quote = accepted[0]
+			case len(accepted) > 1:
+				return errMultipleAcceptedQuotes
+			case req.Amount == nil:
+				return errNoAcceptedQuote
+			}
+		}
+		if quote.ID.Valid && quote.Currency != current.Currency {
+			return errQuoteCurrencyMismatch
+		}
+		// The winning quote needs the same discount approvals as sending it would.
+		if quote.ID.Valid {
+			checks, queryErr := checkDiscountPolicies(r.Context(), q, tenantID, quote.ID)
+			if queryErr != nil {
+				return queryErr
+			}
+			for _, check := range checks {
+				if !check.approved() {
+					pendingChecks = checks
+					return errQuoteNotApproved
+				}
+			}
+		}
+
+		amount := quote.Amount
+		if req.Amount != nil {
+			lineCount, queryErr := q.CountOpportunityLineItems(r.Context(), dbgen.CountOpportunityLineItemsParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if lineCount > 0 {
+				return errAmountDerived
+			}
+			amount = toPGNumeric(*req.Amount)
+		}
+
+		if quote.ID.Valid && quote.Status != dbgen.QuoteStatusEnumAccepted {
+			if queryErr := q.UpdateQuoteStatus(r.Context(), dbgen.UpdateQuoteStatusParams{
+				Status:   dbgen.QuoteStatusEnumAccepted,
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  quote.ID,
+			}); queryErr != nil {
+				return queryErr
+			}
+			if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", uuid.UUID(quote.ID.Bytes), map[string]any{
+				"event":      "status_changed",
+				"fromStatus": string(quote.Status),
+				"toStatus":   string(dbgen.QuoteStatusEnumAccepted),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+
+		rejected, queryErr = q.RejectOpenQuotes(r.Context(), dbgen.RejectOpenQuotesParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			KeepQuoteID:   quote.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		for _, item := range rejected {
+			if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", uuid.UUID(item.ID.Bytes), map[string]any{
+				"event":      "status_changed",
+				"fromStatus": string(item.PreviousStatus),
+				"toStatus":   string(dbgen.QuoteStatusEnumRejected),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+
+		opportunity, queryErr = q.CloseOpportunityAsWon(r.Context(), dbgen.CloseOpportunityAsWonParams{
+			Amount:        amount,
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{OpportunityStageEnum: current.Stage, Valid: true}, opportunity, toPGUUID(actorID)); queryErr != nil {
+			return queryErr
+		}
+
+		orderNo, queryErr := nextDocumentNumber(r.Context(), q, tenantID, documentTypeOrder, now)
+		if queryErr != nil {
+			return queryErr
+		}
+		note := strings.TrimSpace(req.Note)
+		if note == "" && quote.ID.Valid {
+			note = "Converted from quote " + quote.QuoteNo
+		}
+		order, queryErr = q.CreateOrder(r.Context(), dbgen.CreateOrderParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			OrderNo:       orderNo,
+			Amount:        amount,
+			Currency:      toPGText(current.Currency),
+			OrderedOn:     orderedOn,
+			Note:          toPGText(note),
+			CreatedBy:     toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if quote.ID.Valid && req.Amount == nil {
+			if opportunity, queryErr = syncQuoteLineItems(r, q, tenantID, quote, &order, opportunity); queryErr != nil {
+				return queryErr
+			}
+		}
+		if queryErr := rebuildOrderSchedule(r.Context(), q, tenantID, order.ID); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "order", uuid.UUID(order.ID.Bytes), map[string]any{
+			"event":   "close_won",
+			"orderNo": order.OrderNo,
+			"quoteId": pgUUIDToString(quote.ID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, map[string]any{
+			"event":     "close_won",
+			"fromStage": string(current.Stage),
+			"orderId":   pgUUIDToString(order.ID),
+			"quoteId":   pgUUIDToString(quote.ID),
+		})
+	}); err != nil {
+		var transitionErr stageTransitionError
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.As(err, &transitionErr):
+			writeStageTransitionError(w, transitionErr)
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case errors.Is(err, errQuoteNotFound):
+			writeError(w, http.StatusBadRequest, "invalid_quote_id", err.Error())
+		case errors.Is(err, errOpportunityNotOpen):
+			writeError(w, http.StatusConflict, "opportunity_closed", "opportunity is already closed_won")
+		case errors.Is(err, errNoAcceptedQuote):
+			writeError(w, http.StatusConflict, "no_accepted_quote", err.Error())
+		case errors.Is(err, errMultipleAcceptedQuotes):
+			writeError(w, http.StatusConflict, "multiple_accepted_quotes", err.Error())
+		case errors.Is(err, errAmountDerived):
+			writeError(w, http.StatusConflict, "amount_derived", err.Error())
+		case errors.Is(err, errQuoteCurrencyMismatch):
+			writeError(w, http.StatusConflict, "currency_mismatch", err.Error())
+		case errors.Is(err, errQuoteNotApproved):
+			writeApprovalRequiredError(w, pendingChecks)
+		case errors.Is(err, errQuoteNotUsable):
+			writeJSON(w, http.StatusConflict, map[string]any{
+				"error": map[string]any{
+					"code":            "quote_not_usable",
+					"message":         err.Error(),
+					"currentStatus":   string(quote.Status),
+					"allowedStatuses": []string{string(dbgen.QuoteStatusEnumSent), string(dbgen.QuoteStatusEnumAccepted)},
+				},
+			})
+		default:
+			writeError(w, http.StatusInternalServerError, "close_won_failed", "failed to close opportunity as won")
+		}
+		return
+	}
+
+	rejectedIDs := make([]string, 0, len(rejected))
+	for _, item := range rejected {
+		rejectedIDs = append(rejectedIDs, pgUUIDToString(item.ID))
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"data": map[string]any{
+			"opportunity":      opportunityDTO(opportunity),
+			"order":            orderDTO(order),
+			"quoteId":          pgUUIDToString(quote.ID),
+			"rejectedQuoteIds": rejectedIDs,
+		},
+	})
+}
+
+// quoteClosable reports whether a quote can win the deal: accepted quotes always can,
+// sent quotes only until their validUntil has passed. Drafts have not been sent (nor
+// cleared discount approval) and the other states are final.
+func quoteClosable(quote dbgen.Quote, now time.Time) bool {
+	switch quote.Status {
+	case dbgen.QuoteStatusEnumAccepted:
+		return true
+	case dbgen.QuoteStatusEnumSent:
+		return !quote.ValidUntil.Valid || !quote.ValidUntil.Time.Before(now.Truncate(24*time.Hour))
+	default:
+		return false
+	}
+}
+
+// syncQuoteLineItems copies the won quote's line items onto the new order and replaces the
+// opportunity's own lines with them, so all three headers report what was actually sold.
+// Quotes without line items leave both untouched.
+func syncQuoteLineItems(r *http.Request, q *dbgen.Queries, tenantID uuid.UUID, quote dbgen.Quote, order *dbgen.Order, opportunity dbgen.Opportunity) (dbgen.Opportunity, error) {
+	copied, err := q.CopyQuoteLineItems(r.Context(), dbgen.CopyQuoteLineItemsParams{
+		OrderID:  order.ID,
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quote.ID,
+	})
+	if err != nil || copied == 0 {
+		return opportunity, err
+	}
+	if *order, err = q.RecalculateOrderAmount(r.Context(), dbgen.RecalculateOrderAmountParams{
+		TenantID: toPGUUID(tenantID),
+		OrderID:  order.ID,
+	}); err != nil {
+		return opportunity, err
+	}
+
+	if err := q.DeleteLineItems(r.Context(), dbgen.DeleteLineItemsParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: opportunity.ID,
+	}); err != nil {
+		return opportunity, err
+	}
+	if _, err := q.CopyQuoteLineItems(r.Context(), dbgen.CopyQuoteLineItemsParams{
+		OpportunityID: opportunity.ID,
+		TenantID:      toPGUUID(tenantID),
+		QuoteID:       quote.ID,
+	}); err != nil {
+		return opportunity, err
+	}
+	return q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: opportunity.ID,
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -23,6 +25,19 @@ func tenantIDFromHeader(r *http.Request) (uuid.UUID, error) {
 	return tenantID, nil
 }
 
+func userIDFromHeader(r *http.Request) (uuid.UUID, error) {
+	raw := r.Header.Get("X-User-ID")
+	if raw == "" {
+		return uuid.Nil, errors.New("X-User-ID header is required")
+	}
+
+	userID, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, errors.New("X-User-ID must be a valid UUID")
+	}
+	return userID, nil
+}
+
 func parseUUID(raw string) (uuid.UUID, error) {
 	return uuid.Parse(raw)
 }
@@ -45,6 +60,49 @@ func toPGTimestamptz(value time.Time) pgtype.Timestamptz {
 	return pgtype.Timestamptz{Time: value, Valid: true}
 }
 
+func parseOptionalDate(raw string) (pgtype.Date, error) {
+	if strings.TrimSpace(raw) == "" {
+		return pgtype.Date{}, nil
+	}
+	d, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return pgtype.Date{}, errors.New("invalid date")
+	}
+	return pgtype.Date{Time: d, Valid: true}, nil
+}
+
+func toPGNumeric(value float64) pgtype.Numeric {
+	var out pgtype.Numeric
+	_ = out.Scan(strconv.FormatFloat(value, 'f', 2, 64))
+	return out
+}
+
+func pgNumericToFloat(value pgtype.Numeric) float64 {
+	f, err := value.Float64Value()
+	if err != nil || !f.Valid {
+		return 0
+	}
+	return f.Float64
+}
+
+// pgNumericOrNil is pgNumericToFloat for optional columns: NULL renders as JSON null.
+func pgNumericOrNil(value pgtype.Numeric) any {
+	if !value.Valid {
+		return nil
+	}
+	return pgNumericToFloat(value)
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 func pgUUIDToString(value pgtype.UUID) string {
 	if !value.Valid {
 		return ""
@@ -0,0 +1,564 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errCompetitorNotFound = errors.New("competitor not found")
+
+type CompetitorHandler struct {
+	Store *store.Store
+}
+
+func NewCompetitorHandler(store *store.Store) CompetitorHandler {
+	return CompetitorHandler{Store: store}
+}
+
+func (h CompetitorHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var active pgtype.Bool
+	if raw := r.URL.Query().Get("active"); raw != "" {
+		parsed, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_active", "active must be true or false")
+			return
+		}
+		active = pgtype.Bool{Bool: parsed, Valid: true}
+	}
+
+	var rows []dbgen.Competitor
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListCompetitors(r.Context(), dbgen.ListCompetitorsParams{
+			TenantID: toPGUUID(tenantID),
+			IsActive: active,
+			Search:   toPGText(strings.TrimSpace(r.URL.Query().Get("q"))),
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "competitor_list_failed", "failed to load competitors")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, competitorDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h CompetitorHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var req struct {
+		Name    string `json:"name"`
+		Website string `json:"website"`
+		Note    string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "validation_error", "name is required")
+		return
+	}
+
+	var row dbgen.Competitor
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.CreateCompetitor(r.Context(), dbgen.CreateCompetitorParams{
+			TenantID: toPGUUID(tenantID),
+			Name:     req.Name,
+			Website:  toPGText(strings.TrimSpace(req.Website)),
+			Note:     toPGText(req.Note),
+		})
+		return queryErr
+	}); err != nil {
+		if isUniqueViolation(err) {
+			writeError(w, http.StatusConflict, "duplicate_competitor", "competitor name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "competitor_create_failed", "failed to create competitor")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": competitorDTO(row)})
+}
+
+func (h CompetitorHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	competitorID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_competitor_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Name     *string `json:"name"`
+		Website  *string `json:"website"`
+		Note     *string `json:"note"`
+		IsActive *bool   `json:"isActive"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateCompetitorParams{
+		TenantID:     toPGUUID(tenantID),
+		CompetitorID: toPGUUID(competitorID),
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "validation_error", "name cannot be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	if req.Website != nil {
+		params.Website = pgtype.Text{String: strings.TrimSpace(*req.Website), Valid: true}
+	}
+	if req.Note != nil {
+		params.Note = pgtype.Text{String: *req.Note, Valid: true}
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	var row dbgen.Competitor
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.UpdateCompetitor(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "competitor not found")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_competitor", "competitor name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "competitor_update_failed", "failed to update competitor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": competitorDTO(row)})
+}
+
+func (h CompetitorHandler) OpportunityCompetitors(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.ListOpportunityCompetitorsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListOpportunityCompetitors(r.Context(), dbgen.ListOpportunityCompetitorsParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "opportunity_competitor_list_failed", "failed to load opportunity competitors")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": opportunityCompetitorListDTO(rows)})
+}
+
+// UpsertOpportunityCompetitor adds a competitor to a deal or updates its note and
+// winner flag. Only closed_lost deals can have a winner, and setting one moves the
+// flag off any other competitor and into the loss record.
+func (h CompetitorHandler) UpsertOpportunityCompetitor(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+	competitorID, err := parseUUID(chi.URLParam(r, "competitorId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_competitor_id", "competitorId must be UUID")
+		return
+	}
+
+	var req struct {
+		IsWinner bool    `json:"isWinner"`
+		Note     *string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	var note pgtype.Text
+	if req.Note != nil {
+		note = pgtype.Text{String: *req.Note, Valid: true}
+	}
+
+	var rows []dbgen.ListOpportunityCompetitorsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		if req.IsWinner && opportunity.Stage != dbgen.OpportunityStageEnumClosedLost {
+			return errOpportunityNotLost
+		}
+		competitor, queryErr := linkOpportunityCompetitor(r, q, tenantID, actorID, opportunityID, competitorID, req.IsWinner, note)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		rows, queryErr = q.ListOpportunityCompetitors(r.Context(), dbgen.ListOpportunityCompetitorsParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, map[string]any{
+			"event":        "competitor_linked",
+			"competitorId": competitorID.String(),
+			"competitor":   competitor.Name,
+			"isWinner":     req.IsWinner,
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errCompetitorNotFound):
+			writeError(w, http.StatusNotFound, "not_found", err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errOpportunityNotLost):
+			writeError(w, http.StatusConflict, "opportunity_not_lost", "isWinner can only be set on closed_lost opportunities")
+		default:
+			writeError(w, http.StatusInternalServerError, "opportunity_competitor_save_failed", "failed to save opportunity competitor")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": opportunityCompetitorListDTO(rows)})
+}
+
+func (h CompetitorHandler) DeleteOpportunityCompetitor(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+	competitorID, err := parseUUID(chi.URLParam(r, "competitorId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_competitor_id", "competitorId must be UUID")
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		affected, queryErr := q.DeleteOpportunityCompetitor(r.Context(), dbgen.DeleteOpportunityCompetitorParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			CompetitorID:  toPGUUID(competitorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if affected == 0 {
+			return errCompetitorNotFound
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, map[string]any{
+			"event":        "competitor_unlinked",
+			"competitorId": competitorID.String(),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errCompetitorNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "competitor is not linked to this opportunity")
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "opportunity_competitor_delete_failed", "failed to remove opportunity competitor")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// linkOpportunityCompetitor upserts the deal-competitor link. When isWinner is set the
+// previous winner is cleared and the loss record's competitor text follows the catalog.
+func linkOpportunityCompetitor(r *http.Request, q *dbgen.Queries, tenantID, actorID, opportunityID, competitorID uuid.UUID, isWinner bool, note pgtype.Text) (dbgen.Competitor, error) {
+	competitor, err := q.GetCompetitor(r.Context(), dbgen.GetCompetitorParams{
+		TenantID:     toPGUUID(tenantID),
+		CompetitorID: toPGUUID(competitorID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dbgen.Competitor{}, errCompetitorNotFound
+	}
+	if err != nil {
+		return dbgen.Competitor{}, err
+	}
+
+	if isWinner {
+		if err := q.ClearOpportunityCompetitorWinner(r.Context(), dbgen.ClearOpportunityCompetitorWinnerParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		}); err != nil {
+			return dbgen.Competitor{}, err
+		}
+		if err := q.SetOpportunityLossCompetitor(r.Context(), dbgen.SetOpportunityLossCompetitorParams{
+			Competitor:    toPGText(competitor.Name),
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		}); err != nil {
+			return dbgen.Competitor{}, err
+		}
+	}
+	_, err = q.UpsertOpportunityCompetitor(r.Context(), dbgen.UpsertOpportunityCompetitorParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: toPGUUID(opportunityID),
+		CompetitorID:  toPGUUID(competitorID),
+		IsWinner:      isWinner,
+		Note:          note,
+		CreatedBy:     toPGUUID(actorID),
+	})
+	return competitor, err
+}
+
+func (h FeaturePackHandler) CompetitiveAnalysis(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	rangeStart, rangeEnd, err := parseDateRange(r, 365)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", err.Error())
+		return
+	}
+
+	var baseCurrency string
+	var rows []dbgen.GetCompetitiveSummaryRow
+	var trend []dbgen.GetCompetitiveTrendRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.GetCompetitiveSummary(r.Context(), dbgen.GetCompetitiveSummaryParams{
+			TenantID:   toPGUUID(tenantID),
+			RangeStart: rangeStart,
+			RangeEnd:   rangeEnd,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		trend, queryErr = q.GetCompetitiveTrend(r.Context(), dbgen.GetCompetitiveTrendParams{
+			TenantID:   toPGUUID(tenantID),
+			RangeStart: rangeStart,
+			RangeEnd:   rangeEnd,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "competitive_analysis_failed", "failed to load competitive analysis")
+		return
+	}
+
+	trendByCompetitor := map[string][]map[string]any{}
+	for _, row := range trend {
+		key := pgUUIDToString(row.CompetitorID)
+		trendByCompetitor[key] = append(trendByCompetitor[key], map[string]any{
+			"month":            row.MonthBucket,
+			"dealCount":        row.DealCount,
+			"wonCount":         row.WonCount,
+			"lostToCount":      row.LostToCount,
+			"winRate":          winRate(row.WonCount, row.DealCount),
+			"lostAmount":       row.LostAmountBase,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	index := map[string]int{}
+	for _, row := range rows {
+		key := pgUUIDToString(row.CompetitorID)
+		pos, ok := index[key]
+		if !ok {
+			pos = len(data)
+			index[key] = pos
+			months := trendByCompetitor[key]
+			if months == nil {
+				months = []map[string]any{}
+			}
+			data = append(data, map[string]any{
+				"competitorId":     key,
+				"competitorName":   row.CompetitorName,
+				"dealCount":        int64(0),
+				"wonCount":         int64(0),
+				"lostCount":        int64(0),
+				"lostToCount":      int64(0),
+				"lostAmount":       0.0,
+				"missingRateCount": int64(0),
+				"byCurrency":       []map[string]any{},
+				"trend":            months,
+			})
+		}
+		item := data[pos]
+		item["dealCount"] = item["dealCount"].(int64) + row.DealCount
+		item["wonCount"] = item["wonCount"].(int64) + row.WonCount
+		item["lostCount"] = item["lostCount"].(int64) + row.LostCount
+		item["lostToCount"] = item["lostToCount"].(int64) + row.LostToCount
+		item["lostAmount"] = item["lostAmount"].(float64) + row.LostAmountBase
+		item["missingRateCount"] = item["missingRateCount"].(int64) + row.MissingRateCount
+		item["byCurrency"] = append(item["byCurrency"].([]map[string]any), map[string]any{
+			"currency":         row.Currency,
+			"dealCount":        row.DealCount,
+			"lostAmount":       row.LostAmount,
+			"lostAmountBase":   row.LostAmountBase,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+	for _, item := range data {
+		item["winRate"] = winRate(item["wonCount"].(int64), item["dealCount"].(int64))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"from":         pgTimestampToString(rangeStart),
+			"to":           pgTimestampToString(rangeEnd),
+			"baseCurrency": baseCurrency,
+		},
+	})
+}
+
+func competitorDTO(row dbgen.Competitor) map[string]any {
+	return map[string]any{
+		"id":        pgUUIDToString(row.ID),
+		"name":      row.Name,
+		"website":   pgTextToString(row.Website),
+		"note":      pgTextToString(row.Note),
+		"isActive":  row.IsActive,
+		"createdAt": pgTimestampToString(row.CreatedAt),
+		"updatedAt": pgTimestampToString(row.UpdatedAt),
+	}
+}
+
+func opportunityCompetitorListDTO(rows []dbgen.ListOpportunityCompetitorsRow) []map[string]any {
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"id":             pgUUIDToString(row.ID),
+			"opportunityId":  pgUUIDToString(row.OpportunityID),
+			"competitorId":   pgUUIDToString(row.CompetitorID),
+			"competitorName": row.CompetitorName,
+			"isWinner":       row.IsWinner,
+			"note":           pgTextToString(row.Note),
+			"createdBy":      pgUUIDToString(row.CreatedBy),
+			"createdAt":      pgTimestampToString(row.CreatedAt),
+			"updatedAt":      pgTimestampToString(row.UpdatedAt),
+		})
+	}
+	return data
+}
+
+// winRate is won over closed deals as a 0-1 fraction, matching stage velocity.
+func winRate(won, closed int64) float64 {
+	if closed == 0 {
+		return 0
+	}
+	return float64(won) / float64(closed)
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CurrencyHandler struct {
+	Store *store.Store
+}
+
+func NewCurrencyHandler(store *store.Store) CurrencyHandler {
+	return CurrencyHandler{Store: store}
+}
+
+func (h CurrencyHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var baseCurrency string
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "currency_settings_failed", "failed to load currency settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"baseCurrency": baseCurrency}})
+}
+
+// UpdateSettings changes the tenant base currency. Existing rates stay keyed to the old
+// base and stop applying, so new rates must be registered against the new base. Admins only.
+func (h CurrencyHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		BaseCurrency string `json:"baseCurrency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	baseCurrency, err := parseCurrency(req.BaseCurrency)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_currency", err.Error())
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		previous, queryErr := q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.UpdateTenantBaseCurrency(r.Context(), dbgen.UpdateTenantBaseCurrencyParams{
+			BaseCurrency: baseCurrency,
+			TenantID:     toPGUUID(tenantID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "tenant", tenantID, map[string]any{
+			"event":        "base_currency_changed",
+			"fromCurrency": previous,
+			"toCurrency":   baseCurrency,
+		})
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "currency_settings_failed", "failed to update currency settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"baseCurrency": baseCurrency}})
+}
+
+func (h CurrencyHandler) ListFxRates(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 100)
+	var currency pgtype.Text
+	if raw := r.URL.Query().Get("currency"); raw != "" {
+		parsed, parseErr := parseCurrency(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_currency", parseErr.Error())
+			return
+		}
+		currency = toPGText(parsed)
+	}
+
+	var baseCurrency string
+	var rows []dbgen.FxRate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListFxRates(r.Context(), dbgen.ListFxRatesParams{
+			TenantID:     toPGUUID(tenantID),
+			Currency:     currency,
+			BaseCurrency: baseCurrency,
+			OffsetCount:  offset,
+			LimitCount:   limit,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "fx_rate_list_failed", "failed to load fx rates")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, fxRateDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{"baseCurrency": baseCurrency},
+	})
+}
+
+// UpsertFxRate registers the rate of one unit of currency in the base currency from
+// effectiveOn onward. Posting the same currency and date again replaces the rate.
+// Admins only.
+func (h CurrencyHandler) UpsertFxRate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		Currency    string  `json:"currency"`
+		Rate        float64 `json:"rate"`
+		EffectiveOn string  `json:"effectiveOn"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_currency", err.Error())
+		return
+	}
+	if req.Rate <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_rate", "rate must be greater than zero")
+		return
+	}
+	effectiveOn, err := parseOptionalDate(req.EffectiveOn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_effective_on", "effectiveOn must be YYYY-MM-DD")
+		return
+	}
+	if !effectiveOn.Valid {
+		effectiveOn = pgtype.Date{Time: time.Now().UTC().Truncate(24 * time.Hour), Valid: true}
+	}
+
+	var row dbgen.FxRate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		baseCurrency, queryErr := q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		if currency == baseCurrency {
+			return errBaseCurrencyRate
+		}
+		row, queryErr = q.UpsertFxRate(r.Context(), dbgen.UpsertFxRateParams{
+			TenantID:     toPGUUID(tenantID),
+			Currency:     currency,
+			BaseCurrency: baseCurrency,
+			Rate:         toPGRate(req.Rate),
+			EffectiveOn:  effectiveOn,
+			CreatedBy:    toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "tenant", tenantID, map[string]any{
+			"event":        "fx_rate_saved",
+			"fxRateId":     row.ID,
+			"currency":     row.Currency,
+			"baseCurrency": row.BaseCurrency,
+			"rate":         pgNumericToFloat(row.Rate),
+			"effectiveOn":  pgDateToString(row.EffectiveOn),
+		})
+	}); err != nil {
+		if errors.Is(err, errBaseCurrencyRate) {
+			writeError(w, http.StatusBadRequest, "invalid_currency", err.Error())
+			return
+		}
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "fx_rate_save_failed", "failed to save fx rate")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": fxRateDTO(row)})
+}
+
+// DeleteFxRate removes one rate; the previous rate of the currency applies again. Admins only.
+func (h CurrencyHandler) DeleteFxRate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	rateID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_fx_rate_id", "id must be an integer")
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr := q.DeleteFxRate(r.Context(), dbgen.DeleteFxRateParams{
+			TenantID: toPGUUID(tenantID),
+			FxRateID: rateID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumDelete, "tenant", tenantID, map[string]any{
+			"event":        "fx_rate_deleted",
+			"fxRateId":     row.ID,
+			"currency":     row.Currency,
+			"baseCurrency": row.BaseCurrency,
+			"rate":         pgNumericToFloat(row.Rate),
+			"effectiveOn":  pgDateToString(row.EffectiveOn),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "fx rate not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "fx_rate_delete_failed", "failed to delete fx rate")
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errBaseCurrencyRate = errors.New("currency must differ from the tenant base currency")
+
+func parseCurrency(raw string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if !currencyCodePattern.MatchString(code) {
+		return "", errors.New("currency must be a 3-letter ISO 4217 code")
+	}
+	return code, nil
+}
+
+func toPGRate(value float64) pgtype.Numeric {
+	var out pgtype.Numeric
+	_ = out.Scan(strconv.FormatFloat(value, 'f', 8, 64))
+	return out
+}
+
+func fxRateDTO(row dbgen.FxRate) map[string]any {
+	return map[string]any{
+		"id":           row.ID,
+		"currency":     row.Currency,
+		"baseCurrency": row.BaseCurrency,
+		"rate":         pgNumericToFloat(row.Rate),
+		"effectiveOn":  pgDateToString(row.EffectiveOn),
+		"createdBy":    pgUUIDToString(row.CreatedBy),
+		"createdAt":    pgTimestampToString(row.CreatedAt),
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// timeCursor is the opaque keyset position handed back to clients as nextCursor.
+type timeCursor struct {
+	At   time.Time `json:"at"`
+	Kind string    `json:"k,omitempty"`
+	ID   string    `json:"id"`
+}
+
+func encodeCursor(c timeCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(raw string) (timeCursor, error) {
+	var c timeCursor
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(decoded, &c); err != nil || c.At.IsZero() || c.ID == "" {
+		return timeCursor{}, errors.New("invalid cursor")
+	}
+	return c, nil
+}
+
+func queryCursorLimit(r *http.Request, defaultLimit int32) int32 {
+	limit := defaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 && parsed <= 200 {
+			limit = int32(parsed)
+		}
+	}
+	return limit
+}
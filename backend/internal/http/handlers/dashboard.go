@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/google/uuid"
+
 	dbgen "sfa/backend/internal/db/sqlc"
 	"sfa/backend/internal/store"
 )
@@ -71,6 +73,34 @@ func (h DashboardHandler) KPI(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// RefreshKPI recomputes the KPI snapshot in the tenant base currency and returns it.
+func (h DashboardHandler) RefreshKPI(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": map[string]string{
+				"code":    "invalid_tenant_id",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		return q.CreateKpiSnapshot(r.Context(), toPGUUID(tenantID))
+	}); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": map[string]string{
+				"code":    "kpi_refresh_failed",
+				"message": "failed to refresh kpi snapshot",
+			},
+		})
+		return
+	}
+
+	h.KPI(w, r)
+}
+
 func (h DashboardHandler) Pipeline(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := tenantIDFromHeader(r)
 	if err != nil {
@@ -83,9 +113,19 @@ func (h DashboardHandler) Pipeline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("groupBy") == "product" {
+		h.pipelineByProduct(w, r, tenantID)
+		return
+	}
+
+	var baseCurrency string
 	var rows []dbgen.GetPipelineSummaryRow
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
 		rows, queryErr = q.GetPipelineSummary(r.Context(), toPGUUID(tenantID))
 		return queryErr
 	}); err != nil {
@@ -98,12 +138,66 @@ func (h DashboardHandler) Pipeline(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	items := make([]map[string]any, 0, len(rows))
+	index := map[dbgen.OpportunityStageEnum]int{}
+	for _, row := range rows {
+		pos, ok := index[row.Stage]
+		if !ok {
+			pos = len(items)
+			index[row.Stage] = pos
+			items = append(items, map[string]any{
+				"stage":            string(row.Stage),
+				"count":            int64(0),
+				"totalAmount":      0.0,
+				"missingRateCount": int64(0),
+				"byCurrency":       []map[string]any{},
+			})
+		}
+		item := items[pos]
+		item["count"] = item["count"].(int64) + row.DealCount
+		item["totalAmount"] = item["totalAmount"].(float64) + row.TotalAmountBase
+		item["missingRateCount"] = item["missingRateCount"].(int64) + row.MissingRateCount
+		item["byCurrency"] = append(item["byCurrency"].([]map[string]any), map[string]any{
+			"currency":         row.Currency,
+			"count":            row.DealCount,
+			"totalAmount":      row.TotalAmount,
+			"totalAmountBase":  row.TotalAmountBase,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": items,
+		"meta": map[string]any{"baseCurrency": baseCurrency},
+	})
+}
+
+func (h DashboardHandler) pipelineByProduct(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) {
+	var rows []dbgen.GetPipelineSummaryByProductRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.GetPipelineSummaryByProduct(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": map[string]string{
+				"code":    "pipeline_query_failed",
+				"message": "failed to fetch pipeline summary",
+			},
+		})
+		return
+	}
+
 	items := make([]map[string]any, 0, len(rows))
 	for _, row := range rows {
 		items = append(items, map[string]any{
-			"stage":       string(row.Stage),
-			"count":       row.DealCount,
-			"totalAmount": row.TotalAmount,
+			"stage":            string(row.Stage),
+			"productId":        pgUUIDToString(row.ProductID),
+			"sku":              row.Sku,
+			"productName":      row.ProductName,
+			"count":            row.DealCount,
+			"totalAmount":      row.TotalAmount,
+			"missingRateCount": row.MissingRateCount,
 		})
 	}
 
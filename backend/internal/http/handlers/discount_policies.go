@@ -0,0 +1,717 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errApprovalInvalidated = errors.New("approval request was invalidated by a change to the quote")
+var errInvalidPolicyApprover = errors.New("approver is not an active member of this tenant")
+var errInvalidDiscountPolicy = errors.New("invalid discount policy")
+
+type DiscountPolicyHandler struct {
+	Store *store.Store
+}
+
+func NewDiscountPolicyHandler(store *store.Store) DiscountPolicyHandler {
+	return DiscountPolicyHandler{Store: store}
+}
+
+type discountPolicyRequest struct {
+	Name           *string  `json:"name"`
+	Metric         *string  `json:"metric"`
+	Threshold      *float64 `json:"threshold"`
+	ApproverUserID *string  `json:"approverUserId"`
+	ChainID        *string  `json:"chainId"`
+	IsActive       *bool    `json:"isActive"`
+}
+
+// validate trims the name and returns the error code and message for the first invalid
+// field. Percent metrics are capped at 100 once metric and threshold are both known.
+func (req *discountPolicyRequest) validate(currentMetric dbgen.DiscountPolicyMetricEnum, currentThreshold float64) (string, string) {
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+		if *req.Name == "" {
+			return "invalid_name", "name must not be empty"
+		}
+	}
+	metric := currentMetric
+	if req.Metric != nil {
+		parsed, err := parseDiscountPolicyMetric(*req.Metric)
+		if err != nil {
+			return "invalid_metric", err.Error()
+		}
+		metric = parsed
+	}
+	threshold := currentThreshold
+	if req.Threshold != nil {
+		threshold = *req.Threshold
+	}
+	if threshold < 0 {
+		return "invalid_threshold", "threshold must be zero or greater"
+	}
+	if metric != dbgen.DiscountPolicyMetricEnumQuoteAmount && threshold > 100 {
+		return "invalid_threshold", "threshold must be between 0 and 100 for percent metrics"
+	}
+	if req.ApproverUserID != nil {
+		if _, err := parseUUID(*req.ApproverUserID); err != nil {
+			return "invalid_approver", "approverUserId must be UUID"
+		}
+	}
+	if req.ChainID != nil && *req.ChainID != "" {
+		if _, err := parseUUID(*req.ChainID); err != nil {
+			return "invalid_chain_id", "chainId must be UUID"
+		}
+	}
+	return "", ""
+}
+
+// policyChain checks that a chain named by a policy exists and is active. An empty id
+// detaches the chain and yields a NULL UUID.
+func policyChain(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, raw string) (pgtype.UUID, error) {
+	if raw == "" {
+		return pgtype.UUID{}, nil
+	}
+	chainID, _ := parseUUID(raw)
+	chain, err := q.GetApprovalChain(ctx, dbgen.GetApprovalChainParams{
+		TenantID: toPGUUID(tenantID),
+		ChainID:  toPGUUID(chainID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !chain.IsActive) {
+		return pgtype.UUID{}, errInvalidApprovalChain
+	}
+	return chain.ID, err
+}
+
+// checkPolicyApprover keeps a missing membership of the named approver apart from the
+// caller's own access errors.
+func checkPolicyApprover(ctx context.Context, q *dbgen.Queries, tenantID, approverID uuid.UUID) error {
+	_, err := actorRole(ctx, q, tenantID, approverID)
+	if errors.Is(err, errNotTenantMember) {
+		return errInvalidPolicyApprover
+	}
+	return err
+}
+
+func (h DiscountPolicyHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var rows []dbgen.DiscountPolicy
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListDiscountPolicies(r.Context(), dbgen.ListDiscountPoliciesParams{
+			TenantID:   toPGUUID(tenantID),
+			ActiveOnly: r.URL.Query().Get("active") == "true",
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "discount_policy_list_failed", "failed to list discount policies")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, discountPolicyDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h DiscountPolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	policyID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_policy_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.DiscountPolicy
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetDiscountPolicy(r.Context(), dbgen.GetDiscountPolicyParams{
+			TenantID: toPGUUID(tenantID),
+			PolicyID: toPGUUID(policyID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "discount policy not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "discount_policy_get_failed", "failed to load discount policy")
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": discountPolicyDTO(row)})
+}
+
+func (h DiscountPolicyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req discountPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if req.Name == nil || req.Metric == nil || req.Threshold == nil || (derefString(req.ApproverUserID) == "" && derefString(req.ChainID) == "") {
+		writeError(w, http.StatusBadRequest, "invalid_request", "name, metric, threshold and approverUserId or chainId are required")
+		return
+	}
+	if code, message := req.validate("", 0); code != "" {
+		writeError(w, http.StatusBadRequest, code, message)
+		return
+	}
+	metric, _ := parseDiscountPolicyMetric(*req.Metric)
+
+	var row dbgen.DiscountPolicy
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		var approverID pgtype.UUID
+		if raw := derefString(req.ApproverUserID); raw != "" {
+			parsed, _ := parseUUID(raw)
+			if queryErr := checkPolicyApprover(r.Context(), q, tenantID, parsed); queryErr != nil {
+				return queryErr
+			}
+			approverID = toPGUUID(parsed)
+		}
+		chainID, queryErr := policyChain(r.Context(), q, tenantID, derefString(req.ChainID))
+		if queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.CreateDiscountPolicy(r.Context(), dbgen.CreateDiscountPolicyParams{
+			TenantID:       toPGUUID(tenantID),
+			Name:           *req.Name,
+			Metric:         metric,
+			Threshold:      toPGNumeric(*req.Threshold),
+			ApproverUserID: approverID,
+			ChainID:        chainID,
+			IsActive:       req.IsActive == nil || *req.IsActive,
+			CreatedBy:      toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "discount_policy", uuid.UUID(row.ID.Bytes), map[string]any{
+			"name":           row.Name,
+			"metric":         string(row.Metric),
+			"threshold":      pgNumericToFloat(row.Threshold),
+			"approverUserId": pgUUIDToString(row.ApproverUserID),
+			"chainId":        pgUUIDToString(row.ChainID),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, errInvalidPolicyApprover):
+			writeError(w, http.StatusBadRequest, "invalid_approver", err.Error())
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errInvalidApprovalChain):
+			writeError(w, http.StatusBadRequest, "invalid_chain_id", "chainId must name an active approval chain")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_discount_policy", "discount policy name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "discount_policy_create_failed", "failed to create discount policy")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": discountPolicyDTO(row)})
+}
+
+// Update changes a policy. Requests it already raised keep their approver; the new
+// settings apply from the next send attempt. An empty chainId detaches the chain.
+func (h DiscountPolicyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	policyID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_policy_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req discountPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	var row dbgen.DiscountPolicy
+	var validationCode, validationMessage string
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetDiscountPolicy(r.Context(), dbgen.GetDiscountPolicyParams{
+			TenantID: toPGUUID(tenantID),
+			PolicyID: toPGUUID(policyID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if validationCode, validationMessage = req.validate(current.Metric, pgNumericToFloat(current.Threshold)); validationCode != "" {
+			return errInvalidDiscountPolicy
+		}
+
+		params := dbgen.UpdateDiscountPolicyParams{
+			TenantID: toPGUUID(tenantID),
+			PolicyID: toPGUUID(policyID),
+		}
+		if req.Name != nil {
+			params.Name = toPGText(*req.Name)
+		}
+		if req.Metric != nil {
+			metric, _ := parseDiscountPolicyMetric(*req.Metric)
+			params.Metric = dbgen.NullDiscountPolicyMetricEnum{DiscountPolicyMetricEnum: metric, Valid: true}
+		}
+		if req.Threshold != nil {
+			params.Threshold = toPGNumeric(*req.Threshold)
+		}
+		if req.ApproverUserID != nil {
+			approverID, _ := parseUUID(*req.ApproverUserID)
+			if queryErr := checkPolicyApprover(r.Context(), q, tenantID, approverID); queryErr != nil {
+				return queryErr
+			}
+			params.ApproverUserID = toPGUUID(approverID)
+		}
+		if req.ChainID != nil {
+			params.SetChain = true
+			params.ChainID, queryErr = policyChain(r.Context(), q, tenantID, *req.ChainID)
+			if queryErr != nil {
+				return queryErr
+			}
+			if !params.ChainID.Valid && !params.ApproverUserID.Valid && !current.ApproverUserID.Valid {
+				validationCode, validationMessage = "invalid_request", "a policy without a chain needs an approverUserId"
+				return errInvalidDiscountPolicy
+			}
+		}
+		if req.IsActive != nil {
+			params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+		}
+		row, queryErr = q.UpdateDiscountPolicy(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "discount_policy", policyID, map[string]any{
+			"name":           row.Name,
+			"metric":         string(row.Metric),
+			"threshold":      pgNumericToFloat(row.Threshold),
+			"approverUserId": pgUUIDToString(row.ApproverUserID),
+			"chainId":        pgUUIDToString(row.ChainID),
+			"isActive":       row.IsActive,
+			"version":        row.Version,
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "discount policy not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errInvalidDiscountPolicy):
+			writeError(w, http.StatusBadRequest, validationCode, validationMessage)
+		case errors.Is(err, errInvalidPolicyApprover):
+			writeError(w, http.StatusBadRequest, "invalid_approver", err.Error())
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errInvalidApprovalChain):
+			writeError(w, http.StatusBadRequest, "invalid_chain_id", "chainId must name an active approval chain")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_discount_policy", "discount policy name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "discount_policy_update_failed", "failed to update discount policy")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": discountPolicyDTO(row)})
+}
+
+// Approvals reports which active policies the quote matches and the approval request
+// that currently covers each of them.
+func (h QuoteHandler) Approvals(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+
+	var checks []policyCheck
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		quote, queryErr := q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(quote.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		checks, queryErr = checkDiscountPolicies(r.Context(), q, tenantID, quote.ID)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_approvals_failed", "failed to check discount policies")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(checks))
+	approved := true
+	for _, check := range checks {
+		data = append(data, check.dto())
+		approved = approved && check.approved()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{"approved": approved},
+	})
+}
+
+// policyCheck is one active policy the quote matches, with the approval request that
+// covers the quote's current content (nil when none was raised yet).
+type policyCheck struct {
+	Policy   dbgen.DiscountPolicy
+	Value    *float64
+	Approval *dbgen.ApprovalRequest
+}
+
+func (c policyCheck) approved() bool {
+	return c.Approval != nil && c.Approval.Status == dbgen.ApprovalStatusEnumApproved
+}
+
+func (c policyCheck) dto() map[string]any {
+	item := map[string]any{
+		"policyId":  pgUUIDToString(c.Policy.ID),
+		"name":      c.Policy.Name,
+		"metric":    string(c.Policy.Metric),
+		"threshold": pgNumericToFloat(c.Policy.Threshold),
+		"value":     nil,
+		"approval":  nil,
+	}
+	if c.Value != nil {
+		item["value"] = *c.Value
+	}
+	if c.Approval != nil {
+		item["approval"] = approvalDTO(*c.Approval)
+	}
+	return item
+}
+
+// checkDiscountPolicies evaluates the tenant's active policies against the quote. An
+// amount policy matches when the quote currency has no exchange rate, so a missing rate
+// never lets a large quote through.
+func checkDiscountPolicies(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, quoteID pgtype.UUID) ([]policyCheck, error) {
+	policies, err := q.ListDiscountPolicies(ctx, dbgen.ListDiscountPoliciesParams{
+		TenantID:   toPGUUID(tenantID),
+		ActiveOnly: true,
+	})
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	metrics, err := q.GetQuoteDiscountMetrics(ctx, dbgen.GetQuoteDiscountMetricsParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := q.ListCurrentQuotePolicyApprovals(ctx, dbgen.ListCurrentQuotePolicyApprovalsParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quoteID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []policyCheck
+	for _, policy := range policies {
+		value, known := discountPolicyValue(policy.Metric, metrics)
+		threshold := pgNumericToFloat(policy.Threshold)
+		if known && value <= threshold {
+			continue
+		}
+		check := policyCheck{Policy: policy}
+		if known {
+			check.Value = &value
+		}
+		// approvals are newest first, so the first one for the policy is the current one.
+		for i := range approvals {
+			if approvals[i].PolicyID == policy.ID {
+				check.Approval = &approvals[i]
+				break
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func discountPolicyValue(metric dbgen.DiscountPolicyMetricEnum, metrics dbgen.GetQuoteDiscountMetricsRow) (float64, bool) {
+	switch metric {
+	case dbgen.DiscountPolicyMetricEnumLineDiscountPercent:
+		return pgNumericToFloat(metrics.MaxLineDiscountPercent), true
+	case dbgen.DiscountPolicyMetricEnumQuoteDiscountPercent:
+		listAmount := pgNumericToFloat(metrics.ListAmount)
+		if listAmount <= 0 {
+			return 0, true
+		}
+		discount := (listAmount - pgNumericToFloat(metrics.Amount)) / listAmount * 100
+		return math.Round(discount*100) / 100, true
+	default:
+		if !metrics.BaseAmount.Valid {
+			return 0, false
+		}
+		return math.Round(pgNumericToFloat(metrics.BaseAmount)*100) / 100, true
+	}
+}
+
+// requireDiscountApprovals raises a pending approval for every matched policy that has
+// none for the quote's current content. It reports whether every matched policy is
+// approved, plus the checks so the caller can show what is outstanding.
+func requireDiscountApprovals(ctx context.Context, q *dbgen.Queries, r *http.Request, tenantID, actorID uuid.UUID, quote dbgen.Quote) (bool, []policyCheck, error) {
+	checks, err := checkDiscountPolicies(ctx, q, tenantID, quote.ID)
+	if err != nil {
+		return false, nil, err
+	}
+	approved := true
+	for i, check := range checks {
+		if check.approved() {
+			continue
+		}
+		approved = false
+		if check.Approval != nil {
+			continue
+		}
+		reason := fmt.Sprintf("Quote %s matches discount policy %q (%s above %s)", quote.QuoteNo, check.Policy.Name, discountPolicyMetricLabel(check.Policy.Metric), formatPolicyThreshold(check.Policy))
+		var metricValue pgtype.Numeric
+		if check.Value != nil {
+			metricValue = toPGNumeric(*check.Value)
+		}
+		if check.Policy.ChainID.Valid {
+			row, err := startApprovalChain(ctx, q, r, tenantID, actorID, check.Policy.ChainID, approvalSubject{
+				EntityType:  "quote",
+				EntityID:    quote.ID,
+				Reason:      reason,
+				PolicyID:    check.Policy.ID,
+				MetricValue: metricValue,
+			})
+			if err != nil {
+				return false, nil, err
+			}
+			checks[i].Approval = &row
+			continue
+		}
+		approverID, err := policyRequestApprover(ctx, q, tenantID, toPGUUID(actorID), check.Policy.ApproverUserID)
+		if err != nil {
+			return false, nil, err
+		}
+		row, err := q.CreatePolicyApprovalRequest(ctx, dbgen.CreatePolicyApprovalRequestParams{
+			TenantID:       toPGUUID(tenantID),
+			QuoteID:        quote.ID,
+			RequestedBy:    toPGUUID(actorID),
+			ApproverUserID: approverID,
+			Reason:         reason,
+			PolicyID:       check.Policy.ID,
+			MetricValue:    metricValue,
+		})
+		if err != nil {
+			return false, nil, err
+		}
+		if err := writeAuditLog(ctx, q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "approval_request", uuid.UUID(row.ID.Bytes), map[string]any{
+			"event":    "policy_approval_requested",
+			"quoteId":  pgUUIDToString(quote.ID),
+			"quoteNo":  quote.QuoteNo,
+			"policyId": pgUUIDToString(check.Policy.ID),
+			"approver": pgUUIDToString(row.ApproverUserID),
+		}); err != nil {
+			return false, nil, err
+		}
+		if err := recordApprovalEvent(ctx, q, tenantID, row.ID, pgtype.Int4{}, "requested", row.RequestedBy, reason, map[string]any{
+			"approverUserId": pgUUIDToString(row.ApproverUserID),
+		}); err != nil {
+			return false, nil, err
+		}
+		checks[i].Approval = &row
+	}
+	return approved, checks, nil
+}
+
+// policyRequestApprover picks the approver of a single-approver policy request. Like
+// chain steps, it never hands the request to the requester: when the policy names the
+// requester, or someone no longer active, the first other active admin takes it.
+func policyRequestApprover(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, requesterID, approverID pgtype.UUID) (pgtype.UUID, error) {
+	if approverID != requesterID {
+		_, err := actorRole(ctx, q, tenantID, uuid.UUID(approverID.Bytes))
+		if err == nil {
+			return approverID, nil
+		}
+		if !errors.Is(err, errNotTenantMember) {
+			return pgtype.UUID{}, err
+		}
+	}
+	admins, err := q.ListActiveMembersByRole(ctx, dbgen.ListActiveMembersByRoleParams{
+		TenantID: toPGUUID(tenantID),
+		Role:     dbgen.RoleEnumAdmin,
+	})
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	admins = withoutUser(admins, requesterID)
+	if len(admins) == 0 {
+		return pgtype.UUID{}, errApprovalNoApprover
+	}
+	return admins[0], nil
+}
+
+// invalidateQuoteApprovals is called whenever a draft quote changes: approvals given for
+// the old content no longer count, and the next send attempt raises fresh requests.
+// Chain steps still open are cancelled and the quote is locked for sending again.
+func invalidateQuoteApprovals(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, quoteID pgtype.UUID) error {
+	rows, err := q.InvalidateQuoteApprovals(ctx, dbgen.InvalidateQuoteApprovalsParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quoteID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.SetQuoteApprovedForSend(ctx, dbgen.SetQuoteApprovedForSendParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quoteID,
+	}); err != nil {
+		return err
+	}
+	return cancelApprovalChains(ctx, q, tenantID, rows, "quote changed")
+}
+
+func writeApprovalRequiredError(w http.ResponseWriter, checks []policyCheck) {
+	code, message := "approval_required", "quote matches discount policies that are not approved yet"
+	outstanding := make([]map[string]any, 0, len(checks))
+	for _, check := range checks {
+		if check.approved() {
+			continue
+		}
+		if check.Approval != nil && check.Approval.Status == dbgen.ApprovalStatusEnumRejected {
+			code, message = "approval_rejected", "a discount approval was rejected; change the quote to request a new one"
+		}
+		outstanding = append(outstanding, check.dto())
+	}
+	writeJSON(w, http.StatusConflict, map[string]any{
+		"error": map[string]any{
+			"code":     code,
+			"message":  message,
+			"policies": outstanding,
+		},
+	})
+}
+
+func parseDiscountPolicyMetric(raw string) (dbgen.DiscountPolicyMetricEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "line_discount_percent":
+		return dbgen.DiscountPolicyMetricEnumLineDiscountPercent, nil
+	case "quote_discount_percent":
+		return dbgen.DiscountPolicyMetricEnumQuoteDiscountPercent, nil
+	case "quote_amount":
+		return dbgen.DiscountPolicyMetricEnumQuoteAmount, nil
+	default:
+		return "", errors.New("metric must be line_discount_percent, quote_discount_percent or quote_amount")
+	}
+}
+
+func discountPolicyMetricLabel(metric dbgen.DiscountPolicyMetricEnum) string {
+	switch metric {
+	case dbgen.DiscountPolicyMetricEnumLineDiscountPercent:
+		return "line discount"
+	case dbgen.DiscountPolicyMetricEnumQuoteDiscountPercent:
+		return "total discount"
+	default:
+		return "amount"
+	}
+}
+
+func formatPolicyThreshold(policy dbgen.DiscountPolicy) string {
+	threshold := pgNumericToFloat(policy.Threshold)
+	if policy.Metric == dbgen.DiscountPolicyMetricEnumQuoteAmount {
+		return fmt.Sprintf("%.0f in base currency", threshold)
+	}
+	return fmt.Sprintf("%g%%", threshold)
+}
+
+func discountPolicyDTO(row dbgen.DiscountPolicy) map[string]any {
+	return map[string]any{
+		"id":             pgUUIDToString(row.ID),
+		"name":           row.Name,
+		"metric":         string(row.Metric),
+		"threshold":      pgNumericToFloat(row.Threshold),
+		"approverUserId": pgUUIDToString(row.ApproverUserID),
+		"chainId":        pgUUIDToString(row.ChainID),
+		"isActive":       row.IsActive,
+		"createdBy":      pgUUIDToString(row.CreatedBy),
+		"createdAt":      pgTimestampToString(row.CreatedAt),
+		"updatedAt":      pgTimestampToString(row.UpdatedAt),
+		"version":        row.Version,
+	}
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+const (
+	documentTypeQuote = "quote"
+	documentTypeOrder = "order"
+)
+
+var documentPrefixPattern = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+
+// defaultNumberFormats apply until a tenant saves its own format. Orders keep the
+// monthly ORD-YYYYMM-0001 numbering they had before formats were configurable.
+var defaultNumberFormats = map[string]dbgen.DocumentNumberFormat{
+	documentTypeQuote: {DocumentType: documentTypeQuote, Prefix: "Q", PeriodFormat: "yearly", Padding: 5},
+	documentTypeOrder: {DocumentType: documentTypeOrder, Prefix: "ORD", PeriodFormat: "monthly", Padding: 4},
+}
+
+// nextDocumentNumber reserves the next number for documentType using the tenant's format,
+// e.g. Q-2026-00042 or ORD-202604-0007. The counter row is locked until the caller's
+// transaction ends, so concurrent requests never share a number and numbers are gap-free
+// per tenant as long as the transaction commits.
+func nextDocumentNumber(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, documentType string, now time.Time) (string, error) {
+	format, err := documentNumberFormat(ctx, q, tenantID, documentType)
+	if err != nil {
+		return "", err
+	}
+	var period string
+	if format.PeriodFormat == "yearly" || format.PeriodFormat == "monthly" {
+		location, err := tenantLocation(ctx, q, tenantID)
+		if err != nil {
+			return "", err
+		}
+		if format.PeriodFormat == "yearly" {
+			period = now.In(location).Format("2006")
+		} else {
+			period = now.In(location).Format("200601")
+		}
+	}
+	seq, err := q.NextDocumentSequence(ctx, dbgen.NextDocumentSequenceParams{
+		TenantID: toPGUUID(tenantID),
+		Prefix:   format.Prefix,
+		Period:   period,
+	})
+	if err != nil {
+		return "", err
+	}
+	if period == "" {
+		return fmt.Sprintf("%s-%0*d", format.Prefix, format.Padding, seq), nil
+	}
+	return fmt.Sprintf("%s-%s-%0*d", format.Prefix, period, format.Padding, seq), nil
+}
+
+// tenantLocation returns the tenant's time zone, so a number issued just after local
+// midnight on New Year's Day or the 1st of a month lands in the new period.
+func tenantLocation(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID) (*time.Location, error) {
+	name, err := q.GetTenantTimeZone(ctx, toPGUUID(tenantID))
+	if err != nil {
+		return nil, err
+	}
+	return time.LoadLocation(name)
+}
+
+func documentNumberFormat(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, documentType string) (dbgen.DocumentNumberFormat, error) {
+	format, err := q.GetDocumentNumberFormat(ctx, dbgen.GetDocumentNumberFormatParams{
+		TenantID:     toPGUUID(tenantID),
+		DocumentType: documentType,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultNumberFormats[documentType], nil
+	}
+	return format, err
+}
+
+type DocumentHandler struct {
+	Store *store.Store
+}
+
+func NewDocumentHandler(store *store.Store) DocumentHandler {
+	return DocumentHandler{Store: store}
+}
+
+func (h DocumentHandler) ListNumberFormats(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	formats := []dbgen.DocumentNumberFormat{}
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		for _, documentType := range []string{documentTypeOrder, documentTypeQuote} {
+			format, queryErr := documentNumberFormat(r.Context(), q, tenantID, documentType)
+			if queryErr != nil {
+				return queryErr
+			}
+			formats = append(formats, format)
+		}
+		return nil
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "document_settings_failed", "failed to load document number formats")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(formats))
+	for _, format := range formats {
+		data = append(data, documentNumberFormatDTO(format))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// UpdateNumberFormat changes how future numbers look. Issued numbers are kept; a new
+// prefix or period format starts its own counter. Admins only.
+func (h DocumentHandler) UpdateNumberFormat(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	documentType := chi.URLParam(r, "documentType")
+	if _, ok := defaultNumberFormats[documentType]; !ok {
+		writeError(w, http.StatusBadRequest, "invalid_document_type", "documentType must be quote or order")
+		return
+	}
+
+	var req struct {
+		Prefix       string `json:"prefix"`
+		PeriodFormat string `json:"periodFormat"`
+		Padding      int32  `json:"padding"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	prefix := strings.ToUpper(strings.TrimSpace(req.Prefix))
+	if !documentPrefixPattern.MatchString(prefix) {
+		writeError(w, http.StatusBadRequest, "invalid_prefix", "prefix must be 1-10 letters or digits")
+		return
+	}
+	switch req.PeriodFormat {
+	case "none", "yearly", "monthly":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_period_format", "periodFormat must be none, yearly or monthly")
+		return
+	}
+	if req.Padding < 1 || req.Padding > 10 {
+		writeError(w, http.StatusBadRequest, "invalid_padding", "padding must be between 1 and 10")
+		return
+	}
+
+	var row dbgen.DocumentNumberFormat
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		row, queryErr = q.UpsertDocumentNumberFormat(r.Context(), dbgen.UpsertDocumentNumberFormatParams{
+			TenantID:     toPGUUID(tenantID),
+			DocumentType: documentType,
+			Prefix:       prefix,
+			PeriodFormat: req.PeriodFormat,
+			Padding:      req.Padding,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "tenant", tenantID, map[string]any{
+			"event":        "document_number_format_changed",
+			"documentType": documentType,
+			"prefix":       prefix,
+			"periodFormat": req.PeriodFormat,
+			"padding":      req.Padding,
+		})
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "document_settings_failed", "failed to update document number format")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": documentNumberFormatDTO(row)})
+}
+
+func (h DocumentHandler) GetTaxSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var rounding dbgen.TaxRoundingEnum
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rounding, queryErr = q.GetTenantTaxRounding(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "tax_settings_failed", "failed to load tax settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": taxSettingsDTO(rounding)})
+}
+
+// UpdateTaxSettings changes the rounding applied from the next quote recalculation on;
+// totals already stored are not recomputed. Admins only.
+func (h DocumentHandler) UpdateTaxSettings(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		Rounding string `json:"rounding"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	var rounding dbgen.TaxRoundingEnum
+	switch strings.ToLower(strings.TrimSpace(req.Rounding)) {
+	case "floor":
+		rounding = dbgen.TaxRoundingEnumFloor
+	case "round":
+		rounding = dbgen.TaxRoundingEnumRound
+	case "ceil":
+		rounding = dbgen.TaxRoundingEnumCeil
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_rounding", "rounding must be floor, round or ceil")
+		return
+	}
+
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := requireAdmin(r.Context(), q, tenantID, actorID); queryErr != nil {
+			return queryErr
+		}
+		previous, queryErr := q.GetTenantTaxRounding(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.UpdateTenantTaxRounding(r.Context(), dbgen.UpdateTenantTaxRoundingParams{
+			TaxRounding: rounding,
+			TenantID:    toPGUUID(tenantID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "tenant", tenantID, map[string]any{
+			"event":        "tax_rounding_changed",
+			"fromRounding": string(previous),
+			"toRounding":   string(rounding),
+		})
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "tax_settings_failed", "failed to update tax settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": taxSettingsDTO(rounding)})
+}
+
+func documentNumberFormatDTO(row dbgen.DocumentNumberFormat) map[string]any {
+	example := row.Prefix
+	switch row.PeriodFormat {
+	case "yearly":
+		example += "-YYYY"
+	case "monthly":
+		example += "-YYYYMM"
+	}
+	example += "-" + strings.Repeat("0", int(row.Padding)-1) + "1"
+	return map[string]any{
+		"documentType": row.DocumentType,
+		"prefix":       row.Prefix,
+		"periodFormat": row.PeriodFormat,
+		"padding":      row.Padding,
+		"example":      example,
+	}
+}
+
+func taxSettingsDTO(rounding dbgen.TaxRoundingEnum) map[string]any {
+	return map[string]any{
+		"rounding": string(rounding),
+		"rates":    []float64{10, 8, 0},
+	}
+}
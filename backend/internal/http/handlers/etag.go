@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errPreconditionFailed = errors.New("record has changed since it was read; reload and retry")
+
+// ifMatchVersion reads the If-Match header. ok is false when the header is absent or
+// "*", in which case the write is unconditional.
+func ifMatchVersion(r *http.Request) (version int64, ok bool, err error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, false, nil
+	}
+	raw = strings.TrimPrefix(raw, "W/")
+	version, err = strconv.ParseInt(strings.Trim(raw, `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, false, errors.New(`If-Match must be an ETag returned by this API, e.g. "3"`)
+	}
+	return version, true, nil
+}
+
+// checkIfMatch compares the If-Match version with the locked row's current version.
+func checkIfMatch(expected int64, ok bool, current int64) error {
+	if ok && expected != current {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
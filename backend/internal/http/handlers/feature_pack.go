@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"io"
 	"math"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
@@ -103,10 +106,27 @@ func (h FeaturePackHandler) UpdateNextAction(w http.ResponseWriter, r *http.Requ
 		writeError(w, http.StatusBadRequest, "invalid_next_action_at", "nextActionAt must be RFC3339")
 		return
 	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
 
 	var row dbgen.Opportunity
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
-		var queryErr error
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
 		row, queryErr = q.UpdateOpportunityNextAction(r.Context(), dbgen.UpdateOpportunityNextActionParams{
 			NextActionAt:   toPGTimestamptz(actionAt.UTC()),
 			NextActionNote: toPGText(req.NextActionNote),
@@ -115,20 +135,25 @@ func (h FeaturePackHandler) UpdateNextAction(w http.ResponseWriter, r *http.Requ
 		})
 		return queryErr
 	}); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
 			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
-			return
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "update_next_action_failed", "failed to update next action")
 		}
-		writeError(w, http.StatusInternalServerError, "update_next_action_failed", "failed to update next action")
 		return
 	}
 
+	setETag(w, row.Version)
 	writeJSON(w, http.StatusOK, map[string]any{
 		"data": map[string]any{
 			"id":             pgUUIDToString(row.ID),
 			"name":           row.Name,
 			"nextActionAt":   pgTimestampToString(row.NextActionAt),
 			"nextActionNote": pgTextToString(row.NextActionNote),
+			"version":        row.Version,
 		},
 	})
 }
@@ -178,9 +203,24 @@ func (h FeaturePackHandler) Forecast(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch r.URL.Query().Get("attribution") {
+	case "", "owner":
+	case "split":
+		h.splitForecast(w, r, tenantID)
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_attribution", "attribution must be owner or split")
+		return
+	}
+
+	var baseCurrency string
 	var rows []dbgen.GetForecastSummaryRow
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
 		rows, queryErr = q.GetForecastSummary(r.Context(), toPGUUID(tenantID))
 		return queryErr
 	}); err != nil {
@@ -188,18 +228,111 @@ func (h FeaturePackHandler) Forecast(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Rows arrive per owner, month and currency; amounts are summed in the base currency
+	// and the per-currency originals are kept under byCurrency.
 	data := make([]map[string]any, 0, len(rows))
+	index := map[string]int{}
 	for _, row := range rows {
-		data = append(data, map[string]any{
-			"ownerUserId":    pgUUIDToString(row.OwnerUserID),
-			"month":          row.MonthBucket,
-			"dealCount":      row.DealCount,
-			"pipelineAmount": row.PipelineAmount,
-			"weightedAmount": row.WeightedAmount,
+		key := pgUUIDToString(row.OwnerUserID) + "|" + row.MonthBucket
+		pos, ok := index[key]
+		if !ok {
+			pos = len(data)
+			index[key] = pos
+			data = append(data, map[string]any{
+				"ownerUserId":      pgUUIDToString(row.OwnerUserID),
+				"month":            row.MonthBucket,
+				"dealCount":        int64(0),
+				"pipelineAmount":   0.0,
+				"weightedAmount":   0.0,
+				"missingRateCount": int64(0),
+				"byCurrency":       []map[string]any{},
+			})
+		}
+		item := data[pos]
+		item["dealCount"] = item["dealCount"].(int64) + row.DealCount
+		item["pipelineAmount"] = item["pipelineAmount"].(float64) + row.PipelineAmountBase
+		item["weightedAmount"] = item["weightedAmount"].(float64) + row.WeightedAmountBase
+		item["missingRateCount"] = item["missingRateCount"].(int64) + row.MissingRateCount
+		item["byCurrency"] = append(item["byCurrency"].([]map[string]any), map[string]any{
+			"currency":           row.Currency,
+			"dealCount":          row.DealCount,
+			"pipelineAmount":     row.PipelineAmount,
+			"weightedAmount":     row.WeightedAmount,
+			"pipelineAmountBase": row.PipelineAmountBase,
+			"weightedAmountBase": row.WeightedAmountBase,
+			"missingRateCount":   row.MissingRateCount,
 		})
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{"baseCurrency": baseCurrency, "attribution": "owner"},
+	})
+}
+
+// splitForecast credits each deal to its team by split percentage (the owner when the
+// deal has no split) and adds won amounts next to the open pipeline.
+func (h FeaturePackHandler) splitForecast(w http.ResponseWriter, r *http.Request, tenantID uuid.UUID) {
+	var baseCurrency string
+	var rows []dbgen.GetSplitForecastSummaryRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.GetSplitForecastSummary(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "forecast_failed", "failed to load forecast")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	index := map[string]int{}
+	for _, row := range rows {
+		key := pgUUIDToString(row.UserID) + "|" + row.MonthBucket
+		pos, ok := index[key]
+		if !ok {
+			pos = len(data)
+			index[key] = pos
+			data = append(data, map[string]any{
+				"userId":           pgUUIDToString(row.UserID),
+				"month":            row.MonthBucket,
+				"dealCount":        int64(0),
+				"pipelineAmount":   0.0,
+				"weightedAmount":   0.0,
+				"wonCount":         int64(0),
+				"wonAmount":        0.0,
+				"missingRateCount": int64(0),
+				"byCurrency":       []map[string]any{},
+			})
+		}
+		item := data[pos]
+		item["dealCount"] = item["dealCount"].(int64) + row.DealCount
+		item["pipelineAmount"] = item["pipelineAmount"].(float64) + row.PipelineAmountBase
+		item["weightedAmount"] = item["weightedAmount"].(float64) + row.WeightedAmountBase
+		item["wonCount"] = item["wonCount"].(int64) + row.WonCount
+		item["wonAmount"] = item["wonAmount"].(float64) + row.WonAmountBase
+		item["missingRateCount"] = item["missingRateCount"].(int64) + row.MissingRateCount
+		item["byCurrency"] = append(item["byCurrency"].([]map[string]any), map[string]any{
+			"currency":           row.Currency,
+			"dealCount":          row.DealCount,
+			"pipelineAmount":     row.PipelineAmount,
+			"weightedAmount":     row.WeightedAmount,
+			"wonCount":           row.WonCount,
+			"wonAmount":          row.WonAmount,
+			"pipelineAmountBase": row.PipelineAmountBase,
+			"weightedAmountBase": row.WeightedAmountBase,
+			"wonAmountBase":      row.WonAmountBase,
+			"missingRateCount":   row.MissingRateCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{"baseCurrency": baseCurrency, "attribution": "split"},
+	})
 }
 
 func (h FeaturePackHandler) LossReasonAnalysis(w http.ResponseWriter, r *http.Request) {
@@ -209,9 +342,14 @@ func (h FeaturePackHandler) LossReasonAnalysis(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	var baseCurrency string
 	var rows []dbgen.GetLossReasonAnalysisRow
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
 		rows, queryErr = q.GetLossReasonAnalysis(r.Context(), toPGUUID(tenantID))
 		return queryErr
 	}); err != nil {
@@ -220,14 +358,42 @@ func (h FeaturePackHandler) LossReasonAnalysis(w http.ResponseWriter, r *http.Re
 	}
 
 	data := make([]map[string]any, 0, len(rows))
+	index := map[dbgen.LossReasonEnum]int{}
 	for _, row := range rows {
-		data = append(data, map[string]any{
-			"reason":     string(row.Reason),
-			"lostCount":  row.LostCount,
-			"lostAmount": row.LostAmount,
+		pos, ok := index[row.Reason]
+		if !ok {
+			pos = len(data)
+			index[row.Reason] = pos
+			data = append(data, map[string]any{
+				"reason":           string(row.Reason),
+				"lostCount":        int64(0),
+				"lostAmount":       0.0,
+				"missingRateCount": int64(0),
+				"byCurrency":       []map[string]any{},
+			})
+		}
+		item := data[pos]
+		item["lostCount"] = item["lostCount"].(int64) + row.LostCount
+		item["lostAmount"] = item["lostAmount"].(float64) + row.LostAmountBase
+		item["missingRateCount"] = item["missingRateCount"].(int64) + row.MissingRateCount
+		item["byCurrency"] = append(item["byCurrency"].([]map[string]any), map[string]any{
+			"currency":         row.Currency,
+			"lostCount":        row.LostCount,
+			"lostAmount":       row.LostAmount,
+			"lostAmountBase":   row.LostAmountBase,
+			"missingRateCount": row.MissingRateCount,
 		})
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	sort.SliceStable(data, func(i, j int) bool {
+		if data[i]["lostCount"].(int64) != data[j]["lostCount"].(int64) {
+			return data[i]["lostCount"].(int64) > data[j]["lostCount"].(int64)
+		}
+		return data[i]["lostAmount"].(float64) > data[j]["lostAmount"].(float64)
+	})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{"baseCurrency": baseCurrency},
+	})
 }
 
 func (h FeaturePackHandler) DuplicateCandidates(w http.ResponseWriter, r *http.Request) {
@@ -363,61 +529,142 @@ func (h FeaturePackHandler) ListIntegrationConnections(w http.ResponseWriter, r
 	writeJSON(w, http.StatusOK, map[string]any{"data": data})
 }
 
+// CreateApprovalRequest raises a request for a single approver, or walks the given
+// chain when chainId is set.
 func (h FeaturePackHandler) CreateApprovalRequest(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := tenantIDFromHeader(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
 		return
 	}
+	requestedBy, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
 
 	var req struct {
-		EntityType     string `json:"entityType"`
-		EntityID       string `json:"entityId"`
-		RequestedBy    string `json:"requestedBy"`
-		ApproverUserID string `json:"approverUserId"`
-		Reason         string `json:"reason"`
+		EntityType     string   `json:"entityType"`
+		EntityID       string   `json:"entityId"`
+		ApproverUserID string   `json:"approverUserId"`
+		ChainID        string   `json:"chainId"`
+		MetricValue    *float64 `json:"metricValue"`
+		Reason         string   `json:"reason"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
 		return
 	}
 
-	entityID, err := parseUUID(req.EntityID)
+	entityType, err := parseApprovalEntityType(req.EntityType)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_entity_id", "entityId must be UUID")
+		writeError(w, http.StatusBadRequest, "invalid_entity_type", err.Error())
 		return
 	}
-	requestedBy, err := parseUUID(req.RequestedBy)
+	entityID, err := parseUUID(req.EntityID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_requested_by", "requestedBy must be UUID")
+		writeError(w, http.StatusBadRequest, "invalid_entity_id", "entityId must be UUID")
 		return
 	}
-	approverID, err := parseUUID(req.ApproverUserID)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid_approver", "approverUserId must be UUID")
-		return
+	var chainID, approverID uuid.UUID
+	if strings.TrimSpace(req.ChainID) != "" {
+		chainID, err = parseUUID(req.ChainID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_chain_id", "chainId must be UUID")
+			return
+		}
+	} else {
+		approverID, err = parseUUID(req.ApproverUserID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_approver", "approverUserId must be UUID")
+			return
+		}
+		if approverID == requestedBy {
+			writeError(w, http.StatusBadRequest, "invalid_approver", "approverUserId must not be the requester")
+			return
+		}
 	}
 
 	var row dbgen.ApprovalRequest
+	var entities map[approvalEntityKey]json.RawMessage
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if _, queryErr := actorRole(r.Context(), q, tenantID, requestedBy); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkApprovalEntity(r.Context(), q, tenantID, entityType, toPGUUID(entityID)); queryErr != nil {
+			return queryErr
+		}
+		if chainID != uuid.Nil {
+			chain, queryErr := q.GetApprovalChain(r.Context(), dbgen.GetApprovalChainParams{
+				TenantID: toPGUUID(tenantID),
+				ChainID:  toPGUUID(chainID),
+			})
+			if errors.Is(queryErr, pgx.ErrNoRows) || (queryErr == nil && !chain.IsActive) {
+				return errInvalidApprovalChain
+			}
+			if queryErr != nil {
+				return queryErr
+			}
+			subject := approvalSubject{
+				EntityType: entityType,
+				EntityID:   toPGUUID(entityID),
+				Reason:     req.Reason,
+			}
+			if req.MetricValue != nil {
+				subject.MetricValue = toPGNumeric(*req.MetricValue)
+			}
+			row, queryErr = startApprovalChain(r.Context(), q, r, tenantID, requestedBy, chain.ID, subject)
+			if queryErr != nil {
+				return queryErr
+			}
+			entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, []dbgen.ApprovalRequest{row})
+			return queryErr
+		}
 		var queryErr error
 		row, queryErr = q.CreateApprovalRequest(r.Context(), dbgen.CreateApprovalRequestParams{
 			TenantID:       toPGUUID(tenantID),
-			EntityType:     req.EntityType,
+			EntityType:     entityType,
 			EntityID:       toPGUUID(entityID),
 			RequestedBy:    toPGUUID(requestedBy),
 			ApproverUserID: toPGUUID(approverID),
 			Reason:         req.Reason,
 		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := recordApprovalEvent(r.Context(), q, tenantID, row.ID, pgtype.Int4{}, "requested", row.RequestedBy, row.Reason, map[string]any{
+			"approverUserId": pgUUIDToString(row.ApproverUserID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, []dbgen.ApprovalRequest{row})
 		return queryErr
 	}); err != nil {
-		writeError(w, http.StatusInternalServerError, "approval_create_failed", "failed to create approval request")
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errInvalidApprovalEntity):
+			writeError(w, http.StatusBadRequest, "invalid_reference", err.Error())
+		case errors.Is(err, errInvalidApprovalChain):
+			writeError(w, http.StatusBadRequest, "invalid_chain_id", "chainId must name an active approval chain")
+		case errors.Is(err, errApprovalChainEmpty):
+			writeError(w, http.StatusConflict, "approval_chain_empty", err.Error())
+		case errors.Is(err, errApprovalNoApprover):
+			writeError(w, http.StatusConflict, "approval_no_approver", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "approval_create_failed", "failed to create approval request")
+		}
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]any{"data": approvalDTO(row)})
+	data := approvalDTO(row)
+	data["entity"] = approvalEntityDTO(row, entities)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": data})
 }
 
+// ListApprovalRequests pages through approval requests newest first. ?approverUserId
+// narrows the list to requests the user may decide now; chain requests carry their
+// current step with its approvers.
 func (h FeaturePackHandler) ListApprovalRequests(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := tenantIDFromHeader(r)
 	if err != nil {
@@ -435,16 +682,37 @@ func (h FeaturePackHandler) ListApprovalRequests(w http.ResponseWriter, r *http.
 		}
 		status = dbgen.NullApprovalStatusEnum{ApprovalStatusEnum: parsed, Valid: true}
 	}
+	var assignee pgtype.UUID
+	if raw := r.URL.Query().Get("approverUserId"); raw != "" {
+		parsed, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_approver", "approverUserId must be UUID")
+			return
+		}
+		assignee = toPGUUID(parsed)
+	}
 
 	var rows []dbgen.ApprovalRequest
+	var steps []dbgen.ApprovalRequestStep
+	var approvers []dbgen.ListApprovalStepApproversRow
+	var entities map[approvalEntityKey]json.RawMessage
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
 		rows, queryErr = q.ListApprovalRequests(r.Context(), dbgen.ListApprovalRequestsParams{
 			TenantID:    toPGUUID(tenantID),
 			Status:      status,
+			Assignee:    assignee,
 			OffsetCount: offset,
 			LimitCount:  limit,
 		})
+		if queryErr != nil {
+			return queryErr
+		}
+		steps, approvers, queryErr = loadCurrentApprovalSteps(r.Context(), q, tenantID, rows)
+		if queryErr != nil {
+			return queryErr
+		}
+		entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, rows)
 		return queryErr
 	}); err != nil {
 		writeError(w, http.StatusInternalServerError, "approval_list_failed", "failed to load approvals")
@@ -453,17 +721,73 @@ func (h FeaturePackHandler) ListApprovalRequests(w http.ResponseWriter, r *http.
 
 	data := make([]map[string]any, 0, len(rows))
 	for _, row := range rows {
-		data = append(data, approvalDTO(row))
+		item := approvalDTO(row)
+		item["entity"] = approvalEntityDTO(row, entities)
+		for _, step := range steps {
+			if step.ApprovalRequestID == row.ID {
+				item["currentStep"] = approvalStepDTO(step, approvers)
+			}
+		}
+		data = append(data, item)
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"data": data})
 }
 
+// loadCurrentApprovalSteps returns the step each chain request is waiting on, with the
+// users who may decide it.
+func loadCurrentApprovalSteps(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, rows []dbgen.ApprovalRequest) ([]dbgen.ApprovalRequestStep, []dbgen.ListApprovalStepApproversRow, error) {
+	requestIDs := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		if row.CurrentStep.Valid {
+			requestIDs = append(requestIDs, row.ID)
+		}
+	}
+	if len(requestIDs) == 0 {
+		return nil, nil, nil
+	}
+	all, err := q.ListApprovalRequestSteps(ctx, dbgen.ListApprovalRequestStepsParams{
+		TenantID:           toPGUUID(tenantID),
+		ApprovalRequestIds: requestIDs,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	current := map[pgtype.UUID]int32{}
+	for _, row := range rows {
+		if row.CurrentStep.Valid {
+			current[row.ID] = row.CurrentStep.Int32
+		}
+	}
+	var steps []dbgen.ApprovalRequestStep
+	stepIDs := make([]pgtype.UUID, 0, len(requestIDs))
+	for _, step := range all {
+		if order, ok := current[step.ApprovalRequestID]; ok && order == step.StepOrder {
+			steps = append(steps, step)
+			stepIDs = append(stepIDs, step.ID)
+		}
+	}
+	approvers, err := q.ListApprovalStepApprovers(ctx, dbgen.ListApprovalStepApproversParams{
+		TenantID: toPGUUID(tenantID),
+		StepIds:  stepIDs,
+	})
+	return steps, approvers, err
+}
+
+// DecideApproval records a decision by the approver (X-User-ID) or a delegate standing
+// in for them. Only pending requests can be decided; on chain requests the decision
+// applies to the current step and approving moves on to the next one. Once the request
+// is approved or rejected, the effect for its entity type runs in the same transaction.
 func (h FeaturePackHandler) DecideApproval(w http.ResponseWriter, r *http.Request) {
 	tenantID, err := tenantIDFromHeader(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
 		return
 	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
 
 	approvalID, err := parseUUID(chi.URLParam(r, "id"))
 	if err != nil {
@@ -480,31 +804,319 @@ func (h FeaturePackHandler) DecideApproval(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	status, err := parseApprovalStatus(req.Status)
+	if err == nil && status != dbgen.ApprovalStatusEnumApproved && status != dbgen.ApprovalStatusEnumRejected {
+		err = errors.New("status must be approved or rejected")
+	}
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_status", err.Error())
 		return
 	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
 
 	var row dbgen.ApprovalRequest
+	var entities map[approvalEntityKey]json.RawMessage
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := lockApprovalRequest(r.Context(), q, tenantID, approvalID)
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if current.InvalidatedAt.Valid {
+			return errApprovalInvalidated
+		}
+		if current.Status != dbgen.ApprovalStatusEnumPending {
+			return errApprovalNotPending
+		}
+
+		if current.ChainID.Valid {
+			row, queryErr = advanceApprovalChain(r.Context(), q, tenantID, actorID, current, status, req.DecisionNote)
+			if queryErr != nil {
+				return queryErr
+			}
+		} else {
+			if current.RequestedBy == toPGUUID(actorID) {
+				return errSelfApproval
+			}
+			onBehalfOf := pgtype.UUID{}
+			if current.ApproverUserID != toPGUUID(actorID) {
+				delegate, queryErr := isActiveDelegate(r.Context(), q, tenantID, current.ApproverUserID, toPGUUID(actorID))
+				if queryErr != nil {
+					return queryErr
+				}
+				if !delegate {
+					return errNotApprover
+				}
+				onBehalfOf = current.ApproverUserID
+			}
+			row, queryErr = q.DecideApprovalRequest(r.Context(), dbgen.DecideApprovalRequestParams{
+				Status:       status,
+				DecisionNote: toPGText(req.DecisionNote),
+				TenantID:     toPGUUID(tenantID),
+				ApprovalID:   toPGUUID(approvalID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			data := map[string]any{}
+			if onBehalfOf.Valid {
+				data["onBehalfOf"] = pgUUIDToString(onBehalfOf)
+			}
+			if queryErr := recordApprovalEvent(r.Context(), q, tenantID, row.ID, pgtype.Int4{}, string(status), toPGUUID(actorID), req.DecisionNote, data); queryErr != nil {
+				return queryErr
+			}
+		}
+
+		if row.Status != dbgen.ApprovalStatusEnumPending {
+			if queryErr := applyApprovalEffect(r.Context(), q, r, tenantID, actorID, row); queryErr != nil {
+				return queryErr
+			}
+			if queryErr := notifyApprovalDecision(r.Context(), q, tenantID, row); queryErr != nil {
+				return queryErr
+			}
+		}
+		if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "approval_request", approvalID, map[string]any{
+			"event":       "decided",
+			"decision":    string(status),
+			"status":      string(row.Status),
+			"currentStep": pgInt4OrNil(row.CurrentStep),
+		}); queryErr != nil {
+			return queryErr
+		}
+		entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, []dbgen.ApprovalRequest{row})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "approval request not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errApprovalInvalidated):
+			writeError(w, http.StatusConflict, "approval_invalidated", err.Error())
+		case errors.Is(err, errApprovalNotPending):
+			writeError(w, http.StatusConflict, "approval_not_pending", err.Error())
+		case errors.Is(err, errNotApprover), errors.Is(err, errSelfApproval):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errApprovalNoApprover):
+			writeError(w, http.StatusConflict, "approval_no_approver", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "approval_decision_failed", "failed to decide approval")
+		}
+		return
+	}
+
+	data := approvalDTO(row)
+	data["entity"] = approvalEntityDTO(row, entities)
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// WithdrawApproval lets the requester take back a pending request. Open chain steps
+// are cancelled; the subject is left as it is.
+func (h FeaturePackHandler) WithdrawApproval(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	approvalID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_approval_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+			return
+		}
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var row dbgen.ApprovalRequest
+	var entities map[approvalEntityKey]json.RawMessage
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetApprovalRequestForUpdate(r.Context(), dbgen.GetApprovalRequestForUpdateParams{
+			TenantID:   toPGUUID(tenantID),
+			ApprovalID: toPGUUID(approvalID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if current.RequestedBy != toPGUUID(actorID) {
+			return errNotRequester
+		}
+		if current.InvalidatedAt.Valid || current.Status != dbgen.ApprovalStatusEnumPending {
+			return errApprovalNotPending
+		}
+		row, queryErr = q.WithdrawApprovalRequest(r.Context(), dbgen.WithdrawApprovalRequestParams{
+			Reason:     toPGText(strings.TrimSpace(req.Reason)),
+			TenantID:   toPGUUID(tenantID),
+			ApprovalID: toPGUUID(approvalID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := q.CancelOpenApprovalRequestSteps(r.Context(), dbgen.CancelOpenApprovalRequestStepsParams{
+			TenantID:           toPGUUID(tenantID),
+			ApprovalRequestIds: []pgtype.UUID{row.ID},
+		}); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := recordApprovalEvent(r.Context(), q, tenantID, row.ID, current.CurrentStep, "withdrawn", toPGUUID(actorID), strings.TrimSpace(req.Reason), map[string]any{}); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "approval_request", approvalID, map[string]any{
+			"event":  "withdrawn",
+			"reason": strings.TrimSpace(req.Reason),
+		}); queryErr != nil {
+			return queryErr
+		}
+		entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, []dbgen.ApprovalRequest{row})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "approval request not found")
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errNotRequester):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errApprovalNotPending):
+			writeError(w, http.StatusConflict, "approval_not_pending", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "approval_withdraw_failed", "failed to withdraw approval request")
+		}
+		return
+	}
+
+	data := approvalDTO(row)
+	data["entity"] = approvalEntityDTO(row, entities)
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// lockApprovalRequest locks the request's subject and then the request itself.
+func lockApprovalRequest(ctx context.Context, q *dbgen.Queries, tenantID, approvalID uuid.UUID) (dbgen.ApprovalRequest, error) {
+	request, err := q.GetApprovalRequest(ctx, dbgen.GetApprovalRequestParams{
+		TenantID:   toPGUUID(tenantID),
+		ApprovalID: toPGUUID(approvalID),
+	})
+	if err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	if err := lockApprovalSubject(ctx, q, tenantID, request); err != nil {
+		return dbgen.ApprovalRequest{}, err
+	}
+	return q.GetApprovalRequestForUpdate(ctx, dbgen.GetApprovalRequestForUpdateParams{
+		TenantID:   toPGUUID(tenantID),
+		ApprovalID: toPGUUID(approvalID),
+	})
+}
+
+// GetApprovalRequest returns a request with its steps and the full decision trail.
+func (h FeaturePackHandler) GetApprovalRequest(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	approvalID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_approval_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.ApprovalRequest
+	var steps []dbgen.ApprovalRequestStep
+	var approvers []dbgen.ListApprovalStepApproversRow
+	var events []dbgen.ListApprovalRequestEventsRow
+	var entities map[approvalEntityKey]json.RawMessage
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
-		row, queryErr = q.DecideApprovalRequest(r.Context(), dbgen.DecideApprovalRequestParams{
-			Status:       status,
-			DecisionNote: toPGText(req.DecisionNote),
-			TenantID:     toPGUUID(tenantID),
-			ApprovalID:   toPGUUID(approvalID),
+		row, queryErr = q.GetApprovalRequest(r.Context(), dbgen.GetApprovalRequestParams{
+			TenantID:   toPGUUID(tenantID),
+			ApprovalID: toPGUUID(approvalID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		steps, queryErr = q.ListApprovalRequestSteps(r.Context(), dbgen.ListApprovalRequestStepsParams{
+			TenantID:           toPGUUID(tenantID),
+			ApprovalRequestIds: []pgtype.UUID{row.ID},
 		})
+		if queryErr != nil {
+			return queryErr
+		}
+		stepIDs := make([]pgtype.UUID, 0, len(steps))
+		for _, step := range steps {
+			stepIDs = append(stepIDs, step.ID)
+		}
+		approvers, queryErr = q.ListApprovalStepApprovers(r.Context(), dbgen.ListApprovalStepApproversParams{
+			TenantID: toPGUUID(tenantID),
+			StepIds:  stepIDs,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		events, queryErr = q.ListApprovalRequestEvents(r.Context(), dbgen.ListApprovalRequestEventsParams{
+			TenantID:          toPGUUID(tenantID),
+			ApprovalRequestID: row.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		entities, queryErr = loadApprovalEntities(r.Context(), q, tenantID, []dbgen.ApprovalRequest{row})
 		return queryErr
 	}); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "not_found", "approval request not found")
 			return
 		}
-		writeError(w, http.StatusInternalServerError, "approval_decision_failed", "failed to decide approval")
+		writeError(w, http.StatusInternalServerError, "approval_get_failed", "failed to load approval request")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": approvalDTO(row)})
+	data := approvalDTO(row)
+	data["entity"] = approvalEntityDTO(row, entities)
+	stepData := make([]map[string]any, 0, len(steps))
+	for _, step := range steps {
+		stepItem := approvalStepDTO(step, approvers)
+		stepData = append(stepData, stepItem)
+		if row.CurrentStep.Valid && step.StepOrder == row.CurrentStep.Int32 {
+			data["currentStep"] = stepItem
+		}
+	}
+	trail := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		trail = append(trail, approvalEventDTO(event))
+	}
+	data["steps"] = stepData
+	data["trail"] = trail
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
 }
 
 func (h FeaturePackHandler) ExportAccountsCSV(w http.ResponseWriter, r *http.Request) {
@@ -588,8 +1200,12 @@ func parseApprovalStatus(raw string) (dbgen.ApprovalStatusEnum, error) {
 		return dbgen.ApprovalStatusEnumApproved, nil
 	case "rejected":
 		return dbgen.ApprovalStatusEnumRejected, nil
+	case "invalidated":
+		return dbgen.ApprovalStatusEnumInvalidated, nil
+	case "withdrawn":
+		return dbgen.ApprovalStatusEnumWithdrawn, nil
 	default:
-		return "", errors.New("status must be pending, approved, or rejected")
+		return "", errors.New("status must be pending, approved, rejected, invalidated, or withdrawn")
 	}
 }
 
@@ -609,16 +1225,23 @@ func integrationConnectionDTO(row dbgen.IntegrationConnection) map[string]any {
 
 func approvalDTO(row dbgen.ApprovalRequest) map[string]any {
 	return map[string]any{
-		"id":             pgUUIDToString(row.ID),
-		"entityType":     row.EntityType,
-		"entityId":       pgUUIDToString(row.EntityID),
-		"requestedBy":    pgUUIDToString(row.RequestedBy),
-		"approverUserId": pgUUIDToString(row.ApproverUserID),
-		"status":         string(row.Status),
-		"reason":         row.Reason,
-		"decisionNote":   pgTextToString(row.DecisionNote),
-		"decidedAt":      pgTimestampToString(row.DecidedAt),
-		"createdAt":      pgTimestampToString(row.CreatedAt),
+		"id":               pgUUIDToString(row.ID),
+		"entityType":       row.EntityType,
+		"entityId":         pgUUIDToString(row.EntityID),
+		"requestedBy":      pgUUIDToString(row.RequestedBy),
+		"approverUserId":   pgUUIDToString(row.ApproverUserID),
+		"status":           string(row.Status),
+		"reason":           row.Reason,
+		"decisionNote":     pgTextToString(row.DecisionNote),
+		"decidedAt":        pgTimestampToString(row.DecidedAt),
+		"policyId":         pgUUIDToString(row.PolicyID),
+		"metricValue":      pgNumericOrNil(row.MetricValue),
+		"invalidatedAt":    pgTimestampToString(row.InvalidatedAt),
+		"withdrawnAt":      pgTimestampToString(row.WithdrawnAt),
+		"chainId":          pgUUIDToString(row.ChainID),
+		"currentStepOrder": pgInt4OrNil(row.CurrentStep),
+		"createdAt":        pgTimestampToString(row.CreatedAt),
+		"version":          row.Version,
 	}
 }
 
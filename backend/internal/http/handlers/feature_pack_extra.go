@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 
 	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/integrations"
 )
 
 func (h FeaturePackHandler) CreateIntegrationEvent(w http.ResponseWriter, r *http.Request) {
@@ -90,6 +91,7 @@ func (h FeaturePackHandler) CreateIntegrationEvent(w http.ResponseWriter, r *htt
 	}
 
 	var row dbgen.IntegrationEvent
+	var activity *dbgen.Activity
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
 		var queryErr error
 		row, queryErr = q.CreateIntegrationEvent(r.Context(), dbgen.CreateIntegrationEventParams{
@@ -104,12 +106,28 @@ func (h FeaturePackHandler) CreateIntegrationEvent(w http.ResponseWriter, r *htt
 			LinkedOpportunityID: linkedOpportunityID,
 			OccurredAt:          occurredAt,
 		})
+		if queryErr != nil {
+			return queryErr
+		}
+		activity, queryErr = integrations.ConvertEvent(r.Context(), q, tenantID, row.ID)
 		return queryErr
 	}); err != nil {
-		writeError(w, http.StatusInternalServerError, "integration_event_create_failed", "failed to create integration event")
+		switch {
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_integration_event", "integration event already imported")
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "linked record does not exist")
+		default:
+			writeError(w, http.StatusInternalServerError, "integration_event_create_failed", "failed to create integration event")
+		}
 		return
 	}
 
+	var activityID any
+	if activity != nil {
+		activityID = pgUUIDToString(activity.ID)
+	}
+
 	payloadOut := map[string]any{}
 	_ = json.Unmarshal(row.Payload, &payloadOut)
 	writeJSON(w, http.StatusCreated, map[string]any{
@@ -124,6 +142,7 @@ func (h FeaturePackHandler) CreateIntegrationEvent(w http.ResponseWriter, r *htt
 			"linkedContactId":     pgUUIDToString(row.LinkedContactID),
 			"linkedOpportunityId": pgUUIDToString(row.LinkedOpportunityID),
 			"occurredAt":          pgTimestampToString(row.OccurredAt),
+			"activityId":          activityID,
 		},
 	})
 }
@@ -231,7 +250,7 @@ func (h FeaturePackHandler) exportOpportunitiesCSV(w http.ResponseWriter, r *htt
 	w.Header().Set("Content-Disposition", "attachment; filename=opportunities.csv")
 	writer := csv.NewWriter(w)
 	_ = writer.Write([]string{
-		"id", "account_id", "contact_id", "owner_user_id", "name", "stage", "probability", "amount", "expected_close_date", "next_action_at", "next_action_note", "created_at", "updated_at",
+		"id", "account_id", "contact_id", "owner_user_id", "name", "stage", "probability", "amount", "currency", "expected_close_date", "next_action_at", "next_action_note", "created_at", "updated_at",
 	})
 	for _, row := range rows {
 		_ = writer.Write([]string{
@@ -243,6 +262,7 @@ func (h FeaturePackHandler) exportOpportunitiesCSV(w http.ResponseWriter, r *htt
 			string(row.Stage),
 			strconv.Itoa(int(row.Probability)),
 			strconv.FormatFloat(row.Amount, 'f', 2, 64),
+			row.Currency,
 			pgDateToString(row.ExpectedCloseDate),
 			pgTimestampToString(row.NextActionAt),
 			pgTextToString(row.NextActionNote),
@@ -269,6 +289,9 @@ func (h FeaturePackHandler) importAccountsCSV(w http.ResponseWriter, r *http.Req
 	inserted := 0
 	rowErrors := []string{}
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumCsvImport); queryErr != nil {
+			return queryErr
+		}
 		for i, rec := range records[1:] {
 			rowNo := i + 2
 			ownerRaw := csvCell(rec, headers, "owner_user_id")
@@ -329,6 +352,9 @@ func (h FeaturePackHandler) importOpportunitiesCSV(w http.ResponseWriter, r *htt
 	inserted := 0
 	rowErrors := []string{}
 	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumCsvImport); queryErr != nil {
+			return queryErr
+		}
 		for i, rec := range records[1:] {
 			rowNo := i + 2
 			accountRaw := csvCell(rec, headers, "account_id")
@@ -351,6 +377,16 @@ func (h FeaturePackHandler) importOpportunitiesCSV(w http.ResponseWriter, r *htt
 				continue
 			}
 
+			var currency pgtype.Text
+			if rawCurrency := csvCell(rec, headers, "currency"); rawCurrency != "" {
+				code, parseErr := parseCurrency(rawCurrency)
+				if parseErr != nil {
+					rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": invalid currency")
+					continue
+				}
+				currency = toPGText(code)
+			}
+
 			expectedClose := pgtype.Date{}
 			if rawDate := csvCell(rec, headers, "expected_close_date"); rawDate != "" {
 				if t, parseErr := time.Parse("2006-01-02", rawDate); parseErr == nil {
@@ -368,8 +404,9 @@ func (h FeaturePackHandler) importOpportunitiesCSV(w http.ResponseWriter, r *htt
 				OwnerUserID:       toPGUUID(ownerID),
 				Name:              name,
 				Stage:             stage,
-				Probability:       parseInt16(csvCell(rec, headers, "probability"), 0),
+				Probability:       parseInt16(csvCell(rec, headers, "probability"), defaultStageProbability[stage.OpportunityStageEnum]),
 				Amount:            parseFloat(csvCell(rec, headers, "amount"), 0),
+				Currency:          currency,
 				ExpectedCloseDate: expectedClose,
 				Memo:              toPGText(csvCell(rec, headers, "memo")),
 				CreatedBy:         toPGUUID(ownerID),
@@ -378,6 +415,9 @@ func (h FeaturePackHandler) importOpportunitiesCSV(w http.ResponseWriter, r *htt
 				rowErrors = append(rowErrors, "row "+strconv.Itoa(rowNo)+": "+queryErr.Error())
 				continue
 			}
+			if queryErr := recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{}, created, created.CreatedBy); queryErr != nil {
+				return queryErr
+			}
 
 			if nextActionRaw := csvCell(rec, headers, "next_action_at"); nextActionRaw != "" {
 				nextActionAt, parseErr := time.Parse(time.RFC3339, nextActionRaw)
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+// setChangeContext tags the transaction so the field_changes trigger can record who
+// made the change and through which path. X-User-ID is optional here; handlers that
+// require it validate it themselves.
+func setChangeContext(ctx context.Context, q *dbgen.Queries, r *http.Request, source dbgen.ChangeSourceEnum) error {
+	userID := ""
+	if actorID, err := userIDFromHeader(r); err == nil {
+		userID = actorID.String()
+	}
+	return q.SetChangeContext(ctx, dbgen.SetChangeContextParams{
+		UserID: userID,
+		Source: string(source),
+	})
+}
+
+func (h OpportunityHandler) History(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	writeFieldHistory(w, r, h.Store, tenantID, "opportunity", opportunityID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity)
+	})
+}
+
+func (h AccountHandler) History(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	accountID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "id must be UUID")
+		return
+	}
+
+	writeFieldHistory(w, r, h.Store, tenantID, "account", accountID, func(q *dbgen.Queries) error {
+		_, queryErr := q.GetAccount(r.Context(), dbgen.GetAccountParams{
+			TenantID:  toPGUUID(tenantID),
+			AccountID: toPGUUID(accountID),
+		})
+		return queryErr
+	})
+}
+
+func (h AccountHandler) ContactHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	contactID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_contact_id", "id must be UUID")
+		return
+	}
+
+	writeFieldHistory(w, r, h.Store, tenantID, "contact", contactID, func(q *dbgen.Queries) error {
+		_, queryErr := q.GetContact(r.Context(), dbgen.GetContactParams{
+			TenantID:  toPGUUID(tenantID),
+			ContactID: toPGUUID(contactID),
+		})
+		return queryErr
+	})
+}
+
+// writeFieldHistory pages through field_changes newest first. check runs in the same
+// transaction and decides whether the entity exists and is visible to the caller.
+func writeFieldHistory(w http.ResponseWriter, r *http.Request, s *store.Store, tenantID uuid.UUID, entityType string, entityID uuid.UUID, check func(*dbgen.Queries) error) {
+	limit := queryCursorLimit(r, 50)
+	params := dbgen.ListFieldChangesParams{
+		TenantID:   toPGUUID(tenantID),
+		EntityType: entityType,
+		EntityID:   toPGUUID(entityID),
+		FieldNames: parseHistoryFields(r.URL.Query()["field"]),
+		LimitCount: limit + 1,
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, cursorErr := decodeCursor(raw)
+		if cursorErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", cursorErr.Error())
+			return
+		}
+		cursorID, parseErr := strconv.ParseInt(cursor.ID, 10, 64)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", "invalid cursor")
+			return
+		}
+		params.CursorAt = toPGTimestamptz(cursor.At)
+		params.CursorID = pgtype.Int8{Int64: cursorID, Valid: true}
+	}
+
+	var rows []dbgen.ListFieldChangesRow
+	if err := s.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := check(q); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListFieldChanges(r.Context(), params)
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", entityType+" not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "history_failed", "failed to load "+entityType+" history")
+		}
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(timeCursor{
+			At: last.ChangedAt.Time,
+			ID: strconv.FormatInt(last.ID, 10),
+		})
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"id":            row.ID,
+			"field":         snakeToCamel(row.FieldName),
+			"oldValue":      json.RawMessage(jsonOrNull(row.OldValue)),
+			"newValue":      json.RawMessage(jsonOrNull(row.NewValue)),
+			"changedBy":     pgUUIDToString(row.ChangedBy),
+			"changedByName": pgTextToString(row.ChangedByName),
+			"source":        string(row.Source),
+			"changedAt":     pgTimestampToString(row.ChangedAt),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"limit":      limit,
+			"nextCursor": nextCursor,
+		},
+	})
+}
+
+// parseHistoryFields accepts API field names (expectedCloseDate) or column names
+// (expected_close_date), repeated or comma separated.
+func parseHistoryFields(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if field := strings.TrimSpace(part); field != "" {
+				out = append(out, camelToSnake(field))
+			}
+		}
+	}
+	return out
+}
+
+func snakeToCamel(raw string) string {
+	parts := strings.Split(raw, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func camelToSnake(raw string) string {
+	var b strings.Builder
+	for i, r := range raw {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func jsonOrNull(raw []byte) []byte {
+	if len(raw) == 0 {
+		return []byte("null")
+	}
+	return raw
+}
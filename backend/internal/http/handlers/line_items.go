@@ -0,0 +1,518 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errAmountDerived = errors.New("amount is derived from line items; edit the line items instead")
+var errCurrencyLocked = errors.New("currency cannot change while line items exist; clear the line items first")
+
+// lineItemParent identifies the opportunity, quote or order that owns a line item set.
+// Exactly one of the ids is valid.
+type lineItemParent struct {
+	EntityType    string
+	OpportunityID pgtype.UUID
+	QuoteID       pgtype.UUID
+	OrderID       pgtype.UUID
+}
+
+func newLineItemParent(entityType string, id uuid.UUID) lineItemParent {
+	parent := lineItemParent{EntityType: entityType}
+	switch entityType {
+	case "opportunity":
+		parent.OpportunityID = toPGUUID(id)
+	case "quote":
+		parent.QuoteID = toPGUUID(id)
+	case "order":
+		parent.OrderID = toPGUUID(id)
+	}
+	return parent
+}
+
+type lineItemError struct {
+	Index   int
+	Message string
+}
+
+func (e lineItemError) Error() string {
+	return fmt.Sprintf("items[%d]: %s", e.Index, e.Message)
+}
+
+func (h CatalogHandler) OpportunityLineItems(w http.ResponseWriter, r *http.Request) {
+	h.listLineItems(w, r, "opportunity")
+}
+
+func (h CatalogHandler) ReplaceOpportunityLineItems(w http.ResponseWriter, r *http.Request) {
+	h.replaceLineItems(w, r, "opportunity")
+}
+
+func (h CatalogHandler) QuoteLineItems(w http.ResponseWriter, r *http.Request) {
+	h.listLineItems(w, r, "quote")
+}
+
+func (h CatalogHandler) ReplaceQuoteLineItems(w http.ResponseWriter, r *http.Request) {
+	h.replaceLineItems(w, r, "quote")
+}
+
+func (h CatalogHandler) OrderLineItems(w http.ResponseWriter, r *http.Request) {
+	h.listLineItems(w, r, "order")
+}
+
+func (h CatalogHandler) ReplaceOrderLineItems(w http.ResponseWriter, r *http.Request) {
+	h.replaceLineItems(w, r, "order")
+}
+
+func (h CatalogHandler) listLineItems(w http.ResponseWriter, r *http.Request, entityType string) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	parentID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_"+entityType+"_id", "id must be UUID")
+		return
+	}
+	parent := newLineItemParent(entityType, parentID)
+
+	var amount pgtype.Numeric
+	var rows []dbgen.ListLineItemsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		header, queryErr := lineItemParentHeader(r, q, tenantID, parent, false)
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(header.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		amount = header.Amount
+		rows, queryErr = q.ListLineItems(r.Context(), dbgen.ListLineItemsParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: parent.OpportunityID,
+			QuoteID:       parent.QuoteID,
+			OrderID:       parent.OrderID,
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", entityType+" not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "line_item_list_failed", "failed to load line items")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": lineItemListDTO(rows),
+		"meta": map[string]any{"amount": pgNumericToFloat(amount)},
+	})
+}
+
+// replaceLineItems swaps the whole line item set of a parent and re-derives its header
+// amount in the same transaction. unitPrice falls back to the price book entry. The
+// caller needs access to the opportunity the parent belongs to.
+func (h CatalogHandler) replaceLineItems(w http.ResponseWriter, r *http.Request, entityType string) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	parentID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_"+entityType+"_id", "id must be UUID")
+		return
+	}
+	parent := newLineItemParent(entityType, parentID)
+
+	var req struct {
+		PriceBookID string `json:"priceBookId"`
+		Items       []struct {
+			ProductID       string   `json:"productId"`
+			Description     string   `json:"description"`
+			Quantity        float64  `json:"quantity"`
+			UnitPrice       *float64 `json:"unitPrice"`
+			DiscountPercent float64  `json:"discountPercent"`
+			TaxRate         *float64 `json:"taxRate"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	var priceBookID pgtype.UUID
+	if strings.TrimSpace(req.PriceBookID) != "" {
+		id, parseErr := parseUUID(req.PriceBookID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_price_book_id", "priceBookId must be UUID")
+			return
+		}
+		priceBookID = toPGUUID(id)
+	}
+	productIDs := make([]uuid.UUID, len(req.Items))
+	for i, item := range req.Items {
+		id, parseErr := parseUUID(item.ProductID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_line_item", lineItemError{Index: i, Message: "productId must be UUID"}.Error())
+			return
+		}
+		productIDs[i] = id
+		if item.Quantity <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_line_item", lineItemError{Index: i, Message: "quantity must be greater than zero"}.Error())
+			return
+		}
+		if item.UnitPrice != nil && *item.UnitPrice < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_line_item", lineItemError{Index: i, Message: "unitPrice must be zero or greater"}.Error())
+			return
+		}
+		if item.DiscountPercent < 0 || item.DiscountPercent > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_line_item", lineItemError{Index: i, Message: "discountPercent must be between 0 and 100"}.Error())
+			return
+		}
+		if item.TaxRate != nil && !validTaxRate(*item.TaxRate) {
+			writeError(w, http.StatusBadRequest, "invalid_line_item", lineItemError{Index: i, Message: errInvalidTaxRate.Error()}.Error())
+			return
+		}
+	}
+
+	var amount pgtype.Numeric
+	var rows []dbgen.ListLineItemsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		header, queryErr := lineItemParentHeader(r, q, tenantID, parent, true)
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(header.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		currency := header.Currency
+		if parent.QuoteID.Valid {
+			quote, queryErr := q.GetQuoteByIDForUpdate(r.Context(), dbgen.GetQuoteByIDForUpdateParams{
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  parent.QuoteID,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if quote.Status != dbgen.QuoteStatusEnumDraft {
+				return errQuoteNotEditable
+			}
+		}
+		if parent.OrderID.Valid {
+			order, queryErr := q.GetOrderForUpdate(r.Context(), dbgen.GetOrderForUpdateParams{
+				TenantID: toPGUUID(tenantID),
+				OrderID:  parent.OrderID,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if order.Status != dbgen.OrderStatusEnumPending {
+				return errOrderNotEditable
+			}
+		}
+
+		var book dbgen.PriceBook
+		if priceBookID.Valid {
+			book, queryErr = q.GetPriceBook(r.Context(), dbgen.GetPriceBookParams{
+				TenantID:    toPGUUID(tenantID),
+				PriceBookID: priceBookID,
+			})
+			if errors.Is(queryErr, pgx.ErrNoRows) {
+				return errPriceBookNotFound
+			}
+			if queryErr != nil {
+				return queryErr
+			}
+		} else {
+			book, queryErr = q.GetDefaultPriceBook(r.Context(), toPGUUID(tenantID))
+			if queryErr != nil && !errors.Is(queryErr, pgx.ErrNoRows) {
+				return queryErr
+			}
+			priceBookID = book.ID
+		}
+
+		if queryErr := q.DeleteLineItems(r.Context(), dbgen.DeleteLineItemsParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: parent.OpportunityID,
+			QuoteID:       parent.QuoteID,
+			OrderID:       parent.OrderID,
+		}); queryErr != nil {
+			return queryErr
+		}
+
+		for i, item := range req.Items {
+			product, queryErr := q.GetProduct(r.Context(), dbgen.GetProductParams{
+				TenantID:  toPGUUID(tenantID),
+				ProductID: toPGUUID(productIDs[i]),
+			})
+			if errors.Is(queryErr, pgx.ErrNoRows) {
+				return lineItemError{Index: i, Message: "product not found"}
+			}
+			if queryErr != nil {
+				return queryErr
+			}
+			if !product.IsActive {
+				return lineItemError{Index: i, Message: "product " + product.Sku + " is inactive"}
+			}
+
+			var unitPrice pgtype.Numeric
+			if item.UnitPrice != nil {
+				unitPrice = toPGNumeric(*item.UnitPrice)
+			} else {
+				if !priceBookID.Valid {
+					return lineItemError{Index: i, Message: "unitPrice is required when no price book is available"}
+				}
+				if book.Currency != currency {
+					return lineItemError{Index: i, Message: "price book is in " + book.Currency + " but the " + entityType + " is in " + currency + "; pass unitPrice"}
+				}
+				unitPrice, queryErr = q.GetPriceBookEntryPrice(r.Context(), dbgen.GetPriceBookEntryPriceParams{
+					TenantID:    toPGUUID(tenantID),
+					PriceBookID: priceBookID,
+					ProductID:   product.ID,
+				})
+				if errors.Is(queryErr, pgx.ErrNoRows) {
+					return lineItemError{Index: i, Message: "product " + product.Sku + " has no price in the price book; pass unitPrice"}
+				}
+				if queryErr != nil {
+					return queryErr
+				}
+			}
+
+			taxRate := product.TaxRate
+			if item.TaxRate != nil {
+				taxRate = toPGNumeric(*item.TaxRate)
+			}
+
+			if _, queryErr := q.CreateLineItem(r.Context(), dbgen.CreateLineItemParams{
+				TenantID:        toPGUUID(tenantID),
+				OpportunityID:   parent.OpportunityID,
+				QuoteID:         parent.QuoteID,
+				OrderID:         parent.OrderID,
+				ProductID:       product.ID,
+				PriceBookID:     priceBookID,
+				Description:     toPGText(strings.TrimSpace(item.Description)),
+				Quantity:        toPGNumeric(item.Quantity),
+				UnitPrice:       unitPrice,
+				DiscountPercent: toPGNumeric(item.DiscountPercent),
+				TaxRate:         taxRate,
+				SortOrder:       int32(i),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+
+		amount, queryErr = recalculateParentAmount(r, q, tenantID, parent)
+		if queryErr != nil {
+			return queryErr
+		}
+		if parent.QuoteID.Valid {
+			if queryErr := invalidateQuoteApprovals(r.Context(), q, tenantID, parent.QuoteID); queryErr != nil {
+				return queryErr
+			}
+		}
+		if parent.OrderID.Valid {
+			if queryErr := rebuildOrderSchedule(r.Context(), q, tenantID, parent.OrderID); queryErr != nil {
+				return queryErr
+			}
+		}
+		rows, queryErr = q.ListLineItems(r.Context(), dbgen.ListLineItemsParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: parent.OpportunityID,
+			QuoteID:       parent.QuoteID,
+			OrderID:       parent.OrderID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, entityType, parentID, map[string]any{
+			"event":     "line_items_replaced",
+			"itemCount": len(rows),
+			"amount":    pgNumericToFloat(amount),
+		})
+	}); err != nil {
+		var itemErr lineItemError
+		switch {
+		case errors.As(err, &itemErr):
+			writeError(w, http.StatusBadRequest, "invalid_line_item", itemErr.Error())
+		case errors.Is(err, errPriceBookNotFound):
+			writeError(w, http.StatusBadRequest, "invalid_price_book_id", err.Error())
+		case errors.Is(err, errQuoteNotEditable):
+			writeError(w, http.StatusConflict, "quote_not_editable", err.Error())
+		case errors.Is(err, errOrderNotEditable):
+			writeError(w, http.StatusConflict, "order_not_editable", err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", entityType+" not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "line_item_replace_failed", "failed to save line items")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": lineItemListDTO(rows),
+		"meta": map[string]any{"amount": pgNumericToFloat(amount)},
+	})
+}
+
+var errPriceBookNotFound = errors.New("price book not found")
+
+// lineItemHeader is what line item handlers need from the parent header.
+type lineItemHeader struct {
+	Amount        pgtype.Numeric
+	Currency      string
+	OpportunityID pgtype.UUID
+}
+
+// lineItemParentHeader loads the parent header, locking opportunities when forUpdate is
+// set, and returns its current amount, currency and the opportunity it belongs to. A
+// missing parent yields pgx.ErrNoRows.
+func lineItemParentHeader(r *http.Request, q *dbgen.Queries, tenantID uuid.UUID, parent lineItemParent, forUpdate bool) (lineItemHeader, error) {
+	switch {
+	case parent.OpportunityID.Valid:
+		params := dbgen.GetOpportunityForUpdateParams{TenantID: toPGUUID(tenantID), OpportunityID: parent.OpportunityID}
+		if forUpdate {
+			row, err := q.GetOpportunityForUpdate(r.Context(), params)
+			return lineItemHeader{Amount: row.Amount, Currency: row.Currency, OpportunityID: row.ID}, err
+		}
+		row, err := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams(params))
+		return lineItemHeader{Amount: row.Amount, Currency: row.Currency, OpportunityID: row.ID}, err
+	case parent.QuoteID.Valid:
+		row, err := q.GetQuote(r.Context(), dbgen.GetQuoteParams{TenantID: toPGUUID(tenantID), QuoteID: parent.QuoteID})
+		return lineItemHeader{Amount: row.Amount, Currency: row.Currency, OpportunityID: row.OpportunityID}, err
+	default:
+		row, err := q.GetOrder(r.Context(), dbgen.GetOrderParams{TenantID: toPGUUID(tenantID), OrderID: parent.OrderID})
+		return lineItemHeader{Amount: row.Amount, Currency: row.Currency, OpportunityID: row.OpportunityID}, err
+	}
+}
+
+func recalculateParentAmount(r *http.Request, q *dbgen.Queries, tenantID uuid.UUID, parent lineItemParent) (pgtype.Numeric, error) {
+	switch {
+	case parent.OpportunityID.Valid:
+		row, err := q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{TenantID: toPGUUID(tenantID), OpportunityID: parent.OpportunityID})
+		return row.Amount, err
+	case parent.QuoteID.Valid:
+		row, err := q.RecalculateQuoteTotals(r.Context(), dbgen.RecalculateQuoteTotalsParams{TenantID: toPGUUID(tenantID), QuoteID: parent.QuoteID})
+		if err != nil {
+			return row.Amount, err
+		}
+		// The deal amount follows its latest live quote.
+		_, err = q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{TenantID: toPGUUID(tenantID), OpportunityID: row.OpportunityID})
+		return row.Amount, err
+	default:
+		row, err := q.RecalculateOrderAmount(r.Context(), dbgen.RecalculateOrderAmountParams{TenantID: toPGUUID(tenantID), OrderID: parent.OrderID})
+		return row.Amount, err
+	}
+}
+
+func (h FeaturePackHandler) ProductRevenue(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	rangeStart, rangeEnd, err := parseDateRange(r, 90)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", err.Error())
+		return
+	}
+	var ownerID pgtype.UUID
+	if raw := r.URL.Query().Get("ownerUserId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		ownerID = toPGUUID(id)
+	}
+
+	var rows []dbgen.GetProductRevenueSummaryRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.GetProductRevenueSummary(r.Context(), dbgen.GetProductRevenueSummaryParams{
+			TenantID:    toPGUUID(tenantID),
+			RangeStart:  rangeStart,
+			RangeEnd:    rangeEnd,
+			OwnerUserID: ownerID,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "product_revenue_failed", "failed to load product revenue")
+		return
+	}
+
+	var missingRates int64
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		missingRates += row.MissingRateCount
+		data = append(data, map[string]any{
+			"productId":        pgUUIDToString(row.ProductID),
+			"sku":              row.Sku,
+			"productName":      row.ProductName,
+			"openDealCount":    row.OpenDealCount,
+			"pipelineAmount":   row.PipelineAmount,
+			"weightedAmount":   row.WeightedAmount,
+			"wonDealCount":     row.WonDealCount,
+			"wonQuantity":      row.WonQuantity,
+			"wonAmount":        row.WonAmount,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"from":             pgTimestampToString(rangeStart),
+			"to":               pgTimestampToString(rangeEnd),
+			"missingRateCount": missingRates,
+		},
+	})
+}
+
+func lineItemListDTO(rows []dbgen.ListLineItemsRow) []map[string]any {
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"id":              pgUUIDToString(row.ID),
+			"productId":       pgUUIDToString(row.ProductID),
+			"sku":             row.Sku,
+			"productName":     row.ProductName,
+			"priceBookId":     pgUUIDToString(row.PriceBookID),
+			"description":     pgTextToString(row.Description),
+			"quantity":        pgNumericToFloat(row.Quantity),
+			"unitPrice":       pgNumericToFloat(row.UnitPrice),
+			"discountPercent": pgNumericToFloat(row.DiscountPercent),
+			"taxRate":         pgNumericToFloat(row.TaxRate),
+			"lineTotal":       pgNumericToFloat(row.LineTotal),
+			"sortOrder":       row.SortOrder,
+		})
+	}
+	return data
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+type NotificationHandler struct {
+	Store *store.Store
+}
+
+func NewNotificationHandler(store *store.Store) NotificationHandler {
+	return NotificationHandler{Store: store}
+}
+
+// List pages through the caller's notifications newest first; ?unread=true hides read ones.
+func (h NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	limit := queryCursorLimit(r, 50)
+	params := dbgen.ListNotificationsByUserParams{
+		TenantID:   toPGUUID(tenantID),
+		UserID:     toPGUUID(actorID),
+		UnreadOnly: r.URL.Query().Get("unread") == "true",
+		LimitCount: limit + 1,
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, cursorErr := decodeCursor(raw)
+		if cursorErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", cursorErr.Error())
+			return
+		}
+		cursorID, parseErr := parseUUID(cursor.ID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_cursor", "invalid cursor")
+			return
+		}
+		params.CursorAt = toPGTimestamptz(cursor.At)
+		params.CursorID = toPGUUID(cursorID)
+	}
+
+	var rows []dbgen.Notification
+	var unread int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListNotificationsByUser(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		unread, queryErr = q.CountUnreadNotifications(r.Context(), dbgen.CountUnreadNotificationsParams{
+			TenantID: toPGUUID(tenantID),
+			UserID:   toPGUUID(actorID),
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "notification_list_failed", "failed to load notifications")
+		return
+	}
+
+	nextCursor := ""
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(timeCursor{
+			At: last.CreatedAt.Time,
+			ID: pgUUIDToString(last.ID),
+		})
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, notificationDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"limit":       limit,
+			"nextCursor":  nextCursor,
+			"unreadCount": unread,
+		},
+	})
+}
+
+func (h NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	notificationID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_notification_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Notification
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.MarkNotificationRead(r.Context(), dbgen.MarkNotificationReadParams{
+			TenantID:       toPGUUID(tenantID),
+			NotificationID: toPGUUID(notificationID),
+			UserID:         toPGUUID(actorID),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "notification not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "notification_update_failed", "failed to mark notification read")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": notificationDTO(row)})
+}
+
+func (h NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var updated int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		updated, queryErr = q.MarkAllNotificationsRead(r.Context(), dbgen.MarkAllNotificationsReadParams{
+			TenantID: toPGUUID(tenantID),
+			UserID:   toPGUUID(actorID),
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "notification_update_failed", "failed to mark notifications read")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"updated": updated}})
+}
+
+func notificationDTO(row dbgen.Notification) map[string]any {
+	return map[string]any{
+		"id":         pgUUIDToString(row.ID),
+		"kind":       row.Kind,
+		"entityType": row.EntityType,
+		"entityId":   pgUUIDToString(row.EntityID),
+		"title":      row.Title,
+		"body":       row.Body,
+		"data":       json.RawMessage(jsonOrNull(row.Data)),
+		"read":       row.ReadAt.Valid,
+		"readAt":     pgTimestampToString(row.ReadAt),
+		"createdAt":  pgTimestampToString(row.CreatedAt),
+	}
+}
@@ -0,0 +1,576 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errInvalidOpportunityContact = errors.New("contactId must name a contact of the opportunity's account")
+var errInvalidOpportunityOwner = errors.New("ownerUserId must be an active member of this tenant")
+
+type OpportunityHandler struct {
+	Store *store.Store
+}
+
+func NewOpportunityHandler(store *store.Store) OpportunityHandler {
+	return OpportunityHandler{Store: store}
+}
+
+func (h OpportunityHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 20)
+	stage := dbgen.NullOpportunityStageEnum{}
+	if raw := r.URL.Query().Get("stage"); raw != "" {
+		parsed, parseErr := parseOpportunityStage(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_stage", "stage is not a valid opportunity stage")
+			return
+		}
+		stage = parsed
+	}
+	var ownerID pgtype.UUID
+	if raw := r.URL.Query().Get("ownerUserId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		ownerID = toPGUUID(id)
+	}
+
+	var rows []dbgen.Opportunity
+	var total int64
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		visibleTo, queryErr := opportunityVisibility(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListOpportunities(r.Context(), dbgen.ListOpportunitiesParams{
+			TenantID:    toPGUUID(tenantID),
+			Stage:       stage,
+			OwnerUserID: ownerID,
+			VisibleTo:   visibleTo,
+			OffsetCount: offset,
+			LimitCount:  limit,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		total, queryErr = q.CountOpportunities(r.Context(), dbgen.CountOpportunitiesParams{
+			TenantID:    toPGUUID(tenantID),
+			Stage:       stage,
+			OwnerUserID: ownerID,
+			VisibleTo:   visibleTo,
+		})
+		return queryErr
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "opportunity_list_failed", "failed to load opportunities")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, opportunityDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"page":  offset/limit + 1,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h OpportunityHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Opportunity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return authorizeOpportunity(r.Context(), q, tenantID, actorID, row)
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+			return
+		}
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "opportunity_get_failed", "failed to load opportunity")
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": opportunityDTO(row)})
+}
+
+func (h OpportunityHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req struct {
+		AccountID         string   `json:"accountId"`
+		ContactID         string   `json:"contactId"`
+		OwnerUserID       string   `json:"ownerUserId"`
+		Name              string   `json:"name"`
+		Stage             string   `json:"stage"`
+		Probability       *int16   `json:"probability"`
+		Amount            *float64 `json:"amount"`
+		Currency          string   `json:"currency"`
+		ExpectedCloseDate string   `json:"expectedCloseDate"`
+		Memo              string   `json:"memo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	accountID, err := parseUUID(req.AccountID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_account_id", "accountId must be UUID")
+		return
+	}
+	ownerID, err := parseUUID(req.OwnerUserID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+		return
+	}
+	var contactID pgtype.UUID
+	if strings.TrimSpace(req.ContactID) != "" {
+		id, parseErr := parseUUID(req.ContactID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_contact_id", "contactId must be UUID")
+			return
+		}
+		contactID = toPGUUID(id)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_name", "name is required")
+		return
+	}
+	stage, err := parseOpportunityStage(req.Stage)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_stage", "stage is not a valid opportunity stage")
+		return
+	}
+	if isClosedStage(stage.OpportunityStageEnum) {
+		writeError(w, http.StatusBadRequest, "invalid_stage", "opportunities are created open; close them through close-won or the loss flow")
+		return
+	}
+	probability := defaultStageProbability[stage.OpportunityStageEnum]
+	if req.Probability != nil {
+		if *req.Probability < 0 || *req.Probability > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_probability", "probability must be between 0 and 100")
+			return
+		}
+		probability = *req.Probability
+	}
+	var amount any
+	if req.Amount != nil {
+		if *req.Amount < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_amount", "amount must be zero or greater")
+			return
+		}
+		amount = *req.Amount
+	}
+	var currency pgtype.Text
+	if strings.TrimSpace(req.Currency) != "" {
+		code, parseErr := parseCurrency(req.Currency)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_currency", parseErr.Error())
+			return
+		}
+		currency = toPGText(code)
+	}
+	expectedClose, err := parseOptionalDate(req.ExpectedCloseDate)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_expected_close_date", "expectedCloseDate must be YYYY-MM-DD")
+		return
+	}
+
+	var row dbgen.Opportunity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		role, queryErr := actorRole(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		// Sales users only reach deals they own, so they cannot create one for someone else.
+		if role == dbgen.RoleEnumSales && ownerID != actorID {
+			return errOpportunityForbidden
+		}
+		if queryErr := checkOpportunityOwner(r.Context(), q, tenantID, ownerID); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkOpportunityContact(r.Context(), q, tenantID, toPGUUID(accountID), contactID); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.CreateOpportunity(r.Context(), dbgen.CreateOpportunityParams{
+			TenantID:          toPGUUID(tenantID),
+			AccountID:         toPGUUID(accountID),
+			ContactID:         contactID,
+			OwnerUserID:       toPGUUID(ownerID),
+			Name:              strings.TrimSpace(req.Name),
+			Stage:             stage,
+			Probability:       probability,
+			Amount:            amount,
+			Currency:          currency,
+			ExpectedCloseDate: expectedClose,
+			Memo:              toPGText(req.Memo),
+			CreatedBy:         toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{}, row, toPGUUID(actorID))
+	}); err != nil {
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errInvalidOpportunityOwner):
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", err.Error())
+		case errors.Is(err, errInvalidOpportunityContact):
+			writeError(w, http.StatusBadRequest, "invalid_contact_id", err.Error())
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "account, contact or owner does not exist")
+		default:
+			writeError(w, http.StatusInternalServerError, "opportunity_create_failed", "failed to create opportunity")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": opportunityDTO(row)})
+}
+
+func (h OpportunityHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		ContactID         *string  `json:"contactId"`
+		OwnerUserID       *string  `json:"ownerUserId"`
+		Name              *string  `json:"name"`
+		Stage             *string  `json:"stage"`
+		Probability       *int16   `json:"probability"`
+		Amount            *float64 `json:"amount"`
+		Currency          *string  `json:"currency"`
+		ExpectedCloseDate *string  `json:"expectedCloseDate"`
+		Memo              *string  `json:"memo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateOpportunityParams{
+		TenantID:      toPGUUID(tenantID),
+		OpportunityID: toPGUUID(opportunityID),
+	}
+	if req.ContactID != nil {
+		id, parseErr := parseUUID(*req.ContactID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_contact_id", "contactId must be UUID")
+			return
+		}
+		params.ContactID = toPGUUID(id)
+	}
+	if req.OwnerUserID != nil {
+		id, parseErr := parseUUID(*req.OwnerUserID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		params.OwnerUserID = toPGUUID(id)
+	}
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_name", "name must not be empty")
+			return
+		}
+		params.Name = toPGText(strings.TrimSpace(*req.Name))
+	}
+	var targetStage *dbgen.OpportunityStageEnum
+	if req.Stage != nil {
+		parsed, parseErr := parseOpportunityStage(*req.Stage)
+		if parseErr != nil || strings.TrimSpace(*req.Stage) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_stage", "stage is not a valid opportunity stage")
+			return
+		}
+		targetStage = &parsed.OpportunityStageEnum
+	}
+	if req.Probability != nil {
+		if *req.Probability < 0 || *req.Probability > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_probability", "probability must be between 0 and 100")
+			return
+		}
+		params.Probability = pgtype.Int2{Int16: *req.Probability, Valid: true}
+	}
+	if req.Amount != nil {
+		if *req.Amount < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_amount", "amount must be zero or greater")
+			return
+		}
+		params.Amount = toPGNumeric(*req.Amount)
+	}
+	if req.Currency != nil {
+		code, parseErr := parseCurrency(*req.Currency)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_currency", parseErr.Error())
+			return
+		}
+		params.Currency = toPGText(code)
+	}
+	if req.ExpectedCloseDate != nil {
+		// An empty string clears the date.
+		parsed, parseErr := parseOptionalDate(*req.ExpectedCloseDate)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_expected_close_date", "expectedCloseDate must be YYYY-MM-DD or empty")
+			return
+		}
+		params.SetExpectedCloseDate = true
+		params.ExpectedCloseDate = parsed
+	}
+	if req.Memo != nil {
+		params.Memo = toPGText(*req.Memo)
+	}
+
+	var row dbgen.Opportunity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, current); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if params.OwnerUserID.Valid {
+			if queryErr := checkOpportunityOwner(r.Context(), q, tenantID, uuid.UUID(params.OwnerUserID.Bytes)); queryErr != nil {
+				return queryErr
+			}
+		}
+		if queryErr := checkOpportunityContact(r.Context(), q, tenantID, current.AccountID, params.ContactID); queryErr != nil {
+			return queryErr
+		}
+
+		if params.Currency.Valid && params.Currency.String == current.Currency {
+			params.Currency = pgtype.Text{}
+		}
+		if params.Amount.Valid || params.Currency.Valid {
+			lineCount, queryErr := q.CountOpportunityLineItems(r.Context(), dbgen.CountOpportunityLineItemsParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if lineCount > 0 && params.Currency.Valid {
+				return errCurrencyLocked
+			}
+			if lineCount > 0 {
+				return errAmountDerived
+			}
+			quoteLineCount, queryErr := q.CountLiveQuoteLineItems(r.Context(), dbgen.CountLiveQuoteLineItemsParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if quoteLineCount > 0 && params.Amount.Valid {
+				return errAmountDerived
+			}
+		}
+
+		if targetStage != nil && *targetStage != current.Stage {
+			if transitionErr := checkPatchStageTransition(current.Stage, *targetStage); transitionErr != nil {
+				return transitionErr
+			}
+			params.Stage = dbgen.NullOpportunityStageEnum{OpportunityStageEnum: *targetStage, Valid: true}
+			if !params.Probability.Valid {
+				params.Probability = pgtype.Int2{Int16: defaultStageProbability[*targetStage], Valid: true}
+			}
+		}
+
+		row, queryErr = q.UpdateOpportunity(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if row.Stage == current.Stage {
+			return nil
+		}
+		return recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{OpportunityStageEnum: current.Stage, Valid: true}, row, toPGUUID(actorID))
+	}); err != nil {
+		var transitionErr stageTransitionError
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case errors.As(err, &transitionErr):
+			writeStageTransitionError(w, transitionErr)
+		case errors.Is(err, errInvalidOpportunityOwner):
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", err.Error())
+		case errors.Is(err, errInvalidOpportunityContact):
+			writeError(w, http.StatusBadRequest, "invalid_contact_id", err.Error())
+		case isForeignKeyViolation(err):
+			writeError(w, http.StatusBadRequest, "invalid_reference", "contact or owner does not exist")
+		case errors.Is(err, errAmountDerived):
+			writeError(w, http.StatusConflict, "amount_derived", err.Error())
+		case errors.Is(err, errCurrencyLocked):
+			writeError(w, http.StatusConflict, "currency_locked", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "opportunity_update_failed", "failed to update opportunity")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": opportunityDTO(row)})
+}
+
+// checkOpportunityOwner requires the owner to be an active member of the tenant.
+func checkOpportunityOwner(ctx context.Context, q *dbgen.Queries, tenantID, ownerID uuid.UUID) error {
+	_, err := actorRole(ctx, q, tenantID, ownerID)
+	if errors.Is(err, errNotTenantMember) {
+		return errInvalidOpportunityOwner
+	}
+	return err
+}
+
+// checkOpportunityContact requires contactID, when set, to belong to the deal's account.
+func checkOpportunityContact(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, accountID, contactID pgtype.UUID) error {
+	if !contactID.Valid {
+		return nil
+	}
+	contact, err := q.GetContact(ctx, dbgen.GetContactParams{
+		TenantID:  toPGUUID(tenantID),
+		ContactID: contactID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && contact.AccountID != accountID) {
+		return errInvalidOpportunityContact
+	}
+	return err
+}
+
+func writeStageTransitionError(w http.ResponseWriter, err stageTransitionError) {
+	writeJSON(w, http.StatusConflict, map[string]any{
+		"error": map[string]any{
+			"code":              "invalid_stage_transition",
+			"message":           err.Error(),
+			"currentStage":      string(err.From),
+			"allowedNextStages": err.allowedStrings(),
+		},
+	})
+}
+
+func opportunityDTO(row dbgen.Opportunity) map[string]any {
+	return map[string]any{
+		"id":                pgUUIDToString(row.ID),
+		"accountId":         pgUUIDToString(row.AccountID),
+		"contactId":         pgUUIDToString(row.ContactID),
+		"ownerUserId":       pgUUIDToString(row.OwnerUserID),
+		"name":              row.Name,
+		"stage":             string(row.Stage),
+		"probability":       row.Probability,
+		"amount":            pgNumericToFloat(row.Amount),
+		"currency":          row.Currency,
+		"expectedCloseDate": pgDateToString(row.ExpectedCloseDate),
+		"closedAt":          pgTimestampToString(row.ClosedAt),
+		"memo":              pgTextToString(row.Memo),
+		"nextActionAt":      pgTimestampToString(row.NextActionAt),
+		"nextActionNote":    pgTextToString(row.NextActionNote),
+		"createdAt":         pgTimestampToString(row.CreatedAt),
+		"updatedAt":         pgTimestampToString(row.UpdatedAt),
+		"version":           row.Version,
+	}
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errOpportunityNotOpen = errors.New("opportunity is not open")
+var errOpportunityNotLost = errors.New("opportunity is not closed_lost")
+
+func (h OpportunityHandler) MarkLost(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Reason       string `json:"reason"`
+		Detail       string `json:"detail"`
+		Competitor   string `json:"competitor"`
+		CompetitorID string `json:"competitorId"`
+		LostAt       string `json:"lostAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	reason, err := parseLossReason(req.Reason)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_reason", err.Error())
+		return
+	}
+	lostAt, err := parseOptionalTimestamp(req.LostAt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_lost_at", "lostAt must be RFC3339 or YYYY-MM-DD")
+		return
+	}
+	var competitorID uuid.UUID
+	if strings.TrimSpace(req.CompetitorID) != "" {
+		competitorID, err = parseUUID(req.CompetitorID)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_competitor_id", "competitorId must be UUID")
+			return
+		}
+	}
+
+	var loss dbgen.OpportunityLoss
+	var current dbgen.Opportunity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		current, queryErr = q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, current); queryErr != nil {
+			return queryErr
+		}
+		if isClosedStage(current.Stage) {
+			return errOpportunityNotOpen
+		}
+
+		closed, queryErr := q.CloseOpportunityAsLost(r.Context(), dbgen.CloseOpportunityAsLostParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		loss, queryErr = q.CreateOpportunityLoss(r.Context(), dbgen.CreateOpportunityLossParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			Reason:        reason,
+			Detail:        toPGText(strings.TrimSpace(req.Detail)),
+			Competitor:    toPGText(strings.TrimSpace(req.Competitor)),
+			LostAt:        lostAt,
+			CreatedBy:     toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if competitorID != uuid.Nil {
+			competitor, queryErr := linkOpportunityCompetitor(r, q, tenantID, actorID, opportunityID, competitorID, true, pgtype.Text{})
+			if queryErr != nil {
+				return queryErr
+			}
+			loss.Competitor = toPGText(competitor.Name)
+		}
+		if queryErr := recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{OpportunityStageEnum: current.Stage, Valid: true}, closed, toPGUUID(actorID)); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, map[string]any{
+			"event":        "mark_lost",
+			"fromStage":    string(current.Stage),
+			"reason":       string(reason),
+			"competitor":   pgTextToString(loss.Competitor),
+			"competitorId": req.CompetitorID,
+		})
+	}); err != nil {
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case errors.Is(err, errOpportunityNotOpen):
+			writeError(w, http.StatusConflict, "opportunity_closed", "opportunity is already "+string(current.Stage)+"; reopen it first")
+		case errors.Is(err, errCompetitorNotFound):
+			writeError(w, http.StatusBadRequest, "invalid_competitor_id", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "mark_lost_failed", "failed to mark opportunity as lost")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": lossDTO(loss)})
+}
+
+func (h OpportunityHandler) Reopen(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Stage       string `json:"stage"`
+		Probability *int16 `json:"probability"`
+		Note        string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	stage, err := parseOpportunityStage(req.Stage)
+	if err != nil || strings.TrimSpace(req.Stage) == "" || isClosedStage(stage.OpportunityStageEnum) {
+		writeError(w, http.StatusBadRequest, "invalid_stage", "stage must be one of new_lead, qualified, proposal, negotiation")
+		return
+	}
+	probability := defaultStageProbability[stage.OpportunityStageEnum]
+	if req.Probability != nil {
+		if *req.Probability < 0 || *req.Probability > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_probability", "probability must be between 0 and 100")
+			return
+		}
+		probability = *req.Probability
+	}
+
+	var row dbgen.Opportunity
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, current); queryErr != nil {
+			return queryErr
+		}
+		if current.Stage != dbgen.OpportunityStageEnumClosedLost {
+			return errOpportunityNotLost
+		}
+
+		loss, queryErr := q.DeleteOpportunityLoss(r.Context(), dbgen.DeleteOpportunityLossParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil && !errors.Is(queryErr, pgx.ErrNoRows) {
+			return queryErr
+		}
+		if queryErr := q.ClearOpportunityCompetitorWinner(r.Context(), dbgen.ClearOpportunityCompetitorWinnerParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		}); queryErr != nil {
+			return queryErr
+		}
+
+		row, queryErr = q.UpdateOpportunity(r.Context(), dbgen.UpdateOpportunityParams{
+			Stage:         stage,
+			Probability:   pgtype.Int2{Int16: probability, Valid: true},
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := recordStageChange(r.Context(), q, dbgen.NullOpportunityStageEnum{OpportunityStageEnum: current.Stage, Valid: true}, row, toPGUUID(actorID)); queryErr != nil {
+			return queryErr
+		}
+
+		metadata := map[string]any{
+			"event":   "reopen",
+			"toStage": string(row.Stage),
+			"note":    strings.TrimSpace(req.Note),
+		}
+		if loss.ID.Valid {
+			metadata["removedLoss"] = lossDTO(loss)
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, metadata)
+	}); err != nil {
+		switch {
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case errors.Is(err, errOpportunityNotLost):
+			writeError(w, http.StatusConflict, "opportunity_not_lost", "only closed_lost opportunities can be reopened here")
+		default:
+			writeError(w, http.StatusInternalServerError, "reopen_failed", "failed to reopen opportunity")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": opportunityDTO(row)})
+}
+
+func parseLossReason(raw string) (dbgen.LossReasonEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "budget":
+		return dbgen.LossReasonEnumBudget, nil
+	case "competitor":
+		return dbgen.LossReasonEnumCompetitor, nil
+	case "timing":
+		return dbgen.LossReasonEnumTiming, nil
+	case "no_decision":
+		return dbgen.LossReasonEnumNoDecision, nil
+	case "other":
+		return dbgen.LossReasonEnumOther, nil
+	default:
+		return "", errors.New("reason must be budget, competitor, timing, no_decision, or other")
+	}
+}
+
+func lossDTO(row dbgen.OpportunityLoss) map[string]any {
+	return map[string]any{
+		"id":            pgUUIDToString(row.ID),
+		"opportunityId": pgUUIDToString(row.OpportunityID),
+		"reason":        string(row.Reason),
+		"detail":        pgTextToString(row.Detail),
+		"competitor":    pgTextToString(row.Competitor),
+		"lostAt":        pgTimestampToString(row.LostAt),
+		"createdBy":     pgUUIDToString(row.CreatedBy),
+		"createdAt":     pgTimestampToString(row.CreatedAt),
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+// opportunityStageTransitions lists the stages an opportunity may move to. closed_lost is
+// entered and left only through the loss flow, so it never appears here; closed_won is
+// entered only through close-won (see checkPatchStageTransition).
+var opportunityStageTransitions = map[dbgen.OpportunityStageEnum][]dbgen.OpportunityStageEnum{
+	dbgen.OpportunityStageEnumNewLead: {
+		dbgen.OpportunityStageEnumQualified,
+	},
+	dbgen.OpportunityStageEnumQualified: {
+		dbgen.OpportunityStageEnumNewLead,
+		dbgen.OpportunityStageEnumProposal,
+	},
+	dbgen.OpportunityStageEnumProposal: {
+		dbgen.OpportunityStageEnumQualified,
+		dbgen.OpportunityStageEnumNegotiation,
+		dbgen.OpportunityStageEnumClosedWon,
+	},
+	dbgen.OpportunityStageEnumNegotiation: {
+		dbgen.OpportunityStageEnumProposal,
+		dbgen.OpportunityStageEnumClosedWon,
+	},
+	dbgen.OpportunityStageEnumClosedWon: {
+		dbgen.OpportunityStageEnumNegotiation,
+	},
+	dbgen.OpportunityStageEnumClosedLost: {},
+}
+
+var defaultStageProbability = map[dbgen.OpportunityStageEnum]int16{
+	dbgen.OpportunityStageEnumNewLead:     10,
+	dbgen.OpportunityStageEnumQualified:   25,
+	dbgen.OpportunityStageEnumProposal:    50,
+	dbgen.OpportunityStageEnumNegotiation: 75,
+	dbgen.OpportunityStageEnumClosedWon:   100,
+	dbgen.OpportunityStageEnumClosedLost:  0,
+}
+
+type stageTransitionError struct {
+	From    dbgen.OpportunityStageEnum
+	To      dbgen.OpportunityStageEnum
+	Allowed []dbgen.OpportunityStageEnum
+}
+
+func (e stageTransitionError) Error() string {
+	if e.To == dbgen.OpportunityStageEnumClosedLost {
+		return "use POST /opportunities/{id}/lost to mark an opportunity as lost"
+	}
+	if e.To == dbgen.OpportunityStageEnumClosedWon && e.From != e.To && checkStageTransition(e.From, e.To) == nil {
+		return "use POST /opportunities/{id}/close-won to mark an opportunity as won"
+	}
+	if e.From == dbgen.OpportunityStageEnumClosedLost {
+		return "lost opportunities must be reopened through the loss flow"
+	}
+	return fmt.Sprintf("cannot move opportunity from %s to %s", e.From, e.To)
+}
+
+func (e stageTransitionError) allowedStrings() []string {
+	out := make([]string, 0, len(e.Allowed))
+	for _, stage := range e.Allowed {
+		out = append(out, string(stage))
+	}
+	return out
+}
+
+func checkStageTransition(from, to dbgen.OpportunityStageEnum) error {
+	if from == to {
+		return nil
+	}
+	for _, allowed := range opportunityStageTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return stageTransitionError{From: from, To: to, Allowed: opportunityStageTransitions[from]}
+}
+
+// checkPatchStageTransition applies the transition policy to PATCH, which only moves
+// between open stages (and reopens a won deal): closing goes through close-won or the
+// loss flow, so the closed stages are never offered as next stages here.
+func checkPatchStageTransition(from, to dbgen.OpportunityStageEnum) error {
+	if from == to {
+		return nil
+	}
+	allowed := make([]dbgen.OpportunityStageEnum, 0, len(opportunityStageTransitions[from]))
+	for _, stage := range opportunityStageTransitions[from] {
+		if !isClosedStage(stage) {
+			allowed = append(allowed, stage)
+		}
+	}
+	if isClosedStage(to) {
+		return stageTransitionError{From: from, To: to, Allowed: allowed}
+	}
+	if err := checkStageTransition(from, to); err != nil {
+		return stageTransitionError{From: from, To: to, Allowed: allowed}
+	}
+	return nil
+}
+
+func isClosedStage(stage dbgen.OpportunityStageEnum) bool {
+	return stage == dbgen.OpportunityStageEnumClosedWon || stage == dbgen.OpportunityStageEnumClosedLost
+}
+
+// recordStageChange appends a stage history row capturing the amount and probability
+// the opportunity had when it entered row.Stage. from is invalid for newly created deals.
+func recordStageChange(ctx context.Context, q *dbgen.Queries, from dbgen.NullOpportunityStageEnum, row dbgen.Opportunity, actorID pgtype.UUID) error {
+	return q.CreateOpportunityStageHistory(ctx, dbgen.CreateOpportunityStageHistoryParams{
+		TenantID:      row.TenantID,
+		OpportunityID: row.ID,
+		FromStage:     from,
+		ToStage:       row.Stage,
+		Amount:        row.Amount,
+		Probability:   row.Probability,
+		ChangedBy:     actorID,
+	})
+}
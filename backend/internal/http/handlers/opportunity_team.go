@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+type teamMemberError struct {
+	Index   int
+	Message string
+}
+
+func (e teamMemberError) Error() string {
+	return fmt.Sprintf("members[%d]: %s", e.Index, e.Message)
+}
+
+func (h OpportunityHandler) Team(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.ListOpportunityTeamMembersRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListOpportunityTeamMembers(r.Context(), dbgen.ListOpportunityTeamMembersParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "team_list_failed", "failed to load opportunity team")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": teamMemberListDTO(rows)})
+}
+
+// ReplaceTeam swaps the whole team in one transaction. Only the owner, managers and
+// admins may change it; a non-empty team must split exactly 100%.
+func (h OpportunityHandler) ReplaceTeam(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		Members []struct {
+			UserID       string  `json:"userId"`
+			Role         string  `json:"role"`
+			SplitPercent float64 `json:"splitPercent"`
+		} `json:"members"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	userIDs := make([]uuid.UUID, len(req.Members))
+	roles := make([]dbgen.OpportunityTeamRoleEnum, len(req.Members))
+	seen := map[uuid.UUID]bool{}
+	primaryReps := 0
+	var splitCents int64
+	for i, member := range req.Members {
+		id, parseErr := parseUUID(member.UserID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_team_member", teamMemberError{Index: i, Message: "userId must be UUID"}.Error())
+			return
+		}
+		if seen[id] {
+			writeError(w, http.StatusBadRequest, "invalid_team_member", teamMemberError{Index: i, Message: "user is listed more than once"}.Error())
+			return
+		}
+		seen[id] = true
+		userIDs[i] = id
+
+		role, parseErr := parseTeamRole(member.Role)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_team_member", teamMemberError{Index: i, Message: parseErr.Error()}.Error())
+			return
+		}
+		if role == dbgen.OpportunityTeamRoleEnumPrimaryRep {
+			primaryReps++
+		}
+		roles[i] = role
+
+		if member.SplitPercent < 0 || member.SplitPercent > 100 {
+			writeError(w, http.StatusBadRequest, "invalid_team_member", teamMemberError{Index: i, Message: "splitPercent must be between 0 and 100"}.Error())
+			return
+		}
+		splitCents += int64(math.Round(member.SplitPercent * 100))
+	}
+	if primaryReps > 1 {
+		writeError(w, http.StatusBadRequest, "invalid_team", "a team can have only one primary_rep")
+		return
+	}
+	if len(req.Members) > 0 && splitCents != 10000 {
+		writeError(w, http.StatusBadRequest, "invalid_split", fmt.Sprintf("splitPercent must add up to 100 (got %.2f)", float64(splitCents)/100))
+		return
+	}
+
+	var rows []dbgen.ListOpportunityTeamMembersRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		role, queryErr := actorRole(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		if role == dbgen.RoleEnumSales && opportunity.OwnerUserID != toPGUUID(actorID) {
+			return errOpportunityForbidden
+		}
+
+		if queryErr := q.DeleteOpportunityTeamMembers(r.Context(), dbgen.DeleteOpportunityTeamMembersParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		}); queryErr != nil {
+			return queryErr
+		}
+		for i, member := range req.Members {
+			if _, memberErr := actorRole(r.Context(), q, tenantID, userIDs[i]); memberErr != nil {
+				if errors.Is(memberErr, errNotTenantMember) {
+					return teamMemberError{Index: i, Message: "user is not an active member of this tenant"}
+				}
+				return memberErr
+			}
+			if queryErr := q.CreateOpportunityTeamMember(r.Context(), dbgen.CreateOpportunityTeamMemberParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: toPGUUID(opportunityID),
+				UserID:        toPGUUID(userIDs[i]),
+				TeamRole:      roles[i],
+				SplitPercent:  toPGNumeric(member.SplitPercent),
+				CreatedBy:     toPGUUID(actorID),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+
+		rows, queryErr = q.ListOpportunityTeamMembers(r.Context(), dbgen.ListOpportunityTeamMembersParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "opportunity", opportunityID, map[string]any{
+			"event":   "team_replaced",
+			"members": teamMemberListDTO(rows),
+		})
+	}); err != nil {
+		var memberErr teamMemberError
+		switch {
+		case errors.As(err, &memberErr):
+			writeError(w, http.StatusBadRequest, "invalid_team_member", memberErr.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "team_replace_failed", "failed to save opportunity team")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": teamMemberListDTO(rows)})
+}
+
+func parseTeamRole(raw string) (dbgen.OpportunityTeamRoleEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "primary_rep":
+		return dbgen.OpportunityTeamRoleEnumPrimaryRep, nil
+	case "presales_engineer":
+		return dbgen.OpportunityTeamRoleEnumPresalesEngineer, nil
+	case "partner_manager":
+		return dbgen.OpportunityTeamRoleEnumPartnerManager, nil
+	case "executive_sponsor":
+		return dbgen.OpportunityTeamRoleEnumExecutiveSponsor, nil
+	case "other":
+		return dbgen.OpportunityTeamRoleEnumOther, nil
+	default:
+		return "", errors.New("role must be primary_rep, presales_engineer, partner_manager, executive_sponsor, or other")
+	}
+}
+
+func teamMemberListDTO(rows []dbgen.ListOpportunityTeamMembersRow) []map[string]any {
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"id":            pgUUIDToString(row.ID),
+			"opportunityId": pgUUIDToString(row.OpportunityID),
+			"userId":        pgUUIDToString(row.UserID),
+			"displayName":   row.DisplayName,
+			"email":         row.Email,
+			"role":          string(row.TeamRole),
+			"splitPercent":  pgNumericToFloat(row.SplitPercent),
+			"createdBy":     pgUUIDToString(row.CreatedBy),
+			"createdAt":     pgTimestampToString(row.CreatedAt),
+			"updatedAt":     pgTimestampToString(row.UpdatedAt),
+		})
+	}
+	return data
+}
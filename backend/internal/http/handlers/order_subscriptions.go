@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+// maxSubscriptionYears caps a term so a typo cannot generate centuries of schedule rows.
+const maxSubscriptionYears = 10
+
+// maxRevenueReportMonths caps the month range of the revenue schedule report.
+const maxRevenueReportMonths = 36
+
+type subscriptionTerm struct {
+	TermStart        pgtype.Date
+	TermEnd          pgtype.Date
+	BillingFrequency dbgen.BillingFrequencyEnum
+}
+
+// parseSubscriptionTerm validates a term given as YYYY-MM-DD dates. Without dates the
+// order is a one-time order; a recurring billingFrequency then has nothing to bill over.
+func parseSubscriptionTerm(rawStart, rawEnd, rawFrequency string) (subscriptionTerm, error) {
+	term := subscriptionTerm{BillingFrequency: dbgen.BillingFrequencyEnumOneTime}
+	if strings.TrimSpace(rawFrequency) != "" {
+		frequency, err := parseBillingFrequency(rawFrequency)
+		if err != nil {
+			return term, err
+		}
+		term.BillingFrequency = frequency
+	}
+	var err error
+	if term.TermStart, err = parseOptionalDate(rawStart); err != nil {
+		return term, errors.New("termStart must be YYYY-MM-DD")
+	}
+	if term.TermEnd, err = parseOptionalDate(rawEnd); err != nil {
+		return term, errors.New("termEnd must be YYYY-MM-DD")
+	}
+	switch {
+	case term.TermStart.Valid != term.TermEnd.Valid:
+		return term, errors.New("termStart and termEnd must be given together")
+	case !term.TermStart.Valid && term.BillingFrequency != dbgen.BillingFrequencyEnumOneTime:
+		return term, errors.New("termStart and termEnd are required for recurring billing")
+	case term.TermStart.Valid && term.TermEnd.Time.Before(term.TermStart.Time):
+		return term, errors.New("termEnd must not be before termStart")
+	case term.TermStart.Valid && term.TermEnd.Time.After(term.TermStart.Time.AddDate(maxSubscriptionYears, 0, 0)):
+		return term, errors.New("term must not exceed 10 years")
+	}
+	return term, nil
+}
+
+func parseBillingFrequency(raw string) (dbgen.BillingFrequencyEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "one_time":
+		return dbgen.BillingFrequencyEnumOneTime, nil
+	case "monthly":
+		return dbgen.BillingFrequencyEnumMonthly, nil
+	case "quarterly":
+		return dbgen.BillingFrequencyEnumQuarterly, nil
+	case "semi_annual":
+		return dbgen.BillingFrequencyEnumSemiAnnual, nil
+	case "annual":
+		return dbgen.BillingFrequencyEnumAnnual, nil
+	default:
+		return "", errors.New("billingFrequency must be one_time, monthly, quarterly, semi_annual, or annual")
+	}
+}
+
+// rebuildOrderSchedule replaces the order's monthly revenue and billing schedule. Call it
+// whenever the amount, dates or term of an order change.
+func rebuildOrderSchedule(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, orderID pgtype.UUID) error {
+	if err := q.DeleteOrderRevenueSchedule(ctx, dbgen.DeleteOrderRevenueScheduleParams{
+		TenantID: toPGUUID(tenantID),
+		OrderID:  orderID,
+	}); err != nil {
+		return err
+	}
+	_, err := q.BuildOrderRevenueSchedule(ctx, dbgen.BuildOrderRevenueScheduleParams{
+		TenantID: toPGUUID(tenantID),
+		OrderID:  orderID,
+	})
+	return err
+}
+
+// SetSubscription sets the term and billing frequency of a pending order and rebuilds its
+// schedule.
+func (h OrderHandler) SetSubscription(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	orderID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_order_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		TermStart        string `json:"termStart"`
+		TermEnd          string `json:"termEnd"`
+		BillingFrequency string `json:"billingFrequency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	term, err := parseSubscriptionTerm(req.TermStart, req.TermEnd, req.BillingFrequency)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_subscription_term", err.Error())
+		return
+	}
+
+	var row dbgen.Order
+	var schedule []dbgen.OrderRevenueSchedule
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetOrderForUpdate(r.Context(), dbgen.GetOrderForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  toPGUUID(orderID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if current.Status != dbgen.OrderStatusEnumPending {
+			return errOrderNotEditable
+		}
+		row, queryErr = q.SetOrderSubscription(r.Context(), dbgen.SetOrderSubscriptionParams{
+			TermStart:        term.TermStart,
+			TermEnd:          term.TermEnd,
+			BillingFrequency: term.BillingFrequency,
+			TenantID:         toPGUUID(tenantID),
+			OrderID:          current.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := rebuildOrderSchedule(r.Context(), q, tenantID, row.ID); queryErr != nil {
+			return queryErr
+		}
+		schedule, queryErr = q.ListOrderRevenueSchedule(r.Context(), dbgen.ListOrderRevenueScheduleParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  row.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "order", orderID, map[string]any{
+			"event":            "subscription_changed",
+			"orderNo":          row.OrderNo,
+			"termStart":        pgDateToString(row.TermStart),
+			"termEnd":          pgDateToString(row.TermEnd),
+			"billingFrequency": string(row.BillingFrequency),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "order not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errOrderNotEditable):
+			writeError(w, http.StatusConflict, "order_not_editable", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_update_failed", "failed to update order subscription")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": orderDTO(row),
+		"meta": map[string]any{"schedule": orderScheduleDTO(schedule)},
+	})
+}
+
+func (h OrderHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	orderID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_order_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Order
+	var schedule []dbgen.OrderRevenueSchedule
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetOrder(r.Context(), dbgen.GetOrderParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  toPGUUID(orderID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(row.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		schedule, queryErr = q.ListOrderRevenueSchedule(r.Context(), dbgen.ListOrderRevenueScheduleParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  row.ID,
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "order not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_schedule_failed", "failed to load order schedule")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": orderScheduleDTO(schedule),
+		"meta": map[string]any{
+			"orderNo":          row.OrderNo,
+			"currency":         row.Currency,
+			"amount":           pgNumericToFloat(row.Amount),
+			"termStart":        pgDateToString(row.TermStart),
+			"termEnd":          pgDateToString(row.TermEnd),
+			"billingFrequency": string(row.BillingFrequency),
+		},
+	})
+}
+
+// RevenueSchedule reports order revenue by month, account and owner in the tenant's base
+// currency. Months up to the asOf month are recognized, later months scheduled.
+func (h DashboardHandler) RevenueSchedule(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	now := time.Now().UTC()
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if raw := query.Get("asOf"); raw != "" {
+		parsed, parseErr := time.Parse("2006-01-02", raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_as_of", "asOf must be YYYY-MM-DD")
+			return
+		}
+		asOf = parsed
+	}
+	fromMonth := time.Date(asOf.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if raw := query.Get("from"); raw != "" {
+		parsed, parseErr := time.Parse("2006-01", raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_date_range", "from must be YYYY-MM")
+			return
+		}
+		fromMonth = parsed
+	}
+	toMonth := fromMonth.AddDate(0, 11, 0)
+	if raw := query.Get("to"); raw != "" {
+		parsed, parseErr := time.Parse("2006-01", raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_date_range", "to must be YYYY-MM")
+			return
+		}
+		toMonth = parsed
+	}
+	if toMonth.Before(fromMonth) {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", "from must not be after to")
+		return
+	}
+	if toMonth.After(fromMonth.AddDate(0, maxRevenueReportMonths-1, 0)) {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", "range must not exceed 36 months")
+		return
+	}
+	var accountID, ownerID pgtype.UUID
+	if raw := query.Get("accountId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_account_id", "accountId must be UUID")
+			return
+		}
+		accountID = toPGUUID(id)
+	}
+	if raw := query.Get("ownerUserId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		ownerID = toPGUUID(id)
+	}
+	asOfMonth := time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var baseCurrency string
+	var rows []dbgen.GetRevenueScheduleRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		visibleTo, queryErr := opportunityVisibility(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		baseCurrency, queryErr = q.GetTenantBaseCurrency(r.Context(), toPGUUID(tenantID))
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.GetRevenueSchedule(r.Context(), dbgen.GetRevenueScheduleParams{
+			AsOfMonth:   pgtype.Date{Time: asOfMonth, Valid: true},
+			TenantID:    toPGUUID(tenantID),
+			FromMonth:   pgtype.Date{Time: fromMonth, Valid: true},
+			ToMonth:     pgtype.Date{Time: toMonth, Valid: true},
+			AccountID:   accountID,
+			OwnerUserID: ownerID,
+			VisibleTo:   visibleTo,
+		})
+		return queryErr
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "revenue_schedule_failed", "failed to load revenue schedule")
+		return
+	}
+
+	var recognized, scheduled, billed float64
+	var missingRates int64
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		recognized += row.RecognizedAmount
+		scheduled += row.ScheduledAmount
+		billed += row.BillingAmount
+		missingRates += row.MissingRateCount
+		data = append(data, map[string]any{
+			"month":            row.PeriodMonth.Time.Format("2006-01"),
+			"accountId":        pgUUIDToString(row.AccountID),
+			"accountName":      row.AccountName,
+			"ownerUserId":      pgUUIDToString(row.OwnerUserID),
+			"ownerName":        row.OwnerName,
+			"recognizedAmount": row.RecognizedAmount,
+			"scheduledAmount":  row.ScheduledAmount,
+			"billingAmount":    row.BillingAmount,
+			"orderCount":       row.OrderCount,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": data,
+		"meta": map[string]any{
+			"baseCurrency":     baseCurrency,
+			"from":             fromMonth.Format("2006-01"),
+			"to":               toMonth.Format("2006-01"),
+			"asOf":             asOf.Format("2006-01-02"),
+			"recognizedAmount": recognized,
+			"scheduledAmount":  scheduled,
+			"billingAmount":    billed,
+			"missingRateCount": missingRates,
+		},
+	})
+}
+
+func orderScheduleDTO(rows []dbgen.OrderRevenueSchedule) []map[string]any {
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, map[string]any{
+			"month":         row.PeriodMonth.Time.Format("2006-01"),
+			"revenueAmount": pgNumericToFloat(row.RevenueAmount),
+			"billingAmount": pgNumericToFloat(row.BillingAmount),
+			"currency":      row.Currency,
+		})
+	}
+	return data
+}
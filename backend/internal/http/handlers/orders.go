@@ -0,0 +1,612 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errOpportunityNotWon = errors.New("orders can only be added to closed_won opportunities")
+var errInvalidOrderTransition = errors.New("order status transition is not allowed")
+var errOrderNotEditable = errors.New("only pending orders can be edited")
+var errCancelReasonRequired = errors.New("reason is required to cancel an order")
+var errQuoteNotAccepted = errors.New("quoteId must be an accepted quote of this opportunity")
+
+// orderTransitions lists the statuses each order status may move to. Invoiced and
+// cancelled orders are final.
+var orderTransitions = map[dbgen.OrderStatusEnum][]dbgen.OrderStatusEnum{
+	dbgen.OrderStatusEnumPending:   {dbgen.OrderStatusEnumConfirmed, dbgen.OrderStatusEnumCancelled},
+	dbgen.OrderStatusEnumConfirmed: {dbgen.OrderStatusEnumInvoiced, dbgen.OrderStatusEnumCancelled},
+}
+
+type OrderHandler struct {
+	Store *store.Store
+}
+
+func NewOrderHandler(store *store.Store) OrderHandler {
+	return OrderHandler{Store: store}
+}
+
+// List returns the back-office order list across the tenant. Sales users only see orders
+// of deals they own or are on the team of.
+func (h OrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	offset, limit := queryPageLimit(r, 50)
+	query := r.URL.Query()
+	var statuses []string
+	if raw := query.Get("status"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			status, parseErr := parseOrderStatus(part)
+			if parseErr != nil {
+				writeError(w, http.StatusBadRequest, "invalid_status", parseErr.Error())
+				return
+			}
+			statuses = append(statuses, string(status))
+		}
+	}
+	orderedFrom, err := parseOptionalDate(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", "from must be YYYY-MM-DD")
+		return
+	}
+	orderedTo, err := parseOptionalDate(query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", "to must be YYYY-MM-DD")
+		return
+	}
+	if orderedFrom.Valid && orderedTo.Valid && orderedTo.Time.Before(orderedFrom.Time) {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", "from must not be after to")
+		return
+	}
+	var accountID, ownerID pgtype.UUID
+	if raw := query.Get("accountId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_account_id", "accountId must be UUID")
+			return
+		}
+		accountID = toPGUUID(id)
+	}
+	if raw := query.Get("ownerUserId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		ownerID = toPGUUID(id)
+	}
+
+	var rows []dbgen.ListOrdersRow
+	var summary dbgen.SummarizeOrdersRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		visibleTo, queryErr := opportunityVisibility(r.Context(), q, tenantID, actorID)
+		if queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListOrders(r.Context(), dbgen.ListOrdersParams{
+			TenantID:    toPGUUID(tenantID),
+			Statuses:    statuses,
+			OrderedFrom: orderedFrom,
+			OrderedTo:   orderedTo,
+			AccountID:   accountID,
+			OwnerUserID: ownerID,
+			VisibleTo:   visibleTo,
+			OffsetCount: offset,
+			LimitCount:  limit,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		summary, queryErr = q.SummarizeOrders(r.Context(), dbgen.SummarizeOrdersParams{
+			TenantID:    toPGUUID(tenantID),
+			Statuses:    statuses,
+			OrderedFrom: orderedFrom,
+			OrderedTo:   orderedTo,
+			AccountID:   accountID,
+			OwnerUserID: ownerID,
+			VisibleTo:   visibleTo,
+		})
+		return queryErr
+	}); err != nil {
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "order_list_failed", "failed to load orders")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		item := orderDTO(dbgen.Order{
+			ID:               row.ID,
+			TenantID:         row.TenantID,
+			OpportunityID:    row.OpportunityID,
+			OrderNo:          row.OrderNo,
+			Amount:           row.Amount,
+			Status:           row.Status,
+			OrderedOn:        row.OrderedOn,
+			Note:             row.Note,
+			CreatedBy:        row.CreatedBy,
+			CreatedAt:        row.CreatedAt,
+			UpdatedAt:        row.UpdatedAt,
+			Currency:         row.Currency,
+			ConfirmedAt:      row.ConfirmedAt,
+			InvoicedAt:       row.InvoicedAt,
+			CancelledAt:      row.CancelledAt,
+			CancelReason:     row.CancelReason,
+			Version:          row.Version,
+			TermStart:        row.TermStart,
+			TermEnd:          row.TermEnd,
+			BillingFrequency: row.BillingFrequency,
+		})
+		item["opportunityName"] = row.OpportunityName
+		item["ownerUserId"] = pgUUIDToString(row.OwnerUserID)
+		item["accountId"] = pgUUIDToString(row.AccountID)
+		item["accountName"] = row.AccountName
+		data = append(data, item)
+	}
+	meta := map[string]any{
+		"page":        offset/limit + 1,
+		"limit":       limit,
+		"total":       summary.Total,
+		"totalAmount": nil,
+	}
+	// Amounts in different currencies are not added up.
+	if summary.CurrencyCount <= 1 {
+		meta["totalAmount"] = pgNumericToFloat(summary.TotalAmount)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data, "meta": meta})
+}
+
+func (h OrderHandler) ListByOpportunity(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.Order
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, opportunityID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListOrdersByOpportunity(r.Context(), dbgen.ListOrdersByOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_list_failed", "failed to load orders")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, orderDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// Create adds a pending order to a closed_won opportunity, e.g. a follow-up delivery or a
+// renewal. The order copies the line items of an accepted quote when quoteId is given;
+// otherwise amount is required and line items can be added afterwards. termStart, termEnd
+// and billingFrequency make it a subscription order.
+func (h OrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		QuoteID          string   `json:"quoteId"`
+		Amount           *float64 `json:"amount"`
+		OrderedOn        string   `json:"orderedOn"`
+		Note             string   `json:"note"`
+		TermStart        string   `json:"termStart"`
+		TermEnd          string   `json:"termEnd"`
+		BillingFrequency string   `json:"billingFrequency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	term, err := parseSubscriptionTerm(req.TermStart, req.TermEnd, req.BillingFrequency)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_subscription_term", err.Error())
+		return
+	}
+	var quoteID pgtype.UUID
+	if strings.TrimSpace(req.QuoteID) != "" {
+		id, parseErr := parseUUID(req.QuoteID)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_quote_id", "quoteId must be UUID")
+			return
+		}
+		quoteID = toPGUUID(id)
+	}
+	switch {
+	case quoteID.Valid && req.Amount != nil:
+		writeError(w, http.StatusBadRequest, "invalid_amount", "pass either quoteId or amount")
+		return
+	case !quoteID.Valid && req.Amount == nil:
+		writeError(w, http.StatusBadRequest, "invalid_amount", "amount is required without quoteId")
+		return
+	case req.Amount != nil && *req.Amount < 0:
+		writeError(w, http.StatusBadRequest, "invalid_amount", "amount must be >= 0")
+		return
+	}
+	orderedOn, err := parseOptionalDate(req.OrderedOn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_ordered_on", "orderedOn must be YYYY-MM-DD")
+		return
+	}
+	now := time.Now().UTC()
+	if !orderedOn.Valid {
+		orderedOn = pgtype.Date{Time: now.Truncate(24 * time.Hour), Valid: true}
+	}
+
+	var row dbgen.Order
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		if opportunity.Stage != dbgen.OpportunityStageEnumClosedWon {
+			return errOpportunityNotWon
+		}
+
+		var quote dbgen.Quote
+		amount := toPGNumeric(0)
+		if req.Amount != nil {
+			amount = toPGNumeric(*req.Amount)
+		}
+		if quoteID.Valid {
+			quote, queryErr = q.GetQuoteForUpdate(r.Context(), dbgen.GetQuoteForUpdateParams{
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: opportunity.ID,
+				QuoteID:       quoteID,
+			})
+			if errors.Is(queryErr, pgx.ErrNoRows) || (queryErr == nil && quote.Status != dbgen.QuoteStatusEnumAccepted) {
+				return errQuoteNotAccepted
+			}
+			if queryErr != nil {
+				return queryErr
+			}
+			if quote.Currency != opportunity.Currency {
+				return errQuoteCurrencyMismatch
+			}
+			amount = quote.Amount
+		}
+
+		orderNo, queryErr := nextDocumentNumber(r.Context(), q, tenantID, documentTypeOrder, now)
+		if queryErr != nil {
+			return queryErr
+		}
+		note := strings.TrimSpace(req.Note)
+		if note == "" && quote.ID.Valid {
+			note = "Converted from quote " + quote.QuoteNo
+		}
+		row, queryErr = q.CreateOrder(r.Context(), dbgen.CreateOrderParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: opportunity.ID,
+			OrderNo:       orderNo,
+			Amount:        amount,
+			Currency:      toPGText(opportunity.Currency),
+			OrderedOn:     orderedOn,
+			Note:          toPGText(note),
+			CreatedBy:     toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if quote.ID.Valid {
+			copied, queryErr := q.CopyQuoteLineItems(r.Context(), dbgen.CopyQuoteLineItemsParams{
+				OrderID:  row.ID,
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  quote.ID,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if copied > 0 {
+				if row, queryErr = q.RecalculateOrderAmount(r.Context(), dbgen.RecalculateOrderAmountParams{
+					TenantID: toPGUUID(tenantID),
+					OrderID:  row.ID,
+				}); queryErr != nil {
+					return queryErr
+				}
+			}
+		}
+		if term.TermStart.Valid {
+			if row, queryErr = q.SetOrderSubscription(r.Context(), dbgen.SetOrderSubscriptionParams{
+				TermStart:        term.TermStart,
+				TermEnd:          term.TermEnd,
+				BillingFrequency: term.BillingFrequency,
+				TenantID:         toPGUUID(tenantID),
+				OrderID:          row.ID,
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+		if queryErr := rebuildOrderSchedule(r.Context(), q, tenantID, row.ID); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "order", uuid.UUID(row.ID.Bytes), map[string]any{
+			"opportunityId":    opportunityID.String(),
+			"orderNo":          row.OrderNo,
+			"amount":           pgNumericToFloat(row.Amount),
+			"quoteId":          pgUUIDToString(quote.ID),
+			"billingFrequency": string(row.BillingFrequency),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errOpportunityNotWon):
+			writeError(w, http.StatusConflict, "opportunity_not_won", err.Error())
+		case errors.Is(err, errQuoteNotAccepted):
+			writeError(w, http.StatusBadRequest, "invalid_quote_id", err.Error())
+		case errors.Is(err, errQuoteCurrencyMismatch):
+			writeError(w, http.StatusConflict, "currency_mismatch", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_create_failed", "failed to create order")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": orderDTO(row)})
+}
+
+func (h OrderHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	orderID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_order_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Order
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetOrder(r.Context(), dbgen.GetOrderParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  toPGUUID(orderID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(row.OpportunityID.Bytes))
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "order not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_get_failed", "failed to load order")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": orderDTO(row)})
+}
+
+// Transition moves an order along pending -> confirmed -> invoiced. Pending and confirmed
+// orders may be cancelled with a reason; invoiced and cancelled orders are final.
+func (h OrderHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	orderID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_order_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	target, err := parseOrderStatus(req.Status)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_status", err.Error())
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if target == dbgen.OrderStatusEnumCancelled && reason == "" {
+		writeError(w, http.StatusBadRequest, "cancel_reason_required", errCancelReasonRequired.Error())
+		return
+	}
+
+	var row dbgen.Order
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		current, queryErr := q.GetOrderForUpdate(r.Context(), dbgen.GetOrderForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			OrderID:  toPGUUID(orderID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if !orderTransitionAllowed(current.Status, target) {
+			return errInvalidOrderTransition
+		}
+		row, queryErr = q.TransitionOrderStatus(r.Context(), dbgen.TransitionOrderStatusParams{
+			Status:       target,
+			CancelReason: toPGText(reason),
+			TenantID:     toPGUUID(tenantID),
+			OrderID:      current.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		metadata := map[string]any{
+			"event":      "status_changed",
+			"fromStatus": string(current.Status),
+			"toStatus":   string(target),
+			"orderNo":    row.OrderNo,
+		}
+		if reason != "" {
+			metadata["reason"] = reason
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "order", orderID, metadata)
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "order not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errInvalidOrderTransition):
+			writeError(w, http.StatusConflict, "invalid_order_transition", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "order_transition_failed", "failed to change order status")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": orderDTO(row)})
+}
+
+func orderTransitionAllowed(from, to dbgen.OrderStatusEnum) bool {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOrderStatus(raw string) (dbgen.OrderStatusEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "pending":
+		return dbgen.OrderStatusEnumPending, nil
+	case "confirmed":
+		return dbgen.OrderStatusEnumConfirmed, nil
+	case "invoiced":
+		return dbgen.OrderStatusEnumInvoiced, nil
+	case "cancelled":
+		return dbgen.OrderStatusEnumCancelled, nil
+	default:
+		return "", errors.New("status must be pending, confirmed, invoiced, or cancelled")
+	}
+}
+
+func orderDTO(row dbgen.Order) map[string]any {
+	return map[string]any{
+		"id":               pgUUIDToString(row.ID),
+		"opportunityId":    pgUUIDToString(row.OpportunityID),
+		"orderNo":          row.OrderNo,
+		"amount":           pgNumericToFloat(row.Amount),
+		"currency":         row.Currency,
+		"status":           string(row.Status),
+		"orderedOn":        pgDateToString(row.OrderedOn),
+		"note":             pgTextToString(row.Note),
+		"termStart":        pgDateToString(row.TermStart),
+		"termEnd":          pgDateToString(row.TermEnd),
+		"billingFrequency": string(row.BillingFrequency),
+		"confirmedAt":      pgTimestampToString(row.ConfirmedAt),
+		"invoicedAt":       pgTimestampToString(row.InvoicedAt),
+		"cancelledAt":      pgTimestampToString(row.CancelledAt),
+		"cancelReason":     pgTextToString(row.CancelReason),
+		"version":          row.Version,
+		"createdBy":        pgUUIDToString(row.CreatedBy),
+		"createdAt":        pgTimestampToString(row.CreatedAt),
+		"updatedAt":        pgTimestampToString(row.UpdatedAt),
+	}
+}
@@ -0,0 +1,691 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/quotepdf"
+	"sfa/backend/internal/store"
+)
+
+var errQuotePDFTemplateNotFound = errors.New("quote pdf template not found")
+
+var (
+	accentColorPattern        = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+	registrationNumberPattern = regexp.MustCompile(`^T[0-9]{13}$`)
+)
+
+const defaultAccentColor = "#1F4E79"
+
+type QuoteDocumentHandler struct {
+	Store    *store.Store
+	Renderer *quotepdf.Renderer
+}
+
+func NewQuoteDocumentHandler(store *store.Store, renderer *quotepdf.Renderer) QuoteDocumentHandler {
+	return QuoteDocumentHandler{Store: store, Renderer: renderer}
+}
+
+type quotePDFTemplateRequest struct {
+	Name               *string `json:"name"`
+	Language           *string `json:"language"`
+	CompanyName        *string `json:"companyName"`
+	CompanyAddress     *string `json:"companyAddress"`
+	CompanyPhone       *string `json:"companyPhone"`
+	RegistrationNumber *string `json:"registrationNumber"`
+	AccentColor        *string `json:"accentColor"`
+	FooterText         *string `json:"footerText"`
+	IsDefault          *bool   `json:"isDefault"`
+}
+
+// validate trims the fields that are present and returns the error code and message
+// for the first invalid one.
+func (req *quotePDFTemplateRequest) validate() (string, string) {
+	for _, field := range []*string{req.Name, req.Language, req.CompanyName, req.CompanyAddress, req.CompanyPhone, req.RegistrationNumber, req.AccentColor, req.FooterText} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+	if req.Name != nil && *req.Name == "" {
+		return "invalid_name", "name must not be empty"
+	}
+	if req.CompanyName != nil && *req.CompanyName == "" {
+		return "invalid_company_name", "companyName must not be empty"
+	}
+	if req.Language != nil && *req.Language != "ja" && *req.Language != "en" {
+		return "invalid_language", "language must be ja or en"
+	}
+	if req.AccentColor != nil && !accentColorPattern.MatchString(*req.AccentColor) {
+		return "invalid_accent_color", "accentColor must be #RRGGBB"
+	}
+	if req.RegistrationNumber != nil && *req.RegistrationNumber != "" && !registrationNumberPattern.MatchString(*req.RegistrationNumber) {
+		return "invalid_registration_number", "registrationNumber must be T followed by 13 digits"
+	}
+	return "", ""
+}
+
+func (h QuoteDocumentHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	var rows []dbgen.QuotePdfTemplate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		rows, queryErr = q.ListQuotePDFTemplates(r.Context(), toPGUUID(tenantID))
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "quote_template_list_failed", "failed to list quote templates")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, quotePDFTemplateDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h QuoteDocumentHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	templateID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.QuotePdfTemplate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetQuotePDFTemplate(r.Context(), dbgen.GetQuotePDFTemplateParams{
+			TenantID:   toPGUUID(tenantID),
+			TemplateID: toPGUUID(templateID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "quote template not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "quote_template_get_failed", "failed to load quote template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": quotePDFTemplateDTO(row)})
+}
+
+// CreateTemplate adds a letterhead/layout template. The first template of a tenant, or
+// one created with isDefault, becomes the default used when no templateId is given.
+func (h QuoteDocumentHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	var req quotePDFTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if req.Name == nil || req.CompanyName == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "name and companyName are required")
+		return
+	}
+	if code, message := req.validate(); code != "" {
+		writeError(w, http.StatusBadRequest, code, message)
+		return
+	}
+
+	var row dbgen.QuotePdfTemplate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		isDefault := req.IsDefault != nil && *req.IsDefault
+		if !isDefault {
+			_, queryErr := q.GetDefaultQuotePDFTemplate(r.Context(), toPGUUID(tenantID))
+			if errors.Is(queryErr, pgx.ErrNoRows) {
+				isDefault = true
+			} else if queryErr != nil {
+				return queryErr
+			}
+		}
+		params := dbgen.CreateQuotePDFTemplateParams{
+			TenantID:    toPGUUID(tenantID),
+			Name:        *req.Name,
+			Language:    "ja",
+			CompanyName: *req.CompanyName,
+			AccentColor: defaultAccentColor,
+			IsDefault:   isDefault,
+			CreatedBy:   toPGUUID(actorID),
+		}
+		if req.Language != nil {
+			params.Language = *req.Language
+		}
+		if req.AccentColor != nil {
+			params.AccentColor = strings.ToUpper(*req.AccentColor)
+		}
+		if req.CompanyAddress != nil {
+			params.CompanyAddress = toPGText(*req.CompanyAddress)
+		}
+		if req.CompanyPhone != nil {
+			params.CompanyPhone = toPGText(*req.CompanyPhone)
+		}
+		if req.RegistrationNumber != nil {
+			params.RegistrationNumber = toPGText(*req.RegistrationNumber)
+		}
+		if req.FooterText != nil {
+			params.FooterText = toPGText(*req.FooterText)
+		}
+		if isDefault {
+			if queryErr := q.ClearDefaultQuotePDFTemplate(r.Context(), dbgen.ClearDefaultQuotePDFTemplateParams{
+				TenantID:       toPGUUID(tenantID),
+				KeepTemplateID: toPGUUID(uuid.Nil),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.CreateQuotePDFTemplate(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "quote_pdf_template", uuid.UUID(row.ID.Bytes), map[string]any{
+			"name":      row.Name,
+			"isDefault": row.IsDefault,
+		})
+	}); err != nil {
+		switch {
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_quote_template", "quote template name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_template_create_failed", "failed to create quote template")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"data": quotePDFTemplateDTO(row)})
+}
+
+// UpdateTemplate changes a template and bumps its version, so the next PDF request renders
+// a fresh document instead of returning the stored one. isDefault=false is ignored; make
+// another template the default instead.
+func (h QuoteDocumentHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	templateID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_template_id", "id must be UUID")
+		return
+	}
+
+	var req quotePDFTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if code, message := req.validate(); code != "" {
+		writeError(w, http.StatusBadRequest, code, message)
+		return
+	}
+
+	var row dbgen.QuotePdfTemplate
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		params := dbgen.UpdateQuotePDFTemplateParams{
+			TenantID:   toPGUUID(tenantID),
+			TemplateID: toPGUUID(templateID),
+		}
+		if req.Name != nil {
+			params.Name = toPGText(*req.Name)
+		}
+		if req.Language != nil {
+			params.Language = toPGText(*req.Language)
+		}
+		if req.CompanyName != nil {
+			params.CompanyName = toPGText(*req.CompanyName)
+		}
+		if req.CompanyAddress != nil {
+			params.CompanyAddress = toPGText(*req.CompanyAddress)
+		}
+		if req.CompanyPhone != nil {
+			params.CompanyPhone = toPGText(*req.CompanyPhone)
+		}
+		if req.RegistrationNumber != nil {
+			params.RegistrationNumber = toPGText(*req.RegistrationNumber)
+		}
+		if req.AccentColor != nil {
+			params.AccentColor = toPGText(strings.ToUpper(*req.AccentColor))
+		}
+		if req.FooterText != nil {
+			params.FooterText = toPGText(*req.FooterText)
+		}
+		if req.IsDefault != nil && *req.IsDefault {
+			params.IsDefault = pgtype.Bool{Bool: true, Valid: true}
+			if queryErr := q.ClearDefaultQuotePDFTemplate(r.Context(), dbgen.ClearDefaultQuotePDFTemplateParams{
+				TenantID:       toPGUUID(tenantID),
+				KeepTemplateID: toPGUUID(templateID),
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+		var queryErr error
+		row, queryErr = q.UpdateQuotePDFTemplate(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote_pdf_template", templateID, map[string]any{
+			"name":      row.Name,
+			"isDefault": row.IsDefault,
+			"version":   row.Version,
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote template not found")
+		case isUniqueViolation(err):
+			writeError(w, http.StatusConflict, "duplicate_quote_template", "quote template name already exists")
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_template_update_failed", "failed to update quote template")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": quotePDFTemplateDTO(row)})
+}
+
+// RenderPDF returns the quote as a PDF. Every rendered document is stored as a new
+// version; when the latest version was rendered from the same quote and template
+// versions it is returned as-is unless regenerate=true.
+func (h QuoteDocumentHandler) RenderPDF(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	var templateID *uuid.UUID
+	if raw := r.URL.Query().Get("templateId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_template_id", "templateId must be UUID")
+			return
+		}
+		templateID = &id
+	}
+	regenerate := r.URL.Query().Get("regenerate") == "true"
+
+	var document dbgen.QuoteDocument
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		quote, queryErr := q.GetQuoteByIDForUpdate(r.Context(), dbgen.GetQuoteByIDForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(quote.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		template, queryErr := quotePDFTemplate(r.Context(), q, tenantID, templateID)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		if !regenerate {
+			latest, latestErr := q.GetLatestQuoteDocument(r.Context(), dbgen.GetLatestQuoteDocumentParams{
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  quote.ID,
+			})
+			if latestErr == nil && latest.QuoteVersion == quote.Version && latest.TemplateID == template.ID &&
+				(!template.ID.Valid || latest.TemplateVersion.Int64 == template.Version) {
+				document = latest
+				return nil
+			}
+			if latestErr != nil && !errors.Is(latestErr, pgx.ErrNoRows) {
+				return latestErr
+			}
+		}
+
+		content, queryErr := h.renderQuote(r.Context(), q, tenantID, quote, template)
+		if queryErr != nil {
+			return queryErr
+		}
+		sum := sha256.Sum256(content)
+		params := dbgen.CreateQuoteDocumentParams{
+			TenantID:     toPGUUID(tenantID),
+			QuoteID:      quote.ID,
+			QuoteVersion: quote.Version,
+			TemplateID:   template.ID,
+			FileName:     quote.QuoteNo + ".pdf",
+			Content:      content,
+			SizeBytes:    int32(len(content)),
+			Sha256:       hex.EncodeToString(sum[:]),
+			CreatedBy:    toPGUUID(actorID),
+		}
+		if template.ID.Valid {
+			params.TemplateVersion = pgtype.Int8{Int64: template.Version, Valid: true}
+		}
+		document, queryErr = q.CreateQuoteDocument(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "quote", quoteID, map[string]any{
+			"event":           "quote_pdf_generated",
+			"documentVersion": document.Version,
+			"templateId":      pgUUIDToString(template.ID),
+			"sha256":          document.Sha256,
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case errors.Is(err, errQuotePDFTemplateNotFound):
+			writeError(w, http.StatusNotFound, "template_not_found", err.Error())
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", "not allowed to access this quote")
+		case errors.Is(err, quotepdf.ErrFontUnavailable):
+			writeError(w, http.StatusServiceUnavailable, "pdf_unavailable", "pdf rendering is not configured")
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_pdf_failed", "failed to render quote pdf")
+		}
+		return
+	}
+
+	writePDF(w, document)
+}
+
+func (h QuoteDocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.ListQuoteDocumentsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := authorizeQuote(r.Context(), q, tenantID, actorID, quoteID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListQuoteDocuments(r.Context(), dbgen.ListQuoteDocumentsParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", "not allowed to access this quote")
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_document_list_failed", "failed to list quote documents")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		var templateVersion any
+		if row.TemplateVersion.Valid {
+			templateVersion = row.TemplateVersion.Int64
+		}
+		data = append(data, map[string]any{
+			"id":              pgUUIDToString(row.ID),
+			"quoteId":         pgUUIDToString(row.QuoteID),
+			"version":         row.Version,
+			"quoteVersion":    row.QuoteVersion,
+			"templateId":      pgUUIDToString(row.TemplateID),
+			"templateVersion": templateVersion,
+			"fileName":        row.FileName,
+			"sizeBytes":       row.SizeBytes,
+			"sha256":          row.Sha256,
+			"createdBy":       pgUUIDToString(row.CreatedBy),
+			"createdAt":       pgTimestampToString(row.CreatedAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// DownloadDocument returns a stored PDF version exactly as it was generated.
+func (h QuoteDocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		writeError(w, http.StatusBadRequest, "invalid_version", "version must be a positive integer")
+		return
+	}
+
+	var document dbgen.QuoteDocument
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := authorizeQuote(r.Context(), q, tenantID, actorID, quoteID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		document, queryErr = q.GetQuoteDocument(r.Context(), dbgen.GetQuoteDocumentParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+			Version:  int32(version),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote document not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", "not allowed to access this quote")
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_document_get_failed", "failed to load quote document")
+		}
+		return
+	}
+
+	writePDF(w, document)
+}
+
+// quotePDFTemplate resolves the requested template, else the tenant default. A tenant
+// without templates gets a zero template; renderQuote then prints the tenant name.
+func quotePDFTemplate(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, templateID *uuid.UUID) (dbgen.QuotePdfTemplate, error) {
+	if templateID != nil {
+		template, err := q.GetQuotePDFTemplate(ctx, dbgen.GetQuotePDFTemplateParams{
+			TenantID:   toPGUUID(tenantID),
+			TemplateID: toPGUUID(*templateID),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return template, errQuotePDFTemplateNotFound
+		}
+		return template, err
+	}
+	template, err := q.GetDefaultQuotePDFTemplate(ctx, toPGUUID(tenantID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dbgen.QuotePdfTemplate{}, nil
+	}
+	return template, err
+}
+
+func (h QuoteDocumentHandler) renderQuote(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, quote dbgen.Quote, template dbgen.QuotePdfTemplate) ([]byte, error) {
+	pdfContext, err := q.GetQuotePDFContext(ctx, dbgen.GetQuotePDFContextParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quote.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items, err := q.ListLineItems(ctx, dbgen.ListLineItemsParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  quote.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var breakdown []struct {
+		TaxRate       float64 `json:"taxRate"`
+		TaxableAmount float64 `json:"taxableAmount"`
+		TaxAmount     float64 `json:"taxAmount"`
+	}
+	if err := json.Unmarshal(quote.TaxBreakdown, &breakdown); err != nil {
+		return nil, fmt.Errorf("decode tax breakdown: %w", err)
+	}
+
+	doc := quotepdf.Document{
+		Language:    template.Language,
+		AccentColor: template.AccentColor,
+		Letterhead: quotepdf.Letterhead{
+			CompanyName:        template.CompanyName,
+			Address:            pgTextToString(template.CompanyAddress),
+			Phone:              pgTextToString(template.CompanyPhone),
+			RegistrationNumber: pgTextToString(template.RegistrationNumber),
+		},
+		FooterText: pgTextToString(template.FooterText),
+		QuoteNo:    quote.QuoteNo,
+		Subject:    pdfContext.OpportunityName,
+		IssuedOn:   quote.IssuedOn.Time,
+		ValidUntil: quote.ValidUntil.Time,
+		Customer: quotepdf.Customer{
+			Name:         pdfContext.AccountName,
+			LocationName: pdfContext.LocationName,
+			Country:      pgTextToString(pdfContext.Country),
+			PostalCode:   pgTextToString(pdfContext.PostalCode),
+			Prefecture:   pgTextToString(pdfContext.Prefecture),
+			City:         pgTextToString(pdfContext.City),
+			AddressLine1: pgTextToString(pdfContext.AddressLine1),
+			AddressLine2: pgTextToString(pdfContext.AddressLine2),
+		},
+		Currency:    quote.Currency,
+		Subtotal:    pgNumericToFloat(quote.Amount),
+		TaxAmount:   pgNumericToFloat(quote.TaxAmount),
+		Total:       pgNumericToFloat(quote.TotalAmount),
+		Notes:       pgTextToString(quote.Note),
+		GeneratedAt: time.Now().UTC(),
+	}
+	if !template.ID.Valid {
+		doc.Language = "ja"
+		doc.AccentColor = defaultAccentColor
+		doc.Letterhead.CompanyName = pdfContext.TenantName
+	}
+	for _, item := range items {
+		doc.Lines = append(doc.Lines, quotepdf.Line{
+			Name:            item.ProductName,
+			Description:     pgTextToString(item.Description),
+			Quantity:        pgNumericToFloat(item.Quantity),
+			UnitPrice:       pgNumericToFloat(item.UnitPrice),
+			DiscountPercent: pgNumericToFloat(item.DiscountPercent),
+			TaxRate:         pgNumericToFloat(item.TaxRate),
+			Amount:          pgNumericToFloat(item.LineTotal),
+		})
+	}
+	for _, line := range breakdown {
+		doc.TaxLines = append(doc.TaxLines, quotepdf.TaxLine{
+			TaxRate:       line.TaxRate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+	}
+	return h.Renderer.Render(doc)
+}
+
+func authorizeQuote(ctx context.Context, q *dbgen.Queries, tenantID, actorID, quoteID uuid.UUID) error {
+	quote, err := q.GetQuote(ctx, dbgen.GetQuoteParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  toPGUUID(quoteID),
+	})
+	if err != nil {
+		return err
+	}
+	return loadAuthorizedOpportunity(ctx, q, tenantID, actorID, uuid.UUID(quote.OpportunityID.Bytes))
+}
+
+func writePDF(w http.ResponseWriter, document dbgen.QuoteDocument) {
+	name := strings.TrimSuffix(document.FileName, ".pdf")
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fmt.Sprintf("%s_v%d.pdf", name, document.Version)))
+	w.Header().Set("Content-Length", strconv.Itoa(len(document.Content)))
+	w.Header().Set("X-Document-Version", strconv.Itoa(int(document.Version)))
+	w.Header().Set("X-Content-SHA256", document.Sha256)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(document.Content)
+}
+
+func quotePDFTemplateDTO(row dbgen.QuotePdfTemplate) map[string]any {
+	return map[string]any{
+		"id":                 pgUUIDToString(row.ID),
+		"name":               row.Name,
+		"language":           row.Language,
+		"companyName":        row.CompanyName,
+		"companyAddress":     pgTextToString(row.CompanyAddress),
+		"companyPhone":       pgTextToString(row.CompanyPhone),
+		"registrationNumber": pgTextToString(row.RegistrationNumber),
+		"accentColor":        row.AccentColor,
+		"footerText":         pgTextToString(row.FooterText),
+		"isDefault":          row.IsDefault,
+		"version":            row.Version,
+		"createdBy":          pgUUIDToString(row.CreatedBy),
+		"createdAt":          pgTimestampToString(row.CreatedAt),
+		"updatedAt":          pgTimestampToString(row.UpdatedAt),
+	}
+}
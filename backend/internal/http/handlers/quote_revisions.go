@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errQuoteSuperseded = errors.New("quote has been superseded; revise the latest revision")
+var errQuoteNotRevisable = errors.New("draft quotes are edited directly, not revised")
+var errQuotesNotRelated = errors.New("quotes are not revisions of the same quote")
+var errNoPreviousRevision = errors.New("quote has no previous revision; pass against")
+
+// Revise clones a sent, accepted, rejected or expired quote into a new draft revision
+// numbered <root quote no>-R<n> and supersedes the source, so only the latest revision of
+// a chain can be sent, accepted or used to close the opportunity.
+func (h QuoteHandler) Revise(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		Note   *string `json:"note"`
+		Reason string  `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	var row, superseded dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		// Lock the opportunity before the quote, the same order close-won uses.
+		source, queryErr := q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: source.OpportunityID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		source, queryErr = q.GetQuoteByIDForUpdate(r.Context(), dbgen.GetQuoteByIDForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  source.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, source.Version); queryErr != nil {
+			return queryErr
+		}
+		if opportunity.Stage == dbgen.OpportunityStageEnumClosedWon || opportunity.Stage == dbgen.OpportunityStageEnumClosedLost {
+			return errOpportunityNotOpen
+		}
+		switch source.Status {
+		case dbgen.QuoteStatusEnumSuperseded:
+			return errQuoteSuperseded
+		case dbgen.QuoteStatusEnumDraft:
+			return errQuoteNotRevisable
+		}
+
+		// The root row carries the base number; the opportunity lock above already
+		// serializes revisions of the same chain.
+		root := source
+		if source.RootQuoteID.Valid {
+			root, queryErr = q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  source.RootQuoteID,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+		}
+		latest, queryErr := q.GetMaxQuoteRevision(r.Context(), dbgen.GetMaxQuoteRevisionParams{
+			TenantID:    toPGUUID(tenantID),
+			RootQuoteID: root.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		revision := latest + 1
+
+		params := dbgen.CreateQuoteRevisionParams{
+			QuoteNo:       fmt.Sprintf("%s-R%d", root.QuoteNo, revision),
+			CreatedBy:     toPGUUID(actorID),
+			Revision:      revision,
+			TenantID:      toPGUUID(tenantID),
+			SourceQuoteID: source.ID,
+		}
+		if req.Note != nil {
+			params.Note = toPGText(*req.Note)
+		}
+		row, queryErr = q.CreateQuoteRevision(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.CopyQuoteLineItemsToQuote(r.Context(), dbgen.CopyQuoteLineItemsToQuoteParams{
+			TargetQuoteID: row.ID,
+			TenantID:      toPGUUID(tenantID),
+			SourceQuoteID: source.ID,
+		}); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.RecalculateQuoteTotals(r.Context(), dbgen.RecalculateQuoteTotalsParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  row.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		superseded, queryErr = q.SupersedeQuote(r.Context(), dbgen.SupersedeQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  source.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: opportunity.ID,
+		}); queryErr != nil {
+			return queryErr
+		}
+
+		metadata := map[string]any{
+			"event":           "quote_revised",
+			"opportunityId":   pgUUIDToString(opportunity.ID),
+			"quoteNo":         row.QuoteNo,
+			"revision":        row.Revision,
+			"previousQuoteId": quoteID.String(),
+		}
+		if req.Reason != "" {
+			metadata["reason"] = req.Reason
+		}
+		if queryErr := writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "quote", uuid.UUID(row.ID.Bytes), metadata); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", quoteID, map[string]any{
+			"event":        "status_changed",
+			"fromStatus":   string(source.Status),
+			"toStatus":     string(dbgen.QuoteStatusEnumSuperseded),
+			"quoteNo":      source.QuoteNo,
+			"supersededBy": pgUUIDToString(row.ID),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errOpportunityNotOpen):
+			writeError(w, http.StatusConflict, "opportunity_not_open", err.Error())
+		case errors.Is(err, errQuoteSuperseded):
+			writeError(w, http.StatusConflict, "quote_superseded", err.Error())
+		case errors.Is(err, errQuoteNotRevisable):
+			writeError(w, http.StatusConflict, "quote_not_revisable", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_revise_failed", "failed to revise quote")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"data": quoteDTO(row),
+		"meta": map[string]any{"superseded": quoteDTO(superseded)},
+	})
+}
+
+// Revisions returns the whole chain the quote belongs to, oldest revision first.
+func (h QuoteHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		quote, queryErr := q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(quote.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListQuoteRevisions(r.Context(), dbgen.ListQuoteRevisionsParams{
+			TenantID:    toPGUUID(tenantID),
+			RootQuoteID: quoteChainID(quote),
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_revisions_failed", "failed to load quote revisions")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, quoteDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// Diff compares the quote with another revision of the same chain (default: the revision
+// it replaced). Line items are matched by product, in sort order for repeated products.
+func (h QuoteHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	var againstID *uuid.UUID
+	if raw := r.URL.Query().Get("against"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_against", "against must be UUID")
+			return
+		}
+		againstID = &id
+	}
+
+	var base, target dbgen.Quote
+	var baseItems, targetItems []dbgen.ListLineItemsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		target, queryErr = q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(target.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		baseID := target.PreviousQuoteID
+		if againstID != nil {
+			baseID = toPGUUID(*againstID)
+		}
+		if !baseID.Valid {
+			return errNoPreviousRevision
+		}
+		base, queryErr = q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  baseID,
+		})
+		if errors.Is(queryErr, pgx.ErrNoRows) {
+			return errQuotesNotRelated
+		}
+		if queryErr != nil {
+			return queryErr
+		}
+		if quoteChainID(base) != quoteChainID(target) {
+			return errQuotesNotRelated
+		}
+		baseItems, queryErr = q.ListLineItems(r.Context(), dbgen.ListLineItemsParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  base.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		targetItems, queryErr = q.ListLineItems(r.Context(), dbgen.ListLineItemsParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  target.ID,
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errNoPreviousRevision):
+			writeError(w, http.StatusBadRequest, "no_previous_revision", err.Error())
+		case errors.Is(err, errQuotesNotRelated):
+			writeError(w, http.StatusBadRequest, "quotes_not_related", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_diff_failed", "failed to compare quotes")
+		}
+		return
+	}
+
+	lines, summary := diffQuoteLines(baseItems, targetItems)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"base":    quoteRevisionRef(base),
+			"target":  quoteRevisionRef(target),
+			"header":  diffQuoteHeader(base, target),
+			"lines":   lines,
+			"summary": summary,
+		},
+	})
+}
+
+// quoteChainID is the ID shared by every revision of a quote: the original quote's ID.
+func quoteChainID(quote dbgen.Quote) pgtype.UUID {
+	if quote.RootQuoteID.Valid {
+		return quote.RootQuoteID
+	}
+	return quote.ID
+}
+
+func quoteRevisionRef(quote dbgen.Quote) map[string]any {
+	return map[string]any{
+		"id":       pgUUIDToString(quote.ID),
+		"quoteNo":  quote.QuoteNo,
+		"revision": quote.Revision,
+		"status":   string(quote.Status),
+	}
+}
+
+func diffQuoteHeader(base, target dbgen.Quote) []map[string]any {
+	fields := []struct {
+		name     string
+		from, to any
+	}{
+		{"amount", pgNumericToFloat(base.Amount), pgNumericToFloat(target.Amount)},
+		{"taxAmount", pgNumericToFloat(base.TaxAmount), pgNumericToFloat(target.TaxAmount)},
+		{"totalAmount", pgNumericToFloat(base.TotalAmount), pgNumericToFloat(target.TotalAmount)},
+		{"issuedOn", pgDateToString(base.IssuedOn), pgDateToString(target.IssuedOn)},
+		{"validUntil", pgDateToString(base.ValidUntil), pgDateToString(target.ValidUntil)},
+		{"note", pgTextToString(base.Note), pgTextToString(target.Note)},
+	}
+	changes := []map[string]any{}
+	for _, field := range fields {
+		if field.from != field.to {
+			changes = append(changes, map[string]any{"field": field.name, "from": field.from, "to": field.to})
+		}
+	}
+	return changes
+}
+
+func diffQuoteLines(base, target []dbgen.ListLineItemsRow) ([]map[string]any, map[string]int) {
+	type lineKey struct {
+		productID  [16]byte
+		occurrence int
+	}
+	keyed := func(items []dbgen.ListLineItemsRow) ([]lineKey, map[lineKey]dbgen.ListLineItemsRow) {
+		seen := map[[16]byte]int{}
+		keys := make([]lineKey, 0, len(items))
+		byKey := make(map[lineKey]dbgen.ListLineItemsRow, len(items))
+		for _, item := range items {
+			key := lineKey{productID: item.ProductID.Bytes, occurrence: seen[item.ProductID.Bytes]}
+			seen[item.ProductID.Bytes]++
+			keys = append(keys, key)
+			byKey[key] = item
+		}
+		return keys, byKey
+	}
+	baseKeys, baseByKey := keyed(base)
+	targetKeys, targetByKey := keyed(target)
+
+	summary := map[string]int{"added": 0, "removed": 0, "changed": 0, "unchanged": 0}
+	lines := []map[string]any{}
+	for _, key := range targetKeys {
+		item := targetByKey[key]
+		previous, ok := baseByKey[key]
+		if !ok {
+			summary["added"]++
+			lines = append(lines, quoteLineDiff("added", item, nil, &item, nil))
+			continue
+		}
+		changed := quoteLineChanges(previous, item)
+		status := "unchanged"
+		if len(changed) > 0 {
+			status = "changed"
+		}
+		summary[status]++
+		lines = append(lines, quoteLineDiff(status, item, &previous, &item, changed))
+	}
+	for _, key := range baseKeys {
+		if _, ok := targetByKey[key]; ok {
+			continue
+		}
+		item := baseByKey[key]
+		summary["removed"]++
+		lines = append(lines, quoteLineDiff("removed", item, &item, nil, nil))
+	}
+	return lines, summary
+}
+
+func quoteLineChanges(from, to dbgen.ListLineItemsRow) []string {
+	changed := []string{}
+	if pgTextToString(from.Description) != pgTextToString(to.Description) {
+		changed = append(changed, "description")
+	}
+	if pgNumericToFloat(from.Quantity) != pgNumericToFloat(to.Quantity) {
+		changed = append(changed, "quantity")
+	}
+	if pgNumericToFloat(from.UnitPrice) != pgNumericToFloat(to.UnitPrice) {
+		changed = append(changed, "unitPrice")
+	}
+	if pgNumericToFloat(from.DiscountPercent) != pgNumericToFloat(to.DiscountPercent) {
+		changed = append(changed, "discountPercent")
+	}
+	if pgNumericToFloat(from.TaxRate) != pgNumericToFloat(to.TaxRate) {
+		changed = append(changed, "taxRate")
+	}
+	if pgNumericToFloat(from.LineTotal) != pgNumericToFloat(to.LineTotal) {
+		changed = append(changed, "lineTotal")
+	}
+	return changed
+}
+
+func quoteLineDiff(status string, item dbgen.ListLineItemsRow, base, target *dbgen.ListLineItemsRow, changed []string) map[string]any {
+	side := func(row *dbgen.ListLineItemsRow) any {
+		if row == nil {
+			return nil
+		}
+		return map[string]any{
+			"lineItemId":      pgUUIDToString(row.ID),
+			"description":     pgTextToString(row.Description),
+			"quantity":        pgNumericToFloat(row.Quantity),
+			"unitPrice":       pgNumericToFloat(row.UnitPrice),
+			"discountPercent": pgNumericToFloat(row.DiscountPercent),
+			"taxRate":         pgNumericToFloat(row.TaxRate),
+			"lineTotal":       pgNumericToFloat(row.LineTotal),
+		}
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	return map[string]any{
+		"status":        status,
+		"productId":     pgUUIDToString(item.ProductID),
+		"sku":           item.Sku,
+		"productName":   item.ProductName,
+		"base":          side(base),
+		"target":        side(target),
+		"changedFields": changed,
+	}
+}
@@ -0,0 +1,578 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+var errQuoteNotEditable = errors.New("only draft quotes can be edited")
+var errQuoteValidity = errors.New("validUntil must not be before issuedOn")
+var errQuoteEmpty = errors.New("quote has no line items")
+var errInvalidQuoteTransition = errors.New("quote status transition is not allowed")
+var errInvalidTaxRate = errors.New("taxRate must be 10, 8 or 0")
+
+// quoteTransitions lists the statuses each status may move to by hand. Closing an
+// opportunity as won accepts and rejects quotes on its own.
+var quoteTransitions = map[dbgen.QuoteStatusEnum][]dbgen.QuoteStatusEnum{
+	dbgen.QuoteStatusEnumDraft: {dbgen.QuoteStatusEnumSent},
+	dbgen.QuoteStatusEnumSent:  {dbgen.QuoteStatusEnumAccepted, dbgen.QuoteStatusEnumRejected, dbgen.QuoteStatusEnumExpired},
+}
+
+type QuoteHandler struct {
+	Store *store.Store
+}
+
+func NewQuoteHandler(store *store.Store) QuoteHandler {
+	return QuoteHandler{Store: store}
+}
+
+func (h QuoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, opportunityID); queryErr != nil {
+			return queryErr
+		}
+		var queryErr error
+		rows, queryErr = q.ListQuotesByOpportunity(r.Context(), dbgen.ListQuotesByOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+			LatestOnly:    r.URL.Query().Get("latest") == "true",
+		})
+		return queryErr
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_list_failed", "failed to load quotes")
+		}
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, quoteDTO(row))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// Create opens a draft quote numbered from the tenant's quote sequence. The opportunity's
+// line items are copied unless copyLineItems is false.
+func (h QuoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var req struct {
+		IssuedOn      string `json:"issuedOn"`
+		ValidUntil    string `json:"validUntil"`
+		Note          string `json:"note"`
+		CopyLineItems *bool  `json:"copyLineItems"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	issuedOn, err := parseOptionalDate(req.IssuedOn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_issued_on", "issuedOn must be YYYY-MM-DD")
+		return
+	}
+	validUntil, err := parseOptionalDate(req.ValidUntil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_valid_until", "validUntil must be YYYY-MM-DD")
+		return
+	}
+	if issuedOn.Valid && validUntil.Valid && validUntil.Time.Before(issuedOn.Time) {
+		writeError(w, http.StatusBadRequest, "invalid_valid_until", "validUntil must not be before issuedOn")
+		return
+	}
+	copyLineItems := req.CopyLineItems == nil || *req.CopyLineItems
+
+	var row dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		opportunity, queryErr := q.GetOpportunityForUpdate(r.Context(), dbgen.GetOpportunityForUpdateParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		if opportunity.Stage == dbgen.OpportunityStageEnumClosedWon || opportunity.Stage == dbgen.OpportunityStageEnumClosedLost {
+			return errOpportunityNotOpen
+		}
+
+		quoteNo, queryErr := nextDocumentNumber(r.Context(), q, tenantID, documentTypeQuote, time.Now())
+		if queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.CreateQuote(r.Context(), dbgen.CreateQuoteParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: opportunity.ID,
+			QuoteNo:       quoteNo,
+			Amount:        toPGNumeric(0),
+			Currency:      opportunity.Currency,
+			IssuedOn:      issuedOn,
+			ValidUntil:    validUntil,
+			Note:          toPGText(req.Note),
+			CreatedBy:     toPGUUID(actorID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if copyLineItems {
+			if _, queryErr := q.CopyOpportunityLineItemsToQuote(r.Context(), dbgen.CopyOpportunityLineItemsToQuoteParams{
+				QuoteID:       row.ID,
+				TenantID:      toPGUUID(tenantID),
+				OpportunityID: opportunity.ID,
+			}); queryErr != nil {
+				return queryErr
+			}
+		}
+		row, queryErr = q.RecalculateQuoteTotals(r.Context(), dbgen.RecalculateQuoteTotalsParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  row.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if _, queryErr := q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: opportunity.ID,
+		}); queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumCreate, "quote", uuid.UUID(row.ID.Bytes), map[string]any{
+			"opportunityId": opportunityID.String(),
+			"quoteNo":       row.QuoteNo,
+			"amount":        pgNumericToFloat(row.Amount),
+			"totalAmount":   pgNumericToFloat(row.TotalAmount),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errOpportunityNotOpen):
+			writeError(w, http.StatusConflict, "opportunity_not_open", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_create_failed", "failed to create quote")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusCreated, map[string]any{"data": quoteDTO(row)})
+}
+
+func (h QuoteHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+
+	var row dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		row, queryErr = q.GetQuote(r.Context(), dbgen.GetQuoteParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		return loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(row.OpportunityID.Bytes))
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_get_failed", "failed to load quote")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": quoteDTO(row)})
+}
+
+// Update edits the header of a draft quote. Amount, tax and currency follow the line
+// items and are not accepted here.
+func (h QuoteHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		IssuedOn   *string `json:"issuedOn"`
+		ValidUntil *string `json:"validUntil"`
+		Note       *string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+
+	params := dbgen.UpdateQuoteParams{
+		TenantID: toPGUUID(tenantID),
+		QuoteID:  toPGUUID(quoteID),
+	}
+	if req.IssuedOn != nil {
+		params.IssuedOn, err = parseOptionalDate(*req.IssuedOn)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_issued_on", "issuedOn must be YYYY-MM-DD")
+			return
+		}
+	}
+	if req.ValidUntil != nil {
+		params.ValidUntil, err = parseOptionalDate(*req.ValidUntil)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_valid_until", "validUntil must be YYYY-MM-DD")
+			return
+		}
+	}
+	if req.Note != nil {
+		params.Note = pgtype.Text{String: *req.Note, Valid: true}
+	}
+
+	var row dbgen.Quote
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetQuoteByIDForUpdate(r.Context(), dbgen.GetQuoteByIDForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if current.Status != dbgen.QuoteStatusEnumDraft {
+			return errQuoteNotEditable
+		}
+		issuedOn, validUntil := current.IssuedOn, current.ValidUntil
+		if params.IssuedOn.Valid {
+			issuedOn = params.IssuedOn
+		}
+		if params.ValidUntil.Valid {
+			validUntil = params.ValidUntil
+		}
+		if issuedOn.Valid && validUntil.Valid && validUntil.Time.Before(issuedOn.Time) {
+			return errQuoteValidity
+		}
+		// Invalidate first: it may clear approved_for_send_at, and the returned row must
+		// carry the final version.
+		if queryErr := invalidateQuoteApprovals(r.Context(), q, tenantID, current.ID); queryErr != nil {
+			return queryErr
+		}
+		row, queryErr = q.UpdateQuote(r.Context(), params)
+		if queryErr != nil {
+			return queryErr
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", quoteID, map[string]any{
+			"event":      "header_updated",
+			"quoteNo":    row.QuoteNo,
+			"issuedOn":   pgDateToString(row.IssuedOn),
+			"validUntil": pgDateToString(row.ValidUntil),
+			"note":       pgTextToString(row.Note),
+		})
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errQuoteValidity):
+			writeError(w, http.StatusBadRequest, "invalid_valid_until", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errQuoteNotEditable):
+			writeError(w, http.StatusConflict, "quote_not_editable", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_update_failed", "failed to update quote")
+		}
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": quoteDTO(row)})
+}
+
+// Transition moves a quote along draft -> sent -> accepted | rejected | expired. Sending
+// requires at least one line item and stamps issuedOn and validUntil when unset. A quote
+// that matches discount policies is only sent once every matched policy is approved;
+// otherwise the missing approval requests are raised and the response is 409.
+func (h QuoteHandler) Transition(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+	quoteID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_quote_id", "id must be UUID")
+		return
+	}
+	expectedVersion, conditional, err := ifMatchVersion(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_if_match", err.Error())
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	target, err := parseQuoteStatus(req.Status)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_status", err.Error())
+		return
+	}
+
+	var row dbgen.Quote
+	var pendingChecks []policyCheck
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		if queryErr := setChangeContext(r.Context(), q, r, dbgen.ChangeSourceEnumApi); queryErr != nil {
+			return queryErr
+		}
+		current, queryErr := q.GetQuoteByIDForUpdate(r.Context(), dbgen.GetQuoteByIDForUpdateParams{
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  toPGUUID(quoteID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := loadAuthorizedOpportunity(r.Context(), q, tenantID, actorID, uuid.UUID(current.OpportunityID.Bytes)); queryErr != nil {
+			return queryErr
+		}
+		if queryErr := checkIfMatch(expectedVersion, conditional, current.Version); queryErr != nil {
+			return queryErr
+		}
+		if !quoteTransitionAllowed(current.Status, target) {
+			return errInvalidQuoteTransition
+		}
+		if target == dbgen.QuoteStatusEnumSent {
+			count, queryErr := q.CountQuoteLineItems(r.Context(), dbgen.CountQuoteLineItemsParams{
+				TenantID: toPGUUID(tenantID),
+				QuoteID:  current.ID,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			if count == 0 {
+				return errQuoteEmpty
+			}
+			approved, checks, queryErr := requireDiscountApprovals(r.Context(), q, r, tenantID, actorID, current)
+			if queryErr != nil {
+				return queryErr
+			}
+			if !approved {
+				// Commit the approval requests just raised; the quote stays a draft.
+				pendingChecks = checks
+				return nil
+			}
+		}
+		row, queryErr = q.TransitionQuoteStatus(r.Context(), dbgen.TransitionQuoteStatusParams{
+			Status:   target,
+			TenantID: toPGUUID(tenantID),
+			QuoteID:  current.ID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		// Rejected and expired quotes stop counting toward the deal; accepted ones take over.
+		if _, queryErr := q.RecalculateOpportunityAmount(r.Context(), dbgen.RecalculateOpportunityAmountParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: row.OpportunityID,
+		}); queryErr != nil {
+			return queryErr
+		}
+		metadata := map[string]any{
+			"event":      "status_changed",
+			"fromStatus": string(current.Status),
+			"toStatus":   string(target),
+			"quoteNo":    row.QuoteNo,
+		}
+		if req.Reason != "" {
+			metadata["reason"] = req.Reason
+		}
+		return writeAuditLog(r.Context(), q, r, tenantID, actorID, dbgen.AuditActionEnumUpdate, "quote", quoteID, metadata)
+	}); err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			writeError(w, http.StatusNotFound, "not_found", "quote not found")
+		case isAccessDenied(err):
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+		case errors.Is(err, errPreconditionFailed):
+			writeError(w, http.StatusPreconditionFailed, "precondition_failed", err.Error())
+		case errors.Is(err, errInvalidQuoteTransition):
+			writeError(w, http.StatusConflict, "invalid_quote_transition", err.Error())
+		case errors.Is(err, errQuoteEmpty):
+			writeError(w, http.StatusConflict, "quote_empty", err.Error())
+		case errors.Is(err, errApprovalNoApprover):
+			writeError(w, http.StatusConflict, "approval_no_approver", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "quote_transition_failed", "failed to change quote status")
+		}
+		return
+	}
+	if pendingChecks != nil {
+		writeApprovalRequiredError(w, pendingChecks)
+		return
+	}
+
+	setETag(w, row.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"data": quoteDTO(row)})
+}
+
+func quoteTransitionAllowed(from, to dbgen.QuoteStatusEnum) bool {
+	for _, allowed := range quoteTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func parseQuoteStatus(raw string) (dbgen.QuoteStatusEnum, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "draft":
+		return dbgen.QuoteStatusEnumDraft, nil
+	case "sent":
+		return dbgen.QuoteStatusEnumSent, nil
+	case "accepted":
+		return dbgen.QuoteStatusEnumAccepted, nil
+	case "rejected":
+		return dbgen.QuoteStatusEnumRejected, nil
+	case "expired":
+		return dbgen.QuoteStatusEnumExpired, nil
+	default:
+		return "", errors.New("status must be draft, sent, accepted, rejected, or expired")
+	}
+}
+
+func validTaxRate(rate float64) bool {
+	return rate == 10 || rate == 8 || rate == 0
+}
+
+func quoteDTO(row dbgen.Quote) map[string]any {
+	taxBreakdown := []map[string]any{}
+	_ = json.Unmarshal(row.TaxBreakdown, &taxBreakdown)
+	return map[string]any{
+		"id":                pgUUIDToString(row.ID),
+		"opportunityId":     pgUUIDToString(row.OpportunityID),
+		"quoteNo":           row.QuoteNo,
+		"amount":            pgNumericToFloat(row.Amount),
+		"taxAmount":         pgNumericToFloat(row.TaxAmount),
+		"totalAmount":       pgNumericToFloat(row.TotalAmount),
+		"taxBreakdown":      taxBreakdown,
+		"currency":          row.Currency,
+		"status":            string(row.Status),
+		"issuedOn":          pgDateToString(row.IssuedOn),
+		"validUntil":        pgDateToString(row.ValidUntil),
+		"note":              pgTextToString(row.Note),
+		"createdBy":         pgUUIDToString(row.CreatedBy),
+		"createdAt":         pgTimestampToString(row.CreatedAt),
+		"updatedAt":         pgTimestampToString(row.UpdatedAt),
+		"version":           row.Version,
+		"revision":          row.Revision,
+		"rootQuoteId":       pgUUIDToString(row.RootQuoteID),
+		"previousQuoteId":   pgUUIDToString(row.PreviousQuoteID),
+		"supersededAt":      pgTimestampToString(row.SupersededAt),
+		"expiredAt":         pgTimestampToString(row.ExpiredAt),
+		"approvedForSendAt": pgTimestampToString(row.ApprovedForSendAt),
+	}
+}
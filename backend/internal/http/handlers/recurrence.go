@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+var errRecurrenceNeedsDueAt = errors.New("recurring tasks need a dueAt")
+
+// recurrenceRule is the supported RRULE subset: FREQ=DAILY|WEEKLY|MONTHLY with optional
+// INTERVAL and at most one of COUNT or UNTIL.
+type recurrenceRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+}
+
+func parseRecurrenceRule(raw string) (recurrenceRule, error) {
+	rule := recurrenceRule{Interval: 1}
+	raw = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(raw)), "RRULE:")
+	if raw == "" {
+		return rule, errors.New("recurrenceRule must not be empty")
+	}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return rule, fmt.Errorf("recurrenceRule: malformed part %q", part)
+		}
+		if seen[key] {
+			return rule, fmt.Errorf("recurrenceRule: %s is repeated", key)
+		}
+		seen[key] = true
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return rule, errors.New("recurrenceRule: FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 366 {
+				return rule, errors.New("recurrenceRule: INTERVAL must be between 1 and 366")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 1000 {
+				return rule, errors.New("recurrenceRule: COUNT must be between 1 and 1000")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = until
+		default:
+			return rule, fmt.Errorf("recurrenceRule: %s is not supported", key)
+		}
+	}
+	if rule.Freq == "" {
+		return rule, errors.New("recurrenceRule: FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, errors.New("recurrenceRule: COUNT and UNTIL cannot be combined")
+	}
+	return rule, nil
+}
+
+// parseRRuleUntil accepts YYYYMMDD (inclusive, end of day UTC) or YYYYMMDDTHHMMSSZ.
+func parseRRuleUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if d, err := time.Parse("20060102", value); err == nil {
+		return d.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, errors.New("recurrenceRule: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+func (rule recurrenceRule) String() string {
+	parts := []string{"FREQ=" + rule.Freq}
+	if rule.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rule.Interval))
+	}
+	if rule.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(rule.Count))
+	}
+	if !rule.Until.IsZero() {
+		parts = append(parts, "UNTIL="+rule.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// occurrence returns the due time of occurrence seq (1-based) counted from the anchor.
+// Monthly rules clamp to the last day of shorter months without drifting.
+func (rule recurrenceRule) occurrence(anchor time.Time, seq int) time.Time {
+	steps := (seq - 1) * rule.Interval
+	switch rule.Freq {
+	case "DAILY":
+		return anchor.AddDate(0, 0, steps)
+	case "WEEKLY":
+		return anchor.AddDate(0, 0, 7*steps)
+	default:
+		year, month, day := anchor.Date()
+		first := time.Date(year, month+time.Month(steps), 1, anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), anchor.Location())
+		if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+			day = lastDay
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+}
+
+// next returns occurrence seq+1, or false when COUNT or UNTIL ends the series.
+func (rule recurrenceRule) next(anchor time.Time, seq int) (time.Time, bool) {
+	if rule.Count > 0 && seq+1 > rule.Count {
+		return time.Time{}, false
+	}
+	due := rule.occurrence(anchor, seq+1)
+	if !rule.Until.IsZero() && due.After(rule.Until) {
+		return time.Time{}, false
+	}
+	return due, true
+}
+
+// createNextOccurrence schedules the task that follows a just-completed recurring task.
+// It returns nil when the series has ended or the next occurrence already exists.
+func createNextOccurrence(ctx context.Context, q *dbgen.Queries, completed dbgen.Activity, actorID uuid.UUID) (*dbgen.Activity, error) {
+	if !completed.RecurrenceRule.Valid || !completed.RecurrenceAnchor.Valid || !completed.RecurrenceSeq.Valid {
+		return nil, nil
+	}
+	rule, err := parseRecurrenceRule(completed.RecurrenceRule.String)
+	if err != nil {
+		return nil, err
+	}
+	seq := int(completed.RecurrenceSeq.Int32)
+	due, ok := rule.next(completed.RecurrenceAnchor.Time, seq)
+	if !ok {
+		return nil, nil
+	}
+	row, err := q.CreateNextOccurrence(ctx, dbgen.CreateNextOccurrenceParams{
+		TenantID:           completed.TenantID,
+		OpportunityID:      completed.OpportunityID,
+		Subject:            completed.Subject,
+		Detail:             completed.Detail,
+		DueAt:              toPGTimestamptz(due),
+		AssigneeUserID:     completed.AssigneeUserID,
+		Priority:           completed.Priority,
+		RecurrenceRule:     completed.RecurrenceRule,
+		RecurrenceSeriesID: completed.RecurrenceSeriesID,
+		RecurrenceAnchor:   completed.RecurrenceAnchor,
+		RecurrenceSeq:      pgtype.Int4{Int32: int32(seq + 1), Valid: true},
+		CreatedBy:          toPGUUID(actorID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
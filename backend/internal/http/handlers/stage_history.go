@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+)
+
+func (h OpportunityHandler) StageHistory(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+	actorID, err := userIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_user_id", err.Error())
+		return
+	}
+
+	opportunityID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_opportunity_id", "id must be UUID")
+		return
+	}
+
+	var rows []dbgen.OpportunityStageHistory
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		opportunity, queryErr := q.GetOpportunity(r.Context(), dbgen.GetOpportunityParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		if queryErr := authorizeOpportunity(r.Context(), q, tenantID, actorID, opportunity); queryErr != nil {
+			return queryErr
+		}
+		rows, queryErr = q.ListOpportunityStageHistory(r.Context(), dbgen.ListOpportunityStageHistoryParams{
+			TenantID:      toPGUUID(tenantID),
+			OpportunityID: toPGUUID(opportunityID),
+		})
+		return queryErr
+	}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "opportunity not found")
+			return
+		}
+		if isAccessDenied(err) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "stage_history_failed", "failed to load stage history")
+		return
+	}
+
+	data := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		fromStage := ""
+		if row.FromStage.Valid {
+			fromStage = string(row.FromStage.OpportunityStageEnum)
+		}
+		data = append(data, map[string]any{
+			"id":          row.ID,
+			"fromStage":   fromStage,
+			"toStage":     string(row.ToStage),
+			"amount":      pgNumericToFloat(row.Amount),
+			"probability": row.Probability,
+			"changedBy":   pgUUIDToString(row.ChangedBy),
+			"changedAt":   pgTimestampToString(row.ChangedAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+func (h FeaturePackHandler) StageVelocity(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenantIDFromHeader(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_tenant_id", err.Error())
+		return
+	}
+
+	rangeStart, rangeEnd, err := parseDateRange(r, 90)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_date_range", err.Error())
+		return
+	}
+	var ownerID pgtype.UUID
+	if raw := r.URL.Query().Get("ownerUserId"); raw != "" {
+		id, parseErr := parseUUID(raw)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_owner_user_id", "ownerUserId must be UUID")
+			return
+		}
+		ownerID = toPGUUID(id)
+	}
+
+	var durations []dbgen.GetStageDurationStatsRow
+	var conversions []dbgen.GetStageConversionStatsRow
+	var owners []dbgen.GetOwnerVelocityStatsRow
+	if err := h.Store.WithTenantTx(r.Context(), tenantID, func(q *dbgen.Queries) error {
+		var queryErr error
+		durations, queryErr = q.GetStageDurationStats(r.Context(), dbgen.GetStageDurationStatsParams{
+			TenantID:    toPGUUID(tenantID),
+			RangeStart:  rangeStart,
+			RangeEnd:    rangeEnd,
+			OwnerUserID: ownerID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		conversions, queryErr = q.GetStageConversionStats(r.Context(), dbgen.GetStageConversionStatsParams{
+			TenantID:    toPGUUID(tenantID),
+			RangeStart:  rangeStart,
+			RangeEnd:    rangeEnd,
+			OwnerUserID: ownerID,
+		})
+		if queryErr != nil {
+			return queryErr
+		}
+		owners, queryErr = q.GetOwnerVelocityStats(r.Context(), dbgen.GetOwnerVelocityStatsParams{
+			TenantID:    toPGUUID(tenantID),
+			RangeStart:  rangeStart,
+			RangeEnd:    rangeEnd,
+			OwnerUserID: ownerID,
+		})
+		return queryErr
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "stage_velocity_failed", "failed to load stage velocity")
+		return
+	}
+
+	stageData := make([]map[string]any, 0, len(durations))
+	for _, row := range durations {
+		stageData = append(stageData, map[string]any{
+			"stage":          string(row.Stage),
+			"completedCount": row.CompletedCount,
+			"openCount":      row.OpenCount,
+			"avgDays":        row.AvgDays,
+			"medianDays":     row.MedianDays,
+		})
+	}
+
+	conversionData := make([]map[string]any, 0, len(conversions))
+	for _, row := range conversions {
+		conversionData = append(conversionData, map[string]any{
+			"fromStage":       string(row.FromStage.OpportunityStageEnum),
+			"toStage":         string(row.ToStage),
+			"transitionCount": row.TransitionCount,
+			"conversionRate":  row.ConversionRate,
+		})
+	}
+
+	ownerData := make([]map[string]any, 0, len(owners))
+	for _, row := range owners {
+		winRate := 0.0
+		if row.ClosedCount > 0 {
+			winRate = float64(row.WonCount) / float64(row.ClosedCount)
+		}
+		// wonAmount leaves out deals without an FX rate, so average over the rest.
+		avgWonAmount := 0.0
+		if rated := row.WonCount - row.MissingRateCount; rated > 0 {
+			avgWonAmount = row.WonAmount / float64(rated)
+		}
+		// Sales velocity (closed deals x win rate x average deal size / cycle length)
+		// reduces to won amount per day of average sales cycle.
+		velocity := 0.0
+		if row.AvgCycleDays > 0 {
+			velocity = row.WonAmount / row.AvgCycleDays
+		}
+		ownerData = append(ownerData, map[string]any{
+			"ownerUserId":      pgUUIDToString(row.OwnerUserID),
+			"closedCount":      row.ClosedCount,
+			"wonCount":         row.WonCount,
+			"winRate":          winRate,
+			"wonAmount":        row.WonAmount,
+			"avgWonAmount":     avgWonAmount,
+			"avgCycleDays":     row.AvgCycleDays,
+			"velocityPerDay":   velocity,
+			"missingRateCount": row.MissingRateCount,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"stages":      stageData,
+			"conversions": conversionData,
+			"owners":      ownerData,
+		},
+		"meta": map[string]any{
+			"from": pgTimestampToString(rangeStart),
+			"to":   pgTimestampToString(rangeEnd),
+		},
+	})
+}
+
+// parseDateRange reads the from/to query parameters. to defaults to now and from to
+// defaultDays before to.
+func parseDateRange(r *http.Request, defaultDays int) (pgtype.Timestamptz, pgtype.Timestamptz, error) {
+	rangeEnd, err := parseOptionalTimestamp(r.URL.Query().Get("to"))
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.Timestamptz{}, errors.New("to must be RFC3339 or YYYY-MM-DD")
+	}
+	if !rangeEnd.Valid {
+		rangeEnd = toPGTimestamptz(time.Now().UTC())
+	}
+	rangeStart, err := parseOptionalTimestamp(r.URL.Query().Get("from"))
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.Timestamptz{}, errors.New("from must be RFC3339 or YYYY-MM-DD")
+	}
+	if !rangeStart.Valid {
+		rangeStart = toPGTimestamptz(rangeEnd.Time.AddDate(0, 0, -defaultDays))
+	}
+	if !rangeStart.Time.Before(rangeEnd.Time) {
+		return pgtype.Timestamptz{}, pgtype.Timestamptz{}, errors.New("from must be before to")
+	}
+	return rangeStart, rangeEnd, nil
+}
@@ -7,11 +7,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"sfa/backend/internal/config"
 	"sfa/backend/internal/http/handlers"
+	"sfa/backend/internal/quotepdf"
 	"sfa/backend/internal/store"
 )
 
-func NewRouter(store *store.Store) http.Handler {
+func NewRouter(store *store.Store, cfg config.Config) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
@@ -28,10 +30,15 @@ func NewRouter(store *store.Store) http.Handler {
 		// Endpoint placeholders aligned with api/openapi.yaml
 		registerAuthRoutes(api)
 		registerUserRoutes(api)
-		registerAccountRoutes(api)
-		registerOpportunityRoutes(api)
+		registerAccountRoutes(api, store)
+		registerOpportunityRoutes(api, store)
+		registerCatalogRoutes(api, store)
+		registerCompetitorRoutes(api, store)
+		registerCurrencyRoutes(api, store)
+		registerDocumentRoutes(api, store, quotepdf.NewRenderer(cfg.PDFFontPath))
 		registerDashboardRoutes(api, store)
-		registerAuditRoutes(api)
+		registerNotificationRoutes(api, store)
+		registerAuditRoutes(api, store)
 		registerFeaturePackRoutes(api, store)
 	})
 
@@ -55,11 +62,16 @@ func registerUserRoutes(r chi.Router) {
 	})
 }
 
-func registerAccountRoutes(r chi.Router) {
+func registerAccountRoutes(r chi.Router, store *store.Store) {
+	accountHandler := handlers.NewAccountHandler(store)
+
 	r.Route("/accounts", func(accounts chi.Router) {
 		accounts.Get("/", notImplemented)
 		accounts.Post("/", notImplemented)
-		accounts.Patch("/{id}", notImplemented)
+		accounts.Get("/{id}", accountHandler.Get)
+		accounts.Patch("/{id}", accountHandler.Update)
+		accounts.Get("/{id}/timeline", accountHandler.Timeline)
+		accounts.Get("/{id}/history", accountHandler.History)
 
 		accounts.Route("/{id}/contacts", func(contacts chi.Router) {
 			contacts.Get("/", notImplemented)
@@ -67,32 +79,152 @@ func registerAccountRoutes(r chi.Router) {
 		})
 
 		accounts.Route("/{id}/locations", func(locations chi.Router) {
-			locations.Get("/", notImplemented)
-			locations.Post("/", notImplemented)
+			locations.Get("/", accountHandler.ListLocations)
+			locations.Post("/", accountHandler.CreateLocation)
 		})
 	})
+
+	r.Get("/contacts/{id}", accountHandler.GetContact)
+	r.Patch("/contacts/{id}", accountHandler.UpdateContact)
+	r.Get("/contacts/{id}/history", accountHandler.ContactHistory)
 }
 
-func registerOpportunityRoutes(r chi.Router) {
+func registerOpportunityRoutes(r chi.Router, store *store.Store) {
+	opportunityHandler := handlers.NewOpportunityHandler(store)
+	quoteHandler := handlers.NewQuoteHandler(store)
+	orderHandler := handlers.NewOrderHandler(store)
+	activityHandler := handlers.NewActivityHandler(store)
+
 	r.Route("/opportunities", func(opps chi.Router) {
-		opps.Get("/", notImplemented)
-		opps.Post("/", notImplemented)
-		opps.Patch("/{id}", notImplemented)
+		opps.Get("/", opportunityHandler.List)
+		opps.Post("/", opportunityHandler.Create)
+		opps.Get("/{id}", opportunityHandler.Get)
+		opps.Patch("/{id}", opportunityHandler.Update)
+		opps.Get("/{id}/stage-history", opportunityHandler.StageHistory)
+		opps.Get("/{id}/history", opportunityHandler.History)
+		opps.Get("/{id}/team", opportunityHandler.Team)
+		opps.Put("/{id}/team", opportunityHandler.ReplaceTeam)
 
 		opps.Route("/{id}/activities", func(activities chi.Router) {
-			activities.Get("/", notImplemented)
-			activities.Post("/", notImplemented)
+			activities.Get("/", activityHandler.List)
+			activities.Post("/", activityHandler.Create)
+			activities.Post("/from-template", activityHandler.ApplyTemplate)
 		})
 		opps.Route("/{id}/quotes", func(quotes chi.Router) {
-			quotes.Get("/", notImplemented)
-			quotes.Post("/", notImplemented)
+			quotes.Get("/", quoteHandler.List)
+			quotes.Post("/", quoteHandler.Create)
 		})
 		opps.Route("/{id}/orders", func(orders chi.Router) {
-			orders.Get("/", notImplemented)
-			orders.Post("/", notImplemented)
+			orders.Get("/", orderHandler.ListByOpportunity)
+			orders.Post("/", orderHandler.Create)
 		})
-		opps.Post("/{id}/lost", notImplemented)
+		opps.Post("/{id}/lost", opportunityHandler.MarkLost)
+		opps.Post("/{id}/reopen", opportunityHandler.Reopen)
+		opps.Post("/{id}/close-won", opportunityHandler.CloseWon)
+	})
+
+	r.Patch("/activities/{id}", activityHandler.Update)
+	r.Delete("/activities/{id}", activityHandler.Delete)
+	r.Get("/tasks/mine", activityHandler.MyTasks)
+
+	r.Route("/activity-templates", func(templates chi.Router) {
+		templates.Get("/", activityHandler.ListTemplates)
+		templates.Post("/", activityHandler.CreateTemplate)
+		templates.Get("/{id}", activityHandler.GetTemplate)
+		templates.Patch("/{id}", activityHandler.UpdateTemplate)
+	})
+
+	r.Get("/quotes/{id}", quoteHandler.Get)
+	r.Patch("/quotes/{id}", quoteHandler.Update)
+	r.Post("/quotes/{id}/status", quoteHandler.Transition)
+	r.Get("/quotes/{id}/revisions", quoteHandler.Revisions)
+	r.Post("/quotes/{id}/revisions", quoteHandler.Revise)
+	r.Get("/quotes/{id}/diff", quoteHandler.Diff)
+	r.Get("/quotes/{id}/approvals", quoteHandler.Approvals)
+
+	r.Get("/orders", orderHandler.List)
+	r.Get("/orders/{id}", orderHandler.Get)
+	r.Post("/orders/{id}/status", orderHandler.Transition)
+	r.Put("/orders/{id}/subscription", orderHandler.SetSubscription)
+	r.Get("/orders/{id}/schedule", orderHandler.Schedule)
+}
+
+func registerCatalogRoutes(r chi.Router, store *store.Store) {
+	catalogHandler := handlers.NewCatalogHandler(store)
+
+	r.Route("/products", func(products chi.Router) {
+		products.Get("/", catalogHandler.ListProducts)
+		products.Post("/", catalogHandler.CreateProduct)
+		products.Get("/{id}", catalogHandler.GetProduct)
+		products.Patch("/{id}", catalogHandler.UpdateProduct)
+	})
+
+	r.Route("/price-books", func(books chi.Router) {
+		books.Get("/", catalogHandler.ListPriceBooks)
+		books.Post("/", catalogHandler.CreatePriceBook)
+		books.Patch("/{id}", catalogHandler.UpdatePriceBook)
+		books.Get("/{id}/entries", catalogHandler.ListPriceBookEntries)
+		books.Put("/{id}/entries/{productId}", catalogHandler.UpsertPriceBookEntry)
+		books.Delete("/{id}/entries/{productId}", catalogHandler.DeletePriceBookEntry)
 	})
+
+	r.Get("/opportunities/{id}/line-items", catalogHandler.OpportunityLineItems)
+	r.Put("/opportunities/{id}/line-items", catalogHandler.ReplaceOpportunityLineItems)
+	r.Get("/quotes/{id}/line-items", catalogHandler.QuoteLineItems)
+	r.Put("/quotes/{id}/line-items", catalogHandler.ReplaceQuoteLineItems)
+	r.Get("/orders/{id}/line-items", catalogHandler.OrderLineItems)
+	r.Put("/orders/{id}/line-items", catalogHandler.ReplaceOrderLineItems)
+
+	r.Get("/export/products.csv", catalogHandler.ExportProductsCSV)
+	r.Post("/import/products.csv", catalogHandler.ImportProductsCSV)
+}
+
+func registerCompetitorRoutes(r chi.Router, store *store.Store) {
+	competitorHandler := handlers.NewCompetitorHandler(store)
+
+	r.Route("/competitors", func(competitors chi.Router) {
+		competitors.Get("/", competitorHandler.List)
+		competitors.Post("/", competitorHandler.Create)
+		competitors.Patch("/{id}", competitorHandler.Update)
+	})
+
+	r.Get("/opportunities/{id}/competitors", competitorHandler.OpportunityCompetitors)
+	r.Put("/opportunities/{id}/competitors/{competitorId}", competitorHandler.UpsertOpportunityCompetitor)
+	r.Delete("/opportunities/{id}/competitors/{competitorId}", competitorHandler.DeleteOpportunityCompetitor)
+}
+
+func registerCurrencyRoutes(r chi.Router, store *store.Store) {
+	currencyHandler := handlers.NewCurrencyHandler(store)
+
+	r.Get("/settings/currency", currencyHandler.GetSettings)
+	r.Put("/settings/currency", currencyHandler.UpdateSettings)
+
+	r.Route("/fx-rates", func(rates chi.Router) {
+		rates.Get("/", currencyHandler.ListFxRates)
+		rates.Post("/", currencyHandler.UpsertFxRate)
+		rates.Delete("/{id}", currencyHandler.DeleteFxRate)
+	})
+}
+
+func registerDocumentRoutes(r chi.Router, store *store.Store, renderer *quotepdf.Renderer) {
+	documentHandler := handlers.NewDocumentHandler(store)
+	quoteDocumentHandler := handlers.NewQuoteDocumentHandler(store, renderer)
+
+	r.Get("/settings/document-numbering", documentHandler.ListNumberFormats)
+	r.Put("/settings/document-numbering/{documentType}", documentHandler.UpdateNumberFormat)
+	r.Get("/settings/tax", documentHandler.GetTaxSettings)
+	r.Put("/settings/tax", documentHandler.UpdateTaxSettings)
+
+	r.Route("/settings/quote-templates", func(templates chi.Router) {
+		templates.Get("/", quoteDocumentHandler.ListTemplates)
+		templates.Post("/", quoteDocumentHandler.CreateTemplate)
+		templates.Get("/{id}", quoteDocumentHandler.GetTemplate)
+		templates.Patch("/{id}", quoteDocumentHandler.UpdateTemplate)
+	})
+
+	r.Get("/quotes/{id}/pdf", quoteDocumentHandler.RenderPDF)
+	r.Get("/quotes/{id}/documents", quoteDocumentHandler.ListDocuments)
+	r.Get("/quotes/{id}/documents/{version}", quoteDocumentHandler.DownloadDocument)
 }
 
 func registerDashboardRoutes(r chi.Router, store *store.Store) {
@@ -100,12 +232,29 @@ func registerDashboardRoutes(r chi.Router, store *store.Store) {
 
 	r.Route("/dashboard", func(d chi.Router) {
 		d.Get("/kpi", dashboardHandler.KPI)
+		d.Post("/kpi/refresh", dashboardHandler.RefreshKPI)
 		d.Get("/pipeline", dashboardHandler.Pipeline)
+		d.Get("/revenue-schedule", dashboardHandler.RevenueSchedule)
 	})
 }
 
-func registerAuditRoutes(r chi.Router) {
-	r.Get("/audit-logs", notImplemented)
+func registerNotificationRoutes(r chi.Router, store *store.Store) {
+	notificationHandler := handlers.NewNotificationHandler(store)
+
+	r.Route("/notifications", func(notifications chi.Router) {
+		notifications.Get("/", notificationHandler.List)
+		notifications.Post("/read-all", notificationHandler.MarkAllRead)
+		notifications.Post("/{id}/read", notificationHandler.MarkRead)
+	})
+}
+
+func registerAuditRoutes(r chi.Router, store *store.Store) {
+	auditLogHandler := handlers.NewAuditLogHandler(store)
+
+	r.Route("/audit-logs", func(auditLogs chi.Router) {
+		auditLogs.Get("/", auditLogHandler.List)
+		auditLogs.Get("/export", auditLogHandler.Export)
+	})
 }
 
 func registerFeaturePackRoutes(r chi.Router, store *store.Store) {
@@ -119,6 +268,9 @@ func registerFeaturePackRoutes(r chi.Router, store *store.Store) {
 		analytics.Get("/forecast", features.Forecast)
 		analytics.Get("/loss-reasons", features.LossReasonAnalysis)
 		analytics.Get("/duplicates", features.DuplicateCandidates)
+		analytics.Get("/stage-velocity", features.StageVelocity)
+		analytics.Get("/product-revenue", features.ProductRevenue)
+		analytics.Get("/competitors", features.CompetitiveAnalysis)
 	})
 
 	r.Route("/integrations", func(integrations chi.Router) {
@@ -128,10 +280,33 @@ func registerFeaturePackRoutes(r chi.Router, store *store.Store) {
 		integrations.Post("/events", features.CreateIntegrationEvent)
 	})
 
+	discountPolicyHandler := handlers.NewDiscountPolicyHandler(store)
+	r.Route("/settings/discount-policies", func(policies chi.Router) {
+		policies.Get("/", discountPolicyHandler.List)
+		policies.Post("/", discountPolicyHandler.Create)
+		policies.Get("/{id}", discountPolicyHandler.Get)
+		policies.Patch("/{id}", discountPolicyHandler.Update)
+	})
+
+	approvalChainHandler := handlers.NewApprovalChainHandler(store)
+	r.Route("/settings/approval-chains", func(chains chi.Router) {
+		chains.Get("/", approvalChainHandler.List)
+		chains.Post("/", approvalChainHandler.Create)
+		chains.Get("/{id}", approvalChainHandler.Get)
+		chains.Patch("/{id}", approvalChainHandler.Update)
+	})
+	r.Route("/approval-delegations", func(delegations chi.Router) {
+		delegations.Get("/", approvalChainHandler.ListDelegations)
+		delegations.Post("/", approvalChainHandler.CreateDelegation)
+		delegations.Delete("/{id}", approvalChainHandler.DeleteDelegation)
+	})
+
 	r.Route("/approvals", func(approvals chi.Router) {
 		approvals.Get("/", features.ListApprovalRequests)
 		approvals.Post("/", features.CreateApprovalRequest)
+		approvals.Get("/{id}", features.GetApprovalRequest)
 		approvals.Post("/{id}/decision", features.DecideApproval)
+		approvals.Post("/{id}/withdraw", features.WithdrawApproval)
 	})
 
 	r.Get("/export/accounts.csv", features.ExportAccountsCSV)
@@ -0,0 +1,95 @@
+// Package integrations turns imported email and calendar events into CRM records.
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+// ConvertEvent turns a linked integration event into an email or meeting activity on its
+// opportunity. It is idempotent on the event's external ID: it returns nil when the event
+// has no opportunity or external ID, or when the activity is already up to date.
+func ConvertEvent(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, eventID int64) (*dbgen.Activity, error) {
+	row, err := q.UpsertActivityFromEvent(ctx, dbgen.UpsertActivityFromEventParams{
+		TenantID: pgtype.UUID{Bytes: tenantID, Valid: true},
+		EventID:  eventID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// BackfillResult counts the work done by Backfill.
+type BackfillResult struct {
+	Tenants   int
+	Scanned   int
+	Converted int
+}
+
+// Backfill converts every pending event of the given tenants (all tenants when none are
+// given). Each batch runs in its own transaction so a long backfill does not hold locks.
+func Backfill(ctx context.Context, s *store.Store, tenantIDs []uuid.UUID, batchSize int32) (BackfillResult, error) {
+	var result BackfillResult
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if len(tenantIDs) == 0 {
+		rows, err := s.Queries.ListTenantIDs(ctx)
+		if err != nil {
+			return result, fmt.Errorf("list tenants: %w", err)
+		}
+		for _, id := range rows {
+			tenantIDs = append(tenantIDs, uuid.UUID(id.Bytes))
+		}
+	}
+
+	for _, tenantID := range tenantIDs {
+		result.Tenants++
+		var afterID int64
+		for {
+			var eventIDs []int64
+			err := s.WithTenantTx(ctx, tenantID, func(q *dbgen.Queries) error {
+				var queryErr error
+				eventIDs, queryErr = q.ListPendingEventActivities(ctx, dbgen.ListPendingEventActivitiesParams{
+					TenantID:   pgtype.UUID{Bytes: tenantID, Valid: true},
+					AfterID:    afterID,
+					LimitCount: batchSize,
+				})
+				if queryErr != nil {
+					return queryErr
+				}
+				for _, eventID := range eventIDs {
+					activity, convertErr := ConvertEvent(ctx, q, tenantID, eventID)
+					if convertErr != nil {
+						return fmt.Errorf("event %d: %w", eventID, convertErr)
+					}
+					if activity != nil {
+						result.Converted++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return result, fmt.Errorf("tenant %s: %w", tenantID, err)
+			}
+			result.Scanned += len(eventIDs)
+			if len(eventIDs) < int(batchSize) {
+				break
+			}
+			afterID = eventIDs[len(eventIDs)-1]
+		}
+	}
+	return result, nil
+}
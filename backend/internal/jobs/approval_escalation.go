@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+// ApprovalEscalation adds escalation approvers to chain steps that stayed pending past
+// their SLA: the step's escalation user, or the tenant admins when it names none or that
+// user left. The original approvers keep their access. Each step escalates once, and
+// steps locked by a decision in progress are picked up by the next run.
+type ApprovalEscalation struct {
+	Store     *store.Store
+	BatchSize int32
+}
+
+// ApprovalEscalationResult counts the work done by one run.
+type ApprovalEscalationResult struct {
+	Tenants   int
+	Escalated int
+}
+
+// Run calls RunOnce immediately and then every interval until ctx is cancelled.
+func (j ApprovalEscalation) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("approval escalation: %v", err)
+		}
+		if result.Escalated > 0 {
+			log.Printf("approval escalation: tenants=%d escalated=%d", result.Tenants, result.Escalated)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes every tenant. A failing tenant is reported but does not stop the others.
+func (j ApprovalEscalation) RunOnce(ctx context.Context) (ApprovalEscalationResult, error) {
+	var result ApprovalEscalationResult
+	if j.BatchSize <= 0 {
+		j.BatchSize = 200
+	}
+	rows, err := j.Store.Queries.ListTenantIDs(ctx)
+	if err != nil {
+		return result, fmt.Errorf("list tenants: %w", err)
+	}
+
+	var errs []error
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		tenantID := uuid.UUID(row.Bytes)
+		result.Tenants++
+		if err := j.escalateTenant(ctx, tenantID, &result); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func (j ApprovalEscalation) escalateTenant(ctx context.Context, tenantID uuid.UUID, result *ApprovalEscalationResult) error {
+	for {
+		var overdue []dbgen.ListOverdueApprovalStepsRow
+		err := j.Store.WithTenantTx(ctx, tenantID, func(q *dbgen.Queries) error {
+			var queryErr error
+			overdue, queryErr = q.ListOverdueApprovalSteps(ctx, dbgen.ListOverdueApprovalStepsParams{
+				TenantID:   toPGUUID(tenantID),
+				LimitCount: j.BatchSize,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			for _, step := range overdue {
+				if queryErr := escalateStep(ctx, q, tenantID, step); queryErr != nil {
+					return queryErr
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.Escalated += len(overdue)
+		if len(overdue) < int(j.BatchSize) {
+			return nil
+		}
+	}
+}
+
+func escalateStep(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, step dbgen.ListOverdueApprovalStepsRow) error {
+	targets, err := escalationTargets(ctx, q, tenantID, step.EscalationUserID, step.RequestedBy)
+	if err != nil {
+		return err
+	}
+	added := make([]string, 0, len(targets))
+	for _, userID := range targets {
+		inserted, err := q.AddApprovalStepApprover(ctx, dbgen.AddApprovalStepApproverParams{
+			StepID:   step.ID,
+			UserID:   userID,
+			TenantID: toPGUUID(tenantID),
+			Source:   "escalation",
+		})
+		if err != nil {
+			return err
+		}
+		// Users who already approve the step are not notified twice.
+		if inserted == 0 {
+			continue
+		}
+		added = append(added, uuid.UUID(userID.Bytes).String())
+		if _, err := createNotification(ctx, q, tenantID, userID, "approval_escalated", "approval_request", step.ApprovalRequestID,
+			fmt.Sprintf("Escalated approval: %s", step.Name),
+			fmt.Sprintf("%s (step %d: %s) has been pending since %s.", step.RequestReason, step.StepOrder, step.Name, step.ActivatedAt.Time.UTC().Format(time.RFC3339)),
+			map[string]any{
+				"entityType":  step.EntityType,
+				"entityId":    uuid.UUID(step.EntityID.Bytes).String(),
+				"currentStep": step.StepOrder,
+			},
+		); err != nil {
+			return err
+		}
+	}
+	if err := q.MarkApprovalStepEscalated(ctx, dbgen.MarkApprovalStepEscalatedParams{
+		TenantID: toPGUUID(tenantID),
+		StepID:   step.ID,
+	}); err != nil {
+		return err
+	}
+	data, err := json.Marshal(map[string]any{
+		"approvers": added,
+		"slaDueAt":  step.SlaDueAt.Time.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	if err := q.CreateApprovalRequestEvent(ctx, dbgen.CreateApprovalRequestEventParams{
+		TenantID:          toPGUUID(tenantID),
+		ApprovalRequestID: step.ApprovalRequestID,
+		StepOrder:         pgtype.Int4{Int32: step.StepOrder, Valid: true},
+		Event:             "escalated",
+		Data:              data,
+	}); err != nil {
+		return err
+	}
+	return writeAuditLog(ctx, q, tenantID, "approval_escalation_job", "approval_request", step.ApprovalRequestID, map[string]any{
+		"event":     "step_escalated",
+		"stepOrder": step.StepOrder,
+		"approvers": added,
+	})
+}
+
+// escalationTargets returns the step's escalation user while they are an active member,
+// otherwise the tenant admins. The requester is never among them, as with step approvers.
+func escalationTargets(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, escalationUserID, requesterID pgtype.UUID) ([]pgtype.UUID, error) {
+	if escalationUserID.Valid && escalationUserID != requesterID {
+		_, err := q.GetActiveMembershipRole(ctx, dbgen.GetActiveMembershipRoleParams{
+			TenantID: toPGUUID(tenantID),
+			UserID:   escalationUserID,
+		})
+		if err == nil {
+			return []pgtype.UUID{escalationUserID}, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+	admins, err := q.ListActiveMembersByRole(ctx, dbgen.ListActiveMembersByRoleParams{
+		TenantID: toPGUUID(tenantID),
+		Role:     dbgen.RoleEnumAdmin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]pgtype.UUID, 0, len(admins))
+	for _, userID := range admins {
+		if userID != requesterID {
+			targets = append(targets, userID)
+		}
+	}
+	return targets, nil
+}
@@ -0,0 +1,254 @@
+// Package jobs holds the background work the API runs on a schedule.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	dbgen "sfa/backend/internal/db/sqlc"
+	"sfa/backend/internal/store"
+)
+
+// QuoteExpiry moves sent quotes past valid_until to expired and reminds opportunity
+// owners NoticeDays before a quote expires. Every API replica may run it: rows held by
+// another transaction are skipped and reminders are claimed through a unique key, so
+// concurrent runs never expire or announce the same quote twice.
+type QuoteExpiry struct {
+	Store *store.Store
+	// NoticeDays is how many days before valid_until the owner is reminded; 0 disables reminders.
+	NoticeDays int
+	BatchSize  int32
+}
+
+// QuoteExpiryResult counts the work done by one run.
+type QuoteExpiryResult struct {
+	Tenants   int
+	Expired   int
+	Reminded  int
+	FollowUps int
+}
+
+// Run calls RunOnce immediately and then every interval until ctx is cancelled.
+func (j QuoteExpiry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := j.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("quote expiry: %v", err)
+		}
+		if result.Expired > 0 || result.Reminded > 0 {
+			log.Printf("quote expiry: tenants=%d expired=%d reminded=%d follow_ups=%d", result.Tenants, result.Expired, result.Reminded, result.FollowUps)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes every tenant. A failing tenant is reported but does not stop the others.
+func (j QuoteExpiry) RunOnce(ctx context.Context) (QuoteExpiryResult, error) {
+	var result QuoteExpiryResult
+	if j.BatchSize <= 0 {
+		j.BatchSize = 200
+	}
+	rows, err := j.Store.Queries.ListTenantIDs(ctx)
+	if err != nil {
+		return result, fmt.Errorf("list tenants: %w", err)
+	}
+
+	var errs []error
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		tenantID := uuid.UUID(row.Bytes)
+		result.Tenants++
+		if err := j.expireTenant(ctx, tenantID, &result); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: expire: %w", tenantID, err))
+			continue
+		}
+		if j.NoticeDays <= 0 {
+			continue
+		}
+		if err := j.remindTenant(ctx, tenantID, &result); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: remind: %w", tenantID, err))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func (j QuoteExpiry) expireTenant(ctx context.Context, tenantID uuid.UUID, result *QuoteExpiryResult) error {
+	for {
+		var expired []dbgen.ExpireDueQuotesRow
+		err := j.Store.WithTenantTx(ctx, tenantID, func(q *dbgen.Queries) error {
+			var queryErr error
+			expired, queryErr = q.ExpireDueQuotes(ctx, dbgen.ExpireDueQuotesParams{
+				TenantID:   toPGUUID(tenantID),
+				LimitCount: j.BatchSize,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			for _, quote := range expired {
+				if _, queryErr := q.RecalculateOpportunityAmount(ctx, dbgen.RecalculateOpportunityAmountParams{
+					TenantID:      toPGUUID(tenantID),
+					OpportunityID: quote.OpportunityID,
+				}); queryErr != nil {
+					return queryErr
+				}
+				if queryErr := writeAuditLog(ctx, q, tenantID, "quote_expiry_job", "quote", quote.ID, map[string]any{
+					"event":      "status_changed",
+					"fromStatus": string(dbgen.QuoteStatusEnumSent),
+					"toStatus":   string(dbgen.QuoteStatusEnumExpired),
+					"quoteNo":    quote.QuoteNo,
+					"validUntil": quote.ValidUntil.Time.Format("2006-01-02"),
+					"reason":     "valid_until passed",
+				}); queryErr != nil {
+					return queryErr
+				}
+				if _, queryErr := createNotification(ctx, q, tenantID, quote.OwnerUserID, "quote_expired", "quote", quote.ID,
+					fmt.Sprintf("Quote %s has expired", quote.QuoteNo),
+					fmt.Sprintf("Quote %s for %s expired on %s without a response.", quote.QuoteNo, quote.OpportunityName, quote.ValidUntil.Time.Format("2006-01-02")),
+					quoteNotificationData(quote.OpportunityID, quote.ValidUntil),
+				); queryErr != nil {
+					return queryErr
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.Expired += len(expired)
+		if len(expired) < int(j.BatchSize) {
+			return nil
+		}
+	}
+}
+
+func (j QuoteExpiry) remindTenant(ctx context.Context, tenantID uuid.UUID, result *QuoteExpiryResult) error {
+	for {
+		var due []dbgen.ListQuotesDueForExpiryReminderRow
+		reminded, followUps := 0, 0
+		err := j.Store.WithTenantTx(ctx, tenantID, func(q *dbgen.Queries) error {
+			if queryErr := q.SetChangeContext(ctx, dbgen.SetChangeContextParams{
+				Source: string(dbgen.ChangeSourceEnumSystem),
+			}); queryErr != nil {
+				return queryErr
+			}
+			var queryErr error
+			due, queryErr = q.ListQuotesDueForExpiryReminder(ctx, dbgen.ListQuotesDueForExpiryReminderParams{
+				TenantID:   toPGUUID(tenantID),
+				NoticeDays: int32(j.NoticeDays),
+				LimitCount: j.BatchSize,
+			})
+			if queryErr != nil {
+				return queryErr
+			}
+			for _, quote := range due {
+				_, queryErr := q.ClaimQuoteExpiryReminder(ctx, dbgen.ClaimQuoteExpiryReminderParams{
+					TenantID:       toPGUUID(tenantID),
+					QuoteID:        quote.ID,
+					ValidUntil:     quote.ValidUntil,
+					NotifiedUserID: quote.OwnerUserID,
+				})
+				if errors.Is(queryErr, pgx.ErrNoRows) {
+					continue
+				}
+				if queryErr != nil {
+					return queryErr
+				}
+				validUntil := quote.ValidUntil.Time.Format("2006-01-02")
+				if _, queryErr := createNotification(ctx, q, tenantID, quote.OwnerUserID, "quote_expiring", "quote", quote.ID,
+					fmt.Sprintf("Quote %s expires on %s", quote.QuoteNo, validUntil),
+					fmt.Sprintf("Quote %s for %s is valid until %s. Follow up with the customer before it expires.", quote.QuoteNo, quote.OpportunityName, validUntil),
+					quoteNotificationData(quote.OpportunityID, quote.ValidUntil),
+				); queryErr != nil {
+					return queryErr
+				}
+				reminded++
+
+				// The quote is valid through valid_until, so it lapses at the start of the next day.
+				expiresAt := quote.ValidUntil.Time.AddDate(0, 0, 1)
+				updated, queryErr := q.SetOpportunityFollowUp(ctx, dbgen.SetOpportunityFollowUpParams{
+					NextActionAt:   pgtype.Timestamptz{Time: time.Now(), Valid: true},
+					NextActionNote: pgtype.Text{String: fmt.Sprintf("Follow up on quote %s before it expires on %s", quote.QuoteNo, validUntil), Valid: true},
+					TenantID:       toPGUUID(tenantID),
+					OpportunityID:  quote.OpportunityID,
+					ExpiresAt:      pgtype.Timestamptz{Time: expiresAt, Valid: true},
+				})
+				if queryErr != nil {
+					return queryErr
+				}
+				if updated > 0 {
+					followUps++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.Reminded += reminded
+		result.FollowUps += followUps
+		if len(due) < int(j.BatchSize) {
+			return nil
+		}
+	}
+}
+
+func quoteNotificationData(opportunityID pgtype.UUID, validUntil pgtype.Date) map[string]any {
+	return map[string]any{
+		"opportunityId": uuid.UUID(opportunityID.Bytes).String(),
+		"validUntil":    validUntil.Time.Format("2006-01-02"),
+	}
+}
+
+func createNotification(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, userID pgtype.UUID, kind, entityType string, entityID pgtype.UUID, title, body string, data map[string]any) (dbgen.Notification, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return dbgen.Notification{}, err
+	}
+	return q.CreateNotification(ctx, dbgen.CreateNotificationParams{
+		TenantID:   toPGUUID(tenantID),
+		UserID:     userID,
+		Kind:       kind,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Title:      title,
+		Body:       body,
+		Data:       payload,
+	})
+}
+
+// writeAuditLog records a change made by a job itself: there is no actor, IP or user agent.
+func writeAuditLog(ctx context.Context, q *dbgen.Queries, tenantID uuid.UUID, source, entityType string, entityID pgtype.UUID, metadata map[string]any) error {
+	metadata["source"] = source
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateAuditLog(ctx, dbgen.CreateAuditLogParams{
+		TenantID:   toPGUUID(tenantID),
+		Action:     dbgen.AuditActionEnumUpdate,
+		EntityType: pgtype.Text{String: entityType, Valid: true},
+		EntityID:   entityID,
+		Metadata:   payload,
+	})
+	return err
+}
+
+func toPGUUID(id uuid.UUID) pgtype.UUID {
+	return pgtype.UUID{Bytes: id, Valid: true}
+}
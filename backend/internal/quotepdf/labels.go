@@ -0,0 +1,72 @@
+package quotepdf
+
+type labelSet struct {
+	title              string
+	honorific          string
+	postalMark         string
+	quoteNo            string
+	issuedOn           string
+	validUntil         string
+	registrationNumber string
+	subject            string
+	intro              string
+	totalDue           string
+	columns            [7]string
+	reducedRateNote    string
+	subtotal           string
+	taxableFormat      string
+	taxFormat          string
+	taxTotal           string
+	total              string
+	notes              string
+}
+
+var labelSets = map[string]labelSet{
+	"ja": {
+		title:              "御見積書",
+		honorific:          " 御中",
+		postalMark:         "〒",
+		quoteNo:            "見積番号",
+		issuedOn:           "発行日",
+		validUntil:         "有効期限",
+		registrationNumber: "登録番号",
+		subject:            "件名",
+		intro:              "下記の通り御見積申し上げます。",
+		totalDue:           "御見積金額（税込）",
+		columns:            [7]string{"No", "品名", "数量", "単価", "値引", "税率", "金額"},
+		reducedRateNote:    "※は軽減税率（8%）対象",
+		subtotal:           "小計（税抜）",
+		taxableFormat:      "%s対象",
+		taxFormat:          "消費税（%s）",
+		taxTotal:           "消費税合計",
+		total:              "合計（税込）",
+		notes:              "備考",
+	},
+	"en": {
+		title:              "QUOTATION",
+		honorific:          "",
+		postalMark:         "",
+		quoteNo:            "Quote No.",
+		issuedOn:           "Issued",
+		validUntil:         "Valid until",
+		registrationNumber: "Registration No.",
+		subject:            "Subject:",
+		intro:              "We are pleased to quote as follows.",
+		totalDue:           "Total (tax incl.)",
+		columns:            [7]string{"No", "Item", "Qty", "Unit price", "Disc.", "Tax", "Amount"},
+		reducedRateNote:    "※ Reduced tax rate (8%)",
+		subtotal:           "Subtotal (excl. tax)",
+		taxableFormat:      "Taxable at %s",
+		taxFormat:          "Consumption tax (%s)",
+		taxTotal:           "Total tax",
+		total:              "Total (tax incl.)",
+		notes:              "Notes",
+	},
+}
+
+func labelsFor(language string) labelSet {
+	if labels, ok := labelSets[language]; ok {
+		return labels
+	}
+	return labelSets["ja"]
+}
@@ -0,0 +1,518 @@
+// Package quotepdf renders quotes as A4 PDFs with an embedded Japanese font.
+package quotepdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ErrFontUnavailable means the configured TrueType font could not be read. PDFs are not
+// rendered without it because the core PDF fonts cannot draw Japanese text.
+var ErrFontUnavailable = errors.New("pdf font is not available")
+
+const (
+	fontFamily   = "body"
+	pageMargin   = 15.0
+	contentWidth = 180.0
+	lineHeight   = 5.0
+)
+
+// Document is everything printed on a quote PDF. Amounts are in the quote currency.
+type Document struct {
+	Language    string // "ja" or "en"
+	AccentColor string // #RRGGBB
+	Letterhead  Letterhead
+	FooterText  string
+
+	QuoteNo    string
+	Subject    string
+	IssuedOn   time.Time // zero when not issued yet
+	ValidUntil time.Time // zero when open-ended
+	Customer   Customer
+	Currency   string
+	Lines      []Line
+	Subtotal   float64
+	TaxAmount  float64
+	Total      float64
+	TaxLines   []TaxLine
+	Notes      string
+
+	GeneratedAt time.Time
+}
+
+type Letterhead struct {
+	CompanyName        string
+	Address            string
+	Phone              string
+	RegistrationNumber string
+}
+
+type Customer struct {
+	Name         string
+	LocationName string
+	Country      string
+	PostalCode   string
+	Prefecture   string
+	City         string
+	AddressLine1 string
+	AddressLine2 string
+}
+
+type Line struct {
+	Name            string
+	Description     string
+	Quantity        float64
+	UnitPrice       float64
+	DiscountPercent float64
+	TaxRate         float64
+	Amount          float64
+}
+
+type TaxLine struct {
+	TaxRate       float64
+	TaxableAmount float64
+	TaxAmount     float64
+}
+
+// Renderer loads the font once and renders any number of documents concurrently.
+type Renderer struct {
+	fontPath string
+	once     sync.Once
+	font     []byte
+	fontErr  error
+}
+
+// NewRenderer returns a renderer that embeds the TrueType (glyf-outline) font at fontPath,
+// e.g. IPAexGothic. The file is read on first use.
+func NewRenderer(fontPath string) *Renderer {
+	return &Renderer{fontPath: fontPath}
+}
+
+func (r *Renderer) loadFont() ([]byte, error) {
+	r.once.Do(func() {
+		if strings.TrimSpace(r.fontPath) == "" {
+			r.fontErr = ErrFontUnavailable
+			return
+		}
+		font, err := os.ReadFile(r.fontPath)
+		if err != nil {
+			r.fontErr = fmt.Errorf("%w: %v", ErrFontUnavailable, err)
+			return
+		}
+		r.font = font
+	})
+	return r.font, r.fontErr
+}
+
+// Render lays out doc on as many A4 pages as the line items need.
+func (r *Renderer) Render(doc Document) ([]byte, error) {
+	font, err := r.loadFont()
+	if err != nil {
+		return nil, err
+	}
+	labels := labelsFor(doc.Language)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(true)
+	pdf.SetCreationDate(doc.GeneratedAt)
+	pdf.SetModificationDate(doc.GeneratedAt)
+	pdf.SetTitle(labels.title+" "+doc.QuoteNo, true)
+	pdf.SetAuthor(doc.Letterhead.CompanyName, true)
+	pdf.SetCreator("sfa", false)
+	pdf.SetLang(doc.Language)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", font)
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("{nb}")
+
+	accentR, accentG, accentB := parseColor(doc.AccentColor)
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFillColor(accentR, accentG, accentB)
+		pdf.Rect(0, 0, 210, 5, "F")
+		pdf.SetY(pageMargin)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(contentWidth-25, 4, doc.FooterText, "", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 4, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	pdf.AddPage()
+	writeHeading(pdf, doc, labels)
+	writeParties(pdf, doc, labels)
+	writeTotalBox(pdf, doc, labels, accentR, accentG, accentB)
+	writeLines(pdf, doc, labels, accentR, accentG, accentB)
+	writeSummary(pdf, doc, labels)
+	writeNotes(pdf, doc, labels)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeading(pdf *fpdf.Fpdf, doc Document, labels labelSet) {
+	pdf.SetFont(fontFamily, "", 20)
+	pdf.CellFormat(contentWidth, 12, labels.title, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+}
+
+// writeParties prints the addressee on the left and the quote details and letterhead
+// on the right, then moves below whichever column is taller.
+func writeParties(pdf *fpdf.Fpdf, doc Document, labels labelSet) {
+	top := pdf.GetY()
+
+	pdf.SetFont(fontFamily, "", 13)
+	customer := doc.Customer.Name + labels.honorific
+	for _, line := range wrapText(pdf, customer, 100) {
+		pdf.CellFormat(100, 7, line, "", 1, "L", false, 0, "")
+	}
+	pdf.Line(pageMargin, pdf.GetY(), pageMargin+100, pdf.GetY())
+	pdf.Ln(2)
+	pdf.SetFont(fontFamily, "", 9)
+	for _, line := range customerAddress(doc.Customer, labels) {
+		for _, wrapped := range wrapText(pdf, line, 100) {
+			pdf.CellFormat(100, 4.5, wrapped, "", 1, "L", false, 0, "")
+		}
+	}
+	leftBottom := pdf.GetY()
+
+	right := pageMargin + 110
+	pdf.SetXY(right, top)
+	pdf.SetFont(fontFamily, "", 9)
+	details := [][2]string{
+		{labels.quoteNo, doc.QuoteNo},
+		{labels.issuedOn, formatDate(doc.IssuedOn, doc.Language)},
+		{labels.validUntil, formatDate(doc.ValidUntil, doc.Language)},
+	}
+	for _, detail := range details {
+		pdf.SetX(right)
+		pdf.CellFormat(25, 5, detail[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(45, 5, detail[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(4)
+	pdf.SetX(right)
+	pdf.SetFont(fontFamily, "", 11)
+	for _, line := range wrapText(pdf, doc.Letterhead.CompanyName, 70) {
+		pdf.SetX(right)
+		pdf.CellFormat(70, 6, line, "", 1, "L", false, 0, "")
+	}
+	pdf.SetFont(fontFamily, "", 8.5)
+	letterhead := []string{doc.Letterhead.Address}
+	if doc.Letterhead.Phone != "" {
+		letterhead = append(letterhead, "TEL "+doc.Letterhead.Phone)
+	}
+	if doc.Letterhead.RegistrationNumber != "" {
+		letterhead = append(letterhead, labels.registrationNumber+" "+doc.Letterhead.RegistrationNumber)
+	}
+	for _, line := range letterhead {
+		for _, wrapped := range wrapText(pdf, line, 70) {
+			pdf.SetX(right)
+			pdf.CellFormat(70, 4.5, wrapped, "", 1, "L", false, 0, "")
+		}
+	}
+
+	pdf.SetY(math.Max(leftBottom, pdf.GetY()) + 6)
+	if doc.Subject != "" {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.CellFormat(contentWidth, 6, labels.subject+"  "+doc.Subject, "", 1, "L", false, 0, "")
+	}
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(contentWidth, 6, labels.intro, "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+func writeTotalBox(pdf *fpdf.Fpdf, doc Document, labels labelSet, r, g, b int) {
+	pdf.SetDrawColor(r, g, b)
+	pdf.SetLineWidth(0.4)
+	pdf.SetFont(fontFamily, "", 11)
+	pdf.CellFormat(45, 11, labels.totalDue, "1", 0, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 15)
+	pdf.CellFormat(65, 11, formatMoney(doc.Total, doc.Currency), "1", 1, "R", false, 0, "")
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Ln(6)
+}
+
+var lineColumns = []struct {
+	width float64
+	align string
+}{
+	{8, "C"},  // no
+	{76, "L"}, // item
+	{16, "R"}, // quantity
+	{26, "R"}, // unit price
+	{14, "R"}, // discount
+	{14, "C"}, // tax rate
+	{26, "R"}, // amount
+}
+
+func writeLineHeader(pdf *fpdf.Fpdf, labels labelSet, r, g, b int) {
+	pdf.SetFont(fontFamily, "", 8.5)
+	pdf.SetFillColor(r, g, b)
+	pdf.SetTextColor(255, 255, 255)
+	for i, header := range labels.columns {
+		pdf.CellFormat(lineColumns[i].width, 7, header, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// writeLines draws the item table. Rows never split across pages; the header is
+// repeated at the top of every continuation page.
+func writeLines(pdf *fpdf.Fpdf, doc Document, labels labelSet, r, g, b int) {
+	writeLineHeader(pdf, labels, r, g, b)
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	reduced := false
+
+	for i, line := range doc.Lines {
+		pdf.SetFont(fontFamily, "", 8.5)
+		text := wrapText(pdf, line.Name, lineColumns[1].width)
+		if line.Description != "" && line.Description != line.Name {
+			for _, extra := range strings.Split(line.Description, "\n") {
+				text = append(text, wrapText(pdf, extra, lineColumns[1].width)...)
+			}
+		}
+		height := float64(len(text))*4.5 + 2.5
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+			writeLineHeader(pdf, labels, r, g, b)
+			pdf.SetFont(fontFamily, "", 8.5)
+		}
+
+		rate := formatNumber(line.TaxRate, 0) + "%"
+		if line.TaxRate == 8 {
+			rate += "※"
+			reduced = true
+		}
+		discount := ""
+		if line.DiscountPercent > 0 {
+			discount = trimNumber(line.DiscountPercent) + "%"
+		}
+		cells := []string{
+			strconv.Itoa(i + 1),
+			"",
+			trimNumber(line.Quantity),
+			formatMoney(line.UnitPrice, doc.Currency),
+			discount,
+			rate,
+			formatMoney(line.Amount, doc.Currency),
+		}
+
+		x, y := pdf.GetXY()
+		for c, cell := range cells {
+			pdf.SetXY(x, y)
+			pdf.CellFormat(lineColumns[c].width, height, "", "1", 0, "", false, 0, "")
+			if c == 1 {
+				for n, row := range text {
+					pdf.SetXY(x, y+1.25+float64(n)*4.5)
+					pdf.CellFormat(lineColumns[c].width, 4.5, row, "", 0, "L", false, 0, "")
+				}
+			} else {
+				pdf.SetXY(x, y+1.25)
+				pdf.CellFormat(lineColumns[c].width, 4.5, cell, "", 0, lineColumns[c].align, false, 0, "")
+			}
+			x += lineColumns[c].width
+		}
+		pdf.SetXY(pageMargin, y+height)
+	}
+
+	if reduced {
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.CellFormat(contentWidth, 5, labels.reducedRateNote, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+}
+
+// writeSummary prints subtotal, the tax per rate (qualified invoice requirement) and the
+// grand total, right-aligned under the table.
+func writeSummary(pdf *fpdf.Fpdf, doc Document, labels labelSet) {
+	rows := [][2]string{{labels.subtotal, formatMoney(doc.Subtotal, doc.Currency)}}
+	for _, tax := range doc.TaxLines {
+		rate := formatNumber(tax.TaxRate, 0) + "%"
+		rows = append(rows,
+			[2]string{fmt.Sprintf(labels.taxableFormat, rate), formatMoney(tax.TaxableAmount, doc.Currency)},
+			[2]string{fmt.Sprintf(labels.taxFormat, rate), formatMoney(tax.TaxAmount, doc.Currency)},
+		)
+	}
+	rows = append(rows,
+		[2]string{labels.taxTotal, formatMoney(doc.TaxAmount, doc.Currency)},
+		[2]string{labels.total, formatMoney(doc.Total, doc.Currency)},
+	)
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+float64(len(rows))*6 > pageHeight-bottom {
+		pdf.AddPage()
+	}
+	pdf.SetFont(fontFamily, "", 9)
+	for i, row := range rows {
+		pdf.SetX(pageMargin + contentWidth - 90)
+		border := "B"
+		if i == len(rows)-1 {
+			border = "TB"
+			pdf.SetFont(fontFamily, "", 10.5)
+		}
+		pdf.CellFormat(55, 6, row[0], border, 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, row[1], border, 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+}
+
+func writeNotes(pdf *fpdf.Fpdf, doc Document, labels labelSet) {
+	if strings.TrimSpace(doc.Notes) == "" {
+		return
+	}
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.CellFormat(contentWidth, 6, labels.notes, "B", 1, "L", false, 0, "")
+	pdf.Ln(1)
+	for _, paragraph := range strings.Split(doc.Notes, "\n") {
+		for _, line := range wrapText(pdf, paragraph, contentWidth) {
+			pdf.CellFormat(contentWidth, lineHeight, line, "", 1, "L", false, 0, "")
+		}
+	}
+}
+
+func customerAddress(c Customer, labels labelSet) []string {
+	var lines []string
+	if c.PostalCode != "" {
+		lines = append(lines, labels.postalMark+c.PostalCode)
+	}
+	region := c.Prefecture + c.City
+	if labels.postalMark == "" {
+		region = strings.Join(nonEmpty(c.City, c.Prefecture), ", ")
+	}
+	if strings.TrimSpace(region) != "" {
+		lines = append(lines, region)
+	}
+	lines = append(lines, nonEmpty(c.AddressLine1, c.AddressLine2)...)
+	if c.Country != "" && c.Country != "JP" && c.Country != "日本" {
+		lines = append(lines, c.Country)
+	}
+	if c.LocationName != "" && c.LocationName != c.Name {
+		lines = append(lines, c.LocationName)
+	}
+	return lines
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// wrapText breaks text to fit width using the current font. Japanese has no spaces, so
+// lines break between any two characters; Latin text still prefers breaking at spaces.
+func wrapText(pdf *fpdf.Fpdf, text string, width float64) []string {
+	limit := width - 2*pdf.GetCellMargin()
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		runes := []rune(paragraph)
+		start, lastSpace := 0, -1
+		for i := 0; i < len(runes); i++ {
+			if unicode.IsSpace(runes[i]) {
+				lastSpace = i
+			}
+			if pdf.GetStringWidth(string(runes[start:i+1])) <= limit || i == start {
+				continue
+			}
+			end := i
+			if lastSpace > start {
+				end = lastSpace
+			}
+			lines = append(lines, strings.TrimRightFunc(string(runes[start:end]), unicode.IsSpace))
+			start = end
+			for start < len(runes) && unicode.IsSpace(runes[start]) {
+				start++
+			}
+			lastSpace = -1
+			i = start - 1
+		}
+		lines = append(lines, string(runes[start:]))
+	}
+	return lines
+}
+
+func parseColor(hex string) (int, int, int) {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return 0x1F, 0x4E, 0x79
+	}
+	return int(value >> 16 & 0xFF), int(value >> 8 & 0xFF), int(value & 0xFF)
+}
+
+func formatDate(t time.Time, language string) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if language == "en" {
+		return t.Format("Jan 2, 2006")
+	}
+	return t.Format("2006年1月2日")
+}
+
+// minorUnits mirrors currency_minor_units() in the database.
+func minorUnits(currency string) int {
+	switch currency {
+	case "JPY", "KRW", "VND", "CLP", "ISK":
+		return 0
+	}
+	return 2
+}
+
+func formatMoney(amount float64, currency string) string {
+	formatted := formatNumber(amount, minorUnits(currency))
+	if currency == "JPY" {
+		return "¥" + formatted
+	}
+	return currency + " " + formatted
+}
+
+// formatNumber prints value with thousands separators and a fixed number of decimals.
+func formatNumber(value float64, decimals int) string {
+	raw := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(raw, ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	out := grouped.String()
+	if fraction != "" {
+		out += "." + fraction
+	}
+	if value < 0 && strings.Trim(raw, "0.") != "" {
+		out = "-" + out
+	}
+	return out
+}
+
+// trimNumber prints quantities and percentages without trailing zeros, e.g. 1.5 or 12.
+func trimNumber(value float64) string {
+	decimals := 0
+	for decimals < 2 && math.Abs(value*math.Pow10(decimals)-math.Round(value*math.Pow10(decimals))) > 1e-9 {
+		decimals++
+	}
+	return formatNumber(value, decimals)
+}
@@ -0,0 +1,48 @@
+BEGIN;
+
+CREATE TABLE opportunity_stage_history (
+  id BIGSERIAL PRIMARY KEY,
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  opportunity_id UUID NOT NULL REFERENCES opportunities(id) ON DELETE CASCADE,
+  from_stage opportunity_stage_enum,
+  to_stage opportunity_stage_enum NOT NULL,
+  amount NUMERIC(14,2) NOT NULL,
+  probability SMALLINT NOT NULL,
+  changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_opportunity_stage_history_tenant_opportunity ON opportunity_stage_history (tenant_id, opportunity_id, changed_at);
+CREATE INDEX idx_opportunity_stage_history_tenant_changed ON opportunity_stage_history (tenant_id, changed_at);
+
+ALTER TABLE opportunity_stage_history ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_opportunity_stage_history ON opportunity_stage_history
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Backfill: the true entry time of the current stage is unknown, so closed deals use
+-- closed_at and open deals fall back to created_at.
+INSERT INTO opportunity_stage_history (
+  tenant_id, opportunity_id, from_stage, to_stage, amount, probability, changed_by, changed_at
+)
+SELECT
+  o.tenant_id,
+  o.id,
+  NULL,
+  o.stage,
+  o.amount,
+  o.probability,
+  o.created_by,
+  CASE
+    WHEN o.stage IN ('closed_won', 'closed_lost') THEN coalesce(o.closed_at, o.updated_at)
+    ELSE o.created_at
+  END
+FROM opportunities o
+WHERE NOT EXISTS (
+  SELECT 1
+  FROM opportunity_stage_history h
+  WHERE h.opportunity_id = o.id
+);
+
+COMMIT;
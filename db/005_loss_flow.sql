@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE opportunity_losses
+  ADD COLUMN competitor TEXT;
+
+COMMIT;
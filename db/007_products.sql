@@ -0,0 +1,95 @@
+BEGIN;
+
+CREATE TABLE products (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  sku TEXT NOT NULL,
+  name TEXT NOT NULL,
+  description TEXT,
+  unit TEXT,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, sku)
+);
+
+CREATE TABLE price_books (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (tenant_id, name)
+);
+
+CREATE UNIQUE INDEX uq_price_books_tenant_default ON price_books (tenant_id) WHERE is_default;
+
+CREATE TABLE price_book_entries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  price_book_id UUID NOT NULL REFERENCES price_books(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  unit_price NUMERIC(14,2) NOT NULL CHECK (unit_price >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (price_book_id, product_id)
+);
+
+-- A line item belongs to exactly one of opportunity, quote or order. line_total is
+-- derived so header amounts can be summed without recomputing discounts.
+CREATE TABLE line_items (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  opportunity_id UUID REFERENCES opportunities(id) ON DELETE CASCADE,
+  quote_id UUID REFERENCES quotes(id) ON DELETE CASCADE,
+  order_id UUID REFERENCES orders(id) ON DELETE CASCADE,
+  product_id UUID NOT NULL REFERENCES products(id),
+  price_book_id UUID REFERENCES price_books(id) ON DELETE SET NULL,
+  description TEXT,
+  quantity NUMERIC(12,2) NOT NULL CHECK (quantity > 0),
+  unit_price NUMERIC(14,2) NOT NULL CHECK (unit_price >= 0),
+  discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (discount_percent >= 0 AND discount_percent <= 100),
+  line_total NUMERIC(14,2) GENERATED ALWAYS AS (round(quantity * unit_price * (1 - discount_percent / 100), 2)) STORED,
+  sort_order INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (num_nonnulls(opportunity_id, quote_id, order_id) = 1)
+);
+
+CREATE INDEX idx_products_tenant_name ON products (tenant_id, name);
+CREATE INDEX idx_price_book_entries_product ON price_book_entries (tenant_id, product_id);
+CREATE INDEX idx_line_items_opportunity ON line_items (opportunity_id) WHERE opportunity_id IS NOT NULL;
+CREATE INDEX idx_line_items_quote ON line_items (quote_id) WHERE quote_id IS NOT NULL;
+CREATE INDEX idx_line_items_order ON line_items (order_id) WHERE order_id IS NOT NULL;
+CREATE INDEX idx_line_items_tenant_product ON line_items (tenant_id, product_id);
+
+ALTER TABLE products ENABLE ROW LEVEL SECURITY;
+ALTER TABLE price_books ENABLE ROW LEVEL SECURITY;
+ALTER TABLE price_book_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE line_items ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_products ON products
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_price_books ON price_books
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_price_book_entries ON price_book_entries
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_line_items ON line_items
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Every tenant starts with a default price book so list prices have somewhere to live.
+INSERT INTO price_books (tenant_id, name, is_default)
+SELECT t.id, 'Standard', TRUE
+FROM tenants t
+ON CONFLICT DO NOTHING;
+
+COMMIT;
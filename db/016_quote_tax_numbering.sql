@@ -0,0 +1,87 @@
+BEGIN;
+
+-- Japanese consumption tax: 10% standard, 8% reduced (food, newspapers), 0% for
+-- non-taxable items. Products carry the default rate, line items the applied one.
+CREATE TYPE tax_rounding_enum AS ENUM ('floor', 'round', 'ceil');
+
+ALTER TABLE tenants
+  ADD COLUMN tax_rounding tax_rounding_enum NOT NULL DEFAULT 'floor';
+
+ALTER TABLE products
+  ADD COLUMN tax_rate NUMERIC(4,2) NOT NULL DEFAULT 10 CHECK (tax_rate IN (0, 8, 10));
+
+ALTER TABLE line_items
+  ADD COLUMN tax_rate NUMERIC(4,2) NOT NULL DEFAULT 10 CHECK (tax_rate IN (0, 8, 10));
+
+-- Tax is rounded once per rate per document (qualified invoice rules), so the header
+-- keeps the per-rate breakdown next to the totals. amount stays the pre-tax subtotal.
+ALTER TABLE quotes
+  ADD COLUMN tax_amount NUMERIC(14,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0),
+  ADD COLUMN total_amount NUMERIC(14,2) NOT NULL DEFAULT 0 CHECK (total_amount >= 0),
+  ADD COLUMN tax_breakdown JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- currency_minor_units returns the decimals used for amounts in currency.
+CREATE FUNCTION currency_minor_units(p_currency TEXT)
+RETURNS INT
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE WHEN p_currency IN ('JPY', 'KRW', 'VND', 'CLP', 'ISK') THEN 0 ELSE 2 END
+$$;
+
+-- round_tax rounds a non-negative tax amount to p_scale decimals.
+CREATE FUNCTION round_tax(p_amount NUMERIC, p_mode tax_rounding_enum, p_scale INT)
+RETURNS NUMERIC
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE p_mode
+    WHEN 'floor' THEN trunc(p_amount, p_scale)
+    WHEN 'ceil' THEN ceil(p_amount * 10::NUMERIC ^ p_scale) / 10::NUMERIC ^ p_scale
+    ELSE round(p_amount, p_scale)
+  END
+$$;
+
+-- Existing line items are treated as standard rate.
+UPDATE quotes q
+SET tax_amount = round_tax(q.amount * 0.10, t.tax_rounding, currency_minor_units(q.currency)),
+    total_amount = q.amount + round_tax(q.amount * 0.10, t.tax_rounding, currency_minor_units(q.currency)),
+    tax_breakdown = CASE
+      WHEN q.amount > 0 THEN jsonb_build_array(jsonb_build_object(
+        'taxRate', 10,
+        'taxableAmount', q.amount,
+        'taxAmount', round_tax(q.amount * 0.10, t.tax_rounding, currency_minor_units(q.currency))
+      ))
+      ELSE '[]'::jsonb
+    END
+FROM tenants t
+WHERE t.id = q.tenant_id;
+
+-- Per-tenant numbering format per document type. Missing rows fall back to the built-in
+-- defaults (Q-YYYY-00001 for quotes, ORD-YYYYMM-0001 for orders).
+CREATE TABLE document_number_formats (
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  document_type TEXT NOT NULL CHECK (document_type IN ('quote', 'order')),
+  prefix TEXT NOT NULL CHECK (prefix ~ '^[A-Z0-9]{1,10}$'),
+  period_format TEXT NOT NULL CHECK (period_format IN ('none', 'yearly', 'monthly')),
+  padding INT NOT NULL CHECK (padding BETWEEN 1 AND 10),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (tenant_id, document_type)
+);
+
+ALTER TABLE document_number_formats ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_document_number_formats ON document_number_formats
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Continue after hand-entered numbers that already follow the default quote format.
+INSERT INTO document_sequences (tenant_id, prefix, period, last_value)
+SELECT tenant_id, 'Q', substring(quote_no FROM 3 FOR 4), max(substring(quote_no FROM 8)::INT)
+FROM quotes
+WHERE quote_no ~ '^Q-[0-9]{4}-[0-9]{1,9}$'
+GROUP BY tenant_id, substring(quote_no FROM 3 FOR 4)
+ON CONFLICT (tenant_id, prefix, period)
+DO UPDATE SET last_value = GREATEST(document_sequences.last_value, EXCLUDED.last_value);
+
+COMMIT;
@@ -0,0 +1,47 @@
+BEGIN;
+
+-- Tenant rules that put a quote under approval before it can be sent. A quote matches a
+-- policy when its metric is strictly above the threshold:
+--   line_discount_percent  highest discount_percent of any line
+--   quote_discount_percent overall discount against list price (quantity * unit_price)
+--   quote_amount           pre-tax amount converted to the tenant's base currency
+CREATE TYPE discount_policy_metric_enum AS ENUM ('line_discount_percent', 'quote_discount_percent', 'quote_amount');
+
+CREATE TABLE discount_policies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  metric discount_policy_metric_enum NOT NULL,
+  threshold NUMERIC(16,2) NOT NULL CHECK (threshold >= 0),
+  approver_user_id UUID NOT NULL REFERENCES users(id),
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  version BIGINT NOT NULL DEFAULT 1,
+  UNIQUE (tenant_id, name),
+  CHECK (metric = 'quote_amount' OR threshold <= 100)
+);
+
+CREATE TRIGGER trg_discount_policies_version BEFORE UPDATE ON discount_policies
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+ALTER TABLE discount_policies ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_discount_policies ON discount_policies
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+-- Approvals raised by a policy remember it and the value that tripped it. Editing the
+-- quote invalidates its open and granted approvals; invalidated_at is what the send check
+-- looks at, so a later decision on an invalidated request cannot unlock the quote.
+ALTER TYPE approval_status_enum ADD VALUE IF NOT EXISTS 'invalidated';
+
+ALTER TABLE approval_requests
+  ADD COLUMN policy_id UUID REFERENCES discount_policies(id) ON DELETE SET NULL,
+  ADD COLUMN metric_value NUMERIC(16,2),
+  ADD COLUMN invalidated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_approval_requests_tenant_policy ON approval_requests (tenant_id, entity_id, policy_id) WHERE policy_id IS NOT NULL;
+
+COMMIT;
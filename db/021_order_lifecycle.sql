@@ -0,0 +1,18 @@
+BEGIN;
+
+-- Orders move pending -> confirmed -> invoiced and may be cancelled (with a reason)
+-- until they are invoiced. Each step stamps its own timestamp.
+ALTER TABLE orders
+  ADD COLUMN confirmed_at TIMESTAMPTZ,
+  ADD COLUMN invoiced_at TIMESTAMPTZ,
+  ADD COLUMN cancelled_at TIMESTAMPTZ,
+  ADD COLUMN cancel_reason TEXT,
+  ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER trg_orders_version BEFORE UPDATE ON orders
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+CREATE INDEX idx_orders_tenant_ordered_on ON orders (tenant_id, ordered_on DESC, id);
+CREATE INDEX idx_orders_tenant_status ON orders (tenant_id, status, ordered_on DESC);
+
+COMMIT;
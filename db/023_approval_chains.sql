@@ -0,0 +1,166 @@
+BEGIN;
+
+-- Approval chains: ordered steps a request walks through. Each step names its approvers
+-- by user, tenant role or opportunity team role, may only apply above a metric value and
+-- may escalate when it stays pending longer than its SLA.
+CREATE TYPE approval_approver_type_enum AS ENUM ('user', 'role', 'team');
+CREATE TYPE approval_step_status_enum AS ENUM ('waiting', 'pending', 'approved', 'rejected', 'skipped', 'cancelled');
+
+CREATE TABLE approval_chains (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  description TEXT,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_by UUID NOT NULL REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  version BIGINT NOT NULL DEFAULT 1,
+  UNIQUE (tenant_id, name)
+);
+
+CREATE TRIGGER trg_approval_chains_version BEFORE UPDATE ON approval_chains
+  FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+CREATE TABLE approval_chain_steps (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  chain_id UUID NOT NULL REFERENCES approval_chains(id) ON DELETE CASCADE,
+  step_order INT NOT NULL CHECK (step_order >= 1),
+  name TEXT NOT NULL,
+  approver_type approval_approver_type_enum NOT NULL,
+  approver_user_id UUID REFERENCES users(id),
+  approver_role role_enum,
+  approver_team_role opportunity_team_role_enum,
+  min_value NUMERIC(16,2),
+  sla_hours INT CHECK (sla_hours > 0),
+  escalation_user_id UUID REFERENCES users(id),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (chain_id, step_order),
+  CHECK (
+    (approver_type = 'user' AND approver_user_id IS NOT NULL AND approver_role IS NULL AND approver_team_role IS NULL)
+    OR (approver_type = 'role' AND approver_role IS NOT NULL AND approver_user_id IS NULL AND approver_team_role IS NULL)
+    OR (approver_type = 'team' AND approver_team_role IS NOT NULL AND approver_user_id IS NULL AND approver_role IS NULL)
+  )
+);
+
+-- Out-of-office: while a delegation is active, steps that would go to user_id go to
+-- delegate_user_id instead.
+CREATE TABLE approval_delegations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id),
+  delegate_user_id UUID NOT NULL REFERENCES users(id),
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at),
+  CHECK (user_id <> delegate_user_id)
+);
+
+CREATE INDEX idx_approval_delegations_tenant_user ON approval_delegations (tenant_id, user_id, ends_at);
+
+ALTER TABLE approval_requests
+  ADD COLUMN chain_id UUID REFERENCES approval_chains(id) ON DELETE SET NULL,
+  ADD COLUMN current_step INT;
+
+ALTER TABLE discount_policies
+  ADD COLUMN chain_id UUID REFERENCES approval_chains(id),
+  ALTER COLUMN approver_user_id DROP NOT NULL,
+  ADD CONSTRAINT discount_policies_approver_check CHECK (approver_user_id IS NOT NULL OR chain_id IS NOT NULL);
+
+-- The steps of a chain request are copied when it is raised, so editing the chain does
+-- not change requests in flight.
+CREATE TABLE approval_request_steps (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  approval_request_id UUID NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+  step_order INT NOT NULL,
+  name TEXT NOT NULL,
+  approver_type approval_approver_type_enum NOT NULL,
+  approver_user_id UUID REFERENCES users(id),
+  approver_role role_enum,
+  approver_team_role opportunity_team_role_enum,
+  sla_hours INT,
+  escalation_user_id UUID REFERENCES users(id),
+  status approval_step_status_enum NOT NULL DEFAULT 'waiting',
+  activated_at TIMESTAMPTZ,
+  sla_due_at TIMESTAMPTZ,
+  escalated_at TIMESTAMPTZ,
+  decided_by UUID REFERENCES users(id),
+  decided_at TIMESTAMPTZ,
+  decision_note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (approval_request_id, step_order)
+);
+
+CREATE INDEX idx_approval_request_steps_sla ON approval_request_steps (tenant_id, sla_due_at)
+  WHERE status = 'pending' AND escalated_at IS NULL;
+
+-- Who may decide the active step: the resolved approvers, delegates standing in for
+-- them and users added by escalation.
+CREATE TABLE approval_step_approvers (
+  step_id UUID NOT NULL REFERENCES approval_request_steps(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  source TEXT NOT NULL CHECK (source IN ('assigned', 'delegate', 'escalation')),
+  delegated_from UUID REFERENCES users(id),
+  added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (step_id, user_id)
+);
+
+CREATE INDEX idx_approval_step_approvers_user ON approval_step_approvers (tenant_id, user_id);
+
+-- The decision trail of a request: raised, steps activated, delegated, escalated,
+-- approved, rejected, skipped.
+CREATE TABLE approval_request_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+  approval_request_id UUID NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+  step_order INT,
+  event TEXT NOT NULL,
+  actor_user_id UUID REFERENCES users(id),
+  note TEXT,
+  data JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_approval_request_events_request ON approval_request_events (approval_request_id, created_at);
+
+ALTER TABLE approval_chains ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_chain_steps ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_delegations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_request_steps ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_step_approvers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE approval_request_events ENABLE ROW LEVEL SECURITY;
+
+CREATE POLICY tenant_isolation_approval_chains ON approval_chains
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_approval_chain_steps ON approval_chain_steps
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_approval_delegations ON approval_delegations
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_approval_request_steps ON approval_request_steps
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_approval_step_approvers ON approval_step_approvers
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+CREATE POLICY tenant_isolation_approval_request_events ON approval_request_events
+  USING (tenant_id = current_setting('app.tenant_id', true)::UUID)
+  WITH CHECK (tenant_id = current_setting('app.tenant_id', true)::UUID);
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_kind_check
+  CHECK (kind IN ('quote_expiring', 'quote_expired', 'approval_requested', 'approval_escalated'));
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Requesters may withdraw a pending request. The new value is only used by later
+-- transactions, so it can be added inside this one.
+ALTER TYPE approval_status_enum ADD VALUE IF NOT EXISTS 'withdrawn';
+
+ALTER TABLE approval_requests ADD COLUMN withdrawn_at TIMESTAMPTZ;
+
+-- Set by the quote approval effect once every discount policy the draft matches is
+-- approved; cleared when the quote changes or an approval is rejected.
+ALTER TABLE quotes ADD COLUMN approved_for_send_at TIMESTAMPTZ;
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_kind_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_kind_check
+  CHECK (kind IN ('quote_expiring', 'quote_expired', 'approval_requested', 'approval_escalated', 'approval_decided'));
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- Approval subjects are typed now: quote, order, opportunity_discount (the subject is
+-- the opportunity) and account_ownership_transfer (the subject is the account). The API
+-- checks the type and that the subject exists. The feature pack seed raised its sample
+-- request against an opportunity as a 'quote'; free-text 'opportunity' requests mean
+-- the same thing.
+UPDATE approval_requests ar
+SET entity_type = 'opportunity_discount'
+WHERE ar.entity_type IN ('quote', 'opportunity')
+  AND NOT EXISTS (SELECT 1 FROM quotes q WHERE q.tenant_id = ar.tenant_id AND q.id = ar.entity_id)
+  AND EXISTS (SELECT 1 FROM opportunities o WHERE o.tenant_id = ar.tenant_id AND o.id = ar.entity_id);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- /audit-logs pages on (created_at, id); the id breaks ties between entries written in
+-- the same transaction. Entity type filters get their own index; actor and entity id
+-- filters already have one.
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created;
+CREATE INDEX idx_audit_logs_tenant_created ON audit_logs (tenant_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_logs_tenant_entity_type ON audit_logs (tenant_id, entity_type, created_at DESC, id DESC);
+
+COMMIT;
@@ -43,14 +43,19 @@ async function requestWithBody<TResponse, TRequest>(
   path: string,
   method: "POST" | "PATCH",
   body: TRequest,
-  parser: (input: unknown) => TResponse
+  parser: (input: unknown) => TResponse,
+  userID?: string
 ): Promise<TResponse> {
+  const headers: Record<string, string> = {
+    "Content-Type": "application/json",
+    "X-Tenant-ID": tenantID
+  };
+  if (userID) {
+    headers["X-User-ID"] = userID;
+  }
   const response = await fetch(`${apiBaseURL}${path}`, {
     method,
-    headers: {
-      "Content-Type": "application/json",
-      "X-Tenant-ID": tenantID
-    },
+    headers,
     body: JSON.stringify(body)
   });
 
@@ -131,7 +136,8 @@ export async function createApproval(payload: {
   approverUserId: string;
   reason: string;
 }): Promise<ApprovalsResponse> {
-  await requestWithBody("/approvals", "POST", payload, (input) => input);
+  const { requestedBy, ...body } = payload;
+  await requestWithBody("/approvals", "POST", body, (input) => input, requestedBy);
   return fetchApprovals();
 }
 
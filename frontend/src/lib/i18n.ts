@@ -224,14 +224,18 @@ const kpiMetricLabels: Record<Locale, Record<string, string>> = {
     open_pipeline_count: "Open Pipeline Count",
     won_amount_current_month: "Won Amount (Current Month)",
     won_amount_current_quarter: "Won Amount (Current Quarter)",
-    average_deal_size: "Average Deal Size"
+    average_deal_size: "Average Deal Size",
+    open_pipeline_missing_rate_count: "Open Deals Without FX Rate",
+    won_amount_90d_missing_rate_count: "Won Deals Without FX Rate (90 Days)"
   },
   ja: {
     open_pipeline_amount: "進行中パイプライン金額",
     open_pipeline_count: "進行中パイプライン件数",
     won_amount_current_month: "今月の受注金額",
     won_amount_current_quarter: "今四半期の受注金額",
-    average_deal_size: "平均案件単価"
+    average_deal_size: "平均案件単価",
+    open_pipeline_missing_rate_count: "為替レート未登録の進行中案件",
+    won_amount_90d_missing_rate_count: "為替レート未登録の受注案件（90日）"
   }
 };
 
@@ -342,4 +346,4 @@ export function interpolate(template: string, values: Record<string, string>): s
     result = result.replaceAll(`{${key}}`, value);
   });
   return result;
-}
+}